@@ -0,0 +1,183 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/xy-planning-network/trails"
+	"github.com/xy-planning-network/trails/http/middleware"
+)
+
+const (
+	// APIKeyPrefix begins every API key NewAPIKey generates,
+	// so leaked keys are recognizable, e.g., by secret scanners.
+	APIKeyPrefix = "trk_"
+
+	// apiKeyKey stashes the StoredKey authenticating an HTTP request.
+	apiKeyKey trails.Key = "APIKeyKey"
+
+	// apiKeyTouchInterval is how long after a key's last recorded use another is recorded,
+	// so busy keys are not written on every request.
+	apiKeyTouchInterval = time.Minute
+)
+
+// A StoredKey is an API key as stored by a KeyStore, which never stores the key itself.
+type StoredKey struct {
+	// ExpiresAt is when the key stops authenticating requests.
+	//
+	// If ExpiresAt is zero, the key does not expire.
+	ExpiresAt time.Time
+
+	// ID identifies the key.
+	ID uint
+
+	// LastUsedAt is when the key last authenticated a request, as recorded by [KeyStore.TouchKey].
+	LastUsedAt time.Time
+
+	// Owner is the user or account the key acts as,
+	// stashed in the request's context.Context under trails.CurrentUserKey.
+	Owner middleware.User
+
+	// Scopes are what the key may do; cf. RequireScopes.
+	Scopes []string
+}
+
+// HasScopes reports whether the key has every one of scopes.
+func (k StoredKey) HasScopes(scopes ...string) bool {
+	for _, scope := range scopes {
+		if !slices.Contains(k.Scopes, scope) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// A KeyStore looks up API keys by their hash, as HashAPIKey computes it.
+type KeyStore interface {
+	// FindKey finds the key hashing to hash,
+	// returning trails.ErrNotExist if there is none.
+	FindKey(ctx context.Context, hash string) (StoredKey, error)
+
+	// TouchKey records the key identified by id authenticated a request at t.
+	TouchKey(ctx context.Context, id uint, t time.Time) error
+}
+
+// NewAPIKey generates a random API key to hand its owner once,
+// and the hash of it to store in its place.
+func NewAPIKey() (key, hash string, err error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+
+	key = APIKeyPrefix + base64.RawURLEncoding.EncodeToString(b)
+	return key, HashAPIKey(key), nil
+}
+
+// HashAPIKey hashes key with SHA-256 to find or store it by.
+//
+// Since API keys are random, not chosen by people, they need no salt nor slow hash.
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// APIKey authenticates requests by an API key sent in an "Authorization: Bearer <key>" header,
+// looking the key up in store, responding with 401 to requests without a key store finds,
+// whose key has expired, or whose key's Owner does not have access.
+//
+// APIKey stashes the key's Owner in the request's context.Context under trails.CurrentUserKey,
+// so APIKey replaces [middleware.CurrentUser] on routes serving API clients,
+// and the StoredKey itself, retrieved with APIKeyFromContext.
+// APIKey records when each key is used, at most once a minute.
+func APIKey(store KeyStore) middleware.Adapter {
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			scheme, token, _ := strings.Cut(r.Header.Get("Authorization"), " ")
+			if !strings.EqualFold(scheme, "Bearer") || strings.TrimSpace(token) == "" {
+				unauthorized(w, "")
+				return
+			}
+
+			key, err := store.FindKey(r.Context(), HashAPIKey(strings.TrimSpace(token)))
+			switch {
+			case errors.Is(err, trails.ErrNotExist):
+				unauthorized(w, "invalid_token")
+				return
+			case err != nil:
+				http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+				return
+			}
+
+			now := time.Now()
+			if !key.ExpiresAt.IsZero() && now.After(key.ExpiresAt) {
+				unauthorized(w, "invalid_token")
+				return
+			}
+
+			if key.Owner == nil || !key.Owner.HasAccess() {
+				unauthorized(w, "invalid_token")
+				return
+			}
+
+			if now.Sub(key.LastUsedAt) >= apiKeyTouchInterval {
+				// NOTE: failing to record a use does not fail the request it authenticates.
+				_ = store.TouchKey(r.Context(), key.ID, now)
+			}
+
+			ctx := trails.WithValue(r.Context(), trails.CurrentUserKey, key.Owner)
+			ctx = trails.WithValue(ctx, apiKeyKey, key)
+			*r = *r.Clone(ctx)
+
+			h.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RequireScopes responds with 403 to requests whose API key, as authenticated by APIKey,
+// does not have every one of scopes.
+func RequireScopes(scopes ...string) middleware.Adapter {
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key, ok := APIKeyFromContext(r.Context())
+			if !ok {
+				unauthorized(w, "")
+				return
+			}
+
+			if !key.HasScopes(scopes...) {
+				w.Header().Set("WWW-Authenticate", `Bearer error="insufficient_scope", scope="`+strings.Join(scopes, " ")+`"`)
+				http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+				return
+			}
+
+			h.ServeHTTP(w, r)
+		})
+	}
+}
+
+// APIKeyFromContext retrieves the StoredKey APIKey authenticated a request with from ctx.
+func APIKeyFromContext(ctx context.Context) (StoredKey, bool) {
+	return trails.Value[StoredKey](ctx, apiKeyKey)
+}
+
+// unauthorized responds with 401, challenging the client to authenticate with a bearer token;
+// cf. RFC 6750, section 3.
+func unauthorized(w http.ResponseWriter, code string) {
+	challenge := "Bearer"
+	if code != "" {
+		challenge += ` error="` + code + `"`
+	}
+
+	w.Header().Set("WWW-Authenticate", challenge)
+	http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+}
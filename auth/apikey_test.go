@@ -0,0 +1,120 @@
+package auth_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/xy-planning-network/trails"
+	"github.com/xy-planning-network/trails/auth"
+	"github.com/xy-planning-network/trails/http/middleware"
+)
+
+// testKeyStore is an in-memory auth.KeyStore.
+type testKeyStore struct {
+	keys    map[string]auth.StoredKey
+	touched []uint
+}
+
+func (s *testKeyStore) FindKey(_ context.Context, hash string) (auth.StoredKey, error) {
+	key, ok := s.keys[hash]
+	if !ok {
+		return auth.StoredKey{}, trails.ErrNotExist
+	}
+
+	return key, nil
+}
+
+func (s *testKeyStore) TouchKey(_ context.Context, id uint, _ time.Time) error {
+	s.touched = append(s.touched, id)
+	return nil
+}
+
+// testOwner is a middleware.User.
+type testOwner struct{ access bool }
+
+func (o testOwner) HasAccess() bool  { return o.access }
+func (o testOwner) HomePath() string { return "/" }
+
+func TestNewAPIKey(t *testing.T) {
+	// Act
+	key, hash, err := auth.NewAPIKey()
+
+	// Assert
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(key, auth.APIKeyPrefix))
+	require.Equal(t, auth.HashAPIKey(key), hash)
+	require.NotContains(t, hash, key)
+}
+
+func TestAPIKey(t *testing.T) {
+	newKey := func(t *testing.T) (string, string) {
+		key, hash, err := auth.NewAPIKey()
+		require.NoError(t, err)
+
+		return key, hash
+	}
+
+	valid, validHash := newKey(t)
+	recent, recentHash := newKey(t)
+	expired, expiredHash := newKey(t)
+	revoked, revokedHash := newKey(t)
+	unknown, _ := newKey(t)
+
+	store := &testKeyStore{keys: map[string]auth.StoredKey{
+		validHash:   {ID: 1, Owner: testOwner{true}, Scopes: []string{"read", "write"}},
+		recentHash:  {ID: 2, LastUsedAt: time.Now(), Owner: testOwner{true}, Scopes: []string{"read"}},
+		expiredHash: {ExpiresAt: time.Now().Add(-time.Hour), ID: 3, Owner: testOwner{true}},
+		revokedHash: {ID: 4, Owner: testOwner{false}},
+	}}
+
+	h := middleware.Chain(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key, ok := auth.APIKeyFromContext(r.Context())
+			require.True(t, ok)
+			require.Equal(t, key.Owner, r.Context().Value(trails.CurrentUserKey))
+		}),
+		auth.APIKey(store),
+		auth.RequireScopes("write"),
+	)
+
+	for _, tc := range []struct {
+		name    string
+		header  string
+		code    int
+		touched []uint
+	}{
+		{"Valid", "Bearer " + valid, http.StatusOK, []uint{1}},
+		{"Valid-Lowercase-Scheme", "bearer " + valid, http.StatusOK, []uint{1}},
+		{"Missing-Scope", "Bearer " + recent, http.StatusForbidden, nil},
+		{"No-Header", "", http.StatusUnauthorized, nil},
+		{"Basic", "Basic " + valid, http.StatusUnauthorized, nil},
+		{"Unknown", "Bearer " + unknown, http.StatusUnauthorized, nil},
+		{"Expired", "Bearer " + expired, http.StatusUnauthorized, nil},
+		{"No-Access", "Bearer " + revoked, http.StatusUnauthorized, nil},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			store.touched = nil
+			rr := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/api/things", nil)
+			if tc.header != "" {
+				req.Header.Set("Authorization", tc.header)
+			}
+
+			// Act
+			h.ServeHTTP(rr, req)
+
+			// Assert
+			require.Equal(t, tc.code, rr.Code)
+			require.Equal(t, tc.touched, store.touched)
+			if tc.code != http.StatusOK {
+				require.True(t, strings.HasPrefix(rr.Header().Get("WWW-Authenticate"), "Bearer"))
+			}
+		})
+	}
+}
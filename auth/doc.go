@@ -0,0 +1,92 @@
+/*
+Package auth authenticates users of a trails app.
+
+# OpenID Connect
+
+An [OIDC] logs users in with an OpenID Connect provider, e.g., Google, Microsoft, or Okta,
+using the authorization code flow with PKCE.
+[OIDC.Login] redirects a user to the provider.
+[OIDC.Callback], served at the RedirectURL the provider sends the user back to,
+verifies the ID token the provider issues, maps the [Identity] it describes to an app user
+with a [UserProvisioner], and registers that user in their session:
+
+	oidc, err := auth.NewOIDC(ctx, auth.OIDCConfig{
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		Issuer:       auth.GoogleIssuer,
+		Provisioner: auth.UserProvisionerFunc(func(ctx context.Context, id auth.Identity) (uint, error) {
+			return users.FindOrCreateByEmail(ctx, id.Email)
+		}),
+		RedirectURL: "https://example.com/login/google/callback",
+		Responder:   rng.Responder,
+	})
+
+	rng.Router.Get("/login/google", oidc.Login)
+	rng.Router.Get("/login/google/callback", oidc.Callback)
+
+Both handlers expect the request's context.Context to hold a session.Session,
+as it does behind ranger's default middlewares.
+
+# Passwords
+
+[HashPassword] hashes a password with argon2id, and [VerifyPassword] checks a password against
+a hash made by it or by [HashPasswordBcrypt], in constant time.
+A [PasswordPolicy] validates the passwords users choose.
+
+[Passwords] serves drop-in handlers logging users in with an email address and password,
+logging them out, and resetting forgotten passwords with signed, expiring links
+emailed by a [mailer.TemplateMailer]:
+
+	pw, err := auth.NewPasswords(auth.PasswordsConfig{
+		LoginURL:       "https://example.com/login",
+		Mailer:         m,
+		ResetSecret:    resetSecret,
+		ResetTemplates: []string{"tmpl/email/layout.tmpl", "tmpl/email/reset.tmpl"},
+		ResetURL:       "https://example.com/password/reset",
+		Responder:      rng.Responder,
+		Users:          users,
+	})
+
+	rng.Router.Post("/login", pw.Login)
+	rng.Router.Post("/logout", pw.Logout)
+	rng.Router.Post("/password/forgot", pw.RequestReset)
+	rng.Router.Post("/password/reset", pw.Reset)
+
+These handlers, too, expect the request's context.Context to hold a session.Session.
+
+Set PasswordsConfig.Throttle to a [Throttle] to protect logins from brute-force attacks:
+it locks out users and IP addresses failing to log in too many times, for exponentially longer,
+counting failed attempts in an [AttemptStore].
+
+# API keys
+
+[APIKey] authenticates API clients, e.g., partner integrations, by keys they send as bearer tokens.
+[NewAPIKey] generates a key to hand its owner once and the hash of it to store in its place;
+a [KeyStore] finds a [StoredKey] by that hash, naming the user the key acts as and its scopes.
+[RequireScopes] limits routes to keys with certain scopes:
+
+	api := rng.Router.Subrouter("/api", auth.APIKey(keys))
+	api.Get("/reports", reports, auth.RequireScopes("reports:read"))
+
+# JSON Web Tokens
+
+A [JWT] issues and verifies JSON Web Tokens, e.g., for apps to authenticate to one another.
+It signs tokens with HMAC, RSA, or ECDSA [SigningKey]s, loaded from PEM with [ParseSigningKey],
+and verifies tokens signed by any of them by the "kid" header, so keys can be rotated:
+
+	key, err := auth.ParseSigningKey("2024-06", pemBytes)
+	j, err := auth.NewJWT(auth.JWTConfig{Audience: "reports", Issuer: "app", Keys: []auth.SigningKey{key}})
+	token, err := j.Issue(strconv.Itoa(int(user.ID)), map[string]any{"role": "admin"})
+
+[JWT.Authenticate] verifies tokens sent as bearer tokens, stashing their [Claims] in the request's context.Context.
+
+# Google service-to-service tokens
+
+A [GoogleVerifier] verifies ID tokens Google issues to service accounts and internal tools,
+caching Google's keys and allowing only tokens issued for its Audiences.
+[RequireGoogleIdentity] guards routes with it, requiring an identity in a domain:
+
+	v, err := auth.NewGoogleVerifier(auth.GoogleVerifierConfig{Audiences: []string{"https://tools.example.com"}})
+	internal := rng.Router.Subrouter("/internal", auth.RequireGoogleIdentity(v, "xyplanningnetwork.com"))
+*/
+package auth
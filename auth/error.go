@@ -0,0 +1,11 @@
+package auth
+
+import "errors"
+
+var (
+	// ErrBadCredentials is returned when a password does not match the user's.
+	ErrBadCredentials = errors.New("bad credentials")
+
+	// ErrLockedOut is returned when a user or IP address has failed to log in too many times to try again yet.
+	ErrLockedOut = errors.New("locked out")
+)
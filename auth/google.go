@@ -0,0 +1,196 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/xy-planning-network/trails"
+	"github.com/xy-planning-network/trails/http/middleware"
+)
+
+const (
+	// GoogleCertsURL is where Google publishes the keys signing its ID tokens.
+	GoogleCertsURL = "https://www.googleapis.com/oauth2/v3/certs"
+
+	// defaultGoogleCacheTTL is how long Google's keys are cached.
+	defaultGoogleCacheTTL = time.Hour
+
+	// identityKey stashes the Identity a GoogleVerifier verified for an HTTP request.
+	identityKey trails.Key = "IdentityKey"
+)
+
+// GoogleVerifierConfig configures a GoogleVerifier.
+type GoogleVerifierConfig struct {
+	// Audiences are those ID tokens may be issued for, e.g., the OAuth client ID of a service account,
+	// or the URL of the app, as Google issues tokens for Cloud Run, Cloud Scheduler, or Pub/Sub push requests.
+	Audiences []string
+
+	// CacheTTL is how long Google's keys are cached before being fetched again.
+	// Keys are fetched sooner upon a token signed by a key not in the cache.
+	//
+	// If CacheTTL is zero, an hour is used.
+	CacheTTL time.Duration
+
+	// CertsURL is where Google's keys are fetched from.
+	//
+	// If CertsURL is empty, GoogleCertsURL is used.
+	CertsURL string
+
+	// Client fetches Google's keys.
+	//
+	// If Client is nil, a client timing out after Timeout is used.
+	Client *http.Client
+
+	// Timeout bounds requests fetching Google's keys when Client is nil.
+	//
+	// If Timeout is zero, 10 seconds is used.
+	Timeout time.Duration
+}
+
+// A GoogleVerifier verifies ID tokens Google issues to authenticate services to one another,
+// e.g., those of service accounts or internal tools signed in with Google.
+type GoogleVerifier struct {
+	cfg  GoogleVerifierConfig
+	keys *keySet
+}
+
+// NewGoogleVerifier constructs a *GoogleVerifier from cfg.
+//
+// NewGoogleVerifier returns trails.ErrBadConfig if cfg has no Audiences.
+func NewGoogleVerifier(cfg GoogleVerifierConfig) (*GoogleVerifier, error) {
+	if len(cfg.Audiences) == 0 {
+		return nil, fmt.Errorf("%w: missing Audiences", trails.ErrBadConfig)
+	}
+
+	if cfg.CacheTTL == 0 {
+		cfg.CacheTTL = defaultGoogleCacheTTL
+	}
+
+	if cfg.CertsURL == "" {
+		cfg.CertsURL = GoogleCertsURL
+	}
+
+	if cfg.Timeout == 0 {
+		cfg.Timeout = oidcTimeout
+	}
+
+	if cfg.Client == nil {
+		cfg.Client = &http.Client{Timeout: cfg.Timeout}
+	}
+
+	v := &GoogleVerifier{
+		cfg:  cfg,
+		keys: &keySet{client: cfg.Client, ttl: cfg.CacheTTL, url: cfg.CertsURL},
+	}
+
+	return v, nil
+}
+
+// Verify verifies raw is an ID token Google issued for one of the Audiences and valid now,
+// returning the Identity it describes.
+//
+// Verify returns trails.ErrNotValid or trails.ErrMissingData if raw is not such a token,
+// or trails.ErrNotExist if no key Google publishes signed it.
+func (v *GoogleVerifier) Verify(ctx context.Context, raw string) (Identity, error) {
+	t, err := parseJWT(raw)
+	if err != nil {
+		return Identity{}, err
+	}
+
+	key, err := v.keys.key(ctx, t.header.Kid)
+	if err != nil {
+		return Identity{}, err
+	}
+
+	if err := t.verify(key); err != nil {
+		return Identity{}, err
+	}
+
+	// NOTE: Google issues ID tokens under either issuer; cf. https://developers.google.com/identity/openid-connect/openid-connect#validatinganidtoken
+	if iss := t.string("iss"); iss != GoogleIssuer && iss != strings.TrimPrefix(GoogleIssuer, "https://") {
+		return Identity{}, fmt.Errorf("%w: token issued by %q", trails.ErrNotValid, iss)
+	}
+
+	if !slices.ContainsFunc(t.audience(), func(aud string) bool { return slices.Contains(v.cfg.Audiences, aud) }) {
+		return Identity{}, fmt.Errorf("%w: token not issued for an allowed audience", trails.ErrNotValid)
+	}
+
+	if t.string("sub") == "" {
+		return Identity{}, fmt.Errorf("%w: token has no subject", trails.ErrMissingData)
+	}
+
+	if err := t.validAt(time.Now(), oidcLeeway); err != nil {
+		return Identity{}, err
+	}
+
+	return identityOf(t), nil
+}
+
+// RequireGoogleIdentity authenticates requests by an ID token v verifies,
+// sent in an "Authorization: Bearer <token>" header,
+// responding with 401 to requests without one
+// and with 403 to those whose Identity does not have a verified email address in domain,
+// either a Google Workspace domain, e.g., "xyplanningnetwork.com",
+// or that of a project's service accounts, e.g., "my-project.iam.gserviceaccount.com".
+//
+// RequireGoogleIdentity stashes the Identity in the request's context.Context,
+// retrieved with IdentityFromContext.
+//
+// If domain is empty, any Identity v verifies is allowed.
+func RequireGoogleIdentity(v *GoogleVerifier, domain string) middleware.Adapter {
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			scheme, token, _ := strings.Cut(r.Header.Get("Authorization"), " ")
+			if !strings.EqualFold(scheme, "Bearer") || strings.TrimSpace(token) == "" {
+				unauthorized(w, "")
+				return
+			}
+
+			id, err := v.Verify(r.Context(), strings.TrimSpace(token))
+			switch {
+			case errors.Is(err, trails.ErrNotValid), errors.Is(err, trails.ErrMissingData), errors.Is(err, trails.ErrNotExist):
+				unauthorized(w, "invalid_token")
+				return
+			case err != nil:
+				http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+				return
+			}
+
+			if domain != "" && !inDomain(id, domain) {
+				http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+				return
+			}
+
+			*r = *r.Clone(trails.WithValue(r.Context(), identityKey, id))
+			h.ServeHTTP(w, r)
+		})
+	}
+}
+
+// IdentityFromContext retrieves the Identity RequireGoogleIdentity verified for a request from ctx.
+func IdentityFromContext(ctx context.Context) (Identity, bool) {
+	return trails.Value[Identity](ctx, identityKey)
+}
+
+// inDomain reports whether id has a verified email address in domain.
+//
+// Google asserts the Google Workspace domain of a user in the "hd" claim.
+// Lacking it, only service accounts, whose domain Google controls, are matched by email address,
+// since anyone can sign up for a Google account with an email address in any domain.
+func inDomain(id Identity, domain string) bool {
+	if !id.EmailVerified {
+		return false
+	}
+
+	if hd, ok := id.Claims["hd"].(string); ok {
+		return strings.EqualFold(hd, domain)
+	}
+
+	_, emailDomain, ok := strings.Cut(id.Email, "@")
+	return ok && strings.HasSuffix(emailDomain, ".gserviceaccount.com") && strings.EqualFold(emailDomain, domain)
+}
@@ -0,0 +1,119 @@
+package auth_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/xy-planning-network/trails"
+	"github.com/xy-planning-network/trails/auth"
+)
+
+func TestNewGoogleVerifier(t *testing.T) {
+	// Act
+	_, err := auth.NewGoogleVerifier(auth.GoogleVerifierConfig{})
+
+	// Assert
+	require.ErrorIs(t, err, trails.ErrBadConfig)
+}
+
+func TestGoogleVerifier(t *testing.T) {
+	p := newTestProvider(t)
+	v, err := auth.NewGoogleVerifier(auth.GoogleVerifierConfig{
+		Audiences: []string{"https://tools.example.com"},
+		CertsURL:  p.URL + "/keys",
+	})
+	require.NoError(t, err)
+
+	h := auth.RequireGoogleIdentity(v, "example.com")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, ok := auth.IdentityFromContext(r.Context())
+		require.True(t, ok)
+		require.Equal(t, "user-1", id.Subject)
+	}))
+
+	for _, tc := range []struct {
+		name   string
+		claims func(claims map[string]any)
+		code   int
+	}{
+		{"Success", nil, http.StatusOK},
+		{"Success-Bare-Issuer", func(c map[string]any) { c["iss"] = "accounts.google.com" }, http.StatusOK},
+		{"Bad-Issuer", func(c map[string]any) { c["iss"] = "https://evil.example.com" }, http.StatusUnauthorized},
+		{"Bad-Audience", func(c map[string]any) { c["aud"] = "https://other.example.com" }, http.StatusUnauthorized},
+		{"Expired", func(c map[string]any) { c["exp"] = time.Now().Add(-time.Hour).Unix() }, http.StatusUnauthorized},
+		{"Other-Domain", func(c map[string]any) { c["hd"] = "other.com" }, http.StatusForbidden},
+		{"No-Hosted-Domain", func(c map[string]any) { delete(c, "hd") }, http.StatusForbidden},
+		{"Unverified-Email", func(c map[string]any) { c["email_verified"] = false }, http.StatusForbidden},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			claims := map[string]any{
+				"aud":            "https://tools.example.com",
+				"email":          "user@example.com",
+				"email_verified": true,
+				"exp":            time.Now().Add(time.Hour).Unix(),
+				"hd":             "example.com",
+				"iat":            time.Now().Unix(),
+				"iss":            auth.GoogleIssuer,
+				"sub":            "user-1",
+			}
+			if tc.claims != nil {
+				tc.claims(claims)
+			}
+
+			rr := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/internal/tools", nil)
+			req.Header.Set("Authorization", "Bearer "+p.sign(t, claims))
+
+			// Act
+			h.ServeHTTP(rr, req)
+
+			// Assert
+			require.Equal(t, tc.code, rr.Code)
+		})
+	}
+
+	t.Run("Service-Account", func(t *testing.T) {
+		// Arrange
+		token := p.sign(t, map[string]any{
+			"aud":            "https://tools.example.com",
+			"email":          "cron@project.iam.gserviceaccount.com",
+			"email_verified": true,
+			"exp":            time.Now().Add(time.Hour).Unix(),
+			"iss":            auth.GoogleIssuer,
+			"sub":            "sa-1",
+		})
+
+		// Act
+		id, err := v.Verify(context.Background(), token)
+
+		// Assert
+		require.NoError(t, err)
+		require.Equal(t, "cron@project.iam.gserviceaccount.com", id.Email)
+
+		// Arrange
+		rr := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/internal/tools", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+
+		// Act
+		auth.RequireGoogleIdentity(v, "project.iam.gserviceaccount.com")(http.NotFoundHandler()).ServeHTTP(rr, req)
+
+		// Assert
+		require.Equal(t, http.StatusNotFound, rr.Code)
+	})
+
+	t.Run("No-Token", func(t *testing.T) {
+		// Arrange
+		rr := httptest.NewRecorder()
+
+		// Act
+		h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/internal/tools", nil))
+
+		// Assert
+		require.Equal(t, http.StatusUnauthorized, rr.Code)
+	})
+}
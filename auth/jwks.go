@@ -0,0 +1,197 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/xy-planning-network/trails"
+)
+
+// keySetRefresh bounds how often a keySet fetches keys again upon a token signed by a key it does not have.
+const keySetRefresh = time.Minute
+
+// A keySet fetches and caches the public keys in a JSON Web Key Set; cf. RFC 7517.
+type keySet struct {
+	client *http.Client
+	ttl    time.Duration
+	url    string
+
+	fetched time.Time
+	keys    map[string]crypto.PublicKey
+	mu      sync.Mutex
+}
+
+// key retrieves the public key identified by kid,
+// fetching the keys again if it is unknown, e.g., because the provider rotated keys,
+// or if the keys were fetched longer than ttl ago, when ttl is set.
+func (ks *keySet) key(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+
+	if key, ok := ks.lookup(kid); ok && (ks.ttl == 0 || time.Since(ks.fetched) < ks.ttl) {
+		return key, nil
+	}
+
+	if time.Since(ks.fetched) < keySetRefresh {
+		return nil, fmt.Errorf("%w: key %q", trails.ErrNotExist, kid)
+	}
+
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := getJSON(ctx, ks.client, ks.url, &set); err != nil {
+		return nil, fmt.Errorf("could not fetch keys: %w", err)
+	}
+
+	ks.fetched = time.Now()
+	ks.keys = make(map[string]crypto.PublicKey)
+	for _, k := range set.Keys {
+		// NOTE: skip keys for encryption or of kinds not signing tokens.
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+
+		if pub, err := k.publicKey(); err == nil {
+			ks.keys[k.Kid] = pub
+		}
+	}
+
+	if key, ok := ks.lookup(kid); ok {
+		return key, nil
+	}
+
+	return nil, fmt.Errorf("%w: key %q", trails.ErrNotExist, kid)
+}
+
+// lookup retrieves the public key identified by kid
+// or, if kid is empty, the only key in the set.
+func (ks *keySet) lookup(kid string) (crypto.PublicKey, bool) {
+	if key, ok := ks.keys[kid]; ok {
+		return key, true
+	}
+
+	if kid == "" && len(ks.keys) == 1 {
+		for _, key := range ks.keys {
+			return key, true
+		}
+	}
+
+	return nil, false
+}
+
+// A jwk is a JSON Web Key; cf. RFC 7517.
+type jwk struct {
+	Crv string `json:"crv"`
+	E   string `json:"e"`
+	Kid string `json:"kid"`
+	Kty string `json:"kty"`
+	N   string `json:"n"`
+	Use string `json:"use"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// publicKey decodes the RSA or elliptic curve public key k describes.
+func (k jwk) publicKey() (crypto.PublicKey, error) {
+	b64 := func(s string) (*big.Int, error) {
+		b, err := base64.RawURLEncoding.DecodeString(s)
+		if err != nil || len(b) == 0 {
+			return nil, fmt.Errorf("%w: key %q", trails.ErrNotValid, k.Kid)
+		}
+
+		return new(big.Int).SetBytes(b), nil
+	}
+
+	switch k.Kty {
+	case "RSA":
+		n, err := b64(k.N)
+		if err != nil {
+			return nil, err
+		}
+
+		e, err := b64(k.E)
+		if err != nil {
+			return nil, err
+		}
+
+		if !e.IsInt64() || e.Int64() > 1<<31-1 {
+			return nil, fmt.Errorf("%w: key %q", trails.ErrNotValid, k.Kid)
+		}
+
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("%w: key %q has unsupported curve %q", trails.ErrNotValid, k.Kid, k.Crv)
+		}
+
+		x, err := b64(k.X)
+		if err != nil {
+			return nil, err
+		}
+
+		y, err := b64(k.Y)
+		if err != nil {
+			return nil, err
+		}
+
+		key := &ecdsa.PublicKey{Curve: curve, X: x, Y: y}
+		if _, err := key.ECDH(); err != nil {
+			return nil, fmt.Errorf("%w: key %q", trails.ErrNotValid, k.Kid)
+		}
+
+		return key, nil
+	}
+
+	return nil, fmt.Errorf("%w: key %q has unsupported type %q", trails.ErrNotValid, k.Kid, k.Kty)
+}
+
+// getJSON decodes the JSON url responds with into dst.
+func getJSON(ctx context.Context, client *http.Client, url string, dst any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Accept", "application/json")
+	return doJSON(client, req, dst)
+}
+
+// doJSON sends req, decoding the JSON the server responds with into dst,
+// returning an error including the response if the server does not respond with 2xx.
+func doJSON(client *http.Client, req *http.Request, dst any) error {
+	res, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode >= http.StatusMultipleChoices {
+		msg, _ := io.ReadAll(io.LimitReader(res.Body, 1<<10))
+		return fmt.Errorf("%s responded %s: %s", req.URL.Host, res.Status, msg)
+	}
+
+	if err := json.NewDecoder(io.LimitReader(res.Body, 1<<20)).Decode(dst); err != nil {
+		return fmt.Errorf("could not decode response from %s: %w", req.URL.Host, err)
+	}
+
+	return nil
+}
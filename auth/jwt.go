@@ -0,0 +1,244 @@
+package auth
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	_ "crypto/sha256"
+	_ "crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/xy-planning-network/trails"
+)
+
+// A jwt is a JSON Web Token in JWS compact serialization, parsed but not necessarily verified;
+// cf. RFC 7519.
+type jwt struct {
+	claims map[string]any
+	header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	sig    []byte
+	signed string
+}
+
+// parseJWT parses raw into a jwt without verifying it.
+func parseJWT(raw string) (jwt, error) {
+	var t jwt
+	parts := strings.Split(raw, ".")
+	if len(parts) != 3 {
+		return t, fmt.Errorf("%w: token is not a JWS", trails.ErrNotValid)
+	}
+
+	for i, dst := range []any{&t.header, &t.claims} {
+		b, err := base64.RawURLEncoding.DecodeString(parts[i])
+		if err != nil {
+			return t, fmt.Errorf("%w: could not decode token: %s", trails.ErrNotValid, err)
+		}
+
+		dec := json.NewDecoder(bytes.NewReader(b))
+		dec.UseNumber()
+		if err := dec.Decode(dst); err != nil {
+			return t, fmt.Errorf("%w: could not decode token: %s", trails.ErrNotValid, err)
+		}
+	}
+
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return t, fmt.Errorf("%w: could not decode token signature: %s", trails.ErrNotValid, err)
+	}
+
+	t.sig = sig
+	t.signed = parts[0] + "." + parts[1]
+	return t, nil
+}
+
+// signJWT signs claims with key as a JWS identified by kid, using the algorithm alg.
+func signJWT(key crypto.PrivateKey, alg, kid string, claims map[string]any) (string, error) {
+	header := map[string]string{"alg": alg, "typ": "JWT"}
+	if kid != "" {
+		header["kid"] = kid
+	}
+
+	var parts [2]string
+	for i, v := range []any{header, claims} {
+		b, err := json.Marshal(v)
+		if err != nil {
+			return "", fmt.Errorf("could not encode token: %w", err)
+		}
+
+		parts[i] = base64.RawURLEncoding.EncodeToString(b)
+	}
+	signed := parts[0] + "." + parts[1]
+
+	hash := crypto.SHA256
+	switch alg[2:] {
+	case "384":
+		hash = crypto.SHA384
+	case "512":
+		hash = crypto.SHA512
+	}
+
+	h := hash.New()
+	h.Write([]byte(signed))
+	digest := h.Sum(nil)
+
+	var sig []byte
+	switch key := key.(type) {
+	case []byte:
+		mac := hmac.New(hash.New, key)
+		mac.Write([]byte(signed))
+		sig = mac.Sum(nil)
+	case *rsa.PrivateKey:
+		var err error
+		if sig, err = rsa.SignPKCS1v15(rand.Reader, key, hash, digest); err != nil {
+			return "", fmt.Errorf("could not sign token: %w", err)
+		}
+	case *ecdsa.PrivateKey:
+		r, s, err := ecdsa.Sign(rand.Reader, key, digest)
+		if err != nil {
+			return "", fmt.Errorf("could not sign token: %w", err)
+		}
+
+		size := (key.Curve.Params().BitSize + 7) / 8
+		sig = make([]byte, 2*size)
+		r.FillBytes(sig[:size])
+		s.FillBytes(sig[size:])
+	default:
+		return "", fmt.Errorf("%w: cannot sign with %T", trails.ErrBadConfig, key)
+	}
+
+	return signed + "." + base64.RawURLEncoding.EncodeToString(sig), nil
+}
+
+// verify checks key signed t with the algorithm t's header names.
+func (t jwt) verify(key crypto.PublicKey) error {
+	var hash crypto.Hash
+	switch t.header.Alg[min(2, len(t.header.Alg)):] {
+	case "256":
+		hash = crypto.SHA256
+	case "384":
+		hash = crypto.SHA384
+	case "512":
+		hash = crypto.SHA512
+	default:
+		return fmt.Errorf("%w: unsupported algorithm %q", trails.ErrNotValid, t.header.Alg)
+	}
+
+	h := hash.New()
+	h.Write([]byte(t.signed))
+	digest := h.Sum(nil)
+
+	switch key := key.(type) {
+	case []byte:
+		if !strings.HasPrefix(t.header.Alg, "HS") {
+			break
+		}
+
+		mac := hmac.New(hash.New, key)
+		mac.Write([]byte(t.signed))
+		if !hmac.Equal(mac.Sum(nil), t.sig) {
+			return fmt.Errorf("%w: bad token signature", trails.ErrNotValid)
+		}
+
+		return nil
+	case *rsa.PublicKey:
+		if !strings.HasPrefix(t.header.Alg, "RS") {
+			break
+		}
+
+		if err := rsa.VerifyPKCS1v15(key, hash, digest, t.sig); err != nil {
+			return fmt.Errorf("%w: bad token signature", trails.ErrNotValid)
+		}
+
+		return nil
+	case *ecdsa.PublicKey:
+		if !strings.HasPrefix(t.header.Alg, "ES") {
+			break
+		}
+
+		size := (key.Curve.Params().BitSize + 7) / 8
+		if len(t.sig) != 2*size {
+			return fmt.Errorf("%w: bad token signature", trails.ErrNotValid)
+		}
+
+		r := new(big.Int).SetBytes(t.sig[:size])
+		s := new(big.Int).SetBytes(t.sig[size:])
+		if !ecdsa.Verify(key, digest, r, s) {
+			return fmt.Errorf("%w: bad token signature", trails.ErrNotValid)
+		}
+
+		return nil
+	}
+
+	return fmt.Errorf("%w: algorithm %q does not match key %T", trails.ErrNotValid, t.header.Alg, key)
+}
+
+// validAt checks t is neither expired nor not yet valid at now, tolerating leeway of clock skew.
+func (t jwt) validAt(now time.Time, leeway time.Duration) error {
+	exp, ok := t.time("exp")
+	if !ok {
+		return fmt.Errorf("%w: token has no expiry", trails.ErrMissingData)
+	}
+
+	if now.After(exp.Add(leeway)) {
+		return fmt.Errorf("%w: token expired at %s", trails.ErrNotValid, exp.Format(time.RFC3339))
+	}
+
+	for _, name := range []string{"iat", "nbf"} {
+		if at, ok := t.time(name); ok && now.Add(leeway).Before(at) {
+			return fmt.Errorf("%w: token is not valid until %s", trails.ErrNotValid, at.Format(time.RFC3339))
+		}
+	}
+
+	return nil
+}
+
+// audience lists the recipients t is intended for, whether its "aud" claim is a string or an array.
+func (t jwt) audience() []string {
+	switch aud := t.claims["aud"].(type) {
+	case string:
+		return []string{aud}
+	case []any:
+		var out []string
+		for _, a := range aud {
+			if s, ok := a.(string); ok {
+				out = append(out, s)
+			}
+		}
+
+		return out
+	}
+
+	return nil
+}
+
+// string retrieves the claim name, if a string.
+func (t jwt) string(name string) string {
+	s, _ := t.claims[name].(string)
+	return s
+}
+
+// time retrieves the claim name, if a NumericDate.
+func (t jwt) time(name string) (time.Time, bool) {
+	n, ok := t.claims[name].(json.Number)
+	if !ok {
+		return time.Time{}, false
+	}
+
+	secs, err := n.Float64()
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	return time.Unix(0, int64(secs*float64(time.Second))), true
+}
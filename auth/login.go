@@ -0,0 +1,494 @@
+package auth
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/xy-planning-network/trails"
+	"github.com/xy-planning-network/trails/http/middleware"
+	"github.com/xy-planning-network/trails/http/resp"
+	"github.com/xy-planning-network/trails/http/session"
+	"github.com/xy-planning-network/trails/logger"
+	"github.com/xy-planning-network/trails/mailer"
+)
+
+const (
+	// defaultResetSubject is the subject line of password reset emails.
+	defaultResetSubject = "Reset your password"
+
+	// defaultResetTTL is how long a password reset link lasts.
+	defaultResetTTL = time.Hour
+
+	// minResetSecretLen is the fewest bytes of a secret signing password reset tokens.
+	minResetSecretLen = 32
+
+	// PasswordResetSentMsg is flashed after a user asks to reset their password,
+	// whether or not an account exists for the email address they gave.
+	PasswordResetSentMsg = session.LinkSentMsg
+
+	// PasswordResetMsg is flashed after a user resets their password.
+	PasswordResetMsg = "Your password has been reset. Please log in."
+
+	// PasswordResetExpiredMsg is flashed when a user follows a password reset link that expired or was already used.
+	PasswordResetExpiredMsg = "That link has expired. Please ask for a new one."
+
+	// LockedOutMsg is flashed when a user tries to log in while locked out by a Throttle.
+	LockedOutMsg = "Too many failed attempts. Please wait a while and try again."
+)
+
+// dummyHash is verified against when no user has the email address a login gives,
+// so failed logins take as long whether or not the user exists.
+// It is hashed on the first such login, sparing programs never logging anyone in the cost.
+var dummyHash = sync.OnceValue(func() string {
+	hash, _ := HashPassword("trails")
+	return hash
+})
+
+// A PasswordUser is the part of an app user logging in with a password.
+type PasswordUser struct {
+	// Email is the email address the user logs in with.
+	Email string
+
+	// ID identifies the user, as registered in their session.Session.
+	ID uint
+
+	// PasswordHash is the user's password as hashed by HashPassword or HashPasswordBcrypt.
+	PasswordHash string
+}
+
+// PasswordUsers looks up and updates the users of an app logging in with a password.
+type PasswordUsers interface {
+	// FindByEmail finds the user with the email address,
+	// returning trails.ErrNotExist if there is none.
+	FindByEmail(ctx context.Context, email string) (PasswordUser, error)
+
+	// FindByID finds the user with the ID,
+	// returning trails.ErrNotExist if there is none.
+	FindByID(ctx context.Context, id uint) (PasswordUser, error)
+
+	// SetPasswordHash stores hash as the password hash of the user with the ID.
+	SetPasswordHash(ctx context.Context, id uint, hash string) error
+}
+
+// PasswordResetEmail is the data password reset email templates render.
+type PasswordResetEmail struct {
+	// Email is the email address of the user resetting their password.
+	Email string
+
+	// Expires is when URL stops working.
+	Expires time.Time
+
+	// URL is the link the user follows to reset their password.
+	URL string
+}
+
+// PasswordsConfig configures a Passwords.
+type PasswordsConfig struct {
+	// LoginURL is where users are sent after a failed login, after asking to reset their password,
+	// and after resetting it, e.g., the page with the login form.
+	//
+	// If LoginURL is empty, the root URL of the Responder is used.
+	LoginURL string
+
+	// Mailer emails password reset links, rendering ResetTemplates with a PasswordResetEmail.
+	//
+	// If Mailer is nil, [Passwords.RequestReset] and [Passwords.Reset] respond 404 Not Found.
+	Mailer *mailer.TemplateMailer
+
+	// Policy sets which passwords users may choose when resetting theirs.
+	Policy PasswordPolicy
+
+	// ResetSecret signs password reset tokens, and must be at least 32 bytes if Mailer is set.
+	// Rotating ResetSecret invalidates all outstanding password reset links.
+	ResetSecret []byte
+
+	// ResetSubject is the subject line of password reset emails.
+	//
+	// If ResetSubject is empty, "Reset your password" is used.
+	ResetSubject string
+
+	// ResetTemplates render password reset emails; cf. [mailer.TemplateMailer.SendTemplate].
+	ResetTemplates []string
+
+	// ResetTTL is how long a password reset link lasts.
+	//
+	// If ResetTTL is zero, an hour is used.
+	ResetTTL time.Duration
+
+	// ResetURL is the absolute URL of the page with the form posting to [Passwords.Reset].
+	// Password reset links add a "token" query parameter to it the form must post back.
+	ResetURL string
+
+	// Responder redirects users and retrieves their session.Session.
+	Responder *resp.Responder
+
+	// Throttle locks out users and IP addresses failing to log in too many times.
+	//
+	// If Throttle is nil, failed logins are not throttled.
+	Throttle *Throttle
+
+	// Users looks up and updates users.
+	Users PasswordUsers
+}
+
+// Passwords logs users in with an email address and password,
+// logs them out, and resets forgotten passwords with links emailed to them.
+type Passwords struct {
+	cfg      PasswordsConfig
+	resetURL *url.URL
+}
+
+// NewPasswords constructs a *Passwords from cfg.
+//
+// NewPasswords returns trails.ErrBadConfig if cfg is missing values.
+func NewPasswords(cfg PasswordsConfig) (*Passwords, error) {
+	resetting := cfg.Mailer != nil
+	for _, field := range []struct {
+		name    string
+		missing bool
+	}{
+		{"Responder", cfg.Responder == nil},
+		{"Users", cfg.Users == nil},
+		{"ResetSecret", resetting && len(cfg.ResetSecret) < minResetSecretLen},
+		{"ResetTemplates", resetting && len(cfg.ResetTemplates) == 0},
+		{"ResetURL", resetting && cfg.ResetURL == ""},
+	} {
+		if field.missing {
+			return nil, fmt.Errorf("%w: missing %s", trails.ErrBadConfig, field.name)
+		}
+	}
+
+	if cfg.ResetSubject == "" {
+		cfg.ResetSubject = defaultResetSubject
+	}
+
+	if cfg.ResetTTL == 0 {
+		cfg.ResetTTL = defaultResetTTL
+	}
+
+	p := &Passwords{cfg: cfg}
+	if resetting {
+		u, err := url.Parse(cfg.ResetURL)
+		if err != nil || !u.IsAbs() {
+			return nil, fmt.Errorf("%w: ResetURL %q is not absolute", trails.ErrBadConfig, cfg.ResetURL)
+		}
+
+		p.resetURL = u
+	}
+
+	return p, nil
+}
+
+// Login handles a login form posting "email" and "password" values,
+// and optionally a "remember" value of "true" or "on"; cf. [session.Remember].
+//
+// Login registers the user in their session.Session, rehashing their password if it [NeedsRehash],
+// then redirects them to the URL set with [session.Session.SetReturnTo], if any,
+// or the root URL of the Responder.
+//
+// If the credentials do not match a user, Login redirects to LoginURL with an error flash,
+// recording a failed [logger.AuditSessionLogin] event.
+// If the Throttle has locked out the user or their IP address, Login does not check the credentials,
+// instead responding 429 Too Many Requests, with a flash, by redirecting to LoginURL.
+func (p *Passwords) Login(w http.ResponseWriter, r *http.Request) {
+	s, err := p.cfg.Responder.Session(r.Context())
+	if err != nil {
+		p.cfg.Responder.Err(w, r, err)
+		return
+	}
+
+	email, ip := strings.TrimSpace(r.PostFormValue("email")), middleware.RequestIP(r)
+	if p.cfg.Throttle != nil {
+		wait, err := p.cfg.Throttle.Check(r.Context(), email, ip)
+		if errors.Is(err, ErrLockedOut) {
+			setRetryAfter(w, wait)
+			p.cfg.Responder.Redirect(w, r,
+				p.toLogin(),
+				resp.Flash(session.Flash{Type: session.FlashError, Msg: LockedOutMsg}),
+				resp.Code(http.StatusTooManyRequests),
+			)
+			return
+		}
+
+		if err != nil {
+			p.cfg.Responder.Redirect(w, r, p.toLogin(), resp.GenericErr(err))
+			return
+		}
+	}
+
+	password := r.PostFormValue("password")
+	user, err := p.cfg.Users.FindByEmail(r.Context(), email)
+	switch {
+	case errors.Is(err, trails.ErrNotExist):
+		_ = VerifyPassword(dummyHash(), password)
+		err = ErrBadCredentials
+	case err == nil:
+		err = VerifyPassword(user.PasswordHash, password)
+	}
+
+	if errors.Is(err, ErrBadCredentials) {
+		logger.Audit(logger.AuditEvent{
+			Action:  logger.AuditSessionLogin,
+			ActorID: user.ID,
+			Data:    map[string]any{"error": err.Error()},
+			Outcome: logger.AuditFailure,
+			Request: r,
+		})
+
+		if p.cfg.Throttle != nil {
+			if err := p.cfg.Throttle.Fail(r.Context(), email, ip); err != nil {
+				p.cfg.Responder.Redirect(w, r, p.toLogin(), resp.GenericErr(err))
+				return
+			}
+		}
+
+		p.cfg.Responder.Redirect(w, r,
+			p.toLogin(),
+			resp.Flash(session.Flash{Type: session.FlashError, Msg: session.BadCredsMsg}),
+			resp.Code(http.StatusUnauthorized),
+		)
+		return
+	}
+
+	if err != nil {
+		p.cfg.Responder.Redirect(w, r, p.toLogin(), resp.GenericErr(err))
+		return
+	}
+
+	if NeedsRehash(user.PasswordHash) {
+		// NOTE: the user has logged in either way; the old hash is replaced next time.
+		if hash, err := HashPassword(password); err == nil {
+			_ = p.cfg.Users.SetPasswordHash(r.Context(), user.ID, hash)
+		}
+	}
+
+	if p.cfg.Throttle != nil {
+		// NOTE: the user has logged in either way; stale failed attempts only risk locking them out sooner.
+		_ = p.cfg.Throttle.Succeed(r.Context(), email)
+	}
+
+	remember, _ := strconv.ParseBool(r.PostFormValue("remember"))
+	remember = remember || r.PostFormValue("remember") == "on"
+	if err := s.RegisterUser(w, r, user.ID, session.Remember(remember)); err != nil {
+		p.cfg.Responder.Err(w, r, err)
+		return
+	}
+
+	var opts []resp.Fn
+	if returnTo, err := s.ReturnTo(); err == nil && returnTo != "" {
+		opts = append(opts, resp.Url(returnTo))
+	}
+
+	p.cfg.Responder.Redirect(w, r, opts...)
+}
+
+// Logout deregisters the user from their session.Session; cf. [session.Session.DeregisterUser],
+// then redirects them to the root URL of the Responder.
+func (p *Passwords) Logout(w http.ResponseWriter, r *http.Request) {
+	s, err := p.cfg.Responder.Session(r.Context())
+	if err != nil {
+		p.cfg.Responder.Err(w, r, err)
+		return
+	}
+
+	if err := s.DeregisterUser(w, r); err != nil {
+		p.cfg.Responder.Err(w, r, err)
+		return
+	}
+
+	p.cfg.Responder.Redirect(w, r)
+}
+
+// RequestReset handles a form posting the "email" value of a user who forgot their password,
+// emailing them a link to ResetURL lasting ResetTTL.
+//
+// So as not to reveal who has an account, RequestReset redirects to LoginURL
+// with the same flash whether or not a user has the email address.
+func (p *Passwords) RequestReset(w http.ResponseWriter, r *http.Request) {
+	if p.resetURL == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	user, err := p.cfg.Users.FindByEmail(r.Context(), strings.TrimSpace(r.PostFormValue("email")))
+	if err == nil {
+		err = p.sendReset(r.Context(), user)
+	}
+
+	if err != nil && !errors.Is(err, trails.ErrNotExist) {
+		p.cfg.Responder.Redirect(w, r, p.toLogin(), resp.GenericErr(err))
+		return
+	}
+
+	p.cfg.Responder.Redirect(w, r,
+		p.toLogin(),
+		resp.Flash(session.Flash{Type: session.FlashInfo, Msg: PasswordResetSentMsg}),
+	)
+}
+
+// Reset handles the form at ResetURL posting the "token" from a password reset link
+// and the user's new "password", which must meet the Policy.
+//
+// Reset stores the new password's hash, then redirects to LoginURL for the user to log in with it.
+// A password reset link works once, since the token is tied to the password it replaces.
+//
+// If the new password does not meet the Policy, Reset redirects back to ResetURL with a warning flash.
+// If the token is not valid or has expired, Reset redirects to LoginURL with an error flash.
+func (p *Passwords) Reset(w http.ResponseWriter, r *http.Request) {
+	if p.resetURL == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	token := r.PostFormValue("token")
+	user, err := p.verifyReset(r.Context(), token, time.Now())
+	if err != nil {
+		opts := []resp.Fn{p.toLogin(), resp.GenericErr(err)}
+		if errors.Is(err, trails.ErrNotValid) || errors.Is(err, trails.ErrNotExist) {
+			opts = []resp.Fn{
+				p.toLogin(),
+				resp.Flash(session.Flash{Type: session.FlashError, Msg: PasswordResetExpiredMsg}),
+				resp.Code(http.StatusBadRequest),
+			}
+		}
+
+		p.cfg.Responder.Redirect(w, r, opts...)
+		return
+	}
+
+	password := r.PostFormValue("password")
+	if problem := p.cfg.Policy.problem(password, user.Email); problem != "" {
+		p.cfg.Responder.Redirect(w, r,
+			resp.Url(p.resetLink(token)),
+			resp.Warn("Your password "+problem+"."),
+			resp.Code(http.StatusBadRequest),
+		)
+		return
+	}
+
+	hash, err := HashPassword(password)
+	if err == nil {
+		err = p.cfg.Users.SetPasswordHash(r.Context(), user.ID, hash)
+	}
+
+	if err != nil {
+		p.cfg.Responder.Redirect(w, r, p.toLogin(), resp.GenericErr(err))
+		return
+	}
+
+	p.cfg.Responder.Redirect(w, r,
+		p.toLogin(),
+		resp.Flash(session.Flash{Type: session.FlashSuccess, Msg: PasswordResetMsg}),
+	)
+}
+
+// sendReset emails user a password reset link.
+func (p *Passwords) sendReset(ctx context.Context, user PasswordUser) error {
+	expires := time.Now().Add(p.cfg.ResetTTL)
+	data := PasswordResetEmail{
+		Email:   user.Email,
+		Expires: expires,
+		URL:     p.resetLink(p.signReset(user, expires)),
+	}
+
+	msg := mailer.Message{Subject: p.cfg.ResetSubject, To: []string{user.Email}}
+	if err := p.cfg.Mailer.SendTemplate(ctx, msg, data, p.cfg.ResetTemplates...); err != nil {
+		return fmt.Errorf("could not email password reset link: %w", err)
+	}
+
+	return nil
+}
+
+// signReset makes a token resetting user's password until expires.
+//
+// The token signs the user's ID, when it expires, and a fingerprint of the user's password hash,
+// so it stops working once the password is reset.
+func (p *Passwords) signReset(user PasswordUser, expires time.Time) string {
+	payload := fmt.Sprintf("%d.%d.%s", user.ID, expires.Unix(), fingerprint(user.PasswordHash))
+	return base64.RawURLEncoding.EncodeToString([]byte(payload)) + "." + p.mac(payload)
+}
+
+// verifyReset checks token was made by signReset, has not expired as of now,
+// and was made for the password the user has now, returning the user it was made for.
+//
+// verifyReset returns trails.ErrNotValid if the token is not valid.
+func (p *Passwords) verifyReset(ctx context.Context, token string, now time.Time) (PasswordUser, error) {
+	encoded, sig, _ := strings.Cut(token, ".")
+	raw, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil || !hmac.Equal([]byte(sig), []byte(p.mac(string(raw)))) {
+		return PasswordUser{}, fmt.Errorf("%w: password reset token", trails.ErrNotValid)
+	}
+
+	parts := strings.Split(string(raw), ".")
+	if len(parts) != 3 {
+		return PasswordUser{}, fmt.Errorf("%w: password reset token", trails.ErrNotValid)
+	}
+
+	id, err := strconv.ParseUint(parts[0], 10, 0)
+	if err != nil {
+		return PasswordUser{}, fmt.Errorf("%w: password reset token user: %s", trails.ErrNotValid, err)
+	}
+
+	exp, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return PasswordUser{}, fmt.Errorf("%w: password reset token expiry: %s", trails.ErrNotValid, err)
+	}
+
+	if now.After(time.Unix(exp, 0)) {
+		return PasswordUser{}, fmt.Errorf("%w: password reset token expired", trails.ErrNotValid)
+	}
+
+	user, err := p.cfg.Users.FindByID(ctx, uint(id))
+	if err != nil {
+		return PasswordUser{}, err
+	}
+
+	if !hmac.Equal([]byte(parts[2]), []byte(fingerprint(user.PasswordHash))) {
+		return PasswordUser{}, fmt.Errorf("%w: password reset token already used", trails.ErrNotValid)
+	}
+
+	return user, nil
+}
+
+// mac signs payload with the ResetSecret.
+func (p *Passwords) mac(payload string) string {
+	h := hmac.New(sha256.New, p.cfg.ResetSecret)
+	h.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(h.Sum(nil))
+}
+
+// resetLink adds token to the ResetURL.
+func (p *Passwords) resetLink(token string) string {
+	u := *p.resetURL
+	q := u.Query()
+	q.Set("token", token)
+	u.RawQuery = q.Encode()
+
+	return u.String()
+}
+
+// toLogin redirects to the LoginURL, or the root URL of the Responder if there is none.
+func (p *Passwords) toLogin() resp.Fn {
+	if p.cfg.LoginURL != "" {
+		return resp.Url(p.cfg.LoginURL)
+	}
+
+	return resp.ToRoot()
+}
+
+// fingerprint identifies hash without revealing it.
+func fingerprint(hash string) string {
+	sum := sha256.Sum256([]byte(hash))
+	return hex.EncodeToString(sum[:8])
+}
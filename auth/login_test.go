@@ -0,0 +1,266 @@
+package auth_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/require"
+	"github.com/xy-planning-network/trails"
+	"github.com/xy-planning-network/trails/auth"
+	"github.com/xy-planning-network/trails/http/resp"
+	"github.com/xy-planning-network/trails/http/session"
+	"github.com/xy-planning-network/trails/http/template"
+	"github.com/xy-planning-network/trails/logger"
+	"github.com/xy-planning-network/trails/mailer"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// testUsers is an in-memory auth.PasswordUsers.
+type testUsers map[uint]auth.PasswordUser
+
+func (u testUsers) FindByEmail(_ context.Context, email string) (auth.PasswordUser, error) {
+	for _, user := range u {
+		if user.Email == email {
+			return user, nil
+		}
+	}
+
+	return auth.PasswordUser{}, fmt.Errorf("%w: %s", trails.ErrNotExist, email)
+}
+
+func (u testUsers) FindByID(_ context.Context, id uint) (auth.PasswordUser, error) {
+	user, ok := u[id]
+	if !ok {
+		return auth.PasswordUser{}, fmt.Errorf("%w: %d", trails.ErrNotExist, id)
+	}
+
+	return user, nil
+}
+
+func (u testUsers) SetPasswordHash(_ context.Context, id uint, hash string) error {
+	user := u[id]
+	user.PasswordHash = hash
+	u[id] = user
+
+	return nil
+}
+
+// sentMail records the emails sent through it.
+type sentMail []mailer.Message
+
+func (m *sentMail) Send(_ context.Context, msg mailer.Message) error {
+	*m = append(*m, msg)
+	return nil
+}
+
+func newPasswords(t *testing.T, users testUsers, sent *sentMail) *auth.Passwords {
+	l := logger.New(slog.New(slog.NewTextHandler(new(bytes.Buffer), nil)), trails.Testing)
+	fsys := fstest.MapFS{
+		"tmpl/email/reset.tmpl": {Data: []byte(`<a href="{{ .URL }}">Reset</a>`)},
+	}
+
+	p, err := auth.NewPasswords(auth.PasswordsConfig{
+		LoginURL:       "https://example.com/login",
+		Mailer:         mailer.NewTemplateMailer(sent, template.NewParser([]fs.FS{fsys}), l, "hello@example.com"),
+		ResetSecret:    bytes.Repeat([]byte("k"), 32),
+		ResetTemplates: []string{"tmpl/email/reset.tmpl"},
+		ResetURL:       "https://example.com/password/reset",
+		Responder:      resp.NewResponder(resp.WithRootUrl("https://example.com/")),
+		Users:          users,
+	})
+	require.NoError(t, err)
+
+	return p
+}
+
+func newFormReq(t *testing.T, s session.Session, target string, form url.Values) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, target, strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return req.WithContext(context.WithValue(req.Context(), trails.SessionKey, s))
+}
+
+func TestNewPasswords(t *testing.T) {
+	// Arrange
+	cfg := auth.PasswordsConfig{Responder: resp.NewResponder(), Users: testUsers{}}
+
+	// Act
+	_, err := auth.NewPasswords(cfg)
+
+	// Assert
+	require.NoError(t, err)
+
+	// Arrange
+	l := logger.New(slog.New(slog.NewTextHandler(new(bytes.Buffer), nil)), trails.Testing)
+	cfg.Mailer = mailer.NewTemplateMailer(new(sentMail), nil, l, "hello@example.com")
+	cfg.ResetSecret = []byte("short")
+
+	// Act
+	_, err = auth.NewPasswords(cfg)
+
+	// Assert
+	require.ErrorIs(t, err, trails.ErrBadConfig)
+	require.ErrorContains(t, err, "ResetSecret")
+
+	// Arrange
+	cfg.ResetSecret = bytes.Repeat([]byte("k"), 32)
+	cfg.ResetTemplates = []string{"tmpl/email/reset.tmpl"}
+	cfg.ResetURL = "/password/reset"
+
+	// Act
+	_, err = auth.NewPasswords(cfg)
+
+	// Assert
+	require.ErrorIs(t, err, trails.ErrBadConfig)
+}
+
+func TestPasswordsLogin(t *testing.T) {
+	hash, err := auth.HashPassword("correct horse battery staple")
+	require.NoError(t, err)
+
+	bcryptHash, err := auth.HashPasswordBcrypt("correct horse battery staple", bcrypt.MinCost)
+	require.NoError(t, err)
+
+	for _, tc := range []struct {
+		name     string
+		email    string
+		password string
+		hash     string
+		ok       bool
+	}{
+		{"Success", "user@example.com", "correct horse battery staple", hash, true},
+		{"Success-Rehash", "user@example.com", "correct horse battery staple", bcryptHash, true},
+		{"Bad-Password", "user@example.com", "wrong", hash, false},
+		{"Unknown-Email", "nobody@example.com", "correct horse battery staple", hash, false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			users := testUsers{42: {Email: "user@example.com", ID: 42, PasswordHash: tc.hash}}
+			p := newPasswords(t, users, new(sentMail))
+
+			s, err := session.NewStub(false).GetSession(nil)
+			require.NoError(t, err)
+
+			rr := httptest.NewRecorder()
+			req := newFormReq(t, s, "https://example.com/login", url.Values{
+				"email":    {tc.email},
+				"password": {tc.password},
+				"remember": {"on"},
+			})
+
+			// Act
+			p.Login(rr, req)
+
+			// Assert
+			uid, err := s.UserID()
+			if !tc.ok {
+				require.Equal(t, http.StatusSeeOther, rr.Code)
+				require.Equal(t, "/login", rr.Header().Get("Location"))
+				require.ErrorIs(t, err, session.ErrNoUser)
+				require.Equal(t, []session.Flash{{Type: session.FlashError, Msg: session.BadCredsMsg}}, s.Flashes(rr, req))
+				return
+			}
+
+			require.Equal(t, http.StatusSeeOther, rr.Code)
+			require.NoError(t, err)
+			require.Equal(t, uint(42), uid)
+			require.False(t, auth.NeedsRehash(users[42].PasswordHash))
+
+			// Arrange
+			rr = httptest.NewRecorder()
+
+			// Act
+			p.Logout(rr, newFormReq(t, s, "https://example.com/logout", nil))
+
+			// Assert
+			require.Equal(t, http.StatusSeeOther, rr.Code)
+			_, err = s.UserID()
+			require.ErrorIs(t, err, session.ErrNoUser)
+		})
+	}
+}
+
+func TestPasswordsReset(t *testing.T) {
+	// Arrange
+	hash, err := auth.HashPassword("forgotten password")
+	require.NoError(t, err)
+
+	users := testUsers{42: {Email: "user@example.com", ID: 42, PasswordHash: hash}}
+	sent := new(sentMail)
+	p := newPasswords(t, users, sent)
+
+	s, err := session.NewStub(false).GetSession(nil)
+	require.NoError(t, err)
+
+	rr := httptest.NewRecorder()
+
+	// Act
+	p.RequestReset(rr, newFormReq(t, s, "https://example.com/password/forgot", url.Values{"email": {"nobody@example.com"}}))
+
+	// Assert
+	require.Equal(t, http.StatusSeeOther, rr.Code)
+	require.Empty(t, *sent)
+
+	// Arrange
+	rr = httptest.NewRecorder()
+
+	// Act
+	p.RequestReset(rr, newFormReq(t, s, "https://example.com/password/forgot", url.Values{"email": {"user@example.com"}}))
+
+	// Assert
+	require.Equal(t, http.StatusSeeOther, rr.Code)
+	require.Equal(t, "/login", rr.Header().Get("Location"))
+	require.Len(t, *sent, 1)
+	require.Equal(t, []string{"user@example.com"}, (*sent)[0].To)
+	require.Equal(t, "Reset your password", (*sent)[0].Subject)
+
+	m := regexp.MustCompile(`href="([^"]+)"`).FindStringSubmatch((*sent)[0].HTML)
+	require.Len(t, m, 2)
+
+	link, err := url.Parse(strings.ReplaceAll(m[1], "&amp;", "&"))
+	require.NoError(t, err)
+	require.Equal(t, "/password/reset", link.Path)
+
+	token := link.Query().Get("token")
+	require.NotEmpty(t, token)
+
+	for _, tc := range []struct {
+		name     string
+		token    string
+		password string
+		code     int
+		location string
+	}{
+		{"Forged", token + "x", "a brand new password", http.StatusSeeOther, "/login"},
+		{"Bad-Policy", token, "short", http.StatusSeeOther, "/password/reset?token=" + url.QueryEscape(token)},
+		{"Success", token, "a brand new password", http.StatusSeeOther, "/login"},
+		{"Reused", token, "another new password", http.StatusSeeOther, "/login"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			rr := httptest.NewRecorder()
+			req := newFormReq(t, s, "https://example.com/password/reset", url.Values{
+				"password": {tc.password},
+				"token":    {tc.token},
+			})
+
+			// Act
+			p.Reset(rr, req)
+
+			// Assert
+			require.Equal(t, tc.code, rr.Code)
+			require.Equal(t, tc.location, rr.Header().Get("Location"))
+		})
+	}
+
+	require.NoError(t, auth.VerifyPassword(users[42].PasswordHash, "a brand new password"))
+}
@@ -0,0 +1,404 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/xy-planning-network/trails"
+	"github.com/xy-planning-network/trails/http/resp"
+	"github.com/xy-planning-network/trails/http/session"
+)
+
+const (
+	// GoogleIssuer identifies Google as an OpenID Connect provider.
+	GoogleIssuer = "https://accounts.google.com"
+
+	// oidcFlowKey stashes the state, nonce, and PKCE code verifier of a login in progress in a session.
+	oidcFlowKey trails.Key = "OIDCFlowKey"
+
+	// oidcLeeway tolerates clock skew between the app and an OpenID Connect provider.
+	oidcLeeway = time.Minute
+
+	// oidcTimeout bounds requests to an OpenID Connect provider.
+	oidcTimeout = 10 * time.Second
+)
+
+// MicrosoftIssuer identifies the Microsoft Entra ID tenant, by its ID, as an OpenID Connect provider.
+//
+// NOTE: the "common" and "organizations" tenants do not issue tokens under their own issuer,
+// so cannot be used.
+func MicrosoftIssuer(tenant string) string {
+	return "https://login.microsoftonline.com/" + tenant + "/v2.0"
+}
+
+// OIDCConfig configures an OIDC.
+type OIDCConfig struct {
+	// Client sends requests to the provider.
+	//
+	// If Client is nil, a client timing out after 10 seconds is used.
+	Client *http.Client
+
+	// ClientID identifies the app to the provider.
+	ClientID string
+
+	// ClientSecret authenticates the app to the provider, if it issued one.
+	ClientSecret string
+
+	// Issuer identifies the provider, e.g., GoogleIssuer.
+	// The provider's configuration is discovered at Issuer + "/.well-known/openid-configuration".
+	Issuer string
+
+	// Provisioner maps the Identity the provider authenticates to an app user.
+	Provisioner UserProvisioner
+
+	// RedirectURL is the absolute URL the provider sends users back to, served by [OIDC.Callback].
+	// It must be registered with the provider.
+	RedirectURL string
+
+	// Responder redirects users to and from the provider and retrieves their session.Session.
+	Responder *resp.Responder
+
+	// Scopes are requested of the provider in addition to "openid".
+	//
+	// If Scopes is empty, "email" and "profile" are requested.
+	Scopes []string
+}
+
+// An Identity is a user authenticated by an OpenID Connect provider.
+type Identity struct {
+	// Claims are all the claims the provider's ID token makes about the user.
+	Claims map[string]any
+
+	// Email is the user's email address, if the provider shares it.
+	Email string
+
+	// EmailVerified is whether the provider verified the user owns Email.
+	EmailVerified bool
+
+	// Issuer identifies the provider.
+	Issuer string
+
+	// Name is the user's full name, if the provider shares it.
+	Name string
+
+	// Subject identifies the user to the provider, never changing nor being reassigned.
+	// Issuer and Subject together uniquely identify a user.
+	Subject string
+}
+
+// A UserProvisioner maps an Identity to the ID of an app user, creating the user if need be.
+//
+// An error returned by a UserProvisioner, e.g., because the user may not access the app,
+// fails the login.
+type UserProvisioner interface {
+	ProvisionUser(ctx context.Context, id Identity) (uint, error)
+}
+
+// A UserProvisionerFunc is a func implementing UserProvisioner.
+type UserProvisionerFunc func(ctx context.Context, id Identity) (uint, error)
+
+// ProvisionUser calls fn.
+func (fn UserProvisionerFunc) ProvisionUser(ctx context.Context, id Identity) (uint, error) {
+	return fn(ctx, id)
+}
+
+// An OIDC logs users in with an OpenID Connect provider
+// using the authorization code flow with PKCE; cf. https://openid.net/specs/openid-connect-core-1_0.html.
+type OIDC struct {
+	authURL  *url.URL
+	cfg      OIDCConfig
+	keys     *keySet
+	tokenURL string
+}
+
+// NewOIDC constructs an *OIDC from cfg, discovering the provider's configuration.
+//
+// NewOIDC returns trails.ErrBadConfig if cfg is missing values
+// or the provider's configuration does not match cfg.
+func NewOIDC(ctx context.Context, cfg OIDCConfig) (*OIDC, error) {
+	for _, field := range []struct {
+		name    string
+		missing bool
+	}{
+		{"ClientID", cfg.ClientID == ""},
+		{"Issuer", cfg.Issuer == ""},
+		{"Provisioner", cfg.Provisioner == nil},
+		{"RedirectURL", cfg.RedirectURL == ""},
+		{"Responder", cfg.Responder == nil},
+	} {
+		if field.missing {
+			return nil, fmt.Errorf("%w: missing %s", trails.ErrBadConfig, field.name)
+		}
+	}
+
+	if cfg.Client == nil {
+		cfg.Client = &http.Client{Timeout: oidcTimeout}
+	}
+
+	if len(cfg.Scopes) == 0 {
+		cfg.Scopes = []string{"email", "profile"}
+	}
+
+	if !slices.Contains(cfg.Scopes, "openid") {
+		cfg.Scopes = slices.Concat([]string{"openid"}, cfg.Scopes)
+	}
+
+	var doc struct {
+		AuthorizationEndpoint string `json:"authorization_endpoint"`
+		Issuer                string `json:"issuer"`
+		JWKSURI               string `json:"jwks_uri"`
+		TokenEndpoint         string `json:"token_endpoint"`
+	}
+	if err := getJSON(ctx, cfg.Client, strings.TrimSuffix(cfg.Issuer, "/")+"/.well-known/openid-configuration", &doc); err != nil {
+		return nil, fmt.Errorf("could not discover %s: %w", cfg.Issuer, err)
+	}
+
+	if doc.Issuer != cfg.Issuer {
+		return nil, fmt.Errorf("%w: %s identifies itself as %q", trails.ErrBadConfig, cfg.Issuer, doc.Issuer)
+	}
+
+	authURL, err := url.Parse(doc.AuthorizationEndpoint)
+	if err != nil || doc.AuthorizationEndpoint == "" || doc.JWKSURI == "" || doc.TokenEndpoint == "" {
+		return nil, fmt.Errorf("%w: %s is missing endpoints", trails.ErrBadConfig, cfg.Issuer)
+	}
+
+	o := &OIDC{
+		authURL:  authURL,
+		cfg:      cfg,
+		keys:     &keySet{client: cfg.Client, url: doc.JWKSURI},
+		tokenURL: doc.TokenEndpoint,
+	}
+
+	return o, nil
+}
+
+// Login redirects the user to the provider to authenticate,
+// storing what Callback checks the provider's response against in their session.Session.
+func (o *OIDC) Login(w http.ResponseWriter, r *http.Request) {
+	s, err := o.cfg.Responder.Session(r.Context())
+	if err != nil {
+		o.cfg.Responder.Err(w, r, err)
+		return
+	}
+
+	var flow [3]string
+	for i := range flow {
+		if flow[i], err = randomString(); err != nil {
+			o.cfg.Responder.Err(w, r, err)
+			return
+		}
+	}
+	state, nonce, verifier := flow[0], flow[1], flow[2]
+
+	if err := s.Set(w, r, oidcFlowKey, strings.Join(flow[:], " ")); err != nil {
+		o.cfg.Responder.Err(w, r, err)
+		return
+	}
+
+	challenge := sha256.Sum256([]byte(verifier))
+
+	u := *o.authURL
+	q := u.Query()
+	q.Set("client_id", o.cfg.ClientID)
+	q.Set("code_challenge", base64.RawURLEncoding.EncodeToString(challenge[:]))
+	q.Set("code_challenge_method", "S256")
+	q.Set("nonce", nonce)
+	q.Set("redirect_uri", o.cfg.RedirectURL)
+	q.Set("response_type", "code")
+	q.Set("scope", strings.Join(o.cfg.Scopes, " "))
+	q.Set("state", state)
+	u.RawQuery = q.Encode()
+
+	o.cfg.Responder.Redirect(w, r, resp.Url(u.String()))
+}
+
+// Callback completes a login begun by Login once the provider sends the user back,
+// verifying the ID token the provider issues for them, provisioning the user it identifies,
+// and registering them in their session.Session; cf. [session.Session.RegisterUser].
+// Callback then redirects the user to the URL set with [session.Session.SetReturnTo], if any,
+// or the root URL of the Responder.
+//
+// If any step fails, Callback redirects to the root URL of the Responder with an error flash.
+func (o *OIDC) Callback(w http.ResponseWriter, r *http.Request) {
+	s, err := o.cfg.Responder.Session(r.Context())
+	if err != nil {
+		o.cfg.Responder.Err(w, r, err)
+		return
+	}
+
+	id, err := o.authenticate(w, r, s)
+	if err != nil {
+		o.fail(w, r, err)
+		return
+	}
+
+	uid, err := o.cfg.Provisioner.ProvisionUser(r.Context(), id)
+	if err != nil {
+		o.fail(w, r, fmt.Errorf("could not provision %s user %s: %w", id.Issuer, id.Subject, err))
+		return
+	}
+
+	if err := s.RegisterUser(w, r, uid); err != nil {
+		o.cfg.Responder.Err(w, r, err)
+		return
+	}
+
+	var opts []resp.Fn
+	if returnTo, err := s.ReturnTo(); err == nil && returnTo != "" {
+		opts = append(opts, resp.Url(returnTo))
+	}
+
+	o.cfg.Responder.Redirect(w, r, opts...)
+}
+
+// authenticate checks the provider's response to the login in progress in s,
+// exchanging the authorization code for an ID token and verifying it.
+func (o *OIDC) authenticate(w http.ResponseWriter, r *http.Request, s session.Session) (Identity, error) {
+	raw, _ := session.Get[string](s, oidcFlowKey)
+	flow := strings.Fields(raw)
+	if len(flow) != 3 {
+		return Identity{}, fmt.Errorf("%w: no login in progress", trails.ErrNotValid)
+	}
+	state, nonce, verifier := flow[0], flow[1], flow[2]
+
+	// NOTE: a login is attempted once; it must begin again at Login.
+	if err := s.Set(w, r, oidcFlowKey, ""); err != nil {
+		return Identity{}, err
+	}
+
+	q := r.URL.Query()
+	if e := q.Get("error"); e != "" {
+		return Identity{}, fmt.Errorf("%w: %s responded %s: %s", trails.ErrNotValid, o.cfg.Issuer, e, q.Get("error_description"))
+	}
+
+	if subtle.ConstantTimeCompare([]byte(q.Get("state")), []byte(state)) != 1 {
+		return Identity{}, fmt.Errorf("%w: state does not match", trails.ErrNotValid)
+	}
+
+	code := q.Get("code")
+	if code == "" {
+		return Identity{}, fmt.Errorf("%w: no authorization code", trails.ErrMissingData)
+	}
+
+	form := url.Values{
+		"client_id":     {o.cfg.ClientID},
+		"code":          {code},
+		"code_verifier": {verifier},
+		"grant_type":    {"authorization_code"},
+		"redirect_uri":  {o.cfg.RedirectURL},
+	}
+	req, err := http.NewRequestWithContext(r.Context(), http.MethodPost, o.tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return Identity{}, err
+	}
+
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if o.cfg.ClientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(o.cfg.ClientID), url.QueryEscape(o.cfg.ClientSecret))
+	}
+
+	var tokens struct {
+		IDToken string `json:"id_token"`
+	}
+	if err := doJSON(o.cfg.Client, req, &tokens); err != nil {
+		return Identity{}, fmt.Errorf("could not exchange authorization code: %w", err)
+	}
+
+	t, err := o.verify(r.Context(), tokens.IDToken)
+	if err != nil {
+		return Identity{}, err
+	}
+
+	if subtle.ConstantTimeCompare([]byte(t.string("nonce")), []byte(nonce)) != 1 {
+		return Identity{}, fmt.Errorf("%w: nonce does not match", trails.ErrNotValid)
+	}
+
+	return identityOf(t), nil
+}
+
+// verify parses and verifies raw as an ID token the provider issued for the app.
+func (o *OIDC) verify(ctx context.Context, raw string) (jwt, error) {
+	t, err := parseJWT(raw)
+	if err != nil {
+		return t, err
+	}
+
+	key, err := o.keys.key(ctx, t.header.Kid)
+	if err != nil {
+		return t, err
+	}
+
+	if err := t.verify(key); err != nil {
+		return t, err
+	}
+
+	if iss := t.string("iss"); iss != o.cfg.Issuer {
+		return t, fmt.Errorf("%w: token issued by %q", trails.ErrNotValid, iss)
+	}
+
+	aud := t.audience()
+	if !slices.Contains(aud, o.cfg.ClientID) {
+		return t, fmt.Errorf("%w: token not issued for %q", trails.ErrNotValid, o.cfg.ClientID)
+	}
+
+	if azp := t.string("azp"); len(aud) > 1 && azp != o.cfg.ClientID {
+		return t, fmt.Errorf("%w: token authorized for %q", trails.ErrNotValid, azp)
+	}
+
+	if t.string("sub") == "" {
+		return t, fmt.Errorf("%w: token has no subject", trails.ErrMissingData)
+	}
+
+	if err := t.validAt(time.Now(), oidcLeeway); err != nil {
+		return t, err
+	}
+
+	return t, nil
+}
+
+// identityOf describes the user the verified ID token t identifies.
+func identityOf(t jwt) Identity {
+	id := Identity{
+		Claims:  t.claims,
+		Email:   t.string("email"),
+		Issuer:  t.string("iss"),
+		Name:    t.string("name"),
+		Subject: t.string("sub"),
+	}
+
+	switch verified := t.claims["email_verified"].(type) {
+	case bool:
+		id.EmailVerified = verified
+	case string:
+		id.EmailVerified = verified == "true"
+	}
+
+	return id
+}
+
+// fail redirects the user to the root URL of the Responder with an error flash, logging err.
+func (o *OIDC) fail(w http.ResponseWriter, r *http.Request, err error) {
+	if rerr := o.cfg.Responder.Redirect(w, r, resp.GenericErr(err), resp.Code(http.StatusUnauthorized)); rerr != nil {
+		o.cfg.Responder.Err(w, r, err)
+	}
+}
+
+// randomString generates a random, URL-safe string of 32 bytes.
+func randomString() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,249 @@
+package auth_test
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/xy-planning-network/trails"
+	"github.com/xy-planning-network/trails/auth"
+	"github.com/xy-planning-network/trails/http/resp"
+	"github.com/xy-planning-network/trails/http/session"
+)
+
+// A testProvider is an OpenID Connect provider issuing ID tokens with claims for the code "code".
+type testProvider struct {
+	*httptest.Server
+	challenge string
+	claims    map[string]any
+	key       *rsa.PrivateKey
+}
+
+func newTestProvider(t *testing.T) *testProvider {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	p := &testProvider{key: key}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /.well-known/openid-configuration", func(w http.ResponseWriter, _ *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"authorization_endpoint": p.URL + "/authorize?prompt=select_account",
+			"issuer":                 p.URL,
+			"jwks_uri":               p.URL + "/keys",
+			"token_endpoint":         p.URL + "/token",
+		})
+	})
+	mux.HandleFunc("GET /keys", func(w http.ResponseWriter, _ *http.Request) {
+		json.NewEncoder(w).Encode(map[string]any{"keys": []map[string]string{{
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			"kid": "key-1",
+			"kty": "RSA",
+			"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			"use": "sig",
+		}}})
+	})
+	mux.HandleFunc("POST /token", func(w http.ResponseWriter, r *http.Request) {
+		verifier := sha256.Sum256([]byte(r.PostFormValue("code_verifier")))
+		id, secret, _ := r.BasicAuth()
+		if r.PostFormValue("code") != "code" ||
+			base64.RawURLEncoding.EncodeToString(verifier[:]) != p.challenge ||
+			id != "client" || secret != "secret" {
+			http.Error(w, `{"error":"invalid_grant"}`, http.StatusBadRequest)
+			return
+		}
+
+		json.NewEncoder(w).Encode(map[string]string{"id_token": p.sign(t, p.claims)})
+	})
+	p.Server = httptest.NewServer(mux)
+	t.Cleanup(p.Close)
+
+	return p
+}
+
+func (p *testProvider) sign(t *testing.T, claims map[string]any) string {
+	enc := func(v any) string {
+		b, err := json.Marshal(v)
+		require.NoError(t, err)
+
+		return base64.RawURLEncoding.EncodeToString(b)
+	}
+
+	signed := enc(map[string]string{"alg": "RS256", "kid": "key-1"}) + "." + enc(claims)
+	digest := sha256.Sum256([]byte(signed))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, p.key, crypto.SHA256, digest[:])
+	require.NoError(t, err)
+
+	return signed + "." + base64.RawURLEncoding.EncodeToString(sig)
+}
+
+func TestNewOIDC(t *testing.T) {
+	// Arrange
+	p := newTestProvider(t)
+	cfg := auth.OIDCConfig{
+		ClientID:    "client",
+		Issuer:      p.URL,
+		RedirectURL: "https://example.com/callback",
+		Responder:   resp.NewResponder(),
+	}
+
+	// Act
+	_, err := auth.NewOIDC(context.Background(), cfg)
+
+	// Assert
+	require.ErrorIs(t, err, trails.ErrBadConfig)
+	require.ErrorContains(t, err, "Provisioner")
+
+	// Arrange
+	cfg.Provisioner = auth.UserProvisionerFunc(func(context.Context, auth.Identity) (uint, error) { return 1, nil })
+	cfg.Issuer = p.URL + "/"
+
+	// Act
+	_, err = auth.NewOIDC(context.Background(), cfg)
+
+	// Assert
+	require.ErrorIs(t, err, trails.ErrBadConfig)
+
+	// Arrange
+	cfg.Issuer = p.URL
+
+	// Act
+	_, err = auth.NewOIDC(context.Background(), cfg)
+
+	// Assert
+	require.NoError(t, err)
+}
+
+func TestOIDC(t *testing.T) {
+	p := newTestProvider(t)
+
+	var provisioned auth.Identity
+	oidc, err := auth.NewOIDC(context.Background(), auth.OIDCConfig{
+		ClientID:     "client",
+		ClientSecret: "secret",
+		Issuer:       p.URL,
+		Provisioner: auth.UserProvisionerFunc(func(_ context.Context, id auth.Identity) (uint, error) {
+			if id.Email == "banned@example.com" {
+				return 0, errors.New("banned")
+			}
+
+			provisioned = id
+			return 42, nil
+		}),
+		RedirectURL: "https://example.com/callback",
+		Responder:   resp.NewResponder(resp.WithRootUrl("https://example.com/")),
+	})
+	require.NoError(t, err)
+
+	for _, tc := range []struct {
+		name   string
+		claims func(claims map[string]any)
+		state  func(state string) string
+		ok     bool
+	}{
+		{"Success", nil, nil, true},
+		{"Bad-State", nil, func(string) string { return "forged" }, false},
+		{"Bad-Nonce", func(c map[string]any) { c["nonce"] = "replayed" }, nil, false},
+		{"Bad-Audience", func(c map[string]any) { c["aud"] = "other-client" }, nil, false},
+		{"Bad-Issuer", func(c map[string]any) { c["iss"] = "https://evil.example.com" }, nil, false},
+		{"Expired", func(c map[string]any) { c["exp"] = time.Now().Add(-time.Hour).Unix() }, nil, false},
+		{"Provisioner-Err", func(c map[string]any) { c["email"] = "banned@example.com" }, nil, false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			provisioned = auth.Identity{}
+			s, err := session.NewStub(false).GetSession(nil)
+			require.NoError(t, err)
+
+			newReq := func(target string) *http.Request {
+				req := httptest.NewRequest(http.MethodGet, target, nil)
+				return req.WithContext(context.WithValue(req.Context(), trails.SessionKey, s))
+			}
+
+			rr := httptest.NewRecorder()
+
+			// Act
+			oidc.Login(rr, newReq("https://example.com/login"))
+
+			// Assert
+			require.Equal(t, http.StatusFound, rr.Code)
+
+			loc, err := url.Parse(rr.Header().Get("Location"))
+			require.NoError(t, err)
+			require.Equal(t, p.URL+"/authorize", loc.Scheme+"://"+loc.Host+loc.Path)
+
+			q := loc.Query()
+			require.Equal(t, "client", q.Get("client_id"))
+			require.Equal(t, "S256", q.Get("code_challenge_method"))
+			require.Equal(t, "select_account", q.Get("prompt"))
+			require.Equal(t, "https://example.com/callback", q.Get("redirect_uri"))
+			require.Equal(t, "openid email profile", q.Get("scope"))
+
+			// Arrange
+			p.challenge = q.Get("code_challenge")
+			p.claims = map[string]any{
+				"aud":            "client",
+				"email":          "user@example.com",
+				"email_verified": true,
+				"exp":            time.Now().Add(time.Hour).Unix(),
+				"iat":            time.Now().Unix(),
+				"iss":            p.URL,
+				"nonce":          q.Get("nonce"),
+				"sub":            "user-1",
+			}
+			if tc.claims != nil {
+				tc.claims(p.claims)
+			}
+
+			state := q.Get("state")
+			if tc.state != nil {
+				state = tc.state(state)
+			}
+
+			rr = httptest.NewRecorder()
+
+			// Act
+			oidc.Callback(rr, newReq("https://example.com/callback?code=code&state="+state))
+
+			// Assert
+			uid, err := s.UserID()
+			if !tc.ok {
+				require.Equal(t, http.StatusSeeOther, rr.Code)
+				require.ErrorIs(t, err, session.ErrNoUser)
+				require.Empty(t, provisioned.Subject)
+				return
+			}
+
+			require.Equal(t, http.StatusFound, rr.Code)
+			require.NoError(t, err)
+			require.Equal(t, uint(42), uid)
+			require.Equal(t, auth.Identity{
+				Claims:        provisioned.Claims,
+				Email:         "user@example.com",
+				EmailVerified: true,
+				Issuer:        p.URL,
+				Subject:       "user-1",
+			}, provisioned)
+
+			// Arrange
+			rr = httptest.NewRecorder()
+
+			// Act
+			oidc.Callback(rr, newReq("https://example.com/callback?code=code&state="+state))
+
+			// Assert
+			require.Equal(t, http.StatusSeeOther, rr.Code)
+		})
+	}
+}
@@ -0,0 +1,201 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/xy-planning-network/trails"
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Argon2id parameters, following the second recommendation of RFC 9106.
+const (
+	argon2KeyLen  = 32
+	argon2Memory  = 64 * 1024
+	argon2SaltLen = 16
+	argon2Threads = 4
+	argon2Time    = 3
+)
+
+// Password policy defaults, following NIST SP 800-63B.
+const (
+	defaultPasswordMaxLength = 128
+	defaultPasswordMinLength = 12
+)
+
+// HashPassword hashes password with argon2id, using a random salt,
+// encoding the hash, salt, and parameters in the PHC string format, e.g.:
+//
+//	$argon2id$v=19$m=65536,t=3,p=4$c2FsdHNhbHRzYWx0c2FsdA$aGFzaGhhc2hoYXNoaGFzaGhhc2hoYXNoaGFzaGhhc2g
+func HashPassword(password string) (string, error) {
+	salt := make([]byte, argon2SaltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+
+	key := argon2.IDKey([]byte(password), salt, argon2Time, argon2Memory, argon2Threads, argon2KeyLen)
+	return fmt.Sprintf(
+		"$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version, argon2Memory, argon2Time, argon2Threads,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	), nil
+}
+
+// HashPasswordBcrypt hashes password with bcrypt at cost, e.g., bcrypt.DefaultCost,
+// for apps sharing hashes with systems only verifying bcrypt.
+//
+// bcrypt only hashes the first 72 bytes of a password,
+// returning bcrypt.ErrPasswordTooLong for longer ones.
+func HashPasswordBcrypt(password string, cost int) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), cost)
+	if err != nil {
+		return "", err
+	}
+
+	return string(hash), nil
+}
+
+// VerifyPassword checks, in constant time, that password is the one hashed as hash,
+// whether by HashPassword or HashPasswordBcrypt,
+// returning ErrBadCredentials if it is not.
+//
+// VerifyPassword returns trails.ErrNotValid if hash cannot be parsed.
+func VerifyPassword(hash, password string) error {
+	if !strings.HasPrefix(hash, "$argon2id$") {
+		err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
+		switch {
+		case errors.Is(err, bcrypt.ErrMismatchedHashAndPassword):
+			return ErrBadCredentials
+		case err != nil:
+			return fmt.Errorf("%w: password hash: %s", trails.ErrNotValid, err)
+		}
+
+		return nil
+	}
+
+	p, err := parseArgon2id(hash)
+	if err != nil {
+		return err
+	}
+
+	key := argon2.IDKey([]byte(password), p.salt, p.time, p.memory, p.threads, uint32(len(p.key)))
+	if subtle.ConstantTimeCompare(key, p.key) != 1 {
+		return ErrBadCredentials
+	}
+
+	return nil
+}
+
+// NeedsRehash reports whether hash was not made by HashPassword as it hashes passwords now,
+// e.g., because it is a bcrypt hash or argon2id's parameters have since been strengthened.
+// After verifying a password against such a hash, hash the password again and store the new hash.
+func NeedsRehash(hash string) bool {
+	p, err := parseArgon2id(hash)
+	if err != nil {
+		return true
+	}
+
+	return p.memory < argon2Memory || p.time < argon2Time || len(p.key) < argon2KeyLen
+}
+
+// argon2idParams are the parameters an argon2id hash was made with.
+type argon2idParams struct {
+	key     []byte
+	memory  uint32
+	salt    []byte
+	threads uint8
+	time    uint32
+}
+
+// parseArgon2id parses hash as encoded by HashPassword.
+func parseArgon2id(hash string) (argon2idParams, error) {
+	var p argon2idParams
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		return p, fmt.Errorf("%w: password hash is not argon2id", trails.ErrNotValid)
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return p, fmt.Errorf("%w: password hash has unsupported version", trails.ErrNotValid)
+	}
+
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &p.memory, &p.time, &p.threads); err != nil {
+		return p, fmt.Errorf("%w: password hash parameters: %s", trails.ErrNotValid, err)
+	}
+
+	var err error
+	if p.salt, err = base64.RawStdEncoding.DecodeString(parts[4]); err != nil {
+		return p, fmt.Errorf("%w: password hash salt: %s", trails.ErrNotValid, err)
+	}
+
+	if p.key, err = base64.RawStdEncoding.DecodeString(parts[5]); err != nil || len(p.key) == 0 {
+		return p, fmt.Errorf("%w: password hash key", trails.ErrNotValid)
+	}
+
+	return p, nil
+}
+
+// A PasswordPolicy sets which passwords users may choose; cf. NIST SP 800-63B.
+//
+// A PasswordPolicy imposes no composition rules, e.g., requiring digits,
+// since they lead users to predictable passwords.
+type PasswordPolicy struct {
+	// Forbidden are passwords users may not choose, compared case-insensitively,
+	// e.g., commonly used or breached passwords, or the app's name.
+	Forbidden []string
+
+	// MaxLength is the most characters a password may have.
+	//
+	// If MaxLength is zero, 128 is used.
+	MaxLength int
+
+	// MinLength is the fewest characters a password may have.
+	//
+	// If MinLength is zero, 12 is used.
+	MinLength int
+}
+
+// Validate checks password meets the PasswordPolicy and is none of related,
+// e.g., the user's email address, compared case-insensitively,
+// returning trails.ErrNotValid if not.
+func (p PasswordPolicy) Validate(password string, related ...string) error {
+	if problem := p.problem(password, related...); problem != "" {
+		return fmt.Errorf("%w: password %s", trails.ErrNotValid, problem)
+	}
+
+	return nil
+}
+
+// problem describes, for a user, how password does not meet the PasswordPolicy, if it does not.
+func (p PasswordPolicy) problem(password string, related ...string) string {
+	if p.MinLength == 0 {
+		p.MinLength = defaultPasswordMinLength
+	}
+
+	if p.MaxLength == 0 {
+		p.MaxLength = defaultPasswordMaxLength
+	}
+
+	switch n := utf8.RuneCountInString(password); {
+	case n < p.MinLength:
+		return fmt.Sprintf("must be at least %d characters", p.MinLength)
+	case n > p.MaxLength:
+		return fmt.Sprintf("must be at most %d characters", p.MaxLength)
+	}
+
+	for _, forbidden := range append(p.Forbidden, related...) {
+		if strings.EqualFold(password, forbidden) {
+			return "is too easily guessed"
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,92 @@
+package auth_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/xy-planning-network/trails"
+	"github.com/xy-planning-network/trails/auth"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestHashPassword(t *testing.T) {
+	// Arrange
+	password := "correct horse battery staple"
+
+	// Act
+	hash, err := auth.HashPassword(password)
+
+	// Assert
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(hash, "$argon2id$v=19$m=65536,t=3,p=4$"))
+	require.NoError(t, auth.VerifyPassword(hash, password))
+	require.ErrorIs(t, auth.VerifyPassword(hash, "wrong"), auth.ErrBadCredentials)
+	require.False(t, auth.NeedsRehash(hash))
+
+	// Act
+	again, err := auth.HashPassword(password)
+
+	// Assert
+	require.NoError(t, err)
+	require.NotEqual(t, hash, again)
+}
+
+func TestVerifyPassword(t *testing.T) {
+	// Arrange
+	bcryptHash, err := auth.HashPasswordBcrypt("password", bcrypt.MinCost)
+	require.NoError(t, err)
+
+	for _, tc := range []struct {
+		name     string
+		hash     string
+		password string
+		err      error
+	}{
+		{"Bcrypt", bcryptHash, "password", nil},
+		{"Bcrypt-Mismatch", bcryptHash, "wrong", auth.ErrBadCredentials},
+		{"Weak-Argon2id", "$argon2id$v=19$m=1024,t=1,p=1$c2FsdHNhbHQ$Gy7dpQfVW3Zhb8/8U+4BRw", "wrong", auth.ErrBadCredentials},
+		{"Bad-Version", "$argon2id$v=16$m=65536,t=3,p=4$c2FsdHNhbHQ$a2V5", "password", trails.ErrNotValid},
+		{"Bad-Params", "$argon2id$v=19$m=x,t=3,p=4$c2FsdHNhbHQ$a2V5", "password", trails.ErrNotValid},
+		{"Garbage", "not a hash", "password", trails.ErrNotValid},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			// Act
+			err := auth.VerifyPassword(tc.hash, tc.password)
+
+			// Assert
+			require.ErrorIs(t, err, tc.err)
+			require.True(t, auth.NeedsRehash(tc.hash))
+		})
+	}
+}
+
+func TestPasswordPolicyValidate(t *testing.T) {
+	policy := auth.PasswordPolicy{Forbidden: []string{"Password1234"}, MaxLength: 16}
+
+	for _, tc := range []struct {
+		name     string
+		password string
+		ok       bool
+	}{
+		{"Valid", "long enough pass", true},
+		{"Valid-Multibyte", "ééééééééééééé", true},
+		{"Too-Short", "short", false},
+		{"Too-Long", "much too long a passphrase", false},
+		{"Forbidden", "password1234", false},
+		{"Related", "USER@EXAMPLE.COM", false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			// Act
+			err := policy.Validate(tc.password, "user@example.com")
+
+			// Assert
+			if tc.ok {
+				require.NoError(t, err)
+				return
+			}
+
+			require.ErrorIs(t, err, trails.ErrNotValid)
+		})
+	}
+}
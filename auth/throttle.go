@@ -0,0 +1,307 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/xy-planning-network/trails/http/middleware"
+	"github.com/xy-planning-network/trails/logger"
+)
+
+// Throttle defaults.
+const (
+	defaultThrottleBaseDelay     = time.Minute
+	defaultThrottleMaxAttempts   = 5
+	defaultThrottleMaxAttemptsIP = 20
+	defaultThrottleMaxDelay      = time.Hour
+	defaultThrottleWindow        = 24 * time.Hour
+
+	// attemptSweepInterval is how often a MemoryAttemptStore forgets failures older than the window.
+	attemptSweepInterval = time.Minute
+)
+
+// An AttemptStore records failed login attempts under keys, e.g., a user's email address or an IP address.
+type AttemptStore interface {
+	// Fail records a failed attempt under key at t, returning how many failed attempts key now has.
+	// Failed attempts more than window before t are forgotten first.
+	Fail(ctx context.Context, key string, t time.Time, window time.Duration) (int, error)
+
+	// Failures retrieves how many failed attempts key has and when the last one was.
+	Failures(ctx context.Context, key string) (int, time.Time, error)
+
+	// Reset forgets key's failed attempts.
+	Reset(ctx context.Context, key string) error
+}
+
+// A MemoryAttemptStore is an AttemptStore keeping failed attempts in memory,
+// so each process counts separately and forgets them upon restarting.
+//
+// A MemoryAttemptStore is safe for concurrent use.
+type MemoryAttemptStore struct {
+	attempts map[string]attempts
+	mu       sync.Mutex
+	swept    time.Time
+}
+
+// attempts are the failed attempts recorded under a key.
+type attempts struct {
+	count int
+	last  time.Time
+}
+
+// NewMemoryAttemptStore constructs a *MemoryAttemptStore.
+func NewMemoryAttemptStore() *MemoryAttemptStore {
+	return &MemoryAttemptStore{attempts: make(map[string]attempts)}
+}
+
+// Fail records a failed attempt under key at t.
+func (s *MemoryAttemptStore) Fail(_ context.Context, key string, t time.Time, window time.Duration) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if t.Sub(s.swept) >= attemptSweepInterval {
+		for k, a := range s.attempts {
+			if t.Sub(a.last) > window {
+				delete(s.attempts, k)
+			}
+		}
+
+		s.swept = t
+	}
+
+	a := s.attempts[key]
+	if t.Sub(a.last) > window {
+		a.count = 0
+	}
+
+	a.count++
+	a.last = t
+	s.attempts[key] = a
+
+	return a.count, nil
+}
+
+// Failures retrieves the failed attempts recorded under key.
+func (s *MemoryAttemptStore) Failures(_ context.Context, key string) (int, time.Time, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	a := s.attempts[key]
+	return a.count, a.last, nil
+}
+
+// Reset forgets key's failed attempts.
+func (s *MemoryAttemptStore) Reset(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.attempts, key)
+	return nil
+}
+
+// ThrottleConfig configures a Throttle.
+type ThrottleConfig struct {
+	// BaseDelay is how long a user or IP address is locked out once it reaches its maximum failed attempts.
+	// Each further failed attempt doubles the lockout, up to MaxDelay.
+	//
+	// If BaseDelay is zero, a minute is used.
+	BaseDelay time.Duration
+
+	// MaxAttempts is how many failed attempts a user may make before being locked out.
+	//
+	// If MaxAttempts is zero, 5 is used.
+	MaxAttempts int
+
+	// MaxAttemptsIP is how many failed attempts, for any users, an IP address may make before being locked out.
+	// It ought to be higher than MaxAttempts, since many users may share an IP address, e.g., an office's.
+	//
+	// If MaxAttemptsIP is zero, 20 is used.
+	MaxAttemptsIP int
+
+	// MaxDelay is the longest a user or IP address is locked out.
+	//
+	// If MaxDelay is zero, an hour is used.
+	MaxDelay time.Duration
+
+	// Store records failed attempts.
+	// Use a store shared by every process, e.g., backed by the database, to throttle across them.
+	//
+	// If Store is nil, a *MemoryAttemptStore is used.
+	Store AttemptStore
+
+	// Window is how long failed attempts are remembered after the last one.
+	//
+	// If Window is zero, a day is used.
+	Window time.Duration
+}
+
+// A Throttle protects logins from brute-force attacks,
+// tracking failed attempts per user and per IP address
+// and locking either out for exponentially longer after too many.
+type Throttle struct {
+	cfg ThrottleConfig
+}
+
+// NewThrottle constructs a *Throttle from cfg.
+func NewThrottle(cfg ThrottleConfig) *Throttle {
+	if cfg.BaseDelay == 0 {
+		cfg.BaseDelay = defaultThrottleBaseDelay
+	}
+
+	if cfg.MaxAttempts == 0 {
+		cfg.MaxAttempts = defaultThrottleMaxAttempts
+	}
+
+	if cfg.MaxAttemptsIP == 0 {
+		cfg.MaxAttemptsIP = defaultThrottleMaxAttemptsIP
+	}
+
+	if cfg.MaxDelay == 0 {
+		cfg.MaxDelay = defaultThrottleMaxDelay
+	}
+
+	if cfg.Store == nil {
+		cfg.Store = NewMemoryAttemptStore()
+	}
+
+	if cfg.Window == 0 {
+		cfg.Window = defaultThrottleWindow
+	}
+
+	return &Throttle{cfg: cfg}
+}
+
+// Check checks neither user, e.g., the email address a login gives, nor the IP address is locked out,
+// returning ErrLockedOut and how long until the lockout ends if either is.
+func (t *Throttle) Check(ctx context.Context, user, ip string) (time.Duration, error) {
+	now := time.Now()
+
+	var wait time.Duration
+	for _, k := range t.keys(user, ip) {
+		n, last, err := t.cfg.Store.Failures(ctx, k.key)
+		if err != nil {
+			return 0, fmt.Errorf("could not check failed attempts: %w", err)
+		}
+
+		if now.Sub(last) > t.cfg.Window {
+			continue
+		}
+
+		wait = max(wait, last.Add(t.delay(n, k.allowed)).Sub(now))
+	}
+
+	if wait > 0 {
+		return wait, fmt.Errorf("%w: for %s", ErrLockedOut, wait.Round(time.Second))
+	}
+
+	return 0, nil
+}
+
+// Fail records a failed login attempt for user from the IP address,
+// recording a [logger.AuditLoginLockout] event when either becomes locked out.
+func (t *Throttle) Fail(ctx context.Context, user, ip string) error {
+	now := time.Now()
+	for _, k := range t.keys(user, ip) {
+		n, err := t.cfg.Store.Fail(ctx, k.key, now, t.cfg.Window)
+		if err != nil {
+			return fmt.Errorf("could not record failed attempt: %w", err)
+		}
+
+		if delay := t.delay(n, k.allowed); delay > 0 {
+			logger.Audit(logger.AuditEvent{
+				Action:  logger.AuditLoginLockout,
+				Context: ctx,
+				Data: map[string]any{
+					"attempts": n,
+					"key":      k.key,
+					"until":    now.Add(delay).Format(time.RFC3339),
+				},
+				Outcome: logger.AuditFailure,
+			})
+		}
+	}
+
+	return nil
+}
+
+// Succeed forgets the failed login attempts of user, who has now logged in.
+//
+// Succeed does not forget those of the IP address user logged in from,
+// lest an attacker reset them by logging in to an account of their own.
+func (t *Throttle) Succeed(ctx context.Context, user string) error {
+	if err := t.cfg.Store.Reset(ctx, userAttemptsKey(user)); err != nil {
+		return fmt.Errorf("could not reset failed attempts: %w", err)
+	}
+
+	return nil
+}
+
+// Guard responds with 429 to requests from IP addresses that are locked out,
+// e.g., to guard routes posting login forms before they reach their handlers.
+//
+// Requests are attributed to the IP address [middleware.RequestIP] retrieves,
+// which a client cannot change by setting the "X-Forwarded-For" header.
+func (t *Throttle) Guard() middleware.Adapter {
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			wait, err := t.Check(r.Context(), "", middleware.RequestIP(r))
+			if err != nil && wait > 0 {
+				setRetryAfter(w, wait)
+				http.Error(w, http.StatusText(http.StatusTooManyRequests), http.StatusTooManyRequests)
+				return
+			}
+
+			h.ServeHTTP(w, r)
+		})
+	}
+}
+
+// delay is how long n failed attempts lock out a key allowed that many before being locked out.
+func (t *Throttle) delay(n, allowed int) time.Duration {
+	if n < allowed {
+		return 0
+	}
+
+	delay := t.cfg.BaseDelay
+	for i := allowed; i < n && delay < t.cfg.MaxDelay; i++ {
+		delay *= 2
+	}
+
+	return min(delay, t.cfg.MaxDelay)
+}
+
+// attemptsKey is a key failed attempts are recorded under and how many are allowed before locking it out.
+type attemptsKey struct {
+	allowed int
+	key     string
+}
+
+// keys lists the keys failed attempts by user from ip are recorded under, skipping either if empty.
+func (t *Throttle) keys(user, ip string) []attemptsKey {
+	var keys []attemptsKey
+	if user != "" {
+		keys = append(keys, attemptsKey{t.cfg.MaxAttempts, userAttemptsKey(user)})
+	}
+
+	if ip != "" {
+		keys = append(keys, attemptsKey{t.cfg.MaxAttemptsIP, "ip:" + ip})
+	}
+
+	return keys
+}
+
+// userAttemptsKey is the key failed attempts by user are recorded under.
+func userAttemptsKey(user string) string {
+	return "user:" + strings.ToLower(strings.TrimSpace(user))
+}
+
+// setRetryAfter tells the client to wait before trying again, in whole seconds.
+func setRetryAfter(w http.ResponseWriter, wait time.Duration) {
+	w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+}
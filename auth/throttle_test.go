@@ -0,0 +1,161 @@
+package auth_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/xy-planning-network/trails/auth"
+	"github.com/xy-planning-network/trails/http/middleware"
+	"github.com/xy-planning-network/trails/http/resp"
+	"github.com/xy-planning-network/trails/http/session"
+)
+
+func TestMemoryAttemptStore(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	s := auth.NewMemoryAttemptStore()
+	start := time.Now()
+
+	// Act
+	for i := range 3 {
+		_, err := s.Fail(ctx, "user:a", start.Add(time.Duration(i)*time.Second), time.Hour)
+		require.NoError(t, err)
+	}
+	n, last, err := s.Failures(ctx, "user:a")
+
+	// Assert
+	require.NoError(t, err)
+	require.Equal(t, 3, n)
+	require.Equal(t, start.Add(2*time.Second), last)
+
+	// Act
+	n, err = s.Fail(ctx, "user:a", start.Add(2*time.Hour), time.Hour)
+
+	// Assert
+	require.NoError(t, err)
+	require.Equal(t, 1, n)
+
+	// Act
+	require.NoError(t, s.Reset(ctx, "user:a"))
+	n, _, err = s.Failures(ctx, "user:a")
+
+	// Assert
+	require.NoError(t, err)
+	require.Zero(t, n)
+}
+
+func TestThrottle(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	th := auth.NewThrottle(auth.ThrottleConfig{BaseDelay: time.Minute, MaxAttempts: 3, MaxAttemptsIP: 5, MaxDelay: 3 * time.Minute})
+
+	// Act
+	for range 2 {
+		require.NoError(t, th.Fail(ctx, "User@example.com", "10.0.0.1"))
+	}
+	_, err := th.Check(ctx, "user@example.com", "10.0.0.1")
+
+	// Assert
+	require.NoError(t, err)
+
+	// Act
+	require.NoError(t, th.Fail(ctx, "user@example.com", "10.0.0.1"))
+	wait, err := th.Check(ctx, "user@example.com", "10.0.0.2")
+
+	// Assert
+	require.ErrorIs(t, err, auth.ErrLockedOut)
+	require.InDelta(t, time.Minute, wait, float64(time.Second))
+
+	// Act
+	require.NoError(t, th.Fail(ctx, "user@example.com", "10.0.0.1"))
+	wait, err = th.Check(ctx, "user@example.com", "")
+
+	// Assert
+	require.ErrorIs(t, err, auth.ErrLockedOut)
+	require.InDelta(t, 2*time.Minute, wait, float64(time.Second))
+
+	// Act
+	for range 5 {
+		require.NoError(t, th.Fail(ctx, "user@example.com", "10.0.0.1"))
+	}
+	wait, err = th.Check(ctx, "other@example.com", "10.0.0.1")
+
+	// Assert
+	require.ErrorIs(t, err, auth.ErrLockedOut)
+	require.InDelta(t, 3*time.Minute, wait, float64(time.Second))
+
+	// Act
+	require.NoError(t, th.Succeed(ctx, "user@example.com"))
+	_, err = th.Check(ctx, "user@example.com", "10.0.0.2")
+
+	// Assert
+	require.NoError(t, err)
+
+	// Arrange
+	rr := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/login", nil)
+	req.RemoteAddr = "10.0.0.1:1234"
+	req.Header.Set("X-Forwarded-For", "10.0.0.3")
+
+	// Act
+	middleware.InjectIPAddress()(th.Guard()(http.NotFoundHandler())).ServeHTTP(rr, req)
+
+	// Assert
+	require.Equal(t, http.StatusTooManyRequests, rr.Code)
+	require.Equal(t, "180", rr.Header().Get("Retry-After"))
+}
+
+func TestPasswordsLoginThrottled(t *testing.T) {
+	// Arrange
+	hash, err := auth.HashPassword("correct horse battery staple")
+	require.NoError(t, err)
+
+	p, err := auth.NewPasswords(auth.PasswordsConfig{
+		Responder: resp.NewResponder(resp.WithRootUrl("https://example.com/")),
+		Throttle:  auth.NewThrottle(auth.ThrottleConfig{MaxAttempts: 2}),
+		Users:     testUsers{42: {Email: "user@example.com", ID: 42, PasswordHash: hash}},
+	})
+	require.NoError(t, err)
+
+	s, err := session.NewStub(false).GetSession(nil)
+	require.NoError(t, err)
+
+	for _, tc := range []struct {
+		name     string
+		password string
+		code     int
+	}{
+		{"First-Failure", "wrong", http.StatusSeeOther},
+		{"Second-Failure", "wrong", http.StatusSeeOther},
+		{"Locked-Out", "correct horse battery staple", http.StatusSeeOther},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			rr := httptest.NewRecorder()
+			req := newFormReq(t, s, "https://example.com/login", url.Values{
+				"email":    {"user@example.com"},
+				"password": {tc.password},
+			})
+
+			// Act
+			p.Login(rr, req)
+
+			// Assert
+			require.Equal(t, tc.code, rr.Code)
+			_, err := s.UserID()
+			require.ErrorIs(t, err, session.ErrNoUser)
+
+			flashes := s.Flashes(rr, req)
+			require.Len(t, flashes, 1)
+			if tc.name == "Locked-Out" {
+				require.Equal(t, auth.LockedOutMsg, flashes[0].Msg)
+				require.Equal(t, "60", rr.Header().Get("Retry-After"))
+			}
+		})
+	}
+}
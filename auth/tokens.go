@@ -0,0 +1,376 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/xy-planning-network/trails"
+	"github.com/xy-planning-network/trails/http/middleware"
+)
+
+const (
+	// claimsKey stashes the Claims of the JWT authenticating an HTTP request.
+	claimsKey trails.Key = "ClaimsKey"
+
+	// defaultJWTLeeway tolerates clock skew between apps issuing and verifying tokens.
+	defaultJWTLeeway = time.Minute
+
+	// defaultJWTTTL is how long tokens a JWT issues last.
+	defaultJWTTTL = time.Hour
+
+	// minHMACKeyLen is the fewest bytes of a secret signing tokens with HMAC-SHA256.
+	minHMACKeyLen = 32
+)
+
+// A SigningKey signs or verifies JSON Web Tokens.
+type SigningKey struct {
+	// ID identifies the key in the "kid" header of the tokens it signs.
+	ID string
+
+	// Key is one of:
+	//   - a []byte secret of at least 32 bytes, signing and verifying with HMAC-SHA256
+	//   - an *rsa.PrivateKey, signing and verifying with RSASSA-PKCS1-v1_5 and SHA-256
+	//   - an *ecdsa.PrivateKey, signing and verifying with ECDSA on its curve, i.e., ES256, ES384, or ES512
+	//   - an *rsa.PublicKey or *ecdsa.PublicKey, only verifying
+	Key any
+}
+
+// ParseSigningKey parses the PEM-encoded RSA or ECDSA key in pemBytes,
+// whether a private key in PKCS #1, PKCS #8, or SEC 1 form or a public key in PKIX form,
+// as a SigningKey identified by id.
+//
+// ParseSigningKey returns trails.ErrNotValid if pemBytes holds no such key.
+func ParseSigningKey(id string, pemBytes []byte) (SigningKey, error) {
+	block, _ := pem.Decode(pemBytes)
+	if block == nil {
+		return SigningKey{}, fmt.Errorf("%w: key %q is not PEM-encoded", trails.ErrNotValid, id)
+	}
+
+	var (
+		key any
+		err error
+	)
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		key, err = x509.ParseECPrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		key, err = x509.ParsePKIXPublicKey(block.Bytes)
+	default:
+		return SigningKey{}, fmt.Errorf("%w: key %q has unsupported PEM type %q", trails.ErrNotValid, id, block.Type)
+	}
+
+	if err != nil {
+		return SigningKey{}, fmt.Errorf("%w: key %q: %s", trails.ErrNotValid, id, err)
+	}
+
+	k := SigningKey{ID: id, Key: key}
+	if _, err := k.alg(); err != nil {
+		return SigningKey{}, err
+	}
+
+	return k, nil
+}
+
+// alg names the algorithm k signs and verifies with.
+func (k SigningKey) alg() (string, error) {
+	curveAlg := func(c elliptic.Curve) (string, error) {
+		switch c {
+		case elliptic.P256():
+			return "ES256", nil
+		case elliptic.P384():
+			return "ES384", nil
+		case elliptic.P521():
+			return "ES512", nil
+		}
+
+		return "", fmt.Errorf("%w: key %q has unsupported curve", trails.ErrNotValid, k.ID)
+	}
+
+	switch key := k.Key.(type) {
+	case []byte:
+		if len(key) < minHMACKeyLen {
+			return "", fmt.Errorf("%w: key %q is shorter than %d bytes", trails.ErrNotValid, k.ID, minHMACKeyLen)
+		}
+
+		return "HS256", nil
+	case *rsa.PrivateKey, *rsa.PublicKey:
+		return "RS256", nil
+	case *ecdsa.PrivateKey:
+		return curveAlg(key.Curve)
+	case *ecdsa.PublicKey:
+		return curveAlg(key.Curve)
+	}
+
+	return "", fmt.Errorf("%w: key %q is an unsupported %T", trails.ErrNotValid, k.ID, k.Key)
+}
+
+// public is the key verifying tokens k signs.
+func (k SigningKey) public() crypto.PublicKey {
+	switch key := k.Key.(type) {
+	case *rsa.PrivateKey:
+		return &key.PublicKey
+	case *ecdsa.PrivateKey:
+		return &key.PublicKey
+	}
+
+	return k.Key
+}
+
+// JWTConfig configures a JWT.
+type JWTConfig struct {
+	// Audience identifies the apps tokens are intended for.
+	// Tokens are issued for Audience and must be issued for it to verify.
+	Audience string
+
+	// Issuer identifies the app issuing tokens.
+	// Tokens are issued by Issuer and must be issued by it to verify.
+	Issuer string
+
+	// Keys sign and verify tokens.
+	// The first key signs tokens; every key verifies those whose "kid" header identifies it.
+	// To rotate keys, list the new key first and keep the old one until the tokens it signed expire.
+	Keys []SigningKey
+
+	// Leeway tolerates clock skew between apps issuing and verifying tokens.
+	//
+	// If Leeway is zero, a minute is used.
+	Leeway time.Duration
+
+	// TTL is how long tokens last.
+	//
+	// If TTL is zero, an hour is used.
+	TTL time.Duration
+}
+
+// Claims are what a JSON Web Token asserts; cf. RFC 7519, section 4.
+type Claims struct {
+	// Audience identifies the apps the token is intended for.
+	Audience []string
+
+	// ExpiresAt is when the token stops being valid.
+	ExpiresAt time.Time
+
+	// Extra are claims other than the registered ones.
+	Extra map[string]any
+
+	// ID uniquely identifies the token.
+	ID string
+
+	// IssuedAt is when the token was issued.
+	IssuedAt time.Time
+
+	// Issuer identifies the app issuing the token.
+	Issuer string
+
+	// Subject identifies who the token is about, e.g., a user's ID.
+	Subject string
+}
+
+// A JWT issues and verifies JSON Web Tokens, e.g., for apps to authenticate to one another.
+type JWT struct {
+	algs map[string]string
+	cfg  JWTConfig
+}
+
+// NewJWT constructs a *JWT from cfg.
+//
+// NewJWT returns trails.ErrBadConfig if cfg is missing values or a key is not valid.
+func NewJWT(cfg JWTConfig) (*JWT, error) {
+	for _, field := range []struct {
+		name    string
+		missing bool
+	}{
+		{"Audience", cfg.Audience == ""},
+		{"Issuer", cfg.Issuer == ""},
+		{"Keys", len(cfg.Keys) == 0},
+	} {
+		if field.missing {
+			return nil, fmt.Errorf("%w: missing %s", trails.ErrBadConfig, field.name)
+		}
+	}
+
+	if cfg.Leeway == 0 {
+		cfg.Leeway = defaultJWTLeeway
+	}
+
+	if cfg.TTL == 0 {
+		cfg.TTL = defaultJWTTTL
+	}
+
+	j := &JWT{algs: make(map[string]string), cfg: cfg}
+	for _, k := range cfg.Keys {
+		alg, err := k.alg()
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", trails.ErrBadConfig, err)
+		}
+
+		if _, ok := j.algs[k.ID]; ok {
+			return nil, fmt.Errorf("%w: duplicate key %q", trails.ErrBadConfig, k.ID)
+		}
+
+		j.algs[k.ID] = alg
+	}
+
+	return j, nil
+}
+
+// Issue issues a token about subject lasting the TTL, signed by the first of the Keys.
+// Issue adds extra to the token's claims, except those overriding registered claims.
+func (j *JWT) Issue(subject string, extra map[string]any) (string, error) {
+	jti, err := randomString()
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	claims := make(map[string]any, len(extra)+7)
+	maps.Copy(claims, extra)
+	maps.Copy(claims, map[string]any{
+		"aud": j.cfg.Audience,
+		"exp": now.Add(j.cfg.TTL).Unix(),
+		"iat": now.Unix(),
+		"iss": j.cfg.Issuer,
+		"jti": jti,
+		"nbf": now.Unix(),
+		"sub": subject,
+	})
+
+	key := j.cfg.Keys[0]
+	return signJWT(key.Key, j.algs[key.ID], key.ID, claims)
+}
+
+// Verify verifies raw is a token signed by one of the Keys, issued by the Issuer for the Audience,
+// and valid now, tolerating the Leeway of clock skew, returning its Claims.
+//
+// Verify returns trails.ErrNotValid or trails.ErrMissingData if raw is not such a token.
+func (j *JWT) Verify(raw string) (Claims, error) {
+	t, err := parseJWT(raw)
+	if err != nil {
+		return Claims{}, err
+	}
+
+	key, ok := j.key(t.header.Kid)
+	if !ok {
+		return Claims{}, fmt.Errorf("%w: token signed by unknown key %q", trails.ErrNotValid, t.header.Kid)
+	}
+
+	// NOTE: the algorithm must be the key's, not just any the token names.
+	if t.header.Alg != j.algs[key.ID] {
+		return Claims{}, fmt.Errorf("%w: algorithm %q does not match key %q", trails.ErrNotValid, t.header.Alg, key.ID)
+	}
+
+	if err := t.verify(key.public()); err != nil {
+		return Claims{}, err
+	}
+
+	if iss := t.string("iss"); iss != j.cfg.Issuer {
+		return Claims{}, fmt.Errorf("%w: token issued by %q", trails.ErrNotValid, iss)
+	}
+
+	aud := t.audience()
+	if !slices.Contains(aud, j.cfg.Audience) {
+		return Claims{}, fmt.Errorf("%w: token not issued for %q", trails.ErrNotValid, j.cfg.Audience)
+	}
+
+	if err := t.validAt(time.Now(), j.cfg.Leeway); err != nil {
+		return Claims{}, err
+	}
+
+	c := Claims{
+		Audience: aud,
+		Extra:    make(map[string]any),
+		ID:       t.string("jti"),
+		Issuer:   t.string("iss"),
+		Subject:  t.string("sub"),
+	}
+	c.ExpiresAt, _ = t.time("exp")
+	c.IssuedAt, _ = t.time("iat")
+
+	for name, v := range t.claims {
+		switch name {
+		case "aud", "exp", "iat", "iss", "jti", "nbf", "sub":
+			continue
+		}
+
+		// NOTE: numbers were decoded as json.Number to read dates precisely;
+		// decode other claims' numbers as encoding/json would.
+		if n, ok := v.(json.Number); ok {
+			v, _ = n.Float64()
+		}
+
+		c.Extra[name] = v
+	}
+
+	return c, nil
+}
+
+// key retrieves the key identified by kid
+// or, if kid is empty, the only one of the Keys.
+func (j *JWT) key(kid string) (SigningKey, bool) {
+	if kid == "" && len(j.cfg.Keys) == 1 {
+		return j.cfg.Keys[0], true
+	}
+
+	for _, k := range j.cfg.Keys {
+		if k.ID == kid && kid != "" {
+			return k, true
+		}
+	}
+
+	return SigningKey{}, false
+}
+
+// Authenticate authenticates requests by a token sent in an "Authorization: Bearer <token>" header,
+// responding with 401 to requests without a token Verify verifies.
+//
+// Authenticate stashes the token's Claims in the request's context.Context,
+// retrieved with ClaimsFromContext.
+func (j *JWT) Authenticate() middleware.Adapter {
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			scheme, token, _ := strings.Cut(r.Header.Get("Authorization"), " ")
+			if !strings.EqualFold(scheme, "Bearer") || strings.TrimSpace(token) == "" {
+				unauthorized(w, "")
+				return
+			}
+
+			claims, err := j.Verify(strings.TrimSpace(token))
+			if errors.Is(err, trails.ErrNotValid) || errors.Is(err, trails.ErrMissingData) {
+				unauthorized(w, "invalid_token")
+				return
+			}
+
+			if err != nil {
+				http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+				return
+			}
+
+			ctx := trails.WithValue(r.Context(), claimsKey, claims)
+			*r = *r.Clone(ctx)
+
+			h.ServeHTTP(w, r)
+		})
+	}
+}
+
+// ClaimsFromContext retrieves the Claims of the token [JWT.Authenticate] authenticated a request with from ctx.
+func ClaimsFromContext(ctx context.Context) (Claims, bool) {
+	return trails.Value[Claims](ctx, claimsKey)
+}
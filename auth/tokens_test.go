@@ -0,0 +1,252 @@
+package auth_test
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/xy-planning-network/trails"
+	"github.com/xy-planning-network/trails/auth"
+)
+
+func TestParseSigningKey(t *testing.T) {
+	// Arrange
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	ecKey, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	require.NoError(t, err)
+
+	ecDER, err := x509.MarshalECPrivateKey(ecKey)
+	require.NoError(t, err)
+
+	pubDER, err := x509.MarshalPKIXPublicKey(&rsaKey.PublicKey)
+	require.NoError(t, err)
+
+	for _, tc := range []struct {
+		name  string
+		block *pem.Block
+		ok    bool
+	}{
+		{"RSA-PKCS1", &pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(rsaKey)}, true},
+		{"EC", &pem.Block{Type: "EC PRIVATE KEY", Bytes: ecDER}, true},
+		{"Public", &pem.Block{Type: "PUBLIC KEY", Bytes: pubDER}, true},
+		{"Certificate", &pem.Block{Type: "CERTIFICATE", Bytes: pubDER}, false},
+		{"Corrupt", &pem.Block{Type: "RSA PRIVATE KEY", Bytes: []byte("corrupt")}, false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			// Act
+			k, err := auth.ParseSigningKey("key-1", pem.EncodeToMemory(tc.block))
+
+			// Assert
+			if !tc.ok {
+				require.ErrorIs(t, err, trails.ErrNotValid)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, "key-1", k.ID)
+		})
+	}
+}
+
+func TestNewJWT(t *testing.T) {
+	// Arrange
+	cfg := auth.JWTConfig{Audience: "api", Issuer: "app"}
+
+	// Act
+	_, err := auth.NewJWT(cfg)
+
+	// Assert
+	require.ErrorIs(t, err, trails.ErrBadConfig)
+	require.ErrorContains(t, err, "Keys")
+
+	// Arrange
+	cfg.Keys = []auth.SigningKey{{ID: "short", Key: []byte("secret")}}
+
+	// Act
+	_, err = auth.NewJWT(cfg)
+
+	// Assert
+	require.ErrorIs(t, err, trails.ErrBadConfig)
+}
+
+func TestJWT(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	for _, tc := range []struct {
+		name string
+		key  any
+	}{
+		{"HS256", bytes.Repeat([]byte("s"), 32)},
+		{"RS256", rsaKey},
+		{"ES256", ecKey},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			j, err := auth.NewJWT(auth.JWTConfig{
+				Audience: "api",
+				Issuer:   "app",
+				Keys:     []auth.SigningKey{{ID: "key-1", Key: tc.key}},
+			})
+			require.NoError(t, err)
+
+			// Act
+			token, err := j.Issue("42", map[string]any{"role": "admin", "sub": "forged"})
+
+			// Assert
+			require.NoError(t, err)
+
+			header, _, _ := strings.Cut(token, ".")
+			b, err := base64.RawURLEncoding.DecodeString(header)
+			require.NoError(t, err)
+			require.Contains(t, string(b), `"alg":"`+tc.name+`"`)
+
+			// Act
+			c, err := j.Verify(token)
+
+			// Assert
+			require.NoError(t, err)
+			require.Equal(t, "42", c.Subject)
+			require.Equal(t, "app", c.Issuer)
+			require.Equal(t, []string{"api"}, c.Audience)
+			require.Equal(t, map[string]any{"role": "admin"}, c.Extra)
+			require.NotEmpty(t, c.ID)
+			require.WithinDuration(t, time.Now().Add(time.Hour), c.ExpiresAt, time.Minute)
+		})
+	}
+}
+
+func TestJWTVerify(t *testing.T) {
+	oldKey := auth.SigningKey{ID: "old", Key: bytes.Repeat([]byte("o"), 32)}
+	newKey := auth.SigningKey{ID: "new", Key: bytes.Repeat([]byte("n"), 32)}
+
+	newJWT := func(t *testing.T, cfg auth.JWTConfig) *auth.JWT {
+		if cfg.Audience == "" {
+			cfg.Audience = "api"
+		}
+
+		if cfg.Issuer == "" {
+			cfg.Issuer = "app"
+		}
+
+		j, err := auth.NewJWT(cfg)
+		require.NoError(t, err)
+
+		return j
+	}
+
+	rotated := newJWT(t, auth.JWTConfig{Keys: []auth.SigningKey{newKey, oldKey}})
+
+	for _, tc := range []struct {
+		name   string
+		issuer *auth.JWT
+		ok     bool
+	}{
+		{"Rotated-Old-Key", newJWT(t, auth.JWTConfig{Keys: []auth.SigningKey{oldKey}}), true},
+		{"Rotated-New-Key", rotated, true},
+		{"Skewed-Clock", newJWT(t, auth.JWTConfig{Keys: []auth.SigningKey{newKey}, TTL: -30 * time.Second}), true},
+		{"Expired", newJWT(t, auth.JWTConfig{Keys: []auth.SigningKey{newKey}, TTL: -2 * time.Minute}), false},
+		{"Wrong-Audience", newJWT(t, auth.JWTConfig{Audience: "other", Keys: []auth.SigningKey{newKey}}), false},
+		{"Wrong-Issuer", newJWT(t, auth.JWTConfig{Issuer: "other", Keys: []auth.SigningKey{newKey}}), false},
+		{"Unknown-Key", newJWT(t, auth.JWTConfig{Keys: []auth.SigningKey{{ID: "other", Key: bytes.Repeat([]byte("x"), 32)}}}), false},
+		{"Forged-Key", newJWT(t, auth.JWTConfig{Keys: []auth.SigningKey{{ID: "new", Key: bytes.Repeat([]byte("x"), 32)}}}), false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			token, err := tc.issuer.Issue("42", nil)
+			require.NoError(t, err)
+
+			// Act
+			_, err = rotated.Verify(token)
+
+			// Assert
+			if tc.ok {
+				require.NoError(t, err)
+				return
+			}
+
+			require.ErrorIs(t, err, trails.ErrNotValid)
+		})
+	}
+
+	t.Run("Alg-None", func(t *testing.T) {
+		// Arrange
+		token, err := rotated.Issue("42", nil)
+		require.NoError(t, err)
+
+		_, rest, _ := strings.Cut(token, ".")
+		claims, _, _ := strings.Cut(rest, ".")
+		none := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"none","kid":"new"}`))
+
+		// Act
+		_, err = rotated.Verify(none + "." + claims + ".")
+
+		// Assert
+		require.ErrorIs(t, err, trails.ErrNotValid)
+	})
+}
+
+func TestJWTAuthenticate(t *testing.T) {
+	// Arrange
+	j, err := auth.NewJWT(auth.JWTConfig{
+		Audience: "api",
+		Issuer:   "app",
+		Keys:     []auth.SigningKey{{ID: "key-1", Key: bytes.Repeat([]byte("s"), 32)}},
+	})
+	require.NoError(t, err)
+
+	token, err := j.Issue("42", nil)
+	require.NoError(t, err)
+
+	var subject string
+	h := j.Authenticate()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c, ok := auth.ClaimsFromContext(r.Context())
+		require.True(t, ok)
+		subject = c.Subject
+	}))
+
+	for _, tc := range []struct {
+		name   string
+		header string
+		code   int
+	}{
+		{"Valid", "Bearer " + token, http.StatusOK},
+		{"No-Header", "", http.StatusUnauthorized},
+		{"Tampered", "Bearer " + token + "x", http.StatusUnauthorized},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			subject = ""
+			rr := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/api/things", nil)
+			if tc.header != "" {
+				req.Header.Set("Authorization", tc.header)
+			}
+
+			// Act
+			h.ServeHTTP(rr, req)
+
+			// Assert
+			require.Equal(t, tc.code, rr.Code)
+			if tc.code == http.StatusOK {
+				require.Equal(t, "42", subject)
+			}
+		})
+	}
+}
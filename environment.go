@@ -157,6 +157,29 @@ func EnvVarOrString(key, def string) string {
 	return val
 }
 
+// EnvVarOrStrings gets the environment variable for the provided key,
+// splits it on commas into a []string, trimming whitespace and dropping empty values,
+// or returns the provided default []string.
+func EnvVarOrStrings(key string, def []string) []string {
+	val := os.Getenv(key)
+	if val == "" {
+		return def
+	}
+
+	var vals []string
+	for _, v := range strings.Split(val, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			vals = append(vals, v)
+		}
+	}
+
+	if len(vals) == 0 {
+		return def
+	}
+
+	return vals
+}
+
 // EnvVarOrURL gets the environment variable for the provided or the provided default *url.URL.
 func EnvVarOrURL(key, def string) *url.URL {
 	defURL, err := url.ParseRequestURI(def)
@@ -1,10 +1,62 @@
 package trails
 
-import "errors"
+import (
+	"errors"
+	"net/http"
+)
 
 var (
-	ErrBadConfig   = errors.New("bad config")
-	ErrMissingData = errors.New("missing data")
-	ErrNotExist    = errors.New("not exist")
-	ErrNotValid    = errors.New("invalid")
+	ErrBadConfig       = errors.New("bad config")
+	ErrExists          = errors.New("exists")
+	ErrForbidden       = errors.New("forbidden")
+	ErrMissingData     = errors.New("missing data")
+	ErrNotExist        = errors.New("not exist")
+	ErrNotValid        = errors.New("invalid")
+	ErrUnauthenticated = errors.New("unauthenticated")
 )
+
+// A StatusCoder is an error knowing the HTTP status code to respond to it with,
+// e.g., one a handler returns; cf. StatusFor.
+type StatusCoder interface {
+	StatusCode() int
+}
+
+// StatusFor maps err to the HTTP status code to respond to it with,
+// so handlers and middlewares agree on what each error means to a client:
+//   - ErrMissingData: 400
+//   - ErrUnauthenticated: 401
+//   - ErrForbidden: 403
+//   - ErrNotExist: 404
+//   - ErrExists: 409
+//   - ErrNotValid: 422
+//   - anything else: 500
+//
+// An error wrapping a StatusCoder maps to its StatusCode instead.
+// If err is nil, StatusFor returns 200.
+func StatusFor(err error) int {
+	if err == nil {
+		return http.StatusOK
+	}
+
+	var sc StatusCoder
+	if errors.As(err, &sc) {
+		return sc.StatusCode()
+	}
+
+	switch {
+	case errors.Is(err, ErrMissingData):
+		return http.StatusBadRequest
+	case errors.Is(err, ErrUnauthenticated):
+		return http.StatusUnauthorized
+	case errors.Is(err, ErrForbidden):
+		return http.StatusForbidden
+	case errors.Is(err, ErrNotExist):
+		return http.StatusNotFound
+	case errors.Is(err, ErrExists):
+		return http.StatusConflict
+	case errors.Is(err, ErrNotValid):
+		return http.StatusUnprocessableEntity
+	default:
+		return http.StatusInternalServerError
+	}
+}
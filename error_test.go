@@ -0,0 +1,43 @@
+package trails_test
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/xy-planning-network/trails"
+)
+
+type teapotErr struct{}
+
+func (teapotErr) Error() string   { return "teapot" }
+func (teapotErr) StatusCode() int { return http.StatusTeapot }
+
+func TestStatusFor(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		err      error
+		expected int
+	}{
+		{"Nil", nil, http.StatusOK},
+		{"Missing-Data", trails.ErrMissingData, http.StatusBadRequest},
+		{"Unauthenticated", trails.ErrUnauthenticated, http.StatusUnauthorized},
+		{"Forbidden", trails.ErrForbidden, http.StatusForbidden},
+		{"Not-Exist", fmt.Errorf("%w: user 1", trails.ErrNotExist), http.StatusNotFound},
+		{"Exists", fmt.Errorf("%w: email", trails.ErrExists), http.StatusConflict},
+		{"Not-Valid", trails.ErrNotValid, http.StatusUnprocessableEntity},
+		{"Bad-Config", trails.ErrBadConfig, http.StatusInternalServerError},
+		{"Other", errors.New("boom"), http.StatusInternalServerError},
+		{"Status-Coder", fmt.Errorf("%w: %w", trails.ErrNotExist, teapotErr{}), http.StatusTeapot},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			// Act
+			actual := trails.StatusFor(tc.err)
+
+			// Assert
+			require.Equal(t, tc.expected, actual)
+		})
+	}
+}
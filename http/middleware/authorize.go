@@ -55,7 +55,7 @@ func (aa AuthorizeApplicator[T]) Apply(fn func(user T) (string, bool)) Adapter {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			doRedirect := acceptsTextHtml(r.Header)
 
-			val, ok := r.Context().Value(trails.CurrentUserKey).(T)
+			val, ok := trails.Value[T](r.Context(), trails.CurrentUserKey)
 			if !ok {
 				if doRedirect {
 					f := session.Flash{Type: session.FlashWarning, Msg: session.NoAccessMsg}
@@ -89,6 +89,33 @@ func (aa AuthorizeApplicator[T]) Apply(fn func(user T) (string, bool)) Adapter {
 	}
 }
 
+// A RoledUser is a User granted roles, e.g., "admin".
+type RoledUser interface {
+	HasRole(role string) bool
+}
+
+// RequireRole passes a request to the next handler in the middleware stack
+// only if the current user is a RoledUser granted one of roles.
+//
+// Otherwise, as AuthorizeApplicator.Apply does, RequireRole writes 401
+// or, if "text/html" appears in the "Accept" header, sets a "no access" flash on the session
+// and redirects to the user's HomePath.
+func RequireRole(d *resp.Responder, roles ...string) Adapter {
+	return NewAuthorizeApplicator[RoledUser](d).Apply(func(u RoledUser) (string, bool) {
+		for _, role := range roles {
+			if u.HasRole(role) {
+				return "", true
+			}
+		}
+
+		if user, ok := u.(User); ok {
+			return user.HomePath(), false
+		}
+
+		return "/", false
+	})
+}
+
 // acceptsTextHtml asserts whether the requests accepts rendered HTML or not.
 func acceptsTextHtml(header http.Header) bool {
 	v := header.Get("Accept")
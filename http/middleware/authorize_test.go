@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"slices"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -110,3 +111,61 @@ func TestAuthorizeApplicator(t *testing.T) {
 	//	Assert
 	require.Equal(t, http.StatusTeapot, w.Code)
 }
+
+type roledUser struct {
+	testUser
+	roles []string
+}
+
+func (u roledUser) HasRole(role string) bool { return slices.Contains(u.roles, role) }
+
+func TestRequireRole(t *testing.T) {
+	adpt := middleware.RequireRole(resp.NewResponder(resp.WithRootUrl("/")), "admin", "support")
+
+	for _, tc := range []struct {
+		name     string
+		user     any
+		expected int
+	}{
+		{"No-User", nil, http.StatusUnauthorized},
+		{"Not-Roled", testUser(true), http.StatusUnauthorized},
+		{"No-Roles", roledUser{testUser: true}, http.StatusUnauthorized},
+		{"Other-Role", roledUser{true, []string{"billing"}}, http.StatusUnauthorized},
+		{"Role", roledUser{true, []string{"billing", "support"}}, http.StatusTeapot},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodGet, "https://example.com", nil)
+			if tc.user != nil {
+				r = r.Clone(context.WithValue(r.Context(), trails.CurrentUserKey, tc.user))
+			}
+
+			// Act
+			adpt(teapotHandler()).ServeHTTP(w, r)
+
+			// Assert
+			require.Equal(t, tc.expected, w.Code)
+		})
+	}
+
+	t.Run("Redirect", func(t *testing.T) {
+		// Arrange
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "https://example.com", nil)
+		r.Header.Set("Accept", "text/html")
+
+		ss, err := session.NewStub(false).GetSession(r)
+		require.NoError(t, err)
+
+		r = r.Clone(context.WithValue(r.Context(), trails.SessionKey, ss))
+		r = r.Clone(context.WithValue(r.Context(), trails.CurrentUserKey, roledUser{testUser: true}))
+
+		// Act
+		adpt(teapotHandler()).ServeHTTP(w, r)
+
+		// Assert
+		require.Equal(t, http.StatusFound, w.Code)
+		require.Equal(t, "/", w.Header().Get("Location"))
+	})
+}
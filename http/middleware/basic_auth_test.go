@@ -0,0 +1,48 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/xy-planning-network/trails/http/middleware"
+)
+
+func TestBasicAuth(t *testing.T) {
+	tcs := []struct {
+		name     string
+		username string
+		password string
+		setAuth  bool
+		user     string
+		pass     string
+		expected int
+	}{
+		{"Authenticated", "scraper", "secret", true, "scraper", "secret", http.StatusTeapot},
+		{"No-Credentials", "scraper", "secret", false, "", "", http.StatusUnauthorized},
+		{"Wrong-Username", "scraper", "secret", true, "other", "secret", http.StatusUnauthorized},
+		{"Wrong-Password", "scraper", "secret", true, "scraper", "guess", http.StatusUnauthorized},
+		{"Unconfigured", "", "", true, "", "", http.StatusUnauthorized},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodGet, "https://example.com/metrics", nil)
+			if tc.setAuth {
+				r.SetBasicAuth(tc.user, tc.pass)
+			}
+
+			// Act
+			middleware.BasicAuth("metrics", tc.username, tc.password)(teapotHandler()).ServeHTTP(w, r)
+
+			// Assert
+			require.Equal(t, tc.expected, w.Code)
+			if tc.expected == http.StatusUnauthorized {
+				require.Equal(t, `Basic realm="metrics", charset="UTF-8"`, w.Header().Get("WWW-Authenticate"))
+			}
+		})
+	}
+}
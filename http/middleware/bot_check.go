@@ -0,0 +1,316 @@
+package middleware
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/xy-planning-network/trails"
+	"github.com/xy-planning-network/trails/http/resp"
+	"github.com/xy-planning-network/trails/http/session"
+	"github.com/xy-planning-network/trails/http/template"
+	"github.com/xy-planning-network/trails/logger"
+)
+
+const (
+	// BotCheckMsg is the error JSON clients receive when BotCheck rejects a submission.
+	BotCheckMsg = "The submission could not be verified. Please try again."
+
+	// DefaultMinSubmitTime is how long after a form is rendered BotCheck accepts it being submitted.
+	DefaultMinSubmitTime = 3 * time.Second
+
+	// DefaultMaxSubmitTime is how long after a form is rendered BotCheck stops accepting it being submitted.
+	DefaultMaxSubmitTime = 24 * time.Hour
+
+	// HCaptchaField is the form field the hCaptcha widget submits its response in.
+	HCaptchaField = "h-captcha-response"
+
+	// HCaptchaURL is the endpoint verifying hCaptcha responses.
+	HCaptchaURL = "https://api.hcaptcha.com/siteverify"
+
+	// TurnstileField is the form field the Cloudflare Turnstile widget submits its response in.
+	TurnstileField = "cf-turnstile-response"
+
+	// TurnstileURL is the endpoint verifying Cloudflare Turnstile responses.
+	TurnstileURL = "https://challenges.cloudflare.com/turnstile/v0/siteverify"
+
+	botCheckKeyLen = 32
+)
+
+// A CaptchaVerifier asserts whether the CAPTCHA submitted with a request was solved.
+type CaptchaVerifier interface {
+	Verify(r *http.Request) (bool, error)
+}
+
+// BotCheckOpts configures the BotCheck middleware.
+type BotCheckOpts struct {
+	// Captcha verifies the CAPTCHA submitted with the form, e.g., Turnstile or HCaptcha.
+	//
+	// If Captcha is nil, no CAPTCHA is required.
+	Captcha CaptchaVerifier
+
+	// Exempt asserts whether the request skips the checks,
+	// e.g., API routes authenticated by other means.
+	//
+	// If Exempt is nil, no request is exempt.
+	Exempt func(*http.Request) bool
+
+	// Key signs the time a form was rendered at, so it cannot be forged.
+	//
+	// If Key is empty, a random key is generated,
+	// in which case forms rendered by one instance of the application or before it restarted fail the check.
+	Key []byte
+
+	// Logger logs failures to verify a CAPTCHA.
+	//
+	// If Logger is nil, failures are not logged.
+	Logger logger.Logger
+
+	// MaxSubmitTime is how long after a form is rendered it may be submitted.
+	//
+	// If MaxSubmitTime is 0, DefaultMaxSubmitTime is used.
+	MaxSubmitTime time.Duration
+
+	// MinSubmitTime is how long after a form is rendered it may be submitted,
+	// faster than which only bots fill in forms.
+	//
+	// If MinSubmitTime is 0, DefaultMinSubmitTime is used.
+	MinSubmitTime time.Duration
+
+	// Responder responds to requests failing the check.
+	//
+	// If Responder is nil, requests failing the check are responded to with 400.
+	Responder *resp.Responder
+}
+
+// BotCheck mitigates bots submitting public forms, e.g., signing up or contacting support,
+// where no user is logged in to hold accountable.
+//
+// On every request, BotCheck signs the current time
+// and stashes it in the *http.Request.Context under trails.BotCheckKey.
+// [*resp.Responder.Html] makes it available to templates through the "botCheckField" function,
+// rendering a hidden template.BotCheckFieldName field and a template.HoneypotFieldName field
+// people never see, but bots fill in.
+//
+// For requests using methods other than GET, HEAD, OPTIONS, or TRACE, BotCheck rejects the request if:
+//   - the honeypot field is filled in
+//   - the signed time is missing, forged, or not between BotCheckOpts.MinSubmitTime and BotCheckOpts.MaxSubmitTime ago
+//   - BotCheckOpts.Captcha does not verify the CAPTCHA submitted
+//
+// If "text/html" appears in the "Accept" header, BotCheck sets a "bad input" flash on the session
+// and redirects to the same URL; otherwise, BotCheck responds with 400 and
+// JSON in the same shape as *resp.Responder.Json: {"data": {"error": BotCheckMsg}}.
+//
+// Apply BotCheck only to the routes serving and receiving public forms,
+// since every form on those routes must render the "botCheckField".
+func BotCheck(opts BotCheckOpts) Adapter {
+	key := opts.Key
+	if len(key) == 0 {
+		key = make([]byte, botCheckKeyLen)
+		if _, err := rand.Read(key); err != nil {
+			panic(fmt.Sprintf("middleware: could not generate bot check key: %s", err))
+		}
+	}
+
+	minAge := opts.MinSubmitTime
+	if minAge == 0 {
+		minAge = DefaultMinSubmitTime
+	}
+
+	maxAge := opts.MaxSubmitTime
+	if maxAge == 0 {
+		maxAge = DefaultMaxSubmitTime
+	}
+
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if opts.Exempt != nil && opts.Exempt(r) {
+				h.ServeHTTP(w, r)
+				return
+			}
+
+			if !isSafeMethod(r.Method) && !passesBotCheck(r, key, minAge, maxAge, opts.Captcha, opts.Logger) {
+				botCheckFailed(w, r, opts.Responder)
+				return
+			}
+
+			token := signBotCheck(key, time.Now())
+			*r = *r.Clone(trails.WithValue(r.Context(), trails.BotCheckKey, token))
+			h.ServeHTTP(w, r)
+		})
+	}
+}
+
+// passesBotCheck asserts whether the form submitted with r was filled in by a person.
+// Failures to verify the CAPTCHA are logged using l.
+func passesBotCheck(r *http.Request, key []byte, minAge, maxAge time.Duration, captcha CaptchaVerifier, l logger.Logger) bool {
+	if r.FormValue(template.HoneypotFieldName) != "" {
+		return false
+	}
+
+	rendered, ok := verifyBotCheck(key, r.FormValue(template.BotCheckFieldName))
+	if !ok {
+		return false
+	}
+
+	age := time.Since(rendered)
+	if age < minAge || age > maxAge {
+		return false
+	}
+
+	if captcha == nil {
+		return true
+	}
+
+	solved, err := captcha.Verify(r)
+	if err != nil {
+		logError(l, r, "could not verify captcha", err)
+		return false
+	}
+
+	return solved
+}
+
+// botCheckFailed responds to requests failing BotCheck.
+func botCheckFailed(w http.ResponseWriter, r *http.Request, d *resp.Responder) {
+	if d == nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	if acceptsTextHtml(r.Header) {
+		f := session.Flash{Type: session.FlashError, Msg: session.BadInputMsg}
+		if err := d.Redirect(w, r, resp.Url(r.URL.RequestURI()), resp.Flash(f)); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+		}
+
+		return
+	}
+
+	err := d.Json(w, r, resp.Code(http.StatusBadRequest), resp.Data(map[string]string{"error": BotCheckMsg}))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+	}
+}
+
+// signBotCheck signs t as "<unix milliseconds>.<hex HMAC-SHA256>".
+func signBotCheck(key []byte, t time.Time) string {
+	ms := strconv.FormatInt(t.UnixMilli(), 10)
+	return ms + "." + botCheckMAC(key, ms)
+}
+
+// verifyBotCheck parses the time token was signed at, asserting whether the signature matches.
+func verifyBotCheck(key []byte, token string) (time.Time, bool) {
+	ms, sig, ok := strings.Cut(token, ".")
+	if !ok {
+		return time.Time{}, false
+	}
+
+	if !hmac.Equal([]byte(sig), []byte(botCheckMAC(key, ms))) {
+		return time.Time{}, false
+	}
+
+	n, err := strconv.ParseInt(ms, 10, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	return time.UnixMilli(n), true
+}
+
+// botCheckMAC computes the hex encoded HMAC-SHA256 of msg.
+func botCheckMAC(key []byte, msg string) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(msg))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// A SiteVerifier is a CaptchaVerifier posting the response a CAPTCHA widget submits
+// to the widget provider's "siteverify" endpoint, as Cloudflare Turnstile, hCaptcha,
+// and Google reCAPTCHA do.
+type SiteVerifier struct {
+	// Client requests URL.
+	//
+	// If Client is nil, http.DefaultClient is used.
+	Client *http.Client
+
+	// Field is the form field the widget submits its response in.
+	Field string
+
+	// Secret is the secret key the provider issued to the site.
+	Secret string
+
+	// URL is the provider's endpoint verifying responses.
+	URL string
+}
+
+// HCaptcha constructs a SiteVerifier verifying hCaptcha responses with secret.
+func HCaptcha(secret string) SiteVerifier {
+	return SiteVerifier{Field: HCaptchaField, Secret: secret, URL: HCaptchaURL}
+}
+
+// Turnstile constructs a SiteVerifier verifying Cloudflare Turnstile responses with secret.
+func Turnstile(secret string) SiteVerifier {
+	return SiteVerifier{Field: TurnstileField, Secret: secret, URL: TurnstileURL}
+}
+
+// Verify posts the response submitted in the Field form field,
+// along with the IP address of the request, to URL,
+// asserting whether the provider reports it successful.
+//
+// If the request has no response, Verify returns false without posting it.
+func (v SiteVerifier) Verify(r *http.Request) (bool, error) {
+	response := r.FormValue(v.Field)
+	if response == "" {
+		return false, nil
+	}
+
+	form := url.Values{"secret": {v.Secret}, "response": {response}}
+	if ip, ok := trails.Value[string](r.Context(), trails.IpAddrKey); ok && ip != "" {
+		form.Set("remoteip", ip)
+	}
+
+	return v.post(r.Context(), form)
+}
+
+// post posts form to URL, decoding whether the response was successful.
+func (v SiteVerifier) post(ctx context.Context, form url.Values) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, v.URL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set(contentTypeHeader, "application/x-www-form-urlencoded")
+
+	client := v.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	res, err := client.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("%s responded with %d", v.URL, res.StatusCode)
+	}
+
+	var body struct {
+		Success bool `json:"success"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&body); err != nil {
+		return false, err
+	}
+
+	return body.Success, nil
+}
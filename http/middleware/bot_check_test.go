@@ -0,0 +1,249 @@
+package middleware_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/xy-planning-network/trails"
+	"github.com/xy-planning-network/trails/http/middleware"
+	"github.com/xy-planning-network/trails/http/resp"
+	"github.com/xy-planning-network/trails/http/session"
+	"github.com/xy-planning-network/trails/http/template"
+	"github.com/xy-planning-network/trails/logger"
+)
+
+// captchaFunc is a middleware.CaptchaVerifier verifying CAPTCHAs with itself.
+type captchaFunc func(*http.Request) (bool, error)
+
+func (fn captchaFunc) Verify(r *http.Request) (bool, error) { return fn(r) }
+
+func TestBotCheck(t *testing.T) {
+	key := []byte("secret")
+	render := func(opts middleware.BotCheckOpts) string {
+		var token string
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "https://example.com/signup", nil)
+		middleware.BotCheck(opts)(http.HandlerFunc(func(wx http.ResponseWriter, rx *http.Request) {
+			token, _ = trails.Value[string](rx.Context(), trails.BotCheckKey)
+		})).ServeHTTP(w, r)
+
+		return token
+	}
+
+	for _, tc := range []struct {
+		name     string
+		opts     middleware.BotCheckOpts
+		form     func(token string) url.Values
+		expected int
+	}{
+		{
+			"Valid",
+			middleware.BotCheckOpts{Key: key, MinSubmitTime: time.Nanosecond},
+			func(token string) url.Values {
+				return url.Values{template.BotCheckFieldName: {token}, template.HoneypotFieldName: {""}}
+			},
+			http.StatusTeapot,
+		},
+		{
+			"Honeypot",
+			middleware.BotCheckOpts{Key: key, MinSubmitTime: time.Nanosecond},
+			func(token string) url.Values {
+				return url.Values{template.BotCheckFieldName: {token}, template.HoneypotFieldName: {"https://spam.example.com"}}
+			},
+			http.StatusBadRequest,
+		},
+		{
+			"Too-Fast",
+			middleware.BotCheckOpts{Key: key, MinSubmitTime: time.Hour},
+			func(token string) url.Values {
+				return url.Values{template.BotCheckFieldName: {token}}
+			},
+			http.StatusBadRequest,
+		},
+		{
+			"Too-Slow",
+			middleware.BotCheckOpts{Key: key, MinSubmitTime: time.Nanosecond, MaxSubmitTime: time.Nanosecond},
+			func(token string) url.Values {
+				time.Sleep(time.Millisecond)
+				return url.Values{template.BotCheckFieldName: {token}}
+			},
+			http.StatusBadRequest,
+		},
+		{
+			"Forged",
+			middleware.BotCheckOpts{Key: key, MinSubmitTime: time.Nanosecond},
+			func(token string) url.Values {
+				ms, sig, _ := strings.Cut(token, ".")
+				return url.Values{template.BotCheckFieldName: {ms + "0." + sig}}
+			},
+			http.StatusBadRequest,
+		},
+		{
+			"Missing",
+			middleware.BotCheckOpts{Key: key, MinSubmitTime: time.Nanosecond},
+			func(string) url.Values { return url.Values{} },
+			http.StatusBadRequest,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			token := render(tc.opts)
+			require.NotZero(t, token)
+
+			form := tc.form(token)
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPost, "https://example.com/signup", strings.NewReader(form.Encode()))
+			r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+			// Act
+			middleware.BotCheck(tc.opts)(teapotHandler()).ServeHTTP(w, r)
+
+			// Assert
+			require.Equal(t, tc.expected, w.Code)
+		})
+	}
+}
+
+func TestBotCheckCaptcha(t *testing.T) {
+	// Arrange
+	b := new(bytes.Buffer)
+	opts := middleware.BotCheckOpts{
+		Captcha: captchaFunc(func(*http.Request) (bool, error) {
+			return false, errors.New("captcha provider is down")
+		}),
+		Key:           []byte("secret"),
+		Logger:        logger.New(slog.New(slog.NewTextHandler(b, nil)), trails.Testing),
+		MinSubmitTime: time.Nanosecond,
+	}
+
+	var token string
+	middleware.BotCheck(opts)(http.HandlerFunc(func(wx http.ResponseWriter, rx *http.Request) {
+		token, _ = trails.Value[string](rx.Context(), trails.BotCheckKey)
+	})).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "https://example.com/signup", nil))
+
+	form := url.Values{template.BotCheckFieldName: {token}}
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPost, "https://example.com/signup", strings.NewReader(form.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	// Act
+	middleware.BotCheck(opts)(teapotHandler()).ServeHTTP(w, r)
+
+	// Assert
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	require.Contains(t, b.String(), "could not verify captcha")
+	require.Contains(t, b.String(), "captcha provider is down")
+}
+
+func TestBotCheckExempt(t *testing.T) {
+	// Arrange
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPost, "https://example.com/webhook", nil)
+
+	opts := middleware.BotCheckOpts{Exempt: func(r *http.Request) bool { return r.URL.Path == "/webhook" }}
+
+	// Act
+	middleware.BotCheck(opts)(teapotHandler()).ServeHTTP(w, r)
+
+	// Assert
+	require.Equal(t, http.StatusTeapot, w.Code)
+}
+
+func TestBotCheckFailed(t *testing.T) {
+	// Arrange
+	d := resp.NewResponder(resp.WithRootUrl("https://example.com"))
+	adpt := middleware.BotCheck(middleware.BotCheckOpts{Responder: d})
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPost, "https://example.com/signup?plan=pro", nil)
+
+	// Act
+	adpt(teapotHandler()).ServeHTTP(w, r)
+
+	// Assert
+	var body map[string]map[string]string
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&body))
+	require.Equal(t, middleware.BotCheckMsg, body["data"]["error"])
+
+	// Arrange
+	s, err := session.NewStub(false).GetSession(r)
+	require.NoError(t, err)
+
+	w = httptest.NewRecorder()
+	r = httptest.NewRequest(http.MethodPost, "https://example.com/signup?plan=pro", nil)
+	r = r.Clone(context.WithValue(r.Context(), trails.SessionKey, s))
+	r.Header.Set("Accept", "text/html,application/xhtml+xml")
+
+	// Act
+	adpt(teapotHandler()).ServeHTTP(w, r)
+
+	// Assert
+	require.Equal(t, http.StatusSeeOther, w.Code)
+	require.Equal(t, "/signup?plan=pro", w.Header().Get("Location"))
+	require.Equal(t, []session.Flash{{Type: session.FlashError, Msg: session.BadInputMsg}}, s.Flashes(w, r))
+}
+
+func TestSiteVerifier(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		response string
+		status   int
+		success  bool
+		expected bool
+		err      bool
+	}{
+		{"Success", "solved", http.StatusOK, true, true, false},
+		{"Failure", "unsolved", http.StatusOK, false, false, false},
+		{"Missing", "", http.StatusOK, true, false, false},
+		{"Error", "solved", http.StatusInternalServerError, true, false, true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			var posted url.Values
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				require.NoError(t, r.ParseForm())
+				posted = r.PostForm
+				w.WriteHeader(tc.status)
+				json.NewEncoder(w).Encode(map[string]bool{"success": tc.success})
+			}))
+			defer srv.Close()
+
+			v := middleware.Turnstile("shh")
+			v.URL = srv.URL
+
+			form := url.Values{middleware.TurnstileField: {tc.response}}
+			r := httptest.NewRequest(http.MethodPost, "https://example.com/signup", strings.NewReader(form.Encode()))
+			r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			r = r.Clone(context.WithValue(r.Context(), trails.IpAddrKey, "203.0.113.7"))
+
+			// Act
+			ok, err := v.Verify(r)
+
+			// Assert
+			require.Equal(t, tc.expected, ok)
+			if tc.err {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+
+			if tc.response == "" {
+				require.Nil(t, posted)
+				return
+			}
+
+			require.Equal(t, url.Values{"secret": {"shh"}, "response": {tc.response}, "remoteip": {"203.0.113.7"}}, posted)
+		})
+	}
+}
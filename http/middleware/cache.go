@@ -0,0 +1,318 @@
+package middleware
+
+import (
+	"bytes"
+	"net/http"
+	"net/textproto"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/xy-planning-network/trails"
+	"github.com/xy-planning-network/trails/http/session"
+)
+
+const (
+	authorizationHeader = "Authorization"
+	cacheControlHeader  = "Cache-Control"
+	setCookieHeader     = "Set-Cookie"
+	varyHeader          = "Vary"
+
+	// CacheStatusHeader reports whether CacheResponse served a response from the cache ("HIT") or not ("MISS").
+	CacheStatusHeader = "X-Cache"
+)
+
+// A CachedResponse is a complete HTTP response stored in a ResponseCache.
+type CachedResponse struct {
+	Body   []byte
+	Header http.Header
+	Status int
+}
+
+// A ResponseCache stores CachedResponses for CacheResponse.
+//
+// Delete and Purge are the hooks for invalidating responses,
+// e.g., after updating content shown on a cached page.
+type ResponseCache interface {
+	// Delete removes the response stored under the key.
+	Delete(key string)
+
+	// Get retrieves the response stored under the key, if one exists and has not expired.
+	Get(key string) (CachedResponse, bool)
+
+	// Purge removes all stored responses.
+	Purge()
+
+	// Set stores the response under the key for the duration of ttl.
+	Set(key string, res CachedResponse, ttl time.Duration)
+}
+
+// A MemoryCache is an in-memory ResponseCache.
+type MemoryCache struct {
+	val map[string]memoryCacheEntry
+	sync.Mutex
+}
+
+// memoryCacheEntry pairs a CachedResponse with when it expires.
+type memoryCacheEntry struct {
+	expires time.Time
+	res     CachedResponse
+}
+
+// NewMemoryCache constructs an empty MemoryCache.
+func NewMemoryCache() *MemoryCache { return &MemoryCache{val: make(map[string]memoryCacheEntry)} }
+
+// Delete removes the response stored under the key.
+func (mc *MemoryCache) Delete(key string) {
+	mc.Lock()
+	defer mc.Unlock()
+	delete(mc.val, key)
+}
+
+// Get retrieves the response stored under the key,
+// removing it if it has expired.
+func (mc *MemoryCache) Get(key string) (CachedResponse, bool) {
+	mc.Lock()
+	defer mc.Unlock()
+
+	e, ok := mc.val[key]
+	if !ok {
+		return CachedResponse{}, false
+	}
+
+	if time.Now().After(e.expires) {
+		delete(mc.val, key)
+		return CachedResponse{}, false
+	}
+
+	return e.res, true
+}
+
+// Purge removes all stored responses.
+func (mc *MemoryCache) Purge() {
+	mc.Lock()
+	defer mc.Unlock()
+	mc.val = make(map[string]memoryCacheEntry)
+}
+
+// Set stores the response under the key for the duration of ttl.
+func (mc *MemoryCache) Set(key string, res CachedResponse, ttl time.Duration) {
+	mc.Lock()
+	defer mc.Unlock()
+	mc.val[key] = memoryCacheEntry{expires: time.Now().Add(ttl), res: res}
+}
+
+// DefaultCacheKey keys responses by the host and the URI requested.
+func DefaultCacheKey(r *http.Request) string { return r.Host + r.URL.RequestURI() }
+
+// CacheResponse caches complete responses to GET and HEAD requests from anonymous users in the ResponseCache
+// for the duration of ttl, keyed by the value keyFn returns.
+// Pass the same key to ResponseCache.Delete to invalidate a cached response.
+//
+// A request is anonymous when it has no "Authorization" header
+// and its session, if InjectSession comes before CacheResponse, has no registered user.
+//
+// Only responses with a 200 status are cached.
+// Responses the handler sets cookies on or with a "Cache-Control" header of "private" or "no-store" are not cached.
+// Cookies set before CacheResponse, e.g., the session cookie InjectSession sets, belong to the request alone:
+// they are not cached, nor do they keep the response from being cached.
+// Responses to requests carrying values unique to them, which responses may render,
+// i.e., a CSRF token (CSRF), a nonce (SecureHeaders), or a locale (Locale), are not cached,
+// wherever those middlewares come in the middleware chain.
+// When a response has a "Vary" header, the response is cached once per unique set of values
+// of those request headers, unless the "Vary" header is "*", in which case it is not cached.
+//
+// If store is nil or ttl is not positive, NoopAdapter returns and this middleware does nothing.
+// If keyFn is nil, DefaultCacheKey is used.
+func CacheResponse(store ResponseCache, ttl time.Duration, keyFn func(*http.Request) string) Adapter {
+	if store == nil || ttl <= 0 {
+		return NoopAdapter
+	}
+
+	if keyFn == nil {
+		keyFn = DefaultCacheKey
+	}
+
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !cacheable(r) {
+				h.ServeHTTP(w, r)
+				return
+			}
+
+			key := keyFn(r)
+			if res, ok := lookupResponse(store, key, r); ok {
+				header := w.Header()
+				for k, v := range res.Header {
+					header[k] = v
+				}
+
+				header.Set(CacheStatusHeader, "HIT")
+				w.WriteHeader(res.Status)
+				if r.Method != http.MethodHead {
+					w.Write(res.Body)
+				}
+
+				return
+			}
+
+			w.Header().Set(CacheStatusHeader, "MISS")
+			cookies := slices.Clone(w.Header().Values(setCookieHeader))
+			rec := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+			h.ServeHTTP(rec, r)
+
+			// NOTE: middlewares after CacheResponse replace *r, so r.Context carries the values they set.
+			if r.Method == http.MethodHead || perRequest(r) || !storable(rec, cookies) {
+				return
+			}
+
+			header := rec.Header().Clone()
+			header.Del(CacheStatusHeader)
+			header.Del(setCookieHeader)
+			res := CachedResponse{Body: rec.body.Bytes(), Header: header, Status: rec.status}
+
+			vary := varyNames(header)
+			if len(vary) == 0 {
+				store.Set(key, res, ttl)
+				return
+			}
+
+			// NOTE: the response stored under key only records which request headers vary the response,
+			// so deleting key invalidates every variant.
+			store.Set(key, CachedResponse{Header: http.Header{varyHeader: header.Values(varyHeader)}}, ttl)
+			store.Set(variantKey(key, vary, r), res, ttl)
+		})
+	}
+}
+
+// cacheable asserts whether the request is an anonymous GET or HEAD request.
+func cacheable(r *http.Request) bool {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return false
+	}
+
+	if r.Header.Get(authorizationHeader) != "" {
+		return false
+	}
+
+	if s, ok := trails.Value[session.Session](r.Context(), trails.SessionKey); ok {
+		if _, err := s.UserID(); err == nil {
+			return false
+		}
+	}
+
+	return true
+}
+
+// lookupResponse retrieves the response stored under key,
+// following the "Vary" header to the variant matching the request.
+func lookupResponse(store ResponseCache, key string, r *http.Request) (CachedResponse, bool) {
+	res, ok := store.Get(key)
+	if !ok {
+		return CachedResponse{}, false
+	}
+
+	vary := varyNames(res.Header)
+	if len(vary) == 0 {
+		return res, true
+	}
+
+	return store.Get(variantKey(key, vary, r))
+}
+
+// perRequestKeys are the keys of values middlewares stash in a request's context that are unique to it.
+var perRequestKeys = []trails.Key{trails.CSRFTokenKey, trails.LocaleKey, trails.NonceKey}
+
+// perRequest asserts whether the request carries any value unique to it a response may render.
+func perRequest(r *http.Request) bool {
+	for _, key := range perRequestKeys {
+		if r.Context().Value(key) != nil {
+			return true
+		}
+	}
+
+	return false
+}
+
+// storable asserts whether the recorded response may be stored,
+// given the cookies set on it before the handler was called.
+func storable(rec *responseRecorder, cookies []string) bool {
+	if rec.status != http.StatusOK {
+		return false
+	}
+
+	header := rec.Header()
+	if !slices.Equal(header.Values(setCookieHeader), cookies) {
+		return false
+	}
+
+	cc := strings.ToLower(header.Get(cacheControlHeader))
+	if strings.Contains(cc, "private") || strings.Contains(cc, "no-store") {
+		return false
+	}
+
+	for _, name := range varyNames(header) {
+		if name == "*" {
+			return false
+		}
+	}
+
+	return true
+}
+
+// varyNames lists the canonicalized request header names in the "Vary" header.
+func varyNames(header http.Header) []string {
+	var names []string
+	for _, v := range header.Values(varyHeader) {
+		for _, name := range strings.Split(v, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				names = append(names, textproto.CanonicalMIMEHeaderKey(name))
+			}
+		}
+	}
+
+	return names
+}
+
+// variantKey extends key with the values of the request headers named in vary.
+func variantKey(key string, vary []string, r *http.Request) string {
+	var b strings.Builder
+	b.WriteString(key)
+	for _, name := range vary {
+		b.WriteString("\x00")
+		b.WriteString(name)
+		b.WriteString("=")
+		b.WriteString(strings.Join(r.Header.Values(name), ","))
+	}
+
+	return b.String()
+}
+
+// A responseRecorder copies the status and body of a response as it is written.
+type responseRecorder struct {
+	http.ResponseWriter
+	body        bytes.Buffer
+	status      int
+	wroteHeader bool
+}
+
+// WriteHeader records the status before writing it.
+func (rr *responseRecorder) WriteHeader(code int) {
+	if !rr.wroteHeader {
+		rr.status = code
+		rr.wroteHeader = true
+	}
+
+	rr.ResponseWriter.WriteHeader(code)
+}
+
+// Write records the bytes before writing them.
+func (rr *responseRecorder) Write(b []byte) (int, error) {
+	rr.wroteHeader = true
+	rr.body.Write(b)
+	return rr.ResponseWriter.Write(b)
+}
+
+// Unwrap exposes the underlying http.ResponseWriter to an http.ResponseController.
+func (rr *responseRecorder) Unwrap() http.ResponseWriter { return rr.ResponseWriter }
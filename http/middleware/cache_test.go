@@ -0,0 +1,258 @@
+package middleware_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/xy-planning-network/trails"
+	"github.com/xy-planning-network/trails/http/middleware"
+	"github.com/xy-planning-network/trails/http/session"
+)
+
+func TestMemoryCache(t *testing.T) {
+	// Arrange
+	mc := middleware.NewMemoryCache()
+	res := middleware.CachedResponse{Body: []byte("hi"), Status: http.StatusOK}
+
+	// Act
+	mc.Set("a", res, time.Minute)
+	mc.Set("b", res, time.Minute)
+	mc.Set("expired", res, -time.Minute)
+
+	// Assert
+	actual, ok := mc.Get("a")
+	require.True(t, ok)
+	require.Equal(t, res, actual)
+
+	_, ok = mc.Get("expired")
+	require.False(t, ok)
+
+	mc.Delete("a")
+	_, ok = mc.Get("a")
+	require.False(t, ok)
+
+	mc.Purge()
+	_, ok = mc.Get("b")
+	require.False(t, ok)
+}
+
+func TestCacheResponse(t *testing.T) {
+	var calls int
+	handler := func(status int, header http.Header) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			for k, v := range header {
+				w.Header()[k] = v
+			}
+
+			w.WriteHeader(status)
+			w.Write([]byte(r.Header.Get("Accept-Language") + "body"))
+		})
+	}
+
+	serve := func(h http.Handler, r *http.Request) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w
+	}
+
+	t.Run("Noop", func(t *testing.T) {
+		// Arrange
+		calls = 0
+		h := middleware.CacheResponse(nil, time.Minute, nil)(handler(http.StatusOK, nil))
+
+		// Act
+		serve(h, httptest.NewRequest(http.MethodGet, "https://example.com", nil))
+		w := serve(h, httptest.NewRequest(http.MethodGet, "https://example.com", nil))
+
+		// Assert
+		require.Equal(t, 2, calls)
+		require.Empty(t, w.Header().Get(middleware.CacheStatusHeader))
+	})
+
+	t.Run("Anonymous", func(t *testing.T) {
+		// Arrange
+		calls = 0
+		store := middleware.NewMemoryCache()
+		h := middleware.CacheResponse(store, time.Minute, nil)(handler(http.StatusOK, http.Header{"X-Custom": {"val"}}))
+
+		// Act
+		miss := serve(h, httptest.NewRequest(http.MethodGet, "https://example.com/page", nil))
+		hit := serve(h, httptest.NewRequest(http.MethodGet, "https://example.com/page", nil))
+
+		// Assert
+		require.Equal(t, 1, calls)
+		require.Equal(t, "MISS", miss.Header().Get(middleware.CacheStatusHeader))
+		require.Equal(t, "HIT", hit.Header().Get(middleware.CacheStatusHeader))
+		require.Equal(t, http.StatusOK, hit.Code)
+		require.Equal(t, "val", hit.Header().Get("X-Custom"))
+		require.Equal(t, "body", hit.Body.String())
+
+		// Act
+		store.Delete(middleware.DefaultCacheKey(httptest.NewRequest(http.MethodGet, "https://example.com/page", nil)))
+		serve(h, httptest.NewRequest(http.MethodGet, "https://example.com/page", nil))
+
+		// Assert
+		require.Equal(t, 2, calls)
+	})
+
+	t.Run("Session", func(t *testing.T) {
+		// Arrange
+		calls = 0
+		svc, err := session.NewStoreService(session.Config{
+			Env:         trails.Testing,
+			SessionName: "Test",
+			AuthKey:     "ABCD",
+			EncryptKey:  "ABCD",
+		})
+		require.Nil(t, err)
+
+		cache := middleware.CacheResponse(middleware.NewMemoryCache(), time.Minute, nil)
+		h := middleware.InjectSession(svc)(cache(handler(http.StatusOK, nil)))
+
+		// Act
+		miss := serve(h, httptest.NewRequest(http.MethodGet, "https://example.com/page", nil))
+		hit := serve(h, httptest.NewRequest(http.MethodGet, "https://example.com/page", nil))
+
+		// Assert
+		require.Equal(t, 1, calls)
+		require.Equal(t, "MISS", miss.Header().Get(middleware.CacheStatusHeader))
+		require.Equal(t, "HIT", hit.Header().Get(middleware.CacheStatusHeader))
+		require.Len(t, miss.Result().Cookies(), 1)
+		require.Len(t, hit.Result().Cookies(), 1)
+		require.NotEqual(t, miss.Result().Cookies()[0].Value, hit.Result().Cookies()[0].Value)
+	})
+
+	t.Run("Per-Request", func(t *testing.T) {
+		svc, err := session.NewStoreService(session.Config{
+			Env:         trails.Testing,
+			SessionName: "Test",
+			AuthKey:     "ABCD",
+			EncryptKey:  "ABCD",
+		})
+		require.Nil(t, err)
+
+		echo := func(key trails.Key) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls++
+				val, _ := trails.Value[string](r.Context(), key)
+				w.Write([]byte(val))
+			})
+		}
+
+		cache := middleware.CacheResponse(middleware.NewMemoryCache(), time.Minute, nil)
+		for name, h := range map[string]http.Handler{
+			"CSRF-Before": middleware.InjectSession(svc)(middleware.CSRF(middleware.CSRFOpts{})(cache(echo(trails.CSRFTokenKey)))),
+			"CSRF-After":  middleware.InjectSession(svc)(cache(middleware.CSRF(middleware.CSRFOpts{})(echo(trails.CSRFTokenKey)))),
+			"Nonce": middleware.SecureHeaders(middleware.DefaultSecureHeadersConfig(trails.Testing))(
+				cache(echo(trails.NonceKey)),
+			),
+		} {
+			t.Run(name, func(t *testing.T) {
+				// Arrange
+				calls = 0
+
+				// Act
+				first := serve(h, httptest.NewRequest(http.MethodGet, "https://example.com/"+name, nil))
+				second := serve(h, httptest.NewRequest(http.MethodGet, "https://example.com/"+name, nil))
+
+				// Assert
+				require.Equal(t, 2, calls)
+				require.Equal(t, "MISS", second.Header().Get(middleware.CacheStatusHeader))
+				require.NotEmpty(t, first.Body.String())
+				require.NotEqual(t, first.Body.String(), second.Body.String())
+			})
+		}
+	})
+
+	t.Run("Authenticated", func(t *testing.T) {
+		// Arrange
+		calls = 0
+		h := middleware.CacheResponse(middleware.NewMemoryCache(), time.Minute, nil)(handler(http.StatusOK, nil))
+
+		r := httptest.NewRequest(http.MethodGet, "https://example.com", nil)
+		s, err := session.NewStub(true).GetSession(r)
+		require.Nil(t, err)
+		r = r.WithContext(context.WithValue(r.Context(), trails.SessionKey, s))
+
+		authed := httptest.NewRequest(http.MethodGet, "https://example.com", nil)
+		authed.Header.Set("Authorization", "Bearer token")
+
+		// Act
+		serve(h, r)
+		serve(h, r)
+		serve(h, authed)
+		serve(h, authed)
+
+		// Assert
+		require.Equal(t, 4, calls)
+	})
+
+	t.Run("Not-Storable", func(t *testing.T) {
+		for name, h := range map[string]http.Handler{
+			"Status":     handler(http.StatusNotFound, nil),
+			"Set-Cookie": handler(http.StatusOK, http.Header{"Set-Cookie": {"a=b"}}),
+			"Private":    handler(http.StatusOK, http.Header{"Cache-Control": {"private"}}),
+			"No-Store":   handler(http.StatusOK, http.Header{"Cache-Control": {"no-store"}}),
+			"Vary-Star":  handler(http.StatusOK, http.Header{"Vary": {"*"}}),
+		} {
+			t.Run(name, func(t *testing.T) {
+				// Arrange
+				calls = 0
+				h := middleware.CacheResponse(middleware.NewMemoryCache(), time.Minute, nil)(h)
+
+				// Act
+				serve(h, httptest.NewRequest(http.MethodGet, "https://example.com", nil))
+				serve(h, httptest.NewRequest(http.MethodGet, "https://example.com", nil))
+
+				// Assert
+				require.Equal(t, 2, calls)
+			})
+		}
+
+		// Arrange
+		calls = 0
+		h := middleware.CacheResponse(middleware.NewMemoryCache(), time.Minute, nil)(handler(http.StatusOK, nil))
+
+		// Act
+		serve(h, httptest.NewRequest(http.MethodPost, "https://example.com", nil))
+		serve(h, httptest.NewRequest(http.MethodPost, "https://example.com", nil))
+
+		// Assert
+		require.Equal(t, 2, calls)
+	})
+
+	t.Run("Vary", func(t *testing.T) {
+		// Arrange
+		calls = 0
+		store := middleware.NewMemoryCache()
+		h := middleware.CacheResponse(store, time.Minute, nil)(handler(http.StatusOK, http.Header{"Vary": {"accept-language"}}))
+
+		en := httptest.NewRequest(http.MethodGet, "https://example.com", nil)
+		en.Header.Set("Accept-Language", "en")
+		es := httptest.NewRequest(http.MethodGet, "https://example.com", nil)
+		es.Header.Set("Accept-Language", "es")
+
+		// Act
+		serve(h, en)
+		serve(h, es)
+		enHit := serve(h, en)
+		esHit := serve(h, es)
+
+		// Assert
+		require.Equal(t, 2, calls)
+		require.Equal(t, "enbody", enHit.Body.String())
+		require.Equal(t, "esbody", esHit.Body.String())
+
+		// Act
+		store.Delete(middleware.DefaultCacheKey(en))
+		serve(h, en)
+
+		// Assert
+		require.Equal(t, 3, calls)
+	})
+}
@@ -0,0 +1,65 @@
+package middleware
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// CanonicalHost redirects requests arriving on any host other than host,
+// e.g., a www subdomain, an old domain, or the hostname of a load balancer,
+// to the same path and query on host.
+//
+// host is compared to the Host header of requests ignoring case
+// and includes the port, if not the default one, e.g., "example.com" or "localhost:3000".
+//
+// If permanent is true, CanonicalHost responds with 301,
+// or 308 for requests other than GET or HEAD so browsers repeat the method and body;
+// otherwise, CanonicalHost responds with 302 or 307.
+//
+// The scheme of the redirect is the scheme of the request,
+// preferring the "X-Forwarded-Proto" header set by a proxy; cf. ForceHTTPS.
+//
+// If host is empty, CanonicalHost is a NoopAdapter.
+func CanonicalHost(host string, permanent bool) Adapter {
+	if host == "" {
+		return NoopAdapter
+	}
+
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if strings.EqualFold(r.Host, host) {
+				h.ServeHTTP(w, r)
+				return
+			}
+
+			u := new(url.URL)
+			*u = *r.URL
+			u.Host = host
+			u.Scheme = "http"
+			if r.TLS != nil {
+				u.Scheme = "https"
+			}
+			if proto := r.Header.Get("X-Forwarded-Proto"); proto == "http" || proto == "https" {
+				u.Scheme = proto
+			}
+
+			http.Redirect(w, r, u.String(), canonicalHostCode(r.Method, permanent))
+		})
+	}
+}
+
+// canonicalHostCode is the status CanonicalHost redirects a request using method with.
+func canonicalHostCode(method string, permanent bool) int {
+	safe := method == http.MethodGet || method == http.MethodHead
+	switch {
+	case permanent && safe:
+		return http.StatusMovedPermanently
+	case permanent:
+		return http.StatusPermanentRedirect
+	case safe:
+		return http.StatusFound
+	default:
+		return http.StatusTemporaryRedirect
+	}
+}
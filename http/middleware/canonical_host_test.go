@@ -0,0 +1,59 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/xy-planning-network/trails/http/middleware"
+)
+
+func TestCanonicalHost(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		method    string
+		target    string
+		proto     string
+		permanent bool
+		code      int
+		location  string
+	}{
+		{"Canonical", http.MethodGet, "https://example.com/a", "", true, http.StatusOK, ""},
+		{"Canonical-Case", http.MethodGet, "https://EXAMPLE.com/a", "", true, http.StatusOK, ""},
+		{"WWW", http.MethodGet, "https://www.example.com/a/b?c=d", "", true, http.StatusMovedPermanently, "https://example.com/a/b?c=d"},
+		{"Old-Domain", http.MethodGet, "http://example.net/", "", true, http.StatusMovedPermanently, "http://example.com/"},
+		{"Proxied", http.MethodGet, "http://lb-123.elb.amazonaws.com/a", "https", true, http.StatusMovedPermanently, "https://example.com/a"},
+		{"Post", http.MethodPost, "https://www.example.com/a", "", true, http.StatusPermanentRedirect, "https://example.com/a"},
+		{"Temporary", http.MethodGet, "https://www.example.com/a", "", false, http.StatusFound, "https://example.com/a"},
+		{"Temporary-Post", http.MethodPost, "https://www.example.com/a", "", false, http.StatusTemporaryRedirect, "https://example.com/a"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(tc.method, tc.target, nil)
+			if tc.proto != "" {
+				r.Header.Set("X-Forwarded-Proto", tc.proto)
+			}
+
+			// Act
+			middleware.CanonicalHost("example.com", tc.permanent)(noopHandler()).ServeHTTP(w, r)
+
+			// Assert
+			require.Equal(t, tc.code, w.Code)
+			require.Equal(t, tc.location, w.Header().Get("Location"))
+		})
+	}
+
+	t.Run("No-Host", func(t *testing.T) {
+		// Arrange
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "https://www.example.com", nil)
+
+		// Act
+		middleware.CanonicalHost("", true)(noopHandler()).ServeHTTP(w, r)
+
+		// Assert
+		require.Equal(t, http.StatusOK, w.Code)
+	})
+}
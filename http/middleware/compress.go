@@ -0,0 +1,260 @@
+package middleware
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const (
+	acceptEncodingHeader  = "Accept-Encoding"
+	contentEncodingHeader = "Content-Encoding"
+
+	// DefaultCompressMinSize is the number of bytes a response must reach before it is compressed.
+	DefaultCompressMinSize = 1024
+
+	// DefaultMaxDecompressedSize is the number of bytes a decompressed request body may reach.
+	DefaultMaxDecompressedSize = 10 << 20
+)
+
+// CompressOpts configures the Compress middleware.
+type CompressOpts struct {
+	// CompressResponses turns on gzip compression of responses to clients accepting it.
+	CompressResponses bool
+
+	// Level is the gzip compression level responses are compressed with.
+	//
+	// If Level is 0, gzip.DefaultCompression is used.
+	Level int
+
+	// MaxDecompressedSize limits the number of bytes a handler can read from a decompressed request body.
+	//
+	// If MaxDecompressedSize is 0, DefaultMaxDecompressedSize is used.
+	MaxDecompressedSize int64
+
+	// MinSize is the number of bytes a response must reach before it is compressed.
+	//
+	// If MinSize is 0, DefaultCompressMinSize is used.
+	MinSize int
+}
+
+// Compress transparently decompresses request bodies with a "Content-Encoding" of "gzip" or "deflate",
+// responding with 415 to other encodings and 400 to malformed bodies.
+// Reading more than CompressOpts.MaxDecompressedSize bytes from a decompressed body fails.
+//
+// When CompressOpts.CompressResponses is true,
+// Compress gzips responses of at least CompressOpts.MinSize bytes
+// to requests with an "Accept-Encoding" header including "gzip".
+// Handlers, or other middlewares, that compress a response themselves ought to set "Content-Encoding";
+// Compress leaves any such response as is, so nothing is compressed twice.
+func Compress(opts CompressOpts) Adapter {
+	if opts.Level == 0 {
+		opts.Level = gzip.DefaultCompression
+	}
+
+	if opts.MaxDecompressedSize == 0 {
+		opts.MaxDecompressedSize = DefaultMaxDecompressedSize
+	}
+
+	if opts.MinSize == 0 {
+		opts.MinSize = DefaultCompressMinSize
+	}
+
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if enc := r.Header.Get(contentEncodingHeader); enc != "" && r.Body != nil && r.Body != http.NoBody {
+				body, code := decompressBody(r.Body, enc)
+				if code != 0 {
+					http.Error(w, http.StatusText(code), code)
+					return
+				}
+
+				defer body.Close()
+				r.Body = http.MaxBytesReader(w, body, opts.MaxDecompressedSize)
+				r.ContentLength = -1
+				r.Header.Del(contentEncodingHeader)
+				r.Header.Del(contentLenHeader)
+			}
+
+			if !opts.CompressResponses || r.Method == http.MethodHead || !acceptsGzip(r) {
+				h.ServeHTTP(w, r)
+				return
+			}
+
+			w.Header().Add(varyHeader, acceptEncodingHeader)
+			gw := &gzipWriter{ResponseWriter: w, level: opts.Level, minSize: opts.MinSize, status: http.StatusOK}
+			defer gw.close()
+
+			h.ServeHTTP(gw, r)
+		})
+	}
+}
+
+// decompressBody wraps body in a reader decoding enc,
+// returning an HTTP status code other than 0 if it cannot.
+func decompressBody(body io.ReadCloser, enc string) (io.ReadCloser, int) {
+	var (
+		rc  io.ReadCloser
+		err error
+	)
+
+	switch strings.ToLower(strings.TrimSpace(enc)) {
+	case "gzip", "x-gzip":
+		rc, err = gzip.NewReader(body)
+	case "deflate":
+		rc, err = zlib.NewReader(body)
+	case "identity":
+		return body, 0
+	default:
+		return nil, http.StatusUnsupportedMediaType
+	}
+
+	if err != nil {
+		return nil, http.StatusBadRequest
+	}
+
+	return rc, 0
+}
+
+// acceptsGzip asserts whether the request's "Accept-Encoding" header allows gzip.
+func acceptsGzip(r *http.Request) bool {
+	for _, v := range r.Header.Values(acceptEncodingHeader) {
+		for _, enc := range strings.Split(v, ",") {
+			name, params, _ := strings.Cut(strings.TrimSpace(enc), ";")
+			if !strings.EqualFold(strings.TrimSpace(name), "gzip") {
+				continue
+			}
+
+			q, ok := strings.CutPrefix(strings.TrimSpace(params), "q=")
+			if !ok {
+				return true
+			}
+
+			weight, err := strconv.ParseFloat(q, 64)
+			return err == nil && weight > 0
+		}
+	}
+
+	return false
+}
+
+// A gzipWriter buffers a response until it reaches minSize bytes,
+// at which point it starts gzipping the response.
+// Responses never reaching minSize, or already encoded, are written as is.
+type gzipWriter struct {
+	http.ResponseWriter
+	buf         bytes.Buffer
+	decided     bool
+	gz          *gzip.Writer
+	level       int
+	minSize     int
+	status      int
+	wroteHeader bool
+}
+
+// WriteHeader holds onto the status until gzipWriter decides whether to compress the response.
+func (gw *gzipWriter) WriteHeader(code int) {
+	if gw.wroteHeader {
+		return
+	}
+
+	gw.status = code
+	gw.wroteHeader = true
+
+	// NOTE: these responses have no body to compress.
+	if code < http.StatusOK || code == http.StatusNoContent || code == http.StatusNotModified {
+		gw.decide(false)
+	}
+}
+
+// Write buffers b until deciding whether to compress the response.
+func (gw *gzipWriter) Write(b []byte) (int, error) {
+	gw.wroteHeader = true
+	if !gw.decided {
+		if gw.Header().Get(contentEncodingHeader) != "" {
+			gw.decide(false)
+		} else {
+			gw.buf.Write(b)
+			if gw.buf.Len() < gw.minSize {
+				return len(b), nil
+			}
+
+			if err := gw.decide(true); err != nil {
+				return 0, err
+			}
+
+			return len(b), nil
+		}
+	}
+
+	if gw.gz != nil {
+		return gw.gz.Write(b)
+	}
+
+	return gw.ResponseWriter.Write(b)
+}
+
+// Flush compresses what is buffered and flushes it to the client.
+func (gw *gzipWriter) Flush() {
+	if !gw.decided {
+		gw.decide(gw.Header().Get(contentEncodingHeader) == "")
+	}
+
+	if gw.gz != nil {
+		gw.gz.Flush()
+	}
+
+	http.NewResponseController(gw.ResponseWriter).Flush()
+}
+
+// Unwrap exposes the underlying http.ResponseWriter to an http.ResponseController.
+func (gw *gzipWriter) Unwrap() http.ResponseWriter { return gw.ResponseWriter }
+
+// decide writes the status and anything buffered to the client, compressing it if compress is true.
+func (gw *gzipWriter) decide(compress bool) error {
+	gw.decided = true
+	if compress {
+		gz, err := gzip.NewWriterLevel(gw.ResponseWriter, gw.level)
+		if err != nil {
+			return err
+		}
+
+		gw.gz = gz
+		gw.Header().Set(contentEncodingHeader, "gzip")
+		gw.Header().Del(contentLenHeader)
+	}
+
+	gw.ResponseWriter.WriteHeader(gw.status)
+	if gw.buf.Len() == 0 {
+		return nil
+	}
+
+	var err error
+	if gw.gz != nil {
+		_, err = gw.gz.Write(gw.buf.Bytes())
+	} else {
+		_, err = gw.ResponseWriter.Write(gw.buf.Bytes())
+	}
+
+	gw.buf.Reset()
+	return err
+}
+
+// close writes out a response that never reached minSize or finishes the gzip stream.
+func (gw *gzipWriter) close() {
+	if !gw.decided {
+		if !gw.wroteHeader {
+			return
+		}
+
+		gw.decide(false)
+	}
+
+	if gw.gz != nil {
+		gw.gz.Close()
+	}
+}
@@ -0,0 +1,154 @@
+package middleware_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/xy-planning-network/trails/http/middleware"
+)
+
+func TestCompressRequest(t *testing.T) {
+	gzipped := func(s string) *bytes.Buffer {
+		b := new(bytes.Buffer)
+		gz := gzip.NewWriter(b)
+		gz.Write([]byte(s))
+		gz.Close()
+		return b
+	}
+
+	deflated := func(s string) *bytes.Buffer {
+		b := new(bytes.Buffer)
+		zw := zlib.NewWriter(b)
+		zw.Write([]byte(s))
+		zw.Close()
+		return b
+	}
+
+	for _, tc := range []struct {
+		name     string
+		enc      string
+		body     io.Reader
+		code     int
+		expected string
+	}{
+		{"Gzip", "gzip", gzipped(`{"a":1}`), http.StatusOK, `{"a":1}`},
+		{"Deflate", "deflate", deflated(`{"a":1}`), http.StatusOK, `{"a":1}`},
+		{"Identity", "identity", strings.NewReader(`{"a":1}`), http.StatusOK, `{"a":1}`},
+		{"Malformed", "gzip", strings.NewReader(`{"a":1}`), http.StatusBadRequest, ""},
+		{"Unsupported", "br", strings.NewReader(`{"a":1}`), http.StatusUnsupportedMediaType, ""},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			var actual []byte
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPost, "https://example.com", tc.body)
+			r.Header.Set("Content-Encoding", tc.enc)
+			h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				require.Empty(t, r.Header.Get("Content-Encoding"))
+				actual, _ = io.ReadAll(r.Body)
+			})
+
+			// Act
+			middleware.Compress(middleware.CompressOpts{})(h).ServeHTTP(w, r)
+
+			// Assert
+			require.Equal(t, tc.code, w.Code)
+			require.Equal(t, tc.expected, string(actual))
+		})
+	}
+
+	t.Run("Too-Large", func(t *testing.T) {
+		// Arrange
+		var err error
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "https://example.com", gzipped(strings.Repeat("a", 100)))
+		r.Header.Set("Content-Encoding", "gzip")
+		h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, err = io.ReadAll(r.Body)
+		})
+
+		// Act
+		middleware.Compress(middleware.CompressOpts{MaxDecompressedSize: 10})(h).ServeHTTP(w, r)
+
+		// Assert
+		var maxErr *http.MaxBytesError
+		require.ErrorAs(t, err, &maxErr)
+	})
+}
+
+func TestCompressResponse(t *testing.T) {
+	large := strings.Repeat("trails ", 500)
+	handler := func(body string, header http.Header) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			for k, v := range header {
+				w.Header()[k] = v
+			}
+
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(body))
+		})
+	}
+
+	t.Run("Compressed", func(t *testing.T) {
+		// Arrange
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "https://example.com", nil)
+		r.Header.Set("Accept-Encoding", "br, gzip;q=0.8")
+
+		// Act
+		middleware.Compress(middleware.CompressOpts{CompressResponses: true})(handler(large, nil)).ServeHTTP(w, r)
+
+		// Assert
+		require.Equal(t, http.StatusCreated, w.Code)
+		require.Equal(t, "gzip", w.Header().Get("Content-Encoding"))
+		require.Equal(t, "Accept-Encoding", w.Header().Get("Vary"))
+
+		gz, err := gzip.NewReader(w.Body)
+		require.Nil(t, err)
+		actual, err := io.ReadAll(gz)
+		require.Nil(t, err)
+		require.Equal(t, large, string(actual))
+	})
+
+	for _, tc := range []struct {
+		name   string
+		accept string
+		body   string
+		header http.Header
+		opts   middleware.CompressOpts
+	}{
+		{"Off", "gzip", large, nil, middleware.CompressOpts{}},
+		{"Not-Accepted", "br", large, nil, middleware.CompressOpts{CompressResponses: true}},
+		{"Refused", "gzip;q=0", large, nil, middleware.CompressOpts{CompressResponses: true}},
+		{"Too-Small", "gzip", "small", nil, middleware.CompressOpts{CompressResponses: true}},
+		{
+			"Already-Encoded",
+			"gzip",
+			large,
+			http.Header{"Content-Encoding": {"br"}},
+			middleware.CompressOpts{CompressResponses: true},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodGet, "https://example.com", nil)
+			r.Header.Set("Accept-Encoding", tc.accept)
+
+			// Act
+			middleware.Compress(tc.opts)(handler(tc.body, tc.header)).ServeHTTP(w, r)
+
+			// Assert
+			require.Equal(t, http.StatusCreated, w.Code)
+			require.NotEqual(t, "gzip", w.Header().Get("Content-Encoding"))
+			require.Equal(t, tc.body, w.Body.String())
+		})
+	}
+}
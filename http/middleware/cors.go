@@ -2,13 +2,67 @@ package middleware
 
 import (
 	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	originHeader = "Origin"
 
-	"github.com/gorilla/handlers"
+	acAllowCredentialsHeader = "Access-Control-Allow-Credentials"
+	acAllowHeadersHeader     = "Access-Control-Allow-Headers"
+	acAllowMethodsHeader     = "Access-Control-Allow-Methods"
+	acAllowOriginHeader      = "Access-Control-Allow-Origin"
+	acExposeHeadersHeader    = "Access-Control-Expose-Headers"
+	acMaxAgeHeader           = "Access-Control-Max-Age"
+	acRequestHeadersHeader   = "Access-Control-Request-Headers"
+	acRequestMethodHeader    = "Access-Control-Request-Method"
 )
 
-// CORS sets "Access-Control-Allowed" style headers on a response.
-// The handler including this middleware must also handle the http.MethodOptions method
-// and not just the HTTP method it's designed for.
+// A CORSPolicy describes which cross-origin requests are allowed.
+//
+// Different route groups can use different CORSPolicys; cf. [router.Router.CORS].
+type CORSPolicy struct {
+	// AllowCredentials allows requests to include cookies and other credentials.
+	//
+	// When true, AllowedOrigins cannot contain "*", since any origin could then make requests with a user's credentials,
+	// and "*" in AllowedHeaders is answered with the specific headers requested, since browsers reject "*" with credentials.
+	AllowCredentials bool
+
+	// AllowedHeaders lists the request headers allowed, matched case-insensitively.
+	// "*" allows any header.
+	//
+	// If AllowedHeaders is empty, only "Content-Type" is allowed.
+	AllowedHeaders []string
+
+	// AllowedMethods lists the HTTP methods allowed.
+	//
+	// If AllowedMethods is empty, GET, HEAD, and POST are allowed.
+	AllowedMethods []string
+
+	// AllowedOrigins lists the origins allowed, e.g., "https://example.com".
+	// "*" allows any origin.
+	// A single "*" within an origin allows any value in its place, e.g., "https://*.example.com".
+	//
+	// AllowedOrigins cannot be empty.
+	AllowedOrigins []string
+
+	// ExposedHeaders lists the response headers clients may read.
+	ExposedHeaders []string
+
+	// MaxAge is how long browsers may cache the response to a preflight request.
+	//
+	// If MaxAge is 0, browsers use their default.
+	MaxAge time.Duration
+}
+
+// CORS allows cross-origin requests from base,
+// using the HTTP methods DELETE, GET, HEAD, OPTIONS, POST, and PUT
+// and the headers "Content-Type" and CSRFHeader.
+//
+// Use CORSWithPolicy for greater control.
 //
 // If base is it's zero-value, NoopAdapter returns and this middleware does nothing.
 func CORS(base string) Adapter {
@@ -16,19 +70,166 @@ func CORS(base string) Adapter {
 		return NoopAdapter
 	}
 
-	return handlers.CORS(
-		handlers.AllowedHeaders([]string{
-			"Content-Type",
-			"X-CSRF-Token",
-		}),
-		handlers.AllowedOrigins([]string{base}),
-		handlers.AllowedMethods([]string{
+	return CORSWithPolicy(CORSPolicy{
+		AllowedHeaders: []string{
+			contentTypeHeader,
+			CSRFHeader,
+		},
+		AllowedMethods: []string{
 			http.MethodDelete,
 			http.MethodGet,
 			http.MethodHead,
 			http.MethodOptions,
 			http.MethodPost,
 			http.MethodPut,
-		}),
-	)
+		},
+		AllowedOrigins: []string{base},
+	})
+}
+
+// CORSWithPolicy sets "Access-Control-Allow" style headers on responses to requests
+// the CORSPolicy allows.
+// Responses always vary by the "Origin" header.
+//
+// CORSWithPolicy responds to preflight requests itself,
+// with 204 when the CORSPolicy allows the request and 403 when it does not.
+// Since a handler only receives requests using the HTTP methods it is registered for,
+// preflight requests - which use OPTIONS - must be routed to this middleware;
+// [router.Router.CORS] does so.
+//
+// CORSWithPolicy panics if p.AllowedOrigins is empty, or contains "*" while p.AllowCredentials is true,
+// since either is a mistake better found on startup than by clients whose requests fail.
+func CORSWithPolicy(p CORSPolicy) Adapter {
+	if len(p.AllowedOrigins) == 0 {
+		panic("middleware: CORSPolicy.AllowedOrigins is empty")
+	}
+
+	if p.AllowCredentials && slices.Contains(p.AllowedOrigins, "*") {
+		panic(`middleware: CORSPolicy.AllowedOrigins cannot contain "*" when CORSPolicy.AllowCredentials is true`)
+	}
+
+	if len(p.AllowedHeaders) == 0 {
+		p.AllowedHeaders = []string{contentTypeHeader}
+	}
+
+	if len(p.AllowedMethods) == 0 {
+		p.AllowedMethods = []string{http.MethodGet, http.MethodHead, http.MethodPost}
+	}
+
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			header := w.Header()
+			header.Add(varyHeader, originHeader)
+
+			origin := r.Header.Get(originHeader)
+			preflight := r.Method == http.MethodOptions && r.Header.Get(acRequestMethodHeader) != ""
+			if preflight {
+				header.Add(varyHeader, acRequestMethodHeader)
+				header.Add(varyHeader, acRequestHeadersHeader)
+			}
+
+			if origin == "" {
+				h.ServeHTTP(w, r)
+				return
+			}
+
+			if !p.allowsOrigin(origin) {
+				if preflight {
+					w.WriteHeader(http.StatusForbidden)
+					return
+				}
+
+				h.ServeHTTP(w, r)
+				return
+			}
+
+			if preflight {
+				reqHeaders, ok := p.allowsHeaders(r.Header.Values(acRequestHeadersHeader))
+				if !ok || !slices.Contains(p.AllowedMethods, r.Header.Get(acRequestMethodHeader)) {
+					w.WriteHeader(http.StatusForbidden)
+					return
+				}
+
+				p.setOrigin(header, origin)
+				header.Set(acAllowMethodsHeader, strings.Join(p.AllowedMethods, ", "))
+				if len(reqHeaders) > 0 {
+					header.Set(acAllowHeadersHeader, strings.Join(reqHeaders, ", "))
+				}
+
+				if p.MaxAge > 0 {
+					header.Set(acMaxAgeHeader, strconv.Itoa(int(p.MaxAge.Seconds())))
+				}
+
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+
+			p.setOrigin(header, origin)
+			if len(p.ExposedHeaders) > 0 {
+				header.Set(acExposeHeadersHeader, strings.Join(p.ExposedHeaders, ", "))
+			}
+
+			h.ServeHTTP(w, r)
+		})
+	}
+}
+
+// allowsHeaders checks whether each of the comma-separated request headers are allowed,
+// returning them.
+func (p CORSPolicy) allowsHeaders(vals []string) ([]string, bool) {
+	var headers []string
+	for _, v := range vals {
+		for _, name := range strings.Split(v, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				headers = append(headers, name)
+			}
+		}
+	}
+
+	if slices.Contains(p.AllowedHeaders, "*") {
+		return headers, true
+	}
+
+	for _, name := range headers {
+		if !slices.ContainsFunc(p.AllowedHeaders, func(allowed string) bool { return strings.EqualFold(allowed, name) }) {
+			return nil, false
+		}
+	}
+
+	return headers, true
+}
+
+// allowsOrigin checks whether the origin matches any of the allowed origins.
+func (p CORSPolicy) allowsOrigin(origin string) bool {
+	origin = strings.ToLower(origin)
+	for _, allowed := range p.AllowedOrigins {
+		allowed = strings.ToLower(allowed)
+		if allowed == "*" || allowed == origin {
+			return true
+		}
+
+		prefix, suffix, ok := strings.Cut(allowed, "*")
+		if ok && len(origin) > len(prefix)+len(suffix) &&
+			strings.HasPrefix(origin, prefix) && strings.HasSuffix(origin, suffix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// setOrigin sets the headers allowing the origin access to the response.
+func (p CORSPolicy) setOrigin(header http.Header, origin string) {
+	if p.AllowCredentials {
+		header.Set(acAllowCredentialsHeader, "true")
+		header.Set(acAllowOriginHeader, origin)
+		return
+	}
+
+	if slices.Contains(p.AllowedOrigins, "*") {
+		header.Set(acAllowOriginHeader, "*")
+		return
+	}
+
+	header.Set(acAllowOriginHeader, origin)
 }
@@ -2,7 +2,10 @@ package middleware_test
 
 import (
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 	"github.com/xy-planning-network/trails/http/middleware"
@@ -21,3 +24,148 @@ func TestCORS(t *testing.T) {
 	// Assert
 	require.NotEqual(t, fmt.Sprintf("%p", middleware.NoopAdapter), fmt.Sprintf("%p", actual))
 }
+
+func TestCORSWithPolicy(t *testing.T) {
+	// Arrange + Act + Assert
+	require.Panics(t, func() { middleware.CORSWithPolicy(middleware.CORSPolicy{}) })
+	require.Panics(t, func() { middleware.CORSWithPolicy(middleware.CORSPolicy{AllowCredentials: true}) })
+	require.Panics(t, func() {
+		middleware.CORSWithPolicy(middleware.CORSPolicy{AllowedOrigins: []string{"https://example.com", "*"}, AllowCredentials: true})
+	})
+	require.NotPanics(t, func() { middleware.CORSWithPolicy(middleware.CORSPolicy{AllowedOrigins: []string{"*"}}) })
+
+	policy := middleware.CORSPolicy{
+		AllowedHeaders: []string{"Content-Type", "X-Custom"},
+		AllowedMethods: []string{http.MethodGet, http.MethodPut},
+		AllowedOrigins: []string{"https://example.com", "https://*.example.org"},
+		ExposedHeaders: []string{"X-Total"},
+		MaxAge:         time.Hour,
+	}
+
+	tcs := []struct {
+		name     string
+		policy   middleware.CORSPolicy
+		method   string
+		header   http.Header
+		code     int
+		expected http.Header
+	}{
+		{
+			"No-Origin",
+			policy,
+			http.MethodGet,
+			http.Header{},
+			http.StatusTeapot,
+			http.Header{"Vary": {"Origin"}},
+		},
+		{
+			"Simple",
+			policy,
+			http.MethodGet,
+			http.Header{"Origin": {"https://example.com"}},
+			http.StatusTeapot,
+			http.Header{
+				"Vary":                          {"Origin"},
+				"Access-Control-Allow-Origin":   {"https://example.com"},
+				"Access-Control-Expose-Headers": {"X-Total"},
+			},
+		},
+		{
+			"Wildcard-Subdomain",
+			policy,
+			http.MethodGet,
+			http.Header{"Origin": {"https://app.example.org"}},
+			http.StatusTeapot,
+			http.Header{
+				"Vary":                          {"Origin"},
+				"Access-Control-Allow-Origin":   {"https://app.example.org"},
+				"Access-Control-Expose-Headers": {"X-Total"},
+			},
+		},
+		{
+			"Disallowed-Origin",
+			policy,
+			http.MethodGet,
+			http.Header{"Origin": {"https://example.org"}},
+			http.StatusTeapot,
+			http.Header{"Vary": {"Origin"}},
+		},
+		{
+			"Preflight",
+			policy,
+			http.MethodOptions,
+			http.Header{
+				"Origin":                         {"https://example.com"},
+				"Access-Control-Request-Method":  {http.MethodPut},
+				"Access-Control-Request-Headers": {"x-custom, content-type"},
+			},
+			http.StatusNoContent,
+			http.Header{
+				"Vary":                         {"Origin", "Access-Control-Request-Method", "Access-Control-Request-Headers"},
+				"Access-Control-Allow-Origin":  {"https://example.com"},
+				"Access-Control-Allow-Methods": {"GET, PUT"},
+				"Access-Control-Allow-Headers": {"x-custom, content-type"},
+				"Access-Control-Max-Age":       {"3600"},
+			},
+		},
+		{
+			"Preflight-Disallowed-Method",
+			policy,
+			http.MethodOptions,
+			http.Header{
+				"Origin":                        {"https://example.com"},
+				"Access-Control-Request-Method": {http.MethodDelete},
+			},
+			http.StatusForbidden,
+			http.Header{"Vary": {"Origin", "Access-Control-Request-Method", "Access-Control-Request-Headers"}},
+		},
+		{
+			"Preflight-Disallowed-Header",
+			policy,
+			http.MethodOptions,
+			http.Header{
+				"Origin":                         {"https://example.com"},
+				"Access-Control-Request-Method":  {http.MethodGet},
+				"Access-Control-Request-Headers": {"Authorization"},
+			},
+			http.StatusForbidden,
+			http.Header{"Vary": {"Origin", "Access-Control-Request-Method", "Access-Control-Request-Headers"}},
+		},
+		{
+			"Any-Origin",
+			middleware.CORSPolicy{AllowedOrigins: []string{"*"}},
+			http.MethodGet,
+			http.Header{"Origin": {"https://anywhere.com"}},
+			http.StatusTeapot,
+			http.Header{"Vary": {"Origin"}, "Access-Control-Allow-Origin": {"*"}},
+		},
+		{
+			"Credentials",
+			middleware.CORSPolicy{AllowedOrigins: []string{"https://*.example.org"}, AllowCredentials: true},
+			http.MethodGet,
+			http.Header{"Origin": {"https://app.example.org"}},
+			http.StatusTeapot,
+			http.Header{
+				"Vary":                             {"Origin"},
+				"Access-Control-Allow-Origin":      {"https://app.example.org"},
+				"Access-Control-Allow-Credentials": {"true"},
+			},
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(tc.method, "https://api.example.com", nil)
+			r.Header = tc.header
+
+			// Act
+			middleware.CORSWithPolicy(tc.policy)(teapotHandler()).ServeHTTP(w, r)
+
+			// Assert
+			require.Equal(t, tc.code, w.Code)
+			require.Equal(t, tc.expected, w.Header())
+		})
+	}
+}
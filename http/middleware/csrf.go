@@ -0,0 +1,142 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"mime"
+	"net/http"
+
+	"github.com/xy-planning-network/trails"
+	"github.com/xy-planning-network/trails/http/session"
+	"github.com/xy-planning-network/trails/http/template"
+)
+
+const (
+	// CSRFHeader is the HTTP header carrying the token protecting against cross-site request forgery.
+	CSRFHeader = "X-CSRF-Token"
+
+	csrfTokenLen = 32
+)
+
+// CSRFOpts configures the CSRF middleware.
+type CSRFOpts struct {
+	// Exempt asserts whether the request skips checking for a token,
+	// e.g., webhooks authenticated by other means.
+	//
+	// If Exempt is nil, no request is exempt.
+	Exempt func(*http.Request) bool
+
+	// Failed handles requests failing the check.
+	//
+	// If Failed is nil, requests failing the check are responded to with 403.
+	Failed http.Handler
+}
+
+// CSRF protects against cross-site request forgery using synchronizer tokens stored in the session.
+//
+// CSRF requires InjectSession to come before it in the middleware chain.
+//
+// On every request, CSRF ensures the session has a token, generating one if necessary,
+// and stashes it in the *http.Request.Context under trails.CSRFTokenKey.
+// [*resp.Responder.Html] makes that token available to templates
+// through the "csrfField" and "csrfToken" functions,
+// and [resp.Vue] includes it in "initialProps" as "csrfToken".
+//
+// For requests using methods other than GET, HEAD, OPTIONS, or TRACE,
+// CSRF compares the token in the session to the one submitted.
+// JSON requests must submit the token in the CSRFHeader header.
+// Other requests may use that header or the template.CSRFFieldName form field.
+func CSRF(opts CSRFOpts) Adapter {
+	failed := opts.Failed
+	if failed == nil {
+		failed = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusForbidden)
+		})
+	}
+
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if opts.Exempt != nil && opts.Exempt(r) {
+				h.ServeHTTP(w, r)
+				return
+			}
+
+			s, ok := trails.Value[session.Session](r.Context(), trails.SessionKey)
+			if !ok {
+				if isSafeMethod(r.Method) {
+					h.ServeHTTP(w, r)
+					return
+				}
+
+				failed.ServeHTTP(w, r)
+				return
+			}
+
+			token, err := s.CSRFToken()
+			if err != nil {
+				token, err = newCSRFToken()
+				if err != nil {
+					failed.ServeHTTP(w, r)
+					return
+				}
+
+				if err := s.SetCSRFToken(w, r, token); err != nil {
+					failed.ServeHTTP(w, r)
+					return
+				}
+			}
+
+			if !isSafeMethod(r.Method) && !validCSRFToken(token, submittedCSRFToken(r)) {
+				failed.ServeHTTP(w, r)
+				return
+			}
+
+			*r = *r.Clone(trails.WithValue(r.Context(), trails.CSRFTokenKey, token))
+			h.ServeHTTP(w, r)
+		})
+	}
+}
+
+// isSafeMethod asserts whether the HTTP method is one that ought not change state.
+func isSafeMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+		return true
+	default:
+		return false
+	}
+}
+
+// newCSRFToken generates a random, URL-safe token.
+func newCSRFToken() (string, error) {
+	b := make([]byte, csrfTokenLen)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// submittedCSRFToken retrieves the token from the header or, for non-JSON requests, the form.
+func submittedCSRFToken(r *http.Request) string {
+	if token := r.Header.Get(CSRFHeader); token != "" {
+		return token
+	}
+
+	mt, _, _ := mime.ParseMediaType(r.Header.Get(contentTypeHeader))
+	if mt == "application/json" {
+		return ""
+	}
+
+	return r.FormValue(template.CSRFFieldName)
+}
+
+// validCSRFToken compares the tokens in constant time.
+func validCSRFToken(expected, actual string) bool {
+	if expected == "" || actual == "" {
+		return false
+	}
+
+	return subtle.ConstantTimeCompare([]byte(expected), []byte(actual)) == 1
+}
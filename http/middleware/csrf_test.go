@@ -0,0 +1,131 @@
+package middleware_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/xy-planning-network/trails"
+	"github.com/xy-planning-network/trails/http/middleware"
+	"github.com/xy-planning-network/trails/http/session"
+	"github.com/xy-planning-network/trails/http/template"
+)
+
+func TestCSRF(t *testing.T) {
+	// Arrange
+	store := session.NewStub(false)
+	s, _ := store.GetSession(httptest.NewRequest(http.MethodGet, "https://example.com", nil))
+
+	withSession := func(r *http.Request) *http.Request {
+		return r.WithContext(context.WithValue(r.Context(), trails.SessionKey, s))
+	}
+
+	var token string
+	w := httptest.NewRecorder()
+	r := withSession(httptest.NewRequest(http.MethodGet, "https://example.com", nil))
+
+	// Act
+	middleware.CSRF(middleware.CSRFOpts{})(http.HandlerFunc(func(wx http.ResponseWriter, rx *http.Request) {
+		token, _ = rx.Context().Value(trails.CSRFTokenKey).(string)
+		wx.WriteHeader(http.StatusTeapot)
+	})).ServeHTTP(w, r)
+
+	// Assert
+	require.Equal(t, http.StatusTeapot, w.Code)
+	require.NotZero(t, token)
+
+	stored, err := s.CSRFToken()
+	require.Nil(t, err)
+	require.Equal(t, token, stored)
+
+	form := url.Values{template.CSRFFieldName: {token}}.Encode()
+
+	for _, tc := range []struct {
+		name     string
+		r        *http.Request
+		opts     middleware.CSRFOpts
+		expected int
+	}{
+		{
+			"No-Session",
+			httptest.NewRequest(http.MethodPost, "https://example.com", nil),
+			middleware.CSRFOpts{},
+			http.StatusForbidden,
+		},
+		{
+			"No-Token",
+			withSession(httptest.NewRequest(http.MethodPost, "https://example.com", nil)),
+			middleware.CSRFOpts{},
+			http.StatusForbidden,
+		},
+		{
+			"Wrong-Token",
+			func() *http.Request {
+				r := withSession(httptest.NewRequest(http.MethodPost, "https://example.com", nil))
+				r.Header.Set(middleware.CSRFHeader, "wrong")
+				return r
+			}(),
+			middleware.CSRFOpts{},
+			http.StatusForbidden,
+		},
+		{
+			"Custom-Failed",
+			withSession(httptest.NewRequest(http.MethodPost, "https://example.com", nil)),
+			middleware.CSRFOpts{Failed: http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusBadRequest)
+			})},
+			http.StatusBadRequest,
+		},
+		{
+			"Exempt",
+			withSession(httptest.NewRequest(http.MethodPost, "https://example.com", nil)),
+			middleware.CSRFOpts{Exempt: func(*http.Request) bool { return true }},
+			http.StatusTeapot,
+		},
+		{
+			"Header",
+			func() *http.Request {
+				r := withSession(httptest.NewRequest(http.MethodPost, "https://example.com", nil))
+				r.Header.Set(middleware.CSRFHeader, token)
+				return r
+			}(),
+			middleware.CSRFOpts{},
+			http.StatusTeapot,
+		},
+		{
+			"Form",
+			func() *http.Request {
+				r := withSession(httptest.NewRequest(http.MethodPost, "https://example.com", strings.NewReader(form)))
+				r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+				return r
+			}(),
+			middleware.CSRFOpts{},
+			http.StatusTeapot,
+		},
+		{
+			"JSON-Requires-Header",
+			func() *http.Request {
+				r := withSession(httptest.NewRequest(http.MethodPost, "https://example.com?"+form, nil))
+				r.Header.Set("Content-Type", "application/json")
+				return r
+			}(),
+			middleware.CSRFOpts{},
+			http.StatusForbidden,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			w := httptest.NewRecorder()
+
+			// Act
+			middleware.CSRF(tc.opts)(teapotHandler()).ServeHTTP(w, tc.r)
+
+			// Assert
+			require.Equal(t, tc.expected, w.Code)
+		})
+	}
+}
@@ -1,7 +1,6 @@
 package middleware
 
 import (
-	"context"
 	"net/http"
 	"net/url"
 	"strings"
@@ -36,7 +35,7 @@ func CurrentUser(d *resp.Responder, storer UserStorer) Adapter {
 
 	return func(handler http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			s, ok := r.Context().Value(trails.SessionKey).(session.Session)
+			s, ok := trails.Value[session.Session](r.Context(), trails.SessionKey)
 			if !ok {
 				handleErr(w, r, http.StatusUnauthorized, d, nil)
 				return
@@ -82,7 +81,7 @@ func CurrentUser(d *resp.Responder, storer UserStorer) Adapter {
 			w.Header().Add("Cache-control", "no-store")
 			w.Header().Add("Pragma", "no-cache")
 
-			ctx := context.WithValue(r.Context(), trails.CurrentUserKey, user)
+			ctx := trails.WithValue(r.Context(), trails.CurrentUserKey, user)
 			*r = *r.Clone(ctx)
 			handler.ServeHTTP(w, r)
 		})
@@ -102,7 +101,7 @@ func CurrentUser(d *resp.Responder, storer UserStorer) Adapter {
 func RequireUnauthed() Adapter {
 	return func(handler http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			if cu, ok := r.Context().Value(trails.CurrentUserKey).(User); ok {
+			if cu, ok := trails.Value[User](r.Context(), trails.CurrentUserKey); ok {
 				vs := r.Header.Values("Accept")
 				for _, v := range vs {
 					if strings.Compare(v, "application/json") == 0 {
@@ -136,7 +135,7 @@ func RequireUnauthed() Adapter {
 func RequireAuthed(loginUrl, logoffUrl string) Adapter {
 	return func(handler http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			if _, ok := r.Context().Value(trails.CurrentUserKey).(User); !ok {
+			if _, ok := trails.Value[User](r.Context(), trails.CurrentUserKey); !ok {
 				vs := r.Header.Values("Accept")
 				for _, v := range vs {
 					if strings.Compare(v, "application/json") == 0 {
@@ -0,0 +1,142 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/xy-planning-network/trails"
+	"github.com/xy-planning-network/trails/logger"
+	"gorm.io/gorm"
+)
+
+// txKey stashes the *gorm.DB transaction DBTransaction opens for a request.
+const txKey trails.Key = "DBTxKey"
+
+// DBTransaction opens a transaction on db for each mutating request, i.e., DELETE, PATCH, POST, or PUT,
+// stashing it in the *http.Request.Context, retrieved with TxFromContext,
+// so every write a handler makes succeeds or fails together.
+//
+// DBTransaction commits the transaction when the handler responds with a 1xx, 2xx, or 3xx status,
+// just before the status is written, so a failure to commit responds with 500 instead.
+// DBTransaction rolls the transaction back when the handler responds with a 4xx or 5xx status or panics.
+//
+// DBTransaction logs failures to begin, commit, or roll back a transaction using l, if it is not nil.
+//
+// If db is nil, DBTransaction is a NoopAdapter.
+func DBTransaction(db *gorm.DB, l logger.Logger) Adapter {
+	if db == nil {
+		return NoopAdapter
+	}
+
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.Method {
+			case http.MethodDelete, http.MethodPatch, http.MethodPost, http.MethodPut:
+			default:
+				h.ServeHTTP(w, r)
+				return
+			}
+
+			tx := db.WithContext(r.Context()).Begin()
+			if tx.Error != nil {
+				logError(l, r, "could not begin transaction", tx.Error)
+				http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+				return
+			}
+
+			tw := &txWriter{ResponseWriter: w, l: l, r: r, tx: tx}
+			defer func() {
+				if recovered := recover(); recovered != nil {
+					tw.finish(http.StatusInternalServerError)
+					panic(recovered)
+				}
+
+				// NOTE: a handler writing nothing responds with 200.
+				if !tw.done {
+					tw.WriteHeader(http.StatusOK)
+				}
+			}()
+
+			*r = *r.Clone(trails.WithValue(r.Context(), txKey, tx))
+			h.ServeHTTP(tw, r)
+		})
+	}
+}
+
+// TxFromContext retrieves the transaction DBTransaction opened for a request from ctx.
+func TxFromContext(ctx context.Context) (*gorm.DB, bool) {
+	return trails.Value[*gorm.DB](ctx, txKey)
+}
+
+// A txWriter ends a transaction according to the status of the response before writing it.
+type txWriter struct {
+	http.ResponseWriter
+	failed bool
+	done   bool
+	l      logger.Logger
+	r      *http.Request
+	tx     *gorm.DB
+}
+
+// WriteHeader ends the transaction before writing the status,
+// writing 500 instead if committing fails.
+func (tw *txWriter) WriteHeader(code int) {
+	if tw.done {
+		if !tw.failed {
+			tw.ResponseWriter.WriteHeader(code)
+		}
+
+		return
+	}
+
+	if !tw.finish(code) {
+		tw.failed = true
+		tw.Header().Del("Location")
+		http.Error(tw.ResponseWriter, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	tw.ResponseWriter.WriteHeader(code)
+}
+
+// Write ends the transaction before writing the body, as an implicit 200 does,
+// discarding the body if committing failed.
+func (tw *txWriter) Write(b []byte) (int, error) {
+	if !tw.done {
+		tw.WriteHeader(http.StatusOK)
+	}
+
+	if tw.failed {
+		return len(b), nil
+	}
+
+	return tw.ResponseWriter.Write(b)
+}
+
+// Unwrap exposes the underlying http.ResponseWriter to an http.ResponseController.
+func (tw *txWriter) Unwrap() http.ResponseWriter { return tw.ResponseWriter }
+
+// finish commits the transaction if code is not an error status and rolls it back otherwise,
+// reporting whether it committed or rolled back cleanly.
+// finish ends the transaction once.
+func (tw *txWriter) finish(code int) bool {
+	if tw.done {
+		return true
+	}
+	tw.done = true
+
+	if code >= http.StatusBadRequest {
+		if err := tw.tx.Rollback().Error; err != nil {
+			logError(tw.l, tw.r, "could not roll back transaction", err)
+		}
+
+		return true
+	}
+
+	if err := tw.tx.Commit().Error; err != nil {
+		logError(tw.l, tw.r, "could not commit transaction", err)
+		return false
+	}
+
+	return true
+}
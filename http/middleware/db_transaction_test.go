@@ -0,0 +1,130 @@
+package middleware_test
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/xy-planning-network/trails"
+	"github.com/xy-planning-network/trails/http/middleware"
+	"github.com/xy-planning-network/trails/logger"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// txPool is a gorm.ConnPool recording how transactions end.
+type txPool struct {
+	failCommit bool
+	ended      string
+}
+
+func (p *txPool) PrepareContext(context.Context, string) (*sql.Stmt, error) { return nil, nil }
+func (p *txPool) ExecContext(context.Context, string, ...any) (sql.Result, error) {
+	return nil, nil
+}
+func (p *txPool) QueryContext(context.Context, string, ...any) (*sql.Rows, error) {
+	return nil, nil
+}
+func (p *txPool) QueryRowContext(context.Context, string, ...any) *sql.Row { return nil }
+func (p *txPool) BeginTx(context.Context, *sql.TxOptions) (gorm.ConnPool, error) {
+	return &txConn{p}, nil
+}
+
+type txConn struct{ *txPool }
+
+func (c *txConn) Commit() error {
+	if c.failCommit {
+		c.ended = "failed"
+		return errors.New("serialization failure")
+	}
+
+	c.ended = "committed"
+	return nil
+}
+
+func (c *txConn) Rollback() error {
+	c.ended = "rolled back"
+	return nil
+}
+
+func TestDBTransaction(t *testing.T) {
+	for _, tc := range []struct {
+		name       string
+		method     string
+		failCommit bool
+		handler    http.HandlerFunc
+		code       int
+		ended      string
+	}{
+		{"Get", http.MethodGet, false, func(w http.ResponseWriter, r *http.Request) {
+			_, ok := middleware.TxFromContext(r.Context())
+			require.False(t, ok)
+		}, http.StatusOK, ""},
+		{"Implicit-OK", http.MethodPost, false, func(w http.ResponseWriter, r *http.Request) {
+			_, ok := middleware.TxFromContext(r.Context())
+			require.True(t, ok)
+		}, http.StatusOK, "committed"},
+		{"Redirect", http.MethodPost, false, func(w http.ResponseWriter, r *http.Request) {
+			http.Redirect(w, r, "/done", http.StatusSeeOther)
+		}, http.StatusSeeOther, "committed"},
+		{"Write", http.MethodPut, false, func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("ok"))
+		}, http.StatusOK, "committed"},
+		{"Client-Error", http.MethodPatch, false, func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+		}, http.StatusUnprocessableEntity, "rolled back"},
+		{"Commit-Fails", http.MethodDelete, true, func(w http.ResponseWriter, r *http.Request) {
+			http.Redirect(w, r, "/done", http.StatusSeeOther)
+		}, http.StatusInternalServerError, "failed"},
+		{"Implicit-Commit-Fails", http.MethodPost, true, func(w http.ResponseWriter, r *http.Request) {},
+			http.StatusInternalServerError, "failed"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			pool := &txPool{failCommit: tc.failCommit}
+			db, err := gorm.Open(postgres.New(postgres.Config{Conn: pool}), &gorm.Config{DisableAutomaticPing: true})
+			require.NoError(t, err)
+
+			b := new(bytes.Buffer)
+			l := logger.New(slog.New(slog.NewTextHandler(b, nil)), trails.Testing)
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(tc.method, "https://example.com", nil)
+
+			// Act
+			middleware.DBTransaction(db, l)(tc.handler).ServeHTTP(w, r)
+
+			// Assert
+			require.Equal(t, tc.code, w.Code)
+			require.Equal(t, tc.ended, pool.ended)
+			if tc.code == http.StatusInternalServerError {
+				require.Empty(t, w.Header().Get("Location"))
+				require.Contains(t, b.String(), "could not commit transaction")
+			}
+		})
+	}
+
+	t.Run("Panic", func(t *testing.T) {
+		// Arrange
+		pool := new(txPool)
+		db, err := gorm.Open(postgres.New(postgres.Config{Conn: pool}), &gorm.Config{DisableAutomaticPing: true})
+		require.NoError(t, err)
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "https://example.com", nil)
+
+		// Act + Assert
+		require.Panics(t, func() {
+			middleware.DBTransaction(db, nil)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				panic("oops")
+			})).ServeHTTP(w, r)
+		})
+		require.Equal(t, "rolled back", pool.ended)
+	})
+}
@@ -2,13 +2,35 @@
 The middleware package defines what a middleware is in trails and a set of basic middlewares.
 
 The available middlewares are:
+- BasicAuth
+- BotCheck
+- CacheResponse
+- CanonicalHost
+- Compress
 - CORS
+- CSRF
 - CurrentUser
+- DBTransaction
 - ForceHTTPS
+- Impersonation
+- InjectIPAddress
+- InjectResponder
 - InjectSession
+- IPFilter
+- LimitBody
+- Locale
 - LogRequest
+- MaintenanceGate
+- MaxInFlight
+- Metrics
 - RateLimit
+- Record
 - RequestID
+- RequireRole
+- SecureHeaders
+- Timeout
+- Trace
+- VerifySignature
 
 Due to the amount of configuration required, middleware does not provide a default middleware chain
 Instead, the following can be copy-pasted:
@@ -22,5 +44,14 @@ Instead, the following can be copy-pasted:
 		middleware.InjectSession(sessionStore, sessionKey),
 		middleware.CurrentUser(responder, userStore, userKey),
 	}
+
+To build a chain others can customize by name, use a Stack:
+
+	stack := middleware.NewStack(
+		middleware.Named("RateLimit", middleware.RateLimit(vs)),
+		middleware.Named("CurrentUser", middleware.CurrentUser(responder, userStore, userKey)),
+	)
+	stack.Before("CurrentUser", middleware.Named("InjectSession", middleware.InjectSession(sessionStore, sessionKey)))
+	router.OnEveryRequest(stack.Adapters()...)
 */
 package middleware
@@ -2,9 +2,10 @@ package middleware
 
 import (
 	"bytes"
-	"context"
+	"fmt"
 	"net"
 	"net/http"
+	"net/netip"
 	"strings"
 
 	"github.com/xy-planning-network/trails"
@@ -24,6 +25,9 @@ func (r ipRange) isInRange(ipAddress net.IP) bool {
 	return false
 }
 
+// clientIPKey stashes the IP address of the client InjectIPAddress derives with ClientIP.
+const clientIPKey trails.Key = "ClientIPKey"
+
 // IANA defined IPv4 non-public ranges
 var privateRanges = []ipRange{
 	{start: net.ParseIP("10.0.0.0"), end: net.ParseIP("10.255.255.255")},
@@ -34,22 +38,139 @@ var privateRanges = []ipRange{
 	{start: net.ParseIP("198.18.0.0"), end: net.ParseIP("198.19.255.255")},
 }
 
-// InjectIPAddress grabs the IP address in the *http.Request.Header
+// InjectIPAddress grabs the IP address of the client making the request
 // and promotes it to *http.Request.Context under trails.IpAddrKey.
-func InjectIPAddress() Adapter {
+//
+// When no trusted proxies are passed in, InjectIPAddress uses GetIPAddress,
+// which trusts the "X-Forwarded-For" and "X-Real-Ip" headers no matter who sets them.
+// Otherwise, InjectIPAddress uses ClientIP, which only trusts those headers when set by trusted proxies.
+//
+// Since the address under trails.IpAddrKey may then be spoofed,
+// InjectIPAddress also stashes the address ClientIP derives, which RequestIP retrieves
+// for making access control decisions on.
+func InjectIPAddress(trusted ...netip.Prefix) Adapter {
 	return func(h http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			ip := GetIPAddress(r.Header)
-			*r = *r.Clone(context.WithValue(r.Context(), trails.IpAddrKey, ip))
+			client := ClientIP(r, trusted)
+			ip := client
+			if len(trusted) == 0 {
+				ip = GetIPAddress(r.Header)
+			}
+
+			ctx := trails.WithValue(r.Context(), trails.IpAddrKey, ip)
+			ctx = trails.WithValue(ctx, clientIPKey, client)
+			*r = *r.Clone(ctx)
+
 			h.ServeHTTP(w, r)
 		})
 	}
 }
 
+// ClientIP derives the IP address of the client making the request,
+// trusting the "X-Forwarded-For" and "X-Real-Ip" headers only when set by a proxy in trusted,
+// e.g., a load balancer or CDN.
+//
+// Starting from the address directly connected to the server, *http.Request.RemoteAddr,
+// ClientIP marches right to left through "X-Forwarded-For" while addresses are in trusted.
+// The first address not in trusted is the client.
+// If "X-Forwarded-For" is empty, the value of "X-Real-Ip" is used.
+//
+// If no address can be parsed, ClientIP returns "0.0.0.0".
+func ClientIP(r *http.Request, trusted []netip.Prefix) string {
+	remote, err := netip.ParseAddrPort(r.RemoteAddr)
+	if err != nil {
+		return "0.0.0.0"
+	}
+
+	client := remote.Addr().Unmap()
+	if !isTrusted(client, trusted) {
+		return client.String()
+	}
+
+	var hops []string
+	for _, v := range r.Header.Values("X-Forwarded-For") {
+		hops = append(hops, strings.Split(v, ",")...)
+	}
+
+	if len(hops) == 0 {
+		if ip := strings.TrimSpace(r.Header.Get("X-Real-Ip")); ip != "" {
+			hops = []string{ip}
+		}
+	}
+
+	for i := len(hops) - 1; i >= 0; i-- {
+		addr, err := netip.ParseAddr(strings.TrimSpace(hops[i]))
+		if err != nil {
+			break
+		}
+
+		client = addr.Unmap()
+		if !isTrusted(client, trusted) {
+			break
+		}
+	}
+
+	return client.String()
+}
+
+// RequestIP is the IP address of the client making the request to make access control decisions on:
+// the address ClientIP derives from the proxies trusted by InjectIPAddress, if it comes before,
+// or *http.Request.RemoteAddr otherwise.
+//
+// Unlike the address under trails.IpAddrKey,
+// RequestIP cannot be spoofed by a client setting the "X-Forwarded-For" or "X-Real-Ip" headers.
+func RequestIP(r *http.Request) string {
+	if ip, ok := trails.Value[string](r.Context(), clientIPKey); ok {
+		return ip
+	}
+
+	return ClientIP(r, nil)
+}
+
+// ParsePrefixes parses each value as either a CIDR prefix or a single IP address.
+// It returns trails.ErrNotValid wrapping the first value unable to be parsed.
+func ParsePrefixes(vals []string) ([]netip.Prefix, error) {
+	prefixes := make([]netip.Prefix, 0, len(vals))
+	for _, v := range vals {
+		v = strings.TrimSpace(v)
+		if strings.Contains(v, "/") {
+			prefix, err := netip.ParsePrefix(v)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %q: %s", trails.ErrNotValid, v, err)
+			}
+
+			prefixes = append(prefixes, prefix.Masked())
+			continue
+		}
+
+		addr, err := netip.ParseAddr(v)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %q: %s", trails.ErrNotValid, v, err)
+		}
+
+		addr = addr.Unmap()
+		prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+	}
+
+	return prefixes, nil
+}
+
+// isTrusted checks whether the address is in any of the prefixes.
+func isTrusted(addr netip.Addr, trusted []netip.Prefix) bool {
+	for _, prefix := range trusted {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+
+	return false
+}
+
 // GetIPAddress parses "X-Forward-For" and "X-Real-Ip" headers for the IP address
 // from the request.
 //
 // GetIPAddress skips addresses from non-public ranges.
+// Since any client can set these headers, prefer ClientIP when the proxies in front of the app are known.
 func GetIPAddress(hm http.Header) string {
 	for _, h := range []string{"X-Forwarded-For", "X-Real-Ip"} {
 		addresses := strings.Split(hm.Get(h), ",")
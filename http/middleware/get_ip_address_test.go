@@ -3,6 +3,7 @@ package middleware_test
 import (
 	"net/http"
 	"net/http/httptest"
+	"net/netip"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -92,3 +93,112 @@ func TestGetIPAddress(t *testing.T) {
 		})
 	}
 }
+
+func TestClientIP(t *testing.T) {
+	trusted, err := middleware.ParsePrefixes([]string{"10.0.0.0/8", "173.245.48.0/20"})
+	require.Nil(t, err)
+
+	tcs := []struct {
+		name     string
+		remote   string
+		xff      []string
+		realIP   string
+		expected string
+	}{
+		{"Untrusted-Remote", "203.0.113.7:1234", []string{"1.1.1.1"}, "", "203.0.113.7"},
+		{"Trusted-Remote-No-Headers", "10.0.0.1:1234", nil, "", "10.0.0.1"},
+		{"Trusted-Remote", "10.0.0.1:1234", []string{"1.1.1.1"}, "", "1.1.1.1"},
+		{"Spoofed", "10.0.0.1:1234", []string{"6.6.6.6, 1.1.1.1"}, "", "1.1.1.1"},
+		{"Chained-Proxies", "10.0.0.1:1234", []string{"1.1.1.1, 173.245.48.1"}, "", "1.1.1.1"},
+		{"Multiple-Headers", "10.0.0.1:1234", []string{"6.6.6.6", "1.1.1.1, 10.0.0.2"}, "", "1.1.1.1"},
+		{"All-Trusted", "10.0.0.1:1234", []string{"10.0.0.3, 10.0.0.2"}, "", "10.0.0.3"},
+		{"Malformed", "10.0.0.1:1234", []string{"1.1.1.1, garbage"}, "", "10.0.0.1"},
+		{"Real-IP", "10.0.0.1:1234", nil, "1.1.1.1", "1.1.1.1"},
+		{"IPv4-Mapped", "[::ffff:10.0.0.1]:1234", []string{"1.1.1.1"}, "", "1.1.1.1"},
+		{"Bad-Remote", "nope", []string{"1.1.1.1"}, "", "0.0.0.0"},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			r := httptest.NewRequest(http.MethodGet, "https://example.com", nil)
+			r.RemoteAddr = tc.remote
+			for _, v := range tc.xff {
+				r.Header.Add("X-Forwarded-For", v)
+			}
+
+			if tc.realIP != "" {
+				r.Header.Set("X-Real-Ip", tc.realIP)
+			}
+
+			// Act + Assert
+			require.Equal(t, tc.expected, middleware.ClientIP(r, trusted))
+		})
+	}
+}
+
+func TestInjectIPAddressTrusted(t *testing.T) {
+	// Arrange
+	trusted, err := middleware.ParsePrefixes([]string{"10.0.0.1"})
+	require.Nil(t, err)
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "https://example.com", nil)
+	r.RemoteAddr = "203.0.113.7:1234"
+	r.Header.Set("X-Forwarded-For", "1.1.1.1")
+
+	// Act
+	actual := middleware.InjectIPAddress(trusted...)
+
+	// Assert
+	actual(http.HandlerFunc(func(wx http.ResponseWriter, rx *http.Request) {
+		val, ok := rx.Context().Value(trails.IpAddrKey).(string)
+		require.True(t, ok)
+		require.Equal(t, "203.0.113.7", val)
+	})).ServeHTTP(w, r)
+}
+
+func TestRequestIP(t *testing.T) {
+	// Arrange
+	trusted, err := middleware.ParsePrefixes([]string{"10.0.0.1"})
+	require.Nil(t, err)
+
+	r := httptest.NewRequest(http.MethodGet, "https://example.com", nil)
+	r.RemoteAddr = "203.0.113.7:1234"
+	r.Header.Set("X-Forwarded-For", "1.1.1.1")
+
+	// Act + Assert
+	require.Equal(t, "203.0.113.7", middleware.RequestIP(r))
+
+	middleware.InjectIPAddress()(http.HandlerFunc(func(wx http.ResponseWriter, rx *http.Request) {
+		require.Equal(t, "1.1.1.1", rx.Context().Value(trails.IpAddrKey))
+		require.Equal(t, "203.0.113.7", middleware.RequestIP(rx))
+	})).ServeHTTP(httptest.NewRecorder(), r)
+
+	// Arrange
+	r.RemoteAddr = "10.0.0.1:1234"
+
+	// Act + Assert
+	middleware.InjectIPAddress(trusted...)(http.HandlerFunc(func(wx http.ResponseWriter, rx *http.Request) {
+		require.Equal(t, "1.1.1.1", middleware.RequestIP(rx))
+	})).ServeHTTP(httptest.NewRecorder(), r)
+}
+
+func TestParsePrefixes(t *testing.T) {
+	// Act
+	actual, err := middleware.ParsePrefixes([]string{"10.1.2.3/8", " 1.1.1.1 ", "2001:db8::/32"})
+
+	// Assert
+	require.Nil(t, err)
+	require.Equal(t, []netip.Prefix{
+		netip.MustParsePrefix("10.0.0.0/8"),
+		netip.MustParsePrefix("1.1.1.1/32"),
+		netip.MustParsePrefix("2001:db8::/32"),
+	}, actual)
+
+	// Act
+	_, err = middleware.ParsePrefixes([]string{"1.1.1.1", "nope"})
+
+	// Assert
+	require.ErrorIs(t, err, trails.ErrNotValid)
+}
@@ -0,0 +1,79 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/xy-planning-network/trails"
+	"github.com/xy-planning-network/trails/http/resp"
+	"github.com/xy-planning-network/trails/http/session"
+	"github.com/xy-planning-network/trails/logger"
+)
+
+// Impersonation recognizes when the user in the session.Session is being impersonated,
+// e.g., by a support admin after calling session.Session.StartImpersonation.
+// Impersonation uses storer to retrieve the impersonating user
+// and stashes them in the *http.Request.Context under trails.ImpersonatorKey.
+//
+// Impersonation must come after InjectSession and CurrentUser in the middleware chain;
+// CurrentUser already stashes the impersonated user under trails.CurrentUserKey.
+// With the impersonator stashed:
+//   - LogRequest records their ID as "impersonatorId"
+//   - [*resp.Responder] includes them in a [logger.LogContext]
+//   - [*resp.Responder.Html] makes them available to templates through the "impersonator" function,
+//     e.g., for rendering a banner: {{ with impersonator }}...{{ end }}
+//   - [resp.Vue] includes them in "initialProps" as "impersonator"
+//
+// If the impersonating user cannot be retrieved or no longer has access,
+// Impersonation ends the session's authentication entirely,
+// recording a [logger.AuditImpersonationRevoked] event
+// and responding as CurrentUser does with a *resp.Responder.
+//
+// If d or storer is nil, NoopAdapter returns and this middleware does nothing.
+func Impersonation(d *resp.Responder, storer UserStorer) Adapter {
+	if d == nil || storer == nil {
+		return NoopAdapter
+	}
+
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			s, ok := trails.Value[session.Session](r.Context(), trails.SessionKey)
+			if !ok {
+				h.ServeHTTP(w, r)
+				return
+			}
+
+			id, err := s.ImpersonatorID()
+			if err != nil {
+				h.ServeHTTP(w, r)
+				return
+			}
+
+			impersonator, err := storer(id)
+			if err == nil && !impersonator.HasAccess() {
+				err = session.ErrNoUser
+			}
+
+			if err != nil {
+				target, _ := s.UserID()
+				logger.Audit(logger.AuditEvent{
+					Action:   logger.AuditImpersonationRevoked,
+					ActorID:  id,
+					Data:     map[string]any{"reason": err.Error()},
+					Request:  r,
+					TargetID: target,
+				})
+
+				if err := s.DeregisterUser(w, r); err != nil {
+					handleErr(w, r, http.StatusInternalServerError, d, err)
+					return
+				}
+
+				handleErr(w, r, http.StatusUnauthorized, d, err)
+				return
+			}
+
+			*r = *r.Clone(trails.WithValue(r.Context(), trails.ImpersonatorKey, impersonator))
+			h.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package middleware_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/xy-planning-network/trails"
+	"github.com/xy-planning-network/trails/http/middleware"
+	"github.com/xy-planning-network/trails/http/resp"
+	"github.com/xy-planning-network/trails/http/session"
+)
+
+func TestImpersonation(t *testing.T) {
+	// Arrange + Act
+	actual := middleware.Impersonation(nil, nil)
+
+	// Assert
+	require.Equal(t, fmt.Sprintf("%p", middleware.NoopAdapter), fmt.Sprintf("%p", actual))
+
+	d := resp.NewResponder(resp.WithRootUrl("https://example.com"))
+	newReq := func(impersonating bool) (*http.Request, session.Session) {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "https://example.com", nil)
+		r.Header.Set("Accept", "application/json")
+
+		s, err := session.NewStub(true).GetSession(r)
+		require.Nil(t, err)
+		if impersonating {
+			require.Nil(t, s.StartImpersonation(w, r, 2))
+		}
+
+		return r.Clone(context.WithValue(r.Context(), trails.SessionKey, s)), s
+	}
+
+	// Arrange
+	w := httptest.NewRecorder()
+	r, _ := newReq(false)
+
+	// Act
+	middleware.Impersonation(d, newTestUserStore(true))(http.HandlerFunc(func(wx http.ResponseWriter, rx *http.Request) {
+		require.Nil(t, rx.Context().Value(trails.ImpersonatorKey))
+		wx.WriteHeader(http.StatusTeapot)
+	})).ServeHTTP(w, r)
+
+	// Assert
+	require.Equal(t, http.StatusTeapot, w.Code)
+
+	// Arrange
+	w = httptest.NewRecorder()
+	r, _ = newReq(true)
+
+	// Act
+	middleware.Impersonation(d, newTestUserStore(true))(http.HandlerFunc(func(wx http.ResponseWriter, rx *http.Request) {
+		val, ok := rx.Context().Value(trails.ImpersonatorKey).(testUser)
+		require.True(t, ok)
+		require.True(t, bool(val))
+		wx.WriteHeader(http.StatusTeapot)
+	})).ServeHTTP(w, r)
+
+	// Assert
+	require.Equal(t, http.StatusTeapot, w.Code)
+
+	for name, storer := range map[string]middleware.UserStorer{
+		"Failed-Store": newFailedUserStore(true),
+		"No-Access":    newTestUserStore(false),
+	} {
+		t.Run(name, func(t *testing.T) {
+			// Arrange
+			w := httptest.NewRecorder()
+			r, s := newReq(true)
+
+			// Act
+			middleware.Impersonation(d, storer)(teapotHandler()).ServeHTTP(w, r)
+
+			// Assert
+			require.Equal(t, http.StatusUnauthorized, w.Code)
+
+			_, err := s.UserID()
+			require.ErrorIs(t, err, session.ErrNoUser)
+
+			_, err = s.ImpersonatorID()
+			require.ErrorIs(t, err, session.ErrNotImpersonating)
+		})
+	}
+}
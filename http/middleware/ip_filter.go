@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"net/http"
+	"net/netip"
+)
+
+// IPFilter restricts access by the IP address of the client,
+// responding with 403 to requests from an address in deny
+// or, when allow is not empty, from an address not in allow.
+// deny takes precedence over allow.
+//
+// IPFilter uses the IP address RequestIP retrieves,
+// so InjectIPAddress, passed the proxies in front of the app, ought to come before IPFilter in the middleware chain.
+// Without it, IPFilter uses *http.Request.RemoteAddr.
+//
+// IPFilter is well-suited to route groups only admins use, e.g.:
+//
+//	admin := rt.Subrouter("/admin")
+//	admin.OnEveryRequest(middleware.IPFilter(officePrefixes, nil))
+//
+// If both allow and deny are empty, NoopAdapter returns and this middleware does nothing.
+func IPFilter(allow, deny []netip.Prefix) Adapter {
+	if len(allow) == 0 && len(deny) == 0 {
+		return NoopAdapter
+	}
+
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			addr, ok := requestAddr(r)
+			if !ok || isTrusted(addr, deny) || (len(allow) > 0 && !isTrusted(addr, allow)) {
+				http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+				return
+			}
+
+			h.ServeHTTP(w, r)
+		})
+	}
+}
+
+// requestAddr parses the IP address of the client RequestIP retrieves.
+func requestAddr(r *http.Request) (netip.Addr, bool) {
+	addr, err := netip.ParseAddr(RequestIP(r))
+	return addr.Unmap(), err == nil && !addr.IsUnspecified()
+}
@@ -0,0 +1,71 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/xy-planning-network/trails/http/middleware"
+)
+
+func TestIPFilter(t *testing.T) {
+	allow := []netip.Prefix{netip.MustParsePrefix("192.0.2.0/24")}
+	deny := []netip.Prefix{netip.MustParsePrefix("192.0.2.13/32"), netip.MustParsePrefix("198.51.100.0/24")}
+	trusted := []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8")}
+
+	tcs := []struct {
+		name     string
+		allow    []netip.Prefix
+		deny     []netip.Prefix
+		trusted  []netip.Prefix
+		remote   string
+		xff      string
+		expected int
+	}{
+		{"Noop", nil, nil, nil, "203.0.113.7:1234", "", http.StatusTeapot},
+		{"Allowed", allow, deny, nil, "192.0.2.1:1234", "", http.StatusTeapot},
+		{"Not-Allowed", allow, deny, nil, "203.0.113.7:1234", "", http.StatusForbidden},
+		{"Denied-Over-Allowed", allow, deny, nil, "192.0.2.13:1234", "", http.StatusForbidden},
+		{"Only-Deny-Passes", nil, deny, nil, "203.0.113.7:1234", "", http.StatusTeapot},
+		{"Only-Deny-Denied", nil, deny, nil, "198.51.100.1:1234", "", http.StatusForbidden},
+		{"Bad-Remote-Addr", allow, nil, nil, "0.0.0.0.0", "", http.StatusForbidden},
+		{"Spoofed", allow, deny, nil, "203.0.113.7:1234", "192.0.2.1", http.StatusForbidden},
+		{"Spoofed-Past-Deny", nil, deny, nil, "198.51.100.1:1234", "203.0.113.7", http.StatusForbidden},
+		{"Untrusted-Proxy", allow, nil, trusted, "203.0.113.7:1234", "192.0.2.1", http.StatusForbidden},
+		{"Trusted-Proxy", allow, nil, trusted, "10.0.0.1:1234", "192.0.2.1", http.StatusTeapot},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodGet, "https://example.com", nil)
+			r.RemoteAddr = tc.remote
+			if tc.xff != "" {
+				r.Header.Set("X-Forwarded-For", tc.xff)
+			}
+
+			h := middleware.InjectIPAddress(tc.trusted...)(middleware.IPFilter(tc.allow, tc.deny)(teapotHandler()))
+
+			// Act
+			h.ServeHTTP(w, r)
+
+			// Assert
+			require.Equal(t, tc.expected, w.Code)
+		})
+	}
+
+	// Arrange
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "https://example.com", nil)
+	r.RemoteAddr = "203.0.113.7:1234"
+	r.Header.Set("X-Forwarded-For", "192.0.2.1")
+
+	// Act
+	middleware.IPFilter(allow, nil)(teapotHandler()).ServeHTTP(w, r)
+
+	// Assert
+	require.Equal(t, http.StatusForbidden, w.Code)
+}
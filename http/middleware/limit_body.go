@@ -0,0 +1,30 @@
+package middleware
+
+import (
+	"net/http"
+)
+
+// LimitBody responds with 413 to requests declaring a "Content-Length" greater than n bytes
+// and fails reads by handlers past n bytes of a request body, with an [*http.MaxBytesError].
+//
+// If n is not positive, NoopAdapter returns and this middleware does nothing.
+func LimitBody(n int64) Adapter {
+	if n <= 0 {
+		return NoopAdapter
+	}
+
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.ContentLength > n {
+				http.Error(w, http.StatusText(http.StatusRequestEntityTooLarge), http.StatusRequestEntityTooLarge)
+				return
+			}
+
+			if r.Body != nil && r.Body != http.NoBody {
+				r.Body = http.MaxBytesReader(w, r.Body, n)
+			}
+
+			h.ServeHTTP(w, r)
+		})
+	}
+}
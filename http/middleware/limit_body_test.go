@@ -0,0 +1,57 @@
+package middleware_test
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/xy-planning-network/trails/http/middleware"
+)
+
+func TestLimitBody(t *testing.T) {
+	reader := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, err := io.ReadAll(r.Body); err != nil {
+			var mbe *http.MaxBytesError
+			if errors.As(err, &mbe) {
+				w.WriteHeader(http.StatusRequestEntityTooLarge)
+				return
+			}
+		}
+
+		w.WriteHeader(http.StatusTeapot)
+	})
+
+	tcs := []struct {
+		name          string
+		n             int64
+		body          string
+		unknownLength bool
+		expected      int
+	}{
+		{"Noop", 0, "hello world", false, http.StatusTeapot},
+		{"Under", 16, "hello world", false, http.StatusTeapot},
+		{"Content-Length-Over", 4, "hello world", false, http.StatusRequestEntityTooLarge},
+		{"Read-Over", 4, "hello world", true, http.StatusRequestEntityTooLarge},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPost, "https://example.com", strings.NewReader(tc.body))
+			if tc.unknownLength {
+				r.ContentLength = -1
+			}
+
+			// Act
+			middleware.LimitBody(tc.n)(reader).ServeHTTP(w, r)
+
+			// Assert
+			require.Equal(t, tc.expected, w.Code)
+		})
+	}
+}
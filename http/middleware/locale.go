@@ -0,0 +1,103 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/xy-planning-network/trails"
+	"github.com/xy-planning-network/trails/http/session"
+	"golang.org/x/text/language"
+)
+
+// LocaleCookie names the cookie a user's chosen locale is read from, e.g., as set by a language switcher.
+const LocaleCookie = "lang"
+
+// A LocalizedUser is a User preferring a locale, e.g., as chosen in their profile.
+type LocalizedUser interface {
+	// PreferredLocale returns the BCP 47 tag of the user's preferred locale, e.g., "es-MX",
+	// or an empty string if they have none.
+	PreferredLocale() string
+}
+
+// Locale chooses the locale each request is localized in from defaultLang and supported,
+// preferring, in order:
+//   - the current user's, if they are a LocalizedUser; cf. CurrentUser
+//   - the LocaleCookie's
+//   - the one chosen earlier in the session, if InjectSession is used
+//   - the best match for the request's Accept-Language header
+//
+// and falling back to defaultLang if none of those is supported.
+//
+// Locale stashes the chosen language.Tag in the *http.Request.Context under trails.LocaleKey,
+// where *resp.Responder reads it to pass to templates and Vue initialProps,
+// saves it in the session, and sets the Content-Language response header.
+func Locale(defaultLang language.Tag, supported ...language.Tag) Adapter {
+	tags := append([]language.Tag{defaultLang}, supported...)
+	m := language.NewMatcher(tags)
+
+	match := func(candidates ...language.Tag) (language.Tag, bool) {
+		if len(candidates) == 0 {
+			return language.Und, false
+		}
+
+		_, i, conf := m.Match(candidates...)
+		if conf == language.No {
+			return language.Und, false
+		}
+
+		return tags[i], true
+	}
+
+	parse := func(s string) (language.Tag, bool) {
+		if s == "" {
+			return language.Und, false
+		}
+
+		t, err := language.Parse(s)
+		if err != nil {
+			return language.Und, false
+		}
+
+		return match(t)
+	}
+
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var saved string
+			s, hasSession := trails.Value[session.Session](r.Context(), trails.SessionKey)
+			if hasSession {
+				saved, _ = s.Get(trails.LocaleKey).(string)
+			}
+
+			tag, ok := language.Und, false
+			if u, isLocalized := trails.Value[LocalizedUser](r.Context(), trails.CurrentUserKey); isLocalized {
+				tag, ok = parse(u.PreferredLocale())
+			}
+
+			if c, err := r.Cookie(LocaleCookie); !ok && err == nil {
+				tag, ok = parse(c.Value)
+			}
+
+			if !ok {
+				tag, ok = parse(saved)
+			}
+
+			if !ok {
+				prefs, _, _ := language.ParseAcceptLanguage(r.Header.Get("Accept-Language"))
+				tag, ok = match(prefs...)
+			}
+
+			if !ok {
+				tag = defaultLang
+			}
+
+			if hasSession && saved != tag.String() {
+				// NOTE: failing to remember the locale does not fail the request it localizes.
+				_ = s.Set(w, r, trails.LocaleKey, tag.String())
+			}
+
+			w.Header().Set("Content-Language", tag.String())
+			*r = *r.Clone(trails.WithValue(r.Context(), trails.LocaleKey, tag))
+			h.ServeHTTP(w, r)
+		})
+	}
+}
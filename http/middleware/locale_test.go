@@ -0,0 +1,100 @@
+package middleware_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/xy-planning-network/trails"
+	"github.com/xy-planning-network/trails/http/middleware"
+	"github.com/xy-planning-network/trails/http/session"
+	"golang.org/x/text/language"
+)
+
+type localizedUser struct {
+	testUser
+	locale string
+}
+
+func (u localizedUser) PreferredLocale() string { return u.locale }
+
+func TestLocale(t *testing.T) {
+	adpt := middleware.Locale(language.English, language.Spanish, language.French)
+
+	for _, tc := range []struct {
+		name     string
+		user     middleware.User
+		cookie   string
+		accept   string
+		expected language.Tag
+	}{
+		{"Default", nil, "", "", language.English},
+		{"Accept-Language", nil, "", "fr-CA,fr;q=0.9,en;q=0.5", language.French},
+		{"Unsupported-Accept-Language", nil, "", "de", language.English},
+		{"Cookie", nil, "es", "fr", language.Spanish},
+		{"Bad-Cookie", nil, "zz-!!", "fr", language.French},
+		{"User", localizedUser{locale: "es-MX"}, "fr", "fr", language.Spanish},
+		{"User-Without-Preference", localizedUser{}, "fr", "es", language.French},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodGet, "https://example.com", nil)
+			if tc.accept != "" {
+				r.Header.Set("Accept-Language", tc.accept)
+			}
+			if tc.cookie != "" {
+				r.AddCookie(&http.Cookie{Name: middleware.LocaleCookie, Value: tc.cookie})
+			}
+			if tc.user != nil {
+				r = r.WithContext(context.WithValue(r.Context(), trails.CurrentUserKey, tc.user))
+			}
+
+			var actual language.Tag
+
+			// Act
+			adpt(http.HandlerFunc(func(wx http.ResponseWriter, rx *http.Request) {
+				actual, _ = trails.Value[language.Tag](rx.Context(), trails.LocaleKey)
+			})).ServeHTTP(w, r)
+
+			// Assert
+			require.Equal(t, tc.expected, actual)
+			require.Equal(t, tc.expected.String(), w.Header().Get("Content-Language"))
+		})
+	}
+
+	t.Run("Session", func(t *testing.T) {
+		// Arrange
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "https://example.com", nil)
+		r.AddCookie(&http.Cookie{Name: middleware.LocaleCookie, Value: "fr"})
+
+		s, err := session.NewStub(false).GetSession(r)
+		require.NoError(t, err)
+		r = r.WithContext(context.WithValue(r.Context(), trails.SessionKey, s))
+
+		// Act
+		adpt(http.NotFoundHandler()).ServeHTTP(w, r)
+
+		// Assert
+		require.Equal(t, "fr", s.Get(trails.LocaleKey))
+
+		// Arrange
+		w = httptest.NewRecorder()
+		r = httptest.NewRequest(http.MethodGet, "https://example.com", nil)
+		r.Header.Set("Accept-Language", "es")
+		r = r.WithContext(context.WithValue(r.Context(), trails.SessionKey, s))
+
+		var actual language.Tag
+
+		// Act
+		adpt(http.HandlerFunc(func(wx http.ResponseWriter, rx *http.Request) {
+			actual, _ = trails.Value[language.Tag](rx.Context(), trails.LocaleKey)
+		})).ServeHTTP(w, r)
+
+		// Assert
+		require.Equal(t, language.French, actual)
+	})
+}
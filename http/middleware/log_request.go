@@ -66,6 +66,7 @@ type LogRequestRecord struct {
 	Duration       int64  `json:"duration"`
 	Host           string `json:"host"`
 	ID             string `json:"id"`
+	ImpersonatorID uint   `json:"impersonatorId,omitempty"`
 	IPAddr         string `json:"remoteAddr"`
 	Method         string `json:"method"`
 	Path           string `json:"path"`
@@ -76,6 +77,7 @@ type LogRequestRecord struct {
 	Scheme         string `json:"scheme"`
 	SessionID      string `json:"sessionId"`
 	Status         int    `json:"status"`
+	TraceID        string `json:"traceId,omitempty"`
 	URI            string `json:"uri"`
 	UserAgent      string `json:"userAgent"`
 }
@@ -92,17 +94,23 @@ func newRecord(w *requestLogger, r *http.Request) LogRequestRecord {
 
 	contLen, _ := strconv.Atoi(r.Header.Get(contentLenHeader))
 
-	id, _ := r.Context().Value(trails.RequestIDKey).(string)
-	ip, _ := r.Context().Value(trails.IpAddrKey).(string)
-	var sessID string
-	if sess, ok := r.Context().Value(trails.SessionKey).(session.Session); ok {
-		sessID, _ = sess.Get(trails.SessionIDKey).(string)
+	id, _ := trails.Value[string](r.Context(), trails.RequestIDKey)
+	ip, _ := trails.Value[string](r.Context(), trails.IpAddrKey)
+	traceID, _ := trails.Value[string](r.Context(), trails.TraceIDKey)
+	var (
+		sessID         string
+		impersonatorID uint
+	)
+	if sess, ok := trails.Value[session.Session](r.Context(), trails.SessionKey); ok {
+		sessID, _ = session.Get[string](sess, trails.SessionIDKey)
+		impersonatorID, _ = sess.ImpersonatorID()
 	}
 
 	return LogRequestRecord{
 		BodySize:       w.bodySize,
 		Host:           r.Host,
 		ID:             id,
+		ImpersonatorID: impersonatorID,
 		IPAddr:         ip,
 		Method:         r.Method,
 		Path:           r.URL.Path,
@@ -113,13 +121,14 @@ func newRecord(w *requestLogger, r *http.Request) LogRequestRecord {
 		Scheme:         r.URL.Scheme,
 		SessionID:      sessID,
 		Status:         w.status,
+		TraceID:        traceID,
 		URI:            uri.RequestURI(),
 		UserAgent:      r.Header.Get(userAgentHeader),
 	}
 }
 
 func (r LogRequestRecord) attrs() []slog.Attr {
-	return []slog.Attr{
+	attrs := []slog.Attr{
 		slog.Int64("duration", r.Duration),
 		slog.String("host", r.Host),
 		slog.String("id", r.ID),
@@ -137,6 +146,16 @@ func (r LogRequestRecord) attrs() []slog.Attr {
 		slog.String("uri", r.URI),
 		slog.String("userAgent", r.UserAgent),
 	}
+
+	if r.TraceID != "" {
+		attrs = append(attrs, slog.String("traceId", r.TraceID))
+	}
+
+	if r.ImpersonatorID != 0 {
+		attrs = append(attrs, slog.Uint64("impersonatorId", uint64(r.ImpersonatorID)))
+	}
+
+	return attrs
 }
 
 type requestLogger struct {
@@ -0,0 +1,38 @@
+package middleware
+
+import (
+	"net/http"
+)
+
+// MaintenanceMsg is the message written to clients when MaintenanceGate blocks a request.
+const MaintenanceMsg = "We are currently performing maintenance. Please try again later."
+
+// MaintenanceGate blocks requests for which check returns true,
+// responding with 503 and a "Retry-After" header.
+// If the request's "Accept" header has "application/json" in it,
+// the body is JSON in the same shape as *resp.Responder.Json: {"data": {"error": MaintenanceMsg}}.
+// Otherwise, the body is MaintenanceMsg.
+//
+// check is called on every request, so flipping what it returns
+// turns maintenance mode on or off without restarting the application.
+// Let requests through that must work during maintenance, e.g., health checks or admin traffic,
+// by returning false for them.
+//
+// If check is nil, NoopAdapter returns and this middleware does nothing.
+func MaintenanceGate(check func(*http.Request) bool) Adapter {
+	if check == nil {
+		return NoopAdapter
+	}
+
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if check(r) {
+				w.Header().Set("Retry-After", "600")
+				writeUnavailable(w, r, MaintenanceMsg)
+				return
+			}
+
+			h.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/xy-planning-network/trails/http/middleware"
+)
+
+func TestMaintenanceGate(t *testing.T) {
+	// Arrange
+	var on bool
+	h := middleware.MaintenanceGate(func(r *http.Request) bool { return on && r.URL.Path != "/health" })(teapotHandler())
+
+	// Act
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "https://example.com", nil))
+
+	// Assert
+	require.Equal(t, http.StatusTeapot, w.Code)
+
+	// Arrange
+	on = true
+	r := httptest.NewRequest(http.MethodGet, "https://example.com", nil)
+	r.Header.Set("Accept", "application/json")
+
+	// Act
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	// Assert
+	require.Equal(t, http.StatusServiceUnavailable, w.Code)
+	require.Equal(t, "600", w.Header().Get("Retry-After"))
+	require.JSONEq(t, `{"data":{"error":"`+middleware.MaintenanceMsg+`"}}`, w.Body.String())
+
+	// Act
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "https://example.com/health", nil))
+
+	// Assert
+	require.Equal(t, http.StatusTeapot, w.Code)
+}
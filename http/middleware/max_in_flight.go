@@ -0,0 +1,73 @@
+package middleware
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// OverloadedMsg is the message written to clients when MaxInFlight sheds a request.
+const OverloadedMsg = "We are handling too many requests right now. Please try again shortly."
+
+// MaxInFlight limits how many requests are handled at once to n,
+// so a spike in traffic slows some requests down instead of every request contending
+// for database connections and the like.
+//
+// A request arriving while n are in flight waits up to queue for one to finish.
+// If none does, or queue is not positive, MaxInFlight sheds the request,
+// responding with 503 and a "Retry-After" header.
+// If the request's "Accept" header has "application/json" in it,
+// the body is JSON in the same shape as *resp.Responder.Json: {"data": {"error": OverloadedMsg}}.
+// Otherwise, the body is OverloadedMsg.
+//
+// The limit is for each instance of the application, not across all of them.
+//
+// If n is not positive, NoopAdapter returns and this middleware does nothing.
+func MaxInFlight(n int, queue time.Duration) Adapter {
+	if n <= 0 {
+		return NoopAdapter
+	}
+
+	retry := strconv.Itoa(int(math.Max(1, math.Ceil(queue.Seconds()))))
+	slots := make(chan struct{}, n)
+
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !acquire(r, slots, queue) {
+				w.Header().Set("Retry-After", retry)
+				writeUnavailable(w, r, OverloadedMsg)
+				return
+			}
+			defer func() { <-slots }()
+
+			h.ServeHTTP(w, r)
+		})
+	}
+}
+
+// acquire takes a slot for r, waiting up to queue for one to free up,
+// and asserts whether it did.
+func acquire(r *http.Request, slots chan struct{}, queue time.Duration) bool {
+	select {
+	case slots <- struct{}{}:
+		return true
+	default:
+	}
+
+	if queue <= 0 {
+		return false
+	}
+
+	t := time.NewTimer(queue)
+	defer t.Stop()
+
+	select {
+	case slots <- struct{}{}:
+		return true
+	case <-t.C:
+		return false
+	case <-r.Context().Done():
+		return false
+	}
+}
@@ -0,0 +1,108 @@
+package middleware_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/xy-planning-network/trails/http/middleware"
+)
+
+func TestMaxInFlight(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		queue    time.Duration
+		release  bool
+		accept   string
+		code     int
+		retry    string
+		expected string
+	}{
+		{"Sheds-Html", 0, false, "text/html", http.StatusServiceUnavailable, "1", middleware.OverloadedMsg + "\n"},
+		{"Sheds-Json", 0, false, "application/json", http.StatusServiceUnavailable, "1", `{"data":{"error":"` + middleware.OverloadedMsg + `"}}` + "\n"},
+		{"Queues-Then-Sheds", 1500 * time.Millisecond, false, "text/html", http.StatusServiceUnavailable, "2", middleware.OverloadedMsg + "\n"},
+		{"Queues-Then-Serves", time.Minute, true, "text/html", http.StatusTeapot, "", http.StatusText(http.StatusTeapot)},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			started := make(chan struct{})
+			block := make(chan struct{})
+			h := middleware.MaxInFlight(1, tc.queue)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path == "/slow" {
+					close(started)
+					<-block
+				}
+
+				w.WriteHeader(http.StatusTeapot)
+				w.Write([]byte(http.StatusText(http.StatusTeapot)))
+			}))
+
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "https://example.com/slow", nil))
+			}()
+			<-started
+
+			if tc.release {
+				time.AfterFunc(10*time.Millisecond, func() { close(block) })
+			}
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodGet, "https://example.com", nil)
+			r.Header.Set("Accept", tc.accept)
+
+			// Act
+			h.ServeHTTP(w, r)
+
+			// Assert
+			require.Equal(t, tc.code, w.Code)
+			require.Equal(t, tc.retry, w.Header().Get("Retry-After"))
+			require.Equal(t, tc.expected, w.Body.String())
+
+			if !tc.release {
+				close(block)
+			}
+			<-done
+		})
+	}
+
+	t.Run("Canceled", func(t *testing.T) {
+		// Arrange
+		block := make(chan struct{})
+		defer close(block)
+
+		started := make(chan struct{})
+		h := middleware.MaxInFlight(1, time.Minute)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			close(started)
+			<-block
+		}))
+		go h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "https://example.com", nil))
+		<-started
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		w := httptest.NewRecorder()
+
+		// Act
+		h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "https://example.com", nil).WithContext(ctx))
+
+		// Assert
+		require.Equal(t, http.StatusServiceUnavailable, w.Code)
+	})
+
+	t.Run("Noop", func(t *testing.T) {
+		// Arrange
+		w := httptest.NewRecorder()
+
+		// Act
+		middleware.MaxInFlight(0, time.Second)(teapotHandler()).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "https://example.com", nil))
+
+		// Assert
+		require.Equal(t, http.StatusTeapot, w.Code)
+	})
+}
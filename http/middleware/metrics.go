@@ -0,0 +1,114 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	metricsNamespace = "trails"
+	metricsSubsystem = "http"
+
+	// unmatchedRoute labels requests not matching any route, e.g., those responded to with 404.
+	unmatchedRoute = "unmatched"
+)
+
+// metricsLabels are the labels partitioning request metrics.
+var metricsLabels = []string{"method", "route", "status"}
+
+// Metrics records Prometheus metrics about every request in registry:
+//   - trails_http_requests_total: a counter of requests
+//   - trails_http_request_duration_seconds: a histogram of the time taken to respond
+//   - trails_http_response_size_bytes: a histogram of the size of response bodies
+//   - trails_http_requests_in_flight: a gauge of requests currently being handled
+//
+// All but the last are labeled by the HTTP method, the route pattern matched, e.g., "/users/{id}",
+// and the status class of the response, e.g., "2xx".
+// Requests not matching a route are labeled with the route "unmatched",
+// keeping the number of distinct label values small.
+//
+// Calling Metrics again with the same registry reuses the metrics already registered.
+//
+// If registry is nil, NoopAdapter returns and this middleware does nothing.
+func Metrics(registry prometheus.Registerer) Adapter {
+	if registry == nil {
+		return NoopAdapter
+	}
+
+	total := registerCollector(registry, prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Subsystem: metricsSubsystem,
+		Name:      "requests_total",
+		Help:      "Number of HTTP requests handled.",
+	}, metricsLabels))
+
+	duration := registerCollector(registry, prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metricsNamespace,
+		Subsystem: metricsSubsystem,
+		Name:      "request_duration_seconds",
+		Help:      "Time taken to respond to HTTP requests.",
+		Buckets:   prometheus.DefBuckets,
+	}, metricsLabels))
+
+	size := registerCollector(registry, prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metricsNamespace,
+		Subsystem: metricsSubsystem,
+		Name:      "response_size_bytes",
+		Help:      "Size of HTTP response bodies.",
+		Buckets:   prometheus.ExponentialBuckets(100, 10, 6),
+	}, metricsLabels))
+
+	inflight := registerCollector(registry, prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Subsystem: metricsSubsystem,
+		Name:      "requests_in_flight",
+		Help:      "Number of HTTP requests currently being handled.",
+	}))
+
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			inflight.Inc()
+			defer inflight.Dec()
+
+			writer := &requestLogger{ResponseWriter: w, status: http.StatusOK}
+			h.ServeHTTP(writer, r)
+
+			route := routePattern(r)
+			if route == "" {
+				route = unmatchedRoute
+			}
+
+			labels := prometheus.Labels{
+				"method": r.Method,
+				"route":  route,
+				"status": strconv.Itoa(writer.status/100) + "xx",
+			}
+
+			total.With(labels).Inc()
+			duration.With(labels).Observe(time.Since(start).Seconds())
+			size.With(labels).Observe(float64(writer.bodySize))
+		})
+	}
+}
+
+// registerCollector registers c in registry,
+// returning the equivalent collector already registered if there is one.
+func registerCollector[C prometheus.Collector](registry prometheus.Registerer, c C) C {
+	if err := registry.Register(c); err != nil {
+		var are prometheus.AlreadyRegisteredError
+		if errors.As(err, &are) {
+			if existing, ok := are.ExistingCollector.(C); ok {
+				return existing
+			}
+		}
+
+		panic(err)
+	}
+
+	return c
+}
@@ -0,0 +1,78 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	"github.com/xy-planning-network/trails/http/middleware"
+)
+
+func TestMetrics(t *testing.T) {
+	t.Run("Nil-Registry", func(t *testing.T) {
+		// Arrange
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "https://example.com", nil)
+
+		// Act
+		middleware.Metrics(nil)(teapotHandler()).ServeHTTP(w, r)
+
+		// Assert
+		require.Equal(t, http.StatusTeapot, w.Code)
+	})
+
+	t.Run("Records", func(t *testing.T) {
+		// Arrange
+		reg := prometheus.NewRegistry()
+		mw := middleware.Metrics(reg)
+
+		rtr := mux.NewRouter()
+		rtr.Use(mux.MiddlewareFunc(mw))
+		rtr.HandleFunc("/users/{id}", func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("hello"))
+		})
+		rtr.NotFoundHandler = mw(http.NotFoundHandler())
+
+		// Act
+		for _, path := range []string{"/users/1", "/users/2", "/missing"} {
+			rtr.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "https://example.com"+path, nil))
+		}
+
+		// Assert
+		expected := `
+# HELP trails_http_requests_total Number of HTTP requests handled.
+# TYPE trails_http_requests_total counter
+trails_http_requests_total{method="GET",route="/users/{id}",status="2xx"} 2
+trails_http_requests_total{method="GET",route="unmatched",status="4xx"} 1
+# HELP trails_http_requests_in_flight Number of HTTP requests currently being handled.
+# TYPE trails_http_requests_in_flight gauge
+trails_http_requests_in_flight 0
+`
+		require.NoError(t, testutil.GatherAndCompare(
+			reg,
+			strings.NewReader(expected),
+			"trails_http_requests_total",
+			"trails_http_requests_in_flight",
+		))
+
+		count, err := testutil.GatherAndCount(reg, "trails_http_request_duration_seconds", "trails_http_response_size_bytes")
+		require.NoError(t, err)
+		require.Equal(t, 4, count)
+	})
+
+	t.Run("Reuses-Registered", func(t *testing.T) {
+		// Arrange
+		reg := prometheus.NewRegistry()
+
+		// Act + Assert
+		require.NotPanics(t, func() {
+			middleware.Metrics(reg)
+			middleware.Metrics(reg)
+		})
+	})
+}
@@ -2,6 +2,8 @@ package middleware
 
 import (
 	"net/http"
+
+	"github.com/xy-planning-network/trails/logger"
 )
 
 // An Adapter enables chaining middlewares together.
@@ -16,6 +18,15 @@ func NoopAdapter(h http.Handler) http.Handler {
 	})
 }
 
+// logError logs err, which happened while handling r, with msg using l, unless l is nil.
+func logError(l logger.Logger, r *http.Request, msg string, err error) {
+	if l == nil {
+		return
+	}
+
+	l.Error(msg, &logger.LogContext{Error: err, Request: r})
+}
+
 // Chain glues the set of adapters to the handler.
 func Chain(handler http.Handler, adapters ...Adapter) http.Handler {
 	//NOTE: Loop in reverse to preserve middleware order
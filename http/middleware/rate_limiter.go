@@ -16,22 +16,29 @@ type Visitor struct {
 
 // A Visitors maps a Visitor to an IP address.
 type Visitors struct {
-	val map[string]Visitor
+	burst int
+	limit rate.Limit
+	val   map[string]Visitor
 	sync.Mutex
 }
 
-func NewVisitors() *Visitors { return &Visitors{val: make(map[string]Visitor)} }
+// NewVisitors constructs a *Visitors limiting each to 5 requests every second with bursts of up to 20.
+func NewVisitors() *Visitors { return NewVisitorsWithLimit(5, 20) }
+
+// NewVisitorsWithLimit constructs a *Visitors limiting each to limit requests every second
+// with bursts of up to burst; cf. [rate.NewLimiter].
+func NewVisitorsWithLimit(limit rate.Limit, burst int) *Visitors {
+	return &Visitors{burst: burst, limit: limit, val: make(map[string]Visitor)}
+}
 
 // Fetch retrieves the Visitor for the given ip creating a new Visitor if not seen.
-//
-// Newly created visitors are limited to 5 requests every second with bursts of up to 20.
 func (vs *Visitors) Fetch(ip string) Visitor {
 	vs.Lock()
 	defer vs.Unlock()
 
 	v, ok := vs.val[ip]
 	if !ok {
-		v = Visitor{Limiter: rate.NewLimiter(5, 20)}
+		v = Visitor{Limiter: rate.NewLimiter(vs.limit, vs.burst)}
 	}
 
 	v.LastSeen = time.Now().UTC()
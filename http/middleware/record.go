@@ -0,0 +1,403 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/xy-planning-network/trails"
+	"github.com/xy-planning-network/trails/logger"
+	"github.com/xy-planning-network/trails/postgres"
+	"gorm.io/gorm"
+)
+
+// DefaultMaxRecordedBytes is how much of a request or response body Record keeps.
+const DefaultMaxRecordedBytes = 1 << 20
+
+// A Recording is a request Record recorded, along with the response to it.
+type Recording struct {
+	ID       string        `json:"id"`
+	Duration time.Duration `json:"duration"`
+	Method   string        `json:"method"`
+	Route    string        `json:"route,omitempty"`
+	Time     time.Time     `json:"time"`
+	URL      string        `json:"url"`
+
+	RequestBody   []byte      `json:"requestBody"`
+	RequestHeader http.Header `json:"requestHeader"`
+
+	ResponseBody   []byte      `json:"responseBody"`
+	ResponseHeader http.Header `json:"responseHeader"`
+	Status         int         `json:"status"`
+
+	// Truncated reports whether either body was longer than Record keeps.
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+// A RecordStore keeps the Recordings Record makes.
+type RecordStore interface {
+	// Recording retrieves the Recording identified by id,
+	// returning trails.ErrNotExist if there is none.
+	Recording(ctx context.Context, id string) (Recording, error)
+
+	// Recordings lists the Recordings kept, newest first.
+	Recordings(ctx context.Context) ([]Recording, error)
+
+	// Save keeps rec.
+	Save(ctx context.Context, rec Recording) error
+}
+
+// RecordOpts configures the Record middleware.
+type RecordOpts struct {
+	// Match asserts whether to record the request,
+	// e.g., RecordRoutes("POST /users/{id}").
+	//
+	// If Match is nil, every request is recorded.
+	Match func(*http.Request) bool
+
+	// Logger logs failures to read request bodies or save Recordings.
+	//
+	// If Logger is nil, failures are not logged.
+	Logger logger.Logger
+
+	// MaxBodyBytes is how much of a request or response body is kept.
+	//
+	// If MaxBodyBytes is 0, DefaultMaxRecordedBytes is used.
+	MaxBodyBytes int64
+
+	// Store keeps the Recordings.
+	Store RecordStore
+}
+
+// Record records requests, their headers, bodies, and how long they took to respond to,
+// along with the response, in RecordOpts.Store,
+// so requests clients report problems with can be reproduced exactly, e.g., with Replay.
+//
+// Recordings include cookies, passwords, and everything else sent;
+// record requests only while developing or debugging.
+//
+// If RecordOpts.Store is nil, NoopAdapter returns and this middleware does nothing.
+func Record(opts RecordOpts) Adapter {
+	if opts.Store == nil {
+		return NoopAdapter
+	}
+
+	limit := opts.MaxBodyBytes
+	if limit == 0 {
+		limit = DefaultMaxRecordedBytes
+	}
+
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if opts.Match != nil && !opts.Match(r) {
+				h.ServeHTTP(w, r)
+				return
+			}
+
+			rec := Recording{
+				ID:            uuid.NewString(),
+				Method:        r.Method,
+				Route:         routePattern(r),
+				Time:          time.Now(),
+				URL:           r.URL.RequestURI(),
+				RequestHeader: r.Header.Clone(),
+			}
+
+			if r.Host != "" {
+				rec.RequestHeader.Set("Host", r.Host)
+			}
+
+			if r.Body != nil && r.Body != http.NoBody {
+				body, err := io.ReadAll(io.LimitReader(r.Body, limit+1))
+				if err != nil {
+					logError(opts.Logger, r, "could not record request body", err)
+				}
+
+				// NOTE: hand the handler the whole body, including what was read past the limit.
+				r.Body = struct {
+					io.Reader
+					io.Closer
+				}{io.MultiReader(bytes.NewReader(body), r.Body), r.Body}
+
+				rec.RequestBody, rec.Truncated = truncate(body, limit)
+			}
+
+			writer := &recordWriter{ResponseWriter: w, limit: limit, status: http.StatusOK}
+			h.ServeHTTP(writer, r)
+
+			rec.Duration = time.Since(rec.Time)
+			rec.ResponseBody = writer.body.Bytes()
+			rec.ResponseHeader = w.Header().Clone()
+			rec.Status = writer.status
+			rec.Truncated = rec.Truncated || writer.truncated
+
+			if err := opts.Store.Save(context.WithoutCancel(r.Context()), rec); err != nil {
+				logError(opts.Logger, r, "could not save recording", err)
+			}
+		})
+	}
+}
+
+// RecordRoutes matches requests for any of routes,
+// each a path template, e.g., "/users/{id}", optionally preceded by an HTTP method,
+// e.g., "POST /users/{id}".
+func RecordRoutes(routes ...string) func(*http.Request) bool {
+	return func(r *http.Request) bool {
+		pattern := routePattern(r)
+		if pattern == "" {
+			return false
+		}
+
+		return slices.Contains(routes, pattern) || slices.Contains(routes, r.Method+" "+pattern)
+	}
+}
+
+// Replay issues the request rec recorded to h again, returning a Recording of the exchange.
+func Replay(ctx context.Context, h http.Handler, rec Recording) Recording {
+	r := httptest.NewRequestWithContext(ctx, rec.Method, rec.URL, bytes.NewReader(rec.RequestBody))
+	r.Header = rec.RequestHeader.Clone()
+	if host := r.Header.Get("Host"); host != "" {
+		r.Host = host
+		r.Header.Del("Host")
+	}
+
+	replayed := Recording{
+		ID:            uuid.NewString(),
+		Method:        rec.Method,
+		Route:         rec.Route,
+		Time:          time.Now(),
+		URL:           rec.URL,
+		RequestBody:   rec.RequestBody,
+		RequestHeader: rec.RequestHeader,
+		Truncated:     rec.Truncated,
+	}
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	replayed.Duration = time.Since(replayed.Time)
+	replayed.ResponseBody = w.Body.Bytes()
+	replayed.ResponseHeader = w.Header().Clone()
+	replayed.Status = w.Code
+
+	return replayed
+}
+
+// truncate cuts b to limit bytes, reporting whether it was longer.
+func truncate(b []byte, limit int64) ([]byte, bool) {
+	if int64(len(b)) > limit {
+		return b[:limit], true
+	}
+
+	return b, false
+}
+
+// A recordWriter keeps a copy of the status and body written in response to a request.
+type recordWriter struct {
+	http.ResponseWriter
+	body        bytes.Buffer
+	limit       int64
+	status      int
+	truncated   bool
+	wroteHeader bool
+}
+
+// WriteHeader records the status code before writing it.
+func (rw *recordWriter) WriteHeader(code int) {
+	if !rw.wroteHeader {
+		rw.status = code
+		rw.wroteHeader = true
+	}
+
+	rw.ResponseWriter.WriteHeader(code)
+}
+
+// Write keeps a copy of b, up to the limit, before writing it.
+func (rw *recordWriter) Write(b []byte) (int, error) {
+	rw.wroteHeader = true
+	if room := rw.limit - int64(rw.body.Len()); room > 0 {
+		kept, cut := truncate(b, room)
+		rw.body.Write(kept)
+		rw.truncated = rw.truncated || cut
+	} else if len(b) > 0 {
+		rw.truncated = true
+	}
+
+	return rw.ResponseWriter.Write(b)
+}
+
+// Unwrap returns the http.ResponseWriter wrapped, so http.ResponseController can reach it.
+func (rw *recordWriter) Unwrap() http.ResponseWriter { return rw.ResponseWriter }
+
+// A FileRecordStore is a RecordStore keeping each Recording as a JSON file in Dir.
+type FileRecordStore struct {
+	Dir string
+}
+
+// Recording reads the Recording identified by id.
+func (s FileRecordStore) Recording(_ context.Context, id string) (Recording, error) {
+	if id == "" || strings.ContainsAny(id, `/\.`) {
+		return Recording{}, fmt.Errorf("%w: recording %q", trails.ErrNotExist, id)
+	}
+
+	b, err := os.ReadFile(filepath.Join(s.Dir, id+".json"))
+	if errors.Is(err, fs.ErrNotExist) {
+		return Recording{}, fmt.Errorf("%w: recording %q", trails.ErrNotExist, id)
+	}
+	if err != nil {
+		return Recording{}, fmt.Errorf("could not read recording %q: %w", id, err)
+	}
+
+	var rec Recording
+	if err := json.Unmarshal(b, &rec); err != nil {
+		return Recording{}, fmt.Errorf("could not decode recording %q: %w", id, err)
+	}
+
+	return rec, nil
+}
+
+// Recordings reads every Recording in Dir, newest first.
+func (s FileRecordStore) Recordings(ctx context.Context) ([]Recording, error) {
+	paths, err := filepath.Glob(filepath.Join(s.Dir, "*.json"))
+	if err != nil {
+		return nil, fmt.Errorf("could not list recordings: %w", err)
+	}
+
+	recs := make([]Recording, 0, len(paths))
+	for _, path := range paths {
+		rec, err := s.Recording(ctx, strings.TrimSuffix(filepath.Base(path), ".json"))
+		if err != nil {
+			return nil, err
+		}
+
+		recs = append(recs, rec)
+	}
+
+	slices.SortFunc(recs, func(a, b Recording) int { return b.Time.Compare(a.Time) })
+
+	return recs, nil
+}
+
+// Save writes rec to a file in Dir named after its ID, creating Dir if need be.
+func (s FileRecordStore) Save(_ context.Context, rec Recording) error {
+	if err := os.MkdirAll(s.Dir, 0o755); err != nil {
+		return fmt.Errorf("could not save recording %q: %w", rec.ID, err)
+	}
+
+	b, err := json.MarshalIndent(rec, "", "  ")
+	if err != nil {
+		return fmt.Errorf("could not encode recording %q: %w", rec.ID, err)
+	}
+
+	if err := os.WriteFile(filepath.Join(s.Dir, rec.ID+".json"), b, 0o600); err != nil {
+		return fmt.Errorf("could not save recording %q: %w", rec.ID, err)
+	}
+
+	return nil
+}
+
+// RecordingMigrations create the table a PostgresRecordStore keeps Recordings in.
+// Run them with [postgres.MigrateUp], e.g., by including them in ranger.Config.Migrations.
+var RecordingMigrations = []postgres.Migration{
+	{
+		Key: "trails-recordings-20241101-create-table",
+		Executor: func(db *gorm.DB) error {
+			return db.Exec(`
+				CREATE TABLE trails_recordings (
+					id UUID PRIMARY KEY,
+					recorded_at TIMESTAMPTZ NOT NULL,
+					recording JSONB NOT NULL
+				);
+
+				CREATE INDEX trails_recordings_recorded_at_idx ON trails_recordings (recorded_at);
+			`).Error
+		},
+	},
+}
+
+// A PostgresRecordStore is a RecordStore keeping Recordings in a PostgreSQL database.
+//
+// Run RecordingMigrations before using a PostgresRecordStore.
+type PostgresRecordStore struct {
+	db *gorm.DB
+}
+
+// NewPostgresRecordStore constructs a *PostgresRecordStore keeping Recordings in db.
+func NewPostgresRecordStore(db *gorm.DB) *PostgresRecordStore { return &PostgresRecordStore{db: db} }
+
+// Recording retrieves the Recording identified by id.
+func (s *PostgresRecordStore) Recording(ctx context.Context, id string) (Recording, error) {
+	if uuid.Validate(id) != nil {
+		return Recording{}, fmt.Errorf("%w: recording %q", trails.ErrNotExist, id)
+	}
+
+	var rows []struct{ Recording []byte }
+	err := s.db.WithContext(ctx).
+		Raw(`SELECT recording FROM trails_recordings WHERE id = ?`, id).
+		Scan(&rows).
+		Error
+	if err != nil {
+		return Recording{}, fmt.Errorf("could not get recording %q: %w", id, err)
+	}
+
+	if len(rows) == 0 {
+		return Recording{}, fmt.Errorf("%w: recording %q", trails.ErrNotExist, id)
+	}
+
+	var rec Recording
+	if err := json.Unmarshal(rows[0].Recording, &rec); err != nil {
+		return Recording{}, fmt.Errorf("could not decode recording %q: %w", id, err)
+	}
+
+	return rec, nil
+}
+
+// Recordings lists every Recording, newest first.
+func (s *PostgresRecordStore) Recordings(ctx context.Context) ([]Recording, error) {
+	var rows []struct{ Recording []byte }
+	err := s.db.WithContext(ctx).
+		Raw(`SELECT recording FROM trails_recordings ORDER BY recorded_at DESC`).
+		Scan(&rows).
+		Error
+	if err != nil {
+		return nil, fmt.Errorf("could not list recordings: %w", err)
+	}
+
+	recs := make([]Recording, len(rows))
+	for i, row := range rows {
+		if err := json.Unmarshal(row.Recording, &recs[i]); err != nil {
+			return nil, fmt.Errorf("could not decode recording: %w", err)
+		}
+	}
+
+	return recs, nil
+}
+
+// Save inserts rec.
+func (s *PostgresRecordStore) Save(ctx context.Context, rec Recording) error {
+	b, err := json.Marshal(rec)
+	if err != nil {
+		return fmt.Errorf("could not encode recording %q: %w", rec.ID, err)
+	}
+
+	err = s.db.WithContext(ctx).
+		Exec(`INSERT INTO trails_recordings (id, recorded_at, recording) VALUES (?, ?, ?)`, rec.ID, rec.Time, string(b)).
+		Error
+	if err != nil {
+		return fmt.Errorf("could not save recording %q: %w", rec.ID, err)
+	}
+
+	return nil
+}
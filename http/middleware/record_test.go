@@ -0,0 +1,188 @@
+package middleware_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/require"
+	"github.com/xy-planning-network/trails"
+	"github.com/xy-planning-network/trails/http/middleware"
+	"github.com/xy-planning-network/trails/logger"
+)
+
+func TestRecord(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		limit     int64
+		body      string
+		reqBody   string
+		resBody   string
+		truncated bool
+	}{
+		{"Whole", 0, "hello", "hello", "HELLO", false},
+		{"Truncated", 3, "hello", "hel", "HEL", true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			store := middleware.FileRecordStore{Dir: t.TempDir()}
+			var read string
+			h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				b, _ := io.ReadAll(r.Body)
+				read = string(b)
+				w.Header().Set("X-Teapot", "true")
+				w.WriteHeader(http.StatusTeapot)
+				w.Write([]byte(strings.ToUpper(read)))
+			})
+
+			opts := middleware.RecordOpts{MaxBodyBytes: tc.limit, Store: store}
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPost, "https://example.com/echo?q=1", strings.NewReader(tc.body))
+			r.Header.Set("Content-Type", "text/plain")
+
+			// Act
+			middleware.Record(opts)(h).ServeHTTP(w, r)
+
+			// Assert
+			require.Equal(t, tc.body, read)
+			require.Equal(t, strings.ToUpper(tc.body), w.Body.String())
+
+			recs, err := store.Recordings(context.Background())
+			require.NoError(t, err)
+			require.Len(t, recs, 1)
+
+			rec := recs[0]
+			require.Equal(t, http.MethodPost, rec.Method)
+			require.Equal(t, "/echo?q=1", rec.URL)
+			require.Equal(t, "text/plain", rec.RequestHeader.Get("Content-Type"))
+			require.Equal(t, "example.com", rec.RequestHeader.Get("Host"))
+			require.Equal(t, tc.reqBody, string(rec.RequestBody))
+			require.Equal(t, http.StatusTeapot, rec.Status)
+			require.Equal(t, "true", rec.ResponseHeader.Get("X-Teapot"))
+			require.Equal(t, tc.resBody, string(rec.ResponseBody))
+			require.Equal(t, tc.truncated, rec.Truncated)
+
+			found, err := store.Recording(context.Background(), rec.ID)
+			require.NoError(t, err)
+			require.Equal(t, rec.ID, found.ID)
+		})
+	}
+}
+
+func TestRecordSaveFails(t *testing.T) {
+	// Arrange
+	dir := filepath.Join(t.TempDir(), "file")
+	require.NoError(t, os.WriteFile(dir, nil, 0o644))
+
+	b := new(bytes.Buffer)
+	opts := middleware.RecordOpts{
+		Logger: logger.New(slog.New(slog.NewTextHandler(b, nil)), trails.Testing),
+		Store:  middleware.FileRecordStore{Dir: dir},
+	}
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "https://example.com", nil)
+
+	// Act
+	middleware.Record(opts)(teapotHandler()).ServeHTTP(w, r)
+
+	// Assert
+	require.Equal(t, http.StatusTeapot, w.Code)
+	require.Contains(t, b.String(), "could not save recording")
+}
+
+func TestRecordRoutes(t *testing.T) {
+	// Arrange
+	store := middleware.FileRecordStore{Dir: t.TempDir()}
+	opts := middleware.RecordOpts{Match: middleware.RecordRoutes("POST /users/{id}", "/teams"), Store: store}
+
+	rtr := mux.NewRouter()
+	rtr.Use(mux.MiddlewareFunc(middleware.Record(opts)))
+	rtr.Handle("/users/{id}", teapotHandler())
+	rtr.Handle("/teams", teapotHandler())
+	rtr.Handle("/orgs", teapotHandler())
+
+	// Act
+	for _, req := range []struct{ method, path string }{
+		{http.MethodPost, "/users/1"},
+		{http.MethodGet, "/users/1"},
+		{http.MethodGet, "/teams"},
+		{http.MethodPost, "/orgs"},
+	} {
+		rtr.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(req.method, "https://example.com"+req.path, nil))
+	}
+
+	// Assert
+	recs, err := store.Recordings(context.Background())
+	require.NoError(t, err)
+
+	var recorded []string
+	for _, rec := range recs {
+		recorded = append(recorded, rec.Method+" "+rec.Route)
+	}
+	require.ElementsMatch(t, []string{"POST /users/{id}", "GET /teams"}, recorded)
+}
+
+func TestRecordNilStore(t *testing.T) {
+	// Arrange
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "https://example.com", nil)
+
+	// Act
+	middleware.Record(middleware.RecordOpts{})(teapotHandler()).ServeHTTP(w, r)
+
+	// Assert
+	require.Equal(t, http.StatusTeapot, w.Code)
+}
+
+func TestFileRecordStoreRecordingMissing(t *testing.T) {
+	// Arrange
+	store := middleware.FileRecordStore{Dir: t.TempDir()}
+
+	for _, id := range []string{"missing", "../etc/passwd", ""} {
+		// Act
+		_, err := store.Recording(context.Background(), id)
+
+		// Assert
+		require.ErrorIs(t, err, trails.ErrNotExist)
+	}
+}
+
+func TestReplay(t *testing.T) {
+	// Arrange
+	store := middleware.FileRecordStore{Dir: t.TempDir()}
+	calls := 0
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		b, _ := io.ReadAll(r.Body)
+		w.Write([]byte(r.Host + " " + r.Header.Get("X-Client") + " " + string(b)))
+	})
+
+	r := httptest.NewRequest(http.MethodPut, "https://example.com/things/1", strings.NewReader("payload"))
+	r.Header.Set("X-Client", "ios")
+	middleware.Record(middleware.RecordOpts{Store: store})(h).ServeHTTP(httptest.NewRecorder(), r)
+
+	recs, err := store.Recordings(context.Background())
+	require.NoError(t, err)
+	require.Len(t, recs, 1)
+
+	// Act
+	replayed := middleware.Replay(context.Background(), h, recs[0])
+
+	// Assert
+	require.Equal(t, 2, calls)
+	require.NotEqual(t, recs[0].ID, replayed.ID)
+	require.Equal(t, http.MethodPut, replayed.Method)
+	require.Equal(t, "/things/1", replayed.URL)
+	require.Equal(t, http.StatusOK, replayed.Status)
+	require.Equal(t, "example.com ios payload", string(replayed.ResponseBody))
+	require.Equal(t, recs[0].ResponseBody, replayed.ResponseBody)
+}
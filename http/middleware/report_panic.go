@@ -0,0 +1,63 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"runtime/debug"
+	"strings"
+
+	"github.com/xy-planning-network/trails/http/resp"
+	"github.com/xy-planning-network/trails/logger"
+)
+
+// ReportPanicWithResponder encloses the env and returns a function that when called,
+// wraps the passed in http.HandlerFunc in order to recover from and report panics.
+//
+// ReportPanicWithResponder captures the stack of the panicking goroutine and hands it to [resp.Responder.Panic],
+// which logs it alongside the request ID and current user - reporting it to Sentry when so configured -
+// and responds with the Responder's error template or a JSON problem body.
+//
+// ReportPanicWithResponder lets [http.ErrAbortHandler] continue panicking, so net/http aborts the response.
+//
+// If d is nil, ReportPanicWithResponder logs using l, unless l is also nil, and responds with a plain 500.
+//
+// In the development environment, ReportPanicWithResponder also writes the panic and stack to the response body
+// when no Responder is available.
+func ReportPanicWithResponder(env string, d *resp.Responder, l logger.Logger) func(http.HandlerFunc) http.HandlerFunc {
+	return func(handler http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			defer func() {
+				recovered := recover()
+				if recovered == nil {
+					return
+				}
+
+				if recovered == http.ErrAbortHandler {
+					panic(recovered)
+				}
+
+				stack := debug.Stack()
+				if d != nil {
+					d.Panic(w, r, recovered, stack)
+					return
+				}
+
+				if l != nil {
+					l.Error(fmt.Sprintf("%s: %v", resp.ErrPanic, recovered), &logger.LogContext{
+						Data:    map[string]any{"stack": string(stack)},
+						Request: r,
+					})
+				}
+
+				msg := http.StatusText(http.StatusInternalServerError)
+				if strings.EqualFold(env, "development") {
+					msg = fmt.Sprintf("%s: %v\n\n%s", resp.ErrPanic, recovered, stack)
+				}
+
+				http.Error(w, msg, http.StatusInternalServerError)
+			}()
+
+			handler(w, r)
+		}
+	}
+}
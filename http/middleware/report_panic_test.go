@@ -0,0 +1,101 @@
+package middleware_test
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/xy-planning-network/trails"
+	"github.com/xy-planning-network/trails/http/middleware"
+	"github.com/xy-planning-network/trails/http/resp"
+	"github.com/xy-planning-network/trails/logger"
+)
+
+func TestReportPanic(t *testing.T) {
+	panicker := func(v any) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) { panic(v) }
+	}
+
+	t.Run("No-Panic", func(t *testing.T) {
+		// Arrange
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "https://example.com", nil)
+
+		// Act
+		middleware.ReportPanicWithResponder("TESTING", resp.NewResponder(), nil)(teapotHandler().ServeHTTP)(w, r)
+
+		// Assert
+		require.Equal(t, http.StatusTeapot, w.Code)
+	})
+
+	t.Run("Responder", func(t *testing.T) {
+		// Arrange
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "https://example.com", nil)
+		r.Header.Set("Accept", "application/json")
+
+		// Act
+		middleware.ReportPanicWithResponder("TESTING", resp.NewResponder(), nil)(panicker("boom"))(w, r)
+
+		// Assert
+		require.Equal(t, http.StatusInternalServerError, w.Code)
+		require.Equal(t, "application/problem+json", w.Header().Get("Content-Type"))
+	})
+
+	t.Run("Nil-Responder", func(t *testing.T) {
+		// Arrange
+		b := new(bytes.Buffer)
+		l := logger.New(slog.New(slog.NewTextHandler(b, nil)), trails.Testing)
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "https://example.com", nil)
+
+		// Act
+		middleware.ReportPanicWithResponder("TESTING", nil, l)(panicker("boom"))(w, r)
+
+		// Assert
+		require.Equal(t, http.StatusInternalServerError, w.Code)
+		require.NotContains(t, w.Body.String(), "boom")
+		require.Contains(t, b.String(), "boom")
+		require.Contains(t, b.String(), "goroutine")
+	})
+
+	t.Run("Nil-Responder-Development", func(t *testing.T) {
+		// Arrange
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "https://example.com", nil)
+
+		// Act
+		middleware.ReportPanicWithResponder("DEVELOPMENT", nil, nil)(panicker("boom"))(w, r)
+
+		// Assert
+		require.Equal(t, http.StatusInternalServerError, w.Code)
+		require.Contains(t, w.Body.String(), "boom")
+		require.Contains(t, w.Body.String(), "goroutine")
+	})
+
+	t.Run("Abort-Handler", func(t *testing.T) {
+		// Arrange
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "https://example.com", nil)
+
+		// Act + Assert
+		require.PanicsWithValue(t, http.ErrAbortHandler, func() {
+			middleware.ReportPanicWithResponder("TESTING", resp.NewResponder(), nil)(panicker(http.ErrAbortHandler))(w, r)
+		})
+	})
+
+	t.Run("Deprecated-Development", func(t *testing.T) {
+		// Arrange
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "https://example.com", nil)
+
+		// Act
+		middleware.ReportPanic("DEVELOPMENT")(teapotHandler().ServeHTTP)(w, r)
+
+		// Assert
+		require.Equal(t, http.StatusTeapot, w.Code)
+	})
+}
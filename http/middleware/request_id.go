@@ -1,22 +1,101 @@
 package middleware
 
 import (
-	"context"
 	"net/http"
+	"net/netip"
 
 	"github.com/google/uuid"
 	"github.com/xy-planning-network/trails"
 )
 
-// RequestID adds a UUID to the request context using trails.RequestIDKey.
+const (
+	// RequestIDHeader names the header a request's ID is sent in,
+	// whether echoed to the client or forwarded to other services.
+	RequestIDHeader = "X-Request-ID"
+
+	// maxRequestIDLen is the longest incoming request ID RequestID honors.
+	maxRequestIDLen = 128
+)
+
+// RequestID adds an ID to the request context using trails.RequestIDKey
+// and echoes it in the "X-Request-ID" response header.
+//
+// When the request comes from a proxy in trusted, e.g., a load balancer assigning IDs itself,
+// RequestID uses the ID in its "X-Request-ID" header, if it is sensible.
+// Otherwise, RequestID generates a UUID.
 //
-// TODO(dlk): use "X-Request-ID" or similar header for UUID value.
-func RequestID() Adapter {
+// To correlate logs across services, forward the ID on outbound requests with RequestIDTransport.
+func RequestID(trusted ...netip.Prefix) Adapter {
 	return func(h http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			ctx := context.WithValue(r.Context(), trails.RequestIDKey, uuid.NewString())
+			id := r.Header.Get(RequestIDHeader)
+			if !validRequestID(id) || !fromTrusted(r, trusted) {
+				id = uuid.NewString()
+			}
+
+			w.Header().Set(RequestIDHeader, id)
+
+			ctx := trails.WithValue(r.Context(), trails.RequestIDKey, id)
 			*r = *r.Clone(ctx)
 			h.ServeHTTP(w, r)
 		})
 	}
 }
+
+// RequestIDTransport wraps next, setting the "X-Request-ID" header of outbound requests
+// to the ID RequestID stashed in their context.Context, unless already set.
+//
+// If next is nil, http.DefaultTransport is used.
+//
+//	client := &http.Client{Transport: middleware.RequestIDTransport(nil)}
+//	req, _ := http.NewRequestWithContext(r.Context(), http.MethodGet, url, nil)
+//	client.Do(req)
+func RequestIDTransport(next http.RoundTripper) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+
+	return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		id, ok := trails.Value[string](req.Context(), trails.RequestIDKey)
+		if !ok || id == "" || req.Header.Get(RequestIDHeader) != "" {
+			return next.RoundTrip(req)
+		}
+
+		// NOTE: a RoundTripper must not modify the request it is given.
+		req = req.Clone(req.Context())
+		req.Header.Set(RequestIDHeader, id)
+		return next.RoundTrip(req)
+	})
+}
+
+// roundTripperFunc adapts a func to an http.RoundTripper.
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+// RoundTrip calls f(req).
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) { return f(req) }
+
+// fromTrusted checks whether r comes directly from a proxy in trusted.
+func fromTrusted(r *http.Request, trusted []netip.Prefix) bool {
+	remote, err := netip.ParseAddrPort(r.RemoteAddr)
+	if err != nil {
+		return false
+	}
+
+	return isTrusted(remote.Addr().Unmap(), trusted)
+}
+
+// validRequestID checks whether id is sensible to log and echo:
+// not empty, not too long, and only printable ASCII.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLen {
+		return false
+	}
+
+	for i := 0; i < len(id); i++ {
+		if id[i] < '!' || id[i] > '~' {
+			return false
+		}
+	}
+
+	return true
+}
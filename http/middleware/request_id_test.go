@@ -1,8 +1,11 @@
 package middleware_test
 
 import (
+	"context"
 	"net/http"
 	"net/http/httptest"
+	"net/netip"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -23,5 +26,99 @@ func TestRequestID(t *testing.T) {
 		val, ok := rx.Context().Value(trails.RequestIDKey).(string)
 		require.True(t, ok)
 		require.NotZero(t, val)
+		require.Equal(t, val, wx.Header().Get(middleware.RequestIDHeader))
 	})).ServeHTTP(w, r)
 }
+
+func TestRequestIDTrusted(t *testing.T) {
+	trusted := []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8")}
+
+	for _, tc := range []struct {
+		name     string
+		remote   string
+		id       string
+		expected bool
+	}{
+		{"Trusted", "10.0.0.1:1234", "abc-123", true},
+		{"Untrusted", "203.0.113.1:1234", "abc-123", false},
+		{"Too-Long", "10.0.0.1:1234", strings.Repeat("a", 129), false},
+		{"Unprintable", "10.0.0.1:1234", "abc\x00123", false},
+		{"Spaces", "10.0.0.1:1234", "abc 123", false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodGet, "https://example.com", nil)
+			r.RemoteAddr = tc.remote
+			r.Header.Set(middleware.RequestIDHeader, tc.id)
+
+			var actual string
+
+			// Act
+			middleware.RequestID(trusted...)(http.HandlerFunc(func(wx http.ResponseWriter, rx *http.Request) {
+				actual, _ = trails.Value[string](rx.Context(), trails.RequestIDKey)
+			})).ServeHTTP(w, r)
+
+			// Assert
+			require.NotZero(t, actual)
+			require.Equal(t, tc.expected, actual == tc.id)
+			require.Equal(t, actual, w.Header().Get(middleware.RequestIDHeader))
+		})
+	}
+}
+
+func TestRequestIDTransport(t *testing.T) {
+	var actual string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		actual = r.Header.Get(middleware.RequestIDHeader)
+	}))
+	t.Cleanup(srv.Close)
+
+	client := &http.Client{Transport: middleware.RequestIDTransport(nil)}
+
+	t.Run("Forwarded", func(t *testing.T) {
+		// Arrange
+		ctx := context.WithValue(context.Background(), trails.RequestIDKey, "abc-123")
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL, nil)
+		require.NoError(t, err)
+
+		// Act
+		res, err := client.Do(req)
+
+		// Assert
+		require.NoError(t, err)
+		res.Body.Close()
+		require.Equal(t, "abc-123", actual)
+		require.Empty(t, req.Header.Get(middleware.RequestIDHeader))
+	})
+
+	t.Run("Already-Set", func(t *testing.T) {
+		// Arrange
+		ctx := context.WithValue(context.Background(), trails.RequestIDKey, "abc-123")
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL, nil)
+		require.NoError(t, err)
+		req.Header.Set(middleware.RequestIDHeader, "xyz-789")
+
+		// Act
+		res, err := client.Do(req)
+
+		// Assert
+		require.NoError(t, err)
+		res.Body.Close()
+		require.Equal(t, "xyz-789", actual)
+	})
+
+	t.Run("No-ID", func(t *testing.T) {
+		// Arrange
+		req, err := http.NewRequest(http.MethodGet, srv.URL, nil)
+		require.NoError(t, err)
+
+		// Act
+		res, err := client.Do(req)
+
+		// Assert
+		require.NoError(t, err)
+		res.Body.Close()
+		require.Empty(t, actual)
+	})
+}
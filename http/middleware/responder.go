@@ -0,0 +1,25 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/xy-planning-network/trails"
+	"github.com/xy-planning-network/trails/http/resp"
+)
+
+// InjectResponder stashes d in the *http.Request.Context under trails.ResponderKey,
+// for handlers to retrieve with resp.FromContext.
+//
+// If d is nil, NoopAdapter returns and this middleware does nothing.
+func InjectResponder(d *resp.Responder) Adapter {
+	if d == nil {
+		return NoopAdapter
+	}
+
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			*r = *r.Clone(trails.WithValue(r.Context(), trails.ResponderKey, d))
+			h.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,37 @@
+package middleware_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/xy-planning-network/trails/http/middleware"
+	"github.com/xy-planning-network/trails/http/resp"
+)
+
+func TestInjectResponder(t *testing.T) {
+	// Act
+	adpt := middleware.InjectResponder(nil)
+
+	// Assert
+	require.Equal(t, fmt.Sprintf("%p", middleware.NoopAdapter), fmt.Sprintf("%p", adpt))
+
+	// Arrange
+	d := resp.NewResponder()
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "https://example.com", nil)
+
+	var actual *resp.Responder
+	var err error
+
+	// Act
+	middleware.InjectResponder(d)(http.HandlerFunc(func(wx http.ResponseWriter, rx *http.Request) {
+		actual, err = resp.FromContext(rx.Context())
+	})).ServeHTTP(w, r)
+
+	// Assert
+	require.NoError(t, err)
+	require.Same(t, d, actual)
+}
@@ -0,0 +1,83 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/xy-planning-network/trails"
+	"github.com/xy-planning-network/trails/http/middleware"
+)
+
+func TestSecureHeaders(t *testing.T) {
+	t.Run("Production", func(t *testing.T) {
+		// Arrange
+		var nonce string
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "https://example.com", nil)
+		cfg := middleware.DefaultSecureHeadersConfig(trails.Production)
+
+		// Act
+		middleware.SecureHeaders(cfg)(http.HandlerFunc(func(wx http.ResponseWriter, rx *http.Request) {
+			nonce, _ = rx.Context().Value(trails.NonceKey).(string)
+		})).ServeHTTP(w, r)
+
+		// Assert
+		require.NotZero(t, nonce)
+		h := w.Header()
+		require.Equal(t, "nosniff", h.Get("X-Content-Type-Options"))
+		require.Equal(t, "SAMEORIGIN", h.Get("X-Frame-Options"))
+		require.Equal(t, "max-age=63072000; includeSubDomains", h.Get("Strict-Transport-Security"))
+		require.Equal(t, cfg.PermissionsPolicy, h.Get("Permissions-Policy"))
+		require.Equal(t, cfg.ReferrerPolicy, h.Get("Referrer-Policy"))
+		require.Equal(
+			t,
+			strings.ReplaceAll(middleware.DefaultContentSecurityPolicy, middleware.NoncePlaceholder, nonce),
+			h.Get("Content-Security-Policy"),
+		)
+		require.Empty(t, h.Get("Content-Security-Policy-Report-Only"))
+	})
+
+	t.Run("Development", func(t *testing.T) {
+		// Arrange
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "https://example.com", nil)
+
+		// Act
+		middleware.SecureHeaders(middleware.DefaultSecureHeadersConfig(trails.Development))(noopHandler()).ServeHTTP(w, r)
+
+		// Assert
+		h := w.Header()
+		require.Empty(t, h.Get("Strict-Transport-Security"))
+		require.Empty(t, h.Get("Content-Security-Policy"))
+		require.NotEmpty(t, h.Get("Content-Security-Policy-Report-Only"))
+	})
+
+	t.Run("Overrides", func(t *testing.T) {
+		// Arrange
+		var ok bool
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "https://example.com", nil)
+		cfg := middleware.SecureHeadersConfig{
+			ContentSecurityPolicy: "default-src 'none'",
+			HSTSMaxAge:            time.Minute,
+			HSTSPreload:           true,
+		}
+
+		// Act
+		middleware.SecureHeaders(cfg)(http.HandlerFunc(func(wx http.ResponseWriter, rx *http.Request) {
+			_, ok = rx.Context().Value(trails.NonceKey).(string)
+		})).ServeHTTP(w, r)
+
+		// Assert
+		require.False(t, ok)
+		h := w.Header()
+		require.Equal(t, "default-src 'none'", h.Get("Content-Security-Policy"))
+		require.Equal(t, "max-age=60; preload", h.Get("Strict-Transport-Security"))
+		require.Empty(t, h.Get("X-Frame-Options"))
+		require.Empty(t, h.Get("Referrer-Policy"))
+	})
+}
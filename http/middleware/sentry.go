@@ -10,6 +10,9 @@ import (
 // ReportPanic encloses the env and returns a function that when called,
 // wraps the passed in http.HandlerFunc in sentryhttp.HandleFunc
 // in order to recover and report panics.
+//
+// Deprecated: Use [ReportPanicWithResponder], which renders the response with a *resp.Responder
+// and reports panics to Sentry through its logger when so configured.
 func ReportPanic(env string) func(http.HandlerFunc) http.HandlerFunc {
 	return func(handler http.HandlerFunc) http.HandlerFunc {
 		if strings.EqualFold(env, "development") {
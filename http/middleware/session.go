@@ -1,7 +1,6 @@
 package middleware
 
 import (
-	"context"
 	"net/http"
 
 	"github.com/xy-planning-network/trails"
@@ -19,7 +18,7 @@ func InjectSession(store session.SessionStorer) Adapter {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			s, _ := store.GetSession(r)
 
-			ctx := context.WithValue(r.Context(), trails.SessionKey, s)
+			ctx := trails.WithValue(r.Context(), trails.SessionKey, s)
 			*r = *r.Clone(ctx)
 
 			s.Save(w, r)
@@ -0,0 +1,117 @@
+package middleware
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/xy-planning-network/trails"
+)
+
+// A Layer names an Adapter in a Stack.
+type Layer struct {
+	Adapter Adapter
+	Name    string
+}
+
+// Named constructs a Layer naming the Adapter.
+func Named(name string, a Adapter) Layer { return Layer{Adapter: a, Name: name} }
+
+// A Stack is an ordered list of Adapters, each named,
+// so a chain of middlewares built elsewhere can be customized by name
+// rather than rebuilt from scratch, e.g.:
+//
+//	stack.Before("CurrentUser", middleware.Named("Audit", audit))
+//	stack.Replace("RateLimit", middleware.RateLimit(vs))
+//
+// The first Adapter in a Stack is the first called on a request.
+//
+// Methods acting on a name act on the first Layer with that name,
+// returning trails.ErrNotExist if there is none.
+type Stack struct {
+	layers []Layer
+}
+
+// NewStack constructs a *Stack from the Layers, in order.
+func NewStack(layers ...Layer) *Stack {
+	return &Stack{layers: slices.Clone(layers)}
+}
+
+// Adapters lists the Adapters in the Stack, in order,
+// ready for [Chain] or [router.Router.OnEveryRequest].
+func (s *Stack) Adapters() []Adapter {
+	adapters := make([]Adapter, len(s.layers))
+	for i, l := range s.layers {
+		adapters[i] = l.Adapter
+	}
+
+	return adapters
+}
+
+// After inserts the Layers directly after the one named.
+func (s *Stack) After(name string, layers ...Layer) error {
+	i, err := s.index(name)
+	if err != nil {
+		return err
+	}
+
+	s.layers = slices.Insert(s.layers, i+1, layers...)
+	return nil
+}
+
+// Append adds the Layers to the end of the Stack.
+func (s *Stack) Append(layers ...Layer) {
+	s.layers = append(s.layers, layers...)
+}
+
+// Before inserts the Layers directly before the one named.
+func (s *Stack) Before(name string, layers ...Layer) error {
+	i, err := s.index(name)
+	if err != nil {
+		return err
+	}
+
+	s.layers = slices.Insert(s.layers, i, layers...)
+	return nil
+}
+
+// List names the Layers in the Stack, in order.
+func (s *Stack) List() []string {
+	names := make([]string, len(s.layers))
+	for i, l := range s.layers {
+		names[i] = l.Name
+	}
+
+	return names
+}
+
+// Remove drops the Layer named from the Stack.
+func (s *Stack) Remove(name string) error {
+	i, err := s.index(name)
+	if err != nil {
+		return err
+	}
+
+	s.layers = slices.Delete(s.layers, i, i+1)
+	return nil
+}
+
+// Replace swaps the Adapter of the Layer named for a, keeping its position.
+func (s *Stack) Replace(name string, a Adapter) error {
+	i, err := s.index(name)
+	if err != nil {
+		return err
+	}
+
+	s.layers[i].Adapter = a
+	return nil
+}
+
+// index finds the position of the first Layer named.
+func (s *Stack) index(name string) (int, error) {
+	i := slices.IndexFunc(s.layers, func(l Layer) bool { return l.Name == name })
+	if i < 0 {
+		return 0, fmt.Errorf("%w: no middleware named %q", trails.ErrNotExist, name)
+	}
+
+	return i, nil
+}
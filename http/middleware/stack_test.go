@@ -0,0 +1,84 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/xy-planning-network/trails"
+	"github.com/xy-planning-network/trails/http/middleware"
+)
+
+func TestStack(t *testing.T) {
+	// mark appends name to the "X-Order" header so tests can assert the order Adapters are called in.
+	mark := func(name string) middleware.Layer {
+		return middleware.Named(name, func(h http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Add("X-Order", name)
+				h.ServeHTTP(w, r)
+			})
+		})
+	}
+
+	order := func(s *middleware.Stack) string {
+		w := httptest.NewRecorder()
+		middleware.Chain(teapotHandler(), s.Adapters()...).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+		return strings.Join(w.Header().Values("X-Order"), ",")
+	}
+
+	t.Run("Before-After-Append", func(t *testing.T) {
+		// Arrange
+		s := middleware.NewStack(mark("A"), mark("C"))
+
+		// Act
+		errBefore := s.Before("C", mark("B"))
+		errAfter := s.After("C", mark("D"))
+		s.Append(mark("E"))
+
+		// Assert
+		require.NoError(t, errBefore)
+		require.NoError(t, errAfter)
+		require.Equal(t, []string{"A", "B", "C", "D", "E"}, s.List())
+		require.Equal(t, "A,B,C,D,E", order(s))
+	})
+
+	t.Run("Replace", func(t *testing.T) {
+		// Arrange
+		s := middleware.NewStack(mark("A"), mark("B"))
+
+		// Act
+		err := s.Replace("A", mark("Z").Adapter)
+
+		// Assert
+		require.NoError(t, err)
+		require.Equal(t, []string{"A", "B"}, s.List())
+		require.Equal(t, "Z,B", order(s))
+	})
+
+	t.Run("Remove", func(t *testing.T) {
+		// Arrange
+		s := middleware.NewStack(mark("A"), mark("B"))
+
+		// Act
+		err := s.Remove("A")
+
+		// Assert
+		require.NoError(t, err)
+		require.Equal(t, []string{"B"}, s.List())
+		require.Equal(t, "B", order(s))
+	})
+
+	t.Run("Not-Exist", func(t *testing.T) {
+		// Arrange
+		s := middleware.NewStack(mark("A"))
+
+		// Act + Assert
+		require.ErrorIs(t, s.Before("missing", mark("B")), trails.ErrNotExist)
+		require.ErrorIs(t, s.After("missing", mark("B")), trails.ErrNotExist)
+		require.ErrorIs(t, s.Replace("missing", mark("B").Adapter), trails.ErrNotExist)
+		require.ErrorIs(t, s.Remove("missing"), trails.ErrNotExist)
+		require.Equal(t, []string{"A"}, s.List())
+	})
+}
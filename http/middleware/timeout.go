@@ -0,0 +1,137 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TimeoutMsg is the message written to clients when a request times out.
+const TimeoutMsg = "The request took too long to complete. Please try again."
+
+// Timeout sets a deadline of d on the *http.Request.Context.
+// Handlers, and the *resp.Responder methods they call, ought to stop work once the context is done.
+//
+// Timeout buffers what the handler writes and copies it to the client once the handler returns.
+// When the deadline passes first, Timeout writes 503 to the client instead
+// and writes by the handler thereafter fail with http.ErrHandlerTimeout.
+// If the request's "Accept" header has "application/json" in it,
+// the body is JSON in the same shape as *resp.Responder.Json: {"data": {"error": TimeoutMsg}}.
+// Otherwise, the body is TimeoutMsg.
+//
+// If d is not positive, NoopAdapter returns and this middleware does nothing.
+func Timeout(d time.Duration) Adapter {
+	if d <= 0 {
+		return NoopAdapter
+	}
+
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx, cancel := context.WithTimeout(r.Context(), d)
+			defer cancel()
+
+			// NOTE: the handler may outlive the deadline, running alongside middlewares before Timeout,
+			// so it gets a copy of r, which replaces r only once the handler returns in time.
+			inner := r.Clone(ctx)
+			tw := &timeoutWriter{ctx: ctx, header: make(http.Header), status: http.StatusOK}
+			done := make(chan struct{})
+			panicked := make(chan any, 1)
+			go func() {
+				defer func() {
+					if p := recover(); p != nil {
+						panicked <- p
+					}
+				}()
+
+				h.ServeHTTP(tw, inner)
+				close(done)
+			}()
+
+			select {
+			case p := <-panicked:
+				panic(p)
+
+			case <-done:
+				tw.mu.Lock()
+				defer tw.mu.Unlock()
+
+				// NOTE: values set after Timeout outlive its deadline, which middlewares before Timeout are not bound by.
+				*r = *inner.Clone(context.WithoutCancel(inner.Context()))
+
+				header := w.Header()
+				for k, v := range tw.header {
+					header[k] = v
+				}
+
+				w.WriteHeader(tw.status)
+				w.Write(tw.body.Bytes())
+
+			case <-ctx.Done():
+				tw.mu.Lock()
+				defer tw.mu.Unlock()
+
+				// NOTE: the handler may still be running, so mark the writer as timed out before responding.
+				tw.timedOut = true
+				writeUnavailable(w, r, TimeoutMsg)
+			}
+		})
+	}
+}
+
+// writeUnavailable writes 503 and msg in a body formatted according to the request's "Accept" header.
+func writeUnavailable(w http.ResponseWriter, r *http.Request, msg string) {
+	for _, v := range r.Header.Values("Accept") {
+		if strings.Contains(v, "application/json") {
+			w.Header().Set(contentTypeHeader, "application/json; charset=UTF-8")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			json.NewEncoder(w).Encode(map[string]any{"data": map[string]string{"error": msg}})
+			return
+		}
+	}
+
+	http.Error(w, msg, http.StatusServiceUnavailable)
+}
+
+// A timeoutWriter buffers a response until Timeout decides whether to write it to the client.
+type timeoutWriter struct {
+	body        bytes.Buffer
+	ctx         context.Context
+	header      http.Header
+	mu          sync.Mutex
+	status      int
+	timedOut    bool
+	wroteHeader bool
+}
+
+// Header returns the buffered headers.
+func (tw *timeoutWriter) Header() http.Header { return tw.header }
+
+// Write buffers b, failing with http.ErrHandlerTimeout if the request timed out.
+func (tw *timeoutWriter) Write(b []byte) (int, error) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+
+	if tw.timedOut || tw.ctx.Err() != nil {
+		return 0, http.ErrHandlerTimeout
+	}
+
+	tw.wroteHeader = true
+	return tw.body.Write(b)
+}
+
+// WriteHeader buffers the first status written.
+func (tw *timeoutWriter) WriteHeader(code int) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+
+	if tw.timedOut || tw.wroteHeader || tw.ctx.Err() != nil {
+		return
+	}
+
+	tw.status = code
+	tw.wroteHeader = true
+}
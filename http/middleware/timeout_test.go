@@ -0,0 +1,98 @@
+package middleware_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/xy-planning-network/trails"
+	"github.com/xy-planning-network/trails/http/middleware"
+)
+
+func TestTimeout(t *testing.T) {
+	t.Run("Completes", func(t *testing.T) {
+		// Arrange
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "https://example.com", nil)
+		h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, ok := r.Context().Deadline()
+			require.True(t, ok)
+
+			*r = *r.Clone(context.WithValue(r.Context(), trails.RequestIDKey, "id"))
+			w.Header().Set("X-Custom", "val")
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte("done"))
+		})
+
+		// Act
+		middleware.Timeout(time.Second)(h).ServeHTTP(w, r)
+
+		// Assert
+		require.Equal(t, http.StatusCreated, w.Code)
+		require.Equal(t, "val", w.Header().Get("X-Custom"))
+		require.Equal(t, "done", w.Body.String())
+		require.Equal(t, "id", r.Context().Value(trails.RequestIDKey))
+		require.NoError(t, r.Context().Err())
+	})
+
+	t.Run("Times-Out", func(t *testing.T) {
+		for _, tc := range []struct {
+			name     string
+			accept   string
+			expected string
+		}{
+			{"Html", "text/html", middleware.TimeoutMsg + "\n"},
+			{"Json", "application/json", `{"data":{"error":"` + middleware.TimeoutMsg + `"}}` + "\n"},
+		} {
+			t.Run(tc.name, func(t *testing.T) {
+				// Arrange
+				werr := make(chan error, 1)
+				w := httptest.NewRecorder()
+				r := httptest.NewRequest(http.MethodGet, "https://example.com", nil)
+				r.Header.Set("Accept", tc.accept)
+				h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					<-r.Context().Done()
+					_, err := w.Write([]byte("late"))
+					werr <- err
+				})
+
+				// Act
+				middleware.Timeout(time.Millisecond)(h).ServeHTTP(w, r)
+
+				// Assert
+				require.Equal(t, http.StatusServiceUnavailable, w.Code)
+				require.Equal(t, tc.expected, w.Body.String())
+				require.ErrorIs(t, <-werr, http.ErrHandlerTimeout)
+			})
+		}
+	})
+
+	t.Run("Panics", func(t *testing.T) {
+		// Arrange
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "https://example.com", nil)
+		h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { panic("oops") })
+
+		// Act + Assert
+		require.PanicsWithValue(t, "oops", func() { middleware.Timeout(time.Second)(h).ServeHTTP(w, r) })
+	})
+
+	t.Run("Noop", func(t *testing.T) {
+		// Arrange
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "https://example.com", nil)
+		h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, ok := r.Context().Deadline()
+			require.False(t, ok)
+		})
+
+		// Act
+		middleware.Timeout(0)(h).ServeHTTP(w, r)
+
+		// Assert
+		require.Equal(t, http.StatusOK, w.Code)
+	})
+}
@@ -0,0 +1,102 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/xy-planning-network/trails"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracePropagator reads and writes W3C "traceparent", "tracestate", and "baggage" headers.
+var tracePropagator = propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{})
+
+// Trace starts a span with tracer for every request,
+// continuing the trace a W3C "traceparent" header on the request identifies, if any.
+//
+// Trace stashes the span in the *http.Request.Context, where [trace.SpanFromContext] retrieves it;
+// instrumentation of database queries or outgoing HTTP requests using that context
+// records their spans as children of it.
+// Use InjectTrace to propagate the trace to outgoing HTTP requests.
+//
+// Trace also stashes the trace ID in the *http.Request.Context under trails.TraceIDKey,
+// which LogRequest records as "traceId" and [logger.LogContext] includes with the request,
+// and sets the "traceparent" header on the response.
+//
+// The span is named after the route pattern matched, if any, e.g., "GET /users/{id}".
+// Responses with a 5xx status mark the span as an error.
+//
+// If tracer is nil, NoopAdapter returns and this middleware does nothing.
+func Trace(tracer trace.Tracer) Adapter {
+	if tracer == nil {
+		return NoopAdapter
+	}
+
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := tracePropagator.Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+
+			name := r.Method
+			route := routePattern(r)
+			if route != "" {
+				name += " " + route
+			}
+
+			ctx, span := tracer.Start(
+				ctx,
+				name,
+				trace.WithSpanKind(trace.SpanKindServer),
+				trace.WithAttributes(
+					semconv.HTTPRequestMethodKey.String(r.Method),
+					semconv.URLPath(r.URL.Path),
+					semconv.UserAgentOriginal(r.UserAgent()),
+				),
+			)
+			defer span.End()
+
+			if route != "" {
+				span.SetAttributes(semconv.HTTPRoute(route))
+			}
+
+			if sc := span.SpanContext(); sc.HasTraceID() {
+				ctx = trails.WithValue(ctx, trails.TraceIDKey, sc.TraceID().String())
+			}
+
+			tracePropagator.Inject(ctx, propagation.HeaderCarrier(w.Header()))
+
+			writer := &requestLogger{ResponseWriter: w, status: http.StatusOK}
+			*r = *r.Clone(ctx)
+			h.ServeHTTP(writer, r)
+
+			span.SetAttributes(semconv.HTTPResponseStatusCode(writer.status))
+			if writer.status >= http.StatusInternalServerError {
+				span.SetStatus(codes.Error, http.StatusText(writer.status))
+			}
+		})
+	}
+}
+
+// InjectTrace sets W3C "traceparent" and related headers on the outgoing request
+// so the service receiving it continues the trace in ctx.
+func InjectTrace(ctx context.Context, req *http.Request) {
+	tracePropagator.Inject(ctx, propagation.HeaderCarrier(req.Header))
+}
+
+// routePattern retrieves the path template of the route the request matched, if any.
+func routePattern(r *http.Request) string {
+	route := mux.CurrentRoute(r)
+	if route == nil {
+		return ""
+	}
+
+	tmpl, err := route.GetPathTemplate()
+	if err != nil {
+		return ""
+	}
+
+	return tmpl
+}
@@ -0,0 +1,76 @@
+package middleware_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/require"
+	"github.com/xy-planning-network/trails"
+	"github.com/xy-planning-network/trails/http/middleware"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestTrace(t *testing.T) {
+	// Arrange + Act
+	actual := middleware.Trace(nil)
+
+	// Assert
+	require.Equal(t, fmt.Sprintf("%p", middleware.NoopAdapter), fmt.Sprintf("%p", actual))
+
+	// Arrange
+	rec := tracetest.NewSpanRecorder()
+	tracer := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec)).Tracer("test")
+
+	var (
+		ctxSpan trace.Span
+		traceID string
+	)
+	rt := mux.NewRouter()
+	rt.Handle("/users/{id}", middleware.Trace(tracer)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctxSpan = trace.SpanFromContext(r.Context())
+		traceID, _ = r.Context().Value(trails.TraceIDKey).(string)
+		w.WriteHeader(http.StatusBadGateway)
+	})))
+
+	parent := "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "https://example.com/users/1", nil)
+	r.Header.Set("traceparent", parent)
+
+	// Act
+	rt.ServeHTTP(w, r)
+
+	// Assert
+	spans := rec.Ended()
+	require.Len(t, spans, 1)
+
+	span := spans[0]
+	require.Equal(t, "GET /users/{id}", span.Name())
+	require.Equal(t, trace.SpanKindServer, span.SpanKind())
+	require.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", span.SpanContext().TraceID().String())
+	require.Equal(t, "00f067aa0ba902b7", span.Parent().SpanID().String())
+	require.Equal(t, codes.Error, span.Status().Code)
+	require.Contains(t, span.Attributes(), attribute.String("http.route", "/users/{id}"))
+	require.Contains(t, span.Attributes(), attribute.Int("http.response.status_code", http.StatusBadGateway))
+
+	require.Equal(t, span.SpanContext().SpanID(), ctxSpan.SpanContext().SpanID())
+	require.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", traceID)
+	require.Contains(t, w.Header().Get("traceparent"), "4bf92f3577b34da6a3ce929d0e0e4736")
+	require.NotEqual(t, parent, w.Header().Get("traceparent"))
+
+	// Arrange
+	out := httptest.NewRequest(http.MethodGet, "https://other.example.com", nil)
+
+	// Act
+	middleware.InjectTrace(trace.ContextWithSpan(r.Context(), ctxSpan), out)
+
+	// Assert
+	require.Equal(t, w.Header().Get("traceparent"), out.Header.Get("traceparent"))
+}
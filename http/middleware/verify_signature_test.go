@@ -0,0 +1,175 @@
+package middleware_test
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/xy-planning-network/trails/http/middleware"
+)
+
+func TestVerifySignature(t *testing.T) {
+	body := `{"event":"paid"}`
+	sign := func(secret, payload string) string {
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write([]byte(payload))
+		return hex.EncodeToString(mac.Sum(nil))
+	}
+
+	now := time.Now().Unix()
+	stale := time.Now().Add(-time.Hour).Unix()
+
+	tcs := []struct {
+		name     string
+		cfg      middleware.SignatureConfig
+		header   string
+		value    string
+		body     string
+		expected int
+	}{
+		{
+			"GitHub",
+			middleware.SignatureConfig{Secrets: []string{"new"}},
+			middleware.GitHubSignatureHeader,
+			"sha256=" + sign("new", body),
+			body,
+			http.StatusTeapot,
+		},
+		{
+			"GitHub-Rotated-Secret",
+			middleware.SignatureConfig{Secrets: []string{"new", "old"}},
+			middleware.GitHubSignatureHeader,
+			"sha256=" + sign("old", body),
+			body,
+			http.StatusTeapot,
+		},
+		{
+			"GitHub-Wrong-Secret",
+			middleware.SignatureConfig{Secrets: []string{"new"}},
+			middleware.GitHubSignatureHeader,
+			"sha256=" + sign("other", body),
+			body,
+			http.StatusUnauthorized,
+		},
+		{
+			"GitHub-Tampered-Body",
+			middleware.SignatureConfig{Secrets: []string{"new"}},
+			middleware.GitHubSignatureHeader,
+			"sha256=" + sign("new", body),
+			`{"event":"refunded"}`,
+			http.StatusUnauthorized,
+		},
+		{
+			"Missing-Header",
+			middleware.SignatureConfig{Secrets: []string{"new"}},
+			middleware.GitHubSignatureHeader,
+			"",
+			body,
+			http.StatusUnauthorized,
+		},
+		{
+			"No-Secrets",
+			middleware.SignatureConfig{},
+			middleware.GitHubSignatureHeader,
+			"sha256=" + sign("", body),
+			body,
+			http.StatusUnauthorized,
+		},
+		{
+			"Custom-Header",
+			middleware.SignatureConfig{Header: "X-Signature", Secrets: []string{"new"}},
+			"X-Signature",
+			"sha256=" + sign("new", body),
+			body,
+			http.StatusTeapot,
+		},
+		{
+			"Stripe",
+			middleware.SignatureConfig{Format: middleware.SignatureStripe, Secrets: []string{"new"}},
+			middleware.StripeSignatureHeader,
+			fmt.Sprintf("t=%d,v1=%s", now, sign("new", fmt.Sprintf("%d.%s", now, body))),
+			body,
+			http.StatusTeapot,
+		},
+		{
+			"Stripe-Multiple-Signatures",
+			middleware.SignatureConfig{Format: middleware.SignatureStripe, Secrets: []string{"old"}},
+			middleware.StripeSignatureHeader,
+			fmt.Sprintf(
+				"t=%d,v1=%s,v1=%s",
+				now,
+				sign("new", fmt.Sprintf("%d.%s", now, body)),
+				sign("old", fmt.Sprintf("%d.%s", now, body)),
+			),
+			body,
+			http.StatusTeapot,
+		},
+		{
+			"Stripe-Stale",
+			middleware.SignatureConfig{Format: middleware.SignatureStripe, Secrets: []string{"new"}},
+			middleware.StripeSignatureHeader,
+			fmt.Sprintf("t=%d,v1=%s", stale, sign("new", fmt.Sprintf("%d.%s", stale, body))),
+			body,
+			http.StatusUnauthorized,
+		},
+		{
+			"Stripe-Tolerance",
+			middleware.SignatureConfig{Format: middleware.SignatureStripe, Secrets: []string{"new"}, Tolerance: 2 * time.Hour},
+			middleware.StripeSignatureHeader,
+			fmt.Sprintf("t=%d,v1=%s", stale, sign("new", fmt.Sprintf("%d.%s", stale, body))),
+			body,
+			http.StatusTeapot,
+		},
+		{
+			"Stripe-No-Timestamp",
+			middleware.SignatureConfig{Format: middleware.SignatureStripe, Secrets: []string{"new"}},
+			middleware.StripeSignatureHeader,
+			"v1=" + sign("new", "."+body),
+			body,
+			http.StatusUnauthorized,
+		},
+		{
+			"Too-Large",
+			middleware.SignatureConfig{MaxBodySize: 4, Secrets: []string{"new"}},
+			middleware.GitHubSignatureHeader,
+			"sha256=" + sign("new", body),
+			body,
+			http.StatusRequestEntityTooLarge,
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			var read string
+			h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				b, _ := io.ReadAll(r.Body)
+				read = string(b)
+				w.WriteHeader(http.StatusTeapot)
+			})
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPost, "https://example.com/webhooks", strings.NewReader(tc.body))
+			if tc.value != "" {
+				r.Header.Set(tc.header, tc.value)
+			}
+
+			// Act
+			middleware.VerifySignature(tc.cfg)(h).ServeHTTP(w, r)
+
+			// Assert
+			require.Equal(t, tc.expected, w.Code)
+			if tc.expected == http.StatusTeapot {
+				require.Equal(t, tc.body, read)
+			}
+		})
+	}
+}
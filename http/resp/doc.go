@@ -25,5 +25,8 @@ Notably, a Responder contains data on default templates, keys used for an *http.
 the web app's root URL and so forth.
 ResponderOptFns carry out configuring a Responder and are unlikely to be used within a handler.
 Instead, it is expected these feature in a web app's router setup steps.
+
+Rather than respond to its own errors, a handler can be a HandlerFunc, returning them instead;
+HandlerFunc responds to them with the status code fitting what they are, e.g., 422 for ValidationErrors.
 */
 package resp
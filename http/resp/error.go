@@ -1,6 +1,11 @@
 package resp
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+
+	"github.com/xy-planning-network/trails"
+)
 
 var (
 	ErrBadConfig   = errors.New("improperly configured")
@@ -8,5 +13,6 @@ var (
 	ErrInvalid     = errors.New("invalid")
 	ErrMissingData = errors.New("missing data")
 	ErrNotFound    = errors.New("not found")
-	ErrNoUser      = errors.New("no user")
+	ErrNoUser      = fmt.Errorf("%w: no user", trails.ErrUnauthenticated)
+	ErrPanic       = errors.New("panic")
 )
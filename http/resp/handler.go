@@ -0,0 +1,136 @@
+package resp
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/xy-planning-network/trails"
+	"github.com/xy-planning-network/trails/http/session"
+)
+
+// A HandlerFunc handles a request, returning an error instead of responding to it when it fails,
+// rather than every handler responding to its own errors:
+//
+//	func (h *Handler) showUser(w http.ResponseWriter, r *http.Request) error {
+//		user, err := h.users.Find(r.Context(), id)
+//		if err != nil {
+//			return err
+//		}
+//
+//		return h.Html(w, r, resp.Authed(), resp.Tmpls("user.tmpl"), resp.Data(user))
+//	}
+//
+//	router.Route{Path: "/users/{id}", Method: http.MethodGet, Handler: resp.HandlerFunc(h.showUser).ServeHTTP}
+//
+// The error is responded to with the *Responder stashed in the *http.Request.Context, cf. FromContext,
+// with the status code trails.StatusFor maps it to, e.g., 422 for ValidationErrors, logging it if that is 500.
+//
+// If "text/html" appears in the "Accept" header, a request using a method other than GET, HEAD, OPTIONS, or TRACE,
+// e.g., submitting a form, is redirected to the same URL with an error flash; cf. GenericErr.
+// Other requests for HTML are responded to with Err for 500 and the status text otherwise.
+// Requests not for HTML are responded to with JSON, with the fields of ValidationErrors under "fields":
+//
+//	{"data": {"error": "Not Found"}}
+//
+// An error after the request's context.Context is done, e.g., ErrDone, is not responded to,
+// since the client is no longer waiting.
+type HandlerFunc func(w http.ResponseWriter, r *http.Request) error
+
+// ServeHTTP calls fn, responding to the error it returns.
+func (fn HandlerFunc) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	err := fn(w, r)
+	if err == nil || errors.Is(err, ErrDone) || errors.Is(err, context.Canceled) {
+		return
+	}
+
+	code := trails.StatusFor(err)
+	d, dErr := FromContext(r.Context())
+	if dErr != nil {
+		http.Error(w, http.StatusText(code), code)
+		return
+	}
+
+	d.respondErr(w, r, err, code)
+}
+
+// ValidationErrors maps the fields of a request failing validation to why, e.g., {"email": "is required"}.
+//
+// ValidationErrors is ErrInvalid and trails.ErrNotValid.
+type ValidationErrors map[string]string
+
+// Error lists the fields failing validation and why, ordered by field.
+func (ve ValidationErrors) Error() string {
+	fields := make([]string, 0, len(ve))
+	for field := range ve {
+		fields = append(fields, field)
+	}
+	slices.Sort(fields)
+
+	msgs := make([]string, len(fields))
+	for i, field := range fields {
+		msgs[i] = field + " " + ve[field]
+	}
+
+	return ErrInvalid.Error() + ": " + strings.Join(msgs, "; ")
+}
+
+// Is asserts whether target is ErrInvalid or trails.ErrNotValid.
+func (ValidationErrors) Is(target error) bool {
+	return target == ErrInvalid || target == trails.ErrNotValid
+}
+
+// StatusCode is 422.
+func (ValidationErrors) StatusCode() int { return http.StatusUnprocessableEntity }
+
+// respondErr responds to err a handler returned with code.
+func (doer *Responder) respondErr(w http.ResponseWriter, r *http.Request, err error, code int) {
+	var ve ValidationErrors
+	errors.As(err, &ve)
+
+	if strings.HasPrefix(r.Header.Get("Accept"), "text/html") {
+		switch {
+		case !isSafeMethod(r.Method):
+			fn := Flash(session.Flash{Type: session.FlashError, Msg: session.BadInputMsg})
+			if code >= http.StatusInternalServerError {
+				fn = GenericErr(err)
+			}
+
+			if doer.Redirect(w, r, fn, Url(r.URL.RequestURI())) == nil {
+				return
+			}
+		case code >= http.StatusInternalServerError:
+			doer.Err(w, r, err)
+			return
+		}
+
+		http.Error(w, http.StatusText(code), code)
+		return
+	}
+
+	data := map[string]any{"error": http.StatusText(code)}
+	if ve != nil {
+		data["fields"] = ve
+	}
+
+	opts := []Fn{Code(code), Data(data)}
+	if code >= http.StatusInternalServerError {
+		opts = append([]Fn{Err(err)}, opts...)
+	}
+
+	if doer.Json(w, r, opts...) != nil {
+		http.Error(w, http.StatusText(code), code)
+	}
+}
+
+// isSafeMethod asserts whether method does not change anything on the server, per RFC 9110.
+func isSafeMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,137 @@
+package resp_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/xy-planning-network/trails"
+	"github.com/xy-planning-network/trails/http/resp"
+	"github.com/xy-planning-network/trails/http/session"
+)
+
+func TestHandlerFunc(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		err      error
+		code     int
+		expected map[string]any
+	}{
+		{"Nil", nil, http.StatusTeapot, nil},
+		{
+			"Validation",
+			fmt.Errorf("could not save: %w", resp.ValidationErrors{"email": "is required"}),
+			http.StatusUnprocessableEntity,
+			map[string]any{"error": "Unprocessable Entity", "fields": map[string]any{"email": "is required"}},
+		},
+		{"Not-Valid", trails.ErrNotValid, http.StatusUnprocessableEntity, map[string]any{"error": "Unprocessable Entity"}},
+		{"Missing-Data", trails.ErrMissingData, http.StatusBadRequest, map[string]any{"error": "Bad Request"}},
+		{"No-User", resp.ErrNoUser, http.StatusUnauthorized, map[string]any{"error": "Unauthorized"}},
+		{"Not-Found", fmt.Errorf("%w: user 1", trails.ErrNotExist), http.StatusNotFound, map[string]any{"error": "Not Found"}},
+		{"Exists", fmt.Errorf("%w: email", trails.ErrExists), http.StatusConflict, map[string]any{"error": "Conflict"}},
+		{"Other", errors.New("boom"), http.StatusInternalServerError, map[string]any{"error": "Internal Server Error"}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			h := resp.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+				if tc.err == nil {
+					w.WriteHeader(http.StatusTeapot)
+				}
+
+				return tc.err
+			})
+
+			d := resp.NewResponder()
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodGet, "https://example.com/users/1", nil)
+			r = r.WithContext(trails.WithValue(r.Context(), trails.ResponderKey, d))
+
+			// Act
+			h.ServeHTTP(w, r)
+
+			// Assert
+			require.Equal(t, tc.code, w.Code)
+			if tc.expected == nil {
+				return
+			}
+
+			var body map[string]any
+			require.NoError(t, json.NewDecoder(w.Body).Decode(&body))
+			require.Equal(t, tc.expected, body["data"])
+		})
+	}
+}
+
+func TestHandlerFuncHtml(t *testing.T) {
+	h := resp.HandlerFunc(func(http.ResponseWriter, *http.Request) error {
+		return resp.ValidationErrors{"email": "is required"}
+	})
+	d := resp.NewResponder(resp.WithRootUrl("https://example.com"))
+
+	t.Run("Form", func(t *testing.T) {
+		// Arrange
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "https://example.com/signup?plan=pro", nil)
+		r.Header.Set("Accept", "text/html,application/xhtml+xml")
+
+		s, err := session.NewStub(false).GetSession(r)
+		require.NoError(t, err)
+
+		ctx := trails.WithValue(r.Context(), trails.ResponderKey, d)
+		r = r.WithContext(context.WithValue(ctx, trails.SessionKey, s))
+
+		// Act
+		h.ServeHTTP(w, r)
+
+		// Assert
+		require.Equal(t, http.StatusSeeOther, w.Code)
+		require.Equal(t, "/signup?plan=pro", w.Header().Get("Location"))
+		require.Equal(t, []session.Flash{{Type: session.FlashError, Msg: session.BadInputMsg}}, s.Flashes(w, r))
+	})
+
+	t.Run("Page", func(t *testing.T) {
+		// Arrange
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "https://example.com/signup", nil)
+		r.Header.Set("Accept", "text/html,application/xhtml+xml")
+		r = r.WithContext(trails.WithValue(r.Context(), trails.ResponderKey, d))
+
+		// Act
+		h.ServeHTTP(w, r)
+
+		// Assert
+		require.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	})
+}
+
+func TestHandlerFuncNoResponder(t *testing.T) {
+	// Arrange
+	h := resp.HandlerFunc(func(http.ResponseWriter, *http.Request) error { return trails.ErrNotExist })
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "https://example.com", nil)
+
+	// Act
+	h.ServeHTTP(w, r)
+
+	// Assert
+	require.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestValidationErrors(t *testing.T) {
+	// Arrange
+	ve := resp.ValidationErrors{"name": "is required", "email": "is invalid"}
+
+	// Act
+	err := fmt.Errorf("could not save: %w", ve)
+
+	// Assert
+	require.ErrorIs(t, err, resp.ErrInvalid)
+	require.ErrorIs(t, err, trails.ErrNotValid)
+	require.Equal(t, http.StatusUnprocessableEntity, trails.StatusFor(err))
+	require.EqualError(t, err, "could not save: invalid: email is invalid; name is required")
+}
@@ -0,0 +1,452 @@
+package resp
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// A PropsSchema describes the props a Vue entry point expects, as the subset of JSON Schema
+// declaring the types of values, the properties of objects and which are required, and the items of arrays.
+//
+// A PropsSchema is either derived from the Go type passing props to Vue, cf. PropsSchemaOf,
+// or unmarshaled from a JSON Schema generated from the TypeScript type of the entry point's props,
+// e.g., by typescript-json-schema, resolving "$ref"s to its "definitions" or "$defs".
+//
+// Register a PropsSchema with WithVueProps.
+type PropsSchema struct {
+	// Ref points to the PropsSchema this one is, e.g., "#/definitions/User".
+	Ref string `json:"$ref,omitempty"`
+
+	// Title names the type values are, e.g., the Go struct type PropsSchemaOf derives the PropsSchema from.
+	Title string `json:"title,omitempty"`
+
+	// Type lists the JSON types values may be: "array", "boolean", "integer", "null", "number", "object", or "string".
+	// Values may be any type when Type is empty.
+	Type SchemaTypes `json:"type,omitempty"`
+
+	// Properties describes the values of an object's keys.
+	Properties map[string]*PropsSchema `json:"properties,omitempty"`
+
+	// Required lists the keys an object must have.
+	Required []string `json:"required,omitempty"`
+
+	// AdditionalProperties describes the values of an object's keys not in Properties.
+	AdditionalProperties *PropsSchema `json:"additionalProperties,omitempty"`
+
+	// Items describes the values of an array.
+	Items *PropsSchema `json:"items,omitempty"`
+
+	// AnyOf lists the PropsSchemas, at least one of which values must match.
+	AnyOf []*PropsSchema `json:"anyOf,omitempty"`
+
+	// Definitions and Defs are the PropsSchemas Refs point to.
+	Definitions map[string]*PropsSchema `json:"definitions,omitempty"`
+	Defs        map[string]*PropsSchema `json:"$defs,omitempty"`
+}
+
+// UnmarshalJSON decodes b into s,
+// treating the boolean schemas true and false, e.g., "additionalProperties": false, as any value.
+func (s *PropsSchema) UnmarshalJSON(b []byte) error {
+	var boolean bool
+	if err := json.Unmarshal(b, &boolean); err == nil {
+		*s = PropsSchema{}
+		return nil
+	}
+
+	type schema PropsSchema
+	return json.Unmarshal(b, (*schema)(s))
+}
+
+// SchemaTypes are the JSON types a value matching a PropsSchema may be.
+//
+// SchemaTypes is a string in JSON when there is only one.
+type SchemaTypes []string
+
+// MarshalJSON encodes st as a string when there is only one type, and an array otherwise.
+func (st SchemaTypes) MarshalJSON() ([]byte, error) {
+	if len(st) == 1 {
+		return json.Marshal(st[0])
+	}
+
+	return json.Marshal([]string(st))
+}
+
+// UnmarshalJSON decodes b, either a string or an array of strings, into st.
+func (st *SchemaTypes) UnmarshalJSON(b []byte) error {
+	var one string
+	if err := json.Unmarshal(b, &one); err == nil {
+		*st = SchemaTypes{one}
+		return nil
+	}
+
+	var many []string
+	if err := json.Unmarshal(b, &many); err != nil {
+		return fmt.Errorf("%w: type is neither a string nor an array of strings: %v", ErrInvalid, err)
+	}
+
+	*st = many
+	return nil
+}
+
+// PropsSchemaOf derives the PropsSchema of the JSON v encodes to as encoding/json does,
+// e.g., from a struct's exported fields and their json tags.
+//
+// Structs are titled by the names of their types, and a struct within itself is only titled.
+// Fields without the "omitempty" or "omitzero" options are required,
+// and pointers, maps, and slices may also be null.
+// Values of types implementing json.Marshaler, other than those also implementing encoding.TextMarshaler,
+// and interfaces may be any type.
+//
+// Vue passes data other than a map[string]any under the "props" key, so describe it with, e.g.:
+//
+//	resp.PropsSchemaOf(struct {
+//		Props InvoiceProps `json:"props"`
+//	}{})
+func PropsSchemaOf(v any) *PropsSchema {
+	if v == nil {
+		return new(PropsSchema)
+	}
+
+	return schemaOf(reflect.TypeOf(v), nil)
+}
+
+var (
+	jsonMarshaler = reflect.TypeFor[json.Marshaler]()
+	textMarshaler = reflect.TypeFor[encoding.TextMarshaler]()
+)
+
+// schemaOf derives the PropsSchema of t, where seen are the structs t is in,
+// describing recursive structs only by their titles the second time they are seen.
+func schemaOf(t reflect.Type, seen []reflect.Type) *PropsSchema {
+	switch {
+	case t == reflect.TypeFor[time.Time]():
+		return &PropsSchema{Type: SchemaTypes{"string"}}
+	case t.Implements(jsonMarshaler) || reflect.PointerTo(t).Implements(jsonMarshaler):
+		if t.Implements(textMarshaler) || reflect.PointerTo(t).Implements(textMarshaler) {
+			return &PropsSchema{Type: SchemaTypes{"string"}}
+		}
+		return new(PropsSchema)
+	case t.Kind() != reflect.Map && (t.Implements(textMarshaler) || reflect.PointerTo(t).Implements(textMarshaler)):
+		return &PropsSchema{Type: SchemaTypes{"string"}}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &PropsSchema{Type: SchemaTypes{"boolean"}}
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return &PropsSchema{Type: SchemaTypes{"integer"}}
+
+	case reflect.Float32, reflect.Float64:
+		return &PropsSchema{Type: SchemaTypes{"number"}}
+
+	case reflect.String:
+		return &PropsSchema{Type: SchemaTypes{"string"}}
+
+	case reflect.Pointer:
+		return nullable(schemaOf(t.Elem(), seen))
+
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			// NOTE: encoding/json encodes []byte as a base64 string.
+			return &PropsSchema{Type: SchemaTypes{"string", "null"}}
+		}
+		return &PropsSchema{Type: SchemaTypes{"array", "null"}, Items: schemaOf(t.Elem(), seen)}
+
+	case reflect.Array:
+		return &PropsSchema{Type: SchemaTypes{"array"}, Items: schemaOf(t.Elem(), seen)}
+
+	case reflect.Map:
+		return &PropsSchema{Type: SchemaTypes{"object", "null"}, AdditionalProperties: schemaOf(t.Elem(), seen)}
+
+	case reflect.Struct:
+		if slices.Contains(seen, t) {
+			return &PropsSchema{Title: t.Name(), Type: SchemaTypes{"object"}}
+		}
+
+		s := &PropsSchema{Title: t.Name(), Type: SchemaTypes{"object"}, Properties: make(map[string]*PropsSchema)}
+		addFields(s, t, append(seen, t))
+		return s
+
+	default:
+		return new(PropsSchema)
+	}
+}
+
+// addFields adds the fields of the struct t encoding/json encodes to s,
+// including those of embedded structs.
+func addFields(s *PropsSchema, t reflect.Type, seen []reflect.Type) {
+	for _, f := range jsonFields(t) {
+		prop := schemaOf(f.typ, seen)
+		if f.quoted {
+			prop = &PropsSchema{Type: SchemaTypes{"string"}}
+		}
+
+		s.Properties[f.name] = prop
+		if !f.optional {
+			s.Required = append(s.Required, f.name)
+		}
+	}
+}
+
+// nullable copies s, letting values also be null.
+func nullable(s *PropsSchema) *PropsSchema {
+	if len(s.Type) == 0 || slices.Contains(s.Type, "null") {
+		return s
+	}
+
+	n := *s
+	n.Type = append(slices.Clip(s.Type), "null")
+	return &n
+}
+
+// Validate checks the JSON props encodes to matches s,
+// returning ValidationErrors mapping the path to each mismatch to what is wrong,
+// e.g., {"props.user.name": "is missing"}.
+func (s *PropsSchema) Validate(props any) error {
+	b, err := json.Marshal(props)
+	if err != nil {
+		return fmt.Errorf("%w: props do not encode to JSON: %v", ErrInvalid, err)
+	}
+
+	var v any
+	if err := json.Unmarshal(b, &v); err != nil {
+		return fmt.Errorf("%w: props do not decode from JSON: %v", ErrInvalid, err)
+	}
+
+	ve := make(ValidationErrors)
+	s.validate(s, "props", v, ve)
+	if len(ve) > 0 {
+		return ve
+	}
+
+	return nil
+}
+
+// validate records in ve where v, at path, does not match s,
+// resolving Refs against root.
+func (s *PropsSchema) validate(root *PropsSchema, path string, v any, ve ValidationErrors) {
+	s = root.resolve(s)
+
+	if len(s.AnyOf) > 0 {
+		matched := slices.ContainsFunc(s.AnyOf, func(alt *PropsSchema) bool {
+			altVE := make(ValidationErrors)
+			alt.validate(root, path, v, altVE)
+			return len(altVE) == 0
+		})
+		if !matched {
+			ve[path] = "matches none of the types it may be"
+			return
+		}
+	}
+
+	actual := jsonType(v)
+	if len(s.Type) > 0 && !slices.ContainsFunc(s.Type, func(t string) bool { return isType(v, actual, t) }) {
+		ve[path] = fmt.Sprintf("is %s, want %s", actual, strings.Join(s.Type, " or "))
+		return
+	}
+
+	switch v := v.(type) {
+	case map[string]any:
+		for _, key := range s.Required {
+			if _, ok := v[key]; !ok {
+				ve[path+"."+key] = "is missing"
+			}
+		}
+
+		for key, val := range v {
+			prop, ok := s.Properties[key]
+			if !ok {
+				prop = s.AdditionalProperties
+			}
+
+			if prop != nil {
+				prop.validate(root, path+"."+key, val, ve)
+			}
+		}
+
+	case []any:
+		if s.Items == nil {
+			return
+		}
+
+		for i, item := range v {
+			s.Items.validate(root, path+"["+strconv.Itoa(i)+"]", item, ve)
+		}
+	}
+}
+
+// resolve follows the Ref of s to the PropsSchema in root's Definitions or Defs it points to.
+// resolve returns s when it has no Ref or the Ref points nowhere.
+func (root *PropsSchema) resolve(s *PropsSchema) *PropsSchema {
+	// NOTE: bound how many Refs are followed, in case they point in a circle.
+	for range 32 {
+		var defs map[string]*PropsSchema
+		name, ok := strings.CutPrefix(s.Ref, "#/definitions/")
+		if ok {
+			defs = root.Definitions
+		} else if name, ok = strings.CutPrefix(s.Ref, "#/$defs/"); ok {
+			defs = root.Defs
+		}
+
+		next, ok := defs[name]
+		if !ok || next == nil {
+			return s
+		}
+
+		s = next
+	}
+
+	return s
+}
+
+// jsonType names the JSON type of v decoded by encoding/json.
+func jsonType(v any) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	case []any:
+		return "array"
+	default:
+		return "object"
+	}
+}
+
+// isType asserts whether v, whose JSON type is actual, is the JSON type want.
+func isType(v any, actual, want string) bool {
+	switch {
+	case want == actual:
+		return true
+	case want == "integer":
+		n, ok := v.(float64)
+		return ok && n == math.Trunc(n)
+	default:
+		return false
+	}
+}
+
+// A jsonField is a field of a struct encoding/json encodes.
+type jsonField struct {
+	name     string
+	typ      reflect.Type
+	optional bool
+	quoted   bool
+
+	depth  int
+	tagged bool
+}
+
+// jsonFields lists the fields of the struct t encoding/json encodes, ordered as it encodes them,
+// promoting the fields of embedded structs as it does.
+func jsonFields(t reflect.Type) []jsonField {
+	var all []jsonField
+	collectFields(t, 0, &all, nil)
+
+	var names []string
+	byName := make(map[string][]jsonField)
+	for _, f := range all {
+		if _, ok := byName[f.name]; !ok {
+			names = append(names, f.name)
+		}
+		byName[f.name] = append(byName[f.name], f)
+	}
+
+	// NOTE: as encoding/json does, the shallowest field by a name wins;
+	// when several are as shallow, the only one tagged with the name wins, or none do.
+	var fields []jsonField
+	for _, name := range names {
+		rivals := byName[name]
+		depth := slices.MinFunc(rivals, func(a, b jsonField) int { return a.depth - b.depth }).depth
+		rivals = slices.DeleteFunc(rivals, func(f jsonField) bool { return f.depth != depth })
+		if len(rivals) > 1 {
+			rivals = slices.DeleteFunc(rivals, func(f jsonField) bool { return !f.tagged })
+		}
+
+		if len(rivals) == 1 {
+			fields = append(fields, rivals[0])
+		}
+	}
+
+	return fields
+}
+
+// collectFields appends the fields of the struct t at depth to fields, where seen are the structs embedding t.
+func collectFields(t reflect.Type, depth int, fields *[]jsonField, seen []reflect.Type) {
+	if slices.Contains(seen, t) {
+		return
+	}
+	seen = append(seen, t)
+
+	for i := range t.NumField() {
+		sf := t.Field(i)
+		tag := sf.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+
+		name, opts, _ := strings.Cut(tag, ",")
+		ft := sf.Type
+		if sf.Anonymous {
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+
+			if !sf.IsExported() && ft.Kind() != reflect.Struct {
+				continue
+			}
+
+			if name == "" && ft.Kind() == reflect.Struct {
+				collectFields(ft, depth+1, fields, seen)
+				continue
+			}
+		} else if !sf.IsExported() {
+			continue
+		}
+
+		f := jsonField{name: name, typ: sf.Type, depth: depth, tagged: name != ""}
+		if name == "" {
+			f.name = sf.Name
+		}
+
+		for _, opt := range strings.Split(opts, ",") {
+			switch opt {
+			case "omitempty", "omitzero":
+				f.optional = true
+			case "string":
+				f.quoted = isQuotable(sf.Type)
+			}
+		}
+
+		*fields = append(*fields, f)
+	}
+}
+
+// isQuotable asserts whether the "string" option of a json tag applies to fields of type t.
+func isQuotable(t reflect.Type) bool {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.Bool, reflect.String, reflect.Float32, reflect.Float64,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return true
+	default:
+		return false
+	}
+}
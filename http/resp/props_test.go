@@ -0,0 +1,149 @@
+package resp_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/xy-planning-network/trails/http/resp"
+)
+
+type propsAddress struct {
+	City string `json:"city"`
+}
+
+type propsBase struct {
+	ID int `json:"id"`
+}
+
+type propsUser struct {
+	propsBase
+	Name      string        `json:"name"`
+	Nickname  string        `json:"nickname,omitempty"`
+	Address   *propsAddress `json:"address"`
+	Tags      []string      `json:"tags"`
+	Joined    time.Time     `json:"joined"`
+	Score     float64       `json:"score,string"`
+	Manager   *propsUser    `json:"manager,omitempty"`
+	Ignored   bool          `json:"-"`
+	unexposed bool
+}
+
+func TestPropsSchemaOf(t *testing.T) {
+	// Act
+	actual := resp.PropsSchemaOf(propsUser{})
+
+	// Assert
+	require.Equal(t, resp.SchemaTypes{"object"}, actual.Type)
+	require.Equal(t, []string{"id", "name", "address", "tags", "joined", "score"}, actual.Required)
+	require.Len(t, actual.Properties, 8)
+	require.Equal(t, resp.SchemaTypes{"integer"}, actual.Properties["id"].Type)
+	require.Equal(t, resp.SchemaTypes{"string"}, actual.Properties["nickname"].Type)
+	require.Equal(t, resp.SchemaTypes{"object", "null"}, actual.Properties["address"].Type)
+	require.Equal(t, resp.SchemaTypes{"string"}, actual.Properties["address"].Properties["city"].Type)
+	require.Equal(t, resp.SchemaTypes{"array", "null"}, actual.Properties["tags"].Type)
+	require.Equal(t, resp.SchemaTypes{"string"}, actual.Properties["tags"].Items.Type)
+	require.Equal(t, resp.SchemaTypes{"string"}, actual.Properties["joined"].Type)
+	require.Equal(t, resp.SchemaTypes{"string"}, actual.Properties["score"].Type)
+	require.Equal(t, "propsUser", actual.Title)
+	require.Equal(t, &resp.PropsSchema{Title: "propsUser", Type: resp.SchemaTypes{"object", "null"}}, actual.Properties["manager"])
+}
+
+func TestPropsSchemaValidate(t *testing.T) {
+	schema := resp.PropsSchemaOf(struct {
+		Props propsUser `json:"props"`
+	}{})
+
+	for _, tc := range []struct {
+		name     string
+		props    any
+		expected error
+	}{
+		{
+			"Valid",
+			map[string]any{"props": propsUser{Name: "Ada", Address: &propsAddress{}}, "initialProps": map[string]any{}},
+			nil,
+		},
+		{
+			"Missing",
+			map[string]any{"props": map[string]any{"id": 1, "name": "Ada", "address": nil, "tags": nil, "score": "1"}},
+			resp.ValidationErrors{"props.props.joined": "is missing"},
+		},
+		{
+			"Wrong-Types",
+			map[string]any{"props": map[string]any{
+				"id":      1.5,
+				"name":    "Ada",
+				"address": map[string]any{"city": 1},
+				"tags":    []any{"a", false},
+				"joined":  "2024-06-01T00:00:00Z",
+				"score":   1,
+			}},
+			resp.ValidationErrors{
+				"props.props.id":           "is number, want integer",
+				"props.props.address.city": "is number, want string",
+				"props.props.tags[1]":      "is boolean, want string",
+				"props.props.score":        "is number, want string",
+			},
+		},
+		{
+			"Not-Object",
+			[]int{1},
+			resp.ValidationErrors{"props": "is array, want object"},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			// Act
+			err := schema.Validate(tc.props)
+
+			// Assert
+			if tc.expected == nil {
+				require.NoError(t, err)
+				return
+			}
+
+			require.ErrorIs(t, err, resp.ErrInvalid)
+			require.Equal(t, tc.expected, err)
+		})
+	}
+}
+
+func TestPropsSchemaUnmarshal(t *testing.T) {
+	// Arrange
+	doc := `{
+		"$ref": "#/definitions/Props",
+		"definitions": {
+			"Props": {
+				"type": "object",
+				"properties": {
+					"user": {"$ref": "#/definitions/User"},
+					"status": {"anyOf": [{"type": "string"}, {"type": "null"}]},
+					"counts": {"type": "object", "additionalProperties": {"type": "integer"}}
+				},
+				"required": ["user", "status"]
+			},
+			"User": {
+				"type": "object",
+				"properties": {"email": {"type": ["string", "null"]}},
+				"required": ["email"],
+				"additionalProperties": false
+			}
+		}
+	}`
+
+	var schema resp.PropsSchema
+	require.NoError(t, json.Unmarshal([]byte(doc), &schema))
+
+	// Act
+	valid := schema.Validate(map[string]any{"user": map[string]any{"email": nil}, "status": "open"})
+	invalid := schema.Validate(map[string]any{"user": map[string]any{}, "status": 1, "counts": map[string]any{"open": "1"}})
+
+	// Assert
+	require.NoError(t, valid)
+	require.Equal(t, resp.ValidationErrors{
+		"props.user.email":  "is missing",
+		"props.status":      "matches none of the types it may be",
+		"props.counts.open": "is string, want integer",
+	}, invalid)
+}
@@ -3,6 +3,7 @@ package resp
 import (
 	"net/http"
 
+	"github.com/xy-planning-network/trails"
 	"github.com/xy-planning-network/trails/logger"
 )
 
@@ -15,6 +16,9 @@ func newLogContext(r *http.Request, err error, data any, user logger.LogUser) *l
 	ctx := new(logger.LogContext)
 	if r != nil {
 		ctx.Request = r
+		if impersonator, ok := trails.Value[logger.LogUser](r.Context(), trails.ImpersonatorKey); ok {
+			ctx.Impersonator = impersonator
+		}
 	}
 
 	if err != nil {
@@ -4,18 +4,22 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"encoding/xml"
 	"errors"
 	"fmt"
 	"net/http"
 	"net/url"
 	"path"
 	"runtime"
+	"strconv"
+	"strings"
 	"sync"
 
 	"github.com/xy-planning-network/trails"
 	"github.com/xy-planning-network/trails/http/session"
 	"github.com/xy-planning-network/trails/http/template"
 	"github.com/xy-planning-network/trails/logger"
+	"golang.org/x/text/language"
 )
 
 const responderFrames = 0
@@ -27,6 +31,7 @@ const responderFrames = 0
 //	Html
 //	Json
 //	Redirect
+//	Xml
 //
 // Most oftentimes, setting up a single instance of a Responder suffices for an application.
 // Meaning, one needs only application-wide configuration of how HTTP responses should look.
@@ -39,6 +44,12 @@ const responderFrames = 0
 type Responder struct {
 	logger logger.Logger
 
+	// Version of the API responses are from, set in the "API-Version" header
+	apiVersion string
+
+	// Fill in errors before logging them
+	enrichers []logger.ErrorEnricher
+
 	// Initialized template parser
 	parser *template.Parser
 
@@ -52,6 +63,12 @@ type Responder struct {
 	// Root URL the responder is listening on, also used when in an error state
 	rootUrl *url.URL
 
+	// Schema Json structures payloads with
+	schema JsonSchema
+
+	// PropsSchemas describing the props of each Vue entry point
+	vueProps map[string]*PropsSchema
+
 	templates struct {
 		additionalScripts string
 
@@ -131,6 +148,80 @@ func (doer *Responder) Err(w http.ResponseWriter, r *http.Request, err error, op
 	http.Error(w, msg, rr.code)
 }
 
+// Panic responds with 500 to a request whose handler panicked with recovered,
+// logging an error including stack and, if available, the current user.
+// When the Responder logs to Sentry, the error is reported there as well.
+//
+// If the request's "Accept" header has "application/json" in it,
+// the response is an RFC 9457 problem body.
+// Otherwise, Panic renders the error template set on the Responder,
+// falling back to plain text if it cannot.
+//
+// The value recovered is never shown to the client.
+func (doer *Responder) Panic(w http.ResponseWriter, r *http.Request, recovered any, stack []byte) {
+	err, ok := recovered.(error)
+	if ok {
+		err = fmt.Errorf("%w: %w", ErrPanic, err)
+	} else {
+		err = fmt.Errorf("%w: %v", ErrPanic, recovered)
+	}
+
+	var user logger.LogUser
+	if u, uErr := doer.CurrentUser(r.Context()); uErr == nil {
+		user, _ = u.(logger.LogUser)
+	}
+
+	lc := newLogContext(r, err, map[string]any{"stack": string(stack)}, user)
+	logger.EnrichError(lc, doer.enrichers...)
+	doer.logger.Error(err.Error(), lc)
+
+	code := http.StatusInternalServerError
+	for _, v := range r.Header.Values("Accept") {
+		if strings.Contains(v, "application/json") {
+			problem := map[string]any{
+				"status": code,
+				"title":  http.StatusText(code),
+				"type":   "about:blank",
+			}
+			if doer.contactErrMsg != "" {
+				problem["detail"] = doer.contactErrMsg
+			}
+			if id, ok := trails.Value[string](r.Context(), trails.RequestIDKey); ok {
+				problem["requestId"] = id
+			}
+
+			w.Header().Set("Content-Type", "application/problem+json")
+			w.WriteHeader(code)
+			json.NewEncoder(w).Encode(problem)
+			return
+		}
+	}
+
+	if doer.parser == nil || doer.templates.err == "" {
+		http.Error(w, http.StatusText(code), code)
+		return
+	}
+
+	tmpl, err := doer.parser.Parse(doer.templates.err)
+	if err != nil {
+		http.Error(w, http.StatusText(code), code)
+		return
+	}
+
+	b := doer.pool.Get().(*bytes.Buffer)
+	b.Reset()
+	defer doer.pool.Put(b)
+
+	data := map[string]any{"Contact": doer.contactErrMsg, "Error": errors.New(http.StatusText(code))}
+	if err := tmpl.Execute(b, data); err != nil {
+		http.Error(w, http.StatusText(code), code)
+		return
+	}
+
+	w.WriteHeader(code)
+	b.WriteTo(w)
+}
+
 // Html composes together HTML templates set in *Responder
 // and configured by Authed, Unauthed, Tmpls and other such calls.
 func (doer *Responder) Html(w http.ResponseWriter, r *http.Request, opts ...Fn) error {
@@ -161,7 +252,20 @@ func (doer *Responder) Html(w http.ResponseWriter, r *http.Request, opts ...Fn)
 		}
 	}
 
-	p := doer.parser.AddFn(template.CurrentUser(rr.user))
+	csrfToken, _ := trails.Value[string](r.Context(), trails.CSRFTokenKey)
+	botCheck, _ := trails.Value[string](r.Context(), trails.BotCheckKey)
+	p := doer.parser.
+		AddFn(template.BotCheckField(botCheck)).
+		AddFn(template.CurrentUser(rr.user)).
+		AddFn(template.CSRFField(csrfToken)).
+		AddFn(template.CSRFToken(csrfToken)).
+		AddFn(template.Impersonator(r.Context().Value(trails.ImpersonatorKey)))
+	if nonce, ok := trails.Value[string](r.Context(), trails.NonceKey); ok {
+		p = p.AddFn(template.RequestNonce(nonce))
+	}
+	if tag, ok := trails.Value[language.Tag](r.Context(), trails.LocaleKey); ok {
+		p = p.AddFn(template.Locale(tag.String()))
+	}
 
 	tmpl, err := p.Parse(rr.tmpls...)
 	if err != nil {
@@ -173,13 +277,11 @@ func (doer *Responder) Html(w http.ResponseWriter, r *http.Request, opts ...Fn)
 		Flashes []session.Flash
 	}{Data: rr.data}
 
-	s, err := doer.Session(r.Context())
-	if err != nil && !errors.Is(err, ErrNotFound) {
-		return doer.handleHtmlError(w, r, fmt.Errorf("can't retrieve session: %w", err))
+	rd.Flashes, err = doer.flashes(w, r, rr)
+	if err != nil {
+		return doer.handleHtmlError(w, r, err)
 	}
 
-	rd.Flashes = s.Flashes(w, r)
-
 	b := doer.pool.Get().(*bytes.Buffer)
 	b.Reset()
 	defer doer.pool.Put(b)
@@ -195,24 +297,67 @@ func (doer *Responder) Html(w http.ResponseWriter, r *http.Request, opts ...Fn)
 	return nil
 }
 
+// A JsonSchema is how Json structures the payloads it responds with.
+type JsonSchema string
+
+const (
+	// EnvelopeSchema wraps data under "data" and the current user under "currentUser".
+	// It is the default.
+	EnvelopeSchema JsonSchema = "envelope"
+
+	// BareSchema responds with data alone, e.g., a flat array, eliding the current user.
+	BareSchema JsonSchema = "bare"
+
+	// JsonApiSchema responds with JSON:API documents; cf. https://jsonapi.org/format/.
+	// Data ought to be resource objects, which JsonApiSchema places under "data",
+	// and the current user is placed under "meta".
+	// Responses with error status codes place a single error object under "errors" instead,
+	// with data as its "meta".
+	JsonApiSchema JsonSchema = "jsonapi"
+)
+
+// jsonApiMediaType is the media type of JSON:API documents.
+const jsonApiMediaType = "application/vnd.api+json"
+
 type jsonSchema struct {
-	D any `json:"data,omitempty"`
-	U any `json:"currentUser,omitempty"`
+	D any             `json:"data,omitempty"`
+	F []session.Flash `json:"flashes,omitempty"`
+	U any             `json:"currentUser,omitempty"`
+}
+
+type jsonApiSchema struct {
+	D       any            `json:"data,omitempty"`
+	Errors  []jsonApiError `json:"errors,omitempty"`
+	JsonApi struct {
+		Version string `json:"version"`
+	} `json:"jsonapi"`
+	Meta map[string]any `json:"meta,omitempty"`
+}
+
+type jsonApiError struct {
+	Meta   any    `json:"meta,omitempty"`
+	Status string `json:"status"`
+	Title  string `json:"title"`
 }
 
 // Json responds with data in JSON format, collating it from User(), Data() and setting appropriate headers.
 //
-// When standard 2xx codes are supplied, the JSON schema will look like this:
+// How the payload is structured depends on the JsonSchema set by WithJsonSchema or Schema,
+// the latter taking precedence.
+// For EnvelopeSchema, the default, when standard 2xx codes are supplied, the JSON schema will look like this:
 //
 //	{
 //		"currentUser": {},
-//		"data": {}
+//		"data": {},
+//		"flashes": []
 //	}
 //
 // Otherwise, "currentUser" is elided.
 //
 // User() calls populate "currentUser"
 // Data() calls populate "data"
+// Flash() calls, in this or earlier requests, populate "flashes", which is elided when there are none.
+// JsonApiSchema places flashes under "meta"; BareSchema leaves them in the session.
 func (doer *Responder) Json(w http.ResponseWriter, r *http.Request, opts ...Fn) error {
 	rr, err := doer.do(w, r, opts...)
 	// TODO(dlk): call Error() instead of silently closing Body?
@@ -230,9 +375,60 @@ func (doer *Responder) Json(w http.ResponseWriter, r *http.Request, opts ...Fn)
 		}
 	}
 
-	payload := jsonSchema{D: rr.data}
-	if rr.code >= http.StatusOK && rr.code <= http.StatusNoContent {
-		payload.U = rr.user
+	schema := rr.schema
+	if schema == "" {
+		schema = doer.schema
+	}
+
+	success := rr.code >= http.StatusOK && rr.code <= http.StatusNoContent
+	mediaType := "application/json; charset=UTF-8"
+
+	var flashes []session.Flash
+	if schema != BareSchema {
+		if flashes, err = doer.flashes(w, r, rr); err != nil {
+			doer.Err(w, r, err)
+			return err
+		}
+	}
+
+	var payload any
+	switch schema {
+	case BareSchema:
+		payload = rr.data
+	case JsonApiSchema:
+		mediaType = jsonApiMediaType
+		doc := jsonApiSchema{}
+		doc.JsonApi.Version = "1.1"
+		switch {
+		case rr.code >= http.StatusBadRequest:
+			doc.Errors = []jsonApiError{{
+				Meta:   rr.data,
+				Status: strconv.Itoa(rr.code),
+				Title:  http.StatusText(rr.code),
+			}}
+		case success && rr.user != nil:
+			doc.D = rr.data
+			doc.Meta = map[string]any{"currentUser": rr.user}
+		default:
+			doc.D = rr.data
+		}
+
+		if len(flashes) > 0 {
+			if doc.Meta == nil {
+				doc.Meta = make(map[string]any)
+			}
+
+			doc.Meta["flashes"] = flashes
+		}
+
+		payload = doc
+	default:
+		env := jsonSchema{D: rr.data, F: flashes}
+		if success {
+			env.U = rr.user
+		}
+
+		payload = env
 	}
 
 	b := doer.pool.Get().(*bytes.Buffer)
@@ -244,7 +440,100 @@ func (doer *Responder) Json(w http.ResponseWriter, r *http.Request, opts ...Fn)
 		return err
 	}
 
-	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.Header().Set("Content-Type", mediaType)
+	w.WriteHeader(rr.code)
+	if _, err := b.WriteTo(w); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+type xmlSchema struct {
+	XMLName xml.Name    `xml:"response"`
+	D       any         `xml:"data,omitempty"`
+	F       *xmlFlashes `xml:"flashes,omitempty"`
+	U       any         `xml:"currentUser,omitempty"`
+}
+
+// NOTE: unlike a pointer, a path like "flashes>flash" is never omitted when empty.
+type xmlFlashes struct {
+	F []session.Flash `xml:"flash"`
+}
+
+// Xml responds with data in XML format, collating it from User(), Data() and setting appropriate headers,
+// e.g., for partners integrating over XML.
+//
+// Xml structures payloads as Json does for the JsonSchema set by WithJsonSchema or Schema.
+// For EnvelopeSchema, the default, when standard 2xx codes are supplied, the XML schema will look like this:
+//
+//	<response>
+//		<data></data>
+//		<flashes><flash></flash></flashes>
+//		<currentUser></currentUser>
+//	</response>
+//
+// Otherwise, <currentUser> is elided, as are <data> and <flashes> when empty.
+// Data naming its own element with an XMLName field is encoded under that name rather than <data>.
+// BareSchema responds with data alone, which must then encode as a single XML element.
+// JSON:API being JSON-only, JsonApiSchema structures payloads as EnvelopeSchema does.
+//
+// Data must be encodable by [encoding/xml]; notably, maps are not.
+func (doer *Responder) Xml(w http.ResponseWriter, r *http.Request, opts ...Fn) error {
+	rr, err := doer.do(w, r, opts...)
+	if err != nil {
+		return err
+	}
+
+	if rr.closeBody {
+		defer r.Body.Close()
+	}
+
+	if rr.code == 0 {
+		if err := Code(http.StatusOK)(*doer, rr); err != nil {
+			return err
+		}
+	}
+
+	schema := rr.schema
+	if schema == "" {
+		schema = doer.schema
+	}
+
+	var payload any
+	switch schema {
+	case BareSchema:
+		payload = rr.data
+	default:
+		flashes, err := doer.flashes(w, r, rr)
+		if err != nil {
+			doer.Err(w, r, err)
+			return err
+		}
+
+		env := xmlSchema{D: rr.data}
+		if len(flashes) > 0 {
+			env.F = &xmlFlashes{F: flashes}
+		}
+
+		if rr.code >= http.StatusOK && rr.code <= http.StatusNoContent {
+			env.U = rr.user
+		}
+
+		payload = env
+	}
+
+	b := doer.pool.Get().(*bytes.Buffer)
+	b.Reset()
+	defer doer.pool.Put(b)
+
+	b.WriteString(xml.Header)
+	if err := xml.NewEncoder(b).Encode(payload); err != nil {
+		doer.Err(w, r, err)
+		return err
+	}
+
+	w.Header().Set("Content-Type", "application/xml; charset=UTF-8")
 	w.WriteHeader(rr.code)
 	if _, err := b.WriteTo(w); err != nil {
 		return err
@@ -283,7 +572,9 @@ func (doer *Responder) Raw(w http.ResponseWriter, r *http.Request, opts ...Fn) e
 // Redirect calls http.Redirect, given Url() set the redirect destination.
 // If Url() is not passed in opts, then ToRoot() sets the redirect destination.
 //
-// The default response status code is 302.
+// The default response status code is 302 for GET and HEAD requests
+// and 303 for others, e.g., a POST submitting a form,
+// so the client follows the redirect with a GET; cf. SeeOther.
 //
 // If Code() set the status code to something other than standard redirect 3xx statuses,
 // Redirect overwrites the status code with an appropriate 3xx status code.
@@ -311,6 +602,8 @@ func (doer *Responder) Redirect(w http.ResponseWriter, r *http.Request, opts ...
 		rr.code = http.StatusSeeOther
 	case rr.code >= http.StatusInternalServerError:
 		rr.code = http.StatusTemporaryRedirect
+	case r.Method != http.MethodGet && r.Method != http.MethodHead:
+		rr.code = http.StatusSeeOther
 	default:
 		rr.code = http.StatusFound
 	}
@@ -324,6 +617,37 @@ func (doer *Responder) Redirect(w http.ResponseWriter, r *http.Request, opts ...
 	return nil
 }
 
+// flashes retrieves the flashes pending in the session, if there is one,
+// setting them again if KeepFlash was used so the next response has them too.
+//
+// flashes retrieves them once per Response, so Vue and the template rendering the page share them.
+func (doer *Responder) flashes(w http.ResponseWriter, r *http.Request, rr *Response) ([]session.Flash, error) {
+	s, err := doer.Session(r.Context())
+	if errors.Is(err, ErrNotFound) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("can't retrieve session: %w", err)
+	}
+
+	if !rr.flashed {
+		rr.flashes = s.Flashes(w, r)
+		rr.flashed = true
+	}
+
+	if rr.keepFlashes && !rr.kept {
+		for _, f := range rr.flashes {
+			if err := s.SetFlash(w, r, f); err != nil {
+				return nil, fmt.Errorf("can't keep flash: %w", err)
+			}
+		}
+		rr.kept = true
+	}
+
+	return rr.flashes, nil
+}
+
 // Session retrieves the session set in the context as a session.Session.
 //
 // If WithSessionKey was not called setting up the Responder or the context.Context has no
@@ -342,6 +666,28 @@ func (doer Responder) Session(ctx context.Context) (session.Session, error) {
 	return s, nil
 }
 
+// FromContext retrieves the *Responder middleware.InjectResponder stashes in ctx,
+// so handlers written as standalone functions need not close over one:
+//
+//	func showUser(w http.ResponseWriter, r *http.Request) {
+//		d, err := resp.FromContext(r.Context())
+//		...
+//		d.Html(w, r, resp.Tmpls("user.tmpl"))
+//	}
+func FromContext(ctx context.Context) (*Responder, error) {
+	val := ctx.Value(trails.ResponderKey)
+	if val == nil {
+		return nil, fmt.Errorf("%w: no responder found with %q", ErrNotFound, trails.ResponderKey)
+	}
+
+	d, ok := val.(*Responder)
+	if !ok || d == nil {
+		return nil, fmt.Errorf("%w: is not *resp.Responder, is %T", ErrInvalid, val)
+	}
+
+	return d, nil
+}
+
 // do applies all options to the passed in http.ResponseWriter and *http.Request.
 //
 // do closes the *http.Request.Body, which no calling code can read from again.
@@ -359,6 +705,15 @@ func (doer *Responder) do(w http.ResponseWriter, r *http.Request, opts ...Fn) (*
 		r:         r,
 	}
 
+	version := doer.apiVersion
+	if v, ok := trails.Value[string](r.Context(), trails.APIVersionKey); ok && v != "" {
+		version = v
+	}
+
+	if version != "" && w != nil {
+		w.Header().Set("API-Version", version)
+	}
+
 	var err error
 	var redos []Fn
 	for _, opt := range opts {
@@ -405,7 +760,7 @@ func (doer *Responder) do(w http.ResponseWriter, r *http.Request, opts ...Fn) (*
 // and reports errors.
 func (doer *Responder) handleHtmlError(w http.ResponseWriter, r *http.Request, err error) error {
 	// NOTE(dlk): add errors that can be filtered out here.
-	if err.Error() == "request ctx done" {
+	if errors.Is(err, ErrDone) {
 		return nil
 	}
 
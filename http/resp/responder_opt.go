@@ -22,6 +22,17 @@ func WithAdditionalScriptsTemplate(fp string) func(*Responder) {
 	}
 }
 
+// WithAPIVersion sets the version of the API responses are from, e.g., "2024-06-01" or "v2",
+// in the "API-Version" header of every response,
+// e.g., for a Responder serving a versioned API subrouter.
+// The version a request is for, stashed under trails.APIVersionKey, takes precedence;
+// cf. router.DefaultRouter.Versioned.
+func WithAPIVersion(v string) func(*Responder) {
+	return func(d *Responder) {
+		d.apiVersion = v
+	}
+}
+
 // WithAuthTemplate sets the template identified by the filepath to use for rendering
 // when a user is authenticated.
 //
@@ -41,6 +52,14 @@ func WithContactErrMsg(msg string) func(*Responder) {
 	}
 }
 
+// WithErrorEnrichers sets the ErrorEnrichers filling in errors Err, GenericErr, and Responder.Panic log,
+// e.g., so error tracking software groups them; cf. [logger.EnrichError].
+func WithErrorEnrichers(enrichers ...logger.ErrorEnricher) func(*Responder) {
+	return func(d *Responder) {
+		d.enrichers = append(d.enrichers, enrichers...)
+	}
+}
+
 // WithErrTemplate sets the template identified by the filepath to use for rendering
 // when an unexpected, unhandled error occurs while
 func WithErrTemplate(fp string) func(*Responder) {
@@ -49,6 +68,15 @@ func WithErrTemplate(fp string) func(*Responder) {
 	}
 }
 
+// WithJsonSchema sets the JsonSchema Json structures payloads with, unless Schema overrides it.
+//
+// If no JsonSchema is provided through this option, EnvelopeSchema is used.
+func WithJsonSchema(s JsonSchema) func(*Responder) {
+	return func(d *Responder) {
+		d.schema = s
+	}
+}
+
 // WithLogger sets the provided implementation of Logger in order to log all statements through it.
 //
 // If no Logger is provided through this option, a defaultLogger will be configured.
@@ -90,6 +118,19 @@ func WithUnauthTemplate(fp string) func(*Responder) {
 	}
 }
 
+// WithVueProps registers the PropsSchema describing the props the Vue entry point expects,
+// so Vue logs an error listing where the props passed to it are missing keys or have the wrong types.
+//
+// Validating props takes encoding them to JSON an extra time, so WithVueProps ought only be used in development.
+func WithVueProps(entry string, s *PropsSchema) func(*Responder) {
+	return func(d *Responder) {
+		if d.vueProps == nil {
+			d.vueProps = make(map[string]*PropsSchema)
+		}
+		d.vueProps[entry] = s
+	}
+}
+
 // WithVueTemplate sets the template identified by the filepath to use for rendering
 // a Vue client application.
 //
@@ -4,6 +4,8 @@ import (
 	"bytes"
 	"fmt"
 	"log/slog"
+	"net/http"
+	"net/http/httptest"
 	"net/url"
 	"testing"
 
@@ -13,6 +15,20 @@ import (
 	"github.com/xy-planning-network/trails/logger"
 )
 
+func TestResponderWithAPIVersion(t *testing.T) {
+	// Arrange
+	d := NewResponder(WithAPIVersion("v2"))
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "https://example.com/api", nil)
+
+	// Act
+	err := d.Json(w, r)
+
+	// Assert
+	require.Nil(t, err)
+	require.Equal(t, "v2", w.Header().Get("API-Version"))
+}
+
 func TestResponderWithAuthTemplate(t *testing.T) {
 	expected := "test.tmpl"
 	d := NewResponder(WithAuthTemplate(expected))
@@ -31,6 +47,11 @@ func TestResponderWithErrTemplate(t *testing.T) {
 	require.Equal(t, expected, d.templates.err)
 }
 
+func TestResponderWithJsonSchema(t *testing.T) {
+	d := NewResponder(WithJsonSchema(BareSchema))
+	require.Equal(t, BareSchema, d.schema)
+}
+
 func TestResponderWithLogger(t *testing.T) {
 	// Arrange
 	b := new(bytes.Buffer)
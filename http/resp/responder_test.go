@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"encoding/xml"
 	"errors"
 	"fmt"
 	"io"
@@ -118,6 +119,63 @@ func TestResponderErr(t *testing.T) {
 	}
 }
 
+func TestResponderPanic(t *testing.T) {
+	t.Run("Html", func(t *testing.T) {
+		// Arrange
+		r := httptest.NewRequest(http.MethodGet, "http://example.com", nil)
+		w := httptest.NewRecorder()
+		l := newLogger()
+		d := resp.NewResponder(
+			resp.WithLogger(l),
+			resp.WithParser(tt.NewParser(tt.NewMockFile("err.tmpl", []byte("you errored! {{ .Error }}")))),
+			resp.WithErrTemplate("err.tmpl"),
+		)
+
+		// Act
+		d.Panic(w, r, "secret detail", []byte("goroutine 1"))
+
+		// Assert
+		require.Equal(t, http.StatusInternalServerError, w.Code)
+		require.Equal(t, "you errored! Internal Server Error", w.Body.String())
+		require.Equal(t, resp.ErrPanic.Error()+": secret detail", l.b.String())
+	})
+
+	t.Run("Json", func(t *testing.T) {
+		// Arrange
+		r := httptest.NewRequest(http.MethodGet, "http://example.com", nil)
+		r.Header.Set("Accept", "application/json")
+		r = r.WithContext(context.WithValue(r.Context(), trails.RequestIDKey, "abc"))
+		w := httptest.NewRecorder()
+		d := resp.NewResponder(resp.WithContactErrMsg("contact us"))
+
+		// Act
+		d.Panic(w, r, errors.New("secret detail"), nil)
+
+		// Assert
+		require.Equal(t, http.StatusInternalServerError, w.Code)
+		require.Equal(t, "application/problem+json", w.Header().Get("Content-Type"))
+		require.JSONEq(
+			t,
+			`{"detail":"contact us","requestId":"abc","status":500,"title":"Internal Server Error","type":"about:blank"}`,
+			w.Body.String(),
+		)
+	})
+
+	t.Run("No-Err-Template", func(t *testing.T) {
+		// Arrange
+		r := httptest.NewRequest(http.MethodGet, "http://example.com", nil)
+		w := httptest.NewRecorder()
+		d := resp.NewResponder()
+
+		// Act
+		d.Panic(w, r, "secret detail", nil)
+
+		// Assert
+		require.Equal(t, http.StatusInternalServerError, w.Code)
+		require.NotContains(t, w.Body.String(), "secret detail")
+	})
+}
+
 func TestResponderJson(t *testing.T) {
 	tcs := []struct {
 		name   string
@@ -209,6 +267,190 @@ func TestResponderJson(t *testing.T) {
 	}
 }
 
+func TestResponderJsonSchema(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		opts      []resp.ResponderOptFn
+		fns       []resp.Fn
+		mediaType string
+		expected  string
+	}{
+		{
+			name:      "Bare",
+			opts:      []resp.ResponderOptFn{resp.WithJsonSchema(resp.BareSchema)},
+			fns:       []resp.Fn{resp.CurrentUser(1), resp.Data([]int{1, 2, 3})},
+			mediaType: jsonMediaType,
+			expected:  `[1,2,3]`,
+		},
+		{
+			name:      "Bare-Overridden",
+			opts:      []resp.ResponderOptFn{resp.WithJsonSchema(resp.BareSchema)},
+			fns:       []resp.Fn{resp.Data([]int{1}), resp.Schema(resp.EnvelopeSchema)},
+			mediaType: jsonMediaType,
+			expected:  `{"data":[1]}`,
+		},
+		{
+			name:      "JsonApi",
+			fns:       []resp.Fn{resp.Schema(resp.JsonApiSchema), resp.CurrentUser(1), resp.Data(map[string]any{"id": "1", "type": "go"})},
+			mediaType: "application/vnd.api+json",
+			expected:  `{"data":{"id":"1","type":"go"},"jsonapi":{"version":"1.1"},"meta":{"currentUser":1}}`,
+		},
+		{
+			name:      "JsonApi-Error",
+			fns:       []resp.Fn{resp.Schema(resp.JsonApiSchema), resp.Code(http.StatusUnprocessableEntity), resp.CurrentUser(1), resp.Data(map[string]any{"name": "required"})},
+			mediaType: "application/vnd.api+json",
+			expected:  `{"errors":[{"meta":{"name":"required"},"status":"422","title":"Unprocessable Entity"}],"jsonapi":{"version":"1.1"}}`,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			r := httptest.NewRequest(http.MethodGet, "http://example.com", nil)
+			w := httptest.NewRecorder()
+			d := resp.NewResponder(tc.opts...)
+
+			// Act
+			err := d.Json(w, r, tc.fns...)
+
+			// Assert
+			require.Nil(t, err)
+			require.Equal(t, tc.mediaType, w.Header().Get("Content-Type"))
+			require.JSONEq(t, tc.expected, w.Body.String())
+		})
+	}
+}
+
+func TestResponderJsonFlashes(t *testing.T) {
+	// Arrange
+	d := resp.NewResponder()
+	r := httptest.NewRequest(http.MethodGet, "http://example.com", nil)
+	s, err := session.NewStub(false).GetSession(r)
+	require.Nil(t, err)
+	r = r.WithContext(context.WithValue(r.Context(), trails.SessionKey, s))
+
+	flash := session.Flash{
+		Type:        session.FlashWarning,
+		Msg:         "Your trial ends soon.",
+		Title:       "Heads up",
+		ActionURL:   "/billing",
+		ActionLabel: "Upgrade",
+	}
+
+	w := httptest.NewRecorder()
+
+	// Act
+	err = d.Json(w, r, resp.Flash(flash), resp.KeepFlash())
+
+	// Assert
+	require.Nil(t, err)
+	require.JSONEq(t, `{"flashes":[{"type":"warning","message":"Your trial ends soon.","title":"Heads up","actionUrl":"/billing","actionLabel":"Upgrade"}]}`, w.Body.String())
+
+	// Arrange
+	w = httptest.NewRecorder()
+
+	// Act
+	err = d.Json(w, r, resp.Schema(resp.JsonApiSchema))
+
+	// Assert
+	require.Nil(t, err)
+	require.JSONEq(t, `{"jsonapi":{"version":"1.1"},"meta":{"flashes":[{"type":"warning","message":"Your trial ends soon.","title":"Heads up","actionUrl":"/billing","actionLabel":"Upgrade"}]}}`, w.Body.String())
+
+	// Arrange
+	w = httptest.NewRecorder()
+
+	// Act
+	err = d.Json(w, r)
+
+	// Assert
+	require.Nil(t, err)
+	require.Equal(t, "{}\n", w.Body.String())
+}
+
+func TestResponderXml(t *testing.T) {
+	type item struct {
+		XMLName struct{} `xml:"item"`
+		ID      int      `xml:"id,attr"`
+		Name    string   `xml:"name"`
+	}
+
+	for _, tc := range []struct {
+		name     string
+		fns      []resp.Fn
+		code     int
+		expected string
+	}{
+		{
+			name:     "Zero-Value",
+			code:     http.StatusOK,
+			expected: `<response></response>`,
+		},
+		{
+			name:     "With-Data-User",
+			fns:      []resp.Fn{resp.CurrentUser(1), resp.Data(item{ID: 1, Name: "Gopher"})},
+			code:     http.StatusOK,
+			expected: `<response><item id="1"><name>Gopher</name></item><currentUser>1</currentUser></response>`,
+		},
+		{
+			name:     "Error-Elides-User",
+			fns:      []resp.Fn{resp.Code(http.StatusUnprocessableEntity), resp.CurrentUser(1), resp.Data("required")},
+			code:     http.StatusUnprocessableEntity,
+			expected: `<response><data>required</data></response>`,
+		},
+		{
+			name:     "Bare",
+			fns:      []resp.Fn{resp.Schema(resp.BareSchema), resp.CurrentUser(1), resp.Data(item{ID: 1, Name: "Gopher"})},
+			code:     http.StatusOK,
+			expected: `<item id="1"><name>Gopher</name></item>`,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			r := httptest.NewRequest(http.MethodGet, "http://example.com", nil)
+			w := httptest.NewRecorder()
+			d := resp.NewResponder()
+
+			// Act
+			err := d.Xml(w, r, tc.fns...)
+
+			// Assert
+			require.Nil(t, err)
+			require.Equal(t, tc.code, w.Code)
+			require.Equal(t, "application/xml; charset=UTF-8", w.Header().Get("Content-Type"))
+			require.Equal(t, xml.Header+tc.expected, w.Body.String())
+		})
+	}
+
+	t.Run("Flashes", func(t *testing.T) {
+		// Arrange
+		d := resp.NewResponder()
+		r := httptest.NewRequest(http.MethodGet, "http://example.com", nil)
+		s, err := session.NewStub(false).GetSession(r)
+		require.Nil(t, err)
+		r = r.WithContext(context.WithValue(r.Context(), trails.SessionKey, s))
+		w := httptest.NewRecorder()
+
+		// Act
+		err = d.Xml(w, r, resp.Flash(session.Flash{Type: session.FlashInfo, Msg: "Saved."}))
+
+		// Assert
+		require.Nil(t, err)
+		require.Equal(t, xml.Header+`<response><flashes><flash><type>info</type><message>Saved.</message></flash></flashes></response>`, w.Body.String())
+	})
+
+	t.Run("Not-Encodable", func(t *testing.T) {
+		// Arrange
+		r := httptest.NewRequest(http.MethodGet, "http://example.com", nil)
+		w := httptest.NewRecorder()
+		d := resp.NewResponder()
+
+		// Act
+		err := d.Xml(w, r, resp.Data(map[string]any{"go": "rocks"}))
+
+		// Assert
+		require.NotNil(t, err)
+		require.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+}
+
 func TestResponderRaw(t *testing.T) {
 	// TODO?
 }
@@ -342,6 +584,34 @@ func TestResponderRedirect(t *testing.T) {
 	}
 }
 
+func TestResponderRedirectMethod(t *testing.T) {
+	for _, tc := range []struct {
+		method   string
+		fns      []resp.Fn
+		expected int
+	}{
+		{http.MethodGet, nil, http.StatusFound},
+		{http.MethodHead, nil, http.StatusFound},
+		{http.MethodPost, nil, http.StatusSeeOther},
+		{http.MethodDelete, nil, http.StatusSeeOther},
+		{http.MethodPost, []resp.Fn{resp.Code(http.StatusTemporaryRedirect)}, http.StatusTemporaryRedirect},
+	} {
+		t.Run(tc.method, func(t *testing.T) {
+			// Arrange
+			r := httptest.NewRequest(tc.method, "http://example.com", nil)
+			w := httptest.NewRecorder()
+			d := resp.NewResponder(resp.WithRootUrl("http://example.com"))
+
+			// Act
+			err := d.Redirect(w, r, tc.fns...)
+
+			// Assert
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, w.Code)
+		})
+	}
+}
+
 func TestResponderHtml(t *testing.T) {
 	b := new(bytes.Buffer)
 	testlog := logger.New(slog.New(slog.NewTextHandler(b, nil)), trails.Testing)
@@ -497,6 +767,29 @@ func TestResponderHtml(t *testing.T) {
 
 		require.Equal(t, string([]byte("you errored!")), string(b))
 	})
+
+	t.Run("With-CSRF", func(t *testing.T) {
+		// Arrange
+		r := httptest.NewRequest(http.MethodGet, "http://example.com", nil)
+		s, err := session.NewStub(false).GetSession(r)
+		require.Nil(t, err)
+
+		ctx := context.WithValue(r.Context(), trails.SessionKey, s)
+		ctx = context.WithValue(ctx, trails.CSRFTokenKey, "token")
+		r = r.WithContext(ctx)
+		w := httptest.NewRecorder()
+
+		responder := resp.NewResponder(resp.WithParser(tt.NewParser(
+			tt.NewMockFile("test.tmpl", []byte(`{{ csrfToken }}|{{ csrfField }}`)),
+		)))
+
+		// Act
+		err = responder.Html(w, r, resp.Tmpls("test.tmpl"))
+
+		// Assert
+		require.Nil(t, err)
+		require.Equal(t, `token|<input type="hidden" name="csrf_token" value="token">`, w.Body.String())
+	})
 }
 
 func TestResponderSession(t *testing.T) {
@@ -544,6 +837,29 @@ func TestResponderSession(t *testing.T) {
 	}
 }
 
+func TestFromContext(t *testing.T) {
+	d := resp.NewResponder()
+	tcs := []struct {
+		name        string
+		ctx         context.Context
+		expectedVal *resp.Responder
+		expectedErr error
+	}{
+		{"Not-Set", context.Background(), nil, resp.ErrNotFound},
+		{"Set-With-Wrong-Type", context.WithValue(context.Background(), trails.ResponderKey, *d), nil, resp.ErrInvalid},
+		{"Set-With-Nil-Responder", context.WithValue(context.Background(), trails.ResponderKey, (*resp.Responder)(nil)), nil, resp.ErrInvalid},
+		{"Set-With-Responder", context.WithValue(context.Background(), trails.ResponderKey, d), d, nil},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			actual, err := resp.FromContext(tc.ctx)
+			require.ErrorIs(t, err, tc.expectedErr)
+			require.Same(t, tc.expectedVal, actual)
+		})
+	}
+}
+
 func BenchmarkResponderRedirect(b *testing.B) {
 	bcs := []struct {
 		name string
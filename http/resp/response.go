@@ -5,10 +5,12 @@ import (
 	"fmt"
 	"net/http"
 	"net/url"
+	"time"
 
 	"github.com/xy-planning-network/trails"
 	"github.com/xy-planning-network/trails/http/session"
 	"github.com/xy-planning-network/trails/logger"
+	"golang.org/x/text/language"
 )
 
 const responseFnFrames = 4
@@ -19,14 +21,19 @@ type Fn func(Responder, *Response) error
 // A Response is the internal object a Responder response method builds while applying all
 // functional options.
 type Response struct {
-	w         http.ResponseWriter
-	r         *http.Request
-	closeBody bool
-	code      int
-	data      any
-	tmpls     []string
-	url       *url.URL
-	user      any
+	w           http.ResponseWriter
+	r           *http.Request
+	closeBody   bool
+	code        int
+	keepFlashes bool
+	data        any
+	flashes     []session.Flash
+	flashed     bool
+	kept        bool
+	tmpls       []string
+	schema      JsonSchema
+	url         *url.URL
+	user        any
 
 	// frames counts the number of stack trace frames to skip back when logging
 	// so that the callsite of a Responder method is the frame to capture.
@@ -71,7 +78,7 @@ func Code(c int) Fn {
 
 // CurrentUser stores the user in the *Response.
 //
-// Used with Responder.Html and Responder.Json.
+// Used with Responder.Html, Responder.Json, and Responder.Xml.
 // When used with Json, the user is assigned to the "currentUser" key.
 func CurrentUser(u any) Fn {
 	return func(d Responder, r *Response) error {
@@ -82,7 +89,7 @@ func CurrentUser(u any) Fn {
 
 // Data stores the provided value for writing to the client.
 //
-// Used with Responder.Html and Responder.Json.
+// Used with Responder.Html, Responder.Json, and Responder.Xml.
 func Data(d any) Fn {
 	return func(_ Responder, r *Response) error {
 		r.data = d
@@ -90,18 +97,62 @@ func Data(d any) Fn {
 	}
 }
 
-// Err sets the status code http.StatusInternalServerError and logs the error.
+// Deprecated marks the response as from a deprecated endpoint and logs a warning,
+// so traffic still reaching it can be found.
+//
+// Deprecated sets the "Deprecation" header and, if not zero or empty,
+// the "Sunset" header to when the endpoint stops responding (cf. RFC 8594)
+// and a "Link" header to link, documenting the deprecation, e.g., a migration guide.
+func Deprecated(sunset time.Time, link string) Fn {
+	return func(d Responder, r *Response) error {
+		data := map[string]any{"deprecated": true}
+		if r.w != nil {
+			h := r.w.Header()
+			h.Set("Deprecation", "true")
+			if !sunset.IsZero() {
+				h.Set("Sunset", sunset.UTC().Format(http.TimeFormat))
+			}
+
+			if link != "" {
+				h.Add("Link", "<"+link+">; rel=\"deprecation\"")
+			}
+		}
+
+		if !sunset.IsZero() {
+			data["sunset"] = sunset.UTC().Format(time.RFC3339)
+		}
+
+		u, _ := r.user.(logger.LogUser)
+		l := d.logger.AddSkip(responseFnFrames + r.frames)
+		l.Warn("deprecated endpoint requested", newLogContext(r.r, nil, data, u))
+
+		return nil
+	}
+}
+
+// Err sets the status code trails.StatusFor maps e to, e.g., http.StatusInternalServerError,
+// and logs the error, filled in by the ErrorEnrichers set with WithErrorEnrichers.
+//
+// If e is nil, Err sets http.StatusInternalServerError.
 func Err(e error) Fn {
 	return func(d Responder, r *Response) error {
 		if e != nil {
 			populateUser(d, r) // NOTE(dlk): ignore err since a user is not required
 
 			u, _ := r.user.(logger.LogUser)
+			lc := newLogContext(r.r, e, r.data, u)
+			logger.EnrichError(lc, d.enrichers...)
+
 			l := d.logger.AddSkip(responseFnFrames + r.frames)
-			l.Error(e.Error(), newLogContext(r.r, e, r.data, u))
+			l.Error(e.Error(), lc)
 		}
 
-		if err := Code(http.StatusInternalServerError)(d, r); err != nil {
+		code := http.StatusInternalServerError
+		if e != nil {
+			code = trails.StatusFor(e)
+		}
+
+		if err := Code(code)(d, r); err != nil {
 			return err
 		}
 
@@ -122,6 +173,18 @@ func Flash(flash session.Flash) Fn {
 	}
 }
 
+// KeepFlash keeps the flashes pending in the session there after rendering them,
+// so they survive an intermediate response, e.g., an interstitial page or an API call
+// made while a redirect chain is under way, and appear on the next one too.
+//
+// Used with Responder.Html, Responder.Json, and Responder.Xml.
+func KeepFlash() Fn {
+	return func(_ Responder, r *Response) error {
+		r.keepFlashes = true
+		return nil
+	}
+}
+
 // GenericErr combines Err() and Flash() to log the passed in error
 // and set a generic error flash in the session
 // using either the string set by WithContactErrMsg or session.DefaultErrMsg.
@@ -163,6 +226,17 @@ func Params(pairs map[string]string) Fn {
 	}
 }
 
+// Schema sets the JsonSchema Json structures the payload with,
+// overriding the one set by WithJsonSchema.
+//
+// Used with Responder.Json and Responder.Xml.
+func Schema(s JsonSchema) Fn {
+	return func(_ Responder, r *Response) error {
+		r.schema = s
+		return nil
+	}
+}
+
 // Success sets the status OK to http.StatusOK
 // and sets a session.FlashSuccess flash in the session with the passed in msg.
 //
@@ -181,6 +255,30 @@ func Success(msg string) Fn {
 	}
 }
 
+// SeeOther composes Code(303), Url(u), and, unless flash is the zero-value, Flash(flash),
+// for redirecting after handling a form per the Post/Redirect/Get pattern:
+//
+//	d.Redirect(w, r, resp.SeeOther("/accounts", session.Flash{Type: session.FlashSuccess, Msg: "Saved!"}))
+//
+// Used with Responder.Redirect.
+func SeeOther(u string, flash session.Flash) Fn {
+	return func(d Responder, r *Response) error {
+		if err := Code(http.StatusSeeOther)(d, r); err != nil {
+			return err
+		}
+
+		if err := Url(u)(d, r); err != nil {
+			return err
+		}
+
+		if flash == (session.Flash{}) {
+			return nil
+		}
+
+		return Flash(flash)(d, r)
+	}
+}
+
 // Tmpls appends to the templates to be rendered.
 //
 // Used with Responder.Html.
@@ -196,6 +294,9 @@ func Tmpls(fps ...string) Fn {
 // Toolbox only supports including the provided toolbox
 // in the data if it is map[string]any.
 //
+// Toolbox includes only the trails.Tools and trails.ToolActions the current user is permitted to use,
+// if there is one; cf. trails.Toolbox.FilterFor.
+//
 // Multiple calls to Toolbox results in merging the trails.Tools together.
 func Toolbox(toolbox trails.Toolbox) Fn {
 	toolbox = toolbox.Filter()
@@ -208,6 +309,16 @@ func Toolbox(toolbox trails.Toolbox) Fn {
 			return fmt.Errorf("%w: cannot set Toolbox() before Data()", trails.ErrMissingData)
 		}
 
+		user := r.user
+		if user == nil && r.r != nil {
+			user, _ = d.CurrentUser(r.r.Context())
+		}
+
+		permitted := toolbox.FilterFor(user)
+		if len(permitted) == 0 {
+			return nil
+		}
+
 		data, ok := r.data.(map[string]any)
 		if !ok {
 			return nil
@@ -223,7 +334,7 @@ func Toolbox(toolbox trails.Toolbox) Fn {
 			prev = make(trails.Toolbox, 0)
 		}
 
-		prev = append(prev, toolbox...)
+		prev = append(prev, permitted...)
 		props["toolbox"] = prev
 		data["props"] = props
 
@@ -304,7 +415,10 @@ func Url(u string) Fn {
 //		"props": {
 //			"initialProps": {
 //				"baseURL": d.rootUrl,
+//				"csrfToken": token set by middleware.CSRF,
 //				"currentUser": r.user,
+//				"impersonator": user set by middleware.Impersonation,
+//				"locale": locale set by middleware.Locale,
 //			},
 //			...key-value pairs set by Data
 //			...key-value pairs set using trails.AppPropsKey
@@ -374,6 +488,12 @@ func Url(u string) Fn {
 //
 // It is not required to set any keys for pulling additional values
 // out of the *http.Request.Context.
+//
+// The flashes pending in the session are included in "initialProps" under "flashes", when there are any,
+// and remain available to the templates rendering the page.
+//
+// When WithVueProps registers a PropsSchema for entry, the "props" are validated against it,
+// logging an error listing each mismatch without failing the response.
 func Vue(entry string) Fn {
 	return func(d Responder, r *Response) error {
 		if d.templates.vue == "" || entry == "" {
@@ -392,6 +512,27 @@ func Vue(entry string) Fn {
 			init["baseURL"] = d.rootUrl.String()
 		}
 
+		if token, ok := trails.Value[string](r.r.Context(), trails.CSRFTokenKey); ok {
+			init["csrfToken"] = token
+		}
+
+		if impersonator := r.r.Context().Value(trails.ImpersonatorKey); impersonator != nil {
+			init["impersonator"] = impersonator
+		}
+
+		if tag, ok := trails.Value[language.Tag](r.r.Context(), trails.LocaleKey); ok {
+			init["locale"] = tag.String()
+		}
+
+		flashes, err := d.flashes(r.w, r.r, r)
+		if err != nil {
+			return err
+		}
+
+		if len(flashes) > 0 {
+			init["flashes"] = flashes
+		}
+
 		props := map[string]any{"initialProps": init}
 		if val := trails.AppPropsFromContext(r.r.Context()); len(val) > 0 {
 			props["appProps"] = val
@@ -427,8 +568,15 @@ func Vue(entry string) Fn {
 
 		data["props"] = props
 
-		err := Data(data)(d, r)
-		if err != nil {
+		if schema, ok := d.vueProps[entry]; ok && schema != nil {
+			if err := schema.Validate(props); err != nil {
+				u, _ := r.user.(logger.LogUser)
+				l := d.logger.AddSkip(responseFnFrames + r.frames)
+				l.Error(fmt.Sprintf("props passed to Vue entry %q do not match its schema", entry), newLogContext(r.r, err, map[string]any{"entry": entry}, u))
+			}
+		}
+
+		if err := Data(data)(d, r); err != nil {
 			return err
 		}
 
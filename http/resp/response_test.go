@@ -4,11 +4,13 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"log/slog"
 	"math"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 	"github.com/xy-planning-network/trails"
@@ -206,6 +208,25 @@ func TestErr(t *testing.T) {
 
 }
 
+func TestErrEnrichers(t *testing.T) {
+	// Arrange
+	b := new(bytes.Buffer)
+	l := logger.New(slog.New(slog.NewJSONHandler(b, nil)), trails.Testing)
+	enricher := logger.ErrorEnricherFunc(func(ctx *logger.LogContext) {
+		ctx.Tags = map[string]string{"handler": "test"}
+	})
+	d := NewResponder(WithLogger(l), WithErrorEnrichers(logger.CauseFingerprint, enricher))
+	r := &Response{r: httptest.NewRequest(http.MethodGet, "http://example.com", nil)}
+
+	// Act
+	err := Err(fmt.Errorf("%w: user 1", ErrNotFound))(*d, r)
+
+	// Assert
+	require.Nil(t, err)
+	require.Contains(t, b.String(), `"fingerprint":["*errors.errorString","not found"]`)
+	require.Contains(t, b.String(), `"tags":{"handler":"test"}`)
+}
+
 func TestFlash(t *testing.T) {
 	tcs := []struct {
 		name       string
@@ -254,6 +275,50 @@ func TestFlash(t *testing.T) {
 	}
 }
 
+func TestSeeOther(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		url   string
+		flash session.Flash
+		err   error
+	}{
+		{"Flash", "/accounts", session.Flash{Type: session.FlashSuccess, Msg: "Saved!"}, nil},
+		{"Title-Only", "/accounts", session.Flash{Type: session.FlashInfo, Title: "Saved"}, nil},
+		{"No-Flash", "/accounts", session.Flash{}, nil},
+		{"Bad-Url", "::", session.Flash{}, ErrInvalid},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "http://example.com", nil)
+
+			s, err := session.NewStub(false).GetSession(req)
+			require.NoError(t, err)
+			req = req.WithContext(context.WithValue(req.Context(), trails.SessionKey, s))
+
+			r := &Response{r: req, w: w}
+
+			// Act
+			err = SeeOther(tc.url, tc.flash)(*NewResponder(), r)
+
+			// Assert
+			if tc.err != nil {
+				require.ErrorIs(t, err, tc.err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, http.StatusSeeOther, r.code)
+			require.Equal(t, tc.url, r.url.String())
+			if tc.flash == (session.Flash{}) {
+				require.Empty(t, s.Flashes(w, req))
+			} else {
+				require.Equal(t, []session.Flash{tc.flash}, s.Flashes(w, req))
+			}
+		})
+	}
+}
+
 func TestGenericErr(t *testing.T) {
 	tcs := []struct {
 		name   string
@@ -555,11 +620,44 @@ func TestToolbox(t *testing.T) {
 				require.Equal(t, "new", actual[1].Actions[0].Name)
 			},
 		},
+		{
+			"Not-Permitted",
+			make(map[string]any),
+			trails.Toolbox{trails.NewTool("Accounts").Action("Suspend", "/suspend", http.MethodPost).If(func(any) bool { return false })},
+			func(t *testing.T, output any, err error) {
+				require.Nil(t, err)
+				require.Equal(t, make(map[string]any), output)
+			},
+		},
+		{
+			"Permitted",
+			make(map[string]any),
+			trails.Toolbox{
+				trails.NewTool("Accounts").
+					Action("Suspend", "/suspend", http.MethodPost).If(trails.Allow(func(u string) bool { return u == "admin" })).
+					Action("Export", "/export", http.MethodGet).If(trails.Allow(func(u string) bool { return u == "owner" })),
+			},
+			func(t *testing.T, output any, err error) {
+				require.Nil(t, err)
+
+				data, ok := output.(map[string]any)
+				require.True(t, ok)
+
+				props, ok := data["props"].(map[string]any)
+				require.True(t, ok)
+
+				actual, ok := props["toolbox"].(trails.Toolbox)
+				require.True(t, ok)
+				require.Len(t, actual, 1)
+				require.Len(t, actual[0].Actions, 1)
+				require.Equal(t, "Suspend", actual[0].Actions[0].Name)
+			},
+		},
 	} {
 		t.Run(tc.name, func(t *testing.T) {
 			// Arrange
 			d := Responder{}
-			r := &Response{data: tc.dataInput}
+			r := &Response{data: tc.dataInput, user: "admin"}
 
 			// Act
 			err := Toolbox(tc.input)(d, r)
@@ -884,6 +982,110 @@ func TestVue(t *testing.T) {
 	}
 }
 
+func TestVueProps(t *testing.T) {
+	schema := &PropsSchema{
+		Type:       SchemaTypes{"object"},
+		Properties: map[string]*PropsSchema{"count": {Type: SchemaTypes{"integer"}}},
+		Required:   []string{"count"},
+	}
+
+	for _, tc := range []struct {
+		name     string
+		entry    string
+		data     any
+		expected string
+	}{
+		{"Valid", "test", map[string]any{"count": 1}, ""},
+		{"Invalid", "test", map[string]any{"count": "1"}, `props passed to Vue entry "test" do not match its schema`},
+		{"Unregistered", "other", map[string]any{"count": "1"}, ""},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			l := newLogger()
+			d := NewResponder(WithLogger(l), WithVueTemplate("vue.tmpl"), WithVueProps("test", schema))
+			r := &Response{r: httptest.NewRequest(http.MethodGet, "https://example.com", nil), data: tc.data}
+
+			// Act
+			err := Vue(tc.entry)(*d, r)
+
+			// Assert
+			require.Nil(t, err)
+			require.Equal(t, tc.expected, l.String())
+		})
+	}
+}
+
+func TestVueFlashes(t *testing.T) {
+	// Arrange
+	d := Responder{templates: templatesTest{vue: "vue.tmpl"}}
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "https://example.com", nil)
+
+	s, err := session.NewStub(false).GetSession(req)
+	require.Nil(t, err)
+
+	expected := []session.Flash{
+		{Type: session.FlashInfo, Msg: "one"},
+		{Type: session.FlashError, Msg: "two", Title: "Oops", Detail: "Try again.", Dismissible: true},
+	}
+	for _, f := range expected {
+		require.Nil(t, s.SetFlash(w, req, f))
+	}
+
+	r := &Response{w: w, r: req.Clone(context.WithValue(req.Context(), trails.SessionKey, s))}
+
+	// Act
+	err = Vue("test")(d, r)
+
+	// Assert
+	require.Nil(t, err)
+
+	init := r.data.(map[string]any)["props"].(map[string]any)["initialProps"].(map[string]any)
+	require.Equal(t, expected, init["flashes"])
+	require.Empty(t, s.Flashes(w, r.r))
+
+	// Act
+	flashes, err := d.flashes(w, r.r, r)
+
+	// Assert
+	require.Nil(t, err)
+	require.Equal(t, expected, flashes)
+}
+
+func TestDeprecated(t *testing.T) {
+	// Arrange
+	d := NewResponder(WithLogger(newLogger()))
+	w := httptest.NewRecorder()
+	r := &Response{w: w, r: httptest.NewRequest(http.MethodGet, "http://example.com/api/v1", nil)}
+	sunset := time.Date(2030, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	// Act
+	err := Deprecated(sunset, "https://example.com/docs/v2")(*d, r)
+
+	// Assert
+	require.Nil(t, err)
+	require.Equal(t, "true", w.Header().Get("Deprecation"))
+	require.Equal(t, "Tue, 01 Jan 2030 00:00:00 GMT", w.Header().Get("Sunset"))
+	require.Equal(t, `<https://example.com/docs/v2>; rel="deprecation"`, w.Header().Get("Link"))
+
+	l, ok := d.logger.(testLogger)
+	require.True(t, ok)
+	require.Equal(t, "deprecated endpoint requested", l.String())
+
+	// Arrange
+	w = httptest.NewRecorder()
+	r = &Response{w: w, r: httptest.NewRequest(http.MethodGet, "http://example.com/api/v1", nil)}
+
+	// Act
+	err = Deprecated(time.Time{}, "")(*d, r)
+
+	// Assert
+	require.Nil(t, err)
+	require.Equal(t, "true", w.Header().Get("Deprecation"))
+	require.Empty(t, w.Header().Get("Sunset"))
+	require.Empty(t, w.Header().Get("Link"))
+}
+
 func TestWarn(t *testing.T) {
 	tcs := []struct {
 		name       string
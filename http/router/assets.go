@@ -0,0 +1,308 @@
+package router
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultAssetsCachePolicy is the "Cache-Control" header value responses for assets have by default.
+	DefaultAssetsCachePolicy = "max-age=2592000" // 30 days
+
+	// DefaultAssetsPrefix is the URL path assets are served under by default.
+	DefaultAssetsPrefix = "/" + assetsPath
+
+	// HashedAssetsCachePolicy is the "Cache-Control" header value responses for assets
+	// whose names contain a content hash have by default.
+	// Since their content never changes without their name changing, clients may cache them for good.
+	HashedAssetsCachePolicy = "public, max-age=31536000, immutable" // 1 year
+
+	// IndexCachePolicy is the "Cache-Control" header value responses with the SPAIndex have,
+	// so clients always revalidate it and pick up new deploys.
+	IndexCachePolicy = "no-cache"
+
+	acceptEncodingHeader  = "Accept-Encoding"
+	cacheControlHeader    = "Cache-Control"
+	contentEncodingHeader = "Content-Encoding"
+	contentTypeHeader     = "Content-Type"
+	etagHeader            = "ETag"
+	varyHeader            = "Vary"
+)
+
+// hashedName matches file names containing a content hash as bundlers like Vite write them,
+// e.g., "GetDashboard-3f9a1c2b.js" or "app.5d41402a.css".
+var hashedName = regexp.MustCompile(`^.+[-.]([A-Za-z0-9_-]{8,})\.[A-Za-z0-9]+$`)
+
+// precompressedEncodings pairs the encodings of precompressed assets with their file extension,
+// in order of preference.
+var precompressedEncodings = []struct{ encoding, ext string }{
+	{"br", ".br"},
+	{"gzip", ".gz"},
+}
+
+// AssetsConfig configures how a [Router] serves static assets, e.g., a bundle of JavaScript.
+type AssetsConfig struct {
+	// CachePolicies maps file extensions, e.g., ".js", to the "Cache-Control" header value
+	// responses for assets with that extension have.
+	// Extensions are matched case-insensitively.
+	//
+	// Assets with an extension not in CachePolicies use DefaultCachePolicy.
+	CachePolicies map[string]string
+
+	// DefaultCachePolicy is the "Cache-Control" header value responses for assets have.
+	//
+	// If DefaultCachePolicy is empty, DefaultAssetsCachePolicy is used.
+	DefaultCachePolicy string
+
+	// HashedCachePolicy is the "Cache-Control" header value responses for assets
+	// whose names contain a content hash, e.g., "GetDashboard-3f9a1c2b.js", have,
+	// unless CachePolicies sets one for their extension.
+	//
+	// If HashedCachePolicy is empty, HashedAssetsCachePolicy is used.
+	HashedCachePolicy string
+
+	// FS holds the assets, e.g., an [embed.FS].
+	//
+	// If FS is nil, the "client/dist" directory, relative to where the application runs, is used.
+	FS fs.FS
+
+	// Precompressed turns on serving a file named like the one requested plus ".br" or ".gz",
+	// when present in FS, to clients accepting that encoding.
+	Precompressed bool
+
+	// Prefix is the URL path assets are served under, e.g., "/static/".
+	//
+	// If Prefix is empty, DefaultAssetsPrefix is used.
+	Prefix string
+
+	// SPAIndex names the file in FS, e.g., "index.html", responding to requests for paths under Prefix
+	// without an extension that match no file, so a single-page app can route deep links itself.
+	// Responses with it have IndexCachePolicy.
+	//
+	// If SPAIndex is empty, such requests are not found.
+	SPAIndex string
+}
+
+// An assetServer serves static assets according to an AssetsConfig.
+//
+// An assetServer sets an "ETag" header for each asset, a hash of its content,
+// so clients revalidating a cached asset get a 304 when it has not changed.
+type assetServer struct {
+	cfg   AssetsConfig
+	files http.Handler
+	mu    sync.RWMutex
+
+	etags  map[string]assetETag
+	etagMu sync.Mutex
+}
+
+// An assetETag is the ETag of an asset, as of when it was last modified and its size.
+type assetETag struct {
+	etag    string
+	modTime time.Time
+	size    int64
+}
+
+// newAssetServer constructs an *assetServer applying defaults to cfg.
+func newAssetServer(cfg AssetsConfig) *assetServer {
+	as := new(assetServer)
+	as.configure(cfg)
+
+	return as
+}
+
+// configure replaces the AssetsConfig the assetServer serves with.
+func (as *assetServer) configure(cfg AssetsConfig) {
+	if cfg.DefaultCachePolicy == "" {
+		cfg.DefaultCachePolicy = DefaultAssetsCachePolicy
+	}
+
+	if cfg.HashedCachePolicy == "" {
+		cfg.HashedCachePolicy = HashedAssetsCachePolicy
+	}
+
+	if cfg.FS == nil {
+		cfg.FS = os.DirFS(assetsPath)
+	}
+
+	if cfg.Prefix == "" {
+		cfg.Prefix = DefaultAssetsPrefix
+	}
+
+	cfg.Prefix = "/" + strings.Trim(cfg.Prefix, "/")
+	if cfg.Prefix != "/" {
+		cfg.Prefix += "/"
+	}
+
+	policies := make(map[string]string, len(cfg.CachePolicies))
+	for ext, policy := range cfg.CachePolicies {
+		policies[strings.ToLower(ext)] = policy
+	}
+	cfg.CachePolicies = policies
+
+	as.mu.Lock()
+	defer as.mu.Unlock()
+
+	as.cfg = cfg
+	as.files = http.StripPrefix(strings.TrimSuffix(cfg.Prefix, "/"), http.FileServerFS(cfg.FS))
+
+	as.etagMu.Lock()
+	defer as.etagMu.Unlock()
+
+	as.etags = make(map[string]assetETag)
+}
+
+// match asserts whether the request is for an asset.
+func (as *assetServer) match(r *http.Request) bool {
+	as.mu.RLock()
+	defer as.mu.RUnlock()
+
+	return strings.HasPrefix(r.URL.Path, as.cfg.Prefix)
+}
+
+// ServeHTTP responds with the asset requested.
+func (as *assetServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	as.mu.RLock()
+	cfg, files := as.cfg, as.files
+	as.mu.RUnlock()
+
+	name := strings.TrimPrefix(path.Clean(r.URL.Path), "/")
+	name = strings.TrimPrefix(name, strings.Trim(cfg.Prefix, "/")+"/")
+
+	if cfg.SPAIndex != "" && path.Ext(name) == "" && fs.ValidPath(name) {
+		if info, err := fs.Stat(cfg.FS, name); err != nil || info.IsDir() {
+			w.Header().Set(cacheControlHeader, IndexCachePolicy)
+			as.setETag(w, cfg.FS, cfg.SPAIndex)
+			http.ServeFileFS(w, r, cfg.FS, cfg.SPAIndex)
+			return
+		}
+	}
+
+	policy, ok := cfg.CachePolicies[strings.ToLower(path.Ext(name))]
+	switch {
+	case ok:
+	case isHashed(name):
+		policy = cfg.HashedCachePolicy
+	default:
+		policy = cfg.DefaultCachePolicy
+	}
+	w.Header().Set(cacheControlHeader, policy)
+
+	if cfg.Precompressed && fs.ValidPath(name) {
+		w.Header().Add(varyHeader, acceptEncodingHeader)
+		if as.servePrecompressed(w, r, cfg.FS, name) {
+			return
+		}
+	}
+
+	if fs.ValidPath(name) {
+		as.setETag(w, cfg.FS, name)
+	}
+
+	files.ServeHTTP(w, r)
+}
+
+// setETag sets the "ETag" header to a hash of the named file's content,
+// which http.ServeContent compares against an "If-None-Match" header, responding with 304 on a match.
+//
+// setETag hashes each file once, until it is modified.
+func (as *assetServer) setETag(w http.ResponseWriter, fsys fs.FS, name string) {
+	info, err := fs.Stat(fsys, name)
+	if err != nil || info.IsDir() {
+		return
+	}
+
+	as.etagMu.Lock()
+	cached, ok := as.etags[name]
+	as.etagMu.Unlock()
+
+	if !ok || !cached.modTime.Equal(info.ModTime()) || cached.size != info.Size() {
+		f, err := fsys.Open(name)
+		if err != nil {
+			return
+		}
+		defer f.Close()
+
+		h := sha256.New()
+		if _, err := io.Copy(h, f); err != nil {
+			return
+		}
+
+		cached = assetETag{
+			etag:    `"` + base64.RawURLEncoding.EncodeToString(h.Sum(nil)[:12]) + `"`,
+			modTime: info.ModTime(),
+			size:    info.Size(),
+		}
+
+		as.etagMu.Lock()
+		as.etags[name] = cached
+		as.etagMu.Unlock()
+	}
+
+	w.Header().Set(etagHeader, cached.etag)
+}
+
+// isHashed asserts whether the named file's name contains a content hash.
+func isHashed(name string) bool {
+	m := hashedName.FindStringSubmatch(path.Base(name))
+	return m != nil && strings.ContainsAny(m[1], "0123456789")
+}
+
+// servePrecompressed responds with a precompressed version of the named file
+// in an encoding the client accepts, reporting whether it did.
+func (as *assetServer) servePrecompressed(w http.ResponseWriter, r *http.Request, fsys fs.FS, name string) bool {
+	accepted := r.Header.Get(acceptEncodingHeader)
+	for _, pe := range precompressedEncodings {
+		if !acceptsEncoding(accepted, pe.encoding) {
+			continue
+		}
+
+		info, err := fs.Stat(fsys, name+pe.ext)
+		if err != nil || info.IsDir() {
+			continue
+		}
+
+		// NOTE: set the type of the file requested, not the type of the compressed file.
+		if ct := mime.TypeByExtension(path.Ext(name)); ct != "" {
+			w.Header().Set(contentTypeHeader, ct)
+		}
+		w.Header().Set(contentEncodingHeader, pe.encoding)
+		as.setETag(w, fsys, name+pe.ext)
+		http.ServeFileFS(w, r, fsys, name+pe.ext)
+
+		return true
+	}
+
+	return false
+}
+
+// acceptsEncoding asserts whether the value of an "Accept-Encoding" header allows enc.
+func acceptsEncoding(accepted, enc string) bool {
+	for _, part := range strings.Split(accepted, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if !strings.EqualFold(strings.TrimSpace(name), enc) {
+			continue
+		}
+
+		q, ok := strings.CutPrefix(strings.TrimSpace(params), "q=")
+		if !ok {
+			return true
+		}
+
+		weight, err := strconv.ParseFloat(q, 64)
+		return err == nil && weight > 0
+	}
+
+	return false
+}
@@ -0,0 +1,246 @@
+package router_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/require"
+	"github.com/xy-planning-network/trails/http/middleware"
+	"github.com/xy-planning-network/trails/http/router"
+)
+
+func TestDefaultRouterAssets(t *testing.T) {
+	fsys := fstest.MapFS{
+		"app.js":     {Data: []byte("console.log('hi')")},
+		"app.js.br":  {Data: []byte("brotli")},
+		"app.js.gz":  {Data: []byte("gzip")},
+		"index.html": {Data: []byte("<html></html>")},
+		"style.css":  {Data: []byte("body {}")},
+	}
+
+	tcs := []struct {
+		name             string
+		cfg              router.AssetsConfig
+		path             string
+		acceptEncoding   string
+		expectedCode     int
+		expectedBody     string
+		expectedCache    string
+		expectedEncoding string
+	}{
+		{
+			"Default-Policy",
+			router.AssetsConfig{FS: fsys, Prefix: "/static"},
+			"/static/style.css",
+			"",
+			http.StatusOK,
+			"body {}",
+			router.DefaultAssetsCachePolicy,
+			"",
+		},
+		{
+			"Extension-Policy",
+			router.AssetsConfig{FS: fsys, Prefix: "/static/", CachePolicies: map[string]string{".JS": "no-cache"}},
+			"/static/app.js",
+			"",
+			http.StatusOK,
+			"console.log('hi')",
+			"no-cache",
+			"",
+		},
+		{
+			"Outside-Prefix",
+			router.AssetsConfig{FS: fsys, Prefix: "/static/"},
+			"/client/dist/app.js",
+			"",
+			http.StatusNotFound,
+			"",
+			"",
+			"",
+		},
+		{
+			"Missing",
+			router.AssetsConfig{FS: fsys, Prefix: "/static/"},
+			"/static/missing.js",
+			"",
+			http.StatusNotFound,
+			"",
+			"",
+			"",
+		},
+		{
+			"Precompressed-Brotli",
+			router.AssetsConfig{FS: fsys, Prefix: "/static/", Precompressed: true},
+			"/static/app.js",
+			"gzip, br",
+			http.StatusOK,
+			"brotli",
+			router.DefaultAssetsCachePolicy,
+			"br",
+		},
+		{
+			"Precompressed-Gzip",
+			router.AssetsConfig{FS: fsys, Prefix: "/static/", Precompressed: true},
+			"/static/app.js",
+			"gzip, br;q=0",
+			http.StatusOK,
+			"gzip",
+			router.DefaultAssetsCachePolicy,
+			"gzip",
+		},
+		{
+			"Precompressed-Not-Accepted",
+			router.AssetsConfig{FS: fsys, Prefix: "/static/", Precompressed: true},
+			"/static/app.js",
+			"",
+			http.StatusOK,
+			"console.log('hi')",
+			router.DefaultAssetsCachePolicy,
+			"",
+		},
+		{
+			"Precompressed-Not-Present",
+			router.AssetsConfig{FS: fsys, Prefix: "/static/", Precompressed: true},
+			"/static/style.css",
+			"br, gzip",
+			http.StatusOK,
+			"body {}",
+			router.DefaultAssetsCachePolicy,
+			"",
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			rt := router.NewWithResponder("TESTING", middleware.NoopAdapter, nil, nil)
+			rt.HandleNotFound(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusNotFound) })
+			rt.Assets(tc.cfg)
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodGet, tc.path, nil)
+			if tc.acceptEncoding != "" {
+				r.Header.Set("Accept-Encoding", tc.acceptEncoding)
+			}
+
+			// Act
+			rt.ServeHTTP(w, r)
+
+			// Assert
+			require.Equal(t, tc.expectedCode, w.Code)
+			require.Equal(t, tc.expectedCache, w.Header().Get("Cache-Control"))
+			require.Equal(t, tc.expectedEncoding, w.Header().Get("Content-Encoding"))
+			if tc.expectedBody != "" {
+				require.Equal(t, tc.expectedBody, w.Body.String())
+			}
+
+			if tc.expectedEncoding != "" {
+				require.Contains(t, w.Header().Get("Content-Type"), "javascript")
+			}
+		})
+	}
+}
+
+func TestDefaultRouterAssetsCaching(t *testing.T) {
+	fsys := fstest.MapFS{
+		"app-3f9a1c2b.js":       {Data: []byte("console.log('hashed')")},
+		"dashboard-settings.js": {Data: []byte("console.log('settings')")},
+		"index.html":            {Data: []byte("<html></html>")},
+	}
+
+	newRouter := func(cfg router.AssetsConfig) router.Router {
+		rt := router.NewWithResponder("TESTING", middleware.NoopAdapter, nil, nil)
+		rt.HandleNotFound(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusNotFound) })
+		rt.Assets(cfg)
+		return rt
+	}
+
+	t.Run("Hashed", func(t *testing.T) {
+		// Arrange
+		rt := newRouter(router.AssetsConfig{FS: fsys, Prefix: "/static/"})
+
+		for path, expected := range map[string]string{
+			"/static/app-3f9a1c2b.js":       router.HashedAssetsCachePolicy,
+			"/static/dashboard-settings.js": router.DefaultAssetsCachePolicy,
+		} {
+			w := httptest.NewRecorder()
+
+			// Act
+			rt.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+
+			// Assert
+			require.Equal(t, http.StatusOK, w.Code)
+			require.Equal(t, expected, w.Header().Get("Cache-Control"))
+		}
+	})
+
+	t.Run("ETag", func(t *testing.T) {
+		// Arrange
+		rt := newRouter(router.AssetsConfig{FS: fsys, Prefix: "/static/"})
+		w := httptest.NewRecorder()
+
+		// Act
+		rt.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/static/app-3f9a1c2b.js", nil))
+
+		// Assert
+		require.Equal(t, http.StatusOK, w.Code)
+		etag := w.Header().Get("ETag")
+		require.NotEmpty(t, etag)
+
+		// Arrange
+		w = httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/static/app-3f9a1c2b.js", nil)
+		r.Header.Set("If-None-Match", etag)
+
+		// Act
+		rt.ServeHTTP(w, r)
+
+		// Assert
+		require.Equal(t, http.StatusNotModified, w.Code)
+		require.Empty(t, w.Body.String())
+
+		// Arrange
+		w = httptest.NewRecorder()
+		r = httptest.NewRequest(http.MethodGet, "/static/dashboard-settings.js", nil)
+		r.Header.Set("If-None-Match", etag)
+
+		// Act
+		rt.ServeHTTP(w, r)
+
+		// Assert
+		require.Equal(t, http.StatusOK, w.Code)
+		require.NotEqual(t, etag, w.Header().Get("ETag"))
+	})
+
+	t.Run("SPA-Index", func(t *testing.T) {
+		for _, tc := range []struct {
+			name     string
+			spaIndex string
+			path     string
+			code     int
+			body     string
+		}{
+			{"Deep-Link", "index.html", "/static/users/42", http.StatusOK, "<html></html>"},
+			{"Missing-File", "index.html", "/static/missing.js", http.StatusNotFound, ""},
+			{"Not-Configured", "", "/static/users/42", http.StatusNotFound, ""},
+		} {
+			t.Run(tc.name, func(t *testing.T) {
+				// Arrange
+				rt := newRouter(router.AssetsConfig{FS: fsys, Prefix: "/static/", SPAIndex: tc.spaIndex})
+				w := httptest.NewRecorder()
+
+				// Act
+				rt.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tc.path, nil))
+
+				// Assert
+				require.Equal(t, tc.code, w.Code)
+				if tc.body != "" {
+					require.Equal(t, tc.body, w.Body.String())
+					require.Equal(t, router.IndexCachePolicy, w.Header().Get("Cache-Control"))
+				}
+			})
+		}
+	})
+}
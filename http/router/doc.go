@@ -18,9 +18,29 @@ which aid in directing, redirecting, or adding contextual information to a reque
 It is also often the case that small errors can lead to registering a route incorrectly,
 thereby unintentionally exposing a resource or not collecting data necessary for actually handling a request.
 Thus, a [Router] provides conveniences for making a single call to register many logically associated Routes.
+For a one-off Route, methods named after HTTP methods save constructing a [Route]:
+
+	r.Get("/health", healthHandler)
+	r.Post("/webhooks", webhookHandler, middleware.VerifySignature(cfg))
+
+A Route's path can name variables in braces, optionally constrained by a regular expression,
+e.g., "/users/{id:[0-9]+}".
+Handlers retrieve them with [Param], without depending on the package implementing [*DefaultRouter]:
+
+	id, err := router.Param[int64](r, "id")
 
 A Router expects two such groups of routes:
 those pointing to resources, alternatively, outside of or behind authentication barriers.
 The UnauthedRoutes and AuthedRoutes methods ensure routes are registered in the appropriate way, consequently.
+
+A Router built separately, with its own middlewares and [*resp.Responder], can be mounted under a path prefix,
+routing requests as if the prefix were not part of their path:
+
+	r.Mount("/billing", billing)
+
+Versioned registers each version of an API under a prefix, deprecating all but the newest
+and retiring those with no Routes:
+
+	r.Versioned("/api", map[string][]router.Route{"v1": nil, "v2": v2Routes})
 */
 package router
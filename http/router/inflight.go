@@ -0,0 +1,59 @@
+package router
+
+import (
+	"net/http"
+	"sync"
+)
+
+// An inflight counts the requests a Router and its Subrouters are handling, by route.
+type inflight struct {
+	mu     sync.Mutex
+	routes map[string]int
+	total  int
+}
+
+// newInflight constructs an *inflight.
+func newInflight() *inflight { return &inflight{routes: make(map[string]int)} }
+
+// track counts requests h handles under key for as long as h handles them.
+func (in *inflight) track(key string, h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		in.mu.Lock()
+		in.routes[key]++
+		in.total++
+		in.mu.Unlock()
+
+		defer func() {
+			in.mu.Lock()
+			in.routes[key]--
+			if in.routes[key] == 0 {
+				delete(in.routes, key)
+			}
+			in.total--
+			in.mu.Unlock()
+		}()
+
+		h.ServeHTTP(w, r)
+	})
+}
+
+// count is the number of requests being handled.
+func (in *inflight) count() int {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+
+	return in.total
+}
+
+// byRoute copies the number of requests being handled by each route.
+func (in *inflight) byRoute() map[string]int {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+
+	routes := make(map[string]int, len(in.routes))
+	for k, v := range in.routes {
+		routes[k] = v
+	}
+
+	return routes
+}
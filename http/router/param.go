@@ -0,0 +1,67 @@
+package router
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/xy-planning-network/trails"
+)
+
+// A ParamType is a type Param can parse a path variable into.
+type ParamType interface {
+	string | int | int32 | int64 | uint | uint32 | uint64
+}
+
+// Param retrieves the path variable named from the request, parsed into T.
+// Path variables are named in a Route.Path with braces, e.g., "/users/{id}".
+//
+// Param returns trails.ErrNotExist if the request has no such variable
+// and trails.ErrNotValid if it cannot be parsed into T.
+//
+// Constrain the variable in the Route.Path to avoid matching requests Param cannot parse,
+// e.g., "/users/{id:[0-9]+}".
+func Param[T ParamType](r *http.Request, name string) (T, error) {
+	var out T
+	val, ok := mux.Vars(r)[name]
+	if !ok {
+		return out, fmt.Errorf("%w: no path variable named %q", trails.ErrNotExist, name)
+	}
+
+	var err error
+	switch p := any(&out).(type) {
+	case *string:
+		*p = val
+	case *int:
+		*p, err = strconv.Atoi(val)
+	case *int32:
+		var i int64
+		i, err = strconv.ParseInt(val, 10, 32)
+		*p = int32(i)
+	case *int64:
+		*p, err = strconv.ParseInt(val, 10, 64)
+	case *uint:
+		var u uint64
+		u, err = strconv.ParseUint(val, 10, strconv.IntSize)
+		*p = uint(u)
+	case *uint32:
+		var u uint64
+		u, err = strconv.ParseUint(val, 10, 32)
+		*p = uint32(u)
+	case *uint64:
+		*p, err = strconv.ParseUint(val, 10, 64)
+	}
+
+	if err != nil {
+		var zero T
+		return zero, fmt.Errorf("%w: path variable %q: %s", trails.ErrNotValid, name, err)
+	}
+
+	return out, nil
+}
+
+// Params retrieves all path variables from the request, unparsed.
+func Params(r *http.Request) map[string]string {
+	return mux.Vars(r)
+}
@@ -0,0 +1,40 @@
+package router_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/require"
+	"github.com/xy-planning-network/trails"
+	"github.com/xy-planning-network/trails/http/router"
+)
+
+func TestParam(t *testing.T) {
+	// Arrange
+	r := mux.SetURLVars(
+		httptest.NewRequest(http.MethodGet, "/users/42/posts/hello", nil),
+		map[string]string{"id": "42", "slug": "hello", "big": "99999999999"},
+	)
+
+	// Act
+	id, idErr := router.Param[int64](r, "id")
+	uid, uidErr := router.Param[uint](r, "id")
+	slug, slugErr := router.Param[string](r, "slug")
+	_, notValidErr := router.Param[int](r, "slug")
+	_, overflowErr := router.Param[int32](r, "big")
+	_, notExistErr := router.Param[int](r, "missing")
+
+	// Assert
+	require.NoError(t, idErr)
+	require.Equal(t, int64(42), id)
+	require.NoError(t, uidErr)
+	require.Equal(t, uint(42), uid)
+	require.NoError(t, slugErr)
+	require.Equal(t, "hello", slug)
+	require.ErrorIs(t, notValidErr, trails.ErrNotValid)
+	require.ErrorIs(t, overflowErr, trails.ErrNotValid)
+	require.ErrorIs(t, notExistErr, trails.ErrNotExist)
+	require.Equal(t, map[string]string{"id": "42", "slug": "hello", "big": "99999999999"}, router.Params(r))
+}
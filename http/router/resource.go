@@ -0,0 +1,87 @@
+package router
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/xy-planning-network/trails/http/middleware"
+)
+
+// ResourceIDParam names the path variable identifying a single resource in Routes Resource registers;
+// read it with Param, e.g., router.Param[int64](r, router.ResourceIDParam).
+const ResourceIDParam = "id"
+
+// A ResourceController handles requests for a collection of resources and the individual resources in it.
+type ResourceController interface {
+	// Index lists the resources.
+	Index(w http.ResponseWriter, r *http.Request)
+
+	// Show retrieves a single resource.
+	Show(w http.ResponseWriter, r *http.Request)
+
+	// Create adds a resource.
+	Create(w http.ResponseWriter, r *http.Request)
+
+	// Update changes a single resource.
+	Update(w http.ResponseWriter, r *http.Request)
+
+	// Destroy removes a single resource.
+	Destroy(w http.ResponseWriter, r *http.Request)
+}
+
+// A ResourceFormController is a ResourceController also rendering forms
+// for creating and editing resources.
+type ResourceFormController interface {
+	ResourceController
+
+	// New renders a form for creating a resource.
+	New(w http.ResponseWriter, r *http.Request)
+
+	// Edit renders a form for changing a single resource.
+	Edit(w http.ResponseWriter, r *http.Request)
+}
+
+// Resource registers the handlers of the [ResourceController] at conventional paths under prefix,
+// calling middlewares before each:
+//
+//	GET    prefix            Index
+//	POST   prefix            Create
+//	GET    prefix/{id}       Show
+//	PUT    prefix/{id}       Update
+//	PATCH  prefix/{id}       Update
+//	DELETE prefix/{id}       Destroy
+//
+// If ctrl is a [ResourceFormController], Resource also registers:
+//
+//	GET    prefix/new        New
+//	GET    prefix/{id}/edit  Edit
+//
+// {id} matches only digits; read it with Param using ResourceIDParam.
+func (r *DefaultRouter) Resource(prefix string, ctrl ResourceController, middlewares ...middleware.Adapter) {
+	r.HandleRoutes(resourceRoutes(prefix, ctrl), middlewares...)
+}
+
+// resourceRoutes lists the Routes Resource registers.
+func resourceRoutes(prefix string, ctrl ResourceController) []Route {
+	prefix = strings.TrimSuffix(prefix, "/")
+	item := prefix + "/{" + ResourceIDParam + ":[0-9]+}"
+
+	var routes []Route
+	if fc, ok := ctrl.(ResourceFormController); ok {
+		routes = append(
+			routes,
+			Route{Path: prefix + "/new", Method: http.MethodGet, Handler: fc.New},
+			Route{Path: item + "/edit", Method: http.MethodGet, Handler: fc.Edit},
+		)
+	}
+
+	return append(
+		routes,
+		Route{Path: prefix, Method: http.MethodGet, Handler: ctrl.Index},
+		Route{Path: prefix, Method: http.MethodPost, Handler: ctrl.Create},
+		Route{Path: item, Method: http.MethodGet, Handler: ctrl.Show},
+		Route{Path: item, Method: http.MethodPut, Handler: ctrl.Update},
+		Route{Path: item, Method: http.MethodPatch, Handler: ctrl.Update},
+		Route{Path: item, Method: http.MethodDelete, Handler: ctrl.Destroy},
+	)
+}
@@ -0,0 +1,75 @@
+package router_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/xy-planning-network/trails/http/middleware"
+	"github.com/xy-planning-network/trails/http/router"
+)
+
+type testController struct{}
+
+func (testController) Index(w http.ResponseWriter, r *http.Request)   { w.Write([]byte("Index")) }
+func (testController) Create(w http.ResponseWriter, r *http.Request)  { w.Write([]byte("Create")) }
+func (testController) Destroy(w http.ResponseWriter, r *http.Request) { writeWithID(w, r, "Destroy") }
+func (testController) Show(w http.ResponseWriter, r *http.Request)    { writeWithID(w, r, "Show") }
+func (testController) Update(w http.ResponseWriter, r *http.Request)  { writeWithID(w, r, "Update") }
+
+type testFormController struct{ testController }
+
+func (testFormController) New(w http.ResponseWriter, r *http.Request)  { w.Write([]byte("New")) }
+func (testFormController) Edit(w http.ResponseWriter, r *http.Request) { writeWithID(w, r, "Edit") }
+
+func writeWithID(w http.ResponseWriter, r *http.Request, action string) {
+	id, err := router.Param[int64](r, router.ResourceIDParam)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Write([]byte(action + " " + strconv.FormatInt(id, 10)))
+}
+
+func TestDefaultRouterResource(t *testing.T) {
+	tcs := []struct {
+		name         string
+		ctrl         router.ResourceController
+		method       string
+		path         string
+		expectedCode int
+		expectedBody string
+	}{
+		{"Index", testController{}, http.MethodGet, "/things", http.StatusOK, "Index"},
+		{"Create", testController{}, http.MethodPost, "/things", http.StatusOK, "Create"},
+		{"Show", testController{}, http.MethodGet, "/things/7", http.StatusOK, "Show 7"},
+		{"Put", testController{}, http.MethodPut, "/things/7", http.StatusOK, "Update 7"},
+		{"Patch", testController{}, http.MethodPatch, "/things/7", http.StatusOK, "Update 7"},
+		{"Destroy", testController{}, http.MethodDelete, "/things/7", http.StatusOK, "Destroy 7"},
+		{"Non-Numeric-ID", testController{}, http.MethodGet, "/things/abc", http.StatusNotFound, ""},
+		{"No-Forms", testController{}, http.MethodGet, "/things/new", http.StatusNotFound, ""},
+		{"New", testFormController{}, http.MethodGet, "/things/new", http.StatusOK, "New"},
+		{"Edit", testFormController{}, http.MethodGet, "/things/7/edit", http.StatusOK, "Edit 7"},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			rt := router.NewWithResponder("TESTING", middleware.NoopAdapter, nil, nil)
+			rt.Resource("/things/", tc.ctrl)
+			w := httptest.NewRecorder()
+
+			// Act
+			rt.ServeHTTP(w, httptest.NewRequest(tc.method, tc.path, nil))
+
+			// Assert
+			require.Equal(t, tc.expectedCode, w.Code)
+			if tc.expectedBody != "" {
+				require.Equal(t, tc.expectedBody, w.Body.String())
+			}
+		})
+	}
+}
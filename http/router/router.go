@@ -2,33 +2,104 @@ package router
 
 import (
 	"net/http"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/gorilla/mux"
 	"github.com/xy-planning-network/trails/http/middleware"
+	"github.com/xy-planning-network/trails/http/resp"
+	"github.com/xy-planning-network/trails/logger"
 )
 
 const (
-	assetsPath = "client/dist/"
+	allowHeader = "Allow"
+	assetsPath  = "client/dist/"
 )
 
+// routableMethods are the HTTP methods a Route can be registered for.
+var routableMethods = []string{
+	http.MethodDelete,
+	http.MethodGet,
+	http.MethodHead,
+	http.MethodOptions,
+	http.MethodPatch,
+	http.MethodPost,
+	http.MethodPut,
+}
+
 // A Route maps a path and HTTP method to an [http.HandlerFunc].
 // Additional [middleware.Adapter] can be called when a server handles
 // a request matching the Route.
+//
+// Operational policies for a Route can be declared alongside it
+// using MaxBodyBytes, RateLimit, and Timeout.
+// A Router applies these after any middlewares it applies to a group of Routes
+// and before the Route's own Middlewares.
 type Route struct {
 	Path        string
 	Method      string
 	Handler     http.HandlerFunc
 	Middlewares []middleware.Adapter
+
+	// MaxBodyBytes limits the size of request bodies; cf. [middleware.LimitBody].
+	//
+	// If MaxBodyBytes is 0, request bodies are not limited.
+	MaxBodyBytes int64
+
+	// RateLimit limits how often each visitor can make requests; cf. [middleware.RateLimit].
+	// Share a *middleware.Visitors between Routes to limit them together.
+	//
+	// If RateLimit is nil, requests are not limited.
+	RateLimit *middleware.Visitors
+
+	// Timeout sets a deadline for handling requests; cf. [middleware.Timeout].
+	//
+	// If Timeout is 0, requests have no deadline.
+	Timeout time.Duration
+}
+
+// policies lists the middlewares translating the operational policies declared on the Route.
+func (route Route) policies() []middleware.Adapter {
+	var mws []middleware.Adapter
+	if route.RateLimit != nil {
+		mws = append(mws, middleware.RateLimit(route.RateLimit))
+	}
+
+	if route.MaxBodyBytes > 0 {
+		mws = append(mws, middleware.LimitBody(route.MaxBodyBytes))
+	}
+
+	if route.Timeout > 0 {
+		mws = append(mws, middleware.Timeout(route.Timeout))
+	}
+
+	return mws
 }
 
 // A Router handles many [Route], directing HTTP requests to the appropriate endpoint.
 type Router interface {
+	// Assets configures how the Router serves static assets.
+	Assets(cfg AssetsConfig)
+
 	// AuthedRoutes registers the set of Routes as those requiring authentication.
 	AuthedRoutes(loginUrl string, logoffUrl string, routes []Route, middlewares ...middleware.Adapter)
 
 	// CatchAll sets up a handler for all routes to funnel to for e.g. maintenace mode.
 	CatchAll(handler http.HandlerFunc)
 
+	// CORS applies the [middleware.CORSPolicy] to Routes registered afterwards
+	// and responds to preflight requests for any endpoint the Router handles.
+	CORS(policy middleware.CORSPolicy)
+
+	// Delete registers handler for DELETE requests to path,
+	// calling the middlewares before it.
+	Delete(path string, handler http.HandlerFunc, middlewares ...middleware.Adapter)
+
+	// Get registers handler for GET requests to path,
+	// calling the middlewares before it.
+	Get(path string, handler http.HandlerFunc, middlewares ...middleware.Adapter)
+
 	// Handle applies the [Route] to the Router
 	Handle(route Route)
 
@@ -40,36 +111,88 @@ type Router interface {
 	// HandleRoutes calls the provided middlewares before sending a request to the Route.
 	HandleRoutes(routes []Route, middlewares ...middleware.Adapter)
 
+	// InFlight is the number of requests the Router and its Subrouters are handling.
+	InFlight() int
+
+	// InFlightRoutes counts the requests the Router and its Subrouters are handling by route,
+	// e.g., "GET /users/{id}".
+	InFlightRoutes() map[string]int
+
+	// Mount hands requests to paths under prefix to app, which routes them with prefix trimmed,
+	// applying its own middlewares rather than those of the Router.
+	Mount(prefix string, app Router)
+
 	// OnEveryRequest sets the middleware stack to be applied before every request
 	//
 	// Other methods applying a set of [middleware.Adapter] will always apply theirs
 	// after the set defined by OnEveryRequest.
 	OnEveryRequest(middlewares ...middleware.Adapter)
 
-	// Subrouter prefixes a Router's handling with the provided string
-	Subrouter(prefix string) Router
+	// Patch registers handler for PATCH requests to path,
+	// calling the middlewares before it.
+	Patch(path string, handler http.HandlerFunc, middlewares ...middleware.Adapter)
+
+	// Post registers handler for POST requests to path,
+	// calling the middlewares before it.
+	Post(path string, handler http.HandlerFunc, middlewares ...middleware.Adapter)
 
-	SubrouterHost(host string) Router
+	// Put registers handler for PUT requests to path,
+	// calling the middlewares before it.
+	Put(path string, handler http.HandlerFunc, middlewares ...middleware.Adapter)
+
+	// Resource registers the handlers of the [ResourceController] at conventional paths under prefix.
+	Resource(prefix string, ctrl ResourceController, middlewares ...middleware.Adapter)
+
+	// Routes describes the Routes registered on the Router,
+	// including those registered on any Subrouter, in the order they were registered.
+	Routes() []RouteInfo
+
+	// Subrouter prefixes a Router's handling with the provided string,
+	// applying mws to every request after those the Router already applies.
+	Subrouter(prefix string, mws ...middleware.Adapter) Router
+
+	// SubrouterHost restricts a Router's handling to hosts matching the provided string,
+	// applying mws to every request after those the Router already applies.
+	SubrouterHost(host string, mws ...middleware.Adapter) Router
 
 	// UnauthedRoutes handles the set of Routes
 	UnauthedRoutes(routes []Route, middlewares ...middleware.Adapter)
 
+	// Versioned registers the Routes for each version of an API under prefix, e.g., "/api/v2",
+	// responding to requests for retired versions, those with no Routes, with 410.
+	Versioned(prefix string, versions map[string][]Route, middlewares ...middleware.Adapter)
+
 	http.Handler
 }
 
 // The DefaultRouter handles HTTP requests to any Routes it is configured with.
 //
-// DefaultRouter applies the [middleware.ReportPanic] handler to all registered routes.
+// DefaultRouter responds with 405 to requests for a path it handles using an HTTP method it does not,
+// listing those it does in the "Allow" header.
+// OPTIONS requests for such a path are answered with 204 and the same header.
+//
+// DefaultRouter applies the [middleware.ReportPanicWithResponder] handler to all registered routes,
+// responding to panics with the [*resp.Responder] it is constructed with.
 //
-// DefaultRouter routes requests for assets to their location in a standard trails app layout.
+// DefaultRouter routes requests for assets to their location in a standard trails app layout,
+// or wherever Assets configures.
 // DefaultRouter applies a "Cache-Control" header to responses for assets.
 type DefaultRouter struct {
 	Env           string
+	assets        *assetServer
 	everyReqStack []middleware.Adapter
+	inflight      *inflight
 	logReq        middleware.Adapter
+	logger        logger.Logger
+	responder     *resp.Responder
+	routes        *routeRegistry
 	*mux.Router
 }
 
+// Assets configures how the [*DefaultRouter] serves static assets,
+// replacing any previous configuration.
+func (r *DefaultRouter) Assets(cfg AssetsConfig) { r.assets.configure(cfg) }
+
 // AuthedRoutes registers the set of Routes as those requiring authentication.
 // AuthedRoutes applies the given middlewares before performing that check,
 // using middleware.RequireAuthed.
@@ -88,35 +211,96 @@ func (r *DefaultRouter) AuthedRoutes(
 	r.HandleRoutes(routes, append(middlewares, middleware.RequireAuthed(loginUrl, logoffUrl))...)
 }
 
-// NewRouter constructs an implementation of [Router] using [DefaultRouter] for the given environment.
+// New constructs an implementation of [Router] using [DefaultRouter] for the given environment.
 //
-// TODO(dlk): use provided [fs.FS] and [http.FS] instead of [http.FileServer].
+// Deprecated: Use [NewWithResponder], which renders responses to requests whose handler panics.
 func New(env string, logReq middleware.Adapter) Router {
-	r := mux.NewRouter()
-	cacheControl := cacheControlMiddleware()
+	return NewWithResponder(env, logReq, nil, nil)
+}
 
-	assetsServer := http.FileServer(http.Dir(assetsPath))
+// NewWithResponder constructs an implementation of [Router] using [DefaultRouter] for the given environment.
+// d renders responses to requests whose handler panics and, if d is nil, l logs those panics;
+// cf. [middleware.ReportPanicWithResponder].
+//
+// The Router serves assets using the defaults of [AssetsConfig] until Assets configures it otherwise.
+func NewWithResponder(env string, logReq middleware.Adapter, d *resp.Responder, l logger.Logger) Router {
+	r := mux.NewRouter()
+	rtr := &DefaultRouter{
+		Env:       env,
+		Router:    r,
+		assets:    newAssetServer(AssetsConfig{}),
+		inflight:  newInflight(),
+		logReq:    logReq,
+		logger:    l,
+		responder: d,
+		routes:    new(routeRegistry),
+	}
 
 	// NOTE(dlk): direct reqs for the client to its distribution
-	r.PathPrefix("/" + assetsPath).Handler(middleware.Chain(
-		http.StripPrefix("/"+assetsPath, assetsServer),
-		cacheControl,
-		logReq,
-	))
+	r.MatcherFunc(func(req *http.Request, _ *mux.RouteMatch) bool { return rtr.assets.match(req) }).
+		Handler(middleware.Chain(rtr.assets, logReq))
 
-	return &DefaultRouter{logReq: logReq, Env: env, Router: r}
+	var notAllowed http.Handler = http.HandlerFunc(rtr.methodNotAllowed)
+	if logReq != nil {
+		notAllowed = logReq(notAllowed)
+	}
+	r.MethodNotAllowedHandler = notAllowed
+
+	return rtr
 }
 
 // CatchAll sets up a handler for all routes to funnel to for e.g. maintenace mode.
 func (r *DefaultRouter) CatchAll(handler http.HandlerFunc) {
 	r.Router.PathPrefix("/").Handler(
 		middleware.Chain(
-			middleware.ReportPanic(r.Env)(handler),
+			middleware.ReportPanicWithResponder(r.Env, r.responder, r.logger)(handler),
 			r.everyReqStack...,
 		),
 	)
 }
 
+// CORS applies the [middleware.CORSPolicy] to Routes registered after calling CORS
+// and responds to preflight requests for any endpoint the [*DefaultRouter] handles.
+//
+// Call CORS on a Subrouter to apply a policy to only that group of Routes, e.g.:
+//
+//	api := r.Subrouter("/api")
+//	api.CORS(middleware.CORSPolicy{AllowedOrigins: []string{"https://*.example.com"}})
+//	api.HandleRoutes(apiRoutes)
+//
+// Like [middleware.CORSWithPolicy], CORS panics if policy allows no origins
+// or allows any origin with credentials.
+func (r *DefaultRouter) CORS(policy middleware.CORSPolicy) {
+	cors := middleware.CORSWithPolicy(policy)
+	r.everyReqStack = slices.Concat(r.everyReqStack, []middleware.Adapter{cors})
+
+	// NOTE: preflight requests use OPTIONS, which Routes are rarely registered for,
+	// so catch them all for this Router and let cors respond to them.
+	preflight := cors(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	if r.logReq != nil {
+		preflight = r.logReq(preflight)
+	}
+
+	r.Router.
+		Methods(http.MethodOptions).
+		HeadersRegexp("Access-Control-Request-Method", ".+").
+		Handler(preflight)
+}
+
+// Delete registers handler for DELETE requests to path,
+// calling the middlewares before it.
+func (r *DefaultRouter) Delete(path string, handler http.HandlerFunc, middlewares ...middleware.Adapter) {
+	r.handleMethod(http.MethodDelete, path, handler, middlewares)
+}
+
+// Get registers handler for GET requests to path,
+// calling the middlewares before it.
+func (r *DefaultRouter) Get(path string, handler http.HandlerFunc, middlewares ...middleware.Adapter) {
+	r.handleMethod(http.MethodGet, path, handler, middlewares)
+}
+
 // Handle applies the [Route] to the [Router].
 func (r *DefaultRouter) Handle(route Route) {
 	r.HandleRoutes([]Route{route})
@@ -126,7 +310,7 @@ func (r *DefaultRouter) Handle(route Route) {
 // for when no other registered Route is matched.
 func (r *DefaultRouter) HandleNotFound(handler http.HandlerFunc) {
 	r.Router.NotFoundHandler = middleware.Chain(
-		middleware.ReportPanic(r.Env)(handler),
+		middleware.ReportPanicWithResponder(r.Env, r.responder, r.logger)(handler),
 		r.logReq,
 	)
 }
@@ -137,48 +321,161 @@ func (r *DefaultRouter) HandleNotFound(handler http.HandlerFunc) {
 // so are called after the default set.
 func (r *DefaultRouter) HandleRoutes(routes []Route, middlewares ...middleware.Adapter) {
 	for _, route := range routes {
-		mws := append(middlewares, route.Middlewares...)
-		r.Router.
-			Handle(
-				route.Path,
-				middleware.Chain(
-					middleware.ReportPanic(r.Env)(route.Handler),
-					append(r.everyReqStack, mws...)...,
-				),
-			).
-			Methods(route.Method)
+		mws := slices.Concat(r.everyReqStack, middlewares, route.policies(), route.Middlewares)
+		mr := r.Router.NewRoute().Path(route.Path).Methods(route.Method)
+
+		key := route.Method + " " + route.Path
+		if tmpl, err := mr.GetPathTemplate(); err == nil {
+			key = route.Method + " " + tmpl
+		}
+
+		mr.Handler(r.inflight.track(key, middleware.Chain(
+			middleware.ReportPanicWithResponder(r.Env, r.responder, r.logger)(route.Handler),
+			mws...,
+		)))
+
+		r.routes.add(mr, route, mws)
+	}
+
+}
+
+// InFlight is the number of requests the [*DefaultRouter] and its Subrouters are handling
+// for the Routes registered on them and the Routers mounted on them.
+func (r *DefaultRouter) InFlight() int {
+	n := r.inflight.count()
+	for _, m := range r.routes.mounted() {
+		n += m.app.InFlight()
+	}
+
+	return n
+}
+
+// InFlightRoutes counts the requests the [*DefaultRouter] and its Subrouters are handling by Route,
+// keyed by HTTP method and path template, e.g., "GET /users/{id}",
+// including those the Routers mounted on them are handling, whose path templates include the prefix.
+func (r *DefaultRouter) InFlightRoutes() map[string]int {
+	routes := r.inflight.byRoute()
+	for _, m := range r.routes.mounted() {
+		for key, n := range m.app.InFlightRoutes() {
+			method, path, _ := strings.Cut(key, " ")
+			routes[method+" "+m.prefixed(path)] += n
+		}
 	}
 
+	return routes
+}
+
+// Mount hands requests to prefix, or paths under it, to app,
+// which routes them as if prefix were not part of their path, so
+// r.Mount("/billing", app) sends "/billing/invoices" to the Route app registers for "/invoices".
+// prefix ought to be a literal path, not a template with variables.
+//
+// Mount composes applications built separately, e.g., with their own *resp.Responder and templates,
+// into one served by the same server:
+// app applies only the middlewares it is configured with, never those of the [*DefaultRouter].
+//
+// Routes, InFlight, and InFlightRoutes include the Routes registered on app, prefixed with prefix.
+//
+// When called on a Subrouter, the Subrouter's prefix comes before prefix.
+func (r *DefaultRouter) Mount(prefix string, app Router) {
+	prefix = "/" + strings.Trim(prefix, "/")
+	mr := r.Router.NewRoute().PathPrefix(prefix)
+	if tmpl, err := mr.GetPathTemplate(); err == nil {
+		prefix = tmpl
+	}
+
+	// NOTE: a path prefix matches "/billingfoo" as well as "/billing/foo".
+	mr.MatcherFunc(func(req *http.Request, _ *mux.RouteMatch) bool {
+		return req.URL.Path == prefix || strings.HasPrefix(req.URL.Path, prefix+"/")
+	})
+
+	mr.Handler(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		u := *req.URL
+		u.Path = "/" + strings.TrimPrefix(strings.TrimPrefix(req.URL.Path, prefix), "/")
+		u.RawPath = ""
+
+		mounted := req.Clone(req.Context())
+		mounted.URL = &u
+		app.ServeHTTP(w, mounted)
+	}))
+
+	r.routes.mount(mount{app: app, prefix: prefix})
 }
 
 // OnEveryRequest appends the middlewares to the existing stack
 // that the [*DefaultRouter] will apply to every request.
+//
+// Pass a [*middleware.Stack] using its Adapters method:
+//
+//	r.OnEveryRequest(stack.Adapters()...)
 func (r *DefaultRouter) OnEveryRequest(middlewares ...middleware.Adapter) {
-	r.everyReqStack = append(r.everyReqStack, middlewares...)
+	// NOTE: never append in place, since Subrouters may share the backing array.
+	r.everyReqStack = slices.Concat(r.everyReqStack, middlewares)
 }
 
+// Patch registers handler for PATCH requests to path,
+// calling the middlewares before it.
+func (r *DefaultRouter) Patch(path string, handler http.HandlerFunc, middlewares ...middleware.Adapter) {
+	r.handleMethod(http.MethodPatch, path, handler, middlewares)
+}
+
+// Post registers handler for POST requests to path,
+// calling the middlewares before it.
+func (r *DefaultRouter) Post(path string, handler http.HandlerFunc, middlewares ...middleware.Adapter) {
+	r.handleMethod(http.MethodPost, path, handler, middlewares)
+}
+
+// Put registers handler for PUT requests to path,
+// calling the middlewares before it.
+func (r *DefaultRouter) Put(path string, handler http.HandlerFunc, middlewares ...middleware.Adapter) {
+	r.handleMethod(http.MethodPut, path, handler, middlewares)
+}
+
+// Routes describes the Routes registered on the [*DefaultRouter],
+// including those registered on any Subrouter, in the order they were registered.
+func (r *DefaultRouter) Routes() []RouteInfo { return r.routes.list() }
+
 // ServeHTTP responds to an HTTP request.
 func (r *DefaultRouter) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 	r.Router.ServeHTTP(w, req)
 }
 
-func (r *DefaultRouter) SubrouterHost(host string) Router {
-	return &DefaultRouter{
-		Env:           r.Env,
-		Router:        r.Router.Host(host).Subrouter(),
-		everyReqStack: r.everyReqStack,
-	}
+// SubrouterHost constructs a [Router] that handles requests to hosts matching host,
+// e.g., "api.example.com" or "{subdomain}.example.com".
+//
+// The Router inherits the middlewares the [*DefaultRouter] applies to every request
+// as of calling SubrouterHost, followed by mws.
+// Calls to OnEveryRequest on either Router afterwards affect only that Router.
+//
+// e.g., r.SubrouterHost("api.example.com", middleware.CORS("https://app.example.com"))
+func (r *DefaultRouter) SubrouterHost(host string, mws ...middleware.Adapter) Router {
+	return r.subrouter(r.Router.Host(host).Subrouter(), mws)
 }
 
 // Subrouter constructs a [Router] that handles requests to endpoints matching the prefix.
 //
+// The Router inherits the middlewares the [*DefaultRouter] applies to every request
+// as of calling Subrouter, followed by mws.
+// Calls to OnEveryRequest on either Router afterwards affect only that Router.
+//
 // e.g., r.Subrouter("/api/v1") handles requests to endpoints like /api/v1/users
-func (r *DefaultRouter) Subrouter(prefix string) Router {
+func (r *DefaultRouter) Subrouter(prefix string, mws ...middleware.Adapter) Router {
+	return r.subrouter(r.Router.PathPrefix(prefix).Subrouter(), mws)
+}
+
+// subrouter constructs a [*DefaultRouter] handling requests with mr,
+// inheriting the configuration of r and appending mws to its stack of middlewares.
+func (r *DefaultRouter) subrouter(mr *mux.Router, mws []middleware.Adapter) *DefaultRouter {
 	return &DefaultRouter{
 		Env:           r.Env,
-		Router:        r.Router.PathPrefix(prefix).Subrouter(),
+		Router:        mr,
+		assets:        r.assets,
+		everyReqStack: slices.Concat(r.everyReqStack, mws),
+		inflight:      r.inflight,
 		logReq:        r.logReq,
-		everyReqStack: r.everyReqStack,
+		logger:        r.logger,
+		responder:     r.responder,
+		routes:        r.routes,
 	}
 }
 
@@ -188,12 +485,39 @@ func (r *DefaultRouter) UnauthedRoutes(routes []Route, middlewares ...middleware
 	r.HandleRoutes(routes, append(middlewares, middleware.RequireUnauthed())...)
 }
 
-// cacheControlMiddleware helps by adding a "Cache-Control" header to the response.
-func cacheControlMiddleware() middleware.Adapter {
-	return func(handler http.Handler) http.Handler {
-		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			w.Header().Set("Cache-Control", "max-age=2592000") // 30 days
-			handler.ServeHTTP(w, r)
-		})
+// handleMethod registers a single Route for the HTTP method.
+func (r *DefaultRouter) handleMethod(method, path string, handler http.HandlerFunc, middlewares []middleware.Adapter) {
+	r.HandleRoutes([]Route{{Path: path, Method: method, Handler: handler}}, middlewares...)
+}
+
+// allowedMethods lists the HTTP methods the Router handles for the request's path.
+func (r *DefaultRouter) allowedMethods(req *http.Request) []string {
+	var allowed []string
+	for _, method := range routableMethods {
+		if method == http.MethodOptions {
+			allowed = append(allowed, method)
+			continue
+		}
+
+		probe := req.Clone(req.Context())
+		probe.Method = method
+
+		var match mux.RouteMatch
+		if r.Router.Match(probe, &match) && match.MatchErr == nil {
+			allowed = append(allowed, method)
+		}
+	}
+
+	return allowed
+}
+
+// methodNotAllowed responds to requests for a path the Router handles using an HTTP method it does not.
+func (r *DefaultRouter) methodNotAllowed(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set(allowHeader, strings.Join(r.allowedMethods(req), ", "))
+	if req.Method == http.MethodOptions {
+		w.WriteHeader(http.StatusNoContent)
+		return
 	}
+
+	http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
 }
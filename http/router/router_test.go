@@ -0,0 +1,354 @@
+package router_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/xy-planning-network/trails/http/middleware"
+	"github.com/xy-planning-network/trails/http/router"
+	"golang.org/x/time/rate"
+)
+
+func TestDefaultRouterMethods(t *testing.T) {
+	// Arrange
+	rt := router.NewWithResponder("TESTING", middleware.NoopAdapter, nil, nil)
+	respond := func(w http.ResponseWriter, r *http.Request) { w.Write([]byte(r.Method)) }
+	teapot := func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("X-Teapot", "true")
+			h.ServeHTTP(w, r)
+		})
+	}
+
+	rt.Delete("/things", respond)
+	rt.Get("/things", respond, teapot)
+	rt.Patch("/things", respond)
+	rt.Post("/things", respond)
+	rt.Put("/things", respond)
+
+	for _, method := range []string{http.MethodDelete, http.MethodGet, http.MethodPatch, http.MethodPost, http.MethodPut} {
+		t.Run(method, func(t *testing.T) {
+			w := httptest.NewRecorder()
+
+			// Act
+			rt.ServeHTTP(w, httptest.NewRequest(method, "/things", nil))
+
+			// Assert
+			require.Equal(t, http.StatusOK, w.Code)
+			require.Equal(t, method, w.Body.String())
+			require.Equal(t, method == http.MethodGet, w.Header().Get("X-Teapot") == "true")
+		})
+	}
+}
+
+func TestDefaultRouterMethodNotAllowed(t *testing.T) {
+	// Arrange
+	rt := router.NewWithResponder("TESTING", middleware.NoopAdapter, nil, nil)
+	rt.HandleNotFound(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusNotFound) })
+	rt.Get("/things/{id:[0-9]+}", func(w http.ResponseWriter, r *http.Request) {})
+	rt.Delete("/things/{id:[0-9]+}", func(w http.ResponseWriter, r *http.Request) {})
+	rt.Subrouter("/api").Post("/things", func(w http.ResponseWriter, r *http.Request) {})
+
+	tcs := []struct {
+		name          string
+		method        string
+		path          string
+		expectedCode  int
+		expectedAllow string
+	}{
+		{"Not-Allowed", http.MethodPost, "/things/1", http.StatusMethodNotAllowed, "DELETE, GET, OPTIONS"},
+		{"Options", http.MethodOptions, "/things/1", http.StatusNoContent, "DELETE, GET, OPTIONS"},
+		{"Subrouter", http.MethodGet, "/api/things", http.StatusMethodNotAllowed, "OPTIONS, POST"},
+		{"Not-Found", http.MethodPost, "/things/abc", http.StatusNotFound, ""},
+		{"Allowed", http.MethodGet, "/things/1", http.StatusOK, ""},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+
+			// Act
+			rt.ServeHTTP(w, httptest.NewRequest(tc.method, tc.path, nil))
+
+			// Assert
+			require.Equal(t, tc.expectedCode, w.Code)
+			require.Equal(t, tc.expectedAllow, w.Header().Get("Allow"))
+		})
+	}
+}
+
+func TestDefaultRouterRoutes(t *testing.T) {
+	// Arrange
+	rt := router.NewWithResponder("TESTING", middleware.NoopAdapter, nil, nil)
+	rt.OnEveryRequest(middleware.RequestID())
+	rt.Get("/things", testHandler)
+	rt.Subrouter("/api").Post("/things", testHandler, middleware.RequireAuthed("/login", "/logoff"))
+
+	// Act
+	routes := rt.Routes()
+
+	// Assert
+	require.Equal(t, []router.RouteInfo{
+		{
+			Handler:     "router_test.testHandler",
+			Methods:     []string{http.MethodGet},
+			Middlewares: []string{"middleware.RequestID"},
+			Path:        "/things",
+		},
+		{
+			Handler:     "router_test.testHandler",
+			Methods:     []string{http.MethodPost},
+			Middlewares: []string{"middleware.RequestID", "middleware.RequireAuthed"},
+			Path:        "/api/things",
+		},
+	}, routes)
+}
+
+func testHandler(w http.ResponseWriter, r *http.Request) {}
+
+func TestDefaultRouterRoutePolicies(t *testing.T) {
+	// Arrange
+	rt := router.NewWithResponder("TESTING", middleware.NoopAdapter, nil, nil)
+	rt.HandleRoutes([]router.Route{
+		{
+			Path:   "/upload",
+			Method: http.MethodPost,
+			Handler: func(w http.ResponseWriter, r *http.Request) {
+				if _, err := io.ReadAll(r.Body); err != nil {
+					w.WriteHeader(http.StatusRequestEntityTooLarge)
+				}
+			},
+			MaxBodyBytes: 4,
+		},
+		{
+			Path:    "/slow",
+			Method:  http.MethodGet,
+			Handler: func(w http.ResponseWriter, r *http.Request) { <-r.Context().Done() },
+			Timeout: time.Millisecond,
+		},
+		{
+			Path:      "/limited",
+			Method:    http.MethodGet,
+			Handler:   func(w http.ResponseWriter, r *http.Request) {},
+			RateLimit: middleware.NewVisitorsWithLimit(rate.Every(time.Hour), 1),
+		},
+	})
+
+	t.Run("MaxBodyBytes", func(t *testing.T) {
+		w := httptest.NewRecorder()
+
+		// Act
+		rt.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/upload", strings.NewReader("hello world")))
+
+		// Assert
+		require.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	})
+
+	t.Run("Timeout", func(t *testing.T) {
+		w := httptest.NewRecorder()
+
+		// Act
+		rt.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/slow", nil))
+
+		// Assert
+		require.Equal(t, http.StatusServiceUnavailable, w.Code)
+	})
+
+	t.Run("RateLimit", func(t *testing.T) {
+		first := httptest.NewRecorder()
+		second := httptest.NewRecorder()
+
+		// Act
+		rt.ServeHTTP(first, httptest.NewRequest(http.MethodGet, "/limited", nil))
+		rt.ServeHTTP(second, httptest.NewRequest(http.MethodGet, "/limited", nil))
+
+		// Assert
+		require.Equal(t, http.StatusOK, first.Code)
+		require.Equal(t, http.StatusTooManyRequests, second.Code)
+	})
+}
+
+func TestDefaultRouterSubrouterInheritance(t *testing.T) {
+	mark := func(name string) middleware.Adapter {
+		return func(h http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Add("X-Order", name)
+				h.ServeHTTP(w, r)
+			})
+		}
+	}
+
+	// Arrange
+	rt := router.NewWithResponder("TESTING", middleware.NoopAdapter, nil, nil)
+	rt.OnEveryRequest(mark("parent"))
+
+	api := rt.SubrouterHost("api.example.com", mark("host"))
+	api.OnEveryRequest(mark("api"))
+	api.Get("/things", testHandler)
+
+	admin := rt.Subrouter("/admin", mark("prefix"))
+	admin.Get("/things", testHandler)
+
+	rt.OnEveryRequest(mark("parent-after"))
+	rt.Get("/things", testHandler)
+
+	tcs := []struct {
+		name     string
+		url      string
+		expected []string
+	}{
+		{"Parent", "http://example.com/things", []string{"parent", "parent-after"}},
+		{"Host", "http://api.example.com/things", []string{"parent", "host", "api"}},
+		{"Prefix", "http://example.com/admin/things", []string{"parent", "prefix"}},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+
+			// Act
+			rt.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tc.url, nil))
+
+			// Assert
+			require.Equal(t, http.StatusOK, w.Code)
+			require.Equal(t, tc.expected, w.Header().Values("X-Order"))
+		})
+	}
+}
+
+func TestDefaultRouterInFlight(t *testing.T) {
+	// Arrange
+	rt := router.NewWithResponder("TESTING", middleware.NoopAdapter, nil, nil)
+	started := make(chan struct{})
+	release := make(chan struct{})
+	rt.Subrouter("/api").Get("/exports/{id}", func(w http.ResponseWriter, r *http.Request) {
+		started <- struct{}{}
+		<-release
+	})
+
+	done := make(chan struct{})
+	go func() {
+		rt.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/exports/1", nil))
+		close(done)
+	}()
+	<-started
+
+	// Act
+	during, duringRoutes := rt.InFlight(), rt.InFlightRoutes()
+	close(release)
+	<-done
+
+	// Assert
+	require.Equal(t, 1, during)
+	require.Equal(t, map[string]int{"GET /api/exports/{id}": 1}, duringRoutes)
+	require.Equal(t, 0, rt.InFlight())
+	require.Empty(t, rt.InFlightRoutes())
+}
+
+func TestDefaultRouterMount(t *testing.T) {
+	mark := func(name string) middleware.Adapter {
+		return func(h http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Add("X-Order", name)
+				h.ServeHTTP(w, r)
+			})
+		}
+	}
+	respond := func(w http.ResponseWriter, r *http.Request) { w.Write([]byte(r.URL.Path)) }
+
+	// Arrange
+	rt := router.NewWithResponder("TESTING", middleware.NoopAdapter, nil, nil)
+	rt.OnEveryRequest(mark("parent"))
+	rt.Get("/invoices", respond)
+
+	billing := router.NewWithResponder("TESTING", middleware.NoopAdapter, nil, nil)
+	billing.OnEveryRequest(mark("billing"))
+	billing.Get("/", respond)
+	billing.Get("/invoices/{id}", respond)
+	rt.Mount("/billing", billing)
+
+	rt.Subrouter("/admin").Mount("/billing/", billing)
+
+	tcs := []struct {
+		name     string
+		url      string
+		code     int
+		body     string
+		expected []string
+	}{
+		{"Parent", "/invoices", http.StatusOK, "/invoices", []string{"parent"}},
+		{"Mounted", "/billing/invoices/1", http.StatusOK, "/invoices/1", []string{"billing"}},
+		{"Mounted-Root", "/billing", http.StatusOK, "/", []string{"billing"}},
+		{"Mounted-Root-Slash", "/billing/", http.StatusOK, "/", []string{"billing"}},
+		{"Subrouter", "/admin/billing/invoices/1", http.StatusOK, "/invoices/1", []string{"billing"}},
+		{"Mounted-Not-Found", "/billing/invoices", http.StatusNotFound, "404 page not found\n", nil},
+		{"Prefix-Only", "/billingfoo", http.StatusNotFound, "404 page not found\n", nil},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+
+			// Act
+			rt.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tc.url, nil))
+
+			// Assert
+			require.Equal(t, tc.code, w.Code)
+			require.Equal(t, tc.body, w.Body.String())
+			require.Equal(t, tc.expected, w.Header().Values("X-Order"))
+		})
+	}
+
+	// Act
+	routes := rt.Routes()
+
+	// Assert
+	var paths []string
+	for _, info := range routes {
+		paths = append(paths, info.Path)
+	}
+	require.Equal(t, []string{
+		"/invoices",
+		"/billing",
+		"/billing/invoices/{id}",
+		"/admin/billing",
+		"/admin/billing/invoices/{id}",
+	}, paths)
+}
+
+func TestDefaultRouterMountInFlight(t *testing.T) {
+	// Arrange
+	rt := router.NewWithResponder("TESTING", middleware.NoopAdapter, nil, nil)
+	billing := router.NewWithResponder("TESTING", middleware.NoopAdapter, nil, nil)
+	rt.Mount("/billing", billing)
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	billing.Get("/invoices/{id}", func(w http.ResponseWriter, r *http.Request) {
+		started <- struct{}{}
+		<-release
+	})
+
+	done := make(chan struct{})
+	go func() {
+		rt.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/billing/invoices/1", nil))
+		close(done)
+	}()
+	<-started
+
+	// Act
+	during, duringRoutes := rt.InFlight(), rt.InFlightRoutes()
+	close(release)
+	<-done
+
+	// Assert
+	require.Equal(t, 1, during)
+	require.Equal(t, map[string]int{"GET /billing/invoices/{id}": 1}, duringRoutes)
+	require.Equal(t, 0, rt.InFlight())
+	require.Empty(t, rt.InFlightRoutes())
+}
@@ -0,0 +1,137 @@
+package router
+
+import (
+	"reflect"
+	"regexp"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/gorilla/mux"
+	"github.com/xy-planning-network/trails/http/middleware"
+)
+
+// closureSuffix matches the suffix the Go runtime names closures and method values with.
+var closureSuffix = regexp.MustCompile(`(\.func\d+)+$|-fm$`)
+
+// RouteInfo describes a Route registered on a Router.
+type RouteInfo struct {
+	// Handler names the function handling requests, e.g., "handler.(*Handler).ShowUser".
+	Handler string `json:"handler"`
+
+	// Host is the host template the Route is restricted to, if any.
+	Host string `json:"host,omitempty"`
+
+	// Methods are the HTTP methods the Route handles.
+	Methods []string `json:"methods"`
+
+	// Middlewares names the functions constructing each [middleware.Adapter] called before Handler,
+	// in order, e.g., "middleware.CurrentUser".
+	Middlewares []string `json:"middlewares"`
+
+	// Path is the path template the Route handles, including any Subrouter prefix.
+	Path string `json:"path"`
+}
+
+// A mount is a Router mounted under prefix on another.
+type mount struct {
+	app    Router
+	prefix string
+}
+
+// prefixed joins the prefix the Router is mounted under with path.
+func (m mount) prefixed(path string) string {
+	if path == "/" {
+		return m.prefix
+	}
+
+	return m.prefix + path
+}
+
+// A routeRegistry records the Routes registered on a Router and its Subrouters
+// and the Routers mounted on them.
+type routeRegistry struct {
+	infos  []RouteInfo
+	mounts []mount
+	mu     sync.RWMutex
+}
+
+// add records the Route registered as mr.
+func (rr *routeRegistry) add(mr *mux.Route, route Route, mws []middleware.Adapter) {
+	info := RouteInfo{
+		Handler: funcName(route.Handler),
+		Methods: []string{route.Method},
+		Path:    route.Path,
+	}
+
+	if tmpl, err := mr.GetPathTemplate(); err == nil {
+		info.Path = tmpl
+	}
+
+	if tmpl, err := mr.GetHostTemplate(); err == nil {
+		info.Host = tmpl
+	}
+
+	for _, mw := range mws {
+		info.Middlewares = append(info.Middlewares, funcName(mw))
+	}
+
+	rr.mu.Lock()
+	defer rr.mu.Unlock()
+
+	rr.infos = append(rr.infos, info)
+}
+
+// list copies the RouteInfos recorded, followed by those of the Routers mounted,
+// whose paths include the prefix they are mounted under.
+func (rr *routeRegistry) list() []RouteInfo {
+	rr.mu.RLock()
+	infos := slices.Clone(rr.infos)
+	rr.mu.RUnlock()
+
+	for _, m := range rr.mounted() {
+		for _, info := range m.app.Routes() {
+			info.Path = m.prefixed(info.Path)
+			infos = append(infos, info)
+		}
+	}
+
+	return infos
+}
+
+// mount records the Router mounted.
+func (rr *routeRegistry) mount(m mount) {
+	rr.mu.Lock()
+	defer rr.mu.Unlock()
+
+	rr.mounts = append(rr.mounts, m)
+}
+
+// mounted copies the Routers mounted.
+func (rr *routeRegistry) mounted() []mount {
+	rr.mu.RLock()
+	defer rr.mu.RUnlock()
+
+	return slices.Clone(rr.mounts)
+}
+
+// funcName names the function fn, trimming its package path and any closure suffix.
+func funcName(fn any) string {
+	v := reflect.ValueOf(fn)
+	if v.Kind() != reflect.Func || v.IsNil() {
+		return ""
+	}
+
+	f := runtime.FuncForPC(v.Pointer())
+	if f == nil {
+		return ""
+	}
+
+	name := f.Name()
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		name = name[i+1:]
+	}
+
+	return closureSuffix.ReplaceAllString(name, "")
+}
@@ -0,0 +1,177 @@
+package router
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/gorilla/mux"
+	"github.com/xy-planning-network/trails"
+	"github.com/xy-planning-network/trails/http/middleware"
+)
+
+const (
+	deprecationHeader = "Deprecation"
+	linkHeader        = "Link"
+)
+
+// APIVersion retrieves the version of the API a request is for,
+// set for Routes registered with Versioned; "" if there is none.
+func APIVersion(r *http.Request) string {
+	v, _ := trails.Value[string](r.Context(), trails.APIVersionKey)
+	return v
+}
+
+// Versioned registers each version of an API under prefix,
+// handling requests to prefix/version with the Routes for that version,
+// calling middlewares before each:
+//
+//	r.Versioned("/api", map[string][]router.Route{
+//		"v1": nil,
+//		"v2": v2Routes,
+//		"v3": v3Routes,
+//	})
+//
+// The version a request is for is stashed in the *http.Request.Context under trails.APIVersionKey,
+// retrieved with APIVersion; *resp.Responder sets it in the "API-Version" header of responses.
+//
+// Versions are ordered as people read them, comparing runs of digits numerically,
+// so "v10" is newer than "v9", as is "2024-06-01" than "2023-12-31".
+// Every version but the newest is deprecated: responses have a "Deprecation" header
+// and a "Link" header pointing to the newest version.
+//
+// A version with no Routes is retired: requests to it are responded to with 410 and
+// JSON in the same shape as *resp.Responder.Json:
+//
+//	{"data": {"error": "API version v1 is retired.", "latest": "v3", "version": "v1"}}
+func (r *DefaultRouter) Versioned(prefix string, versions map[string][]Route, middlewares ...middleware.Adapter) {
+	prefix = "/" + strings.Trim(prefix, "/")
+	if prefix == "/" {
+		prefix = ""
+	}
+
+	names := make([]string, 0, len(versions))
+	for v := range versions {
+		names = append(names, v)
+	}
+	slices.SortFunc(names, compareVersions)
+
+	var latest string
+	for _, v := range names {
+		if len(versions[v]) > 0 {
+			latest = v
+		}
+	}
+
+	for _, v := range names {
+		path := prefix + "/" + v
+		if len(versions[v]) == 0 {
+			r.retire(path, v, latest, prefix+"/"+latest)
+			continue
+		}
+
+		mws := []middleware.Adapter{injectAPIVersion(v)}
+		if v != latest {
+			mws = append(mws, deprecateAPIVersion(prefix+"/"+latest))
+		}
+
+		r.Subrouter(path, mws...).HandleRoutes(versions[v], middlewares...)
+	}
+}
+
+// retire responds to requests to path, or paths under it, with 410,
+// pointing to the newest version at successor.
+func (r *DefaultRouter) retire(path, version, latest, successor string) {
+	retired := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		data := map[string]string{
+			"error":   fmt.Sprintf("API version %s is retired.", version),
+			"version": version,
+		}
+		if latest != "" {
+			data["latest"] = latest
+			w.Header().Set(linkHeader, successorLink(successor))
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusGone)
+		json.NewEncoder(w).Encode(map[string]any{"data": data})
+	})
+
+	mr := r.Router.NewRoute().PathPrefix(path)
+	if tmpl, err := mr.GetPathTemplate(); err == nil {
+		path = tmpl
+	}
+
+	// NOTE: a path prefix matches "/api/v1foo" as well as "/api/v1/foo".
+	mr.MatcherFunc(func(req *http.Request, _ *mux.RouteMatch) bool {
+		return req.URL.Path == path || strings.HasPrefix(req.URL.Path, path+"/")
+	})
+
+	mr.Handler(middleware.Chain(retired, r.everyReqStack...))
+}
+
+// injectAPIVersion stashes v in the *http.Request.Context under trails.APIVersionKey.
+func injectAPIVersion(v string) middleware.Adapter {
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			*r = *r.Clone(trails.WithValue(r.Context(), trails.APIVersionKey, v))
+			h.ServeHTTP(w, r)
+		})
+	}
+}
+
+// deprecateAPIVersion marks responses as from a deprecated version of an API,
+// linking to the newest at successor.
+func deprecateAPIVersion(successor string) middleware.Adapter {
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set(deprecationHeader, "true")
+			w.Header().Set(linkHeader, successorLink(successor))
+			h.ServeHTTP(w, r)
+		})
+	}
+}
+
+// successorLink formats a "Link" header value pointing to the newest version of an API at path.
+func successorLink(path string) string {
+	return "<" + path + `>; rel="successor-version"`
+}
+
+// compareVersions orders a and b as people read them,
+// comparing runs of digits numerically and everything else lexically.
+func compareVersions(a, b string) int {
+	for a != "" && b != "" {
+		var x, y string
+		x, a = cutRun(a)
+		y, b = cutRun(b)
+
+		xn, xerr := strconv.ParseUint(x, 10, 64)
+		yn, yerr := strconv.ParseUint(y, 10, 64)
+		switch {
+		case xerr == nil && yerr == nil && xn != yn:
+			if xn < yn {
+				return -1
+			}
+			return 1
+		case x != y && (xerr != nil || yerr != nil):
+			return strings.Compare(x, y)
+		}
+	}
+
+	return strings.Compare(a, b)
+}
+
+// cutRun cuts the leading run of digits or non-digits from s.
+func cutRun(s string) (string, string) {
+	digits := unicode.IsDigit(rune(s[0]))
+	i := strings.IndexFunc(s, func(r rune) bool { return unicode.IsDigit(r) != digits })
+	if i < 0 {
+		return s, ""
+	}
+
+	return s[:i], s[i:]
+}
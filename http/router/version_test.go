@@ -0,0 +1,85 @@
+package router_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/xy-planning-network/trails/http/middleware"
+	"github.com/xy-planning-network/trails/http/resp"
+	"github.com/xy-planning-network/trails/http/router"
+)
+
+func TestDefaultRouterVersioned(t *testing.T) {
+	// Arrange
+	rt := router.NewWithResponder("TESTING", middleware.NoopAdapter, nil, nil)
+	d := resp.NewResponder()
+	respond := func(w http.ResponseWriter, r *http.Request) {
+		d.Json(w, r, resp.Data(router.APIVersion(r)))
+	}
+
+	rt.Versioned("/api", map[string][]router.Route{
+		"v1":  nil,
+		"v9":  {{Path: "/users", Method: http.MethodGet, Handler: respond}},
+		"v10": {{Path: "/users", Method: http.MethodGet, Handler: respond}},
+	})
+
+	for _, tc := range []struct {
+		name        string
+		path        string
+		code        int
+		version     string
+		deprecation string
+		link        string
+	}{
+		{"Latest", "/api/v10/users", http.StatusOK, "v10", "", ""},
+		{"Deprecated", "/api/v9/users", http.StatusOK, "v9", "true", `</api/v10>; rel="successor-version"`},
+		{"Retired", "/api/v1/users", http.StatusGone, "", "", `</api/v10>; rel="successor-version"`},
+		{"Retired-Root", "/api/v1", http.StatusGone, "", "", `</api/v10>; rel="successor-version"`},
+		{"Unknown", "/api/v2/users", http.StatusNotFound, "", "", ""},
+		{"Prefix-Only", "/api/v10users", http.StatusNotFound, "", "", ""},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+
+			// Act
+			rt.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tc.path, nil))
+
+			// Assert
+			require.Equal(t, tc.code, w.Code)
+			require.Equal(t, tc.version, w.Header().Get("API-Version"))
+			require.Equal(t, tc.deprecation, w.Header().Get("Deprecation"))
+			require.Equal(t, tc.link, w.Header().Get("Link"))
+
+			if tc.code == http.StatusOK {
+				require.JSONEq(t, `{"data":"`+tc.version+`"}`, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestDefaultRouterVersionedRetired(t *testing.T) {
+	// Arrange
+	rt := router.NewWithResponder("TESTING", middleware.NoopAdapter, nil, nil)
+	rt.Versioned("/api", map[string][]router.Route{
+		"2023-12-31": nil,
+		"2024-06-01": {{Path: "/users", Method: http.MethodGet, Handler: func(http.ResponseWriter, *http.Request) {}}},
+	})
+
+	w := httptest.NewRecorder()
+
+	// Act
+	rt.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/2023-12-31/users", nil))
+
+	// Assert
+	var body map[string]map[string]string
+	require.Equal(t, http.StatusGone, w.Code)
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&body))
+	require.Equal(t, map[string]string{
+		"error":   "API version 2023-12-31 is retired.",
+		"latest":  "2024-06-01",
+		"version": "2023-12-31",
+	}, body["data"])
+}
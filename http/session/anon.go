@@ -0,0 +1,175 @@
+package session
+
+import (
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/xy-planning-network/trails"
+)
+
+const (
+	// DefaultMaxAnonBytes is how many bytes of anonymous data a Session holds by default,
+	// leaving room in a 4KB session cookie for everything else.
+	DefaultMaxAnonBytes = 2048
+
+	// anonKey stashes the anonymous data in a session.
+	anonKey trails.Key = "SessionAnonKey"
+)
+
+func init() {
+	gob.Register(map[string]anonValue{})
+}
+
+// A MergeFunc transfers anonymous data to the user registering with a Session, e.g., saving a cart to their account,
+// receiving each unexpired value, JSON-encoded, by its name; cf. Config.MergeOnLogin.
+type MergeFunc func(w http.ResponseWriter, r *http.Request, userID uint, anon map[string]json.RawMessage) error
+
+// anonValue is a value stored with SetAnon.
+type anonValue struct {
+	// Data is the JSON-encoded value.
+	Data []byte
+
+	// Expires is when the value expires, in Unix milliseconds, or zero if it does not.
+	Expires int64
+}
+
+// anonOptions configures how a Session stores anonymous data.
+type anonOptions struct {
+	maxBytes int
+	merge    MergeFunc
+}
+
+// SetAnon stores val, encoded as JSON, under name in the Session and saves it,
+// e.g., onboarding progress or the state of a signup funnel, whether or not a user is registered with the Session.
+//
+// The value expires after ttl, independent of the Session's lifetime;
+// if ttl is zero, it lasts as long as the Session does.
+//
+// If storing val would make the anonymous data in the Session larger than Config.MaxAnonBytes,
+// SetAnon returns ErrTooLarge.
+func SetAnon[T any](s Session, w http.ResponseWriter, r *http.Request, name string, val T, ttl time.Duration) error {
+	b, err := json.Marshal(val)
+	if err != nil {
+		return fmt.Errorf("%w: %s: %s", ErrNotValid, name, err)
+	}
+
+	now := time.Now()
+	vals := s.anonValues(now)
+
+	v := anonValue{Data: b}
+	if ttl > 0 {
+		v.Expires = now.Add(ttl).UnixMilli()
+	}
+
+	size := anonSize(vals) - anonValueSize(name, vals[name]) + anonValueSize(name, v)
+	if limit := s.maxAnonBytes(); size > limit {
+		return fmt.Errorf("%w: anonymous data would be %d bytes, more than %d", ErrTooLarge, size, limit)
+	}
+
+	vals[name] = v
+	s.s.Values[anonKey] = vals
+
+	return s.Save(w, r)
+}
+
+// GetAnon retrieves the value stored under name with SetAnon as a T.
+//
+// If no value is stored under name or it has expired, ErrNoValue is returned.
+// If the value stored cannot be decoded as a T, ErrNotValid is returned.
+func GetAnon[T any](s Session, name string) (T, error) {
+	var zero T
+	v, ok := s.anonValues(time.Now())[name]
+	if !ok {
+		return zero, fmt.Errorf("%w: %s", ErrNoValue, name)
+	}
+
+	var val T
+	if err := json.Unmarshal(v.Data, &val); err != nil {
+		return zero, fmt.Errorf("%w: %s is not %T: %s", ErrNotValid, name, zero, err)
+	}
+
+	return val, nil
+}
+
+// AnonSize returns how many bytes the unexpired anonymous data in the Session takes up.
+func (s Session) AnonSize() int {
+	return anonSize(s.anonValues(time.Now()))
+}
+
+// DeleteAnon removes the value stored under name with SetAnon from the Session and saves it.
+func (s Session) DeleteAnon(w http.ResponseWriter, r *http.Request, name string) error {
+	vals := s.anonValues(time.Now())
+	delete(vals, name)
+	s.s.Values[anonKey] = vals
+
+	return s.Save(w, r)
+}
+
+// mergeAnon calls the MergeFunc configured, if any, with the unexpired anonymous data in the Session,
+// removing the data once transferred.
+func (s Session) mergeAnon(w http.ResponseWriter, r *http.Request, userID uint) error {
+	vals := s.anonValues(time.Now())
+	if s.anon.merge == nil || len(vals) == 0 {
+		return nil
+	}
+
+	anon := make(map[string]json.RawMessage, len(vals))
+	for name, v := range vals {
+		anon[name] = v.Data
+	}
+
+	if err := s.anon.merge(w, r, userID, anon); err != nil {
+		return fmt.Errorf("could not merge anonymous data: %w", err)
+	}
+
+	delete(s.s.Values, anonKey)
+	return nil
+}
+
+// anonValues retrieves the anonymous data in the Session unexpired at now.
+func (s Session) anonValues(now time.Time) map[string]anonValue {
+	stored, _ := s.s.Values[anonKey].(map[string]anonValue)
+
+	vals := make(map[string]anonValue, len(stored))
+	for name, v := range stored {
+		if v.Expires != 0 && now.UnixMilli() >= v.Expires {
+			continue
+		}
+
+		vals[name] = v
+	}
+
+	return vals
+}
+
+// maxAnonBytes is how many bytes of anonymous data the Session holds.
+func (s Session) maxAnonBytes() int {
+	if s.anon.maxBytes > 0 {
+		return s.anon.maxBytes
+	}
+
+	return DefaultMaxAnonBytes
+}
+
+// anonSize approximates how many bytes vals take up in a session cookie.
+func anonSize(vals map[string]anonValue) int {
+	var n int
+	for name, v := range vals {
+		n += anonValueSize(name, v)
+	}
+
+	return n
+}
+
+// anonValueSize approximates how many bytes v, stored under name, takes up in a session cookie.
+func anonValueSize(name string, v anonValue) int {
+	if v.Data == nil {
+		return 0
+	}
+
+	// NOTE: 8 bytes accounts for the expiry.
+	return len(name) + len(v.Data) + 8
+}
@@ -0,0 +1,132 @@
+package session_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/xy-planning-network/trails"
+	"github.com/xy-planning-network/trails/http/session"
+)
+
+type funnel struct {
+	Plan string
+	Step int
+}
+
+func TestAnon(t *testing.T) {
+	// Arrange
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "https://example.com", nil)
+	s, err := session.NewStub(false).GetSession(r)
+	require.Nil(t, err)
+
+	// Act
+	_, err = session.GetAnon[funnel](s, "signup")
+
+	// Assert
+	require.ErrorIs(t, err, session.ErrNoValue)
+
+	// Act
+	err = session.SetAnon(s, w, r, "signup", funnel{Plan: "pro", Step: 2}, 0)
+	actual, getErr := session.GetAnon[funnel](s, "signup")
+
+	// Assert
+	require.Nil(t, err)
+	require.Nil(t, getErr)
+	require.Equal(t, funnel{Plan: "pro", Step: 2}, actual)
+	require.NotZero(t, s.AnonSize())
+
+	// Act
+	_, err = session.GetAnon[int](s, "signup")
+
+	// Assert
+	require.ErrorIs(t, err, session.ErrNotValid)
+
+	// Act
+	err = session.SetAnon(s, w, r, "welcome", true, time.Millisecond)
+	require.Nil(t, err)
+	time.Sleep(2 * time.Millisecond)
+	_, err = session.GetAnon[bool](s, "welcome")
+
+	// Assert
+	require.ErrorIs(t, err, session.ErrNoValue)
+
+	// Act
+	err = session.SetAnon(s, w, r, "huge", strings.Repeat("a", session.DefaultMaxAnonBytes), 0)
+
+	// Assert
+	require.ErrorIs(t, err, session.ErrTooLarge)
+
+	// Act
+	err = s.DeleteAnon(w, r, "signup")
+	_, getErr = session.GetAnon[funnel](s, "signup")
+
+	// Assert
+	require.Nil(t, err)
+	require.ErrorIs(t, getErr, session.ErrNoValue)
+	require.Zero(t, s.AnonSize())
+}
+
+func TestMergeOnLogin(t *testing.T) {
+	var merged map[string]json.RawMessage
+	var fail bool
+	svc, err := session.NewStoreService(session.Config{
+		Env:         trails.Testing,
+		SessionName: "Test",
+		AuthKey:     "ABCD",
+		EncryptKey:  "ABCD",
+		MergeOnLogin: func(_ http.ResponseWriter, _ *http.Request, userID uint, anon map[string]json.RawMessage) error {
+			if fail {
+				return errors.New("db is down")
+			}
+
+			require.Equal(t, uint(42), userID)
+			merged = anon
+			return nil
+		},
+	})
+	require.Nil(t, err)
+
+	t.Run("Failure", func(t *testing.T) {
+		// Arrange
+		fail = true
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "https://example.com", nil)
+		s, err := svc.GetSession(r)
+		require.Nil(t, err)
+		require.Nil(t, session.SetAnon(s, w, r, "cart", []int{1, 2}, time.Hour))
+
+		// Act
+		err = s.RegisterUser(w, r, 42)
+
+		// Assert
+		require.ErrorContains(t, err, "db is down")
+		_, err = s.UserID()
+		require.ErrorIs(t, err, session.ErrNoUser)
+	})
+
+	t.Run("Success", func(t *testing.T) {
+		// Arrange
+		fail = false
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "https://example.com", nil)
+		s, err := svc.GetSession(r)
+		require.Nil(t, err)
+		require.Nil(t, session.SetAnon(s, w, r, "cart", []int{1, 2}, time.Hour))
+
+		// Act
+		err = s.RegisterUser(w, r, 42)
+
+		// Assert
+		require.Nil(t, err)
+		require.JSONEq(t, `[1,2]`, string(merged["cart"]))
+		_, err = session.GetAnon[[]int](s, "cart")
+		require.ErrorIs(t, err, session.ErrNoValue)
+	})
+}
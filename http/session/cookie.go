@@ -0,0 +1,143 @@
+package session
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gorilla/securecookie"
+	gorilla "github.com/gorilla/sessions"
+	"github.com/xy-planning-network/trails"
+)
+
+// cookieOptions configures the cookie sessions are stored in.
+type cookieOptions struct {
+	gorilla.Options
+
+	// partitioned stores the cookie apart for each site embedding the application;
+	// cf. https://developer.mozilla.org/en-US/docs/Web/Privacy/Privacy_sandbox/Partitioned_cookies.
+	partitioned bool
+}
+
+// validate asserts cookies are Secure when browsers reject them otherwise.
+func (o cookieOptions) validate() error {
+	if o.Secure {
+		return nil
+	}
+
+	if o.SameSite == http.SameSiteNoneMode {
+		return fmt.Errorf("%w: cookies with SameSite=None must be Secure", trails.ErrBadConfig)
+	}
+
+	if o.partitioned {
+		return fmt.Errorf("%w: Partitioned cookies must be Secure", trails.ErrBadConfig)
+	}
+
+	return nil
+}
+
+// WithCookieDomain overrides Config.Domain, setting the domain of the session cookie.
+func WithCookieDomain(domain string) ServiceOpt {
+	return func(s *Service) error {
+		s.cookie.Domain = domain
+		return nil
+	}
+}
+
+// WithCookiePath overrides Config.Path, setting the path of the session cookie.
+func WithCookiePath(path string) ServiceOpt {
+	return func(s *Service) error {
+		s.cookie.Path = path
+		return nil
+	}
+}
+
+// WithSameSite overrides Config.SameSiteMode, setting the SameSite mode of the session cookie.
+//
+// http.SameSiteNoneMode makes the cookie Secure, too.
+func WithSameSite(mode http.SameSite) ServiceOpt {
+	return func(s *Service) error {
+		s.cookie.SameSite = mode
+		s.cookie.Secure = s.cookie.Secure || mode == http.SameSiteNoneMode
+		return nil
+	}
+}
+
+// WithSecure sets whether the session cookie is only sent over HTTPS,
+// overriding the default of being Secure outside the development and testing environments.
+//
+// Browsers reject cookies with SameSite=None or that are Partitioned unless they are Secure,
+// so NewStoreService fails with trails.ErrBadConfig when secure is false for them.
+func WithSecure(secure bool) ServiceOpt {
+	return func(s *Service) error {
+		s.cookie.Secure = secure
+		return nil
+	}
+}
+
+// WithPartitioned overrides Config.Partitioned, making the session cookie Partitioned and Secure.
+func WithPartitioned() ServiceOpt {
+	return func(s *Service) error {
+		s.cookie.partitioned = true
+		s.cookie.Secure = true
+		return nil
+	}
+}
+
+// WithCrossSiteEmbedding has the session cookie work while the application is embedded in another site,
+// e.g., in an iframe, setting it SameSite=None, Secure, and Partitioned,
+// so the application has its own session in each site embedding it.
+func WithCrossSiteEmbedding() ServiceOpt {
+	return func(s *Service) error {
+		s.cookie.SameSite = http.SameSiteNoneMode
+		s.cookie.Secure = true
+		s.cookie.partitioned = true
+		return nil
+	}
+}
+
+// A cookieStore is a gorilla.CookieStore setting its cookies Partitioned,
+// which gorilla does not support.
+type cookieStore struct {
+	*gorilla.CookieStore
+}
+
+// Get retrieves the session named name for r, decoding it once per request.
+func (s *cookieStore) Get(r *http.Request, name string) (*gorilla.Session, error) {
+	return gorilla.GetRegistry(r).Get(s, name)
+}
+
+// New decodes the session named name for r, or constructs a brand new one.
+//
+// NOTE: the same as gorilla.CookieStore.New, saving the session through s.
+func (s *cookieStore) New(r *http.Request, name string) (*gorilla.Session, error) {
+	session := gorilla.NewSession(s, name)
+	opts := *s.Options
+	session.Options = &opts
+	session.IsNew = true
+
+	c, err := r.Cookie(name)
+	if err != nil {
+		return session, nil
+	}
+
+	err = securecookie.DecodeMulti(name, c.Value, &session.Values, s.Codecs...)
+	if err == nil {
+		session.IsNew = false
+	}
+
+	return session, err
+}
+
+// Save sets the Partitioned cookie session is stored in on w.
+func (s *cookieStore) Save(_ *http.Request, w http.ResponseWriter, session *gorilla.Session) error {
+	encoded, err := securecookie.EncodeMulti(session.Name(), session.Values, s.Codecs...)
+	if err != nil {
+		return err
+	}
+
+	c := gorilla.NewCookie(session.Name(), encoded, session.Options)
+	c.Partitioned = true
+	http.SetCookie(w, c)
+
+	return nil
+}
@@ -0,0 +1,119 @@
+package session_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/xy-planning-network/trails"
+	"github.com/xy-planning-network/trails/http/session"
+)
+
+func TestServiceCookie(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		cfg      session.Config
+		opts     []session.ServiceOpt
+		expected http.Cookie
+	}{
+		{
+			"Default",
+			session.Config{SameSiteMode: http.SameSiteLaxMode},
+			nil,
+			http.Cookie{Path: "/", SameSite: http.SameSiteLaxMode},
+		},
+		{
+			"Config",
+			session.Config{Domain: "example.com", Partitioned: true, Path: "/app", SameSiteMode: http.SameSiteStrictMode},
+			nil,
+			http.Cookie{Domain: "example.com", Partitioned: true, Path: "/app", SameSite: http.SameSiteStrictMode, Secure: true},
+		},
+		{
+			"Overridden",
+			session.Config{Domain: "example.com", Path: "/app", SameSiteMode: http.SameSiteStrictMode},
+			[]session.ServiceOpt{
+				session.WithCookieDomain("embed.example.com"),
+				session.WithCookiePath("/embed"),
+				session.WithSameSite(http.SameSiteLaxMode),
+				session.WithSecure(true),
+			},
+			http.Cookie{Domain: "embed.example.com", Path: "/embed", SameSite: http.SameSiteLaxMode, Secure: true},
+		},
+		{
+			"Cross-Site-Embedding",
+			session.Config{SameSiteMode: http.SameSiteLaxMode},
+			[]session.ServiceOpt{session.WithCrossSiteEmbedding()},
+			http.Cookie{Partitioned: true, Path: "/", SameSite: http.SameSiteNoneMode, Secure: true},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			tc.cfg.Env = trails.Testing
+			tc.cfg.SessionName = "Test"
+			tc.cfg.AuthKey = "ABCD"
+			tc.cfg.EncryptKey = "ABCD"
+
+			svc, err := session.NewStoreService(tc.cfg, tc.opts...)
+			require.NoError(t, err)
+
+			r := httptest.NewRequest(http.MethodGet, "https://example.com", nil)
+			s, err := svc.GetSession(r)
+			require.NoError(t, err)
+
+			w := httptest.NewRecorder()
+
+			// Act
+			require.NoError(t, s.Save(w, r))
+
+			// Assert
+			cookies := w.Result().Cookies()
+			require.Len(t, cookies, 1)
+
+			actual := cookies[0]
+			require.Equal(t, tc.expected.Domain, actual.Domain)
+			require.True(t, actual.HttpOnly)
+			require.Equal(t, tc.expected.Partitioned, actual.Partitioned)
+			require.Equal(t, tc.expected.Path, actual.Path)
+			require.Equal(t, tc.expected.SameSite, actual.SameSite)
+			require.Equal(t, tc.expected.Secure, actual.Secure)
+
+			// Arrange
+			r = httptest.NewRequest(http.MethodGet, "https://example.com", nil)
+			r.AddCookie(actual)
+
+			// Act
+			s, err = svc.GetSession(r)
+
+			// Assert
+			require.NoError(t, err)
+			require.NotEmpty(t, s.Get(trails.SessionIDKey))
+		})
+	}
+}
+
+func TestServiceCookieInsecure(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		cfg  session.Config
+		err  error
+	}{
+		{"SameSite-None", session.Config{SameSiteMode: http.SameSiteNoneMode}, trails.ErrBadConfig},
+		{"Partitioned", session.Config{Partitioned: true}, trails.ErrBadConfig},
+		{"Lax", session.Config{SameSiteMode: http.SameSiteLaxMode}, nil},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			tc.cfg.Env = trails.Production
+			tc.cfg.SessionName = "Test"
+			tc.cfg.AuthKey = "ABCD"
+			tc.cfg.EncryptKey = "ABCD"
+
+			// Act
+			_, err := session.NewStoreService(tc.cfg, session.WithSecure(false))
+
+			// Assert
+			require.ErrorIs(t, err, tc.err)
+		})
+	}
+}
@@ -3,6 +3,9 @@ package session
 import "errors"
 
 var (
-	ErrNotValid = errors.New("not valid")
-	ErrNoUser   = errors.New("no user")
+	ErrNotImpersonating = errors.New("not impersonating")
+	ErrNotValid         = errors.New("not valid")
+	ErrNoUser           = errors.New("no user")
+	ErrNoValue          = errors.New("no value")
+	ErrTooLarge         = errors.New("too large")
 )
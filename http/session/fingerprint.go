@@ -0,0 +1,145 @@
+package session
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"net"
+	"net/http"
+	"net/netip"
+
+	"github.com/google/uuid"
+	"github.com/xy-planning-network/trails"
+)
+
+// fingerprintKey stashes the fingerprint of the client a user registered with a session from.
+const fingerprintKey trails.Key = "SessionFingerprintKey"
+
+// A Fingerprint selects the traits of a client a session is bound to once a user registers with it;
+// cf. Config.Fingerprint.
+//
+// Combine Fingerprints with |, e.g., FingerprintIPPrefix | FingerprintUserAgent.
+type Fingerprint uint8
+
+const (
+	// FingerprintIPPrefix binds a session to the network a client connects from:
+	// the /24 of an IPv4 address or the /64 of an IPv6 address,
+	// so clients moving between addresses their ISP assigns them keep their sessions.
+	FingerprintIPPrefix Fingerprint = 1 << iota
+
+	// FingerprintUserAgent binds a session to the User-Agent header a client sends.
+	FingerprintUserAgent
+)
+
+// A FingerprintStrictness determines what happens to a session used by a client
+// whose fingerprint does not match the one the session is bound to.
+type FingerprintStrictness int
+
+const (
+	// FingerprintStrict replaces the session with a brand new one,
+	// recording a [logger.AuditSessionFingerprintMismatch] event.
+	FingerprintStrict FingerprintStrictness = iota
+
+	// FingerprintLenient keeps the session,
+	// only recording a [logger.AuditSessionFingerprintMismatch] event.
+	FingerprintLenient
+)
+
+// A binding determines how sessions are bound to the client using them.
+type binding struct {
+	fingerprint Fingerprint
+	strictness  FingerprintStrictness
+}
+
+// mismatched asserts whether the client making r does not match
+// the fingerprint stored in the session values.
+//
+// Session values without a fingerprint never mismatch,
+// e.g., those of a session binding was turned on after a user registered with it.
+func (b binding) mismatched(vals map[any]any, r *http.Request) bool {
+	stored, ok := vals[fingerprintKey].(string)
+	if !ok || b.fingerprint == 0 {
+		return false
+	}
+
+	return subtle.ConstantTimeCompare([]byte(stored), []byte(b.of(r))) != 1
+}
+
+// stamp stores the fingerprint of the client making r in the session values.
+func (b binding) stamp(vals map[any]any, r *http.Request) {
+	if b.fingerprint == 0 {
+		return
+	}
+
+	vals[fingerprintKey] = b.of(r)
+}
+
+// of hashes the traits of the client making r the binding selects.
+func (b binding) of(r *http.Request) string {
+	h := sha256.New()
+	if b.fingerprint&FingerprintIPPrefix != 0 {
+		h.Write([]byte(ipPrefix(r)))
+	}
+
+	h.Write([]byte{0})
+
+	if b.fingerprint&FingerprintUserAgent != 0 {
+		h.Write([]byte(r.UserAgent()))
+	}
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// ipPrefix masks the IP address of the client making r to the network it belongs to.
+//
+// ipPrefix prefers the IP address stashed under trails.IpAddrKey, falling back to *http.Request.RemoteAddr.
+// An address that cannot be parsed is returned as is.
+func ipPrefix(r *http.Request) string {
+	ip, _ := trails.Value[string](r.Context(), trails.IpAddrKey)
+	if ip == "" {
+		ip = r.RemoteAddr
+		if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+			ip = host
+		}
+	}
+
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return ip
+	}
+
+	bits := 64
+	if addr.Unmap().Is4() {
+		addr, bits = addr.Unmap(), 24
+	}
+
+	prefix, err := addr.Prefix(bits)
+	if err != nil {
+		return ip
+	}
+
+	return prefix.String()
+}
+
+// Rotate issues the Session a new ID and drops its CSRF token, keeping the rest of its data, and saves it,
+// so an ID or token learned before is of no use after, e.g., once a user confirms their password
+// or a second factor to elevate their privileges.
+//
+// RegisterUser, StartImpersonation, and StopImpersonation rotate the Session themselves.
+//
+// Templates rendered by the same request still use the CSRF token dropped;
+// respond with a redirect so the next request generates a new one.
+func (s Session) Rotate(w http.ResponseWriter, r *http.Request) error {
+	s.rotate(r)
+	return s.Save(w, r)
+}
+
+// rotate issues the Session a new ID, drops its CSRF token,
+// and binds it to the client making r.
+func (s Session) rotate(r *http.Request) {
+	// NOTE: stores keeping sessions server-side issue a new ID for a session without one.
+	s.s.ID = ""
+	s.s.Values[trails.SessionIDKey] = uuid.NewString()
+	delete(s.s.Values, trails.CSRFTokenKey)
+	s.binding.stamp(s.s.Values, r)
+}
@@ -0,0 +1,165 @@
+package session_test
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/xy-planning-network/trails"
+	"github.com/xy-planning-network/trails/http/session"
+	"github.com/xy-planning-network/trails/logger"
+)
+
+func TestSessionRotate(t *testing.T) {
+	// Arrange
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "https://example.com", nil)
+	s, _ := session.NewStub(false).GetSession(r)
+
+	require.Nil(t, s.Set(w, r, trails.SessionIDKey, "fixed"))
+	require.Nil(t, s.SetCSRFToken(w, r, "known"))
+
+	// Act
+	err := s.RegisterUser(w, r, 1)
+
+	// Assert
+	require.Nil(t, err)
+
+	id, err := session.Get[string](s, trails.SessionIDKey)
+	require.Nil(t, err)
+	require.NotEqual(t, "fixed", id)
+
+	_, err = s.CSRFToken()
+	require.ErrorIs(t, err, session.ErrNoValue)
+
+	// Arrange
+	require.Nil(t, s.SetCSRFToken(w, r, "known"))
+
+	// Act
+	err = s.Rotate(w, r)
+
+	// Assert
+	require.Nil(t, err)
+
+	rotated, err := session.Get[string](s, trails.SessionIDKey)
+	require.Nil(t, err)
+	require.NotEqual(t, id, rotated)
+
+	_, err = s.CSRFToken()
+	require.ErrorIs(t, err, session.ErrNoValue)
+
+	uid, err := s.UserID()
+	require.Nil(t, err)
+	require.Equal(t, uint(1), uid)
+}
+
+func TestServiceFingerprint(t *testing.T) {
+	base := session.Config{
+		Env:         trails.Testing,
+		SessionName: "Test",
+		AuthKey:     "ABCD",
+		EncryptKey:  "ABCD",
+		MaxAge:      3600,
+		Fingerprint: session.FingerprintIPPrefix | session.FingerprintUserAgent,
+	}
+
+	// request makes a request from ip with the user agent ua.
+	request := func(ip, ua string) *http.Request {
+		r := httptest.NewRequest(http.MethodGet, "https://example.com", nil)
+		r.Header.Set("User-Agent", ua)
+		return r.WithContext(context.WithValue(r.Context(), trails.IpAddrKey, ip))
+	}
+
+	for _, tc := range []struct {
+		name       string
+		strictness session.FingerprintStrictness
+		from       string
+		ip         string
+		ua         string
+		loggedIn   bool
+		audited    bool
+	}{
+		{"Same-Client", session.FingerprintStrict, "203.0.113.7", "203.0.113.7", "Firefox", true, false},
+		{"Same-Network", session.FingerprintStrict, "203.0.113.7", "203.0.113.200", "Firefox", true, false},
+		{"Same-IPv6-Network", session.FingerprintStrict, "2001:db8::ffff", "2001:db8::1", "Firefox", true, false},
+		{"Other-Network", session.FingerprintStrict, "203.0.113.7", "198.51.100.7", "Firefox", false, true},
+		{"Other-User-Agent", session.FingerprintStrict, "203.0.113.7", "203.0.113.7", "curl", false, true},
+		{"Lenient", session.FingerprintLenient, "203.0.113.7", "198.51.100.7", "curl", true, true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			b := new(bytes.Buffer)
+			logger.SetDefaultAuditLogger(logger.NewAuditLogger(slog.NewJSONHandler(b, nil), logger.AuditConfig{}))
+			t.Cleanup(func() { logger.SetDefaultAuditLogger(nil) })
+
+			cfg := base
+			cfg.FingerprintStrictness = tc.strictness
+			svc, err := session.NewStoreService(cfg)
+			require.Nil(t, err)
+
+			w := httptest.NewRecorder()
+			r := request(tc.from, "Firefox")
+			s, err := svc.GetSession(r)
+			require.Nil(t, err)
+			require.Nil(t, s.RegisterUser(w, r, 1))
+
+			cookies := w.Result().Cookies()
+			require.Len(t, cookies, 1)
+
+			b.Reset()
+			r = request(tc.ip, tc.ua)
+			r.AddCookie(cookies[0])
+
+			// Act
+			s, err = svc.GetSession(r)
+
+			// Assert
+			require.Nil(t, err)
+
+			_, err = s.UserID()
+			if tc.loggedIn {
+				require.Nil(t, err)
+			} else {
+				require.ErrorIs(t, err, session.ErrNoUser)
+			}
+
+			if tc.audited {
+				require.Contains(t, b.String(), string(logger.AuditSessionFingerprintMismatch))
+			} else {
+				require.Zero(t, b.Len())
+			}
+		})
+	}
+
+	t.Run("Logged-Out", func(t *testing.T) {
+		// Arrange
+		svc, err := session.NewStoreService(base)
+		require.Nil(t, err)
+
+		w := httptest.NewRecorder()
+		r := request("203.0.113.7", "Firefox")
+		s, err := svc.GetSession(r)
+		require.Nil(t, err)
+		require.Nil(t, s.RegisterUser(w, r, 1))
+		require.Nil(t, s.DeregisterUser(w, r))
+		require.Nil(t, s.Set(w, r, trails.ReturnToKey, "/next"))
+
+		cookies := w.Result().Cookies()
+		r = request("198.51.100.7", "curl")
+		r.AddCookie(cookies[len(cookies)-1])
+
+		// Act
+		s, err = svc.GetSession(r)
+
+		// Assert
+		require.Nil(t, err)
+
+		returnTo, err := s.ReturnTo()
+		require.Nil(t, err)
+		require.Equal(t, "/next", returnTo)
+	})
+}
@@ -18,10 +18,34 @@ const (
 
 var ContactUsErr = DefaultErrMsg + " Please contact us at %s if the issue persists."
 
+const (
+	// DefaultMaxFlashes is how many Flashes a Session holds by default, evicting the oldest past it.
+	DefaultMaxFlashes = 10
+
+	// flashesKey is the key a gorilla.Session stores flashes under by default.
+	flashesKey = "_flash"
+)
+
 // A Flash is a structured message set in a session.
 type Flash struct {
-	Type string `json:"type"`
-	Msg  string `json:"message"`
+	// Type is the severity of the Flash, e.g., FlashError.
+	Type string `json:"type" xml:"type"`
+
+	// Msg is the body of the Flash.
+	Msg string `json:"message" xml:"message"`
+
+	// Title headlines the Flash, if set.
+	Title string `json:"title,omitempty" xml:"title,omitempty"`
+
+	// Detail elaborates on Msg, e.g., listing what went wrong, if set.
+	Detail string `json:"detail,omitempty" xml:"detail,omitempty"`
+
+	// Dismissible is whether the user may close the Flash.
+	Dismissible bool `json:"dismissible,omitempty" xml:"dismissible,omitempty"`
+
+	// ActionURL links to where the user may act on the Flash, labeled by ActionLabel, if set.
+	ActionURL   string `json:"actionUrl,omitempty" xml:"actionUrl,omitempty"`
+	ActionLabel string `json:"actionLabel,omitempty" xml:"actionLabel,omitempty"`
 }
 
 func (f Flash) GetClass() string {
@@ -0,0 +1,95 @@
+package session
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/xy-planning-network/trails"
+	"github.com/xy-planning-network/trails/logger"
+)
+
+// impersonatorKey stashes the ID of the user impersonating the current user.
+const impersonatorKey trails.Key = "SessionImpersonatorKey"
+
+// ImpersonatorID gets the ID of the user impersonating the user whose ID UserID returns.
+// If no user is being impersonated, ErrNotImpersonating is returned.
+func (s Session) ImpersonatorID() (uint, error) {
+	id, err := Get[uint](s, impersonatorKey)
+	if errors.Is(err, ErrNoValue) {
+		return 0, ErrNotImpersonating
+	}
+
+	if err != nil {
+		return 0, ErrNotValid
+	}
+
+	return id, nil
+}
+
+// StartImpersonation swaps the user in the session for the user identified by ID,
+// remembering the user who started impersonating, e.g., a support admin.
+//
+// Starting another impersonation while impersonating keeps the original impersonator.
+// StartImpersonation rotates the session; cf. Rotate.
+//
+// If no user is registered in the session, ErrNoUser is returned.
+// Authorizing who may impersonate whom is up to the calling code.
+//
+// StartImpersonation records a [logger.AuditImpersonationStart] event.
+func (s Session) StartImpersonation(w http.ResponseWriter, r *http.Request, ID uint) error {
+	impersonator, err := s.ImpersonatorID()
+	if errors.Is(err, ErrNotImpersonating) {
+		impersonator, err = s.UserID()
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if impersonator == ID {
+		err = fmt.Errorf("%w: cannot impersonate oneself", ErrNotValid)
+	} else {
+		s.rotate(r)
+		s.s.Values[impersonatorKey] = impersonator
+		s.s.Values[trails.CurrentUserKey] = ID
+		err = s.Save(w, r)
+	}
+
+	audit(logger.AuditEvent{
+		Action:   logger.AuditImpersonationStart,
+		ActorID:  impersonator,
+		Request:  r,
+		TargetID: ID,
+	}, err)
+
+	return err
+}
+
+// StopImpersonation restores the user who started impersonating as the user in the session,
+// rotating the session; cf. Rotate.
+//
+// If no user is being impersonated, ErrNotImpersonating is returned.
+//
+// StopImpersonation records a [logger.AuditImpersonationStop] event.
+func (s Session) StopImpersonation(w http.ResponseWriter, r *http.Request) error {
+	impersonator, err := s.ImpersonatorID()
+	if err != nil {
+		return err
+	}
+
+	impersonated, _ := s.UserID()
+	s.rotate(r)
+	s.s.Values[trails.CurrentUserKey] = impersonator
+	delete(s.s.Values, impersonatorKey)
+	err = s.Save(w, r)
+
+	audit(logger.AuditEvent{
+		Action:   logger.AuditImpersonationStop,
+		ActorID:  impersonator,
+		Request:  r,
+		TargetID: impersonated,
+	}, err)
+
+	return err
+}
@@ -0,0 +1,133 @@
+package session_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/xy-planning-network/trails/http/session"
+	"github.com/xy-planning-network/trails/logger"
+)
+
+func TestSessionImpersonation(t *testing.T) {
+	// Arrange
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "https://example.com", nil)
+	s, _ := session.NewStub(false).GetSession(r)
+
+	// Act
+	err := s.StartImpersonation(w, r, 2)
+
+	// Assert
+	require.ErrorIs(t, err, session.ErrNoUser)
+
+	_, err = s.ImpersonatorID()
+	require.ErrorIs(t, err, session.ErrNotImpersonating)
+	require.ErrorIs(t, s.StopImpersonation(w, r), session.ErrNotImpersonating)
+
+	// Arrange
+	require.Nil(t, s.RegisterUser(w, r, 1))
+
+	// Act
+	err = s.StartImpersonation(w, r, 1)
+
+	// Assert
+	require.ErrorIs(t, err, session.ErrNotValid)
+
+	// Act
+	err = s.StartImpersonation(w, r, 2)
+
+	// Assert
+	require.Nil(t, err)
+
+	uid, err := s.UserID()
+	require.Nil(t, err)
+	require.Equal(t, uint(2), uid)
+
+	impersonator, err := s.ImpersonatorID()
+	require.Nil(t, err)
+	require.Equal(t, uint(1), impersonator)
+
+	// Act
+	err = s.StartImpersonation(w, r, 3)
+
+	// Assert
+	require.Nil(t, err)
+
+	impersonator, err = s.ImpersonatorID()
+	require.Nil(t, err)
+	require.Equal(t, uint(1), impersonator)
+
+	// Act
+	err = s.StopImpersonation(w, r)
+
+	// Assert
+	require.Nil(t, err)
+
+	uid, err = s.UserID()
+	require.Nil(t, err)
+	require.Equal(t, uint(1), uid)
+
+	_, err = s.ImpersonatorID()
+	require.ErrorIs(t, err, session.ErrNotImpersonating)
+
+	// Arrange
+	require.Nil(t, s.StartImpersonation(w, r, 2))
+
+	// Act
+	err = s.DeregisterUser(w, r)
+
+	// Assert
+	require.Nil(t, err)
+
+	_, err = s.ImpersonatorID()
+	require.ErrorIs(t, err, session.ErrNotImpersonating)
+}
+
+func TestSessionImpersonationAudit(t *testing.T) {
+	// Arrange
+	b := new(bytes.Buffer)
+	logger.SetDefaultAuditLogger(logger.NewAuditLogger(slog.NewJSONHandler(b, nil), logger.AuditConfig{}))
+	t.Cleanup(func() { logger.SetDefaultAuditLogger(nil) })
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "https://example.com", nil)
+	s, _ := session.NewStub(false).GetSession(r)
+
+	// Act
+	require.Nil(t, s.RegisterUser(w, r, 1))
+	require.ErrorIs(t, s.StartImpersonation(w, r, 1), session.ErrNotValid)
+	require.Nil(t, s.StartImpersonation(w, r, 2))
+	require.Nil(t, s.StopImpersonation(w, r))
+	require.Nil(t, s.DeregisterUser(w, r))
+
+	// Assert
+	type event struct {
+		Action   string
+		ActorID  uint `json:"actorId"`
+		Outcome  string
+		TargetID uint `json:"targetId"`
+	}
+
+	expected := []event{
+		{"session.login", 1, "success", 0},
+		{"impersonation.start", 1, "failure", 1},
+		{"impersonation.start", 1, "success", 2},
+		{"impersonation.stop", 1, "success", 2},
+		{"session.logout", 1, "success", 0},
+	}
+
+	var actual []event
+	dec := json.NewDecoder(b)
+	for dec.More() {
+		var e event
+		require.NoError(t, dec.Decode(&e))
+		actual = append(actual, e)
+	}
+
+	require.Equal(t, expected, actual)
+}
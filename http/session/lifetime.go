@@ -0,0 +1,104 @@
+package session
+
+import (
+	"time"
+
+	gorilla "github.com/gorilla/sessions"
+	"github.com/xy-planning-network/trails"
+)
+
+const (
+	// DefaultAbsoluteLifetime is how long a session is valid for by default, regardless of activity.
+	DefaultAbsoluteLifetime = 24 * time.Hour
+
+	// createdAtKey stashes when a session began, in Unix milliseconds.
+	createdAtKey trails.Key = "SessionCreatedAtKey"
+
+	// lastSeenAtKey stashes when a session was last used, in Unix milliseconds.
+	lastSeenAtKey trails.Key = "SessionLastSeenAtKey"
+
+	// rememberKey stashes whether the user asked to be remembered when registered.
+	rememberKey trails.Key = "SessionRememberKey"
+)
+
+// A lifetime determines when a session expires.
+type lifetime struct {
+	// absolute is how long a session is valid for, regardless of activity.
+	absolute time.Duration
+
+	// idle is how long a session is valid for without activity.
+	idle time.Duration
+
+	// maxAge is the number of seconds the session cookie is valid for.
+	maxAge int
+
+	// rememberAge is the number of seconds the session cookie is valid for
+	// when a user asks to be remembered.
+	// When set, sessions of users not asking to be remembered use browser-session cookies.
+	rememberAge int
+}
+
+// expired asserts whether the session values have outlived
+// either the absolute lifetime or the idle timeout.
+func (l lifetime) expired(vals map[any]any, now time.Time) bool {
+	if created, ok := vals[createdAtKey].(int64); ok && l.absolute > 0 {
+		if now.Sub(time.UnixMilli(created)) >= l.absolute {
+			return true
+		}
+	}
+
+	if seen, ok := vals[lastSeenAtKey].(int64); ok && l.idle > 0 {
+		if now.Sub(time.UnixMilli(seen)) >= l.idle {
+			return true
+		}
+	}
+
+	return false
+}
+
+// touch marks the session as seen at now
+// and sets the max age of the session cookie,
+// never letting the cookie outlive the absolute lifetime.
+func (l lifetime) touch(s *gorilla.Session, now time.Time) {
+	created, ok := s.Values[createdAtKey].(int64)
+	if !ok {
+		created = now.UnixMilli()
+		s.Values[createdAtKey] = created
+	}
+
+	s.Values[lastSeenAtKey] = now.UnixMilli()
+
+	maxAge := l.maxAge
+	if l.rememberAge > 0 {
+		maxAge = 0
+		if remember, _ := s.Values[rememberKey].(bool); remember {
+			maxAge = l.rememberAge
+		}
+	}
+
+	if l.absolute > 0 && maxAge > 0 {
+		remaining := time.UnixMilli(created).Add(l.absolute).Sub(now)
+		maxAge = min(maxAge, max(int(remaining.Seconds()), 1))
+	}
+
+	s.Options.MaxAge = maxAge
+}
+
+// A RegisterOpt configures how RegisterUser stores a user in a Session.
+type RegisterOpt func(*registration)
+
+// registration collects the configuration set by RegisterOpts.
+type registration struct {
+	remember bool
+}
+
+// Remember sets whether the user asked to be remembered.
+//
+// Remember has no effect unless the Service was configured with Config.RememberMaxAge.
+// When it is, the session cookie of a remembered user lasts that long;
+// otherwise, the session cookie lasts until the browser closes.
+func Remember(remember bool) RegisterOpt {
+	return func(reg *registration) {
+		reg.remember = remember
+	}
+}
@@ -1,17 +1,53 @@
 package session
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
+	"slices"
+	"time"
 
 	gorilla "github.com/gorilla/sessions"
 	"github.com/xy-planning-network/trails"
+	"github.com/xy-planning-network/trails/logger"
 )
 
 // A Session provides all functionality for managing a fully featured session.
 //
 // Its functionality is implemented by lightly wrapping a gorilla.Session.
 type Session struct {
-	s *gorilla.Session
+	s          *gorilla.Session
+	anon       anonOptions
+	binding    binding
+	lifetime   lifetime
+	maxFlashes int
+}
+
+// Get retrieves the value stored under key in the Session as a T.
+//
+// If no value is stored under key, ErrNoValue is returned.
+// If the value stored is not a T, ErrNotValid is returned and represents a programming error.
+func Get[T any](s Session, key trails.Key) (T, error) {
+	var zero T
+	raw, ok := s.s.Values[key]
+	if !ok {
+		return zero, fmt.Errorf("%w: %s", ErrNoValue, key)
+	}
+
+	val, ok := raw.(T)
+	if !ok {
+		return zero, fmt.Errorf("%w: %s is %T, not %T", ErrNotValid, key, raw, zero)
+	}
+
+	return val, nil
+}
+
+// Set stores val under key in the Session and saves it.
+//
+// Any T that is not a built-in type must be registered with [encoding/gob.Register]
+// before the Session is saved.
+func Set[T any](s Session, w http.ResponseWriter, r *http.Request, key trails.Key, val T) error {
+	return s.Set(w, r, key, val)
 }
 
 // ClearFlashes removes all Flashes from the Session.
@@ -19,16 +55,40 @@ func (s Session) ClearFlashes(w http.ResponseWriter, r *http.Request) {
 	_ = s.Flashes(w, r)
 }
 
+// CSRFToken gets the token protecting against cross-site request forgery out of the session.
+// If no token has been set, ErrNoValue is returned.
+func (s Session) CSRFToken() (string, error) {
+	return Get[string](s, trails.CSRFTokenKey)
+}
+
 // Delete removes a session by making the MaxAge negative.
 func (s Session) Delete(w http.ResponseWriter, r *http.Request) error {
 	s.s.Options.MaxAge = -1
 	return s.Save(w, r)
 }
 
-// DeregisterUser removes the User from the session.
+// DeregisterUser removes the User from the session,
+// ending any impersonation.
+//
+// DeregisterUser records a [logger.AuditSessionLogout] event.
 func (s Session) DeregisterUser(w http.ResponseWriter, r *http.Request) error {
+	userID, _ := s.UserID()
+	impersonatorID, _ := s.ImpersonatorID()
+
 	delete(s.s.Values, trails.CurrentUserKey)
-	return s.Save(w, r)
+	delete(s.s.Values, fingerprintKey)
+	delete(s.s.Values, impersonatorKey)
+	delete(s.s.Values, rememberKey)
+	err := s.Save(w, r)
+
+	audit(logger.AuditEvent{
+		Action:         logger.AuditSessionLogout,
+		ActorID:        userID,
+		ImpersonatorID: impersonatorID,
+		Request:        r,
+	}, err)
+
+	return err
 }
 
 // Flashes retrieves []Flash stored in the session.
@@ -64,17 +124,69 @@ func (s Session) Get(key any) any {
 	return s.s.Values[key]
 }
 
-// RegisterUserSession stores the user's ID in the session.
-func (s Session) RegisterUser(w http.ResponseWriter, r *http.Request, ID uint) error {
+// RegisterUser stores the user's ID in the session,
+// restarting the absolute lifetime of the session.
+//
+// RegisterUser rotates the session, protecting against session fixation,
+// and binds it to the client registering if Config.Fingerprint is set; cf. Rotate.
+//
+// Use Remember to issue a long-lived session cookie.
+//
+// If Config.MergeOnLogin is set, RegisterUser first transfers the session's anonymous data with it;
+// if that fails, RegisterUser returns the error without registering the user.
+//
+// RegisterUser records a [logger.AuditSessionLogin] event.
+func (s Session) RegisterUser(w http.ResponseWriter, r *http.Request, ID uint, opts ...RegisterOpt) error {
+	var reg registration
+	for _, opt := range opts {
+		opt(&reg)
+	}
+
+	if err := s.mergeAnon(w, r, ID); err != nil {
+		return err
+	}
+
+	s.rotate(r)
 	s.s.Values[trails.CurrentUserKey] = ID
-	return s.Save(w, r)
+	s.s.Values[createdAtKey] = time.Now().UnixMilli()
+	if reg.remember {
+		s.s.Values[rememberKey] = true
+	} else {
+		delete(s.s.Values, rememberKey)
+	}
+
+	if s.lifetime != (lifetime{}) {
+		s.lifetime.touch(s.s, time.Now())
+	}
+
+	err := s.Save(w, r)
+	audit(logger.AuditEvent{
+		Action:  logger.AuditSessionLogin,
+		ActorID: ID,
+		Data:    map[string]any{"remember": reg.remember},
+		Request: r,
+	}, err)
+
+	return err
 }
 
 // ResetExpiry resets the expiration of the session by saving it.
+//
+// ResetExpiry does not extend the absolute lifetime of the session.
 func (s Session) ResetExpiry(w http.ResponseWriter, r *http.Request) error {
+	if s.lifetime != (lifetime{}) {
+		s.lifetime.touch(s.s, time.Now())
+	}
+
 	return s.Save(w, r)
 }
 
+// ReturnTo gets the URL to send a user to after completing a workflow, e.g., logging in.
+// If no URL has been set, ErrNoValue is returned.
+func (s Session) ReturnTo() (string, error) {
+	return Get[string](s, trails.ReturnToKey)
+}
+
 // Save wraps gorilla.Session.Save, saving the session in the request.
 func (s Session) Save(w http.ResponseWriter, r *http.Request) error { return s.s.Save(r, w) }
 
@@ -84,12 +196,36 @@ func (s Session) Set(w http.ResponseWriter, r *http.Request, key trails.Key, val
 	return s.Save(w, r)
 }
 
-// SetFlash stores the passed in Flash in the session.
+// SetCSRFToken stores the token protecting against cross-site request forgery in the session.
+func (s Session) SetCSRFToken(w http.ResponseWriter, r *http.Request, token string) error {
+	return Set(s, w, r, trails.CSRFTokenKey, token)
+}
+
+// SetReturnTo stores the URL to send a user to after completing a workflow, e.g., logging in.
+func (s Session) SetReturnTo(w http.ResponseWriter, r *http.Request, url string) error {
+	return Set(s, w, r, trails.ReturnToKey, url)
+}
+
+// SetFlash stores the passed in Flash in the session,
+// evicting the oldest Flashes if the session then holds more than Config.MaxFlashes.
 func (s Session) SetFlash(w http.ResponseWriter, r *http.Request, flash Flash) error {
 	s.s.AddFlash(flash)
+	if fs, ok := s.s.Values[flashesKey].([]any); ok && len(fs) > s.flashLimit() {
+		s.s.Values[flashesKey] = slices.Clone(fs[len(fs)-s.flashLimit():])
+	}
+
 	return s.Save(w, r)
 }
 
+// flashLimit is how many Flashes the Session holds.
+func (s Session) flashLimit() int {
+	if s.maxFlashes > 0 {
+		return s.maxFlashes
+	}
+
+	return DefaultMaxFlashes
+}
+
 // UserID gets the user ID out of the session.
 // A user ID should be present in a session if the user is successfully authenticated.
 // If no user ID can be found, this ErrNoUser is returned.
@@ -97,15 +233,27 @@ func (s Session) SetFlash(w http.ResponseWriter, r *http.Request, flash Flash) e
 //
 // If the value returned from the session is not a uint, ErrNotValid is returned and represents a programming error.
 func (s Session) UserID() (uint, error) {
-	intfVal, ok := s.s.Values[trails.CurrentUserKey]
-	if !ok {
+	val, err := Get[uint](s, trails.CurrentUserKey)
+	if errors.Is(err, ErrNoValue) {
 		return 0, ErrNoUser
 	}
 
-	val, ok := intfVal.(uint)
-	if !ok {
+	if err != nil {
 		return 0, ErrNotValid
 	}
 
 	return val, nil
 }
+
+// audit records event, failed if err is not nil.
+func audit(event logger.AuditEvent, err error) {
+	if err != nil {
+		event.Outcome = logger.AuditFailure
+		if event.Data == nil {
+			event.Data = make(map[string]any)
+		}
+		event.Data["error"] = err.Error()
+	}
+
+	logger.Audit(event)
+}
@@ -0,0 +1,126 @@
+package session_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/xy-planning-network/trails"
+	"github.com/xy-planning-network/trails/http/session"
+)
+
+func TestGet(t *testing.T) {
+	// Arrange
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "https://example.com", nil)
+	s, _ := session.NewStub(false).GetSession(r)
+	key := trails.Key("test")
+
+	// Act
+	_, err := session.Get[string](s, key)
+
+	// Assert
+	require.ErrorIs(t, err, session.ErrNoValue)
+
+	// Arrange
+	require.Nil(t, session.Set(s, w, r, key, 1))
+
+	// Act
+	_, err = session.Get[string](s, key)
+
+	// Assert
+	require.ErrorIs(t, err, session.ErrNotValid)
+
+	// Act
+	actual, err := session.Get[int](s, key)
+
+	// Assert
+	require.Nil(t, err)
+	require.Equal(t, 1, actual)
+}
+
+func TestSessionTypedHelpers(t *testing.T) {
+	// Arrange
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "https://example.com", nil)
+	s, _ := session.NewStub(false).GetSession(r)
+
+	// Act
+	_, uidErr := s.UserID()
+	_, tokenErr := s.CSRFToken()
+	_, returnErr := s.ReturnTo()
+
+	// Assert
+	require.ErrorIs(t, uidErr, session.ErrNoUser)
+	require.ErrorIs(t, tokenErr, session.ErrNoValue)
+	require.ErrorIs(t, returnErr, session.ErrNoValue)
+
+	// Arrange
+	require.Nil(t, s.RegisterUser(w, r, 1))
+	require.Nil(t, s.SetCSRFToken(w, r, "token"))
+	require.Nil(t, s.SetReturnTo(w, r, "/next"))
+
+	// Act
+	uid, uidErr := s.UserID()
+	token, tokenErr := s.CSRFToken()
+	returnTo, returnErr := s.ReturnTo()
+
+	// Assert
+	require.Nil(t, uidErr)
+	require.Equal(t, uint(1), uid)
+	require.Nil(t, tokenErr)
+	require.Equal(t, "token", token)
+	require.Nil(t, returnErr)
+	require.Equal(t, "/next", returnTo)
+
+	// Arrange
+	require.Nil(t, s.Set(w, r, trails.CurrentUserKey, "1"))
+
+	// Act
+	_, uidErr = s.UserID()
+
+	// Assert
+	require.ErrorIs(t, uidErr, session.ErrNotValid)
+}
+
+func TestSetFlashMaxFlashes(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		max      int
+		expected []string
+	}{
+		{"Default", 0, []string{"2", "3", "4", "5", "6", "7", "8", "9", "10", "11"}},
+		{"Configured", 3, []string{"9", "10", "11"}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			svc, err := session.NewStoreService(session.Config{
+				Env:         trails.Testing,
+				SessionName: "Test",
+				AuthKey:     "ABCD",
+				EncryptKey:  "ABCD",
+				MaxFlashes:  tc.max,
+			})
+			require.Nil(t, err)
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodGet, "https://example.com", nil)
+			s, err := svc.GetSession(r)
+			require.Nil(t, err)
+
+			// Act
+			for i := 1; i <= 11; i++ {
+				require.Nil(t, s.SetFlash(w, r, session.Flash{Type: session.FlashInfo, Msg: strconv.Itoa(i)}))
+			}
+
+			// Assert
+			var actual []string
+			for _, f := range s.Flashes(w, r) {
+				actual = append(actual, f.Msg)
+			}
+			require.Equal(t, tc.expected, actual)
+		})
+	}
+}
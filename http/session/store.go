@@ -5,10 +5,12 @@ import (
 	"encoding/hex"
 	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/google/uuid"
 	gorilla "github.com/gorilla/sessions"
 	"github.com/xy-planning-network/trails"
+	"github.com/xy-planning-network/trails/logger"
 )
 
 // The SessionStorer defines methods for interacting with a Sessionable for the given *http.Request.
@@ -34,6 +36,21 @@ type Service struct {
 	// The environment the Service is operating within.
 	env trails.Environment
 
+	// How sessions managed by the Service store anonymous data.
+	anon anonOptions
+
+	// How sessions managed by the Service are bound to the clients using them.
+	binding binding
+
+	// The cookie sessions managed by the Service are stored in.
+	cookie cookieOptions
+
+	// When sessions managed by the Service expire.
+	lifetime lifetime
+
+	// How many Flashes sessions managed by the Service hold.
+	maxFlashes int
+
 	// how the Service actually implements storing sessions.
 	store gorilla.Store
 }
@@ -48,18 +65,97 @@ type Config struct {
 	// The number of seconds a session is valid.
 	MaxAge int
 
+	// The duration a session is valid, regardless of activity.
+	// Once elapsed, the session is replaced with a brand new one.
+	//
+	// If zero, DefaultAbsoluteLifetime is used.
+	// If negative, sessions have no absolute lifetime.
+	AbsoluteLifetime time.Duration
+
+	// The duration a session is valid without activity.
+	// Once elapsed, the session is replaced with a brand new one.
+	//
+	// If zero, sessions do not expire from inactivity.
+	IdleTimeout time.Duration
+
+	// The traits of a client a session is bound to once a user registers with it,
+	// e.g., FingerprintIPPrefix | FingerprintUserAgent.
+	// A session used by a client not matching them is handled according to FingerprintStrictness.
+	//
+	// Changing Fingerprint changes the fingerprints of every client,
+	// so strictly bound sessions registered before the change end.
+	//
+	// If zero, sessions are not bound to clients.
+	Fingerprint Fingerprint
+
+	// What happens to a session used by a client not matching its fingerprint; cf. Fingerprint.
+	//
+	// If zero, FingerprintStrict is used.
+	FingerprintStrictness FingerprintStrictness
+
+	// The number of seconds a session is valid
+	// when a user asks to be remembered when registering them; cf. Remember.
+	//
+	// If set, the sessions of users not asking to be remembered
+	// use cookies lasting until the browser closes.
+	// If zero, MaxAge applies to all sessions.
+	//
+	// Sessions of remembered users still end once AbsoluteLifetime elapses,
+	// so AbsoluteLifetime ought to be at least as long.
+	RememberMaxAge int
+
 	// The name sessions are stored under.
 	// Also used as the name of the cookie when WithCookie is used.
 	SessionName string
 
 	// The SameSite mode for the session cookie.
+	//
+	// http.SameSiteNoneMode makes the cookie Secure, even in the development and testing environments.
 	SameSiteMode http.SameSite
 
+	// The path to assign cookies to.
+	//
+	// If empty, "/" is used.
+	Path string
+
+	// Whether the session cookie is stored apart for each site embedding the application, e.g., in an iframe;
+	// cf. https://developer.mozilla.org/en-US/docs/Web/Privacy/Privacy_sandbox/Partitioned_cookies.
+	//
+	// Partitioned makes the cookie Secure, even in the development and testing environments.
+	Partitioned bool
+
+	// The number of bytes of anonymous data a session holds; cf. SetAnon.
+	//
+	// If zero, DefaultMaxAnonBytes is used.
+	MaxAnonBytes int
+
+	// The number of Flashes a session holds, evicting the oldest past it,
+	// so Flashes set on requests never displaying them cannot overflow a session cookie.
+	//
+	// If zero, DefaultMaxFlashes is used.
+	MaxFlashes int
+
+	// Transfers the anonymous data in a session to the user registering with it,
+	// after which the data is removed from the session; cf. Session.RegisterUser.
+	//
+	// If nil, anonymous data stays in the session after users register.
+	MergeOnLogin MergeFunc
+
 	// Hex-encoded key
 	AuthKey string
 
 	// Hex-encoded key
 	EncryptKey string
+
+	// Hex-encoded keys previously used for authentication, ordered newest to oldest.
+	// Sessions authenticated with these keys remain readable,
+	// but sessions are always saved using AuthKey.
+	PreviousAuthKeys []string
+
+	// Hex-encoded keys previously used for encryption, ordered newest to oldest.
+	// Sessions encrypted with these keys remain readable,
+	// but sessions are always saved using EncryptKey.
+	PreviousEncryptKeys []string
 }
 
 func validateConfig(c Config) error {
@@ -75,15 +171,57 @@ func validateConfig(c Config) error {
 	return nil
 }
 
-// NewStoreService initiates a data store for user web sessions with the provided config.
-func NewStoreService(cfg Config) (Service, error) {
+// NewStoreService initiates a data store for user web sessions with the provided config,
+// then applies opts, e.g., WithCrossSiteEmbedding.
+//
+// The session cookie is Secure outside the development and testing environments.
+func NewStoreService(cfg Config, opts ...ServiceOpt) (Service, error) {
 	var err error
 	gob.Register(Flash{})
 	gob.Register(trails.Key(""))
 
 	s := Service{
+		anon:    anonOptions{maxBytes: cfg.MaxAnonBytes, merge: cfg.MergeOnLogin},
+		binding: binding{fingerprint: cfg.Fingerprint, strictness: cfg.FingerprintStrictness},
+		cookie: cookieOptions{
+			Options: gorilla.Options{
+				Domain:   cfg.Domain,
+				HttpOnly: true,
+				Path:     cfg.Path,
+				SameSite: cfg.SameSiteMode,
+				Secure: !(cfg.Env.IsDevelopment() || cfg.Env.IsTesting()) ||
+					cfg.SameSiteMode == http.SameSiteNoneMode ||
+					cfg.Partitioned,
+			},
+			partitioned: cfg.Partitioned,
+		},
 		env: cfg.Env,
-		sn:  cfg.SessionName,
+		lifetime: lifetime{
+			absolute:    cfg.AbsoluteLifetime,
+			idle:        cfg.IdleTimeout,
+			maxAge:      cfg.MaxAge,
+			rememberAge: cfg.RememberMaxAge,
+		},
+		maxFlashes: cfg.MaxFlashes,
+		sn:         cfg.SessionName,
+	}
+
+	for _, opt := range opts {
+		if err := opt(&s); err != nil {
+			return Service{}, err
+		}
+	}
+
+	if s.cookie.Path == "" {
+		s.cookie.Path = "/"
+	}
+
+	if s.lifetime.absolute == 0 {
+		s.lifetime.absolute = DefaultAbsoluteLifetime
+	}
+
+	if err := s.cookie.validate(); err != nil {
+		return Service{}, err
 	}
 
 	s.ak, err = hex.DecodeString(cfg.AuthKey)
@@ -96,33 +234,114 @@ func NewStoreService(cfg Config) (Service, error) {
 		return Service{}, fmt.Errorf("%w: encryption key is not valid: %s", trails.ErrBadConfig, err)
 	}
 
-	var c *gorilla.CookieStore
-	if !s.env.IsTesting() {
-		c = gorilla.NewCookieStore(s.ak, s.ek)
-	} else {
-		c = gorilla.NewCookieStore(s.ak)
+	pairs, err := s.keyPairs(cfg.PreviousAuthKeys, cfg.PreviousEncryptKeys)
+	if err != nil {
+		return Service{}, err
 	}
 
-	c.Options.Domain = cfg.Domain
-	c.Options.HttpOnly = true
-	c.Options.SameSite = cfg.SameSiteMode
-	c.Options.Secure = !(s.env.IsDevelopment() || s.env.IsTesting())
-	c.MaxAge(cfg.MaxAge)
+	c := gorilla.NewCookieStore(pairs...)
+
+	cookie := s.cookie.Options
+	c.Options = &cookie
+	// NOTE: the cookie store validates the age of a cookie with the max age set here,
+	// so it must be the longest a cookie is valid for.
+	c.MaxAge(max(cfg.MaxAge, cfg.RememberMaxAge))
+	c.Options.MaxAge = cfg.MaxAge
 
 	s.store = c
+	if s.cookie.partitioned {
+		s.store = &cookieStore{CookieStore: c}
+	}
 
 	return s, nil
 }
 
+// keyPairs constructs the authentication and encryption key pairs a gorilla.CookieStore uses,
+// placing the current keys first so they are used when saving sessions.
+//
+// When the previous key lists differ in length,
+// the current key fills in for the shorter list
+// so that rotating only one of the two keys is supported.
+func (s Service) keyPairs(prevAuth, prevEncrypt []string) ([][]byte, error) {
+	pairs := [][]byte{s.ak, s.ek}
+	for i := 0; i < max(len(prevAuth), len(prevEncrypt)); i++ {
+		ak, ek := s.ak, s.ek
+		if i < len(prevAuth) {
+			b, err := hex.DecodeString(prevAuth[i])
+			if err != nil {
+				return nil, fmt.Errorf("%w: previous authentication key %d is not valid: %s", trails.ErrBadConfig, i, err)
+			}
+			ak = b
+		}
+
+		if i < len(prevEncrypt) {
+			b, err := hex.DecodeString(prevEncrypt[i])
+			if err != nil {
+				return nil, fmt.Errorf("%w: previous encryption key %d is not valid: %s", trails.ErrBadConfig, i, err)
+			}
+			ek = b
+		}
+
+		pairs = append(pairs, ak, ek)
+	}
+
+	if s.env.IsTesting() {
+		// NOTE: skip encrypting sessions when testing
+		for i := 1; i < len(pairs); i += 2 {
+			pairs[i] = nil
+		}
+	}
+
+	return pairs, nil
+}
+
 // GetSession retrieves the Session for the *http.Request,
 // or creates a brand new one.
+//
+// If the Session retrieved has outlived Config.AbsoluteLifetime or Config.IdleTimeout,
+// GetSession replaces it with a brand new one.
+//
+// If the client making r does not match the fingerprint the Session is bound to,
+// GetSession records a [logger.AuditSessionFingerprintMismatch] event
+// and, when Config.FingerprintStrictness is FingerprintStrict, replaces it with a brand new one.
 func (s Service) GetSession(r *http.Request) (Session, error) {
 	session, err := s.store.Get(r, s.sn)
+
+	if s.binding.mismatched(session.Values, r) {
+		userID, _ := session.Values[trails.CurrentUserKey].(uint)
+		strict := s.binding.strictness == FingerprintStrict
+		logger.Audit(logger.AuditEvent{
+			Action:  logger.AuditSessionFingerprintMismatch,
+			ActorID: userID,
+			Data:    map[string]any{"rejected": strict},
+			Outcome: logger.AuditFailure,
+			Request: r,
+		})
+
+		if strict {
+			session.Values = make(map[any]any)
+			session.IsNew = true
+		}
+	}
+
+	now := time.Now()
+	if s.lifetime.expired(session.Values, now) {
+		// NOTE: anonymous data expires on its own schedule, so it outlives the session it was in.
+		anon, ok := session.Values[anonKey]
+		session.Values = make(map[any]any)
+		if ok {
+			session.Values[anonKey] = anon
+		}
+		session.IsNew = true
+	}
+
 	if _, ok := session.Values[trails.SessionIDKey]; !ok {
 		session.Values[trails.SessionIDKey] = uuid.NewString()
 	}
 
-	return Session{s: session}, err
+	s.lifetime.touch(session, now)
+
+	return Session{s: session, anon: s.anon, binding: s.binding, lifetime: s.lifetime, maxFlashes: s.maxFlashes}, err
 }
 
 // A ServiceOpt configures the provided *Service,
@@ -144,7 +363,7 @@ func NewStub(loggedIn bool) *Stub {
 }
 
 func (s *Stub) GetSession(r *http.Request) (Session, error) {
-	return Session{s.s}, nil
+	return Session{s: s.s}, nil
 
 }
 
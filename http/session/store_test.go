@@ -4,6 +4,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 	"github.com/xy-planning-network/trails"
@@ -51,3 +52,204 @@ func TestNewService(t *testing.T) {
 	require.NotZero(t, svc)
 	require.NotPanics(t, func() { svc.GetSession(r) })
 }
+
+func TestNewServiceKeyRotation(t *testing.T) {
+	// Arrange
+	oldCfg := session.Config{
+		Env:         trails.Testing,
+		SessionName: "Test",
+		AuthKey:     "ABCD",
+		EncryptKey:  "ABCD",
+	}
+	oldSvc, err := session.NewStoreService(oldCfg)
+	require.Nil(t, err)
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "https://example.com", nil)
+	s, err := oldSvc.GetSession(r)
+	require.Nil(t, err)
+	require.Nil(t, s.RegisterUser(w, r, 1))
+
+	cookies := w.Result().Cookies()
+	require.Len(t, cookies, 1)
+
+	newCfg := oldCfg
+	newCfg.AuthKey = "EF01"
+
+	for _, tc := range []struct {
+		name     string
+		previous []string
+		assert   func(*testing.T, session.Session, error)
+	}{
+		{
+			name: "Without-Previous",
+			assert: func(t *testing.T, s session.Session, err error) {
+				require.NotNil(t, err)
+				_, err = s.UserID()
+				require.ErrorIs(t, err, session.ErrNoUser)
+			},
+		},
+		{
+			name:     "Bad-Previous",
+			previous: []string{"😅"},
+		},
+		{
+			name:     "With-Previous",
+			previous: []string{"2345", oldCfg.AuthKey},
+			assert: func(t *testing.T, s session.Session, err error) {
+				require.Nil(t, err)
+				id, err := s.UserID()
+				require.Nil(t, err)
+				require.Equal(t, uint(1), id)
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			cfg := newCfg
+			cfg.PreviousAuthKeys = tc.previous
+
+			r := httptest.NewRequest(http.MethodGet, "https://example.com", nil)
+			r.AddCookie(cookies[0])
+
+			// Act
+			svc, err := session.NewStoreService(cfg)
+			if tc.assert == nil {
+				require.ErrorIs(t, err, trails.ErrBadConfig)
+				return
+			}
+
+			require.Nil(t, err)
+			s, err := svc.GetSession(r)
+
+			// Assert
+			tc.assert(t, s, err)
+		})
+	}
+}
+
+func TestServiceLifetime(t *testing.T) {
+	base := session.Config{
+		Env:         trails.Testing,
+		SessionName: "Test",
+		AuthKey:     "ABCD",
+		EncryptKey:  "ABCD",
+		MaxAge:      3600,
+	}
+
+	// login registers a user, returning the session cookie.
+	login := func(t *testing.T, svc session.Service, opts ...session.RegisterOpt) *http.Cookie {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "https://example.com", nil)
+		s, err := svc.GetSession(r)
+		require.Nil(t, err)
+		require.Nil(t, s.RegisterUser(w, r, 1, opts...))
+
+		cookies := w.Result().Cookies()
+		require.Len(t, cookies, 1)
+
+		return cookies[0]
+	}
+
+	// revisit retrieves the session with the cookie.
+	revisit := func(t *testing.T, svc session.Service, c *http.Cookie) session.Session {
+		r := httptest.NewRequest(http.MethodGet, "https://example.com", nil)
+		r.AddCookie(c)
+		s, err := svc.GetSession(r)
+		require.Nil(t, err)
+
+		return s
+	}
+
+	t.Run("Absolute", func(t *testing.T) {
+		// Arrange
+		cfg := base
+		cfg.AbsoluteLifetime = 10 * time.Millisecond
+		svc, err := session.NewStoreService(cfg)
+		require.Nil(t, err)
+
+		c := login(t, svc)
+		require.Equal(t, 1, c.MaxAge)
+
+		// Act
+		time.Sleep(cfg.AbsoluteLifetime)
+		s := revisit(t, svc, c)
+
+		// Assert
+		_, err = s.UserID()
+		require.ErrorIs(t, err, session.ErrNoUser)
+	})
+
+	t.Run("Absolute-Default", func(t *testing.T) {
+		// Arrange
+		cfg := base
+		cfg.MaxAge = int((2 * session.DefaultAbsoluteLifetime).Seconds())
+		svc, err := session.NewStoreService(cfg)
+		require.Nil(t, err)
+
+		// Act
+		c := login(t, svc)
+
+		// Assert
+		require.InDelta(t, session.DefaultAbsoluteLifetime.Seconds(), c.MaxAge, 1)
+
+		// Arrange
+		cfg.AbsoluteLifetime = -1
+		svc, err = session.NewStoreService(cfg)
+		require.Nil(t, err)
+
+		// Act
+		c = login(t, svc)
+
+		// Assert
+		require.Equal(t, cfg.MaxAge, c.MaxAge)
+	})
+
+	t.Run("Idle", func(t *testing.T) {
+		// Arrange
+		cfg := base
+		cfg.IdleTimeout = time.Hour
+		svc, err := session.NewStoreService(cfg)
+		require.Nil(t, err)
+
+		c := login(t, svc)
+
+		// Act
+		s := revisit(t, svc, c)
+
+		// Assert
+		_, err = s.UserID()
+		require.Nil(t, err)
+
+		// Arrange
+		cfg.IdleTimeout = 10 * time.Millisecond
+		svc, err = session.NewStoreService(cfg)
+		require.Nil(t, err)
+
+		c = login(t, svc)
+
+		// Act
+		time.Sleep(cfg.IdleTimeout)
+		s = revisit(t, svc, c)
+
+		// Assert
+		_, err = s.UserID()
+		require.ErrorIs(t, err, session.ErrNoUser)
+	})
+
+	t.Run("Remember", func(t *testing.T) {
+		// Arrange
+		cfg := base
+		cfg.RememberMaxAge = 7200
+		svc, err := session.NewStoreService(cfg)
+		require.Nil(t, err)
+
+		// Act
+		forget := login(t, svc)
+		remember := login(t, svc, session.Remember(true))
+
+		// Assert
+		require.Zero(t, forget.MaxAge)
+		require.Equal(t, cfg.RememberMaxAge, remember.MaxAge)
+	})
+}
@@ -1,6 +1,7 @@
 package template
 
 import (
+	"fmt"
 	html "html/template"
 	"net/url"
 
@@ -20,6 +21,47 @@ func (p *Parser) AddFn(name string, fn any) *Parser {
 	return newP
 }
 
+const (
+	// BotCheckFieldName is the name of the form field carrying the signed time a form was rendered at.
+	BotCheckFieldName = "bot_check"
+
+	// HoneypotFieldName is the name of the form field people never see nor fill in, but bots might.
+	HoneypotFieldName = "website_url"
+)
+
+// BotCheckField encloses the signed time a form was rendered at.
+// It returns "botCheckField" as the name of the function for convenient passing to a template.FuncMap
+// and returns a function rendering a hidden form field named BotCheckFieldName carrying the token
+// and a honeypot field named HoneypotFieldName, hidden from people and assistive technologies.
+func BotCheckField(token string) (string, func() html.HTML) {
+	field := fmt.Sprintf(
+		`<input type="hidden" name="%s" value="%s">`+
+			`<div aria-hidden="true" style="position:absolute;left:-10000px;">`+
+			`<input type="text" name="%s" value="" tabindex="-1" autocomplete="off">`+
+			`</div>`,
+		BotCheckFieldName, html.HTMLEscapeString(token), HoneypotFieldName,
+	)
+	return "botCheckField", func() html.HTML { return html.HTML(field) }
+}
+
+// CSRFFieldName is the name of the form field carrying the token protecting against cross-site request forgery.
+const CSRFFieldName = "csrf_token"
+
+// CSRFField encloses the token protecting against cross-site request forgery.
+// It returns "csrfField" as the name of the function for convenient passing to a template.FuncMap
+// and returns a function rendering a hidden form field named CSRFFieldName carrying the token.
+func CSRFField(token string) (string, func() html.HTML) {
+	field := fmt.Sprintf(`<input type="hidden" name="%s" value="%s">`, CSRFFieldName, html.HTMLEscapeString(token))
+	return "csrfField", func() html.HTML { return html.HTML(field) }
+}
+
+// CSRFToken encloses the token protecting against cross-site request forgery.
+// It returns "csrfToken" as the name of the function for convenient passing to a template.FuncMap
+// and returns a function returning the token when called.
+func CSRFToken(token string) (string, func() string) {
+	return "csrfToken", func() string { return token }
+}
+
 // CurrentUser encloses some value representing a user.
 // It returns "currentUser" as the name of the function for convenient passing to a template.FuncMap
 // and returns a function returning the enclosed value when called.
@@ -34,12 +76,35 @@ func Env(e trails.Environment) (string, func() string) {
 	return "env", func() string { return e.String() }
 }
 
+// Impersonator encloses some value representing a user impersonating the current user.
+// It returns "impersonator" as the name of the function for convenient passing to a template.FuncMap
+// and returns a function returning the enclosed value when called.
+// That value is nil when no one is impersonating the current user.
+func Impersonator(u any) (string, func() any) {
+	return "impersonator", func() any { return u }
+}
+
+// Locale encloses the BCP 47 tag of the locale a request is localized in, e.g., "en-US".
+// It returns "locale" as the name of the function for convenient passing to a template.FuncMap
+// and returns a function returning the enclosed tag when called.
+func Locale(tag string) (string, func() string) {
+	return "locale", func() string { return tag }
+}
+
 // Nonce returns "nonce" as the name of the function for convenient passing to a template.FuncMap
 // and returns a function generating a uuid.
 func Nonce() (string, func() string) {
 	return "nonce", func() string { return uuid.NewString() }
 }
 
+// RequestNonce encloses the nonce generated for a single request, e.g., for a content security policy.
+// It returns "nonce" as the name of the function for convenient passing to a template.FuncMap
+// and returns a function returning the enclosed nonce when called,
+// replacing the function Nonce returns.
+func RequestNonce(nonce string) (string, func() string) {
+	return "nonce", func() string { return nonce }
+}
+
 // RootUrl encloses the *url.URL representing the base URL of the web app.
 // It returns "rootUrl" as the name of the function for convenient passing to a template.FuncMap
 // and returns a function returning its *url.URL.String().
@@ -62,6 +62,28 @@ func TestCurrentUser(t *testing.T) {
 	}
 }
 
+func TestImpersonator(t *testing.T) {
+	// Arrange
+	tcs := []struct {
+		name     string
+		expected any
+	}{
+		{"nil", nil},
+		{"struct", struct{}{}},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			// Act
+			name, fn := Impersonator(tc.expected)
+
+			// Assert
+			require.Equal(t, "impersonator", name)
+			require.Equal(t, tc.expected, fn())
+		})
+	}
+}
+
 func TestEnv(t *testing.T) {
 	// Arrange
 	tcs := []struct {
@@ -120,3 +142,50 @@ func TestRootUrl(t *testing.T) {
 		})
 	}
 }
+
+func TestBotCheckField(t *testing.T) {
+	// Act
+	name, fn := BotCheckField(`"><script>`)
+
+	// Assert
+	require.Equal(t, "botCheckField", name)
+	require.Contains(t, string(fn()), `<input type="hidden" name="bot_check" value="&#34;&gt;&lt;script&gt;">`)
+	require.Contains(t, string(fn()), `<input type="text" name="website_url" value="" tabindex="-1" autocomplete="off">`)
+}
+
+func TestCSRFField(t *testing.T) {
+	// Act
+	name, fn := CSRFField(`"><script>`)
+
+	// Assert
+	require.Equal(t, "csrfField", name)
+	require.Equal(t, `<input type="hidden" name="csrf_token" value="&#34;&gt;&lt;script&gt;">`, string(fn()))
+}
+
+func TestCSRFToken(t *testing.T) {
+	// Act
+	name, fn := CSRFToken("token")
+
+	// Assert
+	require.Equal(t, "csrfToken", name)
+	require.Equal(t, "token", fn())
+}
+
+func TestRequestNonce(t *testing.T) {
+	// Act
+	name, fn := RequestNonce("abc")
+
+	// Assert
+	require.Equal(t, "nonce", name)
+	require.Equal(t, "abc", fn())
+	require.Equal(t, "abc", fn())
+}
+
+func TestLocale(t *testing.T) {
+	// Act
+	name, fn := Locale("es-MX")
+
+	// Assert
+	require.Equal(t, "locale", name)
+	require.Equal(t, "es-MX", fn())
+}
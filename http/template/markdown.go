@@ -0,0 +1,674 @@
+package template
+
+import (
+	html "html/template"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// A MarkdownPolicy determines the HTML markdown renders as; cf. Markdown.
+//
+// Whatever the policy, HTML written in markdown is escaped, never rendered.
+type MarkdownPolicy struct {
+	// Elements are the HTML elements markdown renders as, e.g., "a", "h2", or "strong".
+	// Markdown for any other element renders only its contents, e.g., the alt text of an image.
+	Elements []string
+
+	// Schemes are the URL schemes links and images use, e.g., "https" or "mailto".
+	// Links and images with any other scheme render only their text.
+	// Relative URLs are always rendered.
+	Schemes []string
+}
+
+var (
+	// MarkdownBasic renders paragraphs, emphasis, code, links, lists, and quotes,
+	// as suits help text and the like.
+	MarkdownBasic = MarkdownPolicy{
+		Elements: []string{"a", "blockquote", "br", "code", "em", "li", "ol", "p", "pre", "strong", "ul"},
+		Schemes:  []string{"http", "https", "mailto"},
+	}
+
+	// MarkdownRich renders what MarkdownBasic does plus headings, horizontal rules, and images,
+	// as suits longer content.
+	MarkdownRich = MarkdownPolicy{
+		Elements: append(slices.Clone(MarkdownBasic.Elements), "h1", "h2", "h3", "h4", "h5", "h6", "hr", "img"),
+		Schemes:  MarkdownBasic.Schemes,
+	}
+)
+
+// Markdown encloses the policy markdown is rendered according to.
+// It returns "markdown" as the name of the function for convenient passing to a template.FuncMap
+// and returns a function rendering markdown as sanitized HTML:
+//
+//	<div class="help">{{ markdown .HelpText }}</div>
+//
+// Only trusted markdown ought to be rendered, e.g., content the application's staff write.
+//
+// The function renders a small subset of markdown:
+//
+//   - paragraphs, separated by blank lines
+//   - headings, e.g., "## Title"
+//   - horizontal rules: "---", "***", or "___"
+//   - quotes: lines starting with ">"
+//   - lists: items starting with "-", "*", "+", or a number followed by ".", e.g., "1.";
+//     lines indented as far as an item's text continue it, so lists nest
+//   - fenced code blocks: lines between "```" or "~~~", optionally naming a language
+//   - emphasis: "*em*" or "_em_" and "**strong**" or "__strong__"
+//   - code spans: "`code`"
+//   - links and images: "[text](url)" and "![alt](url)"
+//   - hard line breaks: a line ending with two spaces or a backslash
+//   - backslash escapes, e.g., "\*"
+//
+// Anything else, e.g., HTML, renders as text.
+func Markdown(policy MarkdownPolicy) (string, func(string) html.HTML) {
+	return "markdown", policy.Render
+}
+
+// WithMarkdown includes the function Markdown returns for policy in the Parse function map.
+func WithMarkdown(policy MarkdownPolicy) ParserOpt {
+	return func(p *Parser) {
+		name, fn := Markdown(policy)
+		p.fns[name] = fn
+	}
+}
+
+// Render renders markdown as HTML according to p.
+func (p MarkdownPolicy) Render(markdown string) html.HTML {
+	r := &mdRenderer{policy: p}
+	markdown = strings.NewReplacer("\r\n", "\n", "\r", "\n", "\t", "    ", "\x00", "\uFFFD").Replace(markdown)
+	r.blocks(strings.Split(markdown, "\n"), false)
+
+	return html.HTML(strings.TrimSuffix(r.b.String(), "\n"))
+}
+
+// allows asserts whether p renders elem.
+func (p MarkdownPolicy) allows(elem string) bool { return slices.Contains(p.Elements, elem) }
+
+// allowsURL asserts whether p renders links or images to u.
+func (p MarkdownPolicy) allowsURL(u string) bool {
+	parsed, err := url.Parse(u)
+	if err != nil {
+		return false
+	}
+
+	return parsed.Scheme == "" || slices.Contains(p.Schemes, parsed.Scheme)
+}
+
+var (
+	// atxHeading matches a heading, capturing its level and text.
+	atxHeading = regexp.MustCompile(`^(#{1,6})(?:[ ]+(.*?))?(?:[ ]+#+)?[ ]*$`)
+
+	// codeFence matches the start of a fenced code block, capturing the fence and info string.
+	codeFence = regexp.MustCompile("^(`{3,}|~{3,})[ ]*([^`]*?)[ ]*$")
+
+	// codeLanguage matches the language named in the info string of a fenced code block.
+	codeLanguage = regexp.MustCompile(`^[\w#+.-]+$`)
+
+	// listItem matches the marker of a list item, capturing the marker.
+	listItem = regexp.MustCompile(`^([-*+]|\d{1,9}\.)(?:[ ]+|$)`)
+
+	// thematicBreak matches a horizontal rule.
+	thematicBreak = regexp.MustCompile(`^(?:(?:\*[ ]*){3,}|(?:-[ ]*){3,}|(?:_[ ]*){3,})$`)
+)
+
+// mdMaxDepth bounds how deeply quotes, lists, and emphasis nest,
+// so rendering stays quick however the markdown is written;
+// deeper quotes and lists render as paragraphs, and deeper emphasis as text.
+const mdMaxDepth = 16
+
+// An mdRenderer renders markdown as HTML according to its policy.
+type mdRenderer struct {
+	b      strings.Builder
+	depth  int
+	policy MarkdownPolicy
+}
+
+// open writes the start tag of elem, with attrs as name-value pairs, if the policy allows elem.
+func (r *mdRenderer) open(elem string, attrs ...string) {
+	if !r.policy.allows(elem) {
+		return
+	}
+
+	r.b.WriteString("<" + elem)
+	for i := 0; i+1 < len(attrs); i += 2 {
+		r.b.WriteString(" " + attrs[i] + `="` + html.HTMLEscapeString(attrs[i+1]) + `"`)
+	}
+	r.b.WriteString(">")
+}
+
+// close writes the end tag of elem if the policy allows elem.
+func (r *mdRenderer) close(elem string) {
+	if r.policy.allows(elem) {
+		r.b.WriteString("</" + elem + ">")
+	}
+}
+
+// text writes s escaped.
+func (r *mdRenderer) text(s string) { r.b.WriteString(html.HTMLEscapeString(s)) }
+
+// blocks renders lines as block elements;
+// in a list, paragraphs render without p elements.
+func (r *mdRenderer) blocks(lines []string, tight bool) {
+	for i := 0; i < len(lines); {
+		line := strings.TrimLeft(lines[i], " ")
+
+		switch {
+		case line == "":
+			i++
+
+		case codeFence.MatchString(line):
+			i = r.codeBlock(lines, i)
+
+		case atxHeading.MatchString(line):
+			m := atxHeading.FindStringSubmatch(line)
+			elem := "h" + strconv.Itoa(len(m[1]))
+			if !r.policy.allows(elem) {
+				elem = "p"
+			}
+
+			r.open(elem)
+			r.inline(m[2])
+			r.close(elem)
+			r.b.WriteString("\n")
+			i++
+
+		case thematicBreak.MatchString(line):
+			if r.policy.allows("hr") {
+				r.open("hr")
+				r.b.WriteString("\n")
+			}
+			i++
+
+		case r.depth < mdMaxDepth && strings.HasPrefix(line, ">"):
+			i = r.blockquote(lines, i)
+
+		case r.depth < mdMaxDepth && listItem.MatchString(line):
+			i = r.list(lines, i)
+
+		default:
+			i = r.paragraph(lines, i, tight)
+		}
+	}
+}
+
+// interrupts asserts whether line starts a block ending a paragraph.
+func interrupts(line string) bool {
+	trimmed := strings.TrimLeft(line, " ")
+	if trimmed == "" {
+		return true
+	}
+
+	if m := listItem.FindStringSubmatch(trimmed); m != nil && trimmed != m[0] {
+		// NOTE: only ordered lists starting at 1 interrupt paragraphs, so prose like "1999. What a year." does not.
+		return !strings.HasSuffix(m[1], ".") || m[1] == "1."
+	}
+
+	return codeFence.MatchString(trimmed) ||
+		atxHeading.MatchString(trimmed) ||
+		thematicBreak.MatchString(trimmed) ||
+		strings.HasPrefix(trimmed, ">")
+}
+
+// paragraph renders the paragraph starting at lines[i], returning the index of the line after it.
+func (r *mdRenderer) paragraph(lines []string, i int, tight bool) int {
+	var text []string
+	for ; i < len(lines); i++ {
+		if len(text) > 0 && interrupts(lines[i]) {
+			break
+		}
+
+		text = append(text, strings.TrimLeft(lines[i], " "))
+	}
+
+	if !tight {
+		r.open("p")
+	}
+	r.inline(strings.TrimRight(strings.Join(text, "\n"), " "))
+	if !tight {
+		r.close("p")
+	}
+	r.b.WriteString("\n")
+
+	return i
+}
+
+// codeBlock renders the fenced code block starting at lines[i], returning the index of the line after it.
+func (r *mdRenderer) codeBlock(lines []string, i int) int {
+	m := codeFence.FindStringSubmatch(strings.TrimLeft(lines[i], " "))
+	fence, info := m[1], strings.Fields(m[2])
+
+	var code strings.Builder
+	for i++; i < len(lines); i++ {
+		line := strings.Trim(lines[i], " ")
+		if strings.HasPrefix(line, fence) && strings.Trim(line, fence[:1]) == "" {
+			i++
+			break
+		}
+
+		code.WriteString(lines[i] + "\n")
+	}
+
+	var attrs []string
+	if len(info) > 0 && codeLanguage.MatchString(info[0]) {
+		attrs = []string{"class", "language-" + info[0]}
+	}
+
+	r.open("pre")
+	r.open("code", attrs...)
+	r.text(code.String())
+	r.close("code")
+	r.close("pre")
+	r.b.WriteString("\n")
+
+	return i
+}
+
+// blockquote renders the quote starting at lines[i], returning the index of the line after it.
+func (r *mdRenderer) blockquote(lines []string, i int) int {
+	var quoted []string
+	for ; i < len(lines); i++ {
+		line := strings.TrimLeft(lines[i], " ")
+		if !strings.HasPrefix(line, ">") {
+			break
+		}
+
+		quoted = append(quoted, strings.TrimPrefix(line[1:], " "))
+	}
+
+	r.open("blockquote")
+	r.b.WriteString("\n")
+	r.depth++
+	r.blocks(quoted, false)
+	r.depth--
+	r.close("blockquote")
+	r.b.WriteString("\n")
+
+	return i
+}
+
+// A marker is the start of a list item.
+type marker struct {
+	// ordered asserts whether the item is in an ordered list.
+	ordered bool
+
+	// start is the number of an ordered list item.
+	start int
+
+	// text is what follows the marker on its line.
+	text string
+
+	// width is how far lines continuing the item are indented.
+	width int
+}
+
+// parseMarker parses the marker of a list item starting line.
+func parseMarker(line string) (marker, bool) {
+	trimmed := strings.TrimLeft(line, " ")
+	m := listItem.FindStringSubmatch(trimmed)
+	if m == nil || thematicBreak.MatchString(trimmed) {
+		return marker{}, false
+	}
+
+	mk := marker{text: trimmed[len(m[0]):], width: len(line) - len(trimmed) + max(len(m[0]), len(m[1])+1)}
+	if n, err := strconv.Atoi(strings.TrimSuffix(m[1], ".")); err == nil {
+		mk.ordered, mk.start = true, n
+	}
+
+	return mk, true
+}
+
+// list renders the list starting at lines[i], returning the index of the line after it.
+func (r *mdRenderer) list(lines []string, i int) int {
+	first, _ := parseMarker(lines[i])
+
+	var items [][]string
+	for i < len(lines) {
+		m, ok := parseMarker(lines[i])
+		if !ok || m.ordered != first.ordered {
+			break
+		}
+
+		item := []string{m.text}
+		for i++; i < len(lines); i++ {
+			line, ok := continueItem(lines[i], item[len(item)-1], m.width)
+			if !ok {
+				break
+			}
+
+			item = append(item, line)
+		}
+
+		items = append(items, item)
+	}
+
+	elem, attrs := "ul", []string(nil)
+	if first.ordered {
+		elem = "ol"
+		if first.start != 1 {
+			attrs = []string{"start", strconv.Itoa(first.start)}
+		}
+	}
+
+	r.open(elem, attrs...)
+	r.b.WriteString("\n")
+	r.depth++
+	for _, item := range items {
+		// NOTE: render the item on its own, so it closes on the line it ends on.
+		li := &mdRenderer{depth: r.depth, policy: r.policy}
+		li.blocks(item, true)
+
+		r.open("li")
+		r.b.WriteString(strings.TrimSuffix(li.b.String(), "\n"))
+		r.close("li")
+		r.b.WriteString("\n")
+	}
+	r.depth--
+	r.close(elem)
+	r.b.WriteString("\n")
+
+	return i
+}
+
+// continueItem returns line as it continues a list item whose text is indented width
+// and whose last line is last, reporting whether it does.
+//
+// Blank lines and lines indented at least width continue an item,
+// as do lines continuing the paragraph it ends with.
+func continueItem(line, last string, width int) (string, bool) {
+	trimmed := strings.TrimLeft(line, " ")
+	switch {
+	case trimmed == "":
+		return "", true
+	case len(line)-len(trimmed) >= width:
+		return line[width:], true
+	}
+
+	if _, ok := parseMarker(line); ok || last == "" || interrupts(line) {
+		return "", false
+	}
+
+	return trimmed, true
+}
+
+// inline renders s as inline elements.
+func (r *mdRenderer) inline(s string) { r.inlineIn(s, false) }
+
+// inlineIn renders s as inline elements; in a link, links render only their text.
+func (r *mdRenderer) inlineIn(s string, inLink bool) {
+	var text strings.Builder
+	flush := func() {
+		r.text(text.String())
+		text.Reset()
+	}
+
+	var closing []int
+	brackets := func() []int {
+		if closing == nil {
+			closing = matchBrackets(s)
+		}
+
+		return closing
+	}
+
+	// NOTE: once nothing closes emphasis opened at s[i], nothing closes it opened later either,
+	// so unclosed remembers where searching for each delimiter failed, keeping unclosed emphasis quick.
+	unclosed := make(map[string]int)
+
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == '\\' && i+1 < len(s) && s[i+1] == '\n':
+			flush()
+			r.open("br")
+			r.b.WriteString("\n")
+			i += 2
+
+		case c == '\\' && i+1 < len(s) && strings.IndexByte(punctuation, s[i+1]) >= 0:
+			text.WriteByte(s[i+1])
+			i += 2
+
+		case c == '\n':
+			trimmed := strings.TrimRight(text.String(), " ")
+			hard := text.Len()-len(trimmed) >= 2
+			text.Reset()
+			text.WriteString(trimmed)
+			flush()
+			if hard {
+				r.open("br")
+			}
+			r.b.WriteString("\n")
+			i++
+
+		case c == '`':
+			n := run(s[i:], '`')
+			end := closingRun(s, i+n, "`", n)
+			if end < 0 {
+				text.WriteString(s[i : i+n])
+				i += n
+				continue
+			}
+
+			flush()
+			r.open("code")
+			r.text(strings.ReplaceAll(s[i+n:end], "\n", " "))
+			r.close("code")
+			i = end + n
+
+		case c == '*' || c == '_':
+			n := run(s[i:], c)
+			end := -1
+			if delim := s[i : i+n]; n <= 2 && r.depth < mdMaxDepth && opensEmphasis(s, i, n) {
+				if from, ok := unclosed[delim]; !ok || i < from {
+					if end = closingEmphasis(s, i+n, delim); end < 0 {
+						unclosed[delim] = i
+					}
+				}
+			}
+
+			if end < 0 {
+				text.WriteString(s[i : i+n])
+				i += n
+				continue
+			}
+
+			elem := [...]string{1: "em", 2: "strong"}[n]
+			flush()
+			r.open(elem)
+			r.depth++
+			r.inlineIn(s[i+n:end], inLink)
+			r.depth--
+			r.close(elem)
+			i = end + n
+
+		case c == '!' && i+1 < len(s) && s[i+1] == '[':
+			label, dest, end, ok := parseLink(s, i+1, brackets()[i+1])
+			if !ok {
+				text.WriteByte(c)
+				i++
+				continue
+			}
+
+			flush()
+			if r.policy.allows("img") && r.policy.allowsURL(dest) {
+				r.open("img", "src", dest, "alt", label)
+			} else {
+				r.text(label)
+			}
+			i = end
+
+		case c == '[' && !inLink:
+			label, dest, end, ok := parseLink(s, i, brackets()[i])
+			if !ok {
+				text.WriteByte(c)
+				i++
+				continue
+			}
+
+			flush()
+			r.link(label, dest)
+			i = end
+
+		default:
+			text.WriteByte(c)
+			i++
+		}
+	}
+
+	flush()
+}
+
+// link renders a link to dest labeled label, or only label if the policy does not allow it.
+func (r *mdRenderer) link(label, dest string) {
+	if !r.policy.allows("a") || !r.policy.allowsURL(dest) {
+		r.inlineIn(label, true)
+		return
+	}
+
+	r.open("a", "href", dest)
+	r.inlineIn(label, true)
+	r.close("a")
+}
+
+// punctuation are the characters a backslash escapes.
+const punctuation = "!\"#$%&'()*+,-./:;<=>?@[\\]^_`{|}~"
+
+// run counts how many times c repeats at the start of s.
+func run(s string, c byte) int {
+	n := 0
+	for n < len(s) && s[n] == c {
+		n++
+	}
+
+	return n
+}
+
+// closingRun finds the next run of exactly n delim in s from i, or -1 if there is none.
+func closingRun(s string, i int, delim string, n int) int {
+	for i < len(s) {
+		j := strings.Index(s[i:], delim)
+		if j < 0 {
+			return -1
+		}
+
+		j += i
+		if m := run(s[j:], delim[0]); m != n {
+			i = j + m
+			continue
+		}
+
+		return j
+	}
+
+	return -1
+}
+
+// opensEmphasis asserts whether width delimiters at s[i] open emphasis.
+// Emphasis does not start with a space, and underscores do not emphasize within words.
+func opensEmphasis(s string, i, width int) bool {
+	open := i + width
+	if open >= len(s) || s[open] == ' ' || s[open] == '\n' {
+		return false
+	}
+
+	return s[i] != '_' || i == 0 || !isWordByte(s[i-1])
+}
+
+// closingEmphasis finds the run of delim closing emphasis opened before s[i], or -1 if there is none.
+// Emphasis does not end with a space, and underscores do not emphasize within words.
+func closingEmphasis(s string, i int, delim string) int {
+	for {
+		j := closingRun(s, i, delim, len(delim))
+		if j < 0 {
+			return -1
+		}
+
+		after := j + len(delim)
+		if j > i && strings.IndexByte(" \n\\", s[j-1]) < 0 &&
+			(delim[0] != '_' || after == len(s) || !isWordByte(s[after])) {
+			return j
+		}
+
+		i = after
+	}
+}
+
+// isWordByte asserts whether b is a letter or digit.
+func isWordByte(b byte) bool {
+	return b >= 'a' && b <= 'z' || b >= 'A' && b <= 'Z' || b >= '0' && b <= '9' || b >= 0x80
+}
+
+// matchBrackets pairs the unescaped brackets in s,
+// returning the index of the "]" closing each "[", or -1 where none does.
+func matchBrackets(s string) []int {
+	closing := make([]int, len(s))
+	for i := range closing {
+		closing[i] = -1
+	}
+
+	var open []int
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '[':
+			open = append(open, i)
+		case ']':
+			if len(open) > 0 {
+				closing[open[len(open)-1]] = i
+				open = open[:len(open)-1]
+			}
+		}
+	}
+
+	return closing
+}
+
+// mdMaxURLLen bounds how long the URL of a link or image is,
+// so rendering stays quick however many brackets the markdown has.
+const mdMaxURLLen = 2048
+
+// parseLink parses a link whose label is between the "[" at s[i] and the "]" at s[j],
+// e.g., [label](dest), returning where it ends.
+// Parentheses in dest must be balanced or escaped.
+func parseLink(s string, i, j int) (label, dest string, end int, ok bool) {
+	if j < 0 || j+1 >= len(s) || s[j+1] != '(' {
+		return "", "", 0, false
+	}
+
+	parens := 0
+	for k := j + 2; k < min(len(s), j+2+mdMaxURLLen) && s[k] > ' '; k++ {
+		switch s[k] {
+		case '\\':
+			k++
+		case '(':
+			parens++
+		case ')':
+			if parens == 0 {
+				return s[i+1 : j], unescape(s[j+2 : k]), k + 1, true
+			}
+			parens--
+		}
+	}
+
+	return "", "", 0, false
+}
+
+// unescape removes the backslashes escaping punctuation in s.
+func unescape(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) && strings.IndexByte(punctuation, s[i+1]) >= 0 {
+			i++
+		}
+		b.WriteByte(s[i])
+	}
+
+	return b.String()
+}
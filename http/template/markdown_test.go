@@ -0,0 +1,217 @@
+package template_test
+
+import (
+	"bytes"
+	"io"
+	"io/fs"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/xy-planning-network/trails/http/template"
+	tt "github.com/xy-planning-network/trails/http/template/templatetest"
+	"golang.org/x/net/html"
+)
+
+func TestMarkdownPolicyRender(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		policy   template.MarkdownPolicy
+		markdown string
+		expected string
+	}{
+		{
+			"Empty",
+			template.MarkdownBasic,
+			"",
+			"",
+		},
+		{
+			"Paragraphs",
+			template.MarkdownBasic,
+			"Some **strong**, _emphasized_, and __strong__ text.\nA hard  \nbreak.\\\nAnother.\n\nSnake_case_words and `co*de*`.",
+			"<p>Some <strong>strong</strong>, <em>emphasized</em>, and <strong>strong</strong> text.\nA hard<br>\nbreak.<br>\nAnother.</p>\n" +
+				"<p>Snake_case_words and <code>co*de*</code>.</p>",
+		},
+		{
+			"Nested-Emphasis",
+			template.MarkdownBasic,
+			`*a **b** c* and \*not\*`,
+			"<p><em>a <strong>b</strong> c</em> and *not*</p>",
+		},
+		{
+			"Unclosed-Emphasis",
+			template.MarkdownBasic,
+			"a * b *c* _d_ *e __f ***g***",
+			"<p>a * b <em>c</em> <em>d</em> *e __f ***g***</p>",
+		},
+		{
+			"Nesting-Limited",
+			template.MarkdownBasic,
+			strings.Repeat(">", 17) + " a",
+			strings.Repeat("<blockquote>\n", 16) + "<p>&gt; a</p>\n" + strings.Repeat("</blockquote>\n", 15) + "</blockquote>",
+		},
+		{
+			"Headings",
+			template.MarkdownRich,
+			"# Title #\n## Sub *title*\n#hashtag",
+			"<h1>Title</h1>\n<h2>Sub <em>title</em></h2>\n<p>#hashtag</p>",
+		},
+		{
+			"Headings-Disallowed",
+			template.MarkdownBasic,
+			"# Title\n\n---\n\ntext",
+			"<p>Title</p>\n<p>text</p>",
+		},
+		{
+			"Lists",
+			template.MarkdownBasic,
+			"- a\n- b\n  - c\n* d\n\n3. x\n4. y\n\nIn\n1999. What a year.",
+			"<ul>\n<li>a</li>\n<li>b\n<ul>\n<li>c</li>\n</ul></li>\n<li>d</li>\n</ul>\n" +
+				"<ol start=\"3\">\n<li>x</li>\n<li>y</li>\n</ol>\n<p>In\n1999. What a year.</p>",
+		},
+		{
+			"Lists-Continued",
+			template.MarkdownBasic,
+			"1. one\n\n2. two\n   continued\nlazily\n\nafter",
+			"<ol>\n<li>one</li>\n<li>two\ncontinued\nlazily</li>\n</ol>\n<p>after</p>",
+		},
+		{
+			"Blockquote-Code",
+			template.MarkdownBasic,
+			"> quoted\n> *text*\n\n```go\nfmt.Println(\"<hi>\")\n```",
+			"<blockquote>\n<p>quoted\n<em>text</em></p>\n</blockquote>\n" +
+				"<pre><code class=\"language-go\">fmt.Println(&#34;&lt;hi&gt;&#34;)\n</code></pre>",
+		},
+		{
+			"Links",
+			template.MarkdownBasic,
+			`[docs](https://example.com/a_(b)) [*home*](/) [\[x\]](/a\)) [titled](/ "Title") <https://example.com>`,
+			`<p><a href="https://example.com/a_(b)">docs</a> <a href="/"><em>home</em></a> <a href="/a)">[x]</a> ` +
+				`[titled](/ &#34;Title&#34;) &lt;https://example.com&gt;</p>`,
+		},
+		{
+			"Links-Too-Long",
+			template.MarkdownBasic,
+			"[x](/" + strings.Repeat("a", 2048) + ")",
+			"<p>[x](/" + strings.Repeat("a", 2048) + ")</p>",
+		},
+		{
+			"Images",
+			template.MarkdownRich,
+			`![a "logo"](/logo.png)`,
+			`<p><img src="/logo.png" alt="a &#34;logo&#34;"></p>`,
+		},
+		{
+			"Images-Disallowed",
+			template.MarkdownBasic,
+			`![a *logo*](/logo.png)`,
+			`<p>a *logo*</p>`,
+		},
+		{
+			"Sanitized",
+			template.MarkdownRich,
+			"<script>alert(1)</script>\n\n[click](javascript:alert(1)) [click](JavaScript:alert(1)) " +
+				`![x](data:image/svg+xml,x) [x](" onclick="alert(1))`,
+			"<p>&lt;script&gt;alert(1)&lt;/script&gt;</p>\n" +
+				`<p>click click x [x](&#34; onclick=&#34;alert(1))</p>`,
+		},
+		{
+			"Custom-Policy",
+			template.MarkdownPolicy{Elements: []string{"a"}, Schemes: []string{"tel"}},
+			"Call [us](tel:5555555555), **not** [them](https://example.com).",
+			`Call <a href="tel:5555555555">us</a>, not them.`,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			// Act
+			actual := tc.policy.Render(tc.markdown)
+
+			// Assert
+			require.Equal(t, tc.expected, string(actual))
+		})
+	}
+}
+
+func TestWithMarkdown(t *testing.T) {
+	// Arrange
+	p := template.NewParser(
+		[]fs.FS{tt.NewMockFS(tt.NewMockFile("help.tmpl", []byte(`<div>{{ markdown . }}</div>`)))},
+		template.WithMarkdown(template.MarkdownBasic),
+	)
+
+	tmpl, err := p.Parse("help.tmpl")
+	require.NoError(t, err)
+
+	b := new(bytes.Buffer)
+
+	// Act
+	err = tmpl.Execute(b, "Read the [docs](/docs).")
+
+	// Assert
+	require.NoError(t, err)
+	require.Equal(t, `<div><p>Read the <a href="/docs">docs</a>.</p></div>`, b.String())
+}
+
+func FuzzMarkdown(f *testing.F) {
+	for _, seed := range []string{
+		"Some **strong**, _emphasized_, and `code`.\n\n- a\n  - b\n1. c",
+		"# Title\n---\n> quoted\n\n```go\ncode\n```",
+		"[docs](https://example.com/a_(b)) ![logo](/logo.png)",
+		"<script>alert(1)</script>",
+		"<img src=x onerror=alert(1)>",
+		`[x](javascript:alert(1)) [x](JaVaScRiPt:alert(1)) [x](java\script:alert(1)) [x](&#106;avascript:alert(1))`,
+		`![x](data:image/svg+xml,<svg onload=alert(1)>) [x](" onclick="alert(1)) [x](/" onmouseover="alert(1))`,
+		"[*a*](/\"><script>alert(1)</script>) `<b onclick=x>`",
+		strings.Repeat("[", 64) + strings.Repeat("*", 64) + strings.Repeat(">", 64),
+	} {
+		f.Add(seed)
+	}
+
+	policy := template.MarkdownRich
+	attrs := []string{"alt", "class", "href", "src", "start"}
+
+	f.Fuzz(func(t *testing.T, markdown string) {
+		// Act
+		actual := string(policy.Render(markdown))
+
+		// Assert
+		// NOTE: text is escaped, so "javascript:" or "onclick=" in text is harmless,
+		// as is "javascript:" in the fragment of a relative URL; only the elements and attributes are checked.
+		require.NotContains(t, strings.ToLower(actual), "<script")
+
+		z := html.NewTokenizer(strings.NewReader(actual))
+		for {
+			kind := z.Next()
+			if kind == html.ErrorToken {
+				require.ErrorIs(t, z.Err(), io.EOF)
+				return
+			}
+
+			if kind != html.StartTagToken && kind != html.SelfClosingTagToken {
+				continue
+			}
+
+			tok := z.Token()
+			require.Contains(t, policy.Elements, tok.Data, actual)
+
+			for _, attr := range tok.Attr {
+				require.Contains(t, attrs, attr.Key, actual)
+				require.False(t, strings.HasPrefix(attr.Key, "on"), actual)
+
+				if attr.Key != "href" && attr.Key != "src" {
+					continue
+				}
+
+				require.False(t, strings.HasPrefix(strings.ToLower(attr.Val), "javascript:"), actual)
+
+				u, err := url.Parse(attr.Val)
+				require.NoError(t, err, actual)
+				if u.Scheme != "" {
+					require.Contains(t, policy.Schemes, u.Scheme, actual)
+				}
+			}
+		}
+	})
+}
@@ -13,15 +13,24 @@ type Parser struct {
 	fns   html.FuncMap
 }
 
+// A ParserOpt configures the *Parser NewParser constructs.
+type ParserOpt func(*Parser)
+
 // NewParser constructs a Parse with the fses and opts.
 // The order of fs.FS in fses matters.
 // The first reference to a filepath,
 // starting at the beginning of fses, is cached.
-func NewParser(fses []fs.FS) *Parser {
-	return &Parser{
+func NewParser(fses []fs.FS, opts ...ParserOpt) *Parser {
+	p := &Parser{
 		fns:   make(html.FuncMap),
 		cache: merge(fses),
 	}
+
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	return p
 }
 
 func (p *Parser) clone() *Parser {
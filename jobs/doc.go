@@ -0,0 +1,50 @@
+/*
+Package jobs runs background work for a trails app.
+
+# Overview
+
+A [Manager] runs named jobs: register a [Handler] for each name with [Manager.Register],
+then enqueue work with [Manager.Enqueue] from anywhere in the app, e.g., a web handler.
+[Manager.Start] begins a pool of workers, each taking a [Job] off a [Backend] and running its Handler.
+[Manager.Shutdown] stops taking jobs off the Backend and waits for those running to finish.
+
+	m := jobs.New(jobs.Config{})
+	m.Register("send-welcome", func(ctx context.Context, job jobs.Job) error {
+		var args struct{ UserID uint }
+		if err := job.Bind(&args); err != nil {
+			return jobs.Permanent(err)
+		}
+
+		return sendWelcome(ctx, args.UserID)
+	})
+
+	m.Enqueue(ctx, "send-welcome", map[string]any{"UserID": 1})
+
+# Retries
+
+A Handler returning an error is retried according to its [RetryPolicy],
+waiting longer between each attempt, until it runs out of attempts and is failed.
+Wrap an error with [Permanent] to fail a job without retrying it.
+
+# Scheduling
+
+[Manager.Schedule] enqueues a job on a cron-style schedule, e.g., "0 3 * * *" for 03:00 every day;
+cf. [ParseSchedule].
+Every Manager started with a schedule enqueues jobs on it,
+so when several processes share a Backend, schedule jobs in only one of them.
+
+# Backends
+
+A Backend stores jobs until a worker runs them.
+[MemoryBackend], used by default, stores them in-process: jobs are lost when the process exits.
+[PostgresBackend] stores them in PostgreSQL, where several processes can share them;
+it moves jobs failed for good to a dead-letter table.
+Other backends implement [Backend].
+
+# Tasks
+
+A [Scheduler] runs a [Task] in-process on a schedule, rather than enqueuing a job for a worker.
+Where several processes schedule the same tasks, a [PostgresTaskStore] ensures only one of them
+executes each run, using an advisory lock, and records the status of the latest run.
+*/
+package jobs
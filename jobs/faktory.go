@@ -0,0 +1,467 @@
+package jobs
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/xy-planning-network/trails"
+)
+
+const (
+	// DefaultFaktoryQueue is the queue a FaktoryBackend pushes jobs to and fetches them from by default.
+	DefaultFaktoryQueue = "default"
+
+	// DefaultFaktoryURL is the URL of the Faktory server a FaktoryBackend connects to by default.
+	DefaultFaktoryURL = "tcp://localhost:7419"
+
+	// faktoryBeatInterval is how often a FaktoryBackend tells Faktory it is alive while fetching jobs.
+	faktoryBeatInterval = 15 * time.Second
+
+	// faktoryDialTimeout bounds connecting to Faktory.
+	faktoryDialTimeout = 5 * time.Second
+)
+
+// FaktoryConfig configures a FaktoryBackend.
+type FaktoryConfig struct {
+	// Queues are the queues fetched from, in priority order;
+	// jobs are pushed to the first.
+	//
+	// If Queues is empty, DefaultFaktoryQueue is used.
+	Queues []string
+
+	// URL locates the Faktory server, e.g., "tcp://:password@localhost:7419";
+	// use the "tcp+tls" scheme to connect over TLS.
+	//
+	// If URL is empty, DefaultFaktoryURL is used.
+	URL string
+}
+
+// A FaktoryBackend is a [Backend] storing jobs in a Faktory server; cf. https://contribsys.com/faktory/.
+//
+// Jobs run by a Manager are pushed with Faktory's retries turned off:
+// the Manager's RetryPolicy retries them instead,
+// and failing a job sends it to Faktory's Dead set.
+//
+// Call Close once done with a FaktoryBackend.
+type FaktoryBackend struct {
+	cfg      FaktoryConfig
+	conns    chan *faktoryConn
+	password string
+	tls      bool
+	url      *url.URL
+	wid      string
+
+	beat   sync.Once
+	closed chan struct{}
+	stop   sync.Once
+}
+
+// NewFaktoryBackend constructs a *FaktoryBackend from cfg.
+// Connections to Faktory are made as needed.
+func NewFaktoryBackend(cfg FaktoryConfig) (*FaktoryBackend, error) {
+	if cfg.URL == "" {
+		cfg.URL = DefaultFaktoryURL
+	}
+
+	if len(cfg.Queues) == 0 {
+		cfg.Queues = []string{DefaultFaktoryQueue}
+	}
+
+	u, err := url.Parse(cfg.URL)
+	if err != nil || (u.Scheme != "tcp" && u.Scheme != "tcp+tls") || u.Host == "" {
+		return nil, fmt.Errorf("%w: invalid Faktory URL %q", trails.ErrBadConfig, cfg.URL)
+	}
+
+	b := &FaktoryBackend{
+		cfg:    cfg,
+		closed: make(chan struct{}),
+		conns:  make(chan *faktoryConn, DefaultConcurrency),
+		tls:    u.Scheme == "tcp+tls",
+		url:    u,
+		wid:    uuid.NewString(),
+	}
+
+	if pwd, ok := u.User.Password(); ok {
+		b.password = pwd
+	}
+
+	return b, nil
+}
+
+// faktoryJob is the JSON encoding of a Faktory job.
+type faktoryJob struct {
+	Args      []json.RawMessage `json:"args"`
+	At        string            `json:"at,omitempty"`
+	CreatedAt string            `json:"created_at,omitempty"`
+	Custom    map[string]any    `json:"custom,omitempty"`
+	JID       string            `json:"jid"`
+	JobType   string            `json:"jobtype"`
+	Queue     string            `json:"queue"`
+	Retry     *int              `json:"retry,omitempty"`
+}
+
+// Enqueue pushes job to the first of FaktoryConfig.Queues.
+func (b *FaktoryBackend) Enqueue(ctx context.Context, job Job) error {
+	return b.push(ctx, job)
+}
+
+// push sends job to Faktory under a new JID, recording its attempt.
+func (b *FaktoryBackend) push(ctx context.Context, job Job) error {
+	args := job.Args
+	if len(args) == 0 {
+		args = json.RawMessage("null")
+	}
+
+	// NOTE: with retry 0, Faktory sends a failed job straight to its Dead set.
+	retry := 0
+	fj := faktoryJob{
+		Args:      []json.RawMessage{args},
+		CreatedAt: time.Now().UTC().Format(time.RFC3339Nano),
+		Custom:    map[string]any{"attempt": max(job.Attempt, 1)},
+		JID:       uuid.NewString(),
+		JobType:   job.Name,
+		Queue:     b.cfg.Queues[0],
+		Retry:     &retry,
+	}
+
+	if time.Until(job.RunAt) > 0 {
+		fj.At = job.RunAt.UTC().Format(time.RFC3339Nano)
+	}
+
+	_, err := b.do(ctx, "PUSH", fj)
+	if err != nil {
+		return fmt.Errorf("could not push job: %w", err)
+	}
+
+	return nil
+}
+
+// Dequeue fetches a job from FaktoryConfig.Queues,
+// asking again each time Faktory responds with none until ctx is done.
+//
+// Once a FaktoryBackend dequeues, it sends Faktory a heartbeat in the background until closed.
+func (b *FaktoryBackend) Dequeue(ctx context.Context) (Job, error) {
+	b.beat.Do(func() { go b.heartbeat() })
+
+	args := make([]any, len(b.cfg.Queues))
+	for i, q := range b.cfg.Queues {
+		args[i] = q
+	}
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return Job{}, err
+		}
+
+		res, err := b.do(ctx, "FETCH", args...)
+		if err != nil {
+			if ctx.Err() != nil {
+				return Job{}, ctx.Err()
+			}
+
+			return Job{}, fmt.Errorf("could not fetch job: %w", err)
+		}
+
+		if res == nil {
+			continue
+		}
+
+		var fj faktoryJob
+		if err := json.Unmarshal(res, &fj); err != nil {
+			return Job{}, fmt.Errorf("could not decode Faktory job: %w", err)
+		}
+
+		job := Job{Attempt: 1, ID: fj.JID, Name: fj.JobType, RunAt: time.Now()}
+		if len(fj.Args) > 0 {
+			job.Args = fj.Args[0]
+		}
+
+		if attempt, ok := fj.Custom["attempt"].(float64); ok && attempt > 1 {
+			job.Attempt = int(attempt)
+		}
+
+		return job, nil
+	}
+}
+
+// Complete acknowledges job.
+func (b *FaktoryBackend) Complete(ctx context.Context, job Job) error {
+	if _, err := b.do(ctx, "ACK", map[string]string{"jid": job.ID}); err != nil {
+		return fmt.Errorf("could not acknowledge job %s: %w", job.ID, err)
+	}
+
+	return nil
+}
+
+// Fail reports job failed, sending it to Faktory's Dead set.
+func (b *FaktoryBackend) Fail(ctx context.Context, job Job, jobErr error) error {
+	payload := map[string]any{"jid": job.ID, "errtype": "error", "message": ""}
+	if jobErr != nil {
+		payload["errtype"] = fmt.Sprintf("%T", jobErr)
+		payload["message"] = jobErr.Error()
+	}
+
+	if _, err := b.do(ctx, "FAIL", payload); err != nil {
+		return fmt.Errorf("could not fail job %s: %w", job.ID, err)
+	}
+
+	return nil
+}
+
+// Retry pushes job to run again at job.RunAt, then acknowledges the attempt that failed.
+func (b *FaktoryBackend) Retry(ctx context.Context, job Job, _ error) error {
+	if err := b.push(ctx, job); err != nil {
+		return err
+	}
+
+	return b.Complete(ctx, job)
+}
+
+// Close stops the heartbeat and closes connections to Faktory.
+func (b *FaktoryBackend) Close() error {
+	b.stop.Do(func() { close(b.closed) })
+
+	var errs []error
+	for {
+		select {
+		case c := <-b.conns:
+			errs = append(errs, c.close())
+		default:
+			return errors.Join(errs...)
+		}
+	}
+}
+
+// heartbeat tells Faktory the FaktoryBackend is alive every faktoryBeatInterval until closed.
+func (b *FaktoryBackend) heartbeat() {
+	ticker := time.NewTicker(faktoryBeatInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-b.closed:
+			return
+		case <-ticker.C:
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), faktoryDialTimeout)
+		// NOTE: a missed heartbeat is retried on the next tick.
+		_, _ = b.do(ctx, "BEAT", map[string]string{"wid": b.wid})
+		cancel()
+	}
+}
+
+// do sends cmd with args, each JSON encoded unless a string, over a pooled connection,
+// returning the response.
+func (b *FaktoryBackend) do(ctx context.Context, cmd string, args ...any) ([]byte, error) {
+	c, err := b.conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	stop := context.AfterFunc(ctx, func() { c.nc.SetDeadline(time.Now()) })
+	res, err := c.do(cmd, args...)
+	if !stop() || err != nil {
+		// NOTE: the connection may be mid-response, so it cannot be reused.
+		c.close()
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+
+		return nil, err
+	}
+
+	select {
+	case <-b.closed:
+		c.close()
+	case b.conns <- c:
+	default:
+		c.close()
+	}
+
+	return res, nil
+}
+
+// conn takes a connection from the pool, or opens one.
+func (b *FaktoryBackend) conn(ctx context.Context) (*faktoryConn, error) {
+	select {
+	case <-b.closed:
+		return nil, net.ErrClosed
+	case c := <-b.conns:
+		return c, nil
+	default:
+	}
+
+	d := &net.Dialer{Timeout: faktoryDialTimeout}
+	var (
+		nc  net.Conn
+		err error
+	)
+	if b.tls {
+		td := &tls.Dialer{NetDialer: d, Config: &tls.Config{ServerName: b.url.Hostname()}}
+		nc, err = td.DialContext(ctx, "tcp", b.url.Host)
+	} else {
+		nc, err = d.DialContext(ctx, "tcp", b.url.Host)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not connect to Faktory: %w", err)
+	}
+
+	c := &faktoryConn{nc: nc, r: bufio.NewReader(nc)}
+	if err := c.hello(b.wid, b.password); err != nil {
+		c.close()
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// faktoryConn is a connection to Faktory speaking its line-based protocol.
+type faktoryConn struct {
+	nc net.Conn
+	r  *bufio.Reader
+}
+
+// hello completes Faktory's handshake, authenticating with password if Faktory requires it.
+func (c *faktoryConn) hello(wid, password string) error {
+	line, err := c.readLine()
+	if err != nil {
+		return fmt.Errorf("could not greet Faktory: %w", err)
+	}
+
+	hi, ok := strings.CutPrefix(line, "+HI ")
+	if !ok {
+		return fmt.Errorf("unexpected Faktory greeting %q", line)
+	}
+
+	var greeting struct {
+		Iterations int    `json:"i"`
+		Salt       string `json:"s"`
+		Version    int    `json:"v"`
+	}
+	if err := json.Unmarshal([]byte(hi), &greeting); err != nil {
+		return fmt.Errorf("could not decode Faktory greeting: %w", err)
+	}
+
+	hostname, _ := os.Hostname()
+	payload := map[string]any{
+		"hostname": hostname,
+		"labels":   []string{"golang", "trails"},
+		"pid":      os.Getpid(),
+		"v":        2,
+		"wid":      wid,
+	}
+
+	if greeting.Salt != "" {
+		if password == "" {
+			return fmt.Errorf("%w: Faktory requires a password", trails.ErrBadConfig)
+		}
+
+		payload["pwdhash"] = faktoryPasswordHash(password, greeting.Salt, greeting.Iterations)
+	}
+
+	if _, err := c.do("HELLO", payload); err != nil {
+		return fmt.Errorf("could not authenticate with Faktory: %w", err)
+	}
+
+	return nil
+}
+
+// faktoryPasswordHash hashes password with salt as Faktory expects:
+// SHA-256 of password followed by salt, rehashed until iterations hashes are done.
+func faktoryPasswordHash(password, salt string, iterations int) string {
+	sum := sha256.Sum256([]byte(password + salt))
+	for i := 1; i < iterations; i++ {
+		sum = sha256.Sum256(sum[:])
+	}
+
+	return hex.EncodeToString(sum[:])
+}
+
+// do writes cmd with args and reads the response:
+// nil for an empty or simple response, or the payload of a bulk one.
+func (c *faktoryConn) do(cmd string, args ...any) ([]byte, error) {
+	var sb strings.Builder
+	sb.WriteString(cmd)
+	for _, arg := range args {
+		sb.WriteByte(' ')
+		if s, ok := arg.(string); ok {
+			sb.WriteString(s)
+			continue
+		}
+
+		b, err := json.Marshal(arg)
+		if err != nil {
+			return nil, err
+		}
+		sb.Write(b)
+	}
+	sb.WriteString("\r\n")
+
+	if _, err := io.WriteString(c.nc, sb.String()); err != nil {
+		return nil, err
+	}
+
+	line, err := c.readLine()
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case strings.HasPrefix(line, "+"):
+		return nil, nil
+	case strings.HasPrefix(line, "-"):
+		return nil, fmt.Errorf("faktory: %s", strings.TrimPrefix(line, "-"))
+	case strings.HasPrefix(line, "$"):
+		n, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, fmt.Errorf("unexpected Faktory response %q", line)
+		}
+
+		if n < 0 {
+			return nil, nil
+		}
+
+		buf := make([]byte, n+2)
+		if _, err := io.ReadFull(c.r, buf); err != nil {
+			return nil, err
+		}
+
+		return buf[:n], nil
+	default:
+		return nil, fmt.Errorf("unexpected Faktory response %q", line)
+	}
+}
+
+// readLine reads a line, sans "\r\n".
+func (c *faktoryConn) readLine() (string, error) {
+	line, err := c.r.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// close says goodbye to Faktory and closes the connection.
+func (c *faktoryConn) close() error {
+	c.nc.SetWriteDeadline(time.Now().Add(time.Second))
+	io.WriteString(c.nc, "END\r\n")
+
+	return c.nc.Close()
+}
@@ -0,0 +1,246 @@
+package jobs_test
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/xy-planning-network/trails"
+	"github.com/xy-planning-network/trails/jobs"
+)
+
+// fakeFaktory is a Faktory server recording the commands it receives
+// and responding to FETCH with the jobs pushed to it, waiting briefly for one as Faktory does.
+type fakeFaktory struct {
+	cmds   chan string
+	jobs   chan string
+	ln     net.Listener
+	salt   string
+	hashes chan string
+}
+
+func newFakeFaktory(t *testing.T, salt string) *fakeFaktory {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.Nil(t, err)
+	t.Cleanup(func() { ln.Close() })
+
+	f := &fakeFaktory{
+		cmds:   make(chan string, 100),
+		hashes: make(chan string, 10),
+		jobs:   make(chan string, 10),
+		ln:     ln,
+		salt:   salt,
+	}
+	go f.serve()
+
+	return f
+}
+
+func (f *fakeFaktory) serve() {
+	for {
+		c, err := f.ln.Accept()
+		if err != nil {
+			return
+		}
+
+		go f.handle(c)
+	}
+}
+
+func (f *fakeFaktory) handle(c net.Conn) {
+	defer c.Close()
+
+	if f.salt != "" {
+		fmt.Fprintf(c, "+HI {\"v\":2,\"s\":%q,\"i\":2}\r\n", f.salt)
+	} else {
+		fmt.Fprint(c, "+HI {\"v\":2}\r\n")
+	}
+
+	r := bufio.NewReader(c)
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+
+		cmd, payload, _ := strings.Cut(strings.TrimSpace(line), " ")
+		select {
+		case f.cmds <- cmd:
+		default:
+		}
+
+		switch cmd {
+		case "HELLO":
+			var hello struct {
+				PwdHash string `json:"pwdhash"`
+			}
+			json.Unmarshal([]byte(payload), &hello)
+			select {
+			case f.hashes <- hello.PwdHash:
+			default:
+			}
+			fmt.Fprint(c, "+OK\r\n")
+		case "PUSH":
+			f.jobs <- payload
+			fmt.Fprint(c, "+OK\r\n")
+		case "FETCH":
+			select {
+			case job := <-f.jobs:
+				fmt.Fprintf(c, "$%d\r\n%s\r\n", len(job), job)
+			case <-time.After(10 * time.Millisecond):
+				fmt.Fprint(c, "$-1\r\n")
+			}
+		case "END":
+			return
+		default:
+			fmt.Fprint(c, "+OK\r\n")
+		}
+	}
+}
+
+// next returns the next command received other than HELLO.
+func (f *fakeFaktory) next(t *testing.T) string {
+	t.Helper()
+
+	for {
+		if cmd := receive(t, f.cmds); cmd != "HELLO" {
+			return cmd
+		}
+	}
+}
+
+func TestFaktoryBackend(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	f := newFakeFaktory(t, "")
+
+	b, err := jobs.NewFaktoryBackend(jobs.FaktoryConfig{URL: "tcp://" + f.ln.Addr().String()})
+	require.Nil(t, err)
+	defer b.Close()
+
+	// Act
+	err = b.Enqueue(ctx, jobs.Job{Args: json.RawMessage(`{"UserID":1}`), Attempt: 2, Name: "welcome"})
+
+	// Assert
+	require.Nil(t, err)
+	require.Equal(t, "PUSH", f.next(t))
+
+	// Act
+	job, err := b.Dequeue(ctx)
+
+	// Assert
+	require.Nil(t, err)
+	require.Equal(t, "FETCH", f.next(t))
+	require.Equal(t, "welcome", job.Name)
+	require.Equal(t, 2, job.Attempt)
+	require.JSONEq(t, `{"UserID":1}`, string(job.Args))
+	require.NotEmpty(t, job.ID)
+
+	// Act
+	err = b.Complete(ctx, job)
+
+	// Assert
+	require.Nil(t, err)
+	require.Equal(t, "ACK", f.next(t))
+
+	// Act
+	err = b.Fail(ctx, job, errors.New("oops"))
+
+	// Assert
+	require.Nil(t, err)
+	require.Equal(t, "FAIL", f.next(t))
+
+	// Act
+	err = b.Retry(ctx, job, errors.New("oops"))
+
+	// Assert
+	require.Nil(t, err)
+	require.Equal(t, "PUSH", f.next(t))
+	require.Equal(t, "ACK", f.next(t))
+}
+
+func TestFaktoryBackendPassword(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	f := newFakeFaktory(t, "salty")
+
+	first := sha256.Sum256([]byte("secret" + "salty"))
+	expected := sha256.Sum256(first[:])
+
+	b, err := jobs.NewFaktoryBackend(jobs.FaktoryConfig{URL: "tcp://:secret@" + f.ln.Addr().String()})
+	require.Nil(t, err)
+	defer b.Close()
+
+	// Act
+	err = b.Enqueue(ctx, jobs.Job{Name: "welcome"})
+
+	// Assert
+	require.Nil(t, err)
+	require.Equal(t, hex.EncodeToString(expected[:]), receive(t, f.hashes))
+}
+
+func TestFaktoryBackendMissingPassword(t *testing.T) {
+	// Arrange
+	f := newFakeFaktory(t, "salty")
+
+	b, err := jobs.NewFaktoryBackend(jobs.FaktoryConfig{URL: "tcp://" + f.ln.Addr().String()})
+	require.Nil(t, err)
+	defer b.Close()
+
+	// Act
+	err = b.Enqueue(context.Background(), jobs.Job{Name: "welcome"})
+
+	// Assert
+	require.ErrorIs(t, err, trails.ErrBadConfig)
+}
+
+func TestNewFaktoryBackendBadURL(t *testing.T) {
+	for _, url := range []string{"http://localhost:7419", "tcp://", "://"} {
+		t.Run(url, func(t *testing.T) {
+			// Act
+			b, err := jobs.NewFaktoryBackend(jobs.FaktoryConfig{URL: url})
+
+			// Assert
+			require.ErrorIs(t, err, trails.ErrBadConfig)
+			require.Nil(t, b)
+		})
+	}
+}
+
+func TestManagerWithFaktory(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	f := newFakeFaktory(t, "")
+
+	b, err := jobs.NewFaktoryBackend(jobs.FaktoryConfig{URL: "tcp://" + f.ln.Addr().String()})
+	require.Nil(t, err)
+	defer b.Close()
+
+	m := newTestManager(b)
+	ran := make(chan jobs.Job, 1)
+	m.Register("welcome", func(_ context.Context, job jobs.Job) error {
+		ran <- job
+		return nil
+	})
+
+	m.Start(ctx)
+	defer m.Shutdown(ctx)
+
+	// Act
+	err = m.Enqueue(ctx, "welcome", map[string]uint{"UserID": 1})
+
+	// Assert
+	require.Nil(t, err)
+	require.JSONEq(t, `{"UserID":1}`, string(receive(t, ran).Args))
+}
@@ -0,0 +1,78 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// A Job is a unit of work for the Handler registered under its Name.
+type Job struct {
+	// Args is the JSON encoding of the arguments the Job was enqueued with.
+	Args json.RawMessage
+
+	// Attempt counts the times the Job has been run, including this one, starting at 1.
+	Attempt int
+
+	// ID identifies the Job; a Backend assigns it when enqueuing the Job.
+	ID string
+
+	// Name is the name of the Handler running the Job.
+	Name string
+
+	// RunAt is the earliest time the Job runs at.
+	RunAt time.Time
+}
+
+// Bind decodes the Job's Args into v.
+func (j Job) Bind(v any) error { return json.Unmarshal(j.Args, v) }
+
+// A Handler runs a Job, returning an error if the Job ought to be retried or failed.
+//
+// ctx is cancelled when the Manager running the Job gives up waiting for it to finish on shutdown
+// or when the Handler runs longer than the timeout it was registered with.
+type Handler func(ctx context.Context, job Job) error
+
+// A Backend stores jobs until a worker runs them.
+//
+// Implementations must be safe for concurrent use.
+type Backend interface {
+	// Enqueue stores job to be run at job.RunAt, identifying it by a new ID.
+	Enqueue(ctx context.Context, job Job) error
+
+	// Dequeue claims the next job due to run,
+	// blocking until one is due or ctx is done, in which case ctx.Err() is returned.
+	Dequeue(ctx context.Context) (Job, error)
+
+	// Complete records job ran successfully.
+	Complete(ctx context.Context, job Job) error
+
+	// Fail records job ran unsuccessfully and will not be retried.
+	Fail(ctx context.Context, job Job, err error) error
+
+	// Retry stores job to be run again at job.RunAt, after failing with err.
+	Retry(ctx context.Context, job Job, err error) error
+}
+
+// permanentError wraps an error a Job ought not be retried after.
+type permanentError struct{ err error }
+
+func (e permanentError) Error() string { return e.err.Error() }
+func (e permanentError) Unwrap() error { return e.err }
+
+// Permanent wraps err so the Job a Handler returns it for fails without being retried.
+// If err is nil, Permanent returns nil.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	return permanentError{err}
+}
+
+// IsPermanent reports whether err, or any error it wraps, came from Permanent.
+func IsPermanent(err error) bool {
+	var pe permanentError
+	return errors.As(err, &pe)
+}
@@ -0,0 +1,416 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/xy-planning-network/trails"
+	"github.com/xy-planning-network/trails/logger"
+)
+
+const (
+	// DefaultConcurrency is the number of jobs a Manager runs at once by default.
+	DefaultConcurrency = 10
+
+	// dequeueRetryInterval is how long a worker waits after its Backend fails to dequeue a Job.
+	dequeueRetryInterval = time.Second
+)
+
+// Config configures a Manager.
+type Config struct {
+	// Backend stores jobs until they are run.
+	//
+	// If Backend is nil, a *MemoryBackend is used.
+	Backend Backend
+
+	// Concurrency is the number of jobs run at once.
+	//
+	// If Concurrency is 0, DefaultConcurrency is used.
+	Concurrency int
+
+	// Logger logs jobs failing.
+	//
+	// If Logger is nil, one writing to slog.Default is used.
+	Logger logger.Logger
+
+	// Retry is the RetryPolicy of jobs registered without WithRetry.
+	Retry RetryPolicy
+}
+
+// A Manager runs jobs with a pool of workers and enqueues jobs on schedules.
+//
+// A Manager is safe for concurrent use.
+type Manager struct {
+	backend     Backend
+	concurrency int
+	logger      logger.Logger
+	retry       RetryPolicy
+
+	mu        sync.RWMutex
+	abort     context.CancelFunc
+	handlers  map[string]registration
+	runCtx    context.Context
+	schedules []scheduled
+	started   bool
+	stop      context.CancelFunc
+	stopCtx   context.Context
+	wg        sync.WaitGroup
+	working   bool
+}
+
+// New constructs a *Manager from cfg.
+func New(cfg Config) *Manager {
+	if cfg.Backend == nil {
+		cfg.Backend = NewMemoryBackend()
+	}
+
+	if cfg.Concurrency <= 0 {
+		cfg.Concurrency = DefaultConcurrency
+	}
+
+	if cfg.Logger == nil {
+		cfg.Logger = logger.New(slog.Default(), trails.Production)
+	}
+
+	return &Manager{
+		backend:     cfg.Backend,
+		concurrency: cfg.Concurrency,
+		handlers:    make(map[string]registration),
+		logger:      cfg.Logger,
+		retry:       cfg.Retry.withDefaults(),
+	}
+}
+
+// Backend returns the Backend the Manager stores jobs in.
+func (m *Manager) Backend() Backend { return m.backend }
+
+// registration is a Handler and how to run it.
+type registration struct {
+	h       Handler
+	retry   RetryPolicy
+	timeout time.Duration
+}
+
+// A JobOpt configures how a Manager runs the jobs of a Handler.
+type JobOpt func(*registration)
+
+// WithRetry overrides [Config].Retry for a Handler's jobs.
+func WithRetry(p RetryPolicy) JobOpt {
+	return func(reg *registration) { reg.retry = p.withDefaults() }
+}
+
+// WithTimeout cancels the context.Context of a Handler's jobs after d.
+func WithTimeout(d time.Duration) JobOpt {
+	return func(reg *registration) { reg.timeout = d }
+}
+
+// Register makes h run jobs enqueued under name,
+// replacing any Handler already registered under name.
+func (m *Manager) Register(name string, h Handler, opts ...JobOpt) {
+	reg := registration{h: h, retry: m.retry}
+	for _, opt := range opts {
+		opt(&reg)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.handlers[name] = reg
+	if m.started {
+		m.startWorkers()
+	}
+}
+
+// Registered lists the names Handlers are registered under.
+func (m *Manager) Registered() []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	names := make([]string, 0, len(m.handlers))
+	for name := range m.handlers {
+		names = append(names, name)
+	}
+
+	return names
+}
+
+// An EnqueueOpt configures a Job being enqueued.
+type EnqueueOpt func(*Job)
+
+// At runs the Job no earlier than t.
+func At(t time.Time) EnqueueOpt {
+	return func(j *Job) { j.RunAt = t }
+}
+
+// In runs the Job no earlier than d from now.
+func In(d time.Duration) EnqueueOpt {
+	return func(j *Job) { j.RunAt = time.Now().Add(d) }
+}
+
+// Enqueue stores a Job for the Handler registered under name to run with args, encoded as JSON.
+//
+// The Handler need not be registered with this Manager,
+// e.g., when a web server enqueues jobs a separate worker process runs.
+func (m *Manager) Enqueue(ctx context.Context, name string, args any, opts ...EnqueueOpt) error {
+	b, err := json.Marshal(args)
+	if err != nil {
+		return fmt.Errorf("%w: could not encode args of job %q: %s", trails.ErrNotValid, name, err)
+	}
+
+	job := Job{Args: b, Attempt: 1, Name: name, RunAt: time.Now()}
+	for _, opt := range opts {
+		opt(&job)
+	}
+
+	if err := m.backend.Enqueue(ctx, job); err != nil {
+		return fmt.Errorf("could not enqueue job %q: %w", name, err)
+	}
+
+	return nil
+}
+
+// scheduled is a job enqueued on a Schedule.
+type scheduled struct {
+	args json.RawMessage
+	name string
+	s    Schedule
+	spec string
+}
+
+// Schedule enqueues a Job for the Handler registered under name to run with args, encoded as JSON,
+// on the Schedule spec describes; cf. [ParseSchedule].
+//
+// Jobs are enqueued while the Manager is started.
+func (m *Manager) Schedule(spec, name string, args any) error {
+	s, err := ParseSchedule(spec)
+	if err != nil {
+		return err
+	}
+
+	b, err := json.Marshal(args)
+	if err != nil {
+		return fmt.Errorf("%w: could not encode args of job %q: %s", trails.ErrNotValid, name, err)
+	}
+
+	sch := scheduled{args: b, name: name, s: s, spec: spec}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.schedules = append(m.schedules, sch)
+	if m.started {
+		m.wg.Add(1)
+		go m.schedule(m.stopCtx, sch)
+	}
+
+	return nil
+}
+
+// Start begins running jobs and enqueuing scheduled ones in the background
+// until ctx is done or Shutdown is called.
+// Workers only begin running jobs once a Handler is registered,
+// so a Manager that only enqueues jobs for another process leaves them be.
+//
+// Jobs already running when ctx is done continue running; Shutdown waits for them.
+//
+// Start does nothing if the Manager is started.
+func (m *Manager) Start(ctx context.Context) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.started {
+		return
+	}
+	m.started = true
+
+	m.stopCtx, m.stop = context.WithCancel(ctx)
+	m.runCtx, m.abort = context.WithCancel(context.WithoutCancel(ctx))
+
+	if len(m.handlers) > 0 {
+		m.startWorkers()
+	}
+
+	for _, sch := range m.schedules {
+		m.wg.Add(1)
+		go m.schedule(m.stopCtx, sch)
+	}
+}
+
+// startWorkers begins the pool of workers running jobs, if not already begun.
+//
+// The caller must hold m.mu.
+func (m *Manager) startWorkers() {
+	if m.working {
+		return
+	}
+	m.working = true
+
+	for range m.concurrency {
+		m.wg.Add(1)
+		go m.work(m.stopCtx)
+	}
+}
+
+// Shutdown stops running new jobs and enqueuing scheduled ones,
+// waiting for those running to finish or ctx to be done.
+// If ctx is done first, Shutdown cancels the context.Context of the jobs still running
+// and returns ctx.Err().
+//
+// Shutdown matches the signature of ranger.ShutdownFn.
+func (m *Manager) Shutdown(ctx context.Context) error {
+	m.mu.Lock()
+	if !m.started {
+		m.mu.Unlock()
+		return nil
+	}
+	m.started = false
+	m.working = false
+	m.stop()
+	abort := m.abort
+	m.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		m.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		abort()
+		return nil
+	case <-ctx.Done():
+		abort()
+		return fmt.Errorf("could not wait for jobs to finish: %w", ctx.Err())
+	}
+}
+
+// work runs jobs off the Backend until ctx is done.
+func (m *Manager) work(ctx context.Context) {
+	defer m.wg.Done()
+	defer logger.CapturePanics(m.logger)
+
+	for {
+		job, err := m.backend.Dequeue(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+
+			m.logger.Error("could not dequeue job: "+err.Error(), &logger.LogContext{Error: err})
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(dequeueRetryInterval):
+			}
+
+			continue
+		}
+
+		m.run(job)
+	}
+}
+
+// run runs job with the Handler registered under its name,
+// then completes, retries, or fails it.
+func (m *Manager) run(job Job) {
+	m.mu.RLock()
+	reg, ok := m.handlers[job.Name]
+	runCtx := m.runCtx
+	m.mu.RUnlock()
+
+	// NOTE: keep recording outcomes even once running jobs are aborted.
+	bg := context.WithoutCancel(runCtx)
+	data := map[string]any{"attempt": job.Attempt, "id": job.ID, "name": job.Name}
+
+	if !ok {
+		err := fmt.Errorf("%w: no Handler registered for job %q", trails.ErrNotExist, job.Name)
+		m.fail(bg, job, err, data)
+		return
+	}
+
+	ctx := runCtx
+	if reg.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, reg.timeout)
+		defer cancel()
+	}
+
+	start := time.Now()
+	err := call(ctx, reg.h, job)
+	data["duration"] = time.Since(start).String()
+
+	if err == nil {
+		m.logger.Debug(fmt.Sprintf("job %q finished", job.Name), &logger.LogContext{Data: data})
+		if err := m.backend.Complete(bg, job); err != nil {
+			m.logger.Error("could not complete job: "+err.Error(), &logger.LogContext{Data: data, Error: err})
+		}
+
+		return
+	}
+
+	if IsPermanent(err) || job.Attempt >= reg.retry.MaxAttempts {
+		m.fail(bg, job, err, data)
+		return
+	}
+
+	job.RunAt = time.Now().Add(reg.retry.Backoff(job.Attempt))
+	job.Attempt++
+	data["retryAt"] = job.RunAt
+
+	m.logger.Warn(fmt.Sprintf("job %q failed, retrying: %s", job.Name, err), &logger.LogContext{Data: data, Error: err})
+	if err := m.backend.Retry(bg, job, err); err != nil {
+		m.logger.Error("could not retry job: "+err.Error(), &logger.LogContext{Data: data, Error: err})
+	}
+}
+
+// fail records job failed with err.
+func (m *Manager) fail(ctx context.Context, job Job, err error, data map[string]any) {
+	m.logger.Error(fmt.Sprintf("job %q failed: %s", job.Name, err), &logger.LogContext{Data: data, Error: err})
+	if err := m.backend.Fail(ctx, job, err); err != nil {
+		m.logger.Error("could not fail job: "+err.Error(), &logger.LogContext{Data: data, Error: err})
+	}
+}
+
+// call runs h, converting a panic into an error.
+func call(ctx context.Context, h Handler, job Job) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("job %q panicked: %v", job.Name, p)
+		}
+	}()
+
+	return h(ctx, job)
+}
+
+// schedule enqueues sch's job each time its Schedule comes due, until ctx is done.
+func (m *Manager) schedule(ctx context.Context, sch scheduled) {
+	defer m.wg.Done()
+	defer logger.CapturePanics(m.logger)
+
+	for {
+		next := sch.s.Next(time.Now())
+		if next.IsZero() {
+			return
+		}
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		job := Job{Args: sch.args, Attempt: 1, Name: sch.name, RunAt: next}
+		if err := m.backend.Enqueue(ctx, job); err != nil {
+			data := map[string]any{"name": sch.name, "schedule": sch.spec}
+			m.logger.Error("could not enqueue scheduled job: "+err.Error(), &logger.LogContext{Data: data, Error: err})
+		}
+	}
+}
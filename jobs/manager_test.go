@@ -0,0 +1,291 @@
+package jobs_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/xy-planning-network/trails"
+	"github.com/xy-planning-network/trails/jobs"
+	"github.com/xy-planning-network/trails/logger"
+)
+
+// recordingBackend is a *jobs.MemoryBackend reporting the outcome of each Job.
+type recordingBackend struct {
+	*jobs.MemoryBackend
+	completed chan jobs.Job
+	failed    chan error
+	retried   chan jobs.Job
+}
+
+func newRecordingBackend() *recordingBackend {
+	return &recordingBackend{
+		MemoryBackend: jobs.NewMemoryBackend(),
+		completed:     make(chan jobs.Job, 10),
+		failed:        make(chan error, 10),
+		retried:       make(chan jobs.Job, 10),
+	}
+}
+
+func (b *recordingBackend) Complete(_ context.Context, job jobs.Job) error {
+	b.completed <- job
+	return nil
+}
+
+func (b *recordingBackend) Fail(_ context.Context, _ jobs.Job, err error) error {
+	b.failed <- err
+	return nil
+}
+
+func (b *recordingBackend) Retry(ctx context.Context, job jobs.Job, err error) error {
+	b.retried <- job
+	return b.MemoryBackend.Retry(ctx, job, err)
+}
+
+func newTestManager(b jobs.Backend) *jobs.Manager {
+	return jobs.New(jobs.Config{
+		Backend: b,
+		Logger:  logger.New(slog.New(slog.NewTextHandler(io.Discard, nil)), trails.Testing),
+		Retry:   jobs.RetryPolicy{Backoff: jobs.ConstantBackoff(0), MaxAttempts: 3},
+	})
+}
+
+// receive waits a short time for a value on ch.
+func receive[T any](t *testing.T, ch <-chan T) T {
+	t.Helper()
+
+	select {
+	case v := <-ch:
+		return v
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting")
+		panic("unreachable")
+	}
+}
+
+func TestManagerRunsJob(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	b := newRecordingBackend()
+	m := newTestManager(b)
+
+	type args struct{ UserID uint }
+	got := make(chan args, 1)
+	m.Register("welcome", func(_ context.Context, job jobs.Job) error {
+		var a args
+		if err := job.Bind(&a); err != nil {
+			return err
+		}
+
+		got <- a
+		return nil
+	})
+
+	m.Start(ctx)
+	defer m.Shutdown(ctx)
+
+	// Act
+	err := m.Enqueue(ctx, "welcome", args{UserID: 1})
+
+	// Assert
+	require.Nil(t, err)
+	require.Equal(t, args{UserID: 1}, receive(t, got))
+	require.Equal(t, 1, receive(t, b.completed).Attempt)
+}
+
+func TestManagerRetries(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	b := newRecordingBackend()
+	m := newTestManager(b)
+
+	m.Register("flaky", func(_ context.Context, job jobs.Job) error {
+		if job.Attempt < 3 {
+			return errors.New("not yet")
+		}
+
+		return nil
+	})
+
+	m.Start(ctx)
+	defer m.Shutdown(ctx)
+
+	// Act
+	require.Nil(t, m.Enqueue(ctx, "flaky", nil))
+
+	// Assert
+	require.Equal(t, 2, receive(t, b.retried).Attempt)
+	require.Equal(t, 3, receive(t, b.retried).Attempt)
+	require.Equal(t, 3, receive(t, b.completed).Attempt)
+}
+
+func TestManagerFails(t *testing.T) {
+	tcs := []struct {
+		name     string
+		job      string
+		h        jobs.Handler
+		expected error
+	}{
+		{
+			"out-of-attempts",
+			"broken",
+			func(context.Context, jobs.Job) error { return io.ErrUnexpectedEOF },
+			io.ErrUnexpectedEOF,
+		},
+		{
+			"permanent",
+			"broken",
+			func(context.Context, jobs.Job) error { return jobs.Permanent(io.EOF) },
+			io.EOF,
+		},
+		{
+			"unregistered",
+			"missing",
+			func(context.Context, jobs.Job) error { return nil },
+			trails.ErrNotExist,
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			ctx := context.Background()
+			b := newRecordingBackend()
+			m := newTestManager(b)
+			m.Register("broken", tc.h, jobs.WithRetry(jobs.RetryPolicy{Backoff: jobs.ConstantBackoff(0), MaxAttempts: 2}))
+
+			m.Start(ctx)
+			defer m.Shutdown(ctx)
+
+			// Act
+			require.Nil(t, m.Enqueue(ctx, tc.job, nil))
+
+			// Assert
+			require.ErrorIs(t, receive(t, b.failed), tc.expected)
+		})
+	}
+}
+
+func TestManagerRecoversPanic(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	b := newRecordingBackend()
+	m := newTestManager(b)
+	m.Register("panics", func(context.Context, jobs.Job) error { panic("oh no") }, jobs.WithRetry(jobs.RetryPolicy{MaxAttempts: 1}))
+
+	m.Start(ctx)
+	defer m.Shutdown(ctx)
+
+	// Act
+	require.Nil(t, m.Enqueue(ctx, "panics", nil))
+
+	// Assert
+	require.ErrorContains(t, receive(t, b.failed), "oh no")
+}
+
+func TestManagerShutdownWaits(t *testing.T) {
+	// Arrange
+	ctx, cancel := context.WithCancel(context.Background())
+	b := newRecordingBackend()
+	m := newTestManager(b)
+
+	started := make(chan struct{})
+	m.Register("slow", func(ctx context.Context, _ jobs.Job) error {
+		close(started)
+		time.Sleep(50 * time.Millisecond)
+		return ctx.Err()
+	})
+
+	m.Start(ctx)
+	require.Nil(t, m.Enqueue(ctx, "slow", nil))
+	<-started
+
+	// Act
+	cancel()
+	err := m.Shutdown(context.Background())
+
+	// Assert
+	require.Nil(t, err)
+	require.Equal(t, "slow", receive(t, b.completed).Name)
+}
+
+func TestManagerShutdownTimesOut(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	b := newRecordingBackend()
+	m := newTestManager(b)
+
+	var once sync.Once
+	started := make(chan struct{})
+	m.Register("stuck", func(ctx context.Context, _ jobs.Job) error {
+		once.Do(func() { close(started) })
+		<-ctx.Done()
+		return jobs.Permanent(ctx.Err())
+	})
+
+	m.Start(ctx)
+	require.Nil(t, m.Enqueue(ctx, "stuck", nil))
+	<-started
+
+	shutdownCtx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+
+	// Act
+	err := m.Shutdown(shutdownCtx)
+
+	// Assert
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.ErrorIs(t, receive(t, b.failed), context.Canceled)
+}
+
+func TestManagerSchedule(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	b := newRecordingBackend()
+	m := newTestManager(b)
+	m.Register("tick", func(context.Context, jobs.Job) error { return nil })
+
+	// Act
+	err := m.Schedule("@every 10ms", "tick", nil)
+	m.Start(ctx)
+	defer m.Shutdown(ctx)
+
+	// Assert
+	require.Nil(t, err)
+	require.Equal(t, "tick", receive(t, b.completed).Name)
+	require.Equal(t, "tick", receive(t, b.completed).Name)
+	require.ErrorIs(t, m.Schedule("nope", "tick", nil), trails.ErrNotValid)
+}
+
+func TestManagerEnqueueOnly(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	b := jobs.NewMemoryBackend()
+	m := newTestManager(b)
+
+	m.Start(ctx)
+	defer m.Shutdown(ctx)
+
+	// Act
+	err := m.Enqueue(ctx, "elsewhere", nil)
+	time.Sleep(10 * time.Millisecond)
+
+	// Assert
+	require.Nil(t, err)
+	require.Equal(t, 1, b.Len())
+}
+
+func TestExponentialBackoff(t *testing.T) {
+	// Arrange
+	backoff := jobs.ExponentialBackoff(time.Second, 5*time.Second)
+
+	// Act + Assert
+	for attempt, expected := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second} {
+		require.Equal(t, expected, backoff(attempt+1))
+	}
+}
@@ -0,0 +1,116 @@
+package jobs
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// A MemoryBackend is a [Backend] storing jobs in-process.
+// Jobs are lost when the process exits, so MemoryBackend suits work that can be redone or skipped.
+//
+// The zero value is not usable; construct one with NewMemoryBackend.
+type MemoryBackend struct {
+	mu    sync.Mutex
+	queue []Job
+	wake  chan struct{}
+}
+
+// NewMemoryBackend constructs an empty *MemoryBackend.
+func NewMemoryBackend() *MemoryBackend {
+	return &MemoryBackend{wake: make(chan struct{})}
+}
+
+// Enqueue stores job, ordered by job.RunAt.
+func (b *MemoryBackend) Enqueue(_ context.Context, job Job) error {
+	if job.ID == "" {
+		job.ID = uuid.NewString()
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	i, _ := slices.BinarySearchFunc(b.queue, job.RunAt, func(j Job, t time.Time) int {
+		// NOTE: sort equal RunAts after one another so jobs run in the order enqueued.
+		if j.RunAt.After(t) {
+			return 1
+		}
+
+		return -1
+	})
+	b.queue = slices.Insert(b.queue, i, job)
+
+	close(b.wake)
+	b.wake = make(chan struct{})
+
+	return nil
+}
+
+// Dequeue removes and returns the job due to run soonest,
+// waiting until it is due, another is enqueued, or ctx is done.
+func (b *MemoryBackend) Dequeue(ctx context.Context) (Job, error) {
+	for {
+		b.mu.Lock()
+		wake := b.wake
+
+		var timer *time.Timer
+		if len(b.queue) > 0 {
+			wait := time.Until(b.queue[0].RunAt)
+			if wait <= 0 {
+				job := b.queue[0]
+				b.queue = b.queue[1:]
+				b.mu.Unlock()
+
+				return job, nil
+			}
+
+			timer = time.NewTimer(wait)
+		}
+		b.mu.Unlock()
+
+		err := b.wait(ctx, wake, timer)
+		if err != nil {
+			return Job{}, err
+		}
+	}
+}
+
+// wait blocks until ctx is done, wake closes, or timer, if any, fires.
+func (b *MemoryBackend) wait(ctx context.Context, wake <-chan struct{}, timer *time.Timer) error {
+	var due <-chan time.Time
+	if timer != nil {
+		defer timer.Stop()
+		due = timer.C
+	}
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-wake:
+	case <-due:
+	}
+
+	return nil
+}
+
+// Len returns the number of jobs waiting to run.
+func (b *MemoryBackend) Len() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return len(b.queue)
+}
+
+// Complete is a no-op, since Dequeue removed job.
+func (b *MemoryBackend) Complete(context.Context, Job) error { return nil }
+
+// Fail is a no-op, since Dequeue removed job.
+func (b *MemoryBackend) Fail(context.Context, Job, error) error { return nil }
+
+// Retry enqueues job again.
+func (b *MemoryBackend) Retry(ctx context.Context, job Job, _ error) error {
+	return b.Enqueue(ctx, job)
+}
@@ -0,0 +1,82 @@
+package jobs_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/xy-planning-network/trails/jobs"
+)
+
+func TestMemoryBackendOrder(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	b := jobs.NewMemoryBackend()
+	now := time.Now()
+
+	require.Nil(t, b.Enqueue(ctx, jobs.Job{Name: "second", RunAt: now.Add(-time.Minute)}))
+	require.Nil(t, b.Enqueue(ctx, jobs.Job{Name: "first", RunAt: now.Add(-time.Hour)}))
+	require.Nil(t, b.Enqueue(ctx, jobs.Job{Name: "third", RunAt: now.Add(-time.Minute)}))
+
+	// Act + Assert
+	for _, name := range []string{"first", "second", "third"} {
+		job, err := b.Dequeue(ctx)
+		require.Nil(t, err)
+		require.Equal(t, name, job.Name)
+		require.NotEmpty(t, job.ID)
+	}
+
+	require.Zero(t, b.Len())
+}
+
+func TestMemoryBackendWaitsForRunAt(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	b := jobs.NewMemoryBackend()
+	start := time.Now()
+
+	require.Nil(t, b.Enqueue(ctx, jobs.Job{Name: "later", RunAt: start.Add(50 * time.Millisecond)}))
+
+	// Act
+	job, err := b.Dequeue(ctx)
+
+	// Assert
+	require.Nil(t, err)
+	require.Equal(t, "later", job.Name)
+	require.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond)
+}
+
+func TestMemoryBackendWakesOnEnqueue(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	b := jobs.NewMemoryBackend()
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		b.Enqueue(ctx, jobs.Job{Name: "woken", RunAt: time.Now()})
+	}()
+
+	// Act
+	job, err := b.Dequeue(ctx)
+
+	// Assert
+	require.Nil(t, err)
+	require.Equal(t, "woken", job.Name)
+}
+
+func TestMemoryBackendDequeueCancelled(t *testing.T) {
+	// Arrange
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	b := jobs.NewMemoryBackend()
+	require.Nil(t, b.Enqueue(context.Background(), jobs.Job{Name: "tomorrow", RunAt: time.Now().Add(24 * time.Hour)}))
+
+	// Act
+	_, err := b.Dequeue(ctx)
+
+	// Assert
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.Equal(t, 1, b.Len())
+}
@@ -0,0 +1,393 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/xy-planning-network/trails"
+	"github.com/xy-planning-network/trails/postgres"
+	"gorm.io/gorm"
+)
+
+const (
+	// DefaultPollInterval is how often a PostgresBackend checks for jobs due to run by default.
+	DefaultPollInterval = time.Second
+
+	// DefaultVisibilityTimeout is how long a PostgresBackend lets a worker hold a job by default.
+	DefaultVisibilityTimeout = 5 * time.Minute
+)
+
+// PostgresMigrations create the tables a PostgresBackend stores jobs in.
+// Run them with [postgres.MigrateUp], e.g., by including them in ranger.Config.Migrations;
+// Ranger includes them when using a PostgresBackend.
+var PostgresMigrations = []postgres.Migration{
+	{
+		Key: "trails-jobs-20241001-create-tables",
+		Executor: func(db *gorm.DB) error {
+			return db.Exec(`
+				CREATE TABLE trails_jobs (
+					id BIGSERIAL PRIMARY KEY,
+					name TEXT NOT NULL,
+					args JSONB NOT NULL DEFAULT 'null',
+					attempt INTEGER NOT NULL DEFAULT 1,
+					run_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+					locked_until TIMESTAMPTZ,
+					last_error TEXT NOT NULL DEFAULT '',
+					created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+					updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+				);
+
+				CREATE INDEX trails_jobs_run_at_idx ON trails_jobs (run_at, id);
+
+				CREATE TABLE trails_dead_jobs (
+					id BIGINT PRIMARY KEY,
+					name TEXT NOT NULL,
+					args JSONB NOT NULL DEFAULT 'null',
+					attempt INTEGER NOT NULL,
+					error TEXT NOT NULL DEFAULT '',
+					failed_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+					created_at TIMESTAMPTZ NOT NULL
+				);
+			`).Error
+		},
+	},
+}
+
+// A QueuedJob is a Job a PostgresBackend has yet to run successfully.
+type QueuedJob struct {
+	ID          int64           `json:"id"`
+	Args        json.RawMessage `json:"args"`
+	Attempt     int             `json:"attempt"`
+	CreatedAt   time.Time       `json:"createdAt"`
+	LastError   string          `json:"lastError"`
+	LockedUntil *time.Time      `json:"lockedUntil"`
+	Name        string          `json:"name"`
+	RunAt       time.Time       `json:"runAt"`
+	UpdatedAt   time.Time       `json:"updatedAt"`
+}
+
+func (QueuedJob) TableName() string { return "trails_jobs" }
+
+// A DeadJob is a Job a PostgresBackend failed and will not retry.
+type DeadJob struct {
+	ID        int64           `json:"id"`
+	Args      json.RawMessage `json:"args"`
+	Attempt   int             `json:"attempt"`
+	CreatedAt time.Time       `json:"createdAt"`
+	Error     string          `json:"error"`
+	FailedAt  time.Time       `json:"failedAt"`
+	Name      string          `json:"name"`
+}
+
+func (DeadJob) TableName() string { return "trails_dead_jobs" }
+
+// PostgresStats counts the jobs a PostgresBackend stores.
+type PostgresStats struct {
+	// Dead is the number of jobs failed.
+	Dead int64 `json:"dead"`
+
+	// Due is the number of jobs waiting to run now.
+	Due int64 `json:"due"`
+
+	// Running is the number of jobs claimed by a worker.
+	Running int64 `json:"running"`
+
+	// Scheduled is the number of jobs waiting to run later.
+	Scheduled int64 `json:"scheduled"`
+}
+
+// PostgresConfig configures a PostgresBackend.
+type PostgresConfig struct {
+	// PollInterval is how often Dequeue checks for jobs due to run.
+	//
+	// If PollInterval is 0, DefaultPollInterval is used.
+	PollInterval time.Duration
+
+	// VisibilityTimeout is how long a worker holds a job it dequeued before
+	// the job is assumed abandoned, e.g., by a crashed process, and run again.
+	// VisibilityTimeout ought to be longer than the longest running job; cf. [WithTimeout].
+	//
+	// If VisibilityTimeout is 0, DefaultVisibilityTimeout is used.
+	VisibilityTimeout time.Duration
+}
+
+// A PostgresBackend is a [Backend] storing jobs in PostgreSQL, durable across restarts.
+// Many processes can share a PostgresBackend, each claiming different jobs.
+//
+// Jobs failed for good move to a dead-letter table, where they can be listed, retried, or deleted.
+//
+// Run PostgresMigrations before using a PostgresBackend.
+type PostgresBackend struct {
+	cfg PostgresConfig
+	db  *gorm.DB
+}
+
+// NewPostgresBackend constructs a *PostgresBackend storing jobs in db.
+func NewPostgresBackend(db *gorm.DB, cfg PostgresConfig) *PostgresBackend {
+	if cfg.PollInterval == 0 {
+		cfg.PollInterval = DefaultPollInterval
+	}
+
+	if cfg.VisibilityTimeout == 0 {
+		cfg.VisibilityTimeout = DefaultVisibilityTimeout
+	}
+
+	return &PostgresBackend{cfg: cfg, db: db}
+}
+
+// Enqueue inserts job.
+func (b *PostgresBackend) Enqueue(ctx context.Context, job Job) error {
+	args := string(job.Args)
+	if args == "" {
+		args = "null"
+	}
+
+	var id int64
+	err := b.db.WithContext(ctx).
+		Raw(
+			`INSERT INTO trails_jobs (name, args, attempt, run_at) VALUES (?, ?::jsonb, ?, ?) RETURNING id`,
+			job.Name, args, max(job.Attempt, 1), job.RunAt,
+		).
+		Scan(&id).
+		Error
+	if err != nil {
+		return fmt.Errorf("could not insert job: %w", err)
+	}
+
+	return nil
+}
+
+// Dequeue claims the job due to run soonest,
+// locking it for PostgresConfig.VisibilityTimeout.
+// Other processes sharing the PostgresBackend skip the job while it is locked.
+//
+// Dequeue checks for a job every PostgresConfig.PollInterval until one is due or ctx is done.
+func (b *PostgresBackend) Dequeue(ctx context.Context) (Job, error) {
+	ticker := time.NewTicker(b.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		job, ok, err := b.claim(ctx)
+		if err != nil {
+			return Job{}, err
+		}
+
+		if ok {
+			return job, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return Job{}, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// claim locks the job due to run soonest, if any,
+// counting an attempt the job's previous holder abandoned.
+func (b *PostgresBackend) claim(ctx context.Context) (Job, bool, error) {
+	var rows []QueuedJob
+	err := b.db.WithContext(ctx).
+		Raw(`
+			UPDATE trails_jobs
+			SET
+				attempt = attempt + CASE WHEN locked_until IS NULL THEN 0 ELSE 1 END,
+				locked_until = now() + ?::interval,
+				updated_at = now()
+			WHERE id = (
+				SELECT id FROM trails_jobs
+				WHERE run_at <= now() AND (locked_until IS NULL OR locked_until < now())
+				ORDER BY run_at, id
+				LIMIT 1
+				FOR UPDATE SKIP LOCKED
+			)
+			RETURNING id, name, args, attempt, run_at
+		`, fmt.Sprintf("%d microseconds", b.cfg.VisibilityTimeout.Microseconds())).
+		Scan(&rows).
+		Error
+	if err != nil {
+		if ctx.Err() != nil {
+			return Job{}, false, ctx.Err()
+		}
+
+		return Job{}, false, fmt.Errorf("could not claim job: %w", err)
+	}
+
+	if len(rows) == 0 {
+		return Job{}, false, nil
+	}
+
+	row := rows[0]
+	return Job{
+		Args:    row.Args,
+		Attempt: row.Attempt,
+		ID:      strconv.FormatInt(row.ID, 10),
+		Name:    row.Name,
+		RunAt:   row.RunAt,
+	}, true, nil
+}
+
+// Complete deletes job.
+func (b *PostgresBackend) Complete(ctx context.Context, job Job) error {
+	id, err := parseID(job)
+	if err != nil {
+		return err
+	}
+
+	if err := b.db.WithContext(ctx).Exec(`DELETE FROM trails_jobs WHERE id = ?`, id).Error; err != nil {
+		return fmt.Errorf("could not complete job %d: %w", id, err)
+	}
+
+	return nil
+}
+
+// Fail moves job to the dead-letter table.
+func (b *PostgresBackend) Fail(ctx context.Context, job Job, jobErr error) error {
+	id, err := parseID(job)
+	if err != nil {
+		return err
+	}
+
+	msg := ""
+	if jobErr != nil {
+		msg = jobErr.Error()
+	}
+
+	err = b.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Exec(`
+			INSERT INTO trails_dead_jobs (id, name, args, attempt, error, created_at)
+			SELECT id, name, args, attempt, ?, created_at FROM trails_jobs WHERE id = ?
+		`, msg, id).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Exec(`DELETE FROM trails_jobs WHERE id = ?`, id).Error
+	})
+	if err != nil {
+		return fmt.Errorf("could not fail job %d: %w", id, err)
+	}
+
+	return nil
+}
+
+// Retry unlocks job, recording jobErr, to run again at job.RunAt.
+func (b *PostgresBackend) Retry(ctx context.Context, job Job, jobErr error) error {
+	id, err := parseID(job)
+	if err != nil {
+		return err
+	}
+
+	msg := ""
+	if jobErr != nil {
+		msg = jobErr.Error()
+	}
+
+	err = b.db.WithContext(ctx).
+		Exec(
+			`UPDATE trails_jobs SET attempt = ?, run_at = ?, locked_until = NULL, last_error = ?, updated_at = now() WHERE id = ?`,
+			job.Attempt, job.RunAt, msg, id,
+		).
+		Error
+	if err != nil {
+		return fmt.Errorf("could not retry job %d: %w", id, err)
+	}
+
+	return nil
+}
+
+// Queued pages through the jobs yet to run successfully, soonest first.
+// Items is a *[]QueuedJob.
+func (b *PostgresBackend) Queued(ctx context.Context, page, perPage int) (postgres.PagedData, error) {
+	session := b.db.WithContext(ctx).Model(new(QueuedJob)).Order("run_at, id")
+	return postgres.NewService(b.db).PagedByQueryFromSession(new([]QueuedJob), session, page, perPage)
+}
+
+// Dead pages through the jobs failed for good, most recent first.
+// Items is a *[]DeadJob.
+func (b *PostgresBackend) Dead(ctx context.Context, page, perPage int) (postgres.PagedData, error) {
+	session := b.db.WithContext(ctx).Model(new(DeadJob)).Order("failed_at DESC, id DESC")
+	return postgres.NewService(b.db).PagedByQueryFromSession(new([]DeadJob), session, page, perPage)
+}
+
+// RetryDead moves the dead job identified by id back to be run now, as its first attempt.
+//
+// If there is no dead job identified by id, RetryDead returns trails.ErrNotExist.
+func (b *PostgresBackend) RetryDead(ctx context.Context, id int64) error {
+	err := b.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Exec(`
+			INSERT INTO trails_jobs (id, name, args, attempt, run_at, created_at)
+			SELECT id, name, args, 1, now(), created_at FROM trails_dead_jobs WHERE id = ?
+		`, id)
+		if res.Error != nil {
+			return res.Error
+		}
+
+		if res.RowsAffected == 0 {
+			return fmt.Errorf("%w: dead job %d", trails.ErrNotExist, id)
+		}
+
+		return tx.Exec(`DELETE FROM trails_dead_jobs WHERE id = ?`, id).Error
+	})
+	if errors.Is(err, trails.ErrNotExist) {
+		return err
+	}
+
+	if err != nil {
+		return fmt.Errorf("could not retry dead job %d: %w", id, err)
+	}
+
+	return nil
+}
+
+// DeleteDead deletes the dead job identified by id.
+//
+// If there is no dead job identified by id, DeleteDead returns trails.ErrNotExist.
+func (b *PostgresBackend) DeleteDead(ctx context.Context, id int64) error {
+	res := b.db.WithContext(ctx).Exec(`DELETE FROM trails_dead_jobs WHERE id = ?`, id)
+	if res.Error != nil {
+		return fmt.Errorf("could not delete dead job %d: %w", id, res.Error)
+	}
+
+	if res.RowsAffected == 0 {
+		return fmt.Errorf("%w: dead job %d", trails.ErrNotExist, id)
+	}
+
+	return nil
+}
+
+// Stats counts the jobs the PostgresBackend stores.
+func (b *PostgresBackend) Stats(ctx context.Context) (PostgresStats, error) {
+	var stats PostgresStats
+	err := b.db.WithContext(ctx).
+		Raw(`
+			SELECT
+				(SELECT count(*) FROM trails_dead_jobs) AS dead,
+				count(*) FILTER (WHERE run_at <= now() AND (locked_until IS NULL OR locked_until < now())) AS due,
+				count(*) FILTER (WHERE locked_until >= now()) AS running,
+				count(*) FILTER (WHERE run_at > now() AND (locked_until IS NULL OR locked_until < now())) AS scheduled
+			FROM trails_jobs
+		`).
+		Scan(&stats).
+		Error
+	if err != nil {
+		return PostgresStats{}, fmt.Errorf("could not count jobs: %w", err)
+	}
+
+	return stats, nil
+}
+
+// parseID parses the ID a PostgresBackend assigned job.
+func parseID(job Job) (int64, error) {
+	id, err := strconv.ParseInt(job.ID, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%w: job ID %q", trails.ErrNotValid, job.ID)
+	}
+
+	return id, nil
+}
@@ -0,0 +1,23 @@
+package jobs_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/xy-planning-network/trails"
+	"github.com/xy-planning-network/trails/jobs"
+)
+
+func TestPostgresBackendInvalidID(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	b := jobs.NewPostgresBackend(nil, jobs.PostgresConfig{})
+	job := jobs.Job{ID: "not-a-number", Name: "test"}
+
+	// Act + Assert
+	require.ErrorIs(t, b.Complete(ctx, job), trails.ErrNotValid)
+	require.ErrorIs(t, b.Fail(ctx, job, errors.New("failed")), trails.ErrNotValid)
+	require.ErrorIs(t, b.Retry(ctx, job, errors.New("failed")), trails.ErrNotValid)
+}
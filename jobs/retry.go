@@ -0,0 +1,63 @@
+package jobs
+
+import "time"
+
+const (
+	// DefaultMaxAttempts is the number of times a Job is run before it is failed by default.
+	DefaultMaxAttempts = 5
+
+	// DefaultBackoffBase is how long the default RetryPolicy waits before the first retry.
+	DefaultBackoffBase = 15 * time.Second
+
+	// DefaultBackoffMax is the longest the default RetryPolicy waits before a retry.
+	DefaultBackoffMax = time.Hour
+)
+
+// A Backoff returns how long to wait before running a Job again
+// after the attempt-th attempt at it failed.
+type Backoff func(attempt int) time.Duration
+
+// ConstantBackoff waits d before every retry.
+func ConstantBackoff(d time.Duration) Backoff {
+	return func(int) time.Duration { return d }
+}
+
+// ExponentialBackoff waits base before the first retry,
+// doubling the wait before each one after up to max.
+func ExponentialBackoff(base, max time.Duration) Backoff {
+	return func(attempt int) time.Duration {
+		d := base
+		for i := 1; i < attempt && d < max; i++ {
+			d *= 2
+		}
+
+		return min(d, max)
+	}
+}
+
+// A RetryPolicy determines whether and when a Job whose Handler returned an error runs again.
+type RetryPolicy struct {
+	// Backoff is how long to wait before each retry.
+	//
+	// If Backoff is nil, ExponentialBackoff(DefaultBackoffBase, DefaultBackoffMax) is used.
+	Backoff Backoff
+
+	// MaxAttempts is the number of times a Job is run before it is failed.
+	// Set MaxAttempts to 1 to never retry.
+	//
+	// If MaxAttempts is 0, DefaultMaxAttempts is used.
+	MaxAttempts int
+}
+
+// withDefaults fills in the zero values of the RetryPolicy.
+func (p RetryPolicy) withDefaults() RetryPolicy {
+	if p.Backoff == nil {
+		p.Backoff = ExponentialBackoff(DefaultBackoffBase, DefaultBackoffMax)
+	}
+
+	if p.MaxAttempts == 0 {
+		p.MaxAttempts = DefaultMaxAttempts
+	}
+
+	return p
+}
@@ -0,0 +1,200 @@
+package jobs
+
+import (
+	"fmt"
+	"math/bits"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/xy-planning-network/trails"
+)
+
+// A Schedule determines when a scheduled job is enqueued.
+type Schedule interface {
+	// Next returns the first time after t the job is enqueued,
+	// or the zero time.Time if it never is.
+	Next(t time.Time) time.Time
+}
+
+// ParseSchedule parses spec into a Schedule.
+//
+// spec is either five space-separated cron fields: minute, hour, day of month, month, and day of week;
+// or one of these descriptors:
+//   - @yearly, or @annually: "0 0 1 1 *"
+//   - @monthly: "0 0 1 * *"
+//   - @weekly: "0 0 * * 0"
+//   - @daily, or @midnight: "0 0 * * *"
+//   - @hourly: "0 * * * *"
+//   - @every <duration>, e.g., "@every 90s", parsed by [time.ParseDuration]
+//
+// Each field is a comma-separated list of "*", a number, or a range of numbers, e.g., "1-5",
+// any of which may be followed by a step, e.g., "*/15".
+// Days of the week count from Sunday as 0; 7 is also Sunday.
+// As with cron, when both day of month and day of week are restricted,
+// a day matching either is scheduled.
+//
+// Times are matched in the location of the time.Time passed to Next.
+func ParseSchedule(spec string) (Schedule, error) {
+	spec = strings.TrimSpace(spec)
+	switch spec {
+	case "@yearly", "@annually":
+		spec = "0 0 1 1 *"
+	case "@monthly":
+		spec = "0 0 1 * *"
+	case "@weekly":
+		spec = "0 0 * * 0"
+	case "@daily", "@midnight":
+		spec = "0 0 * * *"
+	case "@hourly":
+		spec = "0 * * * *"
+	}
+
+	if every, ok := strings.CutPrefix(spec, "@every "); ok {
+		d, err := time.ParseDuration(strings.TrimSpace(every))
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("%w: invalid schedule %q", trails.ErrNotValid, spec)
+		}
+
+		return everySchedule(d), nil
+	}
+
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("%w: schedule %q must have 5 fields", trails.ErrNotValid, spec)
+	}
+
+	var (
+		s   cronSchedule
+		err error
+	)
+	for i, f := range []struct {
+		dst      *uint64
+		min, max int
+	}{
+		{&s.minute, 0, 59},
+		{&s.hour, 0, 23},
+		{&s.dom, 1, 31},
+		{&s.month, 1, 12},
+		{&s.dow, 0, 7},
+	} {
+		*f.dst, err = parseField(fields[i], f.min, f.max)
+		if err != nil {
+			return nil, fmt.Errorf("%w: schedule %q: %s", trails.ErrNotValid, spec, err)
+		}
+	}
+
+	// NOTE: fold 7 onto Sunday.
+	if s.dow&(1<<7) != 0 {
+		s.dow |= 1
+	}
+
+	s.domAny = fields[2] == "*" || strings.HasPrefix(fields[2], "*/")
+	s.dowAny = fields[4] == "*" || strings.HasPrefix(fields[4], "*/")
+
+	return s, nil
+}
+
+// parseField parses a cron field into a bit set of the values, between min and max, it matches.
+func parseField(field string, min, max int) (uint64, error) {
+	var set uint64
+	for _, part := range strings.Split(field, ",") {
+		rng, step, hasStep := strings.Cut(part, "/")
+
+		lo, hi := min, max
+		if rng != "*" {
+			from, to, isRange := strings.Cut(rng, "-")
+
+			var err error
+			if lo, err = strconv.Atoi(from); err != nil {
+				return 0, fmt.Errorf("invalid value %q", part)
+			}
+
+			hi = lo
+			if isRange {
+				if hi, err = strconv.Atoi(to); err != nil {
+					return 0, fmt.Errorf("invalid value %q", part)
+				}
+			} else if hasStep {
+				hi = max
+			}
+		}
+
+		if lo < min || hi > max || lo > hi {
+			return 0, fmt.Errorf("%q out of range %d-%d", part, min, max)
+		}
+
+		n := 1
+		if hasStep {
+			var err error
+			if n, err = strconv.Atoi(step); err != nil || n <= 0 {
+				return 0, fmt.Errorf("invalid step %q", part)
+			}
+		}
+
+		for v := lo; v <= hi; v += n {
+			set |= 1 << v
+		}
+	}
+
+	return set, nil
+}
+
+// cronSchedule is a Schedule parsed from cron fields, each a bit set of the values it matches.
+type cronSchedule struct {
+	dom, dow, hour, minute, month uint64
+	domAny, dowAny                bool
+}
+
+// maxScheduleSearch bounds how far ahead Next looks for a matching time,
+// e.g., for "0 0 30 2 *", which never matches.
+const maxScheduleSearch = 5
+
+// Next returns the first minute after t matching the cronSchedule.
+func (s cronSchedule) Next(t time.Time) time.Time {
+	loc := t.Location()
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(maxScheduleSearch, 0, 0)
+
+	for t.Before(limit) {
+		switch {
+		case !has(s.month, int(t.Month())):
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc)
+		case !s.dayMatches(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
+		case !has(s.hour, t.Hour()):
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, loc)
+		case !has(s.minute, t.Minute()):
+			next := bits.TrailingZeros64(s.minute >> (t.Minute() + 1))
+			if t.Minute()+1+next > 59 {
+				t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, loc)
+				continue
+			}
+
+			t = t.Add(time.Duration(next+1) * time.Minute)
+		default:
+			return t
+		}
+	}
+
+	return time.Time{}
+}
+
+// dayMatches reports whether t falls on a day of the month and day of the week the cronSchedule matches.
+func (s cronSchedule) dayMatches(t time.Time) bool {
+	dom, dow := has(s.dom, t.Day()), has(s.dow, int(t.Weekday()))
+	if s.domAny || s.dowAny {
+		return dom && dow
+	}
+
+	return dom || dow
+}
+
+// has reports whether v is in set.
+func has(set uint64, v int) bool { return set&(1<<v) != 0 }
+
+// everySchedule is a Schedule recurring at a fixed interval.
+type everySchedule time.Duration
+
+// Next returns t plus the interval.
+func (s everySchedule) Next(t time.Time) time.Time { return t.Add(time.Duration(s)) }
@@ -0,0 +1,74 @@
+package jobs_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/xy-planning-network/trails"
+	"github.com/xy-planning-network/trails/jobs"
+)
+
+func TestParseSchedule(t *testing.T) {
+	// Wednesday
+	from := time.Date(2024, time.January, 3, 10, 30, 15, 0, time.UTC)
+
+	tcs := []struct {
+		name     string
+		spec     string
+		expected time.Time
+	}{
+		{"every-minute", "* * * * *", time.Date(2024, time.January, 3, 10, 31, 0, 0, time.UTC)},
+		{"step", "*/15 * * * *", time.Date(2024, time.January, 3, 10, 45, 0, 0, time.UTC)},
+		{"list", "5,20 * * * *", time.Date(2024, time.January, 3, 11, 5, 0, 0, time.UTC)},
+		{"range", "0 9-17 * * *", time.Date(2024, time.January, 3, 11, 0, 0, 0, time.UTC)},
+		{"daily", "@daily", time.Date(2024, time.January, 4, 0, 0, 0, 0, time.UTC)},
+		{"weekly", "@weekly", time.Date(2024, time.January, 7, 0, 0, 0, 0, time.UTC)},
+		{"monthly", "@monthly", time.Date(2024, time.February, 1, 0, 0, 0, 0, time.UTC)},
+		{"yearly", "@yearly", time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)},
+		{"sunday-as-7", "0 0 * * 7", time.Date(2024, time.January, 7, 0, 0, 0, 0, time.UTC)},
+		{"weekdays", "0 8 * * 1-5", time.Date(2024, time.January, 4, 8, 0, 0, 0, time.UTC)},
+		{"dom-or-dow", "0 0 15 * 5", time.Date(2024, time.January, 5, 0, 0, 0, 0, time.UTC)},
+		{"leap-day", "0 0 29 2 *", time.Date(2024, time.February, 29, 0, 0, 0, 0, time.UTC)},
+		{"every", "@every 90s", from.Add(90 * time.Second)},
+		{"never", "0 0 30 2 *", time.Time{}},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			// Act
+			s, err := jobs.ParseSchedule(tc.spec)
+
+			// Assert
+			require.Nil(t, err)
+			require.Equal(t, tc.expected, s.Next(from))
+		})
+	}
+}
+
+func TestParseScheduleInvalid(t *testing.T) {
+	for _, spec := range []string{
+		"",
+		"* * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"5-1 * * * *",
+		"*/0 * * * *",
+		"a * * * *",
+		"@every",
+		"@every -1m",
+		"@fortnightly",
+	} {
+		t.Run(spec, func(t *testing.T) {
+			// Act
+			s, err := jobs.ParseSchedule(spec)
+
+			// Assert
+			require.ErrorIs(t, err, trails.ErrNotValid)
+			require.Nil(t, s)
+		})
+	}
+}
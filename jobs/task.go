@@ -0,0 +1,492 @@
+package jobs
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/xy-planning-network/trails"
+	"github.com/xy-planning-network/trails/logger"
+	"github.com/xy-planning-network/trails/postgres"
+	"gorm.io/gorm"
+)
+
+// A Task is work a Scheduler runs in-process on a Schedule.
+type Task func(ctx context.Context) error
+
+// A TaskStatus describes the latest run of a Task.
+type TaskStatus struct {
+	// Due is when the run was scheduled for.
+	Due time.Time `json:"due"`
+
+	// Error is the error the run returned, if any.
+	Error string `json:"error"`
+
+	// Finished is when the run finished, or the zero value if it is running.
+	Finished time.Time `json:"finished"`
+
+	// Name is the name of the Task.
+	Name string `json:"name"`
+
+	// Schedule is the spec of the Task's Schedule.
+	Schedule string `json:"schedule"`
+
+	// Started is when the run started.
+	Started time.Time `json:"started"`
+}
+
+// A TaskStore coordinates the processes running a Scheduler's tasks
+// so only one of them executes each run, and records how runs went.
+type TaskStore interface {
+	// Claim claims the run of the Task name due at due,
+	// reporting false if another process already claimed or ran it.
+	// The claim is held until unlock is called.
+	Claim(ctx context.Context, name string, due time.Time) (unlock func(), ok bool, err error)
+
+	// Record records status as the latest run of its Task.
+	Record(ctx context.Context, status TaskStatus) error
+
+	// Status returns the latest run of the Task name,
+	// or trails.ErrNotExist if it has not run.
+	Status(ctx context.Context, name string) (TaskStatus, error)
+}
+
+// SchedulerConfig configures a Scheduler.
+type SchedulerConfig struct {
+	// Logger logs tasks running and failing.
+	//
+	// If Logger is nil, one writing to slog.Default is used.
+	Logger logger.Logger
+
+	// Store coordinates processes running the same tasks.
+	//
+	// If Store is nil, a *MemoryTaskStore is used, coordinating nothing.
+	Store TaskStore
+}
+
+// A Scheduler runs tasks in-process on schedules.
+// Unlike a job Manager.Schedule enqueues, a Task runs in the process that scheduled it,
+// not by a worker, and is not retried.
+//
+// Where several processes schedule the same tasks, a shared TaskStore,
+// e.g., a *PostgresTaskStore, ensures only one of them executes each run.
+// A Task never runs alongside itself.
+//
+// A Scheduler is safe for concurrent use.
+type Scheduler struct {
+	logger logger.Logger
+	store  TaskStore
+
+	mu      sync.Mutex
+	abort   context.CancelFunc
+	runCtx  context.Context
+	started bool
+	stop    context.CancelFunc
+	stopCtx context.Context
+	tasks   map[string]scheduledTask
+	wg      sync.WaitGroup
+}
+
+// scheduledTask is a Task and when it runs.
+type scheduledTask struct {
+	every time.Duration
+	fn    Task
+	name  string
+	s     Schedule
+	spec  string
+}
+
+// NewScheduler constructs a *Scheduler from cfg.
+func NewScheduler(cfg SchedulerConfig) *Scheduler {
+	if cfg.Logger == nil {
+		cfg.Logger = logger.New(slog.Default(), trails.Production)
+	}
+
+	if cfg.Store == nil {
+		cfg.Store = NewMemoryTaskStore()
+	}
+
+	return &Scheduler{logger: cfg.Logger, store: cfg.Store, tasks: make(map[string]scheduledTask)}
+}
+
+// Every runs fn under name on the schedule spec describes; cf. [ParseSchedule].
+// Schedules of the form "@every <duration>" align to multiples of the duration since the Unix epoch,
+// so processes agree on when runs are due.
+//
+// Every returns trails.ErrNotValid if spec cannot be parsed
+// or a Task is already scheduled under name.
+func (s *Scheduler) Every(spec, name string, fn Task) error {
+	if name == "" {
+		return fmt.Errorf("%w: missing task name", trails.ErrNotValid)
+	}
+
+	sch, err := ParseSchedule(spec)
+	if err != nil {
+		return err
+	}
+
+	tk := scheduledTask{fn: fn, name: name, s: sch, spec: spec}
+	if every, ok := sch.(everySchedule); ok {
+		tk.every = time.Duration(every)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.tasks[name]; ok {
+		return fmt.Errorf("%w: task %q is already scheduled", trails.ErrNotValid, name)
+	}
+
+	s.tasks[name] = tk
+	if s.started {
+		s.wg.Add(1)
+		go s.loop(s.stopCtx, tk)
+	}
+
+	return nil
+}
+
+// Status returns the latest run of the Task name, as recorded by the Scheduler's TaskStore.
+func (s *Scheduler) Status(ctx context.Context, name string) (TaskStatus, error) {
+	return s.store.Status(ctx, name)
+}
+
+// Start begins running tasks on their schedules until ctx is done or Shutdown is called.
+//
+// Start does nothing if the Scheduler is started.
+func (s *Scheduler) Start(ctx context.Context) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.started {
+		return
+	}
+	s.started = true
+
+	s.stopCtx, s.stop = context.WithCancel(ctx)
+	s.runCtx, s.abort = context.WithCancel(context.WithoutCancel(ctx))
+
+	for _, tk := range s.tasks {
+		s.wg.Add(1)
+		go s.loop(s.stopCtx, tk)
+	}
+}
+
+// Shutdown stops running tasks, waiting for those running to finish or ctx to be done.
+// If ctx is done first, Shutdown cancels the context.Context of the tasks still running
+// and returns ctx.Err().
+//
+// Shutdown matches the signature of ranger.ShutdownFn.
+func (s *Scheduler) Shutdown(ctx context.Context) error {
+	s.mu.Lock()
+	if !s.started {
+		s.mu.Unlock()
+		return nil
+	}
+	s.started = false
+	s.stop()
+	abort := s.abort
+	s.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		abort()
+		return nil
+	case <-ctx.Done():
+		abort()
+		return fmt.Errorf("could not wait for tasks to finish: %w", ctx.Err())
+	}
+}
+
+// next returns when tk is next due after now.
+func (tk scheduledTask) next(now time.Time) time.Time {
+	if tk.every > 0 {
+		return now.Truncate(tk.every).Add(tk.every)
+	}
+
+	return tk.s.Next(now)
+}
+
+// loop runs tk each time it comes due, until ctx is done.
+func (s *Scheduler) loop(ctx context.Context, tk scheduledTask) {
+	defer s.wg.Done()
+	defer logger.CapturePanics(s.logger)
+
+	for {
+		due := tk.next(time.Now())
+		if due.IsZero() {
+			return
+		}
+
+		timer := time.NewTimer(time.Until(due))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		s.run(tk, due)
+	}
+}
+
+// run runs tk for the run due at due, if no other process claimed it.
+func (s *Scheduler) run(tk scheduledTask, due time.Time) {
+	s.mu.Lock()
+	runCtx := s.runCtx
+	s.mu.Unlock()
+
+	// NOTE: keep recording outcomes even once running tasks are aborted.
+	bg := context.WithoutCancel(runCtx)
+	data := map[string]any{"due": due, "name": tk.name, "schedule": tk.spec}
+
+	unlock, ok, err := s.store.Claim(runCtx, tk.name, due)
+	if err != nil {
+		s.logger.Error("could not claim task: "+err.Error(), &logger.LogContext{Data: data, Error: err})
+		return
+	}
+
+	if !ok {
+		s.logger.Debug("skipping task run by another process", &logger.LogContext{Data: data})
+		return
+	}
+	defer unlock()
+
+	status := TaskStatus{Due: due, Name: tk.name, Schedule: tk.spec, Started: time.Now()}
+	if err := s.store.Record(bg, status); err != nil {
+		s.logger.Error("could not record task status: "+err.Error(), &logger.LogContext{Data: data, Error: err})
+	}
+
+	err = callTask(runCtx, tk)
+
+	status.Finished = time.Now()
+	data["duration"] = status.Finished.Sub(status.Started).String()
+	if err != nil {
+		status.Error = err.Error()
+		s.logger.Error("task failed: "+err.Error(), &logger.LogContext{Data: data, Error: err})
+	} else {
+		s.logger.Debug("ran task", &logger.LogContext{Data: data})
+	}
+
+	if err := s.store.Record(bg, status); err != nil {
+		s.logger.Error("could not record task status: "+err.Error(), &logger.LogContext{Data: data, Error: err})
+	}
+}
+
+// callTask calls tk's Task, recovering a panic as an error.
+func callTask(ctx context.Context, tk scheduledTask) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("task %q panicked: %v", tk.name, p)
+		}
+	}()
+
+	return tk.fn(ctx)
+}
+
+// A MemoryTaskStore is a TaskStore recording task statuses in-process.
+// It coordinates no other processes, so each process scheduling a Task runs it.
+type MemoryTaskStore struct {
+	mu       sync.Mutex
+	statuses map[string]TaskStatus
+}
+
+// NewMemoryTaskStore constructs a *MemoryTaskStore.
+func NewMemoryTaskStore() *MemoryTaskStore {
+	return &MemoryTaskStore{statuses: make(map[string]TaskStatus)}
+}
+
+// Claim claims the run unless one due at or after due was recorded.
+func (s *MemoryTaskStore) Claim(_ context.Context, name string, due time.Time) (func(), bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if last, ok := s.statuses[name]; ok && !last.Due.Before(due) {
+		return nil, false, nil
+	}
+
+	return func() {}, true, nil
+}
+
+// Record records status.
+func (s *MemoryTaskStore) Record(_ context.Context, status TaskStatus) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.statuses[status.Name] = status
+
+	return nil
+}
+
+// Status returns the latest run of the Task name.
+func (s *MemoryTaskStore) Status(_ context.Context, name string) (TaskStatus, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	status, ok := s.statuses[name]
+	if !ok {
+		return TaskStatus{}, fmt.Errorf("%w: task %q has not run", trails.ErrNotExist, name)
+	}
+
+	return status, nil
+}
+
+// PostgresTaskMigrations creates the table a PostgresTaskStore records task statuses in.
+var PostgresTaskMigrations = []postgres.Migration{
+	{
+		Key: "trails-tasks-20241015-create-table",
+		Executor: func(db *gorm.DB) error {
+			return db.Exec(`
+				CREATE TABLE trails_tasks (
+					name TEXT PRIMARY KEY,
+					schedule TEXT NOT NULL,
+					due_at TIMESTAMPTZ NOT NULL,
+					started_at TIMESTAMPTZ NOT NULL,
+					finished_at TIMESTAMPTZ,
+					error TEXT NOT NULL DEFAULT ''
+				);
+			`).Error
+		},
+	},
+}
+
+// A PostgresTaskStore is a TaskStore coordinating processes sharing a PostgreSQL database.
+// A process claims a run by taking a session-level advisory lock on the Task's name
+// and checking the run has not been recorded, holding the lock until the run finishes.
+//
+// Run PostgresTaskMigrations before using a PostgresTaskStore.
+type PostgresTaskStore struct {
+	db *gorm.DB
+}
+
+// NewPostgresTaskStore constructs a *PostgresTaskStore recording task statuses in db.
+func NewPostgresTaskStore(db *gorm.DB) *PostgresTaskStore { return &PostgresTaskStore{db: db} }
+
+// Claim takes an advisory lock on name with a dedicated connection,
+// releasing it if the lock is held elsewhere or a run due at or after due was recorded.
+func (s *PostgresTaskStore) Claim(ctx context.Context, name string, due time.Time) (func(), bool, error) {
+	sqlDB, err := s.db.DB()
+	if err != nil {
+		return nil, false, fmt.Errorf("could not get database: %w", err)
+	}
+
+	// NOTE: advisory locks belong to a session, so lock and unlock on the same connection.
+	conn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		return nil, false, fmt.Errorf("could not get connection: %w", err)
+	}
+
+	key := taskLockKey(name)
+
+	var locked bool
+	if err := conn.QueryRowContext(ctx, `SELECT pg_try_advisory_lock($1)`, key).Scan(&locked); err != nil {
+		conn.Close()
+		return nil, false, fmt.Errorf("could not lock task %q: %w", name, err)
+	}
+
+	if !locked {
+		conn.Close()
+		return nil, false, nil
+	}
+
+	unlock := func() {
+		conn.ExecContext(context.Background(), `SELECT pg_advisory_unlock($1)`, key)
+		conn.Close()
+	}
+
+	var last time.Time
+	err = conn.QueryRowContext(ctx, `SELECT due_at FROM trails_tasks WHERE name = $1`, name).Scan(&last)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		unlock()
+		return nil, false, fmt.Errorf("could not check task %q: %w", name, err)
+	}
+
+	if err == nil && !last.Before(due.Truncate(time.Microsecond)) {
+		unlock()
+		return nil, false, nil
+	}
+
+	return unlock, true, nil
+}
+
+// Record upserts status.
+func (s *PostgresTaskStore) Record(ctx context.Context, status TaskStatus) error {
+	var finished *time.Time
+	if !status.Finished.IsZero() {
+		finished = &status.Finished
+	}
+
+	err := s.db.WithContext(ctx).
+		Exec(`
+			INSERT INTO trails_tasks (name, schedule, due_at, started_at, finished_at, error)
+			VALUES (?, ?, ?, ?, ?, ?)
+			ON CONFLICT (name) DO UPDATE SET
+				schedule = EXCLUDED.schedule,
+				due_at = EXCLUDED.due_at,
+				started_at = EXCLUDED.started_at,
+				finished_at = EXCLUDED.finished_at,
+				error = EXCLUDED.error
+		`, status.Name, status.Schedule, status.Due, status.Started, finished, status.Error).
+		Error
+	if err != nil {
+		return fmt.Errorf("could not record task %q: %w", status.Name, err)
+	}
+
+	return nil
+}
+
+// Status returns the latest run of the Task name.
+func (s *PostgresTaskStore) Status(ctx context.Context, name string) (TaskStatus, error) {
+	var rows []struct {
+		Schedule   string
+		DueAt      time.Time
+		StartedAt  time.Time
+		FinishedAt *time.Time
+		Error      string
+	}
+
+	err := s.db.WithContext(ctx).
+		Raw(`SELECT schedule, due_at, started_at, finished_at, error FROM trails_tasks WHERE name = ?`, name).
+		Scan(&rows).
+		Error
+	if err != nil {
+		return TaskStatus{}, fmt.Errorf("could not get task %q: %w", name, err)
+	}
+
+	if len(rows) == 0 {
+		return TaskStatus{}, fmt.Errorf("%w: task %q has not run", trails.ErrNotExist, name)
+	}
+
+	row := rows[0]
+	status := TaskStatus{
+		Due:      row.DueAt,
+		Error:    row.Error,
+		Name:     name,
+		Schedule: row.Schedule,
+		Started:  row.StartedAt,
+	}
+	if row.FinishedAt != nil {
+		status.Finished = *row.FinishedAt
+	}
+
+	return status, nil
+}
+
+// taskLockKey hashes name into the key of its advisory lock,
+// namespaced so it is unlikely to collide with an application's own locks.
+func taskLockKey(name string) int64 {
+	h := fnv.New64a()
+	h.Write([]byte("trails-task:" + name))
+	return int64(h.Sum64())
+}
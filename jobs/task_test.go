@@ -0,0 +1,136 @@
+package jobs_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/xy-planning-network/trails"
+	"github.com/xy-planning-network/trails/jobs"
+	"github.com/xy-planning-network/trails/logger"
+)
+
+func newTestScheduler(store jobs.TaskStore) *jobs.Scheduler {
+	return jobs.NewScheduler(jobs.SchedulerConfig{
+		Logger: logger.New(slog.New(slog.NewTextHandler(io.Discard, nil)), trails.Testing),
+		Store:  store,
+	})
+}
+
+func TestSchedulerEvery(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	store := jobs.NewMemoryTaskStore()
+	s := newTestScheduler(store)
+
+	ran := make(chan time.Time, 10)
+	err := s.Every("@every 10ms", "tick", func(context.Context) error {
+		ran <- time.Now()
+		return errors.New("boom")
+	})
+	require.Nil(t, err)
+
+	// Act
+	s.Start(ctx)
+	defer s.Shutdown(ctx)
+
+	// Assert
+	receive(t, ran)
+	receive(t, ran)
+
+	require.Eventually(t, func() bool {
+		status, err := s.Status(ctx, "tick")
+		return err == nil && !status.Finished.IsZero()
+	}, time.Second, time.Millisecond)
+
+	status, err := s.Status(ctx, "tick")
+	require.Nil(t, err)
+	require.Equal(t, "@every 10ms", status.Schedule)
+	require.Equal(t, "boom", status.Error)
+	require.Zero(t, status.Due.UnixNano()%int64(10*time.Millisecond))
+}
+
+func TestSchedulerEveryInvalid(t *testing.T) {
+	// Arrange
+	s := newTestScheduler(nil)
+	noop := func(context.Context) error { return nil }
+	require.Nil(t, s.Every("@hourly", "hourly", noop))
+
+	// Act + Assert
+	require.ErrorIs(t, s.Every("nope", "nope", noop), trails.ErrNotValid)
+	require.ErrorIs(t, s.Every("@hourly", "", noop), trails.ErrNotValid)
+	require.ErrorIs(t, s.Every("@daily", "hourly", noop), trails.ErrNotValid)
+
+	_, err := s.Status(context.Background(), "hourly")
+	require.ErrorIs(t, err, trails.ErrNotExist)
+}
+
+func TestSchedulerRecoversPanic(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	s := newTestScheduler(nil)
+
+	ran := make(chan struct{}, 10)
+	require.Nil(t, s.Every("@every 10ms", "panics", func(context.Context) error {
+		ran <- struct{}{}
+		panic("oh no")
+	}))
+
+	// Act
+	s.Start(ctx)
+	defer s.Shutdown(ctx)
+
+	// Assert
+	receive(t, ran)
+	receive(t, ran)
+}
+
+func TestSchedulerShutdown(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	s := newTestScheduler(nil)
+
+	started := make(chan struct{}, 1)
+	canceled := make(chan error, 1)
+	require.Nil(t, s.Every("@every 10ms", "slow", func(ctx context.Context) error {
+		started <- struct{}{}
+		<-ctx.Done()
+		canceled <- ctx.Err()
+		return ctx.Err()
+	}))
+
+	s.Start(ctx)
+	receive(t, started)
+
+	shutdownCtx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+
+	// Act
+	err := s.Shutdown(shutdownCtx)
+
+	// Assert
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.ErrorIs(t, receive(t, canceled), context.Canceled)
+}
+
+func TestMemoryTaskStoreClaim(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	store := jobs.NewMemoryTaskStore()
+	due := time.Now().Truncate(time.Minute)
+	require.Nil(t, store.Record(ctx, jobs.TaskStatus{Due: due, Name: "report"}))
+
+	// Act
+	_, same, sameErr := store.Claim(ctx, "report", due)
+	_, next, nextErr := store.Claim(ctx, "report", due.Add(time.Minute))
+
+	// Assert
+	require.Nil(t, sameErr)
+	require.False(t, same)
+	require.Nil(t, nextErr)
+	require.True(t, next)
+}
@@ -5,23 +5,50 @@ import "context"
 type Key string
 
 const (
+	// APIVersionKey stashes the version of the API an HTTP request is for, e.g., "v2".
+	APIVersionKey Key = "APIVersionKey"
+
 	// appPropsKey stashes additional props to be included in HTTP responses.
 	appPropsKey Key = "AppPropsKey"
 
+	// BotCheckKey stashes the signed time a form was rendered at, checking how quickly it is submitted.
+	BotCheckKey Key = "BotCheckKey"
+
+	// CSRFTokenKey stashes the token protecting against cross-site request forgery for a session.
+	CSRFTokenKey Key = "CSRFTokenKey"
+
 	// CurrentUserKey stashes the currentUser for a session.
 	CurrentUserKey Key = "CurrentUserKey"
 
+	// ImpersonatorKey stashes the user impersonating the currentUser for a session.
+	ImpersonatorKey Key = "ImpersonatorKey"
+
 	// IpAddrKey stashes the IP address of an HTTP request being handled by trails.
 	IpAddrKey Key = "IpAddrKey"
 
+	// LocaleKey stashes the language.Tag an HTTP request is localized in.
+	LocaleKey Key = "LocaleKey"
+
+	// NonceKey stashes the nonce a content security policy allows scripts to run with for an HTTP request.
+	NonceKey Key = "NonceKey"
+
 	// RequestIDKey stashes a unique UUID for each HTTP request.
 	RequestIDKey Key = "RequestIDKey"
 
+	// ResponderKey stashes the *resp.Responder handlers respond to an HTTP request with.
+	ResponderKey Key = "ResponderKey"
+
+	// ReturnToKey stashes the URL to send a user to after completing a workflow, e.g., logging in.
+	ReturnToKey Key = "ReturnToKey"
+
 	// SessionKey stashes the session associated with an HTTP request.
 	SessionKey Key = "SessionKey"
 
 	// SessionIDKey stashes a unique UUID for each session.
 	SessionIDKey Key = "SessionIDKey"
+
+	// TraceIDKey stashes the ID of the distributed trace an HTTP request is part of.
+	TraceIDKey Key = "TraceIDKey"
 )
 
 // String formats the stringified key with additional contextual information
@@ -29,6 +56,18 @@ func (k Key) String() string {
 	return "trails context key: " + string(k)
 }
 
+// Value retrieves the value stored under key in ctx as a T,
+// reporting false if no value is stored under key or it is not a T.
+func Value[T any](ctx context.Context, key Key) (T, bool) {
+	val, ok := ctx.Value(key).(T)
+	return val, ok
+}
+
+// WithValue stores val under key in a copy of ctx.
+func WithValue[T any](ctx context.Context, key Key, val T) context.Context {
+	return context.WithValue(ctx, key, val)
+}
+
 // An AppProps passes data from the server to the client as a set of props needed for general application state.
 // The data is passed around in a context.Context and rendered as JSON.
 // The data is expected to be marshaled into Vue/JS props.
@@ -0,0 +1,33 @@
+package trails_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/xy-planning-network/trails"
+)
+
+func TestValue(t *testing.T) {
+	// Arrange
+	ctx := trails.WithValue(context.Background(), trails.RequestIDKey, "abc")
+
+	// Act
+	id, ok := trails.Value[string](ctx, trails.RequestIDKey)
+
+	// Assert
+	require.True(t, ok)
+	require.Equal(t, "abc", id)
+
+	// Act
+	_, ok = trails.Value[int](ctx, trails.RequestIDKey)
+
+	// Assert
+	require.False(t, ok)
+
+	// Act
+	_, ok = trails.Value[string](ctx, trails.TraceIDKey)
+
+	// Assert
+	require.False(t, ok)
+}
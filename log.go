@@ -13,6 +13,7 @@ const (
 
 var (
 	AppLogKind    = slog.StringValue("app")
+	AuditLogKind  = slog.StringValue("audit")
 	HTTPLogKind   = slog.StringValue("http")
 	WorkerLogKind = slog.StringValue("worker")
 
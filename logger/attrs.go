@@ -0,0 +1,77 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+
+	"github.com/xy-planning-network/trails"
+)
+
+// attrsKey stashes the attributes WithAttrs attaches to a context.Context.
+type attrsKey struct{}
+
+// WithAttrs attaches attrs to ctx, returning the resulting context.Context.
+// Handlers wrapped by NewContextHandler include them in every record logged with the context.Context.
+// Attributes already attached to ctx are kept.
+func WithAttrs(ctx context.Context, attrs ...slog.Attr) context.Context {
+	if len(attrs) == 0 {
+		return ctx
+	}
+
+	return context.WithValue(ctx, attrsKey{}, slices.Concat(AttrsFromContext(ctx), attrs))
+}
+
+// AttrsFromContext retrieves the attributes WithAttrs attached to ctx.
+func AttrsFromContext(ctx context.Context) []slog.Attr {
+	if ctx == nil {
+		return nil
+	}
+
+	attrs, _ := ctx.Value(attrsKey{}).([]slog.Attr)
+	return attrs
+}
+
+// A ContextHandler is a [slog.Handler] including attributes from the context.Context
+// a record is logged with:
+//   - those attached with WithAttrs
+//   - "requestId", from trails.RequestIDKey
+//   - "traceId", from trails.TraceIDKey
+//   - "userId", from the user at trails.CurrentUserKey
+type ContextHandler struct {
+	slog.Handler
+}
+
+// NewContextHandler wraps h in a ContextHandler.
+func NewContextHandler(h slog.Handler) slog.Handler { return ContextHandler{h} }
+
+// Handle adds attributes from ctx to rec before handing it off to the wrapped [slog.Handler].
+func (h ContextHandler) Handle(ctx context.Context, rec slog.Record) error {
+	if ctx != nil {
+		if id, ok := trails.Value[string](ctx, trails.RequestIDKey); ok {
+			rec.AddAttrs(slog.String("requestId", id))
+		}
+
+		if id, ok := trails.Value[string](ctx, trails.TraceIDKey); ok {
+			rec.AddAttrs(slog.String("traceId", id))
+		}
+
+		if u, ok := ctx.Value(trails.CurrentUserKey).(interface{ GetID() uint }); ok {
+			rec.AddAttrs(slog.Any("userId", u.GetID()))
+		}
+
+		rec.AddAttrs(AttrsFromContext(ctx)...)
+	}
+
+	return h.Handler.Handle(ctx, rec)
+}
+
+// WithAttrs returns a ContextHandler whose wrapped [slog.Handler] has the attrs.
+func (h ContextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return ContextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+// WithGroup returns a ContextHandler whose wrapped [slog.Handler] has the group.
+func (h ContextHandler) WithGroup(name string) slog.Handler {
+	return ContextHandler{h.Handler.WithGroup(name)}
+}
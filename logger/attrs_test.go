@@ -0,0 +1,65 @@
+package logger_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/xy-planning-network/trails"
+	"github.com/xy-planning-network/trails/logger"
+)
+
+func TestContextHandler(t *testing.T) {
+	// Arrange
+	b := new(bytes.Buffer)
+	l := slog.New(logger.NewContextHandler(slog.NewJSONHandler(b, nil)))
+
+	ctx := context.WithValue(context.Background(), trails.RequestIDKey, "req-1")
+	ctx = context.WithValue(ctx, trails.TraceIDKey, "trace-1")
+	ctx = context.WithValue(ctx, trails.CurrentUserKey, testUser{})
+	ctx = logger.WithAttrs(ctx, slog.String("job", "export"))
+	ctx = logger.WithAttrs(ctx, slog.Int("attempt", 2))
+
+	// Act
+	l.With("kind", "app").InfoContext(ctx, "hello")
+
+	// Assert
+	var actual map[string]any
+	require.NoError(t, json.Unmarshal(b.Bytes(), &actual))
+	require.Equal(t, "req-1", actual["requestId"])
+	require.Equal(t, "trace-1", actual["traceId"])
+	require.Equal(t, float64(1), actual["userId"])
+	require.Equal(t, "export", actual["job"])
+	require.Equal(t, float64(2), actual["attempt"])
+	require.Equal(t, "app", actual["kind"])
+}
+
+func TestTrailsLoggerContext(t *testing.T) {
+	// Arrange
+	b := new(bytes.Buffer)
+	l := logger.New(slog.New(logger.NewContextHandler(slog.NewJSONHandler(b, nil))), trails.Testing)
+
+	r := httptest.NewRequest(http.MethodGet, "https://example.com", nil)
+	r = r.WithContext(context.WithValue(r.Context(), trails.RequestIDKey, "req-1"))
+
+	// Act
+	l.Info("from request", &logger.LogContext{Request: r})
+	l.Info("from context", &logger.LogContext{Context: logger.WithAttrs(context.Background(), slog.String("job", "export"))})
+
+	// Assert
+	dec := json.NewDecoder(b)
+
+	var fromRequest map[string]any
+	require.NoError(t, dec.Decode(&fromRequest))
+	require.Equal(t, "req-1", fromRequest["requestId"])
+
+	var fromContext map[string]any
+	require.NoError(t, dec.Decode(&fromContext))
+	require.Equal(t, "export", fromContext["job"])
+	require.NotContains(t, fromContext, "requestId")
+}
@@ -0,0 +1,206 @@
+package logger
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/xy-planning-network/trails"
+)
+
+// AuditAction names the kind of event an AuditEvent records.
+type AuditAction string
+
+const (
+	AuditImpersonationRevoked       AuditAction = "impersonation.revoked"
+	AuditImpersonationStart         AuditAction = "impersonation.start"
+	AuditImpersonationStop          AuditAction = "impersonation.stop"
+	AuditLoginLockout               AuditAction = "login.lockout"
+	AuditSessionFingerprintMismatch AuditAction = "session.fingerprint_mismatch"
+	AuditSessionLogin               AuditAction = "session.login"
+	AuditSessionLogout              AuditAction = "session.logout"
+)
+
+// AuditOutcome is whether the action an AuditEvent records succeeded.
+type AuditOutcome string
+
+const (
+	AuditFailure AuditOutcome = "failure"
+	AuditSuccess AuditOutcome = "success"
+)
+
+// An AuditEvent records a security-relevant action, e.g., a user logging in.
+type AuditEvent struct {
+	// Action is what happened.
+	Action AuditAction
+
+	// ActorID identifies the user taking the action, if any.
+	ActorID uint
+
+	// Context is the context.Context the action happened in.
+	//
+	// If Context is nil and Request is not, the Request's context.Context is used.
+	Context context.Context
+
+	// Data is any additional information about the action.
+	Data map[string]any
+
+	// ImpersonatorID identifies the user impersonating ActorID, if any.
+	ImpersonatorID uint
+
+	// Outcome is whether the action succeeded.
+	//
+	// If Outcome is empty, AuditSuccess is used.
+	Outcome AuditOutcome
+
+	// Request is the *http.Request the action happened during, if any.
+	Request *http.Request
+
+	// TargetID identifies the user acted upon, if any, e.g., the user impersonated.
+	TargetID uint
+}
+
+// AuditConfig configures an AuditLogger.
+type AuditConfig struct {
+	// Checksum turns on making the audit log tamper-evident:
+	// each event includes "seq", counting up from 1, and "checksum",
+	// the hex-encoded SHA-256 of the previous event's checksum followed by
+	// the JSON encoding of this event's attributes, sans "checksum".
+	// A removed, reordered, or altered event breaks the chain of checksums after it.
+	Checksum bool
+}
+
+// An AuditLogger writes AuditEvents to a dedicated [slog.Handler],
+// keeping them apart from application logs.
+//
+// AuditEvents are never sampled nor filtered by level:
+// an AuditLogger writes each one, at INFO, regardless of what its handler is enabled for.
+//
+// An AuditLogger is safe for concurrent use.
+type AuditLogger struct {
+	cfg  AuditConfig
+	h    slog.Handler
+	mu   sync.Mutex
+	prev string
+	seq  uint64
+}
+
+// NewAuditLogger constructs an *AuditLogger writing to h.
+func NewAuditLogger(h slog.Handler, cfg AuditConfig) *AuditLogger {
+	return &AuditLogger{cfg: cfg, h: h}
+}
+
+// Audit writes event.
+func (al *AuditLogger) Audit(event AuditEvent) {
+	if event.Outcome == "" {
+		event.Outcome = AuditSuccess
+	}
+
+	m := event.toMap()
+
+	al.mu.Lock()
+	defer al.mu.Unlock()
+
+	if al.cfg.Checksum {
+		al.seq++
+		m["seq"] = al.seq
+
+		sum := sha256.New()
+		sum.Write([]byte(al.prev))
+		if b, err := json.Marshal(m); err == nil {
+			sum.Write(b)
+		}
+
+		al.prev = hex.EncodeToString(sum.Sum(nil))
+		m["checksum"] = al.prev
+	}
+
+	rec := slog.NewRecord(time.Now(), slog.LevelInfo, "audit: "+string(event.Action), 0)
+	rec.AddAttrs(processLogValues(m)...)
+
+	// NOTE: an audit log cannot do anything about its handler failing.
+	_ = al.h.Handle(event.context(), rec)
+}
+
+// context retrieves the context.Context the AuditEvent happened in.
+func (event AuditEvent) context() context.Context {
+	if event.Context != nil {
+		return event.Context
+	}
+
+	if event.Request != nil {
+		return event.Request.Context()
+	}
+
+	return context.Background()
+}
+
+// toMap collects the non-zero values of the AuditEvent.
+func (event AuditEvent) toMap() map[string]any {
+	m := map[string]any{
+		"action":  string(event.Action),
+		"outcome": string(event.Outcome),
+	}
+
+	if event.ActorID != 0 {
+		m["actorId"] = event.ActorID
+	}
+
+	if event.Data != nil {
+		m["data"] = event.Data
+	}
+
+	if event.ImpersonatorID != 0 {
+		m["impersonatorId"] = event.ImpersonatorID
+	}
+
+	if event.TargetID != 0 {
+		m["targetId"] = event.TargetID
+	}
+
+	ctx := event.context()
+	r := make(map[string]any)
+	if event.Request != nil {
+		r["method"] = event.Request.Method
+		r["path"] = event.Request.URL.Path
+	}
+
+	if id, ok := trails.Value[string](ctx, trails.RequestIDKey); ok {
+		r["id"] = id
+	}
+
+	if ip, ok := trails.Value[string](ctx, trails.IpAddrKey); ok {
+		r["ip"] = ip
+	}
+
+	if len(r) > 0 {
+		m["request"] = r
+	}
+
+	return m
+}
+
+// defaultAuditLogger is the *AuditLogger Audit writes with.
+var defaultAuditLogger atomic.Pointer[AuditLogger]
+
+// SetDefaultAuditLogger makes al the *AuditLogger [Audit] writes with.
+// Setting nil restores writing with [slog.Default]'s handler.
+func SetDefaultAuditLogger(al *AuditLogger) { defaultAuditLogger.Store(al) }
+
+// Audit writes event with the *AuditLogger set by [SetDefaultAuditLogger].
+//
+// If none is set, Audit writes with [slog.Default]'s handler.
+func Audit(event AuditEvent) {
+	al := defaultAuditLogger.Load()
+	if al == nil {
+		al = NewAuditLogger(slog.Default().Handler(), AuditConfig{})
+	}
+
+	al.Audit(event)
+}
@@ -0,0 +1,73 @@
+package logger_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/xy-planning-network/trails"
+	"github.com/xy-planning-network/trails/logger"
+)
+
+func TestAuditLogger(t *testing.T) {
+	// Arrange
+	b := new(bytes.Buffer)
+	al := logger.NewAuditLogger(slog.NewJSONHandler(b, &slog.HandlerOptions{Level: slog.LevelError}), logger.AuditConfig{})
+
+	r := httptest.NewRequest(http.MethodPost, "https://example.com/login", nil)
+	r = r.WithContext(context.WithValue(r.Context(), trails.RequestIDKey, "req-1"))
+
+	// Act
+	al.Audit(logger.AuditEvent{Action: logger.AuditSessionLogin, ActorID: 1, Request: r})
+
+	// Assert
+	var actual map[string]any
+	require.NoError(t, json.Unmarshal(b.Bytes(), &actual))
+	require.Equal(t, "audit: session.login", actual["msg"])
+	require.Equal(t, "INFO", actual["level"])
+	require.Equal(t, "session.login", actual["action"])
+	require.Equal(t, "success", actual["outcome"])
+	require.Equal(t, float64(1), actual["actorId"])
+	require.Equal(t, map[string]any{"id": "req-1", "method": http.MethodPost, "path": "/login"}, actual["request"])
+	require.NotContains(t, actual, "checksum")
+}
+
+func TestAuditLoggerChecksum(t *testing.T) {
+	// Arrange
+	b := new(bytes.Buffer)
+	al := logger.NewAuditLogger(slog.NewJSONHandler(b, nil), logger.AuditConfig{Checksum: true})
+
+	// Act
+	al.Audit(logger.AuditEvent{Action: logger.AuditSessionLogin, ActorID: 1})
+	al.Audit(logger.AuditEvent{Action: logger.AuditImpersonationStart, ActorID: 1, TargetID: 2})
+	al.Audit(logger.AuditEvent{Action: logger.AuditSessionLogout, ActorID: 2, Outcome: logger.AuditFailure})
+
+	// Assert
+	dec := json.NewDecoder(b)
+
+	var prev string
+	for i := 1; dec.More(); i++ {
+		var event map[string]any
+		require.NoError(t, dec.Decode(&event))
+		require.Equal(t, float64(i), event["seq"])
+
+		checksum := event["checksum"]
+		for _, k := range []string{"checksum", "level", "msg", "time"} {
+			delete(event, k)
+		}
+
+		payload, err := json.Marshal(event)
+		require.NoError(t, err)
+
+		sum := sha256.Sum256(append([]byte(prev), payload...))
+		prev = hex.EncodeToString(sum[:])
+		require.Equal(t, prev, checksum)
+	}
+}
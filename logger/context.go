@@ -2,6 +2,7 @@ package logger
 
 import (
 	"bytes"
+	"context"
 	"encoding"
 	"encoding/json"
 	"fmt"
@@ -36,15 +37,31 @@ type LogContext struct {
 	// Caller helps goroutines identify the callers of the process that spawned it.
 	Caller uintptr
 
+	// Context is the context.Context of the process logging,
+	// whose attributes a [ContextHandler] includes; cf. [WithAttrs].
+	//
+	// If Context is nil and Request is not, the Request's context.Context is used.
+	Context context.Context
+
 	// Data is any information pertinent at the time of the logging event.
 	Data map[string]any
 
 	// Error is the error that may or may not have instigated a logging event.
 	Error error
 
+	// Fingerprint groups the Error with others sharing it in error tracking software,
+	// in place of grouping by the Error's message and stack trace; cf. [ErrorEnricher].
+	Fingerprint []string
+
 	// Request is the *http.Request that may or may not have been open during the logging event.
 	Request *http.Request
 
+	// Impersonator is the user impersonating User during the logging event, if any.
+	Impersonator LogUser
+
+	// Tags are indexed, searchable values error tracking software attaches to the Error.
+	Tags map[string]string
+
 	// LogUser is the user whose session was active during the logging event.
 	User LogUser
 
@@ -73,6 +90,19 @@ func (lc LogContext) String() string {
 	return string(b)
 }
 
+// context retrieves the context.Context the LogContext was logged with.
+func (lc LogContext) context() context.Context {
+	if lc.Context != nil {
+		return lc.Context
+	}
+
+	if lc.Request != nil {
+		return lc.Request.Context()
+	}
+
+	return context.TODO()
+}
+
 func (lc LogContext) attrs() []slog.Attr { return processLogValues(lc.toMap()) }
 
 func (lc LogContext) toMap() map[string]any {
@@ -85,6 +115,10 @@ func (lc LogContext) toMap() map[string]any {
 		m["error"] = lc.Error.Error()
 	}
 
+	if len(lc.Fingerprint) > 0 {
+		m["fingerprint"] = lc.Fingerprint
+	}
+
 	if lc.Request != nil {
 		printData := lc.env.IsDevelopment()
 
@@ -107,31 +141,55 @@ func (lc LogContext) toMap() map[string]any {
 			r["form"] = lc.Request.Form
 		}
 
-		if id, ok := lc.Request.Context().Value(trails.RequestIDKey).(string); ok {
+		if id, ok := trails.Value[string](lc.Request.Context(), trails.RequestIDKey); ok {
 			r["id"] = id
 		}
 
+		if id, ok := trails.Value[string](lc.Request.Context(), trails.TraceIDKey); ok {
+			r["traceId"] = id
+		}
+
 		if len(r) > 0 {
 			m["request"] = r
 		}
 	}
 
-	if lc.User != nil {
-		u := make(map[string]any)
-		if id := lc.User.GetID(); id != 0 {
-			u["id"] = id
-		}
-		if email := lc.User.GetEmail(); email != "" {
-			u["email"] = email
-		}
-		if len(u) > 0 {
-			m["user"] = u
+	if len(lc.Tags) > 0 {
+		tags := make(map[string]any, len(lc.Tags))
+		for k, v := range lc.Tags {
+			tags[k] = v
 		}
+		m["tags"] = tags
+	}
+
+	if u := logUserMap(lc.User); len(u) > 0 {
+		m["user"] = u
+	}
+
+	if u := logUserMap(lc.Impersonator); len(u) > 0 {
+		m["impersonator"] = u
 	}
 
 	return m
 }
 
+// logUserMap collects the non-zero identifying values of the LogUser.
+func logUserMap(lu LogUser) map[string]any {
+	if lu == nil {
+		return nil
+	}
+
+	u := make(map[string]any)
+	if id := lu.GetID(); id != 0 {
+		u["id"] = id
+	}
+	if email := lu.GetEmail(); email != "" {
+		u["email"] = email
+	}
+
+	return u
+}
+
 func processLogValues(m map[string]any) []slog.Attr {
 	var g []slog.Attr
 	for k, v := range m {
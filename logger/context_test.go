@@ -56,6 +56,16 @@ func TestLogContextMarshalText(t *testing.T) {
 	require.Nil(t, err)
 	require.Equal(t, `{"user":{"email":"test@example.com","id":1}}`, string(b))
 
+	// Arrange
+	lc = logger.LogContext{Impersonator: testUser{}}
+
+	// Act
+	b, err = lc.MarshalText()
+
+	// Assert
+	require.Nil(t, err)
+	require.Equal(t, `{"impersonator":{"email":"test@example.com","id":1}}`, string(b))
+
 	// Arrange
 	expected := map[string]any{
 		"request": map[string]any{
@@ -18,11 +18,11 @@ The [TrailsLogger] provides all the logging functionality needed for a trails ap
 It is the implementation of [Logger] returned by the [New] function.
 
 Log messages emitted by [TrailsLogger] are composed of a few parts:
-	- timestamp
-	- log level
-	- call site
-	- message
-	- log context
+  - timestamp
+  - log level
+  - call site
+  - message
+  - log context
 
 Here's an example:
 
@@ -34,6 +34,75 @@ Lastly, the log context is a JSON-encoded [*LogContext].
 The last component allows for including additional data inessential to the message proper,
 but provides a fuller picture of the application state at the time of logging.
 
+# Context
+
+Attributes attached to a context.Context with [WithAttrs] are included in every log emitted with that context.Context
+by a [slog.Handler] wrapped in a [ContextHandler].
+A [ContextHandler] also includes the request ID, trace ID, and current user's ID trails stashes in a request's context.Context.
+[TrailsLogger] uses [LogContext].Context, or else [LogContext].Request's context.Context:
+
+	ctx = logger.WithAttrs(ctx, slog.Int("exportId", id))
+	l.Info("starting export", &logger.LogContext{Context: ctx})
+
+# slog
+
+[FromSlog] constructs a [Logger] writing to any [slog.Handler],
+and [ToSlogHandler] adapts a [Logger] into a [slog.Handler],
+so libraries speaking slog natively log through trails, keeping their attributes and call sites:
+
+	lib.SetLogger(slog.New(logger.ToSlogHandler(l)))
+
+# Error grouping
+
+[SentryLogger] fills in the [LogContext] of each error it reports with [ErrorEnricher]s,
+so errors whose messages differ only by details, like IDs, are grouped into one Sentry issue.
+An error declares its own grouping by implementing [Fingerprinter];
+[CauseFingerprint] groups errors by the innermost error they wrap:
+
+	l = logger.NewSentryLogger(env, l, dsn, logger.CauseFingerprint)
+
+# Sampling
+
+A [slog.Handler] wrapped in a [SamplingHandler] drops logs according to a [SamplingConfig],
+limiting how many logs with the same level and message are written each window
+and dropping a percentage of DEBUG logs:
+
+	h = logger.NewSamplingHandler(h, logger.SamplingConfig{BurstLimit: 10, DebugDropPercent: 50})
+
+The first log written after some were suppressed counts them in a "suppressed" attribute.
+
+# Audit
+
+[Audit] records security-relevant events, like logging in or impersonating a user,
+apart from application logs, through the [AuditLogger] set with [SetDefaultAuditLogger].
+Audit logs are never sampled nor filtered by level;
+[AuditConfig].Checksum chains checksums through them, making them tamper-evident.
+The session package and the Impersonation middleware record their own events:
+
+	logger.Audit(logger.AuditEvent{Action: "user.delete", ActorID: admin.ID, Request: r, TargetID: user.ID})
+
+# Outputs
+
+Beyond any [io.Writer], logs can be written to a [RotatingFile], rotating by size or age,
+or to syslog through [NewSyslogWriter].
+An [OTLPHandler] exports logs to an OpenTelemetry collector;
+compose it with a JSON or text handler using [NewFanoutHandler]:
+
+	oh, err := logger.NewOTLPHandler(logger.OTLPConfig{Endpoint: "http://localhost:4318"})
+	h := logger.NewFanoutHandler(slog.NewJSONHandler(os.Stdout, nil), oh)
+	defer oh.Shutdown(ctx)
+
+# Panics
+
+A panic in a goroutine crashes the process, printing its stack trace to stderr, outside any log.
+[CapturePanics], deferred at the top of a goroutine, logs the panic and its stack trace as an error instead before exiting,
+writing a crash report to the directory set with [SetCrashReportDir], too:
+
+	go func() {
+		defer logger.CapturePanics(l)
+		work()
+	}()
+
 # SkipLogger
 
 Sometimes, especially with internal packages, the file and line number in a log needs to be configurable.
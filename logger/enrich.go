@@ -0,0 +1,87 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+)
+
+// An ErrorEnricher fills in a *LogContext reporting an error before it reaches error tracking software,
+// e.g., setting LogContext.Fingerprint so errors whose messages differ only by IDs are grouped together,
+// or adding to LogContext.Tags, LogContext.User, or LogContext.Data.
+//
+// ErrorEnrichers are called only when LogContext.Error is not nil.
+type ErrorEnricher interface {
+	EnrichError(ctx *LogContext)
+}
+
+// An ErrorEnricherFunc is a function implementing [ErrorEnricher].
+type ErrorEnricherFunc func(ctx *LogContext)
+
+// EnrichError calls fn.
+func (fn ErrorEnricherFunc) EnrichError(ctx *LogContext) { fn(ctx) }
+
+// A Fingerprinter is an error declaring how error tracking software groups it.
+// [EnrichError] uses the Fingerprint of the first error in LogContext.Error's tree implementing Fingerprinter.
+type Fingerprinter interface {
+	error
+	Fingerprint() []string
+}
+
+// EnrichError fills in ctx from a [Fingerprinter] in ctx.Error's tree, if any,
+// then calls the enrichers in order.
+//
+// If ctx or ctx.Error is nil, EnrichError does nothing.
+func EnrichError(ctx *LogContext, enrichers ...ErrorEnricher) {
+	if ctx == nil || ctx.Error == nil {
+		return
+	}
+
+	var fp Fingerprinter
+	if errors.As(ctx.Error, &fp) {
+		ctx.Fingerprint = fp.Fingerprint()
+	}
+
+	for _, e := range enrichers {
+		e.EnrichError(ctx)
+	}
+}
+
+// CauseFingerprint is an [ErrorEnricher] fingerprinting LogContext.Error by its root cause:
+// the type and message of the innermost error it wraps.
+// Errors wrapping a sentinel error, like [trails.ErrNotExist], with varying details
+// are grouped together.
+//
+// CauseFingerprint keeps a Fingerprint already set.
+var CauseFingerprint ErrorEnricher = ErrorEnricherFunc(func(ctx *LogContext) {
+	if len(ctx.Fingerprint) > 0 {
+		return
+	}
+
+	cause := rootCause(ctx.Error)
+	ctx.Fingerprint = []string{fmt.Sprintf("%T", cause), cause.Error()}
+})
+
+// rootCause unwraps err until reaching an error wrapping no others,
+// following the first error wrapped where err wraps many.
+func rootCause(err error) error {
+	for {
+		switch u := err.(type) {
+		case interface{ Unwrap() error }:
+			next := u.Unwrap()
+			if next == nil {
+				return err
+			}
+			err = next
+
+		case interface{ Unwrap() []error }:
+			errs := u.Unwrap()
+			if len(errs) == 0 || errs[0] == nil {
+				return err
+			}
+			err = errs[0]
+
+		default:
+			return err
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package logger_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/xy-planning-network/trails"
+	"github.com/xy-planning-network/trails/logger"
+)
+
+type fingerprintedErr struct{}
+
+func (fingerprintedErr) Error() string         { return "fingerprinted" }
+func (fingerprintedErr) Fingerprint() []string { return []string{"custom"} }
+
+func TestEnrichError(t *testing.T) {
+	tcs := []struct {
+		name      string
+		err       error
+		enrichers []logger.ErrorEnricher
+		expected  []string
+	}{
+		{"No-Enrichers", errors.New("boom"), nil, nil},
+		{"Fingerprinter", fmt.Errorf("wrapped: %w", fingerprintedErr{}), nil, []string{"custom"}},
+		{
+			"Cause",
+			fmt.Errorf("%w: user 1", trails.ErrNotExist),
+			[]logger.ErrorEnricher{logger.CauseFingerprint},
+			[]string{"*errors.errorString", "not exist"},
+		},
+		{
+			"Cause-Joined",
+			fmt.Errorf("%w: %w", fmt.Errorf("%w: user 2", trails.ErrNotValid), trails.ErrNotExist),
+			[]logger.ErrorEnricher{logger.CauseFingerprint},
+			[]string{"*errors.errorString", "invalid"},
+		},
+		{
+			"Cause-Keeps-Fingerprinter",
+			fingerprintedErr{},
+			[]logger.ErrorEnricher{logger.CauseFingerprint},
+			[]string{"custom"},
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			ctx := &logger.LogContext{Error: tc.err}
+
+			// Act
+			logger.EnrichError(ctx, tc.enrichers...)
+
+			// Assert
+			require.Equal(t, tc.expected, ctx.Fingerprint)
+		})
+	}
+}
+
+func TestEnrichErrorNoError(t *testing.T) {
+	// Arrange
+	called := false
+	ctx := &logger.LogContext{}
+
+	// Act
+	logger.EnrichError(nil, logger.ErrorEnricherFunc(func(*logger.LogContext) { called = true }))
+	logger.EnrichError(ctx, logger.ErrorEnricherFunc(func(*logger.LogContext) { called = true }))
+
+	// Assert
+	require.False(t, called)
+}
@@ -0,0 +1,64 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+)
+
+// fanoutHandler hands records off to each of its handlers.
+type fanoutHandler []slog.Handler
+
+// NewFanoutHandler constructs a [slog.Handler] handing each record off to every one of the handlers
+// enabled for its level, e.g., writing JSON to stdout while exporting to a collector with an [OTLPHandler].
+func NewFanoutHandler(handlers ...slog.Handler) slog.Handler {
+	return fanoutHandler(handlers)
+}
+
+// Enabled asserts whether any of the handlers is enabled for lvl.
+func (fh fanoutHandler) Enabled(ctx context.Context, lvl slog.Level) bool {
+	for _, h := range fh {
+		if h.Enabled(ctx, lvl) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Handle hands rec off to each of the handlers enabled for its level,
+// joining the errors they return.
+func (fh fanoutHandler) Handle(ctx context.Context, rec slog.Record) error {
+	var errs []error
+	for _, h := range fh {
+		if !h.Enabled(ctx, rec.Level) {
+			continue
+		}
+
+		if err := h.Handle(ctx, rec.Clone()); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// WithAttrs returns a [slog.Handler] whose handlers each have the attrs.
+func (fh fanoutHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	handlers := make(fanoutHandler, len(fh))
+	for i, h := range fh {
+		handlers[i] = h.WithAttrs(attrs)
+	}
+
+	return handlers
+}
+
+// WithGroup returns a [slog.Handler] whose handlers each have the group.
+func (fh fanoutHandler) WithGroup(name string) slog.Handler {
+	handlers := make(fanoutHandler, len(fh))
+	for i, h := range fh {
+		handlers[i] = h.WithGroup(name)
+	}
+
+	return handlers
+}
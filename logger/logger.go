@@ -1,7 +1,6 @@
 package logger
 
 import (
-	"context"
 	"log/slog"
 	"os"
 	"path"
@@ -95,7 +94,7 @@ func (l *TrailsLogger) log(level slog.Level, msg string, ctx *LogContext) {
 	rec := slog.NewRecord(time.Now(), level, msg, pc)
 	rec.AddAttrs(ctx.attrs()...)
 
-	l.l.Handler().Handle(context.TODO(), rec)
+	l.l.Handler().Handle(ctx.context(), rec)
 }
 
 // ColorizeLevel adds color to the log level!
@@ -0,0 +1,381 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/xy-planning-network/trails"
+)
+
+const (
+	// DefaultOTLPBatchSize is the number of logs an OTLPHandler exports in one request by default.
+	DefaultOTLPBatchSize = 512
+
+	// DefaultOTLPFlushInterval is how often an OTLPHandler exports logs by default.
+	DefaultOTLPFlushInterval = 5 * time.Second
+
+	// otlpLogsPath is the path OTLP/HTTP collectors receive logs on.
+	otlpLogsPath = "/v1/logs"
+)
+
+// OTLPConfig configures an OTLPHandler.
+type OTLPConfig struct {
+	// BatchSize is the number of logs buffered before exporting them early.
+	//
+	// If BatchSize is 0, DefaultOTLPBatchSize is used.
+	BatchSize int
+
+	// Client sends logs to Endpoint.
+	//
+	// If Client is nil, a client timing out after 10 seconds is used.
+	Client *http.Client
+
+	// Endpoint is the URL of the OTLP/HTTP collector, e.g., "http://localhost:4318".
+	// If Endpoint has no path, "/v1/logs" is used.
+	Endpoint string
+
+	// FlushInterval is how often buffered logs are exported.
+	//
+	// If FlushInterval is 0, DefaultOTLPFlushInterval is used.
+	FlushInterval time.Duration
+
+	// Headers are set on each request to Endpoint, e.g., for authenticating with the collector.
+	Headers map[string]string
+
+	// Level is the minimum level exported.
+	//
+	// If Level is nil, slog.LevelInfo is used.
+	Level slog.Leveler
+
+	// ServiceName identifies the application to the collector as the "service.name" resource attribute.
+	ServiceName string
+}
+
+// An OTLPHandler is a [slog.Handler] exporting logs to an OpenTelemetry collector
+// using OTLP/HTTP with JSON encoding.
+// Logs are buffered and exported in the background;
+// call [OTLPHandler.Shutdown] before exiting to export those remaining.
+//
+// Combine an OTLPHandler with other handlers using [NewFanoutHandler].
+type OTLPHandler struct {
+	attrs  []otlpKeyValue
+	exp    *otlpExporter
+	prefix string
+}
+
+// NewOTLPHandler constructs an *OTLPHandler from cfg, starting to export logs in the background.
+func NewOTLPHandler(cfg OTLPConfig) (*OTLPHandler, error) {
+	u, err := url.Parse(cfg.Endpoint)
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("%w: invalid OTLP endpoint %q", trails.ErrBadConfig, cfg.Endpoint)
+	}
+
+	if u.Path == "" || u.Path == "/" {
+		u.Path = otlpLogsPath
+	}
+	cfg.Endpoint = u.String()
+
+	if cfg.BatchSize == 0 {
+		cfg.BatchSize = DefaultOTLPBatchSize
+	}
+
+	if cfg.Client == nil {
+		cfg.Client = &http.Client{Timeout: 10 * time.Second}
+	}
+
+	if cfg.FlushInterval == 0 {
+		cfg.FlushInterval = DefaultOTLPFlushInterval
+	}
+
+	if cfg.Level == nil {
+		cfg.Level = slog.LevelInfo
+	}
+
+	exp := &otlpExporter{
+		cfg:  cfg,
+		done: make(chan struct{}),
+		full: make(chan struct{}, 1),
+	}
+	exp.wg.Add(1)
+	go exp.run()
+
+	return &OTLPHandler{exp: exp}, nil
+}
+
+// Enabled asserts whether the level is at or above OTLPConfig.Level.
+func (h *OTLPHandler) Enabled(_ context.Context, lvl slog.Level) bool {
+	return lvl >= h.exp.cfg.Level.Level()
+}
+
+// Handle buffers rec for exporting.
+func (h *OTLPHandler) Handle(_ context.Context, rec slog.Record) error {
+	attrs := make([]otlpKeyValue, len(h.attrs), len(h.attrs)+rec.NumAttrs())
+	copy(attrs, h.attrs)
+	rec.Attrs(func(a slog.Attr) bool {
+		attrs = appendOTLPAttr(attrs, h.prefix, a)
+		return true
+	})
+
+	ts := rec.Time
+	if ts.IsZero() {
+		ts = time.Now()
+	}
+
+	h.exp.add(otlpRecord{
+		Attributes:     attrs,
+		Body:           otlpAnyValue{StringValue: &rec.Message},
+		SeverityNumber: otlpSeverity(rec.Level),
+		SeverityText:   rec.Level.String(),
+		TimeUnixNano:   strconv.FormatInt(ts.UnixNano(), 10),
+	})
+
+	return nil
+}
+
+// WithAttrs returns an *OTLPHandler including the attrs in every log, sharing h's buffer.
+func (h *OTLPHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	h2 := *h
+	h2.attrs = make([]otlpKeyValue, len(h.attrs), len(h.attrs)+len(attrs))
+	copy(h2.attrs, h.attrs)
+	for _, a := range attrs {
+		h2.attrs = appendOTLPAttr(h2.attrs, h.prefix, a)
+	}
+
+	return &h2
+}
+
+// WithGroup returns an *OTLPHandler prefixing later attributes' keys with name, sharing h's buffer.
+func (h *OTLPHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+
+	h2 := *h
+	h2.prefix = h.prefix + name + "."
+
+	return &h2
+}
+
+// Shutdown stops exporting in the background and exports the logs remaining,
+// giving up when ctx is done.
+func (h *OTLPHandler) Shutdown(ctx context.Context) error {
+	h.exp.stop.Do(func() { close(h.exp.done) })
+	h.exp.wg.Wait()
+
+	return h.exp.flush(ctx)
+}
+
+// otlpExporter buffers and exports logs for an OTLPHandler and those derived from it.
+type otlpExporter struct {
+	cfg     OTLPConfig
+	done    chan struct{}
+	full    chan struct{}
+	mu      sync.Mutex
+	records []otlpRecord
+	stop    sync.Once
+	wg      sync.WaitGroup
+}
+
+// add buffers rec, signaling to export early when the buffer is full.
+func (exp *otlpExporter) add(rec otlpRecord) {
+	exp.mu.Lock()
+	exp.records = append(exp.records, rec)
+	full := len(exp.records) >= exp.cfg.BatchSize
+	exp.mu.Unlock()
+
+	if full {
+		select {
+		case exp.full <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// run exports buffered logs every OTLPConfig.FlushInterval or when the buffer fills up,
+// until exp.done closes.
+func (exp *otlpExporter) run() {
+	defer exp.wg.Done()
+
+	ticker := time.NewTicker(exp.cfg.FlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-exp.done:
+			return
+		case <-ticker.C:
+		case <-exp.full:
+		}
+
+		if err := exp.flush(context.Background()); err != nil {
+			// NOTE: reporting through slog could loop back into this exporter.
+			fmt.Fprintln(os.Stderr, "logger: "+err.Error())
+		}
+	}
+}
+
+// flush exports the buffered logs.
+func (exp *otlpExporter) flush(ctx context.Context) error {
+	exp.mu.Lock()
+	records := exp.records
+	exp.records = nil
+	exp.mu.Unlock()
+
+	if len(records) == 0 {
+		return nil
+	}
+
+	resource := otlpResource{}
+	if exp.cfg.ServiceName != "" {
+		resource.Attributes = []otlpKeyValue{{Key: "service.name", Value: otlpString(exp.cfg.ServiceName)}}
+	}
+
+	body, err := json.Marshal(otlpPayload{
+		ResourceLogs: []otlpResourceLogs{{
+			Resource: resource,
+			ScopeLogs: []otlpScopeLogs{{
+				LogRecords: records,
+				Scope:      otlpScope{Name: "github.com/xy-planning-network/trails"},
+			}},
+		}},
+	})
+	if err != nil {
+		return fmt.Errorf("could not encode OTLP logs: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, exp.cfg.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("could not build OTLP request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range exp.cfg.Headers {
+		req.Header.Set(k, v)
+	}
+
+	res, err := exp.cfg.Client.Do(req)
+	if err != nil {
+		return fmt.Errorf("could not export %d OTLP logs: %w", len(records), err)
+	}
+	res.Body.Close()
+
+	if res.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("could not export %d OTLP logs: collector responded %s", len(records), res.Status)
+	}
+
+	return nil
+}
+
+// otlpSeverity maps lvl onto OpenTelemetry's severity numbers,
+// where DEBUG is 5, INFO 9, WARN 13, and ERROR 17.
+func otlpSeverity(lvl slog.Level) int {
+	return min(max(int(lvl)+9, 1), 24)
+}
+
+// appendOTLPAttr appends a, its key prefixed, to kvs.
+func appendOTLPAttr(kvs []otlpKeyValue, prefix string, a slog.Attr) []otlpKeyValue {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return kvs
+	}
+
+	// NOTE: inline groups without a key, as slog's handlers do.
+	if a.Value.Kind() == slog.KindGroup && a.Key == "" {
+		for _, ga := range a.Value.Group() {
+			kvs = appendOTLPAttr(kvs, prefix, ga)
+		}
+
+		return kvs
+	}
+
+	return append(kvs, otlpKeyValue{Key: prefix + a.Key, Value: otlpValue(a.Value)})
+}
+
+// otlpValue converts v into an OTLP AnyValue.
+func otlpValue(v slog.Value) otlpAnyValue {
+	switch v.Kind() {
+	case slog.KindBool:
+		b := v.Bool()
+		return otlpAnyValue{BoolValue: &b}
+	case slog.KindFloat64:
+		f := v.Float64()
+		return otlpAnyValue{DoubleValue: &f}
+	case slog.KindInt64:
+		i := strconv.FormatInt(v.Int64(), 10)
+		return otlpAnyValue{IntValue: &i}
+	case slog.KindUint64:
+		i := strconv.FormatUint(v.Uint64(), 10)
+		return otlpAnyValue{IntValue: &i}
+	case slog.KindGroup:
+		var kvs []otlpKeyValue
+		for _, a := range v.Group() {
+			kvs = appendOTLPAttr(kvs, "", a)
+		}
+
+		return otlpAnyValue{KvlistValue: &otlpKeyValueList{Values: kvs}}
+	default:
+		return otlpString(v.String())
+	}
+}
+
+// otlpString converts s into an OTLP AnyValue.
+func otlpString(s string) otlpAnyValue { return otlpAnyValue{StringValue: &s} }
+
+// The types below mirror the JSON encoding of OTLP's ExportLogsServiceRequest.
+type (
+	otlpPayload struct {
+		ResourceLogs []otlpResourceLogs `json:"resourceLogs"`
+	}
+
+	otlpResourceLogs struct {
+		Resource  otlpResource    `json:"resource"`
+		ScopeLogs []otlpScopeLogs `json:"scopeLogs"`
+	}
+
+	otlpResource struct {
+		Attributes []otlpKeyValue `json:"attributes,omitempty"`
+	}
+
+	otlpScopeLogs struct {
+		LogRecords []otlpRecord `json:"logRecords"`
+		Scope      otlpScope    `json:"scope"`
+	}
+
+	otlpScope struct {
+		Name string `json:"name"`
+	}
+
+	otlpRecord struct {
+		Attributes     []otlpKeyValue `json:"attributes,omitempty"`
+		Body           otlpAnyValue   `json:"body"`
+		SeverityNumber int            `json:"severityNumber"`
+		SeverityText   string         `json:"severityText"`
+		TimeUnixNano   string         `json:"timeUnixNano"`
+	}
+
+	otlpKeyValue struct {
+		Key   string       `json:"key"`
+		Value otlpAnyValue `json:"value"`
+	}
+
+	otlpKeyValueList struct {
+		Values []otlpKeyValue `json:"values"`
+	}
+
+	otlpAnyValue struct {
+		BoolValue   *bool             `json:"boolValue,omitempty"`
+		DoubleValue *float64          `json:"doubleValue,omitempty"`
+		IntValue    *string           `json:"intValue,omitempty"`
+		KvlistValue *otlpKeyValueList `json:"kvlistValue,omitempty"`
+		StringValue *string           `json:"stringValue,omitempty"`
+	}
+)
@@ -0,0 +1,77 @@
+package logger_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/xy-planning-network/trails"
+	"github.com/xy-planning-network/trails/logger"
+)
+
+func TestOTLPHandler(t *testing.T) {
+	// Arrange
+	var (
+		auth    string
+		path    string
+		payload map[string]any
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		path = r.URL.Path
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+	}))
+	t.Cleanup(srv.Close)
+
+	oh, err := logger.NewOTLPHandler(logger.OTLPConfig{
+		Endpoint:    srv.URL,
+		Headers:     map[string]string{"Authorization": "Bearer token"},
+		ServiceName: "trails-test",
+	})
+	require.NoError(t, err)
+
+	b := new(bytes.Buffer)
+	l := slog.New(logger.NewFanoutHandler(slog.NewJSONHandler(b, nil), oh))
+
+	// Act
+	l.With("kind", "app").WithGroup("job").Warn("retrying", "attempt", 2)
+	l.Debug("dropped")
+	require.NoError(t, oh.Shutdown(context.Background()))
+
+	// Assert
+	require.Contains(t, b.String(), `"msg":"retrying"`)
+	require.Equal(t, "Bearer token", auth)
+	require.Equal(t, "/v1/logs", path)
+
+	resourceLogs := payload["resourceLogs"].([]any)[0].(map[string]any)
+	resource := resourceLogs["resource"].(map[string]any)
+	require.Equal(t, []any{map[string]any{
+		"key":   "service.name",
+		"value": map[string]any{"stringValue": "trails-test"},
+	}}, resource["attributes"])
+
+	records := resourceLogs["scopeLogs"].([]any)[0].(map[string]any)["logRecords"].([]any)
+	require.Len(t, records, 1)
+
+	actual := records[0].(map[string]any)
+	require.Equal(t, map[string]any{"stringValue": "retrying"}, actual["body"])
+	require.Equal(t, float64(13), actual["severityNumber"])
+	require.Equal(t, "WARN", actual["severityText"])
+	require.Equal(t, []any{
+		map[string]any{"key": "kind", "value": map[string]any{"stringValue": "app"}},
+		map[string]any{"key": "job.attempt", "value": map[string]any{"intValue": "2"}},
+	}, actual["attributes"])
+}
+
+func TestNewOTLPHandlerBadEndpoint(t *testing.T) {
+	// Act
+	_, err := logger.NewOTLPHandler(logger.OTLPConfig{Endpoint: "localhost"})
+
+	// Assert
+	require.ErrorIs(t, err, trails.ErrBadConfig)
+}
@@ -0,0 +1,101 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"sync/atomic"
+	"time"
+)
+
+// crashExitCode is the status a process exits with after CapturePanics logs a panic,
+// matching that of a process a panic crashes.
+const crashExitCode = 2
+
+// crashReportDir is the directory CapturePanics writes crash reports to.
+var crashReportDir atomic.Pointer[string]
+
+// SetCrashReportDir has [CapturePanics] write a crash report to a new file in dir before exiting,
+// creating dir if need be.
+// Setting "" stops writing crash reports.
+func SetCrashReportDir(dir string) { crashReportDir.Store(&dir) }
+
+// CapturePanics recovers a panic in the goroutine deferring it,
+// logs it with l as an error, along with its stack trace, and exits with status 2,
+// so a goroutine crashing the process leaves a structured log rather than a raw trace on stderr:
+//
+//	go func() {
+//		defer logger.CapturePanics(l)
+//		...
+//	}()
+//
+// If [SetCrashReportDir] set a directory, CapturePanics first writes a crash report there,
+// named after when and in which process the panic happened, e.g., "crash-20240102T150405Z-1234.txt".
+//
+// CapturePanics must be deferred itself; calling it from a deferred function recovers nothing.
+func CapturePanics(l Logger) {
+	p := recover()
+	if p == nil {
+		return
+	}
+
+	stack := debug.Stack()
+	data := map[string]any{"panic": fmt.Sprint(p), "stack": string(stack)}
+	if path, err := writeCrashReport(p, stack, time.Now()); err != nil {
+		data["crashReportError"] = err.Error()
+	} else if path != "" {
+		data["crashReport"] = path
+	}
+
+	err, ok := p.(error)
+	if !ok {
+		err = fmt.Errorf("%v", p)
+	}
+
+	l.Error(fmt.Sprintf("fatal: panic: %v", p), &LogContext{Data: data, Error: err})
+
+	// NOTE: exiting skips shutting down, so send errors to Sentry now.
+	FlushSentry(context.Background())
+	os.Exit(crashExitCode)
+}
+
+// writeCrashReport writes p and the stack trace of the goroutine it happened in
+// to a new file in the directory set by SetCrashReportDir, returning its path.
+// If no directory is set, writeCrashReport writes nothing.
+func writeCrashReport(p any, stack []byte, now time.Time) (string, error) {
+	dir := crashReportDir.Load()
+	if dir == nil || *dir == "" {
+		return "", nil
+	}
+
+	if err := os.MkdirAll(*dir, 0o755); err != nil {
+		return "", fmt.Errorf("could not write crash report: %w", err)
+	}
+
+	now = now.UTC()
+	path := filepath.Join(*dir, fmt.Sprintf("crash-%s-%d.txt", now.Format("20060102T150405Z"), os.Getpid()))
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "panic: %v\n", p)
+	fmt.Fprintf(&b, "time: %s\n", now.Format(time.RFC3339Nano))
+	fmt.Fprintf(&b, "pid: %d\n", os.Getpid())
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		fmt.Fprintf(&b, "go: %s\n", bi.GoVersion)
+		for _, s := range bi.Settings {
+			if s.Key == "vcs.revision" {
+				fmt.Fprintf(&b, "revision: %s\n", s.Value)
+			}
+		}
+	}
+	b.WriteString("\n")
+	b.Write(stack)
+
+	if err := os.WriteFile(path, b.Bytes(), 0o600); err != nil {
+		return "", fmt.Errorf("could not write crash report: %w", err)
+	}
+
+	return path, nil
+}
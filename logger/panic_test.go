@@ -0,0 +1,69 @@
+package logger_test
+
+import (
+	"errors"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/xy-planning-network/trails"
+	"github.com/xy-planning-network/trails/logger"
+)
+
+// crashEnvVar has TestCapturePanics crash the test binary running it.
+const crashEnvVar = "TRAILS_TEST_CRASH_DIR"
+
+func TestCapturePanics(t *testing.T) {
+	if dir := os.Getenv(crashEnvVar); dir != "" {
+		logger.SetCrashReportDir(dir)
+		l := logger.New(slog.New(slog.NewJSONHandler(os.Stdout, nil)), trails.Testing)
+
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			defer logger.CapturePanics(l)
+			panic("boom")
+		}()
+		<-done
+
+		return
+	}
+
+	// Arrange
+	dir := filepath.Join(t.TempDir(), "crashes")
+	cmd := exec.Command(os.Args[0], "-test.run=^TestCapturePanics$")
+	cmd.Env = append(os.Environ(), crashEnvVar+"="+dir)
+
+	// Act
+	out, err := cmd.Output()
+
+	// Assert
+	var exit *exec.ExitError
+	require.True(t, errors.As(err, &exit))
+	require.Equal(t, 2, exit.ExitCode())
+	require.Contains(t, string(out), `"msg":"fatal: panic: boom"`)
+	require.Contains(t, string(out), `"stack":"goroutine`)
+	require.Contains(t, string(out), `"crashReport":"`+dir)
+
+	reports, err := filepath.Glob(filepath.Join(dir, "crash-*.txt"))
+	require.NoError(t, err)
+	require.Len(t, reports, 1)
+
+	report, err := os.ReadFile(reports[0])
+	require.NoError(t, err)
+	require.Contains(t, string(report), "panic: boom\n")
+	require.Contains(t, string(report), "logger_test.TestCapturePanics")
+}
+
+func TestCapturePanicsNoPanic(t *testing.T) {
+	// Arrange
+	l := logger.New(slog.New(slog.NewJSONHandler(os.Stdout, nil)), trails.Testing)
+
+	// Act + Assert
+	require.NotPanics(t, func() {
+		defer logger.CapturePanics(l)
+	})
+}
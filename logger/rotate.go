@@ -0,0 +1,163 @@
+package logger
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+)
+
+// rotatedTimeFormat suffixes the names of rotated files so they sort oldest to newest.
+const rotatedTimeFormat = "20060102T150405.000000000"
+
+// RotateConfig configures when a RotatingFile rotates.
+type RotateConfig struct {
+	// MaxAge is how long a RotatingFile writes to the same file before rotating it.
+	//
+	// If MaxAge is 0, files are not rotated by age.
+	MaxAge time.Duration
+
+	// MaxBackups is the number of rotated files kept, deleting the oldest.
+	//
+	// If MaxBackups is 0, all rotated files are kept.
+	MaxBackups int
+
+	// MaxSize is the number of bytes a file may reach before a RotatingFile rotates it.
+	//
+	// If MaxSize is 0, files are not rotated by size.
+	MaxSize int64
+}
+
+// A RotatingFile is an [io.WriteCloser] appending to a file,
+// renaming it with a timestamp suffix and starting a new one
+// as the file grows larger or older than its RotateConfig allows.
+//
+// A RotatingFile is safe for concurrent use.
+type RotatingFile struct {
+	cfg    RotateConfig
+	f      *os.File
+	mu     sync.Mutex
+	opened time.Time
+	path   string
+	size   int64
+}
+
+// NewRotatingFile constructs a *RotatingFile appending to the file at path,
+// creating it if necessary.
+func NewRotatingFile(path string, cfg RotateConfig) (*RotatingFile, error) {
+	rf := &RotatingFile{cfg: cfg, path: path}
+	if err := rf.open(); err != nil {
+		return nil, err
+	}
+
+	return rf, nil
+}
+
+// Write appends p to the file, first rotating it if writing p breaks the RotateConfig.
+func (rf *RotatingFile) Write(p []byte) (int, error) {
+	rf.mu.Lock()
+	defer rf.mu.Unlock()
+
+	if rf.f == nil {
+		return 0, os.ErrClosed
+	}
+
+	tooBig := rf.cfg.MaxSize > 0 && rf.size > 0 && rf.size+int64(len(p)) > rf.cfg.MaxSize
+	tooOld := rf.cfg.MaxAge > 0 && time.Since(rf.opened) >= rf.cfg.MaxAge
+	if tooBig || tooOld {
+		if err := rf.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := rf.f.Write(p)
+	rf.size += int64(n)
+
+	return n, err
+}
+
+// Close closes the file; later writes fail.
+func (rf *RotatingFile) Close() error {
+	rf.mu.Lock()
+	defer rf.mu.Unlock()
+
+	if rf.f == nil {
+		return nil
+	}
+
+	err := rf.f.Close()
+	rf.f = nil
+
+	return err
+}
+
+// Rotate renames the file and starts a new one regardless of the RotateConfig.
+func (rf *RotatingFile) Rotate() error {
+	rf.mu.Lock()
+	defer rf.mu.Unlock()
+
+	if rf.f == nil {
+		return os.ErrClosed
+	}
+
+	return rf.rotate()
+}
+
+// open opens the file at rf.path for appending.
+func (rf *RotatingFile) open() error {
+	f, err := os.OpenFile(rf.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("could not open log file: %w", err)
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return fmt.Errorf("could not stat log file: %w", err)
+	}
+
+	rf.f = f
+	rf.opened = time.Now()
+	rf.size = info.Size()
+
+	return nil
+}
+
+// rotate renames the file, opens a new one, and prunes rotated files beyond RotateConfig.MaxBackups.
+//
+// The caller must hold rf.mu.
+func (rf *RotatingFile) rotate() error {
+	if err := rf.f.Close(); err != nil {
+		return fmt.Errorf("could not close log file: %w", err)
+	}
+	rf.f = nil
+
+	if err := os.Rename(rf.path, rf.path+"."+time.Now().Format(rotatedTimeFormat)); err != nil {
+		return fmt.Errorf("could not rotate log file: %w", err)
+	}
+
+	if err := rf.open(); err != nil {
+		return err
+	}
+
+	if rf.cfg.MaxBackups == 0 {
+		return nil
+	}
+
+	rotated, err := filepath.Glob(rf.path + ".*")
+	if err != nil {
+		return fmt.Errorf("could not list rotated log files: %w", err)
+	}
+
+	slices.Sort(rotated)
+	for len(rotated) > rf.cfg.MaxBackups {
+		if err := os.Remove(rotated[0]); err != nil {
+			return fmt.Errorf("could not remove rotated log file: %w", err)
+		}
+		rotated = rotated[1:]
+	}
+
+	return nil
+}
@@ -0,0 +1,42 @@
+package logger_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/xy-planning-network/trails/logger"
+)
+
+func TestRotatingFile(t *testing.T) {
+	// Arrange
+	path := filepath.Join(t.TempDir(), "app.log")
+	rf, err := logger.NewRotatingFile(path, logger.RotateConfig{MaxBackups: 1, MaxSize: 10})
+	require.NoError(t, err)
+	t.Cleanup(func() { rf.Close() })
+
+	// Act
+	for _, line := range []string{"first\n", "second\n", "third\n"} {
+		_, err = rf.Write([]byte(line))
+		require.NoError(t, err)
+	}
+
+	// Assert
+	actual, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, "third\n", string(actual))
+
+	rotated, err := filepath.Glob(path + ".*")
+	require.NoError(t, err)
+	require.Len(t, rotated, 1)
+
+	actual, err = os.ReadFile(rotated[0])
+	require.NoError(t, err)
+	require.Equal(t, "second\n", string(actual))
+
+	// Act + Assert
+	require.NoError(t, rf.Close())
+	_, err = rf.Write([]byte("closed\n"))
+	require.ErrorIs(t, err, os.ErrClosed)
+}
@@ -0,0 +1,133 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+	"math/rand/v2"
+	"sync"
+	"time"
+)
+
+// DefaultBurstWindow is the period a SamplingHandler counts identical records over by default.
+const DefaultBurstWindow = time.Minute
+
+// SamplingConfig configures a SamplingHandler.
+type SamplingConfig struct {
+	// BurstLimit is the number of records with the same level and message
+	// passed on each BurstWindow; those beyond it are dropped.
+	// The first record passed on in the next BurstWindow counts those dropped as "suppressed".
+	//
+	// If BurstLimit is 0, records are not limited.
+	BurstLimit int
+
+	// BurstWindow is the period over which BurstLimit applies.
+	//
+	// If BurstWindow is 0, DefaultBurstWindow is used.
+	BurstWindow time.Duration
+
+	// DebugDropPercent is the percentage of DEBUG records, chosen at random, dropped.
+	//
+	// If DebugDropPercent is 0, no DEBUG records are dropped.
+	DebugDropPercent int
+}
+
+// A SamplingHandler is a [slog.Handler] dropping records according to a SamplingConfig,
+// guarding against floods of identical log messages.
+type SamplingHandler struct {
+	slog.Handler
+	cfg   SamplingConfig
+	state *samplingState
+}
+
+// samplingState is shared by a SamplingHandler and those derived from it.
+type samplingState struct {
+	counts      map[samplingKey]int
+	mu          sync.Mutex
+	suppressed  map[samplingKey]int
+	windowStart time.Time
+}
+
+// samplingKey identifies identical records.
+type samplingKey struct {
+	level slog.Level
+	msg   string
+}
+
+// NewSamplingHandler wraps h in a SamplingHandler.
+func NewSamplingHandler(h slog.Handler, cfg SamplingConfig) slog.Handler {
+	if cfg.BurstWindow == 0 {
+		cfg.BurstWindow = DefaultBurstWindow
+	}
+
+	return SamplingHandler{
+		Handler: h,
+		cfg:     cfg,
+		state: &samplingState{
+			counts:     make(map[samplingKey]int),
+			suppressed: make(map[samplingKey]int),
+		},
+	}
+}
+
+// Handle hands rec off to the wrapped [slog.Handler] unless the SamplingConfig drops it.
+func (h SamplingHandler) Handle(ctx context.Context, rec slog.Record) error {
+	if rec.Level == slog.LevelDebug && h.cfg.DebugDropPercent > 0 && rand.IntN(100) < h.cfg.DebugDropPercent {
+		return nil
+	}
+
+	if h.cfg.BurstLimit > 0 {
+		suppressed, ok := h.state.allow(samplingKey{rec.Level, rec.Message}, h.cfg)
+		if !ok {
+			return nil
+		}
+
+		if suppressed > 0 {
+			rec.AddAttrs(slog.Int("suppressed", suppressed))
+		}
+	}
+
+	return h.Handler.Handle(ctx, rec)
+}
+
+// WithAttrs returns a SamplingHandler whose wrapped [slog.Handler] has the attrs,
+// sharing counts with h.
+func (h SamplingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	h.Handler = h.Handler.WithAttrs(attrs)
+	return h
+}
+
+// WithGroup returns a SamplingHandler whose wrapped [slog.Handler] has the group,
+// sharing counts with h.
+func (h SamplingHandler) WithGroup(name string) slog.Handler {
+	h.Handler = h.Handler.WithGroup(name)
+	return h
+}
+
+// allow counts a record identified by key,
+// reporting whether it is within the burst limit
+// and how many identical records were dropped in the last window, if it is the first in this one.
+func (s *samplingState) allow(key samplingKey, cfg SamplingConfig) (int, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if now := time.Now(); now.Sub(s.windowStart) >= cfg.BurstWindow {
+		for k, n := range s.counts {
+			if n > cfg.BurstLimit {
+				s.suppressed[k] += n - cfg.BurstLimit
+			}
+		}
+
+		clear(s.counts)
+		s.windowStart = now
+	}
+
+	s.counts[key]++
+	if s.counts[key] > cfg.BurstLimit {
+		return 0, false
+	}
+
+	suppressed := s.suppressed[key]
+	delete(s.suppressed, key)
+
+	return suppressed, true
+}
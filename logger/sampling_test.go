@@ -0,0 +1,74 @@
+package logger_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/xy-planning-network/trails/logger"
+)
+
+func TestSamplingHandlerBurst(t *testing.T) {
+	// Arrange
+	b := new(bytes.Buffer)
+	window := 100 * time.Millisecond
+	l := slog.New(logger.NewSamplingHandler(
+		slog.NewJSONHandler(b, nil),
+		logger.SamplingConfig{BurstLimit: 2, BurstWindow: window},
+	))
+
+	// Act
+	for range 5 {
+		l.Error("boom")
+	}
+	l.With("kind", "app").Error("other")
+	time.Sleep(window)
+	l.Error("boom")
+
+	// Assert
+	dec := json.NewDecoder(b)
+
+	var msgs []string
+	var last map[string]any
+	for dec.More() {
+		last = make(map[string]any)
+		require.NoError(t, dec.Decode(&last))
+		msgs = append(msgs, last["msg"].(string))
+	}
+
+	require.Equal(t, []string{"boom", "boom", "other", "boom"}, msgs)
+	require.Equal(t, float64(3), last["suppressed"])
+}
+
+func TestSamplingHandlerDebug(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		percent  int
+		expected int
+	}{
+		{"Zero", 0, 10},
+		{"All", 100, 0},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			b := new(bytes.Buffer)
+			opts := &slog.HandlerOptions{Level: slog.LevelDebug}
+			l := slog.New(logger.NewSamplingHandler(
+				slog.NewJSONHandler(b, opts),
+				logger.SamplingConfig{DebugDropPercent: tc.percent},
+			))
+
+			// Act
+			for range 10 {
+				l.Debug("noisy")
+			}
+			l.Info("kept")
+
+			// Assert
+			require.Equal(t, tc.expected+1, bytes.Count(b.Bytes(), []byte("\n")))
+		})
+	}
+}
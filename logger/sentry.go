@@ -19,12 +19,16 @@ const (
 // A SentryLogger logs messages and reports sufficiently important
 // ones to error tracking software Sentry (https://sentry.io).
 type SentryLogger struct {
-	l Logger
+	enrichers []ErrorEnricher
+	l         Logger
 }
 
 // NewSentryLogger constructs a [*SentryLogger] based off the provided [*TrailsLogger],
 // routing messages to the DSN provided.
-func NewSentryLogger(env trails.Environment, l Logger, dsn string) Logger {
+//
+// Before reporting an error, the *SentryLogger fills in its *LogContext with the enrichers;
+// cf. [EnrichError].
+func NewSentryLogger(env trails.Environment, l Logger, dsn string, enrichers ...ErrorEnricher) Logger {
 	err := sentry.Init(sentry.ClientOptions{
 		Dsn:           dsn,
 		Environment:   env.String(),
@@ -39,13 +43,15 @@ func NewSentryLogger(env trails.Environment, l Logger, dsn string) Logger {
 	}
 	l.Debug("initing SentryLogger", nil)
 
-	return &SentryLogger{l: l.AddSkip(l.Skip() + knownSentryLogFrames)}
+	return &SentryLogger{enrichers: enrichers, l: l.AddSkip(l.Skip() + knownSentryLogFrames)}
 }
 
 // Unwrap exposes the underlying Logger backing the *SentryLogger.
 func (sl *SentryLogger) Unwrap() Logger { return sl.l }
 
-func (sl *SentryLogger) AddSkip(i int) Logger { return &SentryLogger{sl.l.AddSkip(i)} }
+func (sl *SentryLogger) AddSkip(i int) Logger {
+	return &SentryLogger{enrichers: sl.enrichers, l: sl.l.AddSkip(i)}
+}
 
 func (sl *SentryLogger) Skip() int { return sl.l.Skip() }
 
@@ -81,6 +87,11 @@ func (sl *SentryLogger) send(level sentry.Level, ctx *LogContext) {
 		return
 	}
 
+	// NOTE: enrich a copy, leaving the caller's *LogContext as it was.
+	enriched := *ctx
+	ctx = &enriched
+	EnrichError(ctx, sl.enrichers...)
+
 	sentry.WithScope(func(scope *sentry.Scope) {
 		if ctx.User != nil {
 			scope.SetUser(sentry.User{
@@ -97,8 +108,16 @@ func (sl *SentryLogger) send(level sentry.Level, ctx *LogContext) {
 			scope.SetContext("data", ctx.Data)
 		}
 
-		// attempt to populate tags from Data
+		if len(ctx.Fingerprint) > 0 {
+			scope.SetFingerprint(ctx.Fingerprint)
+		}
+
+		// attempt to populate tags from Data, then Tags
 		tags := convertMapAnyToString(ctx.Data)
+		for k, v := range ctx.Tags {
+			tags[k] = v
+		}
+
 		for k, v := range tags {
 			// Sentry tag keys/values are limited to 32 and 200 chars respectively
 			maxK := 32
@@ -0,0 +1,154 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+	"maps"
+)
+
+// FromSlog constructs a Logger writing to h,
+// e.g., a handler a library configures for itself, so trails logs alongside it.
+//
+// The Logger does not know the trails.Environment it runs in,
+// so never logs request bodies or forms; cf. New.
+func FromSlog(h slog.Handler) Logger { return New(slog.New(h), "") }
+
+// ToSlogHandler constructs a [slog.Handler] writing records to l,
+// e.g., to hand a library speaking slog natively a *slog.Logger logging as the rest of the app does:
+//
+//	lib.SetLogger(slog.New(logger.ToSlogHandler(rng.Logger)))
+//
+// The attributes of a record are logged in [LogContext].Data, nested by group,
+// except an error under the "error" key, which is logged as [LogContext].Error.
+// The record's context.Context is logged as [LogContext].Context.
+//
+// Records are logged from where the library logged them, not from the handler,
+// so the number of frames l skips does not matter.
+func ToSlogHandler(l Logger) slog.Handler {
+	return &slogHandler{l: l}
+}
+
+// slogHandler adapts a Logger into a [slog.Handler].
+type slogHandler struct {
+	attrs  map[string]any
+	groups []string
+	l      Logger
+}
+
+// Enabled asserts whether the Logger writes records at lvl.
+// Only a [*TrailsLogger] can tell; any other Logger is assumed to write records at every level.
+func (h *slogHandler) Enabled(ctx context.Context, lvl slog.Level) bool {
+	if tl, ok := h.l.(*TrailsLogger); ok {
+		return tl.l.Handler().Enabled(ctx, lvl)
+	}
+
+	return true
+}
+
+// Handle writes rec to the Logger at the method matching its level.
+func (h *slogHandler) Handle(ctx context.Context, rec slog.Record) error {
+	data := cloneAttrMap(h.attrs)
+	lc := &LogContext{Caller: rec.PC, Context: ctx}
+
+	rec.Attrs(func(a slog.Attr) bool {
+		if err, ok := a.Value.Resolve().Any().(error); ok && a.Key == "error" && len(h.groups) == 0 {
+			lc.Error = err
+			return true
+		}
+
+		addAttr(data, h.groups, a)
+		return true
+	})
+
+	if len(data) > 0 {
+		lc.Data = data
+	}
+
+	switch {
+	case rec.Level >= slog.LevelError:
+		h.l.Error(rec.Message, lc)
+	case rec.Level >= slog.LevelWarn:
+		h.l.Warn(rec.Message, lc)
+	case rec.Level >= slog.LevelInfo:
+		h.l.Info(rec.Message, lc)
+	default:
+		h.l.Debug(rec.Message, lc)
+	}
+
+	return nil
+}
+
+// WithAttrs returns a [slog.Handler] logging attrs with every record.
+func (h *slogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+
+	next := *h
+	next.attrs = cloneAttrMap(h.attrs)
+	for _, a := range attrs {
+		addAttr(next.attrs, h.groups, a)
+	}
+
+	return &next
+}
+
+// WithGroup returns a [slog.Handler] nesting the attributes of every record under name.
+func (h *slogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+
+	next := *h
+	next.groups = append(h.groups[:len(h.groups):len(h.groups)], name)
+	return &next
+}
+
+// addAttr sets a in data, nested under groups, expanding a group into a map.
+func addAttr(data map[string]any, groups []string, a slog.Attr) {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return
+	}
+
+	for _, g := range groups {
+		sub, ok := data[g].(map[string]any)
+		if !ok {
+			sub = make(map[string]any)
+			data[g] = sub
+		}
+
+		data = sub
+	}
+
+	if a.Value.Kind() != slog.KindGroup {
+		data[a.Key] = a.Value.Any()
+		return
+	}
+
+	// NOTE: as slog does, an unnamed group's attributes are inlined.
+	var nested []string
+	if a.Key != "" {
+		nested = []string{a.Key}
+	}
+
+	for _, ga := range a.Value.Group() {
+		addAttr(data, nested, ga)
+	}
+}
+
+// cloneAttrMap deeply copies m, so attributes added to the copy are not added to m.
+func cloneAttrMap(m map[string]any) map[string]any {
+	out := maps.Clone(m)
+	if out == nil {
+		out = make(map[string]any)
+	}
+
+	for k, v := range out {
+		if sub, ok := v.(map[string]any); ok {
+			out[k] = cloneAttrMap(sub)
+		}
+	}
+
+	return out
+}
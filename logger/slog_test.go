@@ -0,0 +1,100 @@
+package logger_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/xy-planning-network/trails/logger"
+)
+
+func TestFromSlog(t *testing.T) {
+	// Arrange
+	b := new(bytes.Buffer)
+	l := logger.FromSlog(slog.NewJSONHandler(b, &slog.HandlerOptions{AddSource: true}))
+
+	// Act
+	l.Info("hello", &logger.LogContext{Data: map[string]any{"n": 1}})
+
+	// Assert
+	var out map[string]any
+	require.NoError(t, json.Unmarshal(b.Bytes(), &out))
+	require.Equal(t, "hello", out["msg"])
+	require.Equal(t, map[string]any{"n": float64(1)}, out["data"])
+	require.Contains(t, out, "source")
+}
+
+func TestToSlogHandler(t *testing.T) {
+	// Arrange
+	b := new(bytes.Buffer)
+	h := slog.NewJSONHandler(b, &slog.HandlerOptions{AddSource: true, Level: slog.LevelInfo})
+	l := slog.New(logger.ToSlogHandler(logger.New(slog.New(h), "")).WithAttrs([]slog.Attr{slog.String("lib", "x")}))
+
+	// Act
+	l.WithGroup("req").With("id", 7).Warn("slow", "ms", 300, slog.Group("db", "rows", 2))
+
+	// Assert
+	var out map[string]any
+	require.NoError(t, json.Unmarshal(b.Bytes(), &out))
+	require.Equal(t, "WARN", out["level"])
+	require.Equal(t, "slow", out["msg"])
+	require.Equal(t, map[string]any{
+		"lib": "x",
+		"req": map[string]any{
+			"id": float64(7),
+			"ms": float64(300),
+			"db": map[string]any{"rows": float64(2)},
+		},
+	}, out["data"])
+	require.True(t, strings.HasSuffix(out["source"].(map[string]any)["file"].(string), "slog_test.go"))
+
+	// Arrange
+	b.Reset()
+
+	// Act
+	l.Error("failed", "error", errors.New("boom"))
+
+	// Assert
+	out = nil
+	require.NoError(t, json.Unmarshal(b.Bytes(), &out))
+	require.Equal(t, "boom", out["error"])
+	require.Equal(t, map[string]any{"lib": "x"}, out["data"])
+
+	// Assert
+	require.False(t, l.Enabled(context.Background(), slog.LevelDebug))
+	require.True(t, l.Enabled(context.Background(), slog.LevelInfo))
+}
+
+type levelLogger struct {
+	logger.Logger
+	levels []string
+}
+
+func (l *levelLogger) Debug(string, *logger.LogContext) { l.levels = append(l.levels, "debug") }
+func (l *levelLogger) Error(string, *logger.LogContext) { l.levels = append(l.levels, "error") }
+func (l *levelLogger) Info(string, *logger.LogContext)  { l.levels = append(l.levels, "info") }
+func (l *levelLogger) Warn(string, *logger.LogContext)  { l.levels = append(l.levels, "warn") }
+
+func TestToSlogHandlerLevels(t *testing.T) {
+	// Arrange
+	ll := new(levelLogger)
+	l := slog.New(logger.ToSlogHandler(ll))
+
+	// Act
+	l.Log(context.Background(), slog.LevelDebug-4, "")
+	l.Debug("")
+	l.Info("")
+	l.Log(context.Background(), slog.LevelInfo+2, "")
+	l.Warn("")
+	l.Error("")
+	l.Log(context.Background(), slog.LevelError+4, "")
+
+	// Assert
+	require.Equal(t, []string{"debug", "debug", "info", "info", "warn", "error", "error"}, ll.levels)
+	require.True(t, l.Enabled(context.Background(), slog.LevelDebug))
+}
@@ -0,0 +1,16 @@
+//go:build !windows && !plan9
+
+package logger
+
+import (
+	"io"
+	"log/syslog"
+)
+
+// NewSyslogWriter connects to the syslog daemon at raddr over network,
+// returning an [io.WriteCloser] sending each write as a message tagged with tag.
+//
+// If network is empty, NewSyslogWriter connects to the local syslog daemon.
+func NewSyslogWriter(network, raddr, tag string) (io.WriteCloser, error) {
+	return syslog.Dial(network, raddr, syslog.LOG_INFO|syslog.LOG_USER, tag)
+}
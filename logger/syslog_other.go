@@ -0,0 +1,16 @@
+//go:build windows || plan9
+
+package logger
+
+import (
+	"fmt"
+	"io"
+	"runtime"
+
+	"github.com/xy-planning-network/trails"
+)
+
+// NewSyslogWriter returns an error since syslog is not available on this platform.
+func NewSyslogWriter(network, raddr, tag string) (io.WriteCloser, error) {
+	return nil, fmt.Errorf("%w: syslog unavailable on %s", trails.ErrBadConfig, runtime.GOOS)
+}
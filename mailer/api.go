@@ -0,0 +1,199 @@
+package mailer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/mail"
+	"strings"
+	"time"
+
+	"github.com/xy-planning-network/trails"
+)
+
+const (
+	// DefaultPostmarkURL is the Postmark API a PostmarkMailer sends through by default.
+	DefaultPostmarkURL = "https://api.postmarkapp.com"
+
+	// DefaultSendGridURL is the SendGrid API a SendGridMailer sends through by default.
+	DefaultSendGridURL = "https://api.sendgrid.com"
+
+	// apiTimeout bounds requests to email providers' APIs.
+	apiTimeout = 10 * time.Second
+)
+
+// APIConfig configures a Mailer sending emails through a provider's HTTP API.
+type APIConfig struct {
+	// BaseURL is the provider's API, e.g., for pointing at a sandbox.
+	//
+	// If BaseURL is empty, the provider's default is used.
+	BaseURL string
+
+	// Client sends requests to BaseURL.
+	//
+	// If Client is nil, a client timing out after 10 seconds is used.
+	Client *http.Client
+
+	// Key authenticates with the provider, e.g., a Postmark server token or a SendGrid API key.
+	Key string
+}
+
+// withDefaults fills in the zero values of the APIConfig.
+func (cfg APIConfig) withDefaults(baseURL string) (APIConfig, error) {
+	if cfg.Key == "" {
+		return cfg, fmt.Errorf("%w: missing API key", trails.ErrBadConfig)
+	}
+
+	if cfg.BaseURL == "" {
+		cfg.BaseURL = baseURL
+	}
+	cfg.BaseURL = strings.TrimSuffix(cfg.BaseURL, "/")
+
+	if cfg.Client == nil {
+		cfg.Client = &http.Client{Timeout: apiTimeout}
+	}
+
+	return cfg, nil
+}
+
+// A PostmarkMailer is a Mailer sending emails through Postmark; cf. https://postmarkapp.com/developer.
+type PostmarkMailer struct {
+	cfg APIConfig
+}
+
+// NewPostmarkMailer constructs a *PostmarkMailer from cfg, whose Key is a Postmark server token.
+func NewPostmarkMailer(cfg APIConfig) (*PostmarkMailer, error) {
+	cfg, err := cfg.withDefaults(DefaultPostmarkURL)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PostmarkMailer{cfg: cfg}, nil
+}
+
+// Send sends msg through Postmark.
+func (m *PostmarkMailer) Send(ctx context.Context, msg Message) error {
+	if err := msg.Valid(); err != nil {
+		return err
+	}
+
+	body := map[string]string{
+		"Bcc":      strings.Join(msg.BCC, ","),
+		"Cc":       strings.Join(msg.CC, ","),
+		"From":     msg.From,
+		"HtmlBody": msg.HTML,
+		"ReplyTo":  msg.ReplyTo,
+		"Subject":  msg.Subject,
+		"TextBody": msg.Text,
+		"To":       strings.Join(msg.To, ","),
+	}
+
+	header := http.Header{"X-Postmark-Server-Token": {m.cfg.Key}}
+	return post(ctx, m.cfg.Client, m.cfg.BaseURL+"/email", header, body)
+}
+
+// A SendGridMailer is a Mailer sending emails through SendGrid; cf. https://docs.sendgrid.com/api-reference.
+type SendGridMailer struct {
+	cfg APIConfig
+}
+
+// NewSendGridMailer constructs a *SendGridMailer from cfg, whose Key is a SendGrid API key.
+func NewSendGridMailer(cfg APIConfig) (*SendGridMailer, error) {
+	cfg, err := cfg.withDefaults(DefaultSendGridURL)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SendGridMailer{cfg: cfg}, nil
+}
+
+// sendGridAddress is the JSON encoding of an address in SendGrid's API.
+type sendGridAddress struct {
+	Email string `json:"email"`
+	Name  string `json:"name,omitempty"`
+}
+
+// Send sends msg through SendGrid.
+func (m *SendGridMailer) Send(ctx context.Context, msg Message) error {
+	if err := msg.Valid(); err != nil {
+		return err
+	}
+
+	addrs := func(list []string) []sendGridAddress {
+		out := make([]sendGridAddress, len(list))
+		for i, addr := range list {
+			a, _ := mail.ParseAddress(addr)
+			out[i] = sendGridAddress{Email: a.Address, Name: a.Name}
+		}
+
+		return out
+	}
+
+	personalization := make(map[string][]sendGridAddress)
+	for k, list := range map[string][]string{"bcc": msg.BCC, "cc": msg.CC, "to": msg.To} {
+		if len(list) > 0 {
+			personalization[k] = addrs(list)
+		}
+	}
+
+	// NOTE: SendGrid requires plain text before HTML.
+	var content []map[string]string
+	if msg.Text != "" {
+		content = append(content, map[string]string{"type": "text/plain", "value": msg.Text})
+	}
+	if msg.HTML != "" {
+		content = append(content, map[string]string{"type": "text/html", "value": msg.HTML})
+	}
+
+	body := map[string]any{
+		"content":          content,
+		"from":             addrs([]string{msg.From})[0],
+		"personalizations": []any{personalization},
+		"subject":          msg.Subject,
+	}
+	if msg.ReplyTo != "" {
+		body["reply_to"] = addrs([]string{msg.ReplyTo})[0]
+	}
+
+	header := http.Header{"Authorization": {"Bearer " + m.cfg.Key}}
+	return post(ctx, m.cfg.Client, m.cfg.BaseURL+"/v3/mail/send", header, body)
+}
+
+// post sends body, encoded as JSON, to url,
+// returning an error including the response if the provider does not accept it.
+func post(ctx context.Context, client *http.Client, url string, header http.Header, body any) error {
+	b, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("could not encode email: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(b))
+	if err != nil {
+		return fmt.Errorf("could not build email request: %w", err)
+	}
+
+	req.Header = header
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Content-Type", "application/json")
+
+	return do(client, req)
+}
+
+// do sends req, returning an error including the response if the provider does not accept it.
+func do(client *http.Client, req *http.Request) error {
+	res, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("could not send email: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode >= http.StatusMultipleChoices {
+		msg, _ := io.ReadAll(io.LimitReader(res.Body, 1<<10))
+		return fmt.Errorf("could not send email: provider responded %s: %s", res.Status, bytes.TrimSpace(msg))
+	}
+
+	return nil
+}
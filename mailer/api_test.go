@@ -0,0 +1,98 @@
+package mailer_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/xy-planning-network/trails"
+	"github.com/xy-planning-network/trails/mailer"
+)
+
+// captureServer starts a server responding with status and recording the last request and its JSON body.
+func captureServer(t *testing.T, status int) (*httptest.Server, *http.Request, map[string]any) {
+	t.Helper()
+
+	req := new(http.Request)
+	body := make(map[string]any)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*req = *r.Clone(context.Background())
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		w.WriteHeader(status)
+		w.Write([]byte(`{"Message":"nope"}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	return srv, req, body
+}
+
+func TestPostmarkMailer(t *testing.T) {
+	// Arrange
+	srv, req, body := captureServer(t, http.StatusOK)
+	m, err := mailer.NewPostmarkMailer(mailer.APIConfig{BaseURL: srv.URL, Key: "token"})
+	require.NoError(t, err)
+
+	msg := validMessage()
+	msg.CC = []string{"a@example.com", "b@example.com"}
+
+	// Act
+	err = m.Send(context.Background(), msg)
+
+	// Assert
+	require.NoError(t, err)
+	require.Equal(t, "/email", req.URL.Path)
+	require.Equal(t, "token", req.Header.Get("X-Postmark-Server-Token"))
+	require.Equal(t, msg.From, body["From"])
+	require.Equal(t, "someone@example.com", body["To"])
+	require.Equal(t, "a@example.com,b@example.com", body["Cc"])
+	require.Equal(t, msg.HTML, body["HtmlBody"])
+	require.Equal(t, msg.Text, body["TextBody"])
+}
+
+func TestSendGridMailer(t *testing.T) {
+	// Arrange
+	srv, req, body := captureServer(t, http.StatusAccepted)
+	m, err := mailer.NewSendGridMailer(mailer.APIConfig{BaseURL: srv.URL, Key: "key"})
+	require.NoError(t, err)
+
+	// Act
+	err = m.Send(context.Background(), validMessage())
+
+	// Assert
+	require.NoError(t, err)
+	require.Equal(t, "/v3/mail/send", req.URL.Path)
+	require.Equal(t, "Bearer key", req.Header.Get("Authorization"))
+	require.Equal(t, map[string]any{"email": "hello@example.com", "name": "XYPN"}, body["from"])
+	require.Equal(t, []any{map[string]any{"to": []any{map[string]any{"email": "someone@example.com"}}}}, body["personalizations"])
+	require.Equal(t, []any{
+		map[string]any{"type": "text/plain", "value": "Hi!"},
+		map[string]any{"type": "text/html", "value": "<p>Hi!</p>"},
+	}, body["content"])
+}
+
+func TestAPIMailerRejected(t *testing.T) {
+	// Arrange
+	srv, _, _ := captureServer(t, http.StatusUnprocessableEntity)
+	m, err := mailer.NewPostmarkMailer(mailer.APIConfig{BaseURL: srv.URL, Key: "token"})
+	require.NoError(t, err)
+
+	// Act
+	err = m.Send(context.Background(), validMessage())
+
+	// Assert
+	require.ErrorContains(t, err, "422")
+	require.ErrorContains(t, err, "nope")
+}
+
+func TestAPIMailerBadConfig(t *testing.T) {
+	// Act
+	_, pmErr := mailer.NewPostmarkMailer(mailer.APIConfig{})
+	_, sgErr := mailer.NewSendGridMailer(mailer.APIConfig{})
+
+	// Assert
+	require.ErrorIs(t, pmErr, trails.ErrBadConfig)
+	require.ErrorIs(t, sgErr, trails.ErrBadConfig)
+}
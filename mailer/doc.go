@@ -0,0 +1,26 @@
+/*
+Package mailer sends emails for a trails app.
+
+# Overview
+
+The [Mailer] interface sends a [Message].
+These implementations send through a provider:
+  - [SMTPMailer], through an SMTP server
+  - [PostmarkMailer], through Postmark's API
+  - [SendGridMailer], through SendGrid's API
+  - [SESMailer], through Amazon SES' API
+
+[LogMailer] logs emails instead of sending them, e.g., during development.
+
+# Templates
+
+A [TemplateMailer] wraps another Mailer, rendering HTML bodies from templates
+with the same [template.Parser] rendering pages, so emails share layouts and partials with them.
+It fills in a default sender and logs each email sent.
+
+	err := m.SendTemplate(ctx, mailer.Message{
+		Subject: "Welcome!",
+		To:      []string{user.Email},
+	}, user, "tmpl/email/layout.tmpl", "tmpl/email/welcome.tmpl")
+*/
+package mailer
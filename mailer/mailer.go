@@ -0,0 +1,177 @@
+package mailer
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/mail"
+	"strings"
+
+	"github.com/xy-planning-network/trails"
+	"github.com/xy-planning-network/trails/http/template"
+	"github.com/xy-planning-network/trails/logger"
+)
+
+// A Message is an email.
+type Message struct {
+	// BCC are the addresses blind carbon copied.
+	BCC []string
+
+	// CC are the addresses carbon copied.
+	CC []string
+
+	// From is the address sending the Message, e.g., "XYPN <hello@xyplanningnetwork.com>".
+	From string
+
+	// HTML is the HTML body of the Message.
+	HTML string
+
+	// ReplyTo is the address replies go to, if not From.
+	ReplyTo string
+
+	// Subject is the subject line of the Message.
+	Subject string
+
+	// Text is the plain text body of the Message, shown by clients not displaying HTML.
+	Text string
+
+	// To are the addresses the Message is sent to.
+	To []string
+}
+
+// Valid asserts the Message has a sender, a recipient, a subject, and a body,
+// returning trails.ErrMissingData if not,
+// or trails.ErrNotValid if an address cannot be parsed or a header contains a line break.
+func (msg Message) Valid() error {
+	if msg.From == "" {
+		return fmt.Errorf("%w: Message.From", trails.ErrMissingData)
+	}
+
+	if len(msg.To)+len(msg.CC)+len(msg.BCC) == 0 {
+		return fmt.Errorf("%w: Message.To", trails.ErrMissingData)
+	}
+
+	if msg.Subject == "" {
+		return fmt.Errorf("%w: Message.Subject", trails.ErrMissingData)
+	}
+
+	if msg.HTML == "" && msg.Text == "" {
+		return fmt.Errorf("%w: Message.HTML or Message.Text", trails.ErrMissingData)
+	}
+
+	if strings.ContainsAny(msg.Subject, "\r\n") {
+		return fmt.Errorf("%w: Message.Subject contains a line break", trails.ErrNotValid)
+	}
+
+	addrs := append([]string{msg.From}, msg.recipients()...)
+	if msg.ReplyTo != "" {
+		addrs = append(addrs, msg.ReplyTo)
+	}
+
+	for _, addr := range addrs {
+		if _, err := mail.ParseAddress(addr); err != nil {
+			return fmt.Errorf("%w: address %q: %s", trails.ErrNotValid, addr, err)
+		}
+	}
+
+	return nil
+}
+
+// recipients lists every address the Message is sent to.
+func (msg Message) recipients() []string {
+	rcpts := make([]string, 0, len(msg.To)+len(msg.CC)+len(msg.BCC))
+	rcpts = append(rcpts, msg.To...)
+	rcpts = append(rcpts, msg.CC...)
+	return append(rcpts, msg.BCC...)
+}
+
+// A Mailer sends emails.
+type Mailer interface {
+	// Send sends msg, returning an error if msg is not Valid or could not be sent.
+	Send(ctx context.Context, msg Message) error
+}
+
+// A LogMailer is a Mailer logging emails instead of sending them, e.g., during development.
+type LogMailer struct {
+	l logger.Logger
+}
+
+// NewLogMailer constructs a *LogMailer logging to l.
+func NewLogMailer(l logger.Logger) *LogMailer { return &LogMailer{l: l} }
+
+// Send logs msg at INFO.
+func (m *LogMailer) Send(ctx context.Context, msg Message) error {
+	if err := msg.Valid(); err != nil {
+		return err
+	}
+
+	m.l.Info("not sending email: "+msg.Subject, &logger.LogContext{
+		Context: ctx,
+		Data: map[string]any{
+			"bcc":  msg.BCC,
+			"cc":   msg.CC,
+			"from": msg.From,
+			"html": msg.HTML,
+			"text": msg.Text,
+			"to":   msg.To,
+		},
+	})
+
+	return nil
+}
+
+// A TemplateMailer is a Mailer rendering HTML bodies from templates,
+// filling in a default sender, and logging each email sent by another Mailer.
+type TemplateMailer struct {
+	from string
+	l    logger.Logger
+	m    Mailer
+	p    *template.Parser
+}
+
+// NewTemplateMailer constructs a *TemplateMailer sending with m,
+// parsing templates with p, logging with l, and sending from from
+// when a Message does not set its own sender.
+func NewTemplateMailer(m Mailer, p *template.Parser, l logger.Logger, from string) *TemplateMailer {
+	return &TemplateMailer{from: from, l: l, m: m, p: p}
+}
+
+// Send sends msg, from the default sender if msg.From is empty.
+func (tm *TemplateMailer) Send(ctx context.Context, msg Message) error {
+	if msg.From == "" {
+		msg.From = tm.from
+	}
+
+	data := map[string]any{"recipients": len(msg.recipients()), "subject": msg.Subject}
+	if err := tm.m.Send(ctx, msg); err != nil {
+		tm.l.Error("could not send email: "+err.Error(), &logger.LogContext{Context: ctx, Data: data, Error: err})
+		return err
+	}
+
+	tm.l.Info("sent email", &logger.LogContext{Context: ctx, Data: data})
+
+	return nil
+}
+
+// SendTemplate renders tmpls with data as msg's HTML body, then sends msg.
+// As with rendering pages, list a layout before the template filling it in,
+// e.g., "tmpl/email/layout.tmpl", "tmpl/email/welcome.tmpl".
+func (tm *TemplateMailer) SendTemplate(ctx context.Context, msg Message, data any, tmpls ...string) error {
+	if tm.p == nil {
+		return fmt.Errorf("%w: no template parser", trails.ErrBadConfig)
+	}
+
+	tmpl, err := tm.p.Parse(tmpls...)
+	if err != nil {
+		return fmt.Errorf("could not parse email templates: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return fmt.Errorf("could not render email templates: %w", err)
+	}
+
+	msg.HTML = buf.String()
+
+	return tm.Send(ctx, msg)
+}
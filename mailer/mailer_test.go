@@ -0,0 +1,171 @@
+package mailer_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/fs"
+	"log/slog"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/require"
+	"github.com/xy-planning-network/trails"
+	"github.com/xy-planning-network/trails/http/template"
+	"github.com/xy-planning-network/trails/logger"
+	"github.com/xy-planning-network/trails/mailer"
+)
+
+func validMessage() mailer.Message {
+	return mailer.Message{
+		From:    "XYPN <hello@example.com>",
+		HTML:    "<p>Hi!</p>",
+		Subject: "Hello",
+		Text:    "Hi!",
+		To:      []string{"someone@example.com"},
+	}
+}
+
+func TestMessageValid(t *testing.T) {
+	tcs := []struct {
+		name   string
+		modify func(*mailer.Message)
+		err    error
+	}{
+		{"Valid", func(*mailer.Message) {}, nil},
+		{"Only-BCC", func(m *mailer.Message) { m.BCC, m.To = m.To, nil }, nil},
+		{"Only-Text", func(m *mailer.Message) { m.HTML = "" }, nil},
+		{"No-From", func(m *mailer.Message) { m.From = "" }, trails.ErrMissingData},
+		{"No-Recipients", func(m *mailer.Message) { m.To = nil }, trails.ErrMissingData},
+		{"No-Subject", func(m *mailer.Message) { m.Subject = "" }, trails.ErrMissingData},
+		{"No-Body", func(m *mailer.Message) { m.HTML, m.Text = "", "" }, trails.ErrMissingData},
+		{"Subject-Line-Break", func(m *mailer.Message) { m.Subject = "Hello\r\nBcc: x@example.com" }, trails.ErrNotValid},
+		{"Bad-To", func(m *mailer.Message) { m.To = []string{"not an address"} }, trails.ErrNotValid},
+		{"Bad-Reply-To", func(m *mailer.Message) { m.ReplyTo = "@" }, trails.ErrNotValid},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			msg := validMessage()
+			tc.modify(&msg)
+
+			// Act
+			err := msg.Valid()
+
+			// Assert
+			if tc.err == nil {
+				require.NoError(t, err)
+				return
+			}
+
+			require.ErrorIs(t, err, tc.err)
+		})
+	}
+}
+
+func TestLogMailer(t *testing.T) {
+	// Arrange
+	b := new(bytes.Buffer)
+	l := logger.New(slog.New(slog.NewTextHandler(b, nil)), trails.Testing)
+	m := mailer.NewLogMailer(l)
+
+	// Act
+	err := m.Send(context.Background(), validMessage())
+
+	// Assert
+	require.NoError(t, err)
+	require.Contains(t, b.String(), "not sending email: Hello")
+	require.Contains(t, b.String(), "someone@example.com")
+
+	// Act
+	err = m.Send(context.Background(), mailer.Message{})
+
+	// Assert
+	require.ErrorIs(t, err, trails.ErrMissingData)
+}
+
+// recordingMailer is a mailer.Mailer recording the messages it sends.
+type recordingMailer struct {
+	err  error
+	sent []mailer.Message
+}
+
+func (m *recordingMailer) Send(_ context.Context, msg mailer.Message) error {
+	if m.err != nil {
+		return m.err
+	}
+
+	m.sent = append(m.sent, msg)
+
+	return nil
+}
+
+func TestTemplateMailerSend(t *testing.T) {
+	// Arrange
+	b := new(bytes.Buffer)
+	l := logger.New(slog.New(slog.NewTextHandler(b, nil)), trails.Testing)
+	rec := new(recordingMailer)
+	m := mailer.NewTemplateMailer(rec, nil, l, "default@example.com")
+
+	msg := validMessage()
+	msg.From = ""
+
+	// Act
+	err := m.Send(context.Background(), msg)
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, rec.sent, 1)
+	require.Equal(t, "default@example.com", rec.sent[0].From)
+	require.Contains(t, b.String(), "sent email")
+
+	// Arrange
+	b.Reset()
+	rec.err = errors.New("boom")
+
+	// Act
+	err = m.Send(context.Background(), validMessage())
+
+	// Assert
+	require.ErrorIs(t, err, rec.err)
+	require.Contains(t, b.String(), "could not send email: boom")
+}
+
+func TestTemplateMailerSendTemplate(t *testing.T) {
+	// Arrange
+	l := logger.New(slog.New(slog.NewTextHandler(new(bytes.Buffer), nil)), trails.Testing)
+	fsys := fstest.MapFS{
+		"tmpl/email/layout.tmpl":  {Data: []byte(`<html>{{ template "content" . }}</html>`)},
+		"tmpl/email/welcome.tmpl": {Data: []byte(`{{ define "content" }}<p>Welcome, {{ .Name }}!</p>{{ end }}`)},
+	}
+	rec := new(recordingMailer)
+	m := mailer.NewTemplateMailer(rec, template.NewParser([]fs.FS{fsys}), l, "default@example.com")
+
+	msg := validMessage()
+	msg.HTML = ""
+
+	// Act
+	err := m.SendTemplate(context.Background(), msg, map[string]string{"Name": "Ada"}, "tmpl/email/layout.tmpl", "tmpl/email/welcome.tmpl")
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, rec.sent, 1)
+	require.Equal(t, "<html><p>Welcome, Ada!</p></html>", rec.sent[0].HTML)
+
+	// Act
+	err = m.SendTemplate(context.Background(), msg, nil, "tmpl/email/missing.tmpl")
+
+	// Assert
+	require.Error(t, err)
+	require.Len(t, rec.sent, 1)
+
+	// Arrange
+	m = mailer.NewTemplateMailer(rec, nil, l, "default@example.com")
+
+	// Act
+	err = m.SendTemplate(context.Background(), msg, nil, "tmpl/email/layout.tmpl")
+
+	// Assert
+	require.ErrorIs(t, err, trails.ErrBadConfig)
+}
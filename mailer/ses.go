@@ -0,0 +1,182 @@
+package mailer
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/xy-planning-network/trails"
+)
+
+// sesPath is the path of SES's SendEmail API.
+const sesPath = "/v2/email/outbound-emails"
+
+// SESConfig configures an SESMailer.
+type SESConfig struct {
+	// AccessKeyID identifies the AWS credentials.
+	AccessKeyID string
+
+	// BaseURL is the SES API, e.g., for pointing at a local stand-in.
+	//
+	// If BaseURL is empty, "https://email.<Region>.amazonaws.com" is used.
+	BaseURL string
+
+	// Client sends requests to BaseURL.
+	//
+	// If Client is nil, a client timing out after 10 seconds is used.
+	Client *http.Client
+
+	// Region is the AWS region SES sends from, e.g., "us-east-1".
+	Region string
+
+	// SecretAccessKey signs requests.
+	SecretAccessKey string
+
+	// SessionToken accompanies temporary AWS credentials, if any.
+	SessionToken string
+}
+
+// An SESMailer is a Mailer sending emails through Amazon SES' v2 API,
+// signing requests with AWS Signature Version 4;
+// cf. https://docs.aws.amazon.com/ses/latest/APIReference-V2/API_SendEmail.html.
+type SESMailer struct {
+	cfg SESConfig
+}
+
+// NewSESMailer constructs an *SESMailer from cfg,
+// returning trails.ErrBadConfig if cfg is missing credentials or a region.
+func NewSESMailer(cfg SESConfig) (*SESMailer, error) {
+	if cfg.AccessKeyID == "" || cfg.SecretAccessKey == "" {
+		return nil, fmt.Errorf("%w: missing AWS credentials", trails.ErrBadConfig)
+	}
+
+	if cfg.Region == "" {
+		return nil, fmt.Errorf("%w: missing AWS region", trails.ErrBadConfig)
+	}
+
+	if cfg.BaseURL == "" {
+		cfg.BaseURL = fmt.Sprintf("https://email.%s.amazonaws.com", cfg.Region)
+	}
+	cfg.BaseURL = strings.TrimSuffix(cfg.BaseURL, "/")
+
+	if cfg.Client == nil {
+		cfg.Client = &http.Client{Timeout: apiTimeout}
+	}
+
+	return &SESMailer{cfg: cfg}, nil
+}
+
+// Send sends msg through SES.
+func (m *SESMailer) Send(ctx context.Context, msg Message) error {
+	if err := msg.Valid(); err != nil {
+		return err
+	}
+
+	content := func(s string) map[string]string { return map[string]string{"Charset": "UTF-8", "Data": s} }
+	body := map[string]any{}
+	if msg.HTML != "" {
+		body["Html"] = content(msg.HTML)
+	}
+	if msg.Text != "" {
+		body["Text"] = content(msg.Text)
+	}
+
+	dest := make(map[string][]string)
+	for k, list := range map[string][]string{"BccAddresses": msg.BCC, "CcAddresses": msg.CC, "ToAddresses": msg.To} {
+		if len(list) > 0 {
+			dest[k] = list
+		}
+	}
+
+	payload := map[string]any{
+		"Content": map[string]any{
+			"Simple": map[string]any{"Body": body, "Subject": content(msg.Subject)},
+		},
+		"Destination":      dest,
+		"FromEmailAddress": msg.From,
+	}
+	if msg.ReplyTo != "" {
+		payload["ReplyToAddresses"] = []string{msg.ReplyTo}
+	}
+
+	b, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("could not encode email: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, m.cfg.BaseURL+sesPath, bytes.NewReader(b))
+	if err != nil {
+		return fmt.Errorf("could not build email request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	m.sign(req, b, time.Now().UTC())
+
+	return do(m.cfg.Client, req)
+}
+
+// sign adds AWS Signature Version 4 headers authenticating req, whose body is payload, at now;
+// cf. https://docs.aws.amazon.com/IAM/latest/UserGuide/create-signed-request.html.
+func (m *SESMailer) sign(req *http.Request, payload []byte, now time.Time) {
+	const service = "ses"
+
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+
+	req.Header.Set("Host", req.URL.Host)
+	req.Header.Set("X-Amz-Date", amzDate)
+	if m.cfg.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", m.cfg.SessionToken)
+	}
+
+	names := make([]string, 0, len(req.Header))
+	for name := range req.Header {
+		names = append(names, strings.ToLower(name))
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + strings.TrimSpace(req.Header.Get(name)) + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	payloadHash := sha256.Sum256(payload)
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		canonicalHeaders.String(),
+		signedHeaders,
+		hex.EncodeToString(payloadHash[:]),
+	}, "\n")
+
+	scope := strings.Join([]string{date, m.cfg.Region, service, "aws4_request"}, "/")
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := strings.Join([]string{"AWS4-HMAC-SHA256", amzDate, scope, hex.EncodeToString(requestHash[:])}, "\n")
+
+	key := []byte("AWS4" + m.cfg.SecretAccessKey)
+	for _, part := range []string{date, m.cfg.Region, service, "aws4_request"} {
+		key = hmacSHA256(key, part)
+	}
+
+	req.Header.Set("Authorization", fmt.Sprintf(
+		"AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		m.cfg.AccessKeyID, scope, signedHeaders, hex.EncodeToString(hmacSHA256(key, stringToSign)),
+	))
+}
+
+// hmacSHA256 computes the HMAC-SHA256 of data with key.
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
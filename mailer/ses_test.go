@@ -0,0 +1,52 @@
+package mailer_test
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/xy-planning-network/trails"
+	"github.com/xy-planning-network/trails/mailer"
+)
+
+func TestSESMailer(t *testing.T) {
+	// Arrange
+	srv, req, body := captureServer(t, http.StatusOK)
+	m, err := mailer.NewSESMailer(mailer.SESConfig{
+		AccessKeyID:     "AKID",
+		BaseURL:         srv.URL,
+		Region:          "us-east-1",
+		SecretAccessKey: "secret",
+		SessionToken:    "session",
+	})
+	require.NoError(t, err)
+
+	// Act
+	err = m.Send(context.Background(), validMessage())
+
+	// Assert
+	require.NoError(t, err)
+	require.Equal(t, "/v2/email/outbound-emails", req.URL.Path)
+	require.Equal(t, "session", req.Header.Get("X-Amz-Security-Token"))
+	require.NotEmpty(t, req.Header.Get("X-Amz-Date"))
+
+	auth := req.Header.Get("Authorization")
+	require.True(t, strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential=AKID/"), auth)
+	require.Contains(t, auth, "/us-east-1/ses/aws4_request")
+	require.Contains(t, auth, "SignedHeaders=content-type;host;x-amz-date;x-amz-security-token")
+
+	require.Equal(t, "XYPN <hello@example.com>", body["FromEmailAddress"])
+	require.Equal(t, map[string]any{"ToAddresses": []any{"someone@example.com"}}, body["Destination"])
+}
+
+func TestNewSESMailerBadConfig(t *testing.T) {
+	// Act
+	_, credsErr := mailer.NewSESMailer(mailer.SESConfig{Region: "us-east-1"})
+	_, regionErr := mailer.NewSESMailer(mailer.SESConfig{AccessKeyID: "AKID", SecretAccessKey: "secret"})
+
+	// Assert
+	require.ErrorIs(t, credsErr, trails.ErrBadConfig)
+	require.ErrorIs(t, regionErr, trails.ErrBadConfig)
+}
@@ -0,0 +1,240 @@
+package mailer
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/xy-planning-network/trails"
+)
+
+const (
+	// DefaultSMTPPort is the port an SMTPMailer connects on by default, for submitting email with STARTTLS.
+	DefaultSMTPPort = "587"
+
+	// smtpsPort is the port SMTP servers accept connections over implicit TLS on.
+	smtpsPort = "465"
+
+	// smtpTimeout bounds sending an email when the context.Context has no deadline.
+	smtpTimeout = 30 * time.Second
+)
+
+// SMTPConfig configures an SMTPMailer.
+type SMTPConfig struct {
+	// Host is the SMTP server's host.
+	Host string
+
+	// Password authenticates Username.
+	Password string
+
+	// Port is the SMTP server's port.
+	// Port 465 connects over TLS; others upgrade to TLS with STARTTLS, if the server supports it.
+	//
+	// If Port is empty, DefaultSMTPPort is used.
+	Port string
+
+	// Username authenticates with the SMTP server, if set.
+	Username string
+}
+
+// An SMTPMailer is a Mailer sending emails through an SMTP server.
+type SMTPMailer struct {
+	cfg SMTPConfig
+}
+
+// NewSMTPMailer constructs an *SMTPMailer from cfg,
+// returning trails.ErrBadConfig if cfg.Host is empty.
+func NewSMTPMailer(cfg SMTPConfig) (*SMTPMailer, error) {
+	if cfg.Host == "" {
+		return nil, fmt.Errorf("%w: missing SMTP host", trails.ErrBadConfig)
+	}
+
+	if cfg.Port == "" {
+		cfg.Port = DefaultSMTPPort
+	}
+
+	return &SMTPMailer{cfg: cfg}, nil
+}
+
+// Send sends msg through the SMTP server.
+func (m *SMTPMailer) Send(ctx context.Context, msg Message) error {
+	if err := msg.Valid(); err != nil {
+		return err
+	}
+
+	body, err := msg.mime()
+	if err != nil {
+		return err
+	}
+
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, smtpTimeout)
+		defer cancel()
+	}
+
+	addr := net.JoinHostPort(m.cfg.Host, m.cfg.Port)
+	tlsCfg := &tls.Config{ServerName: m.cfg.Host}
+
+	var conn net.Conn
+	if m.cfg.Port == smtpsPort {
+		conn, err = (&tls.Dialer{Config: tlsCfg}).DialContext(ctx, "tcp", addr)
+	} else {
+		conn, err = new(net.Dialer).DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return fmt.Errorf("could not connect to SMTP server: %w", err)
+	}
+
+	deadline, _ := ctx.Deadline()
+	conn.SetDeadline(deadline)
+
+	c, err := smtp.NewClient(conn, m.cfg.Host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("could not greet SMTP server: %w", err)
+	}
+	defer c.Close()
+
+	if ok, _ := c.Extension("STARTTLS"); ok && m.cfg.Port != smtpsPort {
+		if err := c.StartTLS(tlsCfg); err != nil {
+			return fmt.Errorf("could not start TLS with SMTP server: %w", err)
+		}
+	}
+
+	if m.cfg.Username != "" {
+		if err := c.Auth(smtp.PlainAuth("", m.cfg.Username, m.cfg.Password, m.cfg.Host)); err != nil {
+			return fmt.Errorf("could not authenticate with SMTP server: %w", err)
+		}
+	}
+
+	from, _ := mail.ParseAddress(msg.From)
+	if err := c.Mail(from.Address); err != nil {
+		return fmt.Errorf("could not send email: %w", err)
+	}
+
+	for _, rcpt := range msg.recipients() {
+		to, _ := mail.ParseAddress(rcpt)
+		if err := c.Rcpt(to.Address); err != nil {
+			return fmt.Errorf("could not send email to %s: %w", to.Address, err)
+		}
+	}
+
+	w, err := c.Data()
+	if err != nil {
+		return fmt.Errorf("could not send email: %w", err)
+	}
+
+	if _, err := w.Write(body); err != nil {
+		return fmt.Errorf("could not send email: %w", err)
+	}
+
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("could not send email: %w", err)
+	}
+
+	return c.Quit()
+}
+
+// mime encodes the Message as a MIME message,
+// with alternative plain text and HTML parts if it has both.
+// BCC is left out, so recipients do not see those blind carbon copied.
+func (msg Message) mime() ([]byte, error) {
+	var buf bytes.Buffer
+
+	from, _ := mail.ParseAddress(msg.From)
+	domain := from.Address[strings.LastIndex(from.Address, "@")+1:]
+
+	header := func(k, v string) { fmt.Fprintf(&buf, "%s: %s\r\n", k, v) }
+	header("From", from.String())
+	if len(msg.To) > 0 {
+		header("To", formatAddresses(msg.To))
+	}
+	if len(msg.CC) > 0 {
+		header("Cc", formatAddresses(msg.CC))
+	}
+	if msg.ReplyTo != "" {
+		header("Reply-To", formatAddresses([]string{msg.ReplyTo}))
+	}
+	header("Subject", mime.QEncoding.Encode("utf-8", msg.Subject))
+	header("Date", time.Now().Format(time.RFC1123Z))
+	header("Message-ID", fmt.Sprintf("<%s@%s>", uuid.NewString(), domain))
+	header("MIME-Version", "1.0")
+
+	if msg.HTML == "" || msg.Text == "" {
+		contentType, body := "text/plain", msg.Text
+		if msg.HTML != "" {
+			contentType, body = "text/html", msg.HTML
+		}
+
+		header("Content-Type", contentType+"; charset=UTF-8")
+		header("Content-Transfer-Encoding", "quoted-printable")
+		buf.WriteString("\r\n")
+
+		if err := writeQuotedPrintable(&buf, body); err != nil {
+			return nil, err
+		}
+
+		return buf.Bytes(), nil
+	}
+
+	mw := multipart.NewWriter(&buf)
+	header("Content-Type", "multipart/alternative; boundary="+mw.Boundary())
+	buf.WriteString("\r\n")
+
+	for _, part := range []struct{ contentType, body string }{
+		{"text/plain", msg.Text},
+		{"text/html", msg.HTML},
+	} {
+		pw, err := mw.CreatePart(textproto.MIMEHeader{
+			"Content-Transfer-Encoding": {"quoted-printable"},
+			"Content-Type":              {part.contentType + "; charset=UTF-8"},
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		if err := writeQuotedPrintable(pw, part.body); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := mw.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// formatAddresses formats addrs for a header, encoding names as RFC 2047 requires.
+func formatAddresses(addrs []string) string {
+	formatted := make([]string, len(addrs))
+	for i, addr := range addrs {
+		a, _ := mail.ParseAddress(addr)
+		formatted[i] = a.String()
+	}
+
+	return strings.Join(formatted, ", ")
+}
+
+// writeQuotedPrintable writes s to w, quoted-printable encoded.
+func writeQuotedPrintable(w io.Writer, s string) error {
+	qp := quotedprintable.NewWriter(w)
+	if _, err := qp.Write([]byte(s)); err != nil {
+		return err
+	}
+
+	return qp.Close()
+}
@@ -0,0 +1,123 @@
+package mailer_test
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"net/textproto"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/xy-planning-network/trails"
+	"github.com/xy-planning-network/trails/mailer"
+)
+
+// fakeSMTPServer accepts one SMTP session, sending the commands and message data it receives on the returned channels.
+func fakeSMTPServer(t *testing.T) (string, <-chan []string, <-chan string) {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { ln.Close() })
+
+	cmds := make(chan []string, 1)
+	data := make(chan string, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		tp := textproto.NewConn(conn)
+		tp.PrintfLine("220 localhost ESMTP")
+
+		var (
+			received []string
+			body     string
+		)
+		defer func() {
+			cmds <- received
+			data <- body
+		}()
+
+		for {
+			line, err := tp.ReadLine()
+			if err != nil {
+				return
+			}
+			received = append(received, line)
+
+			verb := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+			switch verb {
+			case "EHLO":
+				tp.PrintfLine("250-localhost")
+				tp.PrintfLine("250 AUTH PLAIN")
+			case "AUTH":
+				tp.PrintfLine("235 authenticated")
+			case "DATA":
+				tp.PrintfLine("354 go ahead")
+				b, err := tp.ReadDotBytes()
+				if err != nil {
+					return
+				}
+				body = string(b)
+				tp.PrintfLine("250 queued")
+			case "QUIT":
+				tp.PrintfLine("221 bye")
+				return
+			default:
+				tp.PrintfLine("250 ok")
+			}
+		}
+	}()
+
+	return ln.Addr().String(), cmds, data
+}
+
+func TestSMTPMailer(t *testing.T) {
+	// Arrange
+	addr, cmds, data := fakeSMTPServer(t)
+	host, port, err := net.SplitHostPort(addr)
+	require.NoError(t, err)
+
+	m, err := mailer.NewSMTPMailer(mailer.SMTPConfig{Host: host, Password: "pass", Port: port, Username: "user"})
+	require.NoError(t, err)
+
+	msg := validMessage()
+	msg.BCC = []string{"hidden@example.com"}
+
+	// Act
+	err = m.Send(context.Background(), msg)
+
+	// Assert
+	require.NoError(t, err)
+
+	received := strings.Join(<-cmds, "\n")
+	require.Contains(t, received, "AUTH PLAIN")
+	require.Contains(t, received, "MAIL FROM:<hello@example.com>")
+	require.Contains(t, received, "RCPT TO:<someone@example.com>")
+	require.Contains(t, received, "RCPT TO:<hidden@example.com>")
+
+	body := <-data
+	require.Contains(t, body, `From: "XYPN" <hello@example.com>`)
+	require.Contains(t, body, "To: <someone@example.com>")
+	require.Contains(t, body, "Subject: Hello")
+	require.Contains(t, body, "Content-Type: multipart/alternative")
+	require.Contains(t, body, "<p>Hi!</p>")
+	require.NotContains(t, body, "hidden@example.com")
+
+	r := textproto.NewReader(bufio.NewReader(strings.NewReader(body)))
+	header, err := r.ReadMIMEHeader()
+	require.NoError(t, err)
+	require.Equal(t, "1.0", header.Get("MIME-Version"))
+}
+
+func TestNewSMTPMailerBadConfig(t *testing.T) {
+	// Act
+	_, err := mailer.NewSMTPMailer(mailer.SMTPConfig{})
+
+	// Assert
+	require.ErrorIs(t, err, trails.ErrBadConfig)
+}
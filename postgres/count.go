@@ -0,0 +1,79 @@
+package postgres
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/xy-planning-network/trails"
+	"gorm.io/gorm"
+)
+
+// CountDistinct counts the distinct, non-NULL values of column among the rows tx selects:
+//
+//	n, err := postgres.CountDistinct(db.Model(&Login{}).Where("created_at > ?", since), "user_id")
+func CountDistinct(tx *gorm.DB, column string) (int64, error) {
+	var n int64
+	return n, tx.Distinct(column).Count(&n).Error
+}
+
+// EstimatedCount estimates the number of rows in the table of tx's model
+// from the statistics Postgres keeps on it, i.e., pg_class.reltuples,
+// for tables so large COUNT(*) takes seconds:
+//
+//	n, err := postgres.EstimatedCount(db.Model(&Event{}))
+//
+// The estimate is as fresh as the last VACUUM or ANALYZE of the table
+// and includes soft-deleted rows.
+// If Postgres has no statistics on the table yet, EstimatedCount counts its rows exactly.
+//
+// Statistics cover whole tables, so if tx has conditions, EstimatedCount returns trails.ErrNotValid.
+// If tx has no model or table, EstimatedCount returns trails.ErrMissingData;
+// if the table does not exist, trails.ErrNotExist.
+func EstimatedCount(tx *gorm.DB) (int64, error) {
+	table, err := countedTable(tx)
+	if err != nil {
+		return 0, err
+	}
+
+	if _, ok := tx.Statement.Clauses["WHERE"]; ok {
+		return 0, fmt.Errorf("%w: cannot estimate the count of %s with conditions", trails.ErrNotValid, table)
+	}
+
+	var reltuples sql.NullFloat64
+	err = tx.Session(&gorm.Session{NewDB: true}).
+		Raw("SELECT reltuples FROM pg_class WHERE oid = to_regclass(?)", table).
+		Scan(&reltuples).Error
+	if err != nil {
+		return 0, err
+	}
+
+	if !reltuples.Valid {
+		return 0, fmt.Errorf("%w: table %s", trails.ErrNotExist, table)
+	}
+
+	// NOTE: reltuples is -1 for a table never vacuumed nor analyzed.
+	if reltuples.Float64 < 0 {
+		var n int64
+		return n, tx.Count(&n).Error
+	}
+
+	return int64(reltuples.Float64), nil
+}
+
+// countedTable names the table of tx's model.
+func countedTable(tx *gorm.DB) (string, error) {
+	stmt := tx.Statement
+	if stmt.Table != "" {
+		return stmt.Table, nil
+	}
+
+	if stmt.Model == nil {
+		return "", fmt.Errorf("%w: no model or table to count", trails.ErrMissingData)
+	}
+
+	if err := stmt.Parse(stmt.Model); err != nil {
+		return "", fmt.Errorf("%w: cannot count %T: %s", trails.ErrNotValid, stmt.Model, err)
+	}
+
+	return stmt.Table, nil
+}
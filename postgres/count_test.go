@@ -0,0 +1,104 @@
+package postgres_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/xy-planning-network/trails"
+	"github.com/xy-planning-network/trails/postgres"
+	pg "gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+type login struct {
+	ID     uint
+	UserID uint
+}
+
+func TestCountDistinct(t *testing.T) {
+	// Arrange
+	l := &sqlLogger{}
+	db, err := gorm.Open(pg.Open("host=localhost"), &gorm.Config{DisableAutomaticPing: true, DryRun: true, Logger: l})
+	require.NoError(t, err)
+
+	// Act
+	_, err = postgres.CountDistinct(db.Model(&login{}).Where("id > ?", 1), "user_id")
+
+	// Assert
+	require.NoError(t, err)
+	require.Equal(t, []string{`SELECT COUNT(DISTINCT("user_id")) FROM "logins" WHERE id > 1`}, l.sql)
+}
+
+func TestEstimatedCount(t *testing.T) {
+	// Arrange
+	l := &sqlLogger{}
+	db, err := gorm.Open(pg.Open("host=localhost"), &gorm.Config{DisableAutomaticPing: true, DryRun: true, Logger: l})
+	require.NoError(t, err)
+
+	// Act
+	_, err = postgres.EstimatedCount(db.Model(&login{}))
+
+	// Assert
+	// NOTE: a dry run cannot scan what it never queried.
+	require.ErrorIs(t, err, gorm.ErrDryRunModeUnsupported)
+	require.Equal(t, []string{`SELECT reltuples FROM pg_class WHERE oid = to_regclass('logins')`}, l.sql)
+
+	// Act
+	_, err = postgres.EstimatedCount(db.Model(&login{}).Where("user_id = ?", 1))
+
+	// Assert
+	require.ErrorIs(t, err, trails.ErrNotValid)
+
+	// Act
+	_, err = postgres.EstimatedCount(db)
+
+	// Assert
+	require.ErrorIs(t, err, trails.ErrMissingData)
+}
+
+func TestPagedByQueryEstimateCountsOver(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		query    string
+		params   []any
+		expected []string
+	}{
+		{
+			"Unfiltered",
+			"",
+			nil,
+			[]string{
+				`SELECT reltuples FROM pg_class WHERE oid = to_regclass('logins')`,
+				`SELECT count(*) FROM "logins"`,
+				`SELECT * FROM "logins" ORDER BY id LIMIT 10`,
+			},
+		},
+		{
+			"Filtered",
+			"user_id = ?",
+			[]any{1},
+			[]string{
+				`SELECT count(*) FROM "logins" WHERE user_id = 1`,
+				`SELECT * FROM "logins" WHERE user_id = 1 ORDER BY id LIMIT 10`,
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			l := &sqlLogger{}
+			db, err := gorm.Open(pg.Open("host=localhost"), &gorm.Config{DisableAutomaticPing: true, DryRun: true, Logger: l})
+			require.NoError(t, err)
+
+			service := postgres.NewService(db)
+			service.EstimateCountsOver = 1_000_000
+
+			// Act
+			pd, err := service.PagedByQuery(new([]login), tc.query, tc.params, "id", 1, 10)
+
+			// Assert
+			require.NoError(t, err)
+			require.False(t, pd.Estimated)
+			require.Equal(t, tc.expected, l.sql)
+		})
+	}
+}
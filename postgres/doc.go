@@ -5,5 +5,37 @@ considered as well. In this scenario, we are dropping the public schema.
 
 There is a very basic set of getter methods that have been implemented as well. An interface has been provided such that
 it can be mocked out for testing that does not need an actual database running in the environment.
+
+WhereNull, WhereNotNull, and WhereAny build common predicates consistently, composing with *gorm.DB's Where and Or.
+
+A ColumnValidator checks the maps models are created or updated with against their columns,
+catching typos before they reach the database.
+
+Logged queries mask the values of CxnConfig.SensitiveColumns and of parameters marked Sensitive,
+so passwords, tokens, and the like never appear in logs.
+
+A StmtCache bounds how many statements a *gorm.DB preparing them keeps,
+so queries Postgres parses and plans once per connection do not pile up without end.
+
+A Collector records Prometheus metrics about the statements and transactions a *gorm.DB executes
+and the connections it pools.
+
+CountDistinct counts the distinct values of a column.
+For tables so large COUNT(*) takes seconds, EstimatedCount estimates their rows from Postgres' statistics;
+DatabaseServiceImpl.EstimateCountsOver has paging through such tables use the estimate, too.
+
+Paging through rows Postgres orders equally, or not at all, can put a row on two pages or none.
+DatabaseServiceImpl.TiebreakOrder orders pages by the primary key last,
+and DatabaseServiceImpl.RequireOrder refuses to page without an order.
+
+For full-text search, AddSearchColumn migrates a table to have an indexed tsvector column,
+which WhereSearch searches and OrderByRank orders by relevance.
+
+For locations, Point reads and writes point columns,
+which WhereWithinRadius filters and OrderByDistance orders by distance using the earthdistance extension.
+
+For reporting, a View's methods are Migration Executors creating, replacing, and dropping views and materialized views,
+which DatabaseServiceImpl.RefreshMaterializedView recomputes.
+AddGeneratedColumn migrates a table to have a column computed from others.
 */
 package postgres
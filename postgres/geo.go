@@ -0,0 +1,73 @@
+package postgres
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"strconv"
+
+	"github.com/xy-planning-network/trails"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// metersPerMile converts the statute miles earthdistance's <@> operator measures in to meters.
+const metersPerMile = 1609.344
+
+// A Point is a location stored in a Postgres point column as (longitude, latitude),
+// as earthdistance's <@> operator expects.
+type Point struct {
+	Lat float64
+	Lng float64
+}
+
+// Scan scans a point column into p.
+//
+// Scan returns trails.ErrNotValid if src is not a point.
+func (p *Point) Scan(src any) error {
+	var s string
+	switch v := src.(type) {
+	case string:
+		s = v
+	case []byte:
+		s = string(v)
+	default:
+		return fmt.Errorf("%w: cannot scan %T into Point", trails.ErrNotValid, src)
+	}
+
+	if _, err := fmt.Sscanf(s, "(%g,%g)", &p.Lng, &p.Lat); err != nil {
+		return fmt.Errorf("%w: cannot scan %q into Point: %s", trails.ErrNotValid, s, err)
+	}
+
+	return nil
+}
+
+// Value writes p to a point column.
+func (p Point) Value() (driver.Value, error) {
+	return "(" + strconv.FormatFloat(p.Lng, 'f', -1, 64) + "," + strconv.FormatFloat(p.Lat, 'f', -1, 64) + ")", nil
+}
+
+// WhereWithinRadius matches rows whose point column is within meters of lat and lng.
+//
+// WhereWithinRadius and OrderByDistance use the earthdistance extension's <@> operator,
+// requiring a migration to run:
+//
+//	CREATE EXTENSION IF NOT EXISTS cube;
+//	CREATE EXTENSION IF NOT EXISTS earthdistance;
+func WhereWithinRadius(column string, lat, lng, meters float64) clause.Expression {
+	return clause.Expr{
+		SQL:  "? <@> point(?, ?) <= ?",
+		Vars: []any{clause.Column{Name: column}, lng, lat, meters / metersPerMile},
+	}
+}
+
+// OrderByDistance orders rows by how far their point column is from lat and lng, nearest first.
+// Use it with *gorm.DB's Scopes.
+func OrderByDistance(column string, lat, lng float64) func(*gorm.DB) *gorm.DB {
+	return func(tx *gorm.DB) *gorm.DB {
+		return tx.Clauses(clause.OrderBy{Expression: clause.Expr{
+			SQL:                "? <@> point(?, ?)",
+			Vars:               []any{clause.Column{Name: column}, lng, lat},
+			WithoutParentheses: true,
+		}})
+	}
+}
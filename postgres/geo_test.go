@@ -0,0 +1,63 @@
+package postgres_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/xy-planning-network/trails"
+	"github.com/xy-planning-network/trails/postgres"
+	pg "gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+func TestPoint(t *testing.T) {
+	// Arrange
+	p := postgres.Point{Lat: 41.8781, Lng: -87.6298}
+
+	// Act
+	v, err := p.Value()
+
+	// Assert
+	require.NoError(t, err)
+	require.Equal(t, "(-87.6298,41.8781)", v)
+
+	// Arrange
+	var actual postgres.Point
+
+	// Act
+	err = actual.Scan([]byte("(-87.6298,41.8781)"))
+
+	// Assert
+	require.NoError(t, err)
+	require.Equal(t, p, actual)
+
+	// Act
+	err = actual.Scan(42)
+
+	// Assert
+	require.ErrorIs(t, err, trails.ErrNotValid)
+
+	// Act
+	err = actual.Scan("nowhere")
+
+	// Assert
+	require.ErrorIs(t, err, trails.ErrNotValid)
+}
+
+func TestGeo(t *testing.T) {
+	// Arrange
+	db, err := gorm.Open(pg.Open("host=localhost"), &gorm.Config{DisableAutomaticPing: true, DryRun: true})
+	require.NoError(t, err)
+
+	// Act
+	actual := db.ToSQL(func(tx *gorm.DB) *gorm.DB {
+		var ws []widget
+		return tx.
+			Where(postgres.WhereWithinRadius("coordinates", 41.8781, -87.6298, 1609.344)).
+			Scopes(postgres.OrderByDistance("coordinates", 41.8781, -87.6298)).
+			Find(&ws)
+	})
+
+	// Assert
+	require.Equal(t, `SELECT * FROM "widgets" WHERE "coordinates" <@> point(-87.6298, 41.8781) <= 1 ORDER BY "coordinates" <@> point(-87.6298, 41.8781)`, actual)
+}
@@ -0,0 +1,157 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"path"
+	"runtime"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// A slogLogger implements [logger.Interface], logging with a [*slog.Logger]
+// so the context.Context a query runs with - e.g., *gorm.DB.WithContext(r.Context()) -
+// contributes attributes to the log, such as request IDs;
+// cf. [github.com/xy-planning-network/trails/logger.ContextHandler].
+//
+// A slogLogger logs queries from where the application ran them, not from inside gorm or package postgres,
+// and includes that call site under "caller".
+type slogLogger struct {
+	cfg logger.Config
+	l   *slog.Logger
+}
+
+// newSlogLogger constructs a [logger.Interface] configured by cfg, logging with l.
+// If l is nil, [slog.Default] is used.
+// cfg.Colorful is ignored.
+func newSlogLogger(cfg logger.Config, l *slog.Logger) logger.Interface {
+	return slogLogger{cfg: cfg, l: l}
+}
+
+// logger is the *slog.Logger l logs with.
+func (l slogLogger) logger() *slog.Logger {
+	if l.l == nil {
+		return slog.Default()
+	}
+
+	return l.l
+}
+
+// LogMode sets the level queries are logged at.
+func (l slogLogger) LogMode(level logger.LogLevel) logger.Interface {
+	l.cfg.LogLevel = level
+	return l
+}
+
+// Info logs an informational message.
+func (l slogLogger) Info(ctx context.Context, msg string, data ...any) {
+	if l.cfg.LogLevel >= logger.Info {
+		l.logger().InfoContext(ctx, fmt.Sprintf(msg, data...))
+	}
+}
+
+// Warn logs a warning message.
+func (l slogLogger) Warn(ctx context.Context, msg string, data ...any) {
+	if l.cfg.LogLevel >= logger.Warn {
+		l.logger().WarnContext(ctx, fmt.Sprintf(msg, data...))
+	}
+}
+
+// Error logs an error message.
+func (l slogLogger) Error(ctx context.Context, msg string, data ...any) {
+	if l.cfg.LogLevel >= logger.Error {
+		l.logger().ErrorContext(ctx, fmt.Sprintf(msg, data...))
+	}
+}
+
+// Trace logs the query fc describes when it fails, is slow, or the level is logger.Info.
+func (l slogLogger) Trace(ctx context.Context, begin time.Time, fc func() (sql string, rowsAffected int64), err error) {
+	if l.cfg.LogLevel <= logger.Silent {
+		return
+	}
+
+	elapsed := time.Since(begin)
+	switch {
+	case err != nil && l.cfg.LogLevel >= logger.Error &&
+		(!errors.Is(err, gorm.ErrRecordNotFound) || !l.cfg.IgnoreRecordNotFoundError):
+		sql, rows := fc()
+		logQuery(ctx, l.logger(), slog.LevelError, "query failed", queryAttrs(sql, rows, elapsed, err))
+
+	case l.cfg.SlowThreshold != 0 && elapsed > l.cfg.SlowThreshold && l.cfg.LogLevel >= logger.Warn:
+		sql, rows := fc()
+		logQuery(ctx, l.logger(), slog.LevelWarn, "slow query", queryAttrs(sql, rows, elapsed, nil))
+
+	case l.cfg.LogLevel == logger.Info:
+		sql, rows := fc()
+		logQuery(ctx, l.logger(), slog.LevelInfo, "query", queryAttrs(sql, rows, elapsed, nil))
+	}
+}
+
+// logQuery logs msg and attrs at level with sl, from the application's call site.
+func logQuery(ctx context.Context, sl *slog.Logger, level slog.Level, msg string, attrs []any) {
+	h := sl.Handler()
+	if !h.Enabled(ctx, level) {
+		return
+	}
+
+	pc := queryCaller()
+	rec := slog.NewRecord(time.Now(), level, msg, pc)
+	rec.Add(attrs...)
+	if pc != 0 {
+		f, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+		rec.Add("caller", fmt.Sprintf("%s/%s:%d", path.Base(path.Dir(f.File)), path.Base(f.File), f.Line))
+	}
+
+	_ = h.Handle(ctx, rec)
+}
+
+// queryCaller finds the PC of the first frame calling into gorm or package postgres,
+// or zero if there is none.
+func queryCaller() uintptr {
+	pcs := make([]uintptr, 64)
+	n := runtime.Callers(3, pcs)
+	for _, pc := range pcs[:n] {
+		if f, _ := runtime.CallersFrames([]uintptr{pc}).Next(); !queryInternal(f) {
+			return pc
+		}
+	}
+
+	return 0
+}
+
+// queryInternal asserts whether f is in a function running a query on behalf of the application.
+// Tests are never internal, even those of package postgres.
+func queryInternal(f runtime.Frame) bool {
+	if strings.HasSuffix(f.File, "_test.go") {
+		return false
+	}
+
+	for _, prefix := range []string{
+		"database/sql.",
+		"github.com/jackc/",
+		"github.com/xy-planning-network/trails/postgres.",
+		"gorm.io/",
+		"runtime.",
+	} {
+		if strings.HasPrefix(f.Function, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// queryAttrs collects the details of a query as key-value pairs for logging.
+func queryAttrs(sql string, rows int64, elapsed time.Duration, err error) []any {
+	attrs := []any{"elapsed", elapsed.String(), "rows", rows, "sql", sql}
+	if err != nil {
+		attrs = append(attrs, "error", err.Error())
+	}
+
+	return attrs
+}
@@ -0,0 +1,39 @@
+package postgres
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	pg "gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestSlogLoggerCaller(t *testing.T) {
+	// Arrange
+	b := new(bytes.Buffer)
+	l := slog.New(slog.NewJSONHandler(b, &slog.HandlerOptions{AddSource: true}))
+
+	db, err := gorm.Open(pg.Open("host=localhost"), &gorm.Config{
+		DisableAutomaticPing: true,
+		DryRun:               true,
+		Logger:               newSlogLogger(logger.Config{LogLevel: logger.Warn}, l),
+	})
+	require.NoError(t, err)
+
+	var n int64
+
+	// Act
+	db.Debug().Table("widgets").Count(&n)
+
+	// Assert
+	var out map[string]any
+	require.NoError(t, json.Unmarshal(b.Bytes(), &out))
+	require.Equal(t, "query", out["msg"])
+	require.Regexp(t, regexp.MustCompile(`^postgres/logger_test\.go:\d+$`), out["caller"])
+	require.Regexp(t, regexp.MustCompile(`logger_test\.go$`), out["source"].(map[string]any)["file"])
+}
@@ -0,0 +1,313 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/xy-planning-network/trails"
+	"gorm.io/gorm"
+)
+
+const (
+	metricsNamespace = "trails"
+	metricsSubsystem = "db"
+
+	// metricsStartKey stashes when a statement started in its *gorm.DB.
+	metricsStartKey = "trails:metrics_start"
+)
+
+// A Collector is a gorm plugin and a prometheus.Collector recording metrics about a database:
+//
+//	c := postgres.NewCollector()
+//	err := db.Use(c)
+//	registry.MustRegister(c)
+//
+// A Collector records:
+//   - trails_db_queries_total: a counter of statements executed
+//   - trails_db_query_duration_seconds: a histogram of the time taken to execute statements
+//   - trails_db_query_errors_total: a counter of statements failing
+//   - trails_db_transaction_duration_seconds: a histogram of the time between beginning and ending transactions
+//   - trails_db_connections: gauges of the connections in the pool
+//   - trails_db_connections_max: a gauge of the most connections the pool opens
+//   - trails_db_connection_waits_total: a counter of waits for a connection
+//   - trails_db_connection_wait_seconds_total: a counter of the time spent waiting for connections
+//   - trails_db_connections_closed_total: a counter of connections the pool closed
+//
+// Statement metrics are labeled by the operation, i.e., "create", "delete", "query", "raw", "row", or "update".
+// Errors are further labeled by the trails sentinel error they match, i.e.,
+// "not_exist" (including gorm.ErrRecordNotFound), "not_valid", "missing_data", or "bad_config",
+// "canceled" or "deadline_exceeded" for contexts ending, and "other" for everything else.
+// Transactions are labeled by whether they were committed or rolled back.
+// Connections are labeled by their state, i.e., "in_use" or "idle",
+// and closed connections by why they were, i.e., "max_idle", "max_idle_time", or "max_lifetime".
+//
+// New ranger.Ranger register a Collector when ranger.Config.Metrics is true.
+type Collector struct {
+	db           atomic.Pointer[sql.DB]
+	errors       *prometheus.CounterVec
+	queries      *prometheus.CounterVec
+	durations    *prometheus.HistogramVec
+	transactions *prometheus.HistogramVec
+
+	closed    *prometheus.Desc
+	conns     *prometheus.Desc
+	maxConns  *prometheus.Desc
+	waitTime  *prometheus.Desc
+	waitCount *prometheus.Desc
+}
+
+// NewCollector constructs a Collector, which records nothing until used by a *gorm.DB.
+func NewCollector() *Collector {
+	return &Collector{
+		errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Subsystem: metricsSubsystem,
+			Name:      "query_errors_total",
+			Help:      "Number of database statements failing.",
+		}, []string{"operation", "error"}),
+		queries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Subsystem: metricsSubsystem,
+			Name:      "queries_total",
+			Help:      "Number of database statements executed.",
+		}, []string{"operation"}),
+		durations: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: metricsNamespace,
+			Subsystem: metricsSubsystem,
+			Name:      "query_duration_seconds",
+			Help:      "Time taken to execute database statements.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"operation"}),
+		transactions: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: metricsNamespace,
+			Subsystem: metricsSubsystem,
+			Name:      "transaction_duration_seconds",
+			Help:      "Time between beginning and ending database transactions.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"outcome"}),
+
+		closed: prometheus.NewDesc(
+			prometheus.BuildFQName(metricsNamespace, metricsSubsystem, "connections_closed_total"),
+			"Number of database connections the pool closed.",
+			[]string{"reason"}, nil,
+		),
+		conns: prometheus.NewDesc(
+			prometheus.BuildFQName(metricsNamespace, metricsSubsystem, "connections"),
+			"Number of database connections in the pool.",
+			[]string{"state"}, nil,
+		),
+		maxConns: prometheus.NewDesc(
+			prometheus.BuildFQName(metricsNamespace, metricsSubsystem, "connections_max"),
+			"Most database connections the pool opens; 0 is unlimited.",
+			nil, nil,
+		),
+		waitCount: prometheus.NewDesc(
+			prometheus.BuildFQName(metricsNamespace, metricsSubsystem, "connection_waits_total"),
+			"Number of waits for a database connection.",
+			nil, nil,
+		),
+		waitTime: prometheus.NewDesc(
+			prometheus.BuildFQName(metricsNamespace, metricsSubsystem, "connection_wait_seconds_total"),
+			"Time spent waiting for database connections.",
+			nil, nil,
+		),
+	}
+}
+
+// Name names the plugin.
+func (*Collector) Name() string { return "trails:metrics" }
+
+// Initialize registers the callbacks timing statements
+// and wraps the connection pool of db to time transactions.
+func (c *Collector) Initialize(db *gorm.DB) error {
+	cb := db.Callback()
+	for _, op := range []struct {
+		name          string
+		before, after registrar
+	}{
+		{"create", cb.Create().Before("gorm:create"), cb.Create().After("gorm:create")},
+		{"delete", cb.Delete().Before("gorm:delete"), cb.Delete().After("gorm:delete")},
+		{"query", cb.Query().Before("gorm:query"), cb.Query().After("gorm:query")},
+		{"raw", cb.Raw().Before("gorm:raw"), cb.Raw().After("gorm:raw")},
+		{"row", cb.Row().Before("gorm:row"), cb.Row().After("gorm:row")},
+		{"update", cb.Update().Before("gorm:update"), cb.Update().After("gorm:update")},
+	} {
+		if err := op.before.Register(c.Name()+":before_"+op.name, startStatement); err != nil {
+			return err
+		}
+
+		if err := op.after.Register(c.Name()+":after_"+op.name, c.endStatement(op.name)); err != nil {
+			return err
+		}
+	}
+
+	c.instrument(db)
+
+	return nil
+}
+
+// A registrar registers a callback where gorm executes statements,
+// naming the callback type gorm does not export.
+type registrar interface {
+	Register(name string, fn func(*gorm.DB)) error
+}
+
+// instrument wraps the *sql.DB db queries through, including when preparing statements,
+// so transactions begun on it are timed.
+func (c *Collector) instrument(db *gorm.DB) {
+	shared := db.Statement != nil && db.Statement.ConnPool == db.ConnPool
+
+	switch pool := db.ConnPool.(type) {
+	case *sql.DB:
+		c.db.Store(pool)
+		db.ConnPool = &metricsPool{DB: pool, c: c}
+	case *gorm.PreparedStmtDB:
+		if sqlDB, ok := pool.ConnPool.(*sql.DB); ok {
+			c.db.Store(sqlDB)
+			pool.ConnPool = &metricsPool{DB: sqlDB, c: c}
+		}
+	}
+
+	if shared {
+		db.Statement.ConnPool = db.ConnPool
+	}
+}
+
+// Describe sends the descriptors of every metric c records to ch.
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	c.errors.Describe(ch)
+	c.queries.Describe(ch)
+	c.durations.Describe(ch)
+	c.transactions.Describe(ch)
+
+	ch <- c.closed
+	ch <- c.conns
+	ch <- c.maxConns
+	ch <- c.waitCount
+	ch <- c.waitTime
+}
+
+// Collect sends the current value of every metric c records to ch,
+// reading the statistics of the connection pool.
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	c.errors.Collect(ch)
+	c.queries.Collect(ch)
+	c.durations.Collect(ch)
+	c.transactions.Collect(ch)
+
+	db := c.db.Load()
+	if db == nil {
+		return
+	}
+
+	s := db.Stats()
+	ch <- prometheus.MustNewConstMetric(c.conns, prometheus.GaugeValue, float64(s.InUse), "in_use")
+	ch <- prometheus.MustNewConstMetric(c.conns, prometheus.GaugeValue, float64(s.Idle), "idle")
+	ch <- prometheus.MustNewConstMetric(c.maxConns, prometheus.GaugeValue, float64(s.MaxOpenConnections))
+	ch <- prometheus.MustNewConstMetric(c.waitCount, prometheus.CounterValue, float64(s.WaitCount))
+	ch <- prometheus.MustNewConstMetric(c.waitTime, prometheus.CounterValue, s.WaitDuration.Seconds())
+	ch <- prometheus.MustNewConstMetric(c.closed, prometheus.CounterValue, float64(s.MaxIdleClosed), "max_idle")
+	ch <- prometheus.MustNewConstMetric(c.closed, prometheus.CounterValue, float64(s.MaxIdleTimeClosed), "max_idle_time")
+	ch <- prometheus.MustNewConstMetric(c.closed, prometheus.CounterValue, float64(s.MaxLifetimeClosed), "max_lifetime")
+}
+
+// startStatement stashes when the statement tx executes started.
+func startStatement(tx *gorm.DB) {
+	tx.InstanceSet(metricsStartKey, time.Now())
+}
+
+// endStatement records the statement tx executed as an op.
+func (c *Collector) endStatement(op string) func(*gorm.DB) {
+	return func(tx *gorm.DB) {
+		c.queries.WithLabelValues(op).Inc()
+
+		if start, ok := tx.InstanceGet(metricsStartKey); ok {
+			if start, ok := start.(time.Time); ok {
+				c.durations.WithLabelValues(op).Observe(time.Since(start).Seconds())
+			}
+		}
+
+		if tx.Error != nil {
+			c.errors.WithLabelValues(op, errorClass(tx.Error)).Inc()
+		}
+	}
+}
+
+// errorClass labels err by the sentinel error it matches.
+func errorClass(err error) string {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound), errors.Is(err, trails.ErrNotExist):
+		return "not_exist"
+	case errors.Is(err, trails.ErrNotValid):
+		return "not_valid"
+	case errors.Is(err, trails.ErrMissingData):
+		return "missing_data"
+	case errors.Is(err, trails.ErrBadConfig):
+		return "bad_config"
+	case errors.Is(err, context.Canceled):
+		return "canceled"
+	case errors.Is(err, context.DeadlineExceeded):
+		return "deadline_exceeded"
+	default:
+		return "other"
+	}
+}
+
+// A metricsPool is the *sql.DB a Collector begins transactions on, timing them.
+type metricsPool struct {
+	*sql.DB
+	c *Collector
+}
+
+// BeginTx begins a transaction timed until it is committed or rolled back.
+func (p *metricsPool) BeginTx(ctx context.Context, opts *sql.TxOptions) (gorm.ConnPool, error) {
+	tx, err := p.DB.BeginTx(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return &metricsTx{Tx: tx, db: p.DB, c: p.c, start: time.Now()}, nil
+}
+
+// GetDBConn returns the wrapped *sql.DB, so *gorm.DB's DB method does.
+func (p *metricsPool) GetDBConn() (*sql.DB, error) { return p.DB, nil }
+
+// A metricsTx is a transaction a metricsPool began.
+type metricsTx struct {
+	*sql.Tx
+	c     *Collector
+	db    *sql.DB
+	ended atomic.Bool
+	start time.Time
+}
+
+// Commit commits the transaction, recording how long it took.
+func (tx *metricsTx) Commit() error {
+	defer tx.observe("commit")
+
+	return tx.Tx.Commit()
+}
+
+// Rollback rolls back the transaction, recording how long it took.
+func (tx *metricsTx) Rollback() error {
+	defer tx.observe("rollback")
+
+	return tx.Tx.Rollback()
+}
+
+// GetDBConn returns the *sql.DB the transaction began on, so *gorm.DB's DB method does.
+func (tx *metricsTx) GetDBConn() (*sql.DB, error) { return tx.db, nil }
+
+// observe records the duration of the transaction as ending by outcome,
+// unless it already ended, e.g., rolling back after failing to commit.
+func (tx *metricsTx) observe(outcome string) {
+	if tx.ended.Swap(true) {
+		return
+	}
+
+	tx.c.transactions.WithLabelValues(outcome).Observe(time.Since(tx.start).Seconds())
+}
@@ -0,0 +1,127 @@
+package postgres_test
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	"github.com/xy-planning-network/trails/postgres"
+	pg "gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// txDriver is a database/sql driver whose connections only begin and end transactions.
+type txDriver struct{}
+
+func (txDriver) Open(string) (driver.Conn, error) { return txConn{}, nil }
+
+type txConn struct{}
+
+func (txConn) Prepare(string) (driver.Stmt, error) { return nil, errors.New("not implemented") }
+func (txConn) Close() error                        { return nil }
+func (txConn) Begin() (driver.Tx, error)           { return txConn{}, nil }
+func (txConn) Commit() error                       { return nil }
+func (txConn) Rollback() error                     { return nil }
+
+func init() { sql.Register("trails-tx", txDriver{}) }
+
+func TestCollector(t *testing.T) {
+	// Arrange
+	// NOTE: queries are not executed, but the transactions creating and updating run in are.
+	db, err := gorm.Open(pg.New(pg.Config{DriverName: "trails-tx"}), &gorm.Config{
+		DisableAutomaticPing: true,
+		DryRun:               true,
+		Logger:               &sqlLogger{},
+	})
+	require.NoError(t, err)
+	require.NoError(t, db.Use(postgres.ColumnValidator{}))
+
+	c := postgres.NewCollector()
+	require.NoError(t, db.Use(c))
+
+	reg := prometheus.NewRegistry()
+	require.NoError(t, reg.Register(c))
+
+	// Act
+	require.NoError(t, db.Create(&login{UserID: 1}).Error)
+	require.NoError(t, db.Find(&[]login{}).Error)
+	require.Error(t, db.Model(&login{ID: 1}).Updates(map[string]any{"usr_id": 2}).Error)
+
+	// Assert
+	expected := `
+# HELP trails_db_queries_total Number of database statements executed.
+# TYPE trails_db_queries_total counter
+trails_db_queries_total{operation="create"} 1
+trails_db_queries_total{operation="query"} 1
+trails_db_queries_total{operation="update"} 1
+# HELP trails_db_query_errors_total Number of database statements failing.
+# TYPE trails_db_query_errors_total counter
+trails_db_query_errors_total{error="not_valid",operation="update"} 1
+# HELP trails_db_connections Number of database connections in the pool.
+# TYPE trails_db_connections gauge
+trails_db_connections{state="idle"} 1
+trails_db_connections{state="in_use"} 0
+`
+	require.NoError(t, testutil.GatherAndCompare(
+		reg,
+		strings.NewReader(expected),
+		"trails_db_queries_total",
+		"trails_db_query_errors_total",
+		"trails_db_connections",
+	))
+
+	count, err := testutil.GatherAndCount(reg, "trails_db_query_duration_seconds", "trails_db_transaction_duration_seconds")
+	require.NoError(t, err)
+	require.Equal(t, 5, count)
+
+	_, err = db.DB()
+	require.NoError(t, err)
+}
+
+func TestCollectorTransaction(t *testing.T) {
+	// Arrange
+	db, err := gorm.Open(pg.New(pg.Config{DriverName: "trails-tx"}), &gorm.Config{
+		DisableAutomaticPing: true,
+		DryRun:               true,
+		Logger:               &sqlLogger{},
+	})
+	require.NoError(t, err)
+
+	c := postgres.NewCollector()
+	require.NoError(t, db.Use(c))
+
+	reg := prometheus.NewRegistry()
+	require.NoError(t, reg.Register(c))
+
+	// Act
+	tx := db.Begin()
+	require.NoError(t, tx.Error)
+	require.NoError(t, tx.Commit().Error)
+	tx.Rollback() // NOTE: already committed, so not recorded again.
+
+	err = db.Transaction(func(tx *gorm.DB) error { return errors.New("boom") })
+
+	// Assert
+	require.Error(t, err)
+
+	families, err := reg.Gather()
+	require.NoError(t, err)
+
+	outcomes := make(map[string]uint64)
+	for _, f := range families {
+		if f.GetName() != "trails_db_transaction_duration_seconds" {
+			continue
+		}
+
+		for _, m := range f.GetMetric() {
+			outcomes[m.GetLabel()[0].GetValue()] = m.GetHistogram().GetSampleCount()
+		}
+	}
+
+	require.Equal(t, map[string]uint64{"commit": 1, "rollback": 1}, outcomes)
+}
@@ -129,3 +129,17 @@ func (mr *MockDatabaseServiceMockRecorder) PagedByQueryFromSession(models, sessi
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PagedByQueryFromSession", reflect.TypeOf((*MockDatabaseService)(nil).PagedByQueryFromSession), models, session, page, perPage)
 }
+
+// Updates mocks base method.
+func (m *MockDatabaseService) Updates(model any, values map[string]any) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Updates", model, values)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Updates indicates an expected call of Updates.
+func (mr *MockDatabaseServiceMockRecorder) Updates(model, values any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Updates", reflect.TypeOf((*MockDatabaseService)(nil).Updates), model, values)
+}
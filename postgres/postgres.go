@@ -2,13 +2,11 @@ package postgres
 
 import (
 	"fmt"
-	"log"
-	"os"
+	"log/slog"
 	"strings"
 	"time"
 
 	"github.com/xy-planning-network/trails"
-	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
 	"gorm.io/gorm/schema"
@@ -28,6 +26,30 @@ type CxnConfig struct {
 	Password    string
 	SSLMode     string
 	MaxIdleCxns int
+
+	// Logger logs the queries the connection runs that fail or are slow, and those run with *gorm.DB.Debug.
+	//
+	// If Logger is nil, slog.Default is used.
+	Logger *slog.Logger
+
+	// PrepareStmt has the connection prepare the statements it executes, caching them for reuse,
+	// so Postgres parses and plans each query once per connection instead of every time it is executed.
+	PrepareStmt bool
+
+	// SensitiveColumns names the columns whose values are logged as trails.LogMaskVal
+	// when the connection logs queries, e.g., with *gorm.DB.Debug or as slow queries;
+	// cf. Sensitive for marking parameters instead.
+	SensitiveColumns []string
+
+	// StmtCacheSize is the most prepared statements the connection keeps when PrepareStmt is true;
+	// cf. StmtCache.
+	//
+	// If StmtCacheSize is 0, DefaultStmtCacheSize is used.
+	StmtCacheSize int
+
+	// ValidateColumns has the connection check maps models are created or updated with
+	// against their columns; cf. ColumnValidator.
+	ValidateColumns bool
 }
 
 // Connect creates a database connection through GORM according to the connection config.
@@ -39,26 +61,34 @@ func Connect(config *CxnConfig, env trails.Environment) (*gorm.DB, error) {
 		SlowThreshold:             200 * time.Millisecond,
 		LogLevel:                  logger.Warn,
 		IgnoreRecordNotFoundError: true,
-		Colorful:                  false,
-	}
-
-	if env.IsDevelopment() {
-		c.Colorful = true
 	}
 
-	gormDB, err := gorm.Open(postgres.Open(buildCxnStr(config)), &gorm.Config{
-		Logger: logger.New(log.New(os.Stdout, "\r\n", log.LstdFlags), c),
+	gormDB, err := gorm.Open(newRedactingDialector(buildCxnStr(config), config.SensitiveColumns), &gorm.Config{
+		Logger: newSlogLogger(c, config.Logger),
 		NamingStrategy: schema.NamingStrategy{
 			NameReplacer: strings.NewReplacer("Table", ""),
 		},
 		NowFunc: func() time.Time {
 			return time.Now().Truncate(time.Microsecond)
 		},
+		PrepareStmt: config.PrepareStmt,
 	})
 	if err != nil {
 		return nil, err
 	}
 
+	if config.PrepareStmt {
+		if err := gormDB.Use(StmtCache{Size: config.StmtCacheSize}); err != nil {
+			return nil, err
+		}
+	}
+
+	if config.ValidateColumns {
+		if err := gormDB.Use(ColumnValidator{}); err != nil {
+			return nil, err
+		}
+	}
+
 	db, err := gormDB.DB()
 	if err != nil {
 		return nil, err
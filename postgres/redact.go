@@ -0,0 +1,135 @@
+package postgres
+
+import (
+	"database/sql/driver"
+	"log/slog"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/xy-planning-network/trails"
+	"gorm.io/driver/postgres"
+)
+
+var (
+	// comparedParams matches a column compared to, or set to, parameters,
+	// e.g., "users"."password" = $1 or "token" IN ($2,$3).
+	comparedParams = regexp.MustCompile(
+		`(?i)"?(\w+)"?\s*(?:=|<>|!=|<=|>=|<|>|\b(?:NOT\s+)?(?:I?LIKE|IN)\b)\s*(\(\s*\$\d+(?:\s*,\s*\$\d+)*\s*\)|\$\d+)`,
+	)
+
+	// insertedParams matches the columns and rows of an INSERT.
+	insertedParams = regexp.MustCompile(`(?is)\bINSERT\s+INTO\s+\S+\s*\(([^)]*)\)\s*VALUES\s*(.*)`)
+
+	// insertedRow matches a row of values in an INSERT.
+	insertedRow = regexp.MustCompile(`\(([^()]*)\)`)
+
+	// param matches a parameter, capturing its position.
+	param = regexp.MustCompile(`\$(\d+)`)
+)
+
+// Sensitive marks v as a sensitive query parameter,
+// which the *gorm.DB Connect constructs logs as trails.LogMaskVal,
+// e.g., with *gorm.DB.Debug or as a slow query:
+//
+//	db.Where("token = ?", postgres.Sensitive(token)).First(&session)
+//
+// v is queried with as is, so it cannot be a list, e.g., for IN clauses;
+// mark the column sensitive with CxnConfig.SensitiveColumns instead.
+func Sensitive(v any) SensitiveValue { return SensitiveValue{V: v} }
+
+// A SensitiveValue is a query parameter that is never logged; cf. Sensitive.
+type SensitiveValue struct{ V any }
+
+// Value returns the value queried with.
+func (s SensitiveValue) Value() (driver.Value, error) {
+	if v, ok := s.V.(driver.Valuer); ok {
+		return v.Value()
+	}
+
+	return s.V, nil
+}
+
+// LogValue masks the value when logged with [slog].
+func (SensitiveValue) LogValue() slog.Value { return trails.MaskedLogValue }
+
+// String masks the value when formatted.
+func (SensitiveValue) String() string { return trails.LogMaskVal }
+
+// A redactingDialector is a [postgres.Dialector] explaining queries for logging
+// with SensitiveValues and the parameters of sensitive columns masked as trails.LogMaskVal.
+//
+// gorm explains queries only for logging them, including with *gorm.DB.Debug and as slow queries,
+// so queries still run with their parameters.
+type redactingDialector struct {
+	postgres.Dialector
+	columns []string
+}
+
+// newRedactingDialector constructs a [gorm.Dialector] connecting to dsn
+// and explaining queries with the parameters of columns masked.
+func newRedactingDialector(dsn string, columns []string) redactingDialector {
+	return redactingDialector{Dialector: postgres.Dialector{Config: &postgres.Config{DSN: dsn}}, columns: columns}
+}
+
+// Explain interpolates vars into sql, masking the sensitive ones.
+func (d redactingDialector) Explain(sql string, vars ...any) string {
+	return d.Dialector.Explain(sql, redactVars(sql, vars, d.columns)...)
+}
+
+// redactVars copies vars, replacing SensitiveValues and the parameters
+// sql compares, sets, or inserts columns to with trails.LogMaskVal.
+func redactVars(sql string, vars []any, columns []string) []any {
+	redacted := slices.Clone(vars)
+	for i, v := range redacted {
+		if _, ok := v.(SensitiveValue); ok {
+			redacted[i] = trails.LogMaskVal
+		}
+	}
+
+	if len(columns) == 0 {
+		return redacted
+	}
+
+	sensitive := func(column string) bool {
+		column = strings.Trim(strings.TrimSpace(column), `"`)
+		return slices.ContainsFunc(columns, func(c string) bool { return strings.EqualFold(c, column) })
+	}
+
+	mask := func(p string) {
+		if n, err := strconv.Atoi(p); err == nil && n > 0 && n <= len(redacted) {
+			redacted[n-1] = trails.LogMaskVal
+		}
+	}
+
+	for _, m := range comparedParams.FindAllStringSubmatch(sql, -1) {
+		if sensitive(m[1]) {
+			for _, p := range param.FindAllStringSubmatch(m[2], -1) {
+				mask(p[1])
+			}
+		}
+	}
+
+	m := insertedParams.FindStringSubmatch(sql)
+	if m == nil {
+		return redacted
+	}
+
+	cols := strings.Split(m[1], ",")
+	for _, row := range insertedRow.FindAllStringSubmatch(m[2], -1) {
+		vals := strings.Split(row[1], ",")
+		if len(vals) != len(cols) {
+			continue
+		}
+
+		for i, col := range cols {
+			v := strings.TrimSpace(vals[i])
+			if p := param.FindStringSubmatch(v); p != nil && p[0] == v && sensitive(col) {
+				mask(p[1])
+			}
+		}
+	}
+
+	return redacted
+}
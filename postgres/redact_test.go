@@ -0,0 +1,94 @@
+package postgres
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+type account struct {
+	ID       uint
+	Email    string
+	Password string
+}
+
+func TestRedactingDialector(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		query    func(db *gorm.DB)
+		expected string
+	}{
+		{
+			"Create",
+			func(db *gorm.DB) {
+				db.Create(&[]account{{Email: "a@example.com", Password: "hunter2"}, {Email: "b@example.com", Password: "hunter3"}})
+			},
+			`INSERT INTO "accounts" ("email","password") VALUES ('a@example.com','xxxxxx'),('b@example.com','xxxxxx') RETURNING "id"`,
+		},
+		{
+			"Where",
+			func(db *gorm.DB) { db.Where(&account{Email: "a@example.com", Password: "hunter2"}).Find(&[]account{}) },
+			`SELECT * FROM "accounts" WHERE "accounts"."email" = 'a@example.com' AND "accounts"."password" = 'xxxxxx'`,
+		},
+		{
+			"Where-In",
+			func(db *gorm.DB) { db.Where("password IN ?", []string{"hunter2", "hunter3"}).Find(&[]account{}) },
+			`SELECT * FROM "accounts" WHERE password IN ('xxxxxx','xxxxxx')`,
+		},
+		{
+			"Updates",
+			func(db *gorm.DB) {
+				db.Model(&account{ID: 1}).Updates(map[string]any{"email": "a@example.com", "password": "hunter2"})
+			},
+			`UPDATE "accounts" SET "email"='a@example.com',"password"='xxxxxx' WHERE "id" = 1`,
+		},
+		{
+			"Sensitive",
+			func(db *gorm.DB) {
+				db.Exec("UPDATE sessions SET revoked = ? WHERE secret = ?", true, Sensitive("s3cr3t"))
+			},
+			`UPDATE sessions SET revoked = true WHERE secret = 'xxxxxx'`,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			b := new(bytes.Buffer)
+
+			db, err := gorm.Open(newRedactingDialector("host=localhost", []string{"Password"}), &gorm.Config{
+				DisableAutomaticPing:   true,
+				DryRun:                 true,
+				Logger:                 newSlogLogger(logger.Config{LogLevel: logger.Warn}, slog.New(slog.NewJSONHandler(b, nil))),
+				SkipDefaultTransaction: true,
+			})
+			require.NoError(t, err)
+
+			// Act
+			tc.query(db.Debug())
+
+			// Assert
+			var out map[string]any
+			require.NoError(t, json.Unmarshal(b.Bytes(), &out))
+			require.Equal(t, tc.expected, out["sql"])
+		})
+	}
+}
+
+func TestSensitiveValue(t *testing.T) {
+	// Arrange
+	b := new(bytes.Buffer)
+	s := Sensitive("hunter2")
+
+	// Act
+	slog.New(slog.NewTextHandler(b, nil)).Info("login", "password", s)
+	v, err := s.Value()
+
+	// Assert
+	require.NoError(t, err)
+	require.Equal(t, "hunter2", v)
+	require.Contains(t, b.String(), "password=xxxxxx")
+}
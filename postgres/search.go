@@ -0,0 +1,77 @@
+package postgres
+
+import (
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// SearchConfig is the text search configuration search columns are built and searched with.
+// "simple" neither stems words nor drops stop words, suiting names and email addresses.
+const SearchConfig = "simple"
+
+// searchQuery parses a query as typed into a search box into a tsquery; cf. websearch_to_tsquery.
+const searchQuery = "websearch_to_tsquery('" + SearchConfig + "', ?)"
+
+// WhereSearch matches rows whose tsvector column, e.g., one AddSearchColumn adds, matches query,
+// parsed as typed into a search box: words are ANDed, "quoted phrases" match in order,
+// "or" ORs words, and a leading "-" negates a word.
+//
+// If query is blank, WhereSearch matches every row.
+//
+//	db.Where(postgres.WhereSearch("search", q)).Scopes(postgres.OrderByRank("search", q)).Find(&users)
+func WhereSearch(column, query string) clause.Expression {
+	if strings.TrimSpace(query) == "" {
+		return clause.Expr{SQL: "TRUE"}
+	}
+
+	return clause.Expr{SQL: "? @@ " + searchQuery, Vars: []any{clause.Column{Name: column}, query}}
+}
+
+// OrderByRank orders rows by how well their tsvector column matches query, best first.
+// Use it with *gorm.DB's Scopes alongside WhereSearch.
+//
+// If query is blank, OrderByRank leaves the order as is.
+func OrderByRank(column, query string) func(*gorm.DB) *gorm.DB {
+	return func(tx *gorm.DB) *gorm.DB {
+		if strings.TrimSpace(query) == "" {
+			return tx
+		}
+
+		return tx.Clauses(clause.OrderBy{Expression: clause.Expr{
+			SQL:                "ts_rank(?, " + searchQuery + ") DESC",
+			Vars:               []any{clause.Column{Name: column}, query},
+			WithoutParentheses: true,
+		}})
+	}
+}
+
+// AddSearchColumn returns a Migration Executor adding a tsvector column to table,
+// generated from the text of sources, and a GIN index on it, so WhereSearch need not scan the table.
+//
+//	postgres.Migration{Key: "20240601_users_search", Executor: postgres.AddSearchColumn("users", "search", "name", "email")}
+func AddSearchColumn(table, column string, sources ...string) func(*gorm.DB) error {
+	return func(tx *gorm.DB) error {
+		doc := make([]string, len(sources))
+		vars := make([]any, 0, len(sources)+2)
+		vars = append(vars, clause.Table{Name: table}, clause.Column{Name: column})
+		for i, src := range sources {
+			doc[i] = "coalesce(?::text, '')"
+			vars = append(vars, clause.Column{Name: src})
+		}
+
+		err := tx.Exec(
+			"ALTER TABLE ? ADD COLUMN ? tsvector GENERATED ALWAYS AS (to_tsvector('"+SearchConfig+"', "+strings.Join(doc, " || ' ' || ")+")) STORED",
+			vars...,
+		).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Exec(
+			"CREATE INDEX ? ON ? USING GIN (?)",
+			clause.Column{Name: table + "_" + column + "_idx"}, clause.Table{Name: table}, clause.Column{Name: column},
+		).Error
+	}
+}
@@ -0,0 +1,73 @@
+package postgres_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/xy-planning-network/trails/postgres"
+	pg "gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestSearch(t *testing.T) {
+	db, err := gorm.Open(pg.Open("host=localhost"), &gorm.Config{DisableAutomaticPing: true, DryRun: true})
+	require.NoError(t, err)
+
+	for _, tc := range []struct {
+		name     string
+		query    string
+		expected string
+	}{
+		{
+			"Query",
+			"jane -doe",
+			`SELECT * FROM "widgets" WHERE "search" @@ websearch_to_tsquery('simple', 'jane -doe') ORDER BY ts_rank("search", websearch_to_tsquery('simple', 'jane -doe')) DESC`,
+		},
+		{"Blank", "  ", `SELECT * FROM "widgets" WHERE TRUE`},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			// Act
+			actual := db.ToSQL(func(tx *gorm.DB) *gorm.DB {
+				var ws []widget
+				return tx.Where(postgres.WhereSearch("search", tc.query)).Scopes(postgres.OrderByRank("search", tc.query)).Find(&ws)
+			})
+
+			// Assert
+			require.Equal(t, tc.expected, actual)
+		})
+	}
+}
+
+func TestAddSearchColumn(t *testing.T) {
+	// Arrange
+	l := &sqlLogger{}
+	db, err := gorm.Open(pg.Open("host=localhost"), &gorm.Config{DisableAutomaticPing: true, DryRun: true, Logger: l})
+	require.NoError(t, err)
+
+	// Act
+	err = postgres.AddSearchColumn("users", "search", "name", "email")(db)
+
+	// Assert
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		`ALTER TABLE "users" ADD COLUMN "search" tsvector GENERATED ALWAYS AS (to_tsvector('simple', coalesce("name"::text, '') || ' ' || coalesce("email"::text, ''))) STORED`,
+		`CREATE INDEX "users_search_idx" ON "users" USING GIN ("search")`,
+	}, l.sql)
+}
+
+// sqlLogger records the SQL of statements gorm runs.
+type sqlLogger struct {
+	sql []string
+}
+
+func (l *sqlLogger) LogMode(logger.LogLevel) logger.Interface { return l }
+func (l *sqlLogger) Info(context.Context, string, ...any)     {}
+func (l *sqlLogger) Warn(context.Context, string, ...any)     {}
+func (l *sqlLogger) Error(context.Context, string, ...any)    {}
+func (l *sqlLogger) Trace(_ context.Context, _ time.Time, fc func() (string, int64), _ error) {
+	sql, _ := fc()
+	l.sql = append(l.sql, sql)
+}
@@ -1,9 +1,13 @@
 package postgres
 
 import (
+	"fmt"
 	"math"
+	"strings"
 
+	"github.com/xy-planning-network/trails"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // DatabaseService sets up the interface to be used at the handler/middelware level. These should be straightforward
@@ -17,6 +21,7 @@ type DatabaseService interface {
 	FindByQuery(model any, query map[string]any) error
 	PagedByQuery(models any, query string, params []any, order string, page int, perPage int, preloads ...string) (PagedData, error)
 	PagedByQueryFromSession(models any, session *gorm.DB, page int, perPage int) (PagedData, error)
+	Updates(model any, values map[string]any) error
 }
 
 // PagedData is returned from the PagedByQuery method. It contains paged database records and pagination metadata.
@@ -26,11 +31,30 @@ type PagedData struct {
 	PerPage    int   `json:"perPage"`
 	TotalItems int64 `json:"totalItems"`
 	TotalPages int   `json:"totalPages"`
+
+	// Estimated is whether TotalItems is an estimate; cf. DatabaseServiceImpl.EstimateCountsOver.
+	Estimated bool `json:"estimated,omitempty"`
 }
 
 // DatabaseServiceImpl satisfies the above DatabaseService interface.
 type DatabaseServiceImpl struct {
 	DB *gorm.DB
+
+	// EstimateCountsOver is the number of rows a table holds over which
+	// PagedByQuery and PagedByQueryFromSession estimate TotalItems with EstimatedCount
+	// instead of counting rows exactly, when paging through the table without conditions.
+	//
+	// If zero, TotalItems is always exact.
+	EstimateCountsOver int64
+
+	// RequireOrder has PagedByQuery and PagedByQueryFromSession return trails.ErrNotValid
+	// when paging without an order, since Postgres returns rows in no particular order otherwise.
+	RequireOrder bool
+
+	// TiebreakOrder has PagedByQuery and PagedByQueryFromSession order by the primary key of the models paged through
+	// after any other order, unless that order includes it,
+	// so rows ordered equally, or not at all, fall on the same page every time.
+	TiebreakOrder bool
 }
 
 // NewService hydrates the gorm database for the implementation struct methods.
@@ -77,8 +101,8 @@ func (service *DatabaseServiceImpl) PagedByQuery(models any, query string, param
 	}
 
 	// Conduct unlimited count query to calculate totals
-	var totalRecords int64
-	if err := service.DB.Where(query, params...).Model(models).Count(&totalRecords).Error; err != nil {
+	totalRecords, estimated, err := service.count(service.DB.Where(query, params...).Model(models))
+	if err != nil {
 		return pd, err
 	}
 
@@ -88,7 +112,11 @@ func (service *DatabaseServiceImpl) PagedByQuery(models any, query string, param
 	for _, preload := range preloads {
 		session = session.Preload(preload)
 	}
-	if err := session.Where(query, params...).Order(order).Limit(perPage).Offset(offset).Find(models).Error; err != nil {
+	session, err = service.order(session.Where(query, params...).Order(order), models)
+	if err != nil {
+		return pd, err
+	}
+	if err := session.Limit(perPage).Offset(offset).Find(models).Error; err != nil {
 		return pd, err
 	}
 
@@ -96,6 +124,7 @@ func (service *DatabaseServiceImpl) PagedByQuery(models any, query string, param
 	pd.Page = page
 	pd.PerPage = perPage
 	pd.TotalItems = totalRecords
+	pd.Estimated = estimated
 	totalPagesFloat := float64(totalRecords) / float64(perPage)
 	pd.TotalPages = int(math.Ceil(totalPagesFloat))
 
@@ -115,14 +144,18 @@ func (service *DatabaseServiceImpl) PagedByQueryFromSession(models any, session
 	}
 
 	// Conduct unlimited count query to calculate totals
-	var totalRecords int64
-	if err := session.Model(models).Count(&totalRecords).Error; err != nil {
+	totalRecords, estimated, err := service.count(session.Model(models))
+	if err != nil {
 		return pd, err
 	}
 
 	// Calculate offset and conduct limited query
 	offset := (page - 1) * perPage
-	if err := session.Session(&gorm.Session{QueryFields: true}).Limit(perPage).Offset(offset).Find(models).Error; err != nil {
+	session, err = service.order(session.Session(&gorm.Session{QueryFields: true}), models)
+	if err != nil {
+		return pd, err
+	}
+	if err := session.Limit(perPage).Offset(offset).Find(models).Error; err != nil {
 		return pd, err
 	}
 
@@ -130,8 +163,105 @@ func (service *DatabaseServiceImpl) PagedByQueryFromSession(models any, session
 	pd.Page = page
 	pd.PerPage = perPage
 	pd.TotalItems = totalRecords
+	pd.Estimated = estimated
 	totalPagesFloat := float64(totalRecords) / float64(perPage)
 	pd.TotalPages = int(math.Ceil(totalPagesFloat))
 
 	return pd, nil
 }
+
+// count counts the rows tx selects, estimating the count if the table holds more than EstimateCountsOver rows,
+// reporting whether it did.
+func (service *DatabaseServiceImpl) count(tx *gorm.DB) (int64, bool, error) {
+	if service.EstimateCountsOver > 0 {
+		// NOTE: tables that cannot be estimated, e.g., when tx has conditions, are counted exactly.
+		if n, err := EstimatedCount(tx); err == nil && n > service.EstimateCountsOver {
+			return n, true, nil
+		}
+	}
+
+	var n int64
+	return n, false, tx.Count(&n).Error
+}
+
+// order checks tx orders models if RequireOrder is set
+// and orders models by their primary key last if TiebreakOrder is set.
+func (service *DatabaseServiceImpl) order(tx *gorm.DB, models any) (*gorm.DB, error) {
+	if !service.RequireOrder && !service.TiebreakOrder {
+		return tx, nil
+	}
+
+	var orders []clause.OrderByColumn
+	if c, ok := tx.Statement.Clauses["ORDER BY"]; ok {
+		if ob, ok := c.Expression.(clause.OrderBy); ok {
+			orders = ob.Columns
+		}
+	}
+
+	if service.RequireOrder && len(orders) == 0 {
+		return nil, fmt.Errorf("%w: cannot page through %T without an order", trails.ErrNotValid, models)
+	}
+
+	if !service.TiebreakOrder {
+		return tx, nil
+	}
+
+	// NOTE: parse a copy of the statement, so tx still parses models when querying.
+	stmt := &gorm.Statement{DB: tx}
+	if err := stmt.Parse(models); err != nil || stmt.Schema.PrioritizedPrimaryField == nil {
+		return tx, nil
+	}
+
+	pk := stmt.Schema.PrioritizedPrimaryField.DBName
+	for _, o := range orders {
+		if ordersBy(o, pk) {
+			return tx, nil
+		}
+	}
+
+	return tx.Order(clause.OrderByColumn{Column: clause.Column{Table: clause.CurrentTable, Name: pk}}), nil
+}
+
+// ordersBy asserts whether the column o orders by is column,
+// including when o is a raw order, e.g., "created_at DESC, users.id".
+func ordersBy(o clause.OrderByColumn, column string) bool {
+	if !o.Column.Raw {
+		return o.Column.Name == column
+	}
+
+	for _, expr := range strings.Split(o.Column.Name, ",") {
+		fields := strings.Fields(expr)
+		if len(fields) == 0 {
+			continue
+		}
+
+		name := fields[0]
+		if i := strings.LastIndex(name, "."); i >= 0 {
+			name = name[i+1:]
+		}
+
+		if strings.Trim(name, `"`) == column {
+			return true
+		}
+	}
+
+	return false
+}
+
+// RefreshMaterializedView recomputes the results of the materialized view named, e.g., one a View creates.
+// When concurrently is true, reads of the view are not blocked while it is refreshed,
+// which requires a unique index on it; cf. View.UniqueIndex.
+func (service *DatabaseServiceImpl) RefreshMaterializedView(name string, concurrently bool) error {
+	sql := "REFRESH MATERIALIZED VIEW ?"
+	if concurrently {
+		sql = "REFRESH MATERIALIZED VIEW CONCURRENTLY ?"
+	}
+
+	return service.DB.Exec(sql, clause.Table{Name: name}).Error
+}
+
+// Updates receives a database model as a pointer, with its primary key set, and updates the columns in values.
+// values may be keyed by either column or field name.
+func (service *DatabaseServiceImpl) Updates(model any, values map[string]any) error {
+	return service.DB.Model(model).Updates(values).Error
+}
@@ -0,0 +1,111 @@
+package postgres_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/xy-planning-network/trails"
+	"github.com/xy-planning-network/trails/postgres"
+	pg "gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+func TestPagedByQueryOrder(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		service  postgres.DatabaseServiceImpl
+		order    string
+		expected string
+		err      error
+	}{
+		{
+			"Unordered",
+			postgres.DatabaseServiceImpl{},
+			"",
+			`SELECT * FROM "logins" LIMIT 10`,
+			nil,
+		},
+		{
+			"Tiebreak-Unordered",
+			postgres.DatabaseServiceImpl{TiebreakOrder: true},
+			"",
+			`SELECT * FROM "logins" ORDER BY "logins"."id" LIMIT 10`,
+			nil,
+		},
+		{
+			"Tiebreak-Ordered",
+			postgres.DatabaseServiceImpl{TiebreakOrder: true},
+			"user_id desc",
+			`SELECT * FROM "logins" ORDER BY user_id desc,"logins"."id" LIMIT 10`,
+			nil,
+		},
+		{
+			"Tiebreak-Ordered-By-Primary-Key",
+			postgres.DatabaseServiceImpl{TiebreakOrder: true},
+			`user_id, "logins"."id" DESC`,
+			`SELECT * FROM "logins" ORDER BY user_id, "logins"."id" DESC LIMIT 10`,
+			nil,
+		},
+		{
+			"Require-Unordered",
+			postgres.DatabaseServiceImpl{RequireOrder: true},
+			"",
+			"",
+			trails.ErrNotValid,
+		},
+		{
+			"Require-Ordered",
+			postgres.DatabaseServiceImpl{RequireOrder: true},
+			"user_id",
+			`SELECT * FROM "logins" ORDER BY user_id LIMIT 10`,
+			nil,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			l := &sqlLogger{}
+			db, err := gorm.Open(pg.Open("host=localhost"), &gorm.Config{DisableAutomaticPing: true, DryRun: true, Logger: l})
+			require.NoError(t, err)
+
+			service := tc.service
+			service.DB = db
+
+			// Act
+			_, err = service.PagedByQuery(new([]login), "", nil, tc.order, 1, 10)
+
+			// Assert
+			if tc.err != nil {
+				require.ErrorIs(t, err, tc.err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, l.sql[len(l.sql)-1])
+		})
+	}
+}
+
+func TestPagedByQueryFromSessionOrder(t *testing.T) {
+	// Arrange
+	l := &sqlLogger{}
+	db, err := gorm.Open(pg.Open("host=localhost"), &gorm.Config{DisableAutomaticPing: true, DryRun: true, Logger: l})
+	require.NoError(t, err)
+
+	service := postgres.NewService(db)
+	service.RequireOrder = true
+	service.TiebreakOrder = true
+
+	// Act
+	_, err = service.PagedByQueryFromSession(new([]login), db.Where("user_id = ?", 1), 2, 10)
+
+	// Assert
+	require.ErrorIs(t, err, trails.ErrNotValid)
+
+	// Act
+	// NOTE: a dry run never resets the SQL a statement built, so start a new one for counting and finding.
+	_, err = service.PagedByQueryFromSession(new([]login), db.Where("user_id = ?", 1).Order("user_id").Session(&gorm.Session{}), 2, 10)
+
+	// Assert
+	require.NoError(t, err)
+	require.Equal(t, `SELECT "logins"."id","logins"."user_id" FROM "logins" WHERE user_id = 1 ORDER BY user_id,"logins"."id" LIMIT 10 OFFSET 10`, l.sql[len(l.sql)-1])
+}
@@ -0,0 +1,90 @@
+package postgres
+
+import (
+	"fmt"
+
+	"github.com/xy-planning-network/trails"
+	"gorm.io/gorm"
+)
+
+// DefaultStmtCacheSize is how many prepared statements a StmtCache keeps when its Size is not positive.
+const DefaultStmtCacheSize = 256
+
+// A StmtCache is a gorm plugin bounding how many prepared statements
+// a *gorm.DB opened with gorm.Config.PrepareStmt keeps:
+//
+//	db, err := gorm.Open(dialector, &gorm.Config{PrepareStmt: true})
+//	err = db.Use(postgres.StmtCache{Size: 500})
+//
+// Preparing statements spares Postgres parsing and planning the same query every time it is executed,
+// but gorm keeps every statement it prepares for as long as the *gorm.DB is open,
+// and Postgres keeps each on every connection it is executed on.
+// Queries built from varying input, e.g., IN clauses over lists of different lengths,
+// can prepare statements without end.
+// Once more than Size are kept, a StmtCache closes the oldest prepared.
+//
+// Connect uses a StmtCache when CxnConfig.PrepareStmt is true.
+type StmtCache struct {
+	// Size is the most prepared statements kept for the connection pool.
+	//
+	// If Size is not positive, DefaultStmtCacheSize is used.
+	Size int
+}
+
+// Name names the plugin.
+func (StmtCache) Name() string { return "trails:stmt_cache" }
+
+// Initialize registers the callbacks evicting prepared statements after executing statements,
+// failing with trails.ErrBadConfig if db does not prepare statements.
+func (c StmtCache) Initialize(db *gorm.DB) error {
+	stmts, ok := db.ConnPool.(*gorm.PreparedStmtDB)
+	if !ok {
+		return fmt.Errorf("%w: %s requires gorm.Config.PrepareStmt", trails.ErrBadConfig, c.Name())
+	}
+
+	size := c.Size
+	if size <= 0 {
+		size = DefaultStmtCacheSize
+	}
+
+	evict := func(*gorm.DB) { evictStmts(stmts, size) }
+
+	cb := db.Callback()
+	for _, op := range []struct {
+		name  string
+		after registrar
+	}{
+		{"create", cb.Create().After("gorm:create")},
+		{"delete", cb.Delete().After("gorm:delete")},
+		{"query", cb.Query().After("gorm:query")},
+		{"raw", cb.Raw().After("gorm:raw")},
+		{"row", cb.Row().After("gorm:row")},
+		{"update", cb.Update().After("gorm:update")},
+	} {
+		if err := op.after.Register(c.Name()+":after_"+op.name, evict); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// evictStmts closes the oldest statements stmts prepared until it keeps no more than size.
+//
+// NOTE: database/sql finishes closing a statement once rows read from it are closed.
+func evictStmts(stmts *gorm.PreparedStmtDB, size int) {
+	stmts.Mux.Lock()
+	defer stmts.Mux.Unlock()
+
+	for len(stmts.Stmts) > size && len(stmts.PreparedSQL) > 0 {
+		query := stmts.PreparedSQL[0]
+		stmts.PreparedSQL = stmts.PreparedSQL[1:]
+
+		// NOTE: statements still being prepared are not yet in PreparedSQL,
+		// nor are those failing to execute, which gorm already closed, still in Stmts.
+		if stmt, ok := stmts.Stmts[query]; ok && stmt.Stmt != nil {
+			delete(stmts.Stmts, query)
+			go stmt.Close()
+		}
+	}
+}
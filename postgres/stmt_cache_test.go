@@ -0,0 +1,113 @@
+package postgres_test
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/xy-planning-network/trails"
+	"github.com/xy-planning-network/trails/postgres"
+	pg "gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// stmtDriver is a database/sql driver counting the statements its connections prepare and close,
+// which execute without doing anything.
+type stmtDriver struct{ prepared, closed atomic.Int64 }
+
+func (d *stmtDriver) Open(string) (driver.Conn, error) { return stmtConn{d}, nil }
+
+type stmtConn struct{ d *stmtDriver }
+
+func (c stmtConn) Prepare(string) (driver.Stmt, error) {
+	c.d.prepared.Add(1)
+	return stmtStmt(c), nil
+}
+
+func (stmtConn) Close() error              { return nil }
+func (stmtConn) Begin() (driver.Tx, error) { return nil, errors.New("not implemented") }
+
+type stmtStmt struct{ d *stmtDriver }
+
+func (s stmtStmt) Close() error                             { s.d.closed.Add(1); return nil }
+func (stmtStmt) NumInput() int                              { return -1 }
+func (stmtStmt) Exec([]driver.Value) (driver.Result, error) { return driver.RowsAffected(1), nil }
+func (stmtStmt) Query([]driver.Value) (driver.Rows, error)  { return nil, errors.New("not implemented") }
+
+var stmtDrivers atomic.Int64
+
+// openStmtDB opens a *gorm.DB on a new stmtDriver.
+func openStmtDB(t testing.TB, prepare bool) (*gorm.DB, *stmtDriver) {
+	d := new(stmtDriver)
+	name := fmt.Sprintf("trails-stmt-%d", stmtDrivers.Add(1))
+	sql.Register(name, d)
+
+	db, err := gorm.Open(pg.New(pg.Config{DriverName: name}), &gorm.Config{
+		DisableAutomaticPing: true,
+		Logger:               &sqlLogger{},
+		PrepareStmt:          prepare,
+	})
+	require.NoError(t, err)
+
+	return db, d
+}
+
+func TestStmtCache(t *testing.T) {
+	// Arrange
+	db, d := openStmtDB(t, true)
+	require.NoError(t, db.Use(postgres.StmtCache{Size: 2}))
+
+	// Act
+	for i := range 5 {
+		require.NoError(t, db.Exec(fmt.Sprintf("SELECT %d", i)).Error)
+	}
+	require.NoError(t, db.Exec("SELECT 4").Error)
+
+	// Assert
+	stmts := db.ConnPool.(*gorm.PreparedStmtDB)
+	require.Len(t, stmts.Stmts, 2)
+	require.Contains(t, stmts.Stmts, "SELECT 3")
+	require.Contains(t, stmts.Stmts, "SELECT 4")
+	require.EqualValues(t, 5, d.prepared.Load())
+	require.Eventually(t, func() bool { return d.closed.Load() == 3 }, time.Second, time.Millisecond)
+}
+
+func TestStmtCacheUnprepared(t *testing.T) {
+	// Arrange
+	db, _ := openStmtDB(t, false)
+
+	// Act
+	err := db.Use(postgres.StmtCache{})
+
+	// Assert
+	require.ErrorIs(t, err, trails.ErrBadConfig)
+}
+
+func BenchmarkStmtCache(b *testing.B) {
+	for _, bc := range []struct {
+		name    string
+		prepare bool
+	}{
+		{"Unprepared", false},
+		{"Prepared", true},
+	} {
+		b.Run(bc.name, func(b *testing.B) {
+			db, d := openStmtDB(b, bc.prepare)
+			if bc.prepare {
+				require.NoError(b, db.Use(postgres.StmtCache{}))
+			}
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				db.Exec(`UPDATE "logins" SET "user_id"=$1 WHERE "id" = $2`, i, i)
+			}
+
+			b.ReportMetric(float64(d.prepared.Load())/float64(b.N), "prepares/op")
+		})
+	}
+}
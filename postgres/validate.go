@@ -0,0 +1,75 @@
+package postgres
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/xy-planning-network/trails"
+	"gorm.io/gorm"
+)
+
+// A ColumnValidator is a gorm plugin checking the keys of maps a model is created or updated with,
+// e.g., with *gorm.DB's Create, Update, or Updates or DatabaseServiceImpl.Updates,
+// against the columns and fields of the model, before querying the database:
+//
+//	err := db.Use(postgres.ColumnValidator{})
+//
+// A map with keys naming neither fails with trails.ErrNotValid listing them,
+// rather than an error from Postgres after the fact.
+//
+// Connect uses a ColumnValidator when CxnConfig.ValidateColumns is true.
+type ColumnValidator struct{}
+
+// Name names the plugin.
+func (ColumnValidator) Name() string { return "trails:validate_columns" }
+
+// Initialize registers the callbacks validating columns before creating or updating.
+func (v ColumnValidator) Initialize(db *gorm.DB) error {
+	if err := db.Callback().Create().Before("gorm:create").Register(v.Name(), validateColumns); err != nil {
+		return err
+	}
+
+	return db.Callback().Update().Before("gorm:update").Register(v.Name(), validateColumns)
+}
+
+// validateColumns adds trails.ErrNotValid to tx if it creates or updates its model
+// with maps keyed by anything other than its columns or fields.
+func validateColumns(tx *gorm.DB) {
+	if tx.Error != nil || tx.Statement.Schema == nil {
+		return
+	}
+
+	var maps []map[string]any
+	switch dest := tx.Statement.Dest.(type) {
+	case map[string]any:
+		maps = append(maps, dest)
+	case *map[string]any:
+		maps = append(maps, *dest)
+	case []map[string]any:
+		maps = dest
+	case *[]map[string]any:
+		maps = *dest
+	default:
+		return
+	}
+
+	var unknown []string
+	for _, m := range maps {
+		for key := range m {
+			if tx.Statement.Schema.LookUpField(key) == nil && !slices.Contains(unknown, key) {
+				unknown = append(unknown, key)
+			}
+		}
+	}
+
+	if len(unknown) == 0 {
+		return
+	}
+
+	slices.Sort(unknown)
+	tx.AddError(fmt.Errorf(
+		"%w: %s has no columns %s",
+		trails.ErrNotValid, tx.Statement.Schema.Table, strings.Join(unknown, ", "),
+	))
+}
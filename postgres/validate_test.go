@@ -0,0 +1,98 @@
+package postgres_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/xy-planning-network/trails"
+	"github.com/xy-planning-network/trails/postgres"
+	pg "gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+func TestColumnValidator(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		exec  func(*gorm.DB) error
+		valid bool
+	}{
+		{
+			"Updates-Columns",
+			func(db *gorm.DB) error {
+				return db.Model(&login{ID: 1}).Updates(map[string]any{"user_id": 2}).Error
+			},
+			true,
+		},
+		{
+			"Updates-Fields",
+			func(db *gorm.DB) error {
+				return db.Model(&login{ID: 1}).Updates(map[string]any{"UserID": 2}).Error
+			},
+			true,
+		},
+		{
+			"Updates-Unknown",
+			func(db *gorm.DB) error {
+				return db.Model(&login{ID: 1}).Updates(map[string]any{"usr_id": 2, "UserID": 2}).Error
+			},
+			false,
+		},
+		{
+			"Update-Unknown",
+			func(db *gorm.DB) error {
+				return db.Model(&login{ID: 1}).Update("usr_id", 2).Error
+			},
+			false,
+		},
+		{
+			"Service-Updates-Unknown",
+			func(db *gorm.DB) error {
+				return postgres.NewService(db).Updates(&login{ID: 1}, map[string]any{"usr_id": 2})
+			},
+			false,
+		},
+		{
+			"Create-Unknown",
+			func(db *gorm.DB) error {
+				return db.Model(&login{}).Create([]map[string]any{{"user_id": 1}, {"usr_id": 2}}).Error
+			},
+			false,
+		},
+		{
+			"Struct",
+			func(db *gorm.DB) error {
+				return db.Create(&login{UserID: 1}).Error
+			},
+			true,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			l := &sqlLogger{}
+			// NOTE: skip the transaction creating and updating run in, which connects to the database.
+			db, err := gorm.Open(pg.Open("host=localhost"), &gorm.Config{
+				DisableAutomaticPing:   true,
+				DryRun:                 true,
+				Logger:                 l,
+				SkipDefaultTransaction: true,
+			})
+			require.NoError(t, err)
+			require.NoError(t, db.Use(postgres.ColumnValidator{}))
+
+			// Act
+			err = tc.exec(db)
+
+			// Assert
+			if tc.valid {
+				require.NoError(t, err)
+				require.Len(t, l.sql, 1)
+				return
+			}
+
+			require.ErrorIs(t, err, trails.ErrNotValid)
+			require.ErrorContains(t, err, "usr_id")
+			require.NotContains(t, err.Error(), "UserID")
+			require.Empty(t, l.sql)
+		})
+	}
+}
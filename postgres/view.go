@@ -0,0 +1,92 @@
+package postgres
+
+import (
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// A View is a SQL view, or a materialized view, whose definition is managed by Migrations,
+// e.g., keeping a reporting query in the database rather than inline in Go.
+//
+// Its methods are Migration Executors:
+//
+//	report := postgres.View{Name: "monthly_signups", Query: "SELECT date_trunc('month', created_at) AS month, count(*) FROM users GROUP BY 1"}
+//	migrations := []postgres.Migration{{Key: "20240601_monthly_signups", Executor: report.Create}}
+//
+// To change the View later, change Query and add a Migration with Executor: report.Replace.
+type View struct {
+	// Materialized stores the results of Query, which RefreshMaterializedView recomputes.
+	Materialized bool
+
+	// Name is the name of the view.
+	Name string
+
+	// Query is the SELECT statement defining the view.
+	// Query must not include bind variables.
+	Query string
+
+	// UniqueIndex names the columns a materialized view is uniquely indexed on,
+	// which RefreshMaterializedView requires to refresh it concurrently.
+	UniqueIndex []string
+}
+
+// Create creates the View.
+func (v View) Create(tx *gorm.DB) error {
+	if err := tx.Exec("CREATE " + v.kind() + " " + tx.Statement.Quote(clause.Table{Name: v.Name}) + " AS " + v.Query).Error; err != nil {
+		return err
+	}
+
+	if !v.Materialized || len(v.UniqueIndex) == 0 {
+		return nil
+	}
+
+	cols := make([]string, len(v.UniqueIndex))
+	for i, col := range v.UniqueIndex {
+		cols[i] = tx.Statement.Quote(clause.Column{Name: col})
+	}
+
+	return tx.Exec(
+		"CREATE UNIQUE INDEX " + tx.Statement.Quote(clause.Column{Name: v.Name + "_unique_idx"}) +
+			" ON " + tx.Statement.Quote(clause.Table{Name: v.Name}) + " (" + strings.Join(cols, ", ") + ")",
+	).Error
+}
+
+// Drop drops the View, if it exists.
+func (v View) Drop(tx *gorm.DB) error {
+	return tx.Exec("DROP " + v.kind() + " IF EXISTS " + tx.Statement.Quote(clause.Table{Name: v.Name})).Error
+}
+
+// Replace drops and creates the View again from its current definition,
+// since neither a materialized view nor a view's columns can be altered in place.
+// Views depending on the View must be replaced in the same Migration.
+func (v View) Replace(tx *gorm.DB) error {
+	if err := v.Drop(tx); err != nil {
+		return err
+	}
+
+	return v.Create(tx)
+}
+
+// kind is the kind of view the View is in SQL.
+func (v View) kind() string {
+	if v.Materialized {
+		return "MATERIALIZED VIEW"
+	}
+
+	return "VIEW"
+}
+
+// AddGeneratedColumn returns a Migration Executor adding a column of type typ to table,
+// generated from the SQL expression expr, referring to other columns of the row.
+//
+//	postgres.Migration{Key: "20240601_users_full_name", Executor: postgres.AddGeneratedColumn("users", "full_name", "text", "first_name || ' ' || last_name")}
+func AddGeneratedColumn(table, column, typ, expr string) func(*gorm.DB) error {
+	return func(tx *gorm.DB) error {
+		return tx.Exec(
+			"ALTER TABLE ? ADD COLUMN ? "+typ+" GENERATED ALWAYS AS ("+expr+") STORED",
+			clause.Table{Name: table}, clause.Column{Name: column},
+		).Error
+	}
+}
@@ -0,0 +1,101 @@
+package postgres_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/xy-planning-network/trails/postgres"
+	pg "gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+func TestView(t *testing.T) {
+	query := "SELECT user_id, count(*) AS logins FROM logins GROUP BY user_id"
+
+	for _, tc := range []struct {
+		name     string
+		view     postgres.View
+		exec     func(postgres.View) func(*gorm.DB) error
+		expected []string
+	}{
+		{
+			"Create",
+			postgres.View{Name: "login_counts", Query: query},
+			func(v postgres.View) func(*gorm.DB) error { return v.Create },
+			[]string{`CREATE VIEW "login_counts" AS ` + query},
+		},
+		{
+			"Create-Materialized",
+			postgres.View{Materialized: true, Name: "login_counts", Query: query, UniqueIndex: []string{"user_id"}},
+			func(v postgres.View) func(*gorm.DB) error { return v.Create },
+			[]string{
+				`CREATE MATERIALIZED VIEW "login_counts" AS ` + query,
+				`CREATE UNIQUE INDEX "login_counts_unique_idx" ON "login_counts" ("user_id")`,
+			},
+		},
+		{
+			"Drop",
+			postgres.View{Name: "login_counts", Query: query},
+			func(v postgres.View) func(*gorm.DB) error { return v.Drop },
+			[]string{`DROP VIEW IF EXISTS "login_counts"`},
+		},
+		{
+			"Replace-Materialized",
+			postgres.View{Materialized: true, Name: "login_counts", Query: query},
+			func(v postgres.View) func(*gorm.DB) error { return v.Replace },
+			[]string{
+				`DROP MATERIALIZED VIEW IF EXISTS "login_counts"`,
+				`CREATE MATERIALIZED VIEW "login_counts" AS ` + query,
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			l := &sqlLogger{}
+			db, err := gorm.Open(pg.Open("host=localhost"), &gorm.Config{DisableAutomaticPing: true, DryRun: true, Logger: l})
+			require.NoError(t, err)
+
+			// Act
+			err = tc.exec(tc.view)(db)
+
+			// Assert
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, l.sql)
+		})
+	}
+}
+
+func TestRefreshMaterializedView(t *testing.T) {
+	// Arrange
+	l := &sqlLogger{}
+	db, err := gorm.Open(pg.Open("host=localhost"), &gorm.Config{DisableAutomaticPing: true, DryRun: true, Logger: l})
+	require.NoError(t, err)
+
+	service := postgres.NewService(db)
+
+	// Act
+	require.NoError(t, service.RefreshMaterializedView("login_counts", false))
+	require.NoError(t, service.RefreshMaterializedView("login_counts", true))
+
+	// Assert
+	require.Equal(t, []string{
+		`REFRESH MATERIALIZED VIEW "login_counts"`,
+		`REFRESH MATERIALIZED VIEW CONCURRENTLY "login_counts"`,
+	}, l.sql)
+}
+
+func TestAddGeneratedColumn(t *testing.T) {
+	// Arrange
+	l := &sqlLogger{}
+	db, err := gorm.Open(pg.Open("host=localhost"), &gorm.Config{DisableAutomaticPing: true, DryRun: true, Logger: l})
+	require.NoError(t, err)
+
+	// Act
+	err = postgres.AddGeneratedColumn("users", "full_name", "text", "first_name || ' ' || last_name")(db)
+
+	// Assert
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		`ALTER TABLE "users" ADD COLUMN "full_name" text GENERATED ALWAYS AS (first_name || ' ' || last_name) STORED`,
+	}, l.sql)
+}
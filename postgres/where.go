@@ -0,0 +1,40 @@
+package postgres
+
+import (
+	"database/sql/driver"
+
+	"gorm.io/gorm/clause"
+)
+
+// WhereNull matches rows whose column is NULL.
+//
+// WhereNull, WhereNotNull, and WhereAny compose with *gorm.DB's Where, Or, and Not:
+//
+//	db.Where(postgres.WhereNull("deleted_at")).Or(postgres.WhereAny("id", ids)).Find(&users)
+func WhereNull(column string) clause.Expression {
+	return clause.Expr{SQL: "? IS NULL", Vars: []any{clause.Column{Name: column}}}
+}
+
+// WhereNotNull matches rows whose column is not NULL.
+func WhereNotNull(column string) clause.Expression {
+	return clause.Expr{SQL: "? IS NOT NULL", Vars: []any{clause.Column{Name: column}}}
+}
+
+// WhereAny matches rows whose column equals any of vals, using "= ANY(?)" with vals as a single array parameter,
+// so the query is the same no matter how many vals there are.
+//
+// If vals is empty, WhereAny matches no rows.
+func WhereAny[T any](column string, vals []T) clause.Expression {
+	if vals == nil {
+		vals = []T{}
+	}
+
+	return clause.Expr{SQL: "? = ANY(?)", Vars: []any{clause.Column{Name: column}, array[T](vals)}}
+}
+
+// An array passes a slice as a single Postgres array parameter,
+// rather than gorm expanding it into a list of parameters.
+type array[T any] []T
+
+// Value returns the slice for the driver to encode as an array.
+func (a array[T]) Value() (driver.Value, error) { return []T(a), nil }
@@ -0,0 +1,59 @@
+package postgres_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/xy-planning-network/trails/postgres"
+	pg "gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+type widget struct {
+	ID uint
+}
+
+func TestWhere(t *testing.T) {
+	db, err := gorm.Open(pg.Open("host=localhost"), &gorm.Config{DisableAutomaticPing: true, DryRun: true})
+	require.NoError(t, err)
+
+	for _, tc := range []struct {
+		name     string
+		scope    func(tx *gorm.DB) *gorm.DB
+		expected string
+	}{
+		{
+			"Null",
+			func(tx *gorm.DB) *gorm.DB { return tx.Where(postgres.WhereNull("widgets.deleted_at")) },
+			`SELECT * FROM "widgets" WHERE "widgets"."deleted_at" IS NULL`,
+		},
+		{
+			"Not-Null",
+			func(tx *gorm.DB) *gorm.DB { return tx.Where(postgres.WhereNotNull("name")) },
+			`SELECT * FROM "widgets" WHERE "name" IS NOT NULL`,
+		},
+		{
+			"Any",
+			func(tx *gorm.DB) *gorm.DB { return tx.Where(postgres.WhereAny("id", []int{1, 2, 3})) },
+			`SELECT * FROM "widgets" WHERE "id" = ANY('[1 2 3]')`,
+		},
+		{
+			"Or",
+			func(tx *gorm.DB) *gorm.DB {
+				return tx.Where("name = ?", "a").Or(postgres.WhereNull("name"))
+			},
+			`SELECT * FROM "widgets" WHERE name = 'a' OR "name" IS NULL`,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			// Act
+			actual := db.ToSQL(func(tx *gorm.DB) *gorm.DB {
+				var ws []widget
+				return tc.scope(tx).Find(&ws)
+			})
+
+			// Assert
+			require.Equal(t, tc.expected, actual)
+		})
+	}
+}
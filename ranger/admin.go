@@ -0,0 +1,591 @@
+package ranger
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/xy-planning-network/trails"
+	"github.com/xy-planning-network/trails/http/middleware"
+	"github.com/xy-planning-network/trails/http/resp"
+	"github.com/xy-planning-network/trails/http/router"
+	"github.com/xy-planning-network/trails/http/session"
+	"github.com/xy-planning-network/trails/postgres"
+	"gorm.io/gorm"
+)
+
+const (
+	// AdminPath is the path the admin UI is served under.
+	AdminPath = "/admin"
+
+	// AdminRole is the role a user must be granted to use the admin UI; cf. [middleware.RoledUser].
+	AdminRole = "admin"
+
+	// DefaultAdminPerPage is how many records the admin UI lists per page by default.
+	DefaultAdminPerPage = 25
+
+	adminTmplDir   = "tmpl/admin"
+	adminEditTmpl  = adminTmplDir + "/edit.tmpl"
+	adminIndexTmpl = adminTmplDir + "/index.tmpl"
+	adminListTmpl  = adminTmplDir + "/list.tmpl"
+	adminNavTmpl   = adminTmplDir + "/nav.tmpl"
+	adminShowTmpl  = adminTmplDir + "/show.tmpl"
+
+	// adminTimeLayout is how the admin UI shows and reads times, as a datetime-local input does.
+	adminTimeLayout = "2006-01-02T15:04"
+)
+
+// AdminOpts configures how the admin UI lists, shows, and edits a model.
+type AdminOpts struct {
+	// Columns names the fields listed, by Go field name.
+	// The default is every exported field holding a bool, number, string, or time.Time.
+	Columns []string
+
+	// Editable names the fields that can be edited, by Go field name.
+	// The default is none, leaving the model read-only.
+	Editable []string
+
+	// Order orders the listing, e.g., "created_at DESC". The default is "id".
+	Order string
+
+	// PerPage is how many records are listed per page. The default is DefaultAdminPerPage.
+	PerPage int
+
+	// Slug is the path segment the model is served under, e.g., "/admin/users".
+	// The default is the model's type name, lowercased, with an "s" appended.
+	Slug string
+
+	// Title names the model in pages. The default is the model's type name.
+	Title string
+}
+
+// An Admin serves pages listing, showing, and editing the models registered with it
+// to users granted [AdminRole], under [AdminPath].
+type Admin struct {
+	db     postgres.DatabaseService
+	d      *resp.Responder
+	models []*adminModel
+	router router.Router
+}
+
+// Admin returns the admin UI, serving an index of the models registered with it at [AdminPath].
+// The admin UI renders pages with the authenticated layout, so the current user must be a [middleware.RoledUser].
+func (r *Ranger) Admin() *Admin {
+	if r.admin != nil {
+		return r.admin
+	}
+
+	r.admin = &Admin{db: r.db, d: r.Responder, router: r.Router}
+	r.Router.Get(AdminPath, r.admin.handleIndex, middleware.RequireRole(r.Responder, AdminRole))
+
+	return r.admin
+}
+
+// Register serves pages for model, a struct or pointer to one with an ID field, as a gorm model would have:
+//   - GET /admin/{slug} lists records, a page at a time
+//   - GET /admin/{slug}/{id} shows a record
+//   - GET /admin/{slug}/{id}/edit shows a form editing the record, if any fields are editable
+//   - POST /admin/{slug}/{id} updates the record from that form
+//
+// Register returns trails.ErrNotValid if model or opts cannot be served,
+// e.g., a field named is not exported or cannot be edited from a form.
+func (a *Admin) Register(model any, opts AdminOpts) error {
+	if a.db == nil {
+		return fmt.Errorf("%w: no database to administer models with", trails.ErrBadConfig)
+	}
+
+	m, err := newAdminModel(model, opts)
+	if err != nil {
+		return err
+	}
+
+	for _, registered := range a.models {
+		if registered.Slug == m.Slug {
+			return fmt.Errorf("%w: %q is already registered", trails.ErrNotValid, m.Slug)
+		}
+	}
+
+	a.models = append(a.models, m)
+
+	mw := middleware.RequireRole(a.d, AdminRole)
+	routes := []router.Route{
+		{Path: m.url(), Method: http.MethodGet, Handler: a.handleList(m)},
+		{Path: m.url() + "/{id:[0-9]+}", Method: http.MethodGet, Handler: a.handleShow(m)},
+	}
+
+	if len(m.Editable) > 0 {
+		routes = append(routes,
+			router.Route{Path: m.url() + "/{id:[0-9]+}/edit", Method: http.MethodGet, Handler: a.handleEdit(m)},
+			router.Route{Path: m.url() + "/{id:[0-9]+}", Method: http.MethodPost, Handler: a.handleUpdate(m)},
+		)
+	}
+
+	a.router.HandleRoutes(routes, mw)
+
+	return nil
+}
+
+// handleIndex lists the models registered.
+func (a *Admin) handleIndex(w http.ResponseWriter, r *http.Request) {
+	a.render(w, r, adminIndexTmpl, adminPage{Nav: a.nav(), Title: "Admin"})
+}
+
+// handleList lists a page of m's records.
+func (a *Admin) handleList(m *adminModel) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		page, err := strconv.Atoi(r.URL.Query().Get("page"))
+		if err != nil || page < 1 {
+			page = 1
+		}
+
+		records := reflect.New(reflect.SliceOf(m.typ))
+		pd, err := a.db.PagedByQuery(records.Interface(), "", nil, m.Order, page, m.PerPage)
+		if err != nil {
+			a.d.Err(w, r, err)
+			return
+		}
+
+		p := adminPage{
+			Columns: make([]string, len(m.Columns)),
+			Model:   m.Title,
+			Nav:     a.nav(),
+			Title:   m.Title,
+			URL:     m.url(),
+		}
+		for i, name := range m.Columns {
+			p.Columns[i] = adminLabel(name)
+		}
+
+		records = records.Elem()
+		for i := range records.Len() {
+			v := reflect.Indirect(records.Index(i))
+			row := adminRow{ID: m.id(v)}
+			row.URL = m.url() + "/" + row.ID
+			for _, name := range m.Columns {
+				row.Cells = append(row.Cells, adminFormat(m.field(v, name)))
+			}
+
+			p.Rows = append(p.Rows, row)
+		}
+
+		if pd.Page > 1 {
+			p.PrevURL = fmt.Sprintf("%s?page=%d", m.url(), pd.Page-1)
+		}
+		if pd.Page < pd.TotalPages {
+			p.NextURL = fmt.Sprintf("%s?page=%d", m.url(), pd.Page+1)
+		}
+		p.Page, p.TotalPages = pd.Page, pd.TotalPages
+
+		a.render(w, r, adminListTmpl, p)
+	}
+}
+
+// handleShow shows every field of one of m's records.
+func (a *Admin) handleShow(m *adminModel) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		v, ok := a.find(w, r, m)
+		if !ok {
+			return
+		}
+
+		p := m.page(a.nav(), v)
+		p.Fields = m.fields(v, nil)
+
+		a.render(w, r, adminShowTmpl, p)
+	}
+}
+
+// handleEdit shows a form editing m's editable fields of one of its records.
+func (a *Admin) handleEdit(m *adminModel) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		v, ok := a.find(w, r, m)
+		if !ok {
+			return
+		}
+
+		p := m.page(a.nav(), v)
+		p.Fields = m.fields(v, m.Editable)
+
+		a.render(w, r, adminEditTmpl, p)
+	}
+}
+
+// handleUpdate updates one of m's records from the form handleEdit shows.
+func (a *Admin) handleUpdate(m *adminModel) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		v, ok := a.find(w, r, m)
+		if !ok {
+			return
+		}
+
+		recordURL := m.url() + "/" + m.id(v)
+		if err := r.ParseForm(); err != nil {
+			a.redirect(w, r, recordURL+"/edit", session.FlashError, session.BadInputMsg)
+			return
+		}
+
+		values := make(map[string]any, len(m.Editable))
+		for _, name := range m.Editable {
+			val, err := adminParse(m.fieldType(name), r.PostForm.Get(name))
+			if err != nil {
+				a.redirect(w, r, recordURL+"/edit", session.FlashError, session.BadInputMsg)
+				return
+			}
+
+			values[name] = val
+		}
+
+		if err := a.db.Updates(v.Addr().Interface(), values); err != nil {
+			a.d.Err(w, r, err)
+			return
+		}
+
+		a.redirect(w, r, recordURL, session.FlashSuccess, fmt.Sprintf("%s %s saved.", m.Title, m.id(v)))
+	}
+}
+
+// find retrieves the record of m identified in the request's path,
+// responding with 404 and false if there is none.
+func (a *Admin) find(w http.ResponseWriter, r *http.Request, m *adminModel) (reflect.Value, bool) {
+	id, err := router.Param[uint](r, "id")
+	if err != nil {
+		http.NotFound(w, r)
+		return reflect.Value{}, false
+	}
+
+	record := reflect.New(m.typ)
+	if err := a.db.FindByID(record.Interface(), id); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			http.NotFound(w, r)
+			return reflect.Value{}, false
+		}
+
+		a.d.Err(w, r, err)
+		return reflect.Value{}, false
+	}
+
+	return record.Elem(), true
+}
+
+// nav links to the index of every model registered.
+func (a *Admin) nav() []adminLink {
+	links := make([]adminLink, len(a.models))
+	for i, m := range a.models {
+		links[i] = adminLink{Title: m.Title, URL: m.url()}
+	}
+
+	return links
+}
+
+// redirect redirects to url, flashing msg.
+func (a *Admin) redirect(w http.ResponseWriter, r *http.Request, url, flashType, msg string) {
+	if err := a.d.Redirect(w, r, resp.SeeOther(url, session.Flash{Type: flashType, Msg: msg})); err != nil {
+		a.d.Err(w, r, err)
+	}
+}
+
+// render renders tmpl with p in the authenticated layout.
+func (a *Admin) render(w http.ResponseWriter, r *http.Request, tmpl string, p adminPage) {
+	_ = a.d.Html(w, r, resp.Authed(), resp.Tmpls(tmpl, adminNavTmpl), resp.Data(p))
+}
+
+// An adminPage is the data the admin templates render.
+type adminPage struct {
+	Columns    []string
+	Editable   bool
+	Fields     []adminField
+	ID         string
+	Model      string
+	Nav        []adminLink
+	NextURL    string
+	Page       int
+	PrevURL    string
+	Rows       []adminRow
+	Title      string
+	TotalPages int
+	URL        string
+}
+
+// An adminField is a field of a record shown or edited.
+type adminField struct {
+	Input string
+	Label string
+	Name  string
+	Value string
+}
+
+// An adminLink links to a page of the admin UI.
+type adminLink struct {
+	Title string
+	URL   string
+}
+
+// An adminRow is a record listed.
+type adminRow struct {
+	Cells []string
+	ID    string
+	URL   string
+}
+
+// An adminModel is a model registered with an Admin.
+type adminModel struct {
+	AdminOpts
+	typ reflect.Type
+}
+
+// newAdminModel validates model and opts, applying defaults to opts.
+func newAdminModel(model any, opts AdminOpts) (*adminModel, error) {
+	typ := reflect.TypeOf(model)
+	if typ != nil && typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+
+	if typ == nil || typ.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("%w: %T is not a struct", trails.ErrNotValid, model)
+	}
+
+	m := &adminModel{AdminOpts: opts, typ: typ}
+	if _, ok := m.lookup("ID"); !ok {
+		return nil, fmt.Errorf("%w: %s has no ID field", trails.ErrNotValid, typ)
+	}
+
+	if m.Columns == nil {
+		for _, f := range reflect.VisibleFields(typ) {
+			if f.IsExported() && !f.Anonymous && adminInput(f.Type) != "" {
+				m.Columns = append(m.Columns, f.Name)
+			}
+		}
+	}
+
+	for _, name := range m.Columns {
+		if _, ok := m.lookup(name); !ok {
+			return nil, fmt.Errorf("%w: %s has no exported field %q", trails.ErrNotValid, typ, name)
+		}
+	}
+
+	for _, name := range m.Editable {
+		f, ok := m.lookup(name)
+		if !ok {
+			return nil, fmt.Errorf("%w: %s has no exported field %q", trails.ErrNotValid, typ, name)
+		}
+
+		if name == "ID" || adminInput(f.Type) == "" {
+			return nil, fmt.Errorf("%w: %s.%s cannot be edited", trails.ErrNotValid, typ, name)
+		}
+	}
+
+	if m.Order == "" {
+		m.Order = "id"
+	}
+	if m.PerPage < 1 {
+		m.PerPage = DefaultAdminPerPage
+	}
+	if m.Slug == "" {
+		m.Slug = strings.ToLower(typ.Name()) + "s"
+	}
+	if m.Title == "" {
+		m.Title = typ.Name()
+	}
+
+	return m, nil
+}
+
+// field retrieves the field named from v, returning the zero Value if an embedded pointer is nil.
+func (m *adminModel) field(v reflect.Value, name string) reflect.Value {
+	f, _ := m.lookup(name)
+	fv, err := v.FieldByIndexErr(f.Index)
+	if err != nil {
+		return reflect.Value{}
+	}
+
+	return fv
+}
+
+// fieldType returns the type of the field named.
+func (m *adminModel) fieldType(name string) reflect.Type {
+	f, _ := m.lookup(name)
+	return f.Type
+}
+
+// fields describes the fields named of the record v, or every field listed if names is nil.
+func (m *adminModel) fields(v reflect.Value, names []string) []adminField {
+	if names == nil {
+		names = m.Columns
+	}
+
+	fields := make([]adminField, len(names))
+	for i, name := range names {
+		ft := m.fieldType(name)
+		fields[i] = adminField{Input: adminInput(ft), Label: adminLabel(name), Name: name, Value: adminFormat(m.field(v, name))}
+	}
+
+	return fields
+}
+
+// id formats the ID of the record v.
+func (m *adminModel) id(v reflect.Value) string {
+	return adminFormat(m.field(v, "ID"))
+}
+
+// lookup finds the exported field named in m's type.
+func (m *adminModel) lookup(name string) (reflect.StructField, bool) {
+	f, ok := m.typ.FieldByName(name)
+	if !ok || !f.IsExported() {
+		return reflect.StructField{}, false
+	}
+
+	return f, true
+}
+
+// page begins the adminPage for the record v.
+func (m *adminModel) page(nav []adminLink, v reflect.Value) adminPage {
+	id := m.id(v)
+	return adminPage{
+		Editable: len(m.Editable) > 0,
+		ID:       id,
+		Model:    m.Title,
+		Nav:      nav,
+		Title:    m.Title + " " + id,
+		URL:      m.url() + "/" + id,
+	}
+}
+
+// url is the path m's records are listed at.
+func (m *adminModel) url() string {
+	return AdminPath + "/" + m.Slug
+}
+
+// adminFormat formats v for showing in a page.
+func adminFormat(v reflect.Value) string {
+	for v.IsValid() && v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return ""
+		}
+
+		v = v.Elem()
+	}
+
+	if !v.IsValid() {
+		return ""
+	}
+
+	if t, ok := v.Interface().(time.Time); ok {
+		if t.IsZero() {
+			return ""
+		}
+
+		return t.Format(adminTimeLayout)
+	}
+
+	return fmt.Sprint(v.Interface())
+}
+
+// adminInput chooses the type of the input editing a field of type t, or none if it cannot be edited.
+func adminInput(t reflect.Type) string {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	if t == reflect.TypeFor[time.Time]() {
+		return "datetime-local"
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return "checkbox"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.String:
+		return "text"
+	default:
+		return ""
+	}
+}
+
+// adminLabel splits name into words, e.g., "CreatedAt" into "Created At".
+func adminLabel(name string) string {
+	var b strings.Builder
+	runes := []rune(name)
+	for i, r := range runes {
+		if i > 0 && unicode.IsUpper(r) &&
+			(unicode.IsLower(runes[i-1]) || i+1 < len(runes) && unicode.IsLower(runes[i+1])) {
+			b.WriteRune(' ')
+		}
+
+		b.WriteRune(r)
+	}
+
+	return b.String()
+}
+
+// adminParse parses s, as submitted from the input adminInput chooses, into a value of type t.
+// An empty s is nil when t is a pointer.
+func adminParse(t reflect.Type, s string) (any, error) {
+	if t.Kind() == reflect.Pointer {
+		if s == "" {
+			return nil, nil
+		}
+
+		val, err := adminParse(t.Elem(), s)
+		if err != nil {
+			return nil, err
+		}
+
+		p := reflect.New(t.Elem())
+		p.Elem().Set(reflect.ValueOf(val))
+		return p.Interface(), nil
+	}
+
+	v := reflect.New(t).Elem()
+	if t == reflect.TypeFor[time.Time]() {
+		if s == "" {
+			return v.Interface(), nil
+		}
+
+		tm, err := time.Parse(adminTimeLayout, s)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", trails.ErrNotValid, err)
+		}
+
+		return tm, nil
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		v.SetBool(s == "on" || s == "true")
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(s, 10, t.Bits())
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", trails.ErrNotValid, err)
+		}
+
+		v.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(s, 10, t.Bits())
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", trails.ErrNotValid, err)
+		}
+
+		v.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, t.Bits())
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", trails.ErrNotValid, err)
+		}
+
+		v.SetFloat(f)
+	case reflect.String:
+		v.SetString(s)
+	default:
+		return nil, fmt.Errorf("%w: %s cannot be parsed", trails.ErrNotValid, t)
+	}
+
+	return v.Interface(), nil
+}
@@ -0,0 +1,286 @@
+package ranger_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/xy-planning-network/trails"
+	"github.com/xy-planning-network/trails/http/middleware"
+	"github.com/xy-planning-network/trails/postgres"
+	"github.com/xy-planning-network/trails/ranger"
+	"go.uber.org/mock/gomock"
+	"gorm.io/gorm"
+)
+
+type adminUser struct {
+	ID    uint
+	Roles []string
+}
+
+func (u adminUser) GetEmail() string { return "admin@example.com" }
+func (u adminUser) GetID() uint      { return u.ID }
+func (adminUser) HasAccess() bool    { return true }
+func (adminUser) HomePath() string   { return "/home" }
+func (u adminUser) HasRole(role string) bool {
+	for _, r := range u.Roles {
+		if r == role {
+			return true
+		}
+	}
+
+	return false
+}
+
+type widget struct {
+	gorm.Model
+	Name    string
+	Price   float64
+	Secret  []byte
+	Visible bool
+}
+
+func newAdminRanger(t *testing.T, user adminUser) (*ranger.Ranger, *postgres.MockDatabaseService) {
+	t.Helper()
+
+	t.Setenv("APP_DESCRIPTION", "An app")
+	t.Setenv("APP_TITLE", "App")
+
+	db := postgres.NewMockDatabaseService(gomock.NewController(t))
+	cfg := ranger.Config[adminUser]{
+		FS: fstest.MapFS{},
+		Middleware: func(stack *middleware.Stack) error {
+			return stack.Replace("CurrentUser", func(h http.Handler) http.Handler {
+				return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					h.ServeHTTP(w, r.WithContext(trails.WithValue(r.Context(), trails.CurrentUserKey, user)))
+				})
+			})
+		},
+	}
+	cfg.UseDBMock(db)
+	cfg.UseLogOutput(new(bytes.Buffer))
+
+	rng, err := ranger.New(cfg)
+	require.NoError(t, err)
+
+	return rng, db
+}
+
+func TestAdminRegister(t *testing.T) {
+	rng, _ := newAdminRanger(t, adminUser{})
+
+	for _, tc := range []struct {
+		name  string
+		model any
+		opts  ranger.AdminOpts
+	}{
+		{"Not-Struct", "widget", ranger.AdminOpts{}},
+		{"No-ID", new(struct{ Name string }), ranger.AdminOpts{}},
+		{"Unknown-Column", new(widget), ranger.AdminOpts{Columns: []string{"Color"}}},
+		{"Unexported-Column", new(widget), ranger.AdminOpts{Columns: []string{"model"}}},
+		{"Uneditable", new(widget), ranger.AdminOpts{Editable: []string{"Secret"}}},
+		{"ID-Editable", new(widget), ranger.AdminOpts{Editable: []string{"ID"}}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			// Act
+			err := rng.Admin().Register(tc.model, tc.opts)
+
+			// Assert
+			require.ErrorIs(t, err, trails.ErrNotValid)
+		})
+	}
+
+	// Arrange
+	require.NoError(t, rng.Admin().Register(new(widget), ranger.AdminOpts{}))
+
+	// Act
+	err := rng.Admin().Register(widget{}, ranger.AdminOpts{Title: "Gadget"})
+
+	// Assert
+	require.ErrorIs(t, err, trails.ErrNotValid)
+}
+
+func TestAdmin(t *testing.T) {
+	// Arrange
+	rng, db := newAdminRanger(t, adminUser{ID: 1, Roles: []string{ranger.AdminRole}})
+	err := rng.Admin().Register(new(widget), ranger.AdminOpts{
+		Columns:  []string{"ID", "Name", "Price"},
+		Editable: []string{"Name", "Price", "Visible"},
+		PerPage:  2,
+	})
+	require.NoError(t, err)
+
+	t.Run("Index", func(t *testing.T) {
+		// Arrange
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, ranger.AdminPath, nil)
+
+		// Act
+		rng.Router.ServeHTTP(w, r)
+
+		// Assert
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Contains(t, w.Body.String(), `href="/admin/widgets"`)
+	})
+
+	t.Run("List", func(t *testing.T) {
+		// Arrange
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, ranger.AdminPath+"/widgets?page=2", nil)
+
+		db.EXPECT().
+			PagedByQuery(gomock.Any(), "", nil, "id", 2, 2).
+			DoAndReturn(func(models any, _ string, _ []any, _ string, page, perPage int, _ ...string) (postgres.PagedData, error) {
+				reflect.ValueOf(models).Elem().Set(reflect.ValueOf([]widget{
+					{Model: gorm.Model{ID: 3}, Name: "Sprocket", Price: 1.5},
+					{Model: gorm.Model{ID: 4}, Name: "Gear", Price: 2},
+				}))
+
+				return postgres.PagedData{Items: models, Page: page, PerPage: perPage, TotalItems: 5, TotalPages: 3}, nil
+			})
+
+		// Act
+		rng.Router.ServeHTTP(w, r)
+
+		// Assert
+		require.Equal(t, http.StatusOK, w.Code)
+
+		body := w.Body.String()
+		require.Contains(t, body, "Sprocket")
+		require.Contains(t, body, `href="/admin/widgets/4"`)
+		require.Contains(t, body, `href="/admin/widgets?page=1"`)
+		require.Contains(t, body, `href="/admin/widgets?page=3"`)
+		require.Contains(t, body, "Page 2 of 3")
+	})
+
+	t.Run("Show", func(t *testing.T) {
+		// Arrange
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, ranger.AdminPath+"/widgets/3", nil)
+
+		db.EXPECT().
+			FindByID(gomock.Any(), uint(3)).
+			DoAndReturn(func(model, _ any) error {
+				*model.(*widget) = widget{Model: gorm.Model{ID: 3}, Name: "Sprocket"}
+				return nil
+			})
+
+		// Act
+		rng.Router.ServeHTTP(w, r)
+
+		// Assert
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Contains(t, w.Body.String(), "Sprocket")
+		require.Contains(t, w.Body.String(), `href="/admin/widgets/3/edit"`)
+	})
+
+	t.Run("Not-Found", func(t *testing.T) {
+		// Arrange
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, ranger.AdminPath+"/widgets/9", nil)
+
+		db.EXPECT().FindByID(gomock.Any(), uint(9)).Return(gorm.ErrRecordNotFound)
+
+		// Act
+		rng.Router.ServeHTTP(w, r)
+
+		// Assert
+		require.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("Edit", func(t *testing.T) {
+		// Arrange
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, ranger.AdminPath+"/widgets/3/edit", nil)
+
+		db.EXPECT().
+			FindByID(gomock.Any(), uint(3)).
+			DoAndReturn(func(model, _ any) error {
+				*model.(*widget) = widget{Model: gorm.Model{ID: 3}, Name: "Sprocket", Visible: true}
+				return nil
+			})
+
+		// Act
+		rng.Router.ServeHTTP(w, r)
+
+		// Assert
+		require.Equal(t, http.StatusOK, w.Code)
+
+		body := w.Body.String()
+		require.Contains(t, body, `name="Name" type="text" value="Sprocket"`)
+		require.Contains(t, body, `name="Visible" type="checkbox" checked`)
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		// Arrange
+		form := url.Values{"Name": {"Cog"}, "Price": {"3.25"}}
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, ranger.AdminPath+"/widgets/3", strings.NewReader(form.Encode()))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		db.EXPECT().
+			FindByID(gomock.Any(), uint(3)).
+			DoAndReturn(func(model, _ any) error {
+				*model.(*widget) = widget{Model: gorm.Model{ID: 3, CreatedAt: time.Now()}, Name: "Sprocket", Visible: true}
+				return nil
+			})
+		db.EXPECT().Updates(gomock.Any(), map[string]any{"Name": "Cog", "Price": 3.25, "Visible": false}).Return(nil)
+
+		// Act
+		rng.Router.ServeHTTP(w, r)
+
+		// Assert
+		require.Equal(t, http.StatusSeeOther, w.Code)
+		require.Equal(t, "/admin/widgets/3", w.Header().Get("Location"))
+	})
+
+	t.Run("Update-Bad-Input", func(t *testing.T) {
+		// Arrange
+		form := url.Values{"Name": {"Cog"}, "Price": {"cheap"}}
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, ranger.AdminPath+"/widgets/3", strings.NewReader(form.Encode()))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		db.EXPECT().
+			FindByID(gomock.Any(), uint(3)).
+			DoAndReturn(func(model, _ any) error {
+				*model.(*widget) = widget{Model: gorm.Model{ID: 3}}
+				return nil
+			})
+
+		// Act
+		rng.Router.ServeHTTP(w, r)
+
+		// Assert
+		require.Equal(t, http.StatusSeeOther, w.Code)
+		require.Equal(t, "/admin/widgets/3/edit", w.Header().Get("Location"))
+	})
+}
+
+func TestAdminRequiresRole(t *testing.T) {
+	// Arrange
+	rng, _ := newAdminRanger(t, adminUser{ID: 1})
+	require.NoError(t, rng.Admin().Register(new(widget), ranger.AdminOpts{}))
+
+	for _, path := range []string{ranger.AdminPath, ranger.AdminPath + "/widgets", ranger.AdminPath + "/widgets/1"} {
+		t.Run(path, func(t *testing.T) {
+			// Arrange
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodGet, path, nil)
+			r.Header.Set("Accept", "application/json")
+
+			// Act
+			rng.Router.ServeHTTP(w, r)
+
+			// Assert
+			require.Equal(t, http.StatusUnauthorized, w.Code)
+		})
+	}
+}
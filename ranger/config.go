@@ -5,9 +5,14 @@ import (
 	"fmt"
 	"io"
 	"io/fs"
+	"net/http"
 
 	"github.com/xy-planning-network/trails"
 	"github.com/xy-planning-network/trails/http/middleware"
+	"github.com/xy-planning-network/trails/http/resp"
+	"github.com/xy-planning-network/trails/http/router"
+	"github.com/xy-planning-network/trails/jobs"
+	"github.com/xy-planning-network/trails/logger"
 	"github.com/xy-planning-network/trails/postgres"
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
@@ -19,16 +24,109 @@ type Config[U RangerUser] struct {
 	// in all Ranger methods or references to Ranger.
 	// Config ought to be restricted to New.
 
+	// Assets configures serving static assets, e.g., from an [embed.FS].
+	//
+	// If Assets is nil, assets are served from the "client/dist" directory.
+	Assets *router.AssetsConfig
+
+	// ConfigureServer tunes the web server after Ranger configures it from env vars,
+	// e.g., setting ConnState, without replacing it and losing those defaults.
+	// Guide sets the Handler, so ConfigureServer ought not to.
+	ConfigureServer func(srv *http.Server)
+
+	// Diagnostics turns on serving runtime profiles at PprofPath, cf. [net/http/pprof],
+	// and runtime variables at VarsPath, cf. [expvar], including goroutine, garbage collector,
+	// and database connection pool statistics.
+	// They are always served on the internal listener, if INTERNAL_PORT is set;
+	// Diagnostics serves them on the web server otherwise,
+	// behind HTTP Basic authentication, so the ADMIN_USERNAME and ADMIN_PASSWORD env vars must be set.
+	Diagnostics bool
+
+	// EnvSections are an app's own configuration, each a pointer to a struct
+	// New loads from env vars alongside Env, reporting problems with them the same way;
+	// cf. [LoadEnv].
+	EnvSections []any
+
+	// ErrorEnrichers fill in errors the Responder or app logger report,
+	// e.g., setting fingerprints so Sentry groups them; cf. [logger.EnrichError].
+	// An error may pass through ErrorEnrichers more than once, so they ought to be idempotent.
+	ErrorEnrichers []logger.ErrorEnricher
+
 	// FS is the filesystem to find templates in for rendering them.
 	FS fs.FS
 
+	// HealthEndpoints turns on serving liveness at HealthzPath and readiness at ReadyzPath
+	// to every client, ahead of any middleware, for load balancers and orchestrators to probe;
+	// cf. [Ranger.AddHealthCheck].
+	// Build information is served at BuildInfoPath behind HTTP Basic authentication
+	// when the ADMIN_USERNAME and ADMIN_PASSWORD env vars are set.
+	HealthEndpoints bool
+
+	// JobBackend stores background jobs until they are run; cf. [Ranger.Jobs].
+	//
+	// If JobBackend is nil, the JOBS_BACKEND env var determines where jobs are stored.
+	JobBackend jobs.Backend
+
+	// LogLevelEndpoint turns on serving the level app and worker logs are written at at LogLevelPath,
+	// behind HTTP Basic authentication.
+	// A PUT or POST request with a "level" form value, e.g., "DEBUG", changes it; cf. [Ranger.SetLogLevel].
+	// The ADMIN_USERNAME and ADMIN_PASSWORD env vars must be set when true.
+	LogLevelEndpoint bool
+
+	// LogLevelSignal turns on toggling DEBUG logs each time the process receives SIGUSR1,
+	// e.g., from `kill -USR1 <pid>`.
+	// LogLevelSignal has no effect on platforms without SIGUSR1.
+	LogLevelSignal bool
+
 	// MaintMode determines how to configure ranger on ranger.New.
 	// If true, it skips setting up a database connection and routes to a maintenance page.
 	MaintMode bool
 
+	// Maintenance customizes the page served when MaintMode is true,
+	// and the routes kept live alongside it.
+	Maintenance MaintenanceConfig
+
+	// Middleware customizes the chain of middlewares Ranger applies to every request.
+	// Each middleware is named after the function constructing it, e.g., "CurrentUser";
+	// [middleware.Stack.List] lists them.
+	// An error returned by Middleware is returned by New.
+	Middleware func(stack *middleware.Stack) error
+
+	// Metrics turns on recording Prometheus metrics about every request
+	// and, with a postgres.Collector, about the database,
+	// serving them at MetricsPath, behind HTTP Basic authentication.
+	// The METRICS_USERNAME and METRICS_PASSWORD env vars must be set when true.
+	Metrics bool
+
 	// Migrations are a list of DB migrations to run upon DB successful connection.
 	Migrations []postgres.Migration
 
+	// Recording turns on recording the requests it selects, along with the responses to them,
+	// so requests clients report problems with can be reproduced exactly; cf. [middleware.Record].
+	// Recordings are listed at RecordingsPath, retrieved at RecordingsPath/{id},
+	// and issued to the app again by POSTing to RecordingsPath/{id}/replay.
+	// Recording has no effect outside the development environment.
+	Recording *RecordingConfig
+
+	// RoutesEndpoint turns on serving a JSON description of every Route registered at RoutesPath,
+	// for auditing which endpoints exist and which middlewares protect them.
+	// RoutesEndpoint has no effect outside the development environment.
+	RoutesEndpoint bool
+
+	// Shutdowns stop components of the application when Ranger shuts down,
+	// each ShutdownGroup after the one before it, before the web server stops accepting requests;
+	// cf. [Ranger.Shutdown], [ShutdownFn.WithTimeout].
+	Shutdowns []ShutdownGroup
+
+	// StartupReport turns on logging the value of every env var Ranger reads once New validates them,
+	// masking secrets, so a deploy's configuration can be checked at a glance; cf. [Config.Validate].
+	StartupReport bool
+
+	// VueProps are the PropsSchemas describing the props each Vue entry point expects, by entry point,
+	// so frontend and backend props drifting apart is logged loudly whenever a page renders; cf. [resp.WithVueProps].
+	// VueProps has no effect outside the development environment.
+	VueProps map[string]*resp.PropsSchema
+
 	mockdb    *postgres.MockDatabaseService
 	logoutput io.Writer
 }
@@ -37,7 +135,7 @@ type Config[U RangerUser] struct {
 // hooked up to ctrl.
 func (c *Config[U]) UseDBMock(mockdb *postgres.MockDatabaseService) { c.mockdb = mockdb }
 
-// UseLogOutput overrides the writing logs to os.Stdout;
+// UseLogOutput overrides writing logs to the outputs listed by LOG_OUTPUT;
 // use a bytes.Buffer in unit tests so log outputs can be inspected.
 func (c *Config[U]) UseLogOutput(w io.Writer) { c.logoutput = w }
 
@@ -3,18 +3,23 @@ package ranger
 import (
 	"context"
 	"embed"
+	"errors"
 	"fmt"
 	"io"
 	"io/fs"
 	"log/slog"
 	"net"
 	"net/http"
+	"net/netip"
 	"net/url"
 	"os"
 	"regexp"
 	"strings"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/xy-planning-network/tint"
 	"github.com/xy-planning-network/trails"
 	"github.com/xy-planning-network/trails/http/middleware"
@@ -22,7 +27,9 @@ import (
 	"github.com/xy-planning-network/trails/http/router"
 	"github.com/xy-planning-network/trails/http/session"
 	"github.com/xy-planning-network/trails/http/template"
+	"github.com/xy-planning-network/trails/jobs"
 	"github.com/xy-planning-network/trails/logger"
+	"github.com/xy-planning-network/trails/mailer"
 	"github.com/xy-planning-network/trails/postgres"
 	"golang.org/x/text/cases"
 	"golang.org/x/text/language"
@@ -34,84 +41,92 @@ const (
 	BaseURLEnvVar   = "BASE_URL"
 
 	// App metadata
-	AppDescEnvVar    = "APP_DESCRIPTION"
-	AppTitleEnvVar   = "APP_TITLE"
-	ContactUsEnvVar  = "CONTACT_US_EMAIL"
-	defaultContactUs = "hello@xyplanningnetwork.com"
-
-	// Environment defaults
-	environmentEnvVar = "ENVIRONMENT"
-
-	// Log defaults
-	logLevelEnvVar  = "LOG_LEVEL"
-	defaultLogLvl   = slog.LevelInfo
-	logJSONEnvVar   = "LOG_JSON"
-	defaultLogJSON  = false
-	sentryDsnEnvVar = "SENTRY_DSN"
-
-	// Database defaults
-	dbHostEnvVar        = "DATABASE_HOST"
-	defaultDBHost       = "localhost"
-	dbNameEnvVar        = "DATABASE_NAME"
-	dbPassEnvVar        = "DATABASE_PASSWORD"
-	dbPortEnvVar        = "DATABASE_PORT"
-	defaultDBPort       = "5432"
-	dbSSLModeEnvVar     = "DATABASE_SSLMODE"
-	defaultDBSSLMode    = "prefer"
-	dbURLEnvVar         = "DATABASE_URL"
-	dbUserEnvVar        = "DATABASE_USER"
-	dbMaxIdleCxnsEnvVar = "DATABASE_MAX_IDLE_CXNS"
-	// NOTE(dlk): same as database/sql
-	// cf., https://cs.opensource.google/go/go/+/refs/tags/go1.21.1:src/database/sql/sql.go;l=912
-	defaultDBMaxIdleCxns = 2
+	AppDescEnvVar   = "APP_DESCRIPTION"
+	AppTitleEnvVar  = "APP_TITLE"
+	ContactUsEnvVar = "CONTACT_US_EMAIL"
+
+	// Log output defaults
+	logOutputEnvVar      = "LOG_OUTPUT"
+	logAuditOutputEnvVar = "LOG_AUDIT_OUTPUT"
 
 	// Default HTML template files
 	defaultTmplDir               = "tmpl"
 	defaultErrTmpl               = defaultTmplDir + "/error.tmpl"
 	defaultLayoutDir             = defaultTmplDir + "/layout"
+	defaultMaintTmpl             = defaultTmplDir + "/maintenance.tmpl"
 	defaultAdditionalScriptsTmpl = defaultLayoutDir + "/additional_scripts.tmpl"
 	defaultAuthedTmpl            = defaultLayoutDir + "/authenticated_base.tmpl"
 	defaultUnauthedTmpl          = defaultLayoutDir + "/unauthenticated_base.tmpl"
 	defaultVueTmpl               = defaultLayoutDir + "/vue.tmpl"
 	defaultVueScriptsTmpl        = defaultLayoutDir + "/vue_scripts.tmpl"
 
+	// Admin endpoint defaults
+	adminPasswordEnvVar = "ADMIN_PASSWORD"
+	adminUsernameEnvVar = "ADMIN_USERNAME"
+	LogLevelPath        = "/_/log-level"
+
+	// Jobs defaults
+	jobsBackendEnvVar = "JOBS_BACKEND"
+
+	// Mailer defaults
+	mailProviderEnvVar        = "MAIL_PROVIDER"
+	awsAccessKeyIDEnvVar      = "AWS_ACCESS_KEY_ID"
+	awsRegionEnvVar           = "AWS_REGION"
+	awsSecretAccessKeyEnvVar  = "AWS_SECRET_ACCESS_KEY"
+	postmarkServerTokenEnvVar = "POSTMARK_SERVER_TOKEN"
+	sendGridAPIKeyEnvVar      = "SENDGRID_API_KEY"
+	smtpHostEnvVar            = "SMTP_HOST"
+
+	// Maintenance defaults
+	defaultMaintRetryAfter = 10 * time.Minute
+
+	// Metrics defaults
+	MetricsPath           = "/metrics"
+	metricsPasswordEnvVar = "METRICS_PASSWORD"
+	metricsUsernameEnvVar = "METRICS_USERNAME"
+
+	// TLS defaults
+	acmeDomainsEnvVar = "ACME_DOMAINS"
+	certFileEnvVar    = "CERT_FILE"
+	keyFileEnvVar     = "KEY_FILE"
+
+	// Diagnostics defaults
+	PprofPath = "/debug/pprof/"
+	VarsPath  = "/debug/vars"
+
+	// Recording defaults
+	DefaultRecordingsDir = "tmp/recordings"
+	RecordingsPath       = "/_/recordings"
+
+	// Routes endpoint defaults
+	RoutesPath = "/_/routes"
+
 	// Web server defaults
-	DefaultHost               = "localhost"
-	hostEnvVar                = "HOST"
-	DefaultPort               = ":3000"
-	portEnvVar                = "PORT"
-	serverReadTimeoutEnvVar   = "SERVER_READ_TIMEOUT"
-	DefaultServerReadTimeout  = 5 * time.Second
-	serverIdleTimeoutEnvVar   = "SERVER_IDLE_TIMEOUT"
-	DefaultServerIdleTimeout  = 120 * time.Second
-	serverWriteTimeoutEnvVar  = "SERVER_WRITE_TIMEOUT"
-	DefaultServerWriteTimeout = 5 * time.Second
+	DefaultHost                  = "localhost"
+	DefaultPort                  = ":3000"
+	DefaultServerReadTimeout     = 5 * time.Second
+	DefaultServerShutdownTimeout = 5 * time.Second
+	drainInterval                = 50 * time.Millisecond
+	DefaultServerIdleTimeout     = 120 * time.Second
+	DefaultServerWriteTimeout    = 5 * time.Second
+	trustedProxiesEnvVar         = "TRUSTED_PROXIES"
 
 	// Session defaults
-	SessionDomainEnvVar     = "SESSION_DOMAIN"
-	SessionAuthKeyEnvVar    = "SESSION_AUTH_KEY"
-	SessionEncryptKeyEnvVar = "SESSION_ENCRYPTION_KEY"
-	SessionMaxAgeEnvVar     = "SESSION_MAX_AGE"
-	SessionSameSiteMode     = "SESSION_SAMESITE_MODE"
-	defaultSessionMaxAge    = 24 * time.Hour
-
-	// Test defaults
-	dbTestHostEnvVar     = "DATABASE_TEST_HOST"
-	defaultDBTestHost    = "localhost"
-	dbTestNameEnvVar     = "DATABASE_TEST_NAME"
-	dbTestPassEnvVar     = "DATABASE_TEST_PASSWORD"
-	dbTestPortEnvVar     = "DATABASE_TEST_PORT"
-	defaultDBTestPort    = "5432"
-	dbTestURLEnvVar      = "DATABASE_TEST_URL"
-	dbTestUserEnvVar     = "DATABASE_TEST_USER"
-	dbTestSSLModeEnvVar  = "DATABASE_TEST_SSLMODE"
-	defaultDBTestSSLMode = "prefer"
+	SessionAbsoluteLifetimeEnvVar   = "SESSION_ABSOLUTE_LIFETIME"
+	SessionDomainEnvVar             = "SESSION_DOMAIN"
+	SessionIdleTimeoutEnvVar        = "SESSION_IDLE_TIMEOUT"
+	SessionAuthKeyEnvVar            = "SESSION_AUTH_KEY"
+	SessionAuthKeyPreviousEnvVar    = "SESSION_AUTH_KEY_PREVIOUS"
+	SessionEncryptKeyEnvVar         = "SESSION_ENCRYPTION_KEY"
+	SessionEncryptKeyPreviousEnvVar = "SESSION_ENCRYPTION_KEY_PREVIOUS"
+	SessionMaxAgeEnvVar             = "SESSION_MAX_AGE"
+	SessionPartitionedEnvVar        = "SESSION_PARTITIONED"
+	SessionPathEnvVar               = "SESSION_PATH"
+	SessionRememberMaxAgeEnvVar     = "SESSION_REMEMBER_MAX_AGE"
+	SessionSameSiteMode             = "SESSION_SAMESITE_MODE"
 )
 
 var (
-	defaultAssetsURL = "/"
-	defaultBaseURL   = "http://" + DefaultHost + DefaultPort
-
 	//go:embed tmpl/*
 	tmpls embed.FS
 )
@@ -119,59 +134,205 @@ var (
 // NewPostgresConfig constructs a *postgres.CxnConfig appropriate to the given environment.
 // Confer the DATABASE env vars for usage.
 func NewPostgresConfig(env trails.Environment) *postgres.CxnConfig {
+	var e Env
+	LoadEnv(&e)
+
+	return newPostgresConfig(env, e.Database, e.DatabaseTest)
+}
+
+// newPostgresConfig constructs a *postgres.CxnConfig appropriate to the given environment
+// from db or, when testing, test.
+func newPostgresConfig(env trails.Environment, db DatabaseEnv, test DatabaseTestEnv) *postgres.CxnConfig {
 	var cfg *postgres.CxnConfig
-	url := os.Getenv(dbURLEnvVar)
 	switch {
 	case env.IsTesting():
 		cfg = &postgres.CxnConfig{
-			Host:     trails.EnvVarOrString(dbTestHostEnvVar, defaultDBTestHost),
+			Host:     test.Host,
 			IsTestDB: true,
-			Name:     os.Getenv(dbTestNameEnvVar),
-			Password: os.Getenv(dbTestPassEnvVar),
-			Port:     trails.EnvVarOrString(dbTestPortEnvVar, defaultDBTestPort),
-			SSLMode:  trails.EnvVarOrString(dbTestSSLModeEnvVar, defaultDBTestSSLMode),
-			User:     os.Getenv(dbTestUserEnvVar),
+			Name:     test.Name,
+			Password: test.Password,
+			Port:     test.Port,
+			SSLMode:  test.SSLMode,
+			User:     test.User,
 		}
 
-	case url == "":
+	case db.URL == "":
 		cfg = &postgres.CxnConfig{
-			Host:     trails.EnvVarOrString(dbHostEnvVar, defaultDBHost),
+			Host:     db.Host,
 			IsTestDB: false,
-			Name:     os.Getenv(dbNameEnvVar),
-			Password: os.Getenv(dbPassEnvVar),
-			Port:     trails.EnvVarOrString(dbPortEnvVar, defaultDBPort),
-			SSLMode:  trails.EnvVarOrString(dbSSLModeEnvVar, defaultDBSSLMode),
-			User:     os.Getenv(dbUserEnvVar),
+			Name:     db.Name,
+			Password: db.Password,
+			Port:     db.Port,
+			SSLMode:  db.SSLMode,
+			User:     db.User,
 		}
 
 	default:
-		cfg = &postgres.CxnConfig{IsTestDB: false, URL: url}
+		cfg = &postgres.CxnConfig{IsTestDB: false, URL: db.URL}
 	}
 
-	cfg.MaxIdleCxns = trails.EnvVarOrInt(dbMaxIdleCxnsEnvVar, defaultDBMaxIdleCxns)
+	cfg.MaxIdleCxns = db.MaxIdleCxns
+	cfg.PrepareStmt = db.PrepareStmt
+	cfg.SensitiveColumns = db.SensitiveColumns
+	cfg.StmtCacheSize = db.StmtCacheSize
+	cfg.ValidateColumns = db.ValidateColumns
 
 	return cfg
 }
 
 // defaultDB connects to a Postgres database
-// using default configuration environment variables
-// and runs the list of [postgres.Migration] passed in.
-func defaultDB(env trails.Environment) (postgres.DatabaseService, error) {
-	db, err := postgres.Connect(NewPostgresConfig(env), env)
+// using default configuration environment variables,
+// logging queries with l.
+func defaultDB(env trails.Environment, e Env, l *slog.Logger) (postgres.DatabaseService, error) {
+	cfg := newPostgresConfig(env, e.Database, e.DatabaseTest)
+	cfg.Logger = l
+
+	db, err := postgres.Connect(cfg, env)
 	if err != nil {
 		return nil, err
 	}
 
-	return postgres.NewService(db), nil
+	service := postgres.NewService(db)
+	service.RequireOrder = e.Database.RequireOrder
+	service.TiebreakOrder = e.Database.TiebreakOrder
+
+	return service, nil
+}
+
+// logSinks are where logs are written or exported to, the level they are written at,
+// and how they are formatted and sampled.
+type logSinks struct {
+	closers  []ShutdownFn
+	export   slog.Handler
+	json     bool
+	level    slog.Leveler
+	out      io.Writer
+	sampling logger.SamplingConfig
 }
 
-// defaultAppLogger constructs a [tlog.Logger] configured for use in the application.
-func defaultAppLogger(env trails.Environment, output io.Writer) logger.Logger {
-	slogger := newSlogger(trails.AppLogKind, env, output)
+// defaultLogSinks constructs the logSinks specs lists, read from envVar, e.g., LOG_OUTPUT, each one of:
+//
+//   - "stdout" or "stderr"
+//   - "file:<path>", a [logger.RotatingFile] configured by LOG_FILE_* env vars
+//   - "syslog", the local syslog daemon, or "syslog:<network>://<address>", e.g., "syslog:udp://localhost:514"
+//   - "otlp:<url>", an OpenTelemetry collector receiving OTLP/HTTP, e.g., "otlp:http://localhost:4318"
+//
+// Logs are written at or above lvl, formatted and sampled as e configures.
+//
+// If output is not nil, defaultLogSinks uses it instead.
+func defaultLogSinks(envVar string, specs []string, e Env, lvl slog.Leveler, output io.Writer) (logSinks, error) {
+	sinks := logSinks{
+		json:  e.Log.JSON,
+		level: lvl,
+		sampling: logger.SamplingConfig{
+			BurstLimit:       e.Log.BurstLimit,
+			BurstWindow:      e.Log.BurstWindow,
+			DebugDropPercent: e.Log.DebugDropPercent,
+		},
+	}
+
+	if output != nil {
+		sinks.out = output
+		return sinks, nil
+	}
+
+	var writers []io.Writer
+	for _, spec := range specs {
+		kind, target, _ := strings.Cut(strings.TrimSpace(spec), ":")
+		switch kind {
+		case "stdout":
+			writers = append(writers, os.Stdout)
+
+		case "stderr":
+			writers = append(writers, os.Stderr)
+
+		case "file":
+			rf, err := logger.NewRotatingFile(target, logger.RotateConfig{
+				MaxAge:     e.Log.FileMaxAge,
+				MaxBackups: e.Log.FileMaxBackups,
+				MaxSize:    int64(e.Log.FileMaxSizeMB) << 20,
+			})
+			if err != nil {
+				sinks.close(context.Background())
+				return logSinks{}, fmt.Errorf("%w: %q: %s", trails.ErrBadConfig, envVar, err)
+			}
+
+			writers = append(writers, rf)
+			sinks.closers = append(sinks.closers, func(context.Context) error { return rf.Close() })
+
+		case "syslog":
+			var network, raddr string
+			if target != "" {
+				u, err := url.Parse(target)
+				if err != nil {
+					sinks.close(context.Background())
+					return logSinks{}, fmt.Errorf("%w: %q: %s", trails.ErrBadConfig, envVar, err)
+				}
+				network, raddr = u.Scheme, u.Host
+			}
+
+			sw, err := logger.NewSyslogWriter(network, raddr, e.App.Title)
+			if err != nil {
+				sinks.close(context.Background())
+				return logSinks{}, fmt.Errorf("%w: %q: %s", trails.ErrBadConfig, envVar, err)
+			}
+
+			writers = append(writers, sw)
+			sinks.closers = append(sinks.closers, func(context.Context) error { return sw.Close() })
+
+		case "otlp":
+			headers := make(map[string]string)
+			for _, h := range e.Log.OTLPHeaders {
+				if k, v, ok := strings.Cut(h, "="); ok {
+					headers[strings.TrimSpace(k)] = strings.TrimSpace(v)
+				}
+			}
+
+			oh, err := logger.NewOTLPHandler(logger.OTLPConfig{
+				Endpoint:    target,
+				Headers:     headers,
+				Level:       lvl,
+				ServiceName: e.App.Title,
+			})
+			if err != nil {
+				sinks.close(context.Background())
+				return logSinks{}, fmt.Errorf("%w: %q: %s", trails.ErrBadConfig, envVar, err)
+			}
+
+			sinks.export = oh
+			sinks.closers = append(sinks.closers, oh.Shutdown)
+
+		default:
+			sinks.close(context.Background())
+			return logSinks{}, fmt.Errorf("%w: %q: unknown output %q", trails.ErrBadConfig, envVar, spec)
+		}
+	}
+
+	sinks.out = io.MultiWriter(writers...)
+
+	return sinks, nil
+}
+
+// close closes each of the logSinks needing it.
+func (ls logSinks) close(ctx context.Context) error {
+	var errs []error
+	for _, fn := range ls.closers {
+		if err := fn(ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// defaultAppLogger constructs a [tlog.Logger] configured for use in the application,
+// reporting errors to Sentry if dsn is set.
+func defaultAppLogger(env trails.Environment, sinks logSinks, dsn string, enrichers []logger.ErrorEnricher) logger.Logger {
+	slogger := newSlogger(trails.AppLogKind, env, sinks)
 	l := logger.New(slogger, env)
 	l.Debug("setting up app logger", nil)
-	if dsn := os.Getenv(sentryDsnEnvVar); dsn != "" {
-		l = logger.NewSentryLogger(env, l, dsn)
+	if dsn != "" {
+		l = logger.NewSentryLogger(env, l, dsn, enrichers...)
 		l.Debug("using SentryLogger for app logger", nil)
 	}
 
@@ -180,20 +341,31 @@ func defaultAppLogger(env trails.Environment, output io.Writer) logger.Logger {
 	return l
 }
 
+// defaultAuditLogger constructs a [*logger.AuditLogger] for recording security-relevant events,
+// making it the one [logger.Audit] uses.
+func defaultAuditLogger(env trails.Environment, sinks logSinks, checksum bool) {
+	al := logger.NewAuditLogger(
+		newSlogger(trails.AuditLogKind, env, sinks).Handler(),
+		logger.AuditConfig{Checksum: checksum},
+	)
+	logger.SetDefaultAuditLogger(al)
+}
+
 // defaultHTTPLogger constructs a [*log/slog.Logger] for use in HTTP router logging.
-func defaultHTTPLogger(env trails.Environment, output io.Writer) *slog.Logger {
-	sl := newSlogger(trails.HTTPLogKind, env, output)
+func defaultHTTPLogger(env trails.Environment, sinks logSinks) *slog.Logger {
+	sl := newSlogger(trails.HTTPLogKind, env, sinks)
 	sl.Debug("setting up HTTP router logger")
 
 	return sl
 }
 
-// defaultWorkerLogger constructs a [*log/slog.Logger] for use in Faktory worker logging.
-func defaultWorkerLogger(env trails.Environment) logger.Logger {
-	slogger := newSlogger(trails.WorkerLogKind, env, os.Stdout)
+// defaultWorkerLogger constructs a [*log/slog.Logger] for use in Faktory worker logging,
+// reporting errors to Sentry if dsn is set.
+func defaultWorkerLogger(env trails.Environment, sinks logSinks, dsn string) logger.Logger {
+	slogger := newSlogger(trails.WorkerLogKind, env, sinks)
 	l := logger.New(slogger, env)
 	l.Debug("setting up worker logger", nil)
-	if dsn := os.Getenv(sentryDsnEnvVar); dsn != "" {
+	if dsn != "" {
 		l = logger.NewSentryLogger(env, l, dsn)
 		l.Debug("using SentryLogger for worker logger", nil)
 	}
@@ -201,20 +373,139 @@ func defaultWorkerLogger(env trails.Environment) logger.Logger {
 	return l
 }
 
+// defaultJobs constructs a [*jobs.Manager] storing jobs in backend
+// or, if backend is nil, where JOBS_BACKEND names:
+// "memory", in-process; "postgres", in db; or "faktory", in the Faktory server at FAKTORY_URL.
+func defaultJobs(l logger.Logger, backend jobs.Backend, db postgres.DatabaseService, e JobsEnv) (*jobs.Manager, error) {
+	if backend == nil {
+		switch kind := e.Backend; kind {
+		case "memory":
+		case "postgres":
+			impl, ok := db.(*postgres.DatabaseServiceImpl)
+			if !ok {
+				return nil, fmt.Errorf("%w: %s=postgres requires a database connection", trails.ErrBadConfig, jobsBackendEnvVar)
+			}
+
+			backend = jobs.NewPostgresBackend(impl.DB, jobs.PostgresConfig{
+				PollInterval:      e.PollInterval,
+				VisibilityTimeout: e.VisibilityTimeout,
+			})
+		case "faktory":
+			var err error
+			backend, err = jobs.NewFaktoryBackend(jobs.FaktoryConfig{
+				Queues: e.FaktoryQueues,
+				URL:    e.FaktoryURL,
+			})
+			if err != nil {
+				return nil, err
+			}
+		default:
+			return nil, fmt.Errorf("%w: unknown %s %q", trails.ErrBadConfig, jobsBackendEnvVar, kind)
+		}
+	}
+
+	return jobs.New(jobs.Config{
+		Backend:     backend,
+		Concurrency: e.Concurrency,
+		Logger:      l,
+	}), nil
+}
+
+// defaultScheduler constructs a [*jobs.Scheduler] for running tasks on schedules,
+// coordinating processes through db with a [*jobs.PostgresTaskStore], if connected to one.
+func defaultScheduler(l logger.Logger, db postgres.DatabaseService) *jobs.Scheduler {
+	cfg := jobs.SchedulerConfig{Logger: l}
+	if impl, ok := db.(*postgres.DatabaseServiceImpl); ok {
+		cfg.Store = jobs.NewPostgresTaskStore(impl.DB)
+	}
+
+	return jobs.NewScheduler(cfg)
+}
+
+// defaultMailer constructs a [*mailer.TemplateMailer] rendering templates with p
+// and sending through the provider MAIL_PROVIDER names: "log", "smtp", "postmark", "sendgrid", or "ses".
+//
+// Emails are sent from MAIL_FROM, or contact if not set.
+func defaultMailer(l logger.Logger, p *template.Parser, contact string, e MailEnv) (*mailer.TemplateMailer, error) {
+	var (
+		m   mailer.Mailer
+		err error
+	)
+
+	switch provider := e.Provider; provider {
+	case "log":
+		m = mailer.NewLogMailer(l)
+	case "smtp":
+		m, err = mailer.NewSMTPMailer(mailer.SMTPConfig{
+			Host:     e.SMTPHost,
+			Password: e.SMTPPassword,
+			Port:     e.SMTPPort,
+			Username: e.SMTPUsername,
+		})
+	case "postmark":
+		m, err = mailer.NewPostmarkMailer(mailer.APIConfig{Key: e.PostmarkServerToken})
+	case "sendgrid":
+		m, err = mailer.NewSendGridMailer(mailer.APIConfig{Key: e.SendGridAPIKey})
+	case "ses":
+		m, err = mailer.NewSESMailer(mailer.SESConfig{
+			AccessKeyID:     e.AWSAccessKeyID,
+			Region:          e.AWSRegion,
+			SecretAccessKey: e.AWSSecretAccessKey,
+			SessionToken:    e.AWSSessionToken,
+		})
+	default:
+		err = fmt.Errorf("%w: unknown %s %q", trails.ErrBadConfig, mailProviderEnvVar, provider)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not configure mailer: %w", err)
+	}
+
+	from := e.From
+	if from == "" {
+		from = contact
+	}
+
+	return mailer.NewTemplateMailer(m, p, l, from), nil
+}
+
+// backgroundShutdown stops s running tasks and m running jobs at once,
+// then closes m's Backend, if it can be.
+func backgroundShutdown(s *jobs.Scheduler, m *jobs.Manager) ShutdownGroup {
+	return ShutdownGroup{
+		Fns: []ShutdownFn{
+			s.Shutdown,
+			func(ctx context.Context) error {
+				err := m.Shutdown(ctx)
+				if c, ok := m.Backend().(io.Closer); ok {
+					err = errors.Join(err, c.Close())
+				}
+
+				return err
+			},
+		},
+		Name:     "background jobs and tasks",
+		Parallel: true,
+	}
+}
+
 // newSlogger toggles contructing the specific [*log/slog.Logger]
 // from the given parameters.
-func newSlogger(kind slog.Value, env trails.Environment, out io.Writer) *slog.Logger {
-	lvl := new(slog.LevelVar)
-	lvl.Set(trails.EnvVarOrLogLevel(logLevelEnvVar, slog.LevelInfo))
+func newSlogger(kind slog.Value, env trails.Environment, sinks logSinks) *slog.Logger {
+	out, lvl := sinks.out, sinks.level
 
-	useJSON := !env.IsDevelopment() || trails.EnvVarOrBool(logJSONEnvVar, defaultLogJSON)
+	useJSON := !env.IsDevelopment() || sinks.json
 	kindStr := kind.String()
 	isApp := kindStr == trails.AppLogKind.String()
 	isHTTP := kindStr == trails.HTTPLogKind.String()
 	isWorker := kindStr == trails.WorkerLogKind.String()
+	isAudit := kindStr == trails.AuditLogKind.String()
 
 	var handler slog.Handler
 	switch {
+	case isAudit:
+		// NOTE: audit logs are always JSON, for machines to consume, and never filtered by level.
+		handler = slog.NewJSONHandler(out, nil)
+
 	case useJSON && (isApp || isWorker):
 		opts := &slog.HandlerOptions{
 			AddSource:   true,
@@ -256,6 +547,16 @@ func newSlogger(kind slog.Value, env trails.Environment, out io.Writer) *slog.Lo
 
 	}
 
+	if sinks.export != nil {
+		handler = logger.NewFanoutHandler(handler, sinks.export)
+	}
+
+	// NOTE: LogRequest already records request-scoped values in HTTP logs.
+	if isApp || isWorker {
+		handler = logger.NewContextHandler(handler)
+		handler = logger.NewSamplingHandler(handler, sinks.sampling)
+	}
+
 	handler = handler.WithAttrs([]slog.Attr{
 		{Key: trails.LogKindKey, Value: kind},
 	})
@@ -278,8 +579,9 @@ func newSlogger(kind slog.Value, env trails.Environment, out io.Writer) *slog.Lo
 //   - "isDevelopment"
 //   - "isStaging"
 //   - "isProduction"
+//   - "markdown" renders markdown as sanitized HTML; cf. [template.MarkdownBasic]
 func defaultParser(env trails.Environment, url *url.URL, assetsURL *url.URL, files fs.FS, m Metadata) *template.Parser {
-	p := template.NewParser([]fs.FS{files, tmpls})
+	p := template.NewParser([]fs.FS{files, tmpls}, template.WithMarkdown(template.MarkdownBasic))
 	p = p.AddFn(template.Env(env))
 	p = p.AddFn("isDevelopment", env.IsDevelopment)
 	p = p.AddFn("isStaging", env.IsStaging)
@@ -293,11 +595,19 @@ func defaultParser(env trails.Environment, url *url.URL, assetsURL *url.URL, fil
 }
 
 // defaultResponder configures the [*resp.Responder] to be used by http.Handlers.
-func defaultResponder(l logger.Logger, url *url.URL, p *template.Parser, contact string) *resp.Responder {
+func defaultResponder(
+	l logger.Logger,
+	url *url.URL,
+	p *template.Parser,
+	contact string,
+	enrichers []logger.ErrorEnricher,
+	vueProps map[string]*resp.PropsSchema,
+) *resp.Responder {
 	args := []resp.ResponderOptFn{
 		resp.WithAdditionalScriptsTemplate(defaultAdditionalScriptsTmpl),
 		resp.WithAuthTemplate(defaultAuthedTmpl),
 		resp.WithContactErrMsg(fmt.Sprintf(session.ContactUsErr, contact)),
+		resp.WithErrorEnrichers(enrichers...),
 		resp.WithErrTemplate(defaultErrTmpl),
 		resp.WithLogger(l),
 		resp.WithParser(p),
@@ -307,6 +617,10 @@ func defaultResponder(l logger.Logger, url *url.URL, p *template.Parser, contact
 		resp.WithVueScriptsTemplate(defaultVueScriptsTmpl),
 	}
 
+	for entry, schema := range vueProps {
+		args = append(args, resp.WithVueProps(entry, schema))
+	}
+
 	return resp.NewResponder(args...)
 }
 
@@ -318,7 +632,7 @@ func defaultRouter(
 	logReqMiddleware middleware.Adapter,
 	mws []middleware.Adapter,
 ) router.Router {
-	route := router.New(env.String(), logReqMiddleware)
+	route := router.NewWithResponder(env.String(), logReqMiddleware, responder, nil)
 	route.OnEveryRequest(mws...)
 	route.HandleNotFound(http.HandlerFunc(func(wx http.ResponseWriter, rx *http.Request) {
 		if strings.Contains(rx.Header.Get("Accept"), "text/html") && rx.URL.Path != baseURL.Path {
@@ -332,23 +646,103 @@ func defaultRouter(
 	return route
 }
 
+// defaultAdminAuth constructs a [middleware.Adapter] requiring HTTP Basic authentication
+// with the credentials set by ADMIN_USERNAME and ADMIN_PASSWORD,
+// for protecting endpoints operators use.
+//
+// defaultAdminAuth relies on these env vars:
+//   - ADMIN_PASSWORD
+//   - ADMIN_USERNAME
+func defaultAdminAuth(title string, e AdminEnv) (middleware.Adapter, error) {
+	if e.Username == "" {
+		return nil, fmt.Errorf("%w: missing %q", trails.ErrBadConfig, adminUsernameEnvVar)
+	}
+
+	if e.Password == "" {
+		return nil, fmt.Errorf("%w: missing %q", trails.ErrBadConfig, adminPasswordEnvVar)
+	}
+
+	return middleware.BasicAuth(title, e.Username, e.Password), nil
+}
+
+// defaultLogLevel constructs the [*slog.LevelVar] every log, but audit logs, is written at,
+// starting at the level set by LOG_LEVEL.
+//
+// defaultLogLevel relies on these env vars:
+//   - LOG_LEVEL
+func defaultLogLevel(e LogEnv) *slog.LevelVar {
+	lvl := new(slog.LevelVar)
+	lvl.Set(e.Level)
+
+	return lvl
+}
+
+// defaultMetrics constructs a registry for Prometheus metrics,
+// including those about the Go runtime and process,
+// and a handler serving them behind HTTP Basic authentication.
+//
+// defaultMetrics relies on these env vars:
+//   - METRICS_PASSWORD
+//   - METRICS_USERNAME
+func defaultMetrics(title string, e MetricsEnv) (*prometheus.Registry, http.Handler, error) {
+	if e.Username == "" {
+		return nil, nil, fmt.Errorf("%w: missing %q", trails.ErrBadConfig, metricsUsernameEnvVar)
+	}
+
+	if e.Password == "" {
+		return nil, nil, fmt.Errorf("%w: missing %q", trails.ErrBadConfig, metricsPasswordEnvVar)
+	}
+
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+
+	handler := middleware.BasicAuth(title, e.Username, e.Password)(promhttp.HandlerFor(reg, promhttp.HandlerOpts{Registry: reg}))
+
+	return reg, handler, nil
+}
+
+// defaultTrustedProxies parses the CIDR prefixes and IP addresses of proxies
+// trusted to set the "X-Forwarded-For" header, set by TRUSTED_PROXIES.
+func defaultTrustedProxies(proxies []string) ([]netip.Prefix, error) {
+	prefixes, err := middleware.ParsePrefixes(proxies)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %q: %s", trails.ErrBadConfig, trustedProxiesEnvVar, err)
+	}
+
+	return prefixes, nil
+}
+
 // defaultSessionStore constructs a SessionStorer to be used for storing session data.
 //
 // defaultSessionStore relies on these env vars:
 //   - APP_TITLE
+//   - SESSION_ABSOLUTE_LIFETIME
 //   - SESSION_DOMAIN
+//   - SESSION_IDLE_TIMEOUT
+//   - SESSION_MAX_AGE
+//   - SESSION_PARTITIONED
+//   - SESSION_PATH
+//   - SESSION_REMEMBER_MAX_AGE
 //   - SESSION_AUTH_KEY
+//   - SESSION_AUTH_KEY_PREVIOUS
 //   - SESSION_ENCRYPTION_KEY
+//   - SESSION_ENCRYPTION_KEY_PREVIOUS
+//   - SESSION_FINGERPRINT
+//   - SESSION_FINGERPRINT_STRICTNESS
 //   - SESSION_SAMESITE_MODE
 //
-// Both KEY env vars be valid hex encoded values; cf. [encoding/hex].
-func defaultSessionStore(env trails.Environment, appName string) (session.SessionStorer, error) {
+// All KEY env vars must be valid hex encoded values; cf. [encoding/hex].
+// The PREVIOUS env vars are comma-separated lists, ordered newest to oldest.
+func defaultSessionStore(env trails.Environment, appName string, e SessionEnv) (session.SessionStorer, error) {
 	appName = cases.Lower(language.English).String(appName)
 	appName = regexp.MustCompile(`[,':]`).ReplaceAllString(appName, "")
 	appName = regexp.MustCompile(`\s`).ReplaceAllString(appName, "-")
 
 	var sameSiteMode http.SameSite
-	switch strings.ToLower(trails.EnvVarOrString(SessionSameSiteMode, "")) {
+	switch e.SameSiteMode {
 	case "lax":
 		sameSiteMode = http.SameSiteLaxMode
 	case "none":
@@ -359,35 +753,98 @@ func defaultSessionStore(env trails.Environment, appName string) (session.Sessio
 		sameSiteMode = http.SameSiteLaxMode
 	}
 
+	var fingerprint session.Fingerprint
+	switch e.Fingerprint {
+	case "ip":
+		fingerprint = session.FingerprintIPPrefix
+	case "ip-user-agent":
+		fingerprint = session.FingerprintIPPrefix | session.FingerprintUserAgent
+	case "user-agent":
+		fingerprint = session.FingerprintUserAgent
+	}
+
+	strictness := session.FingerprintStrict
+	if e.FingerprintStrictness == "lenient" {
+		strictness = session.FingerprintLenient
+	}
+
+	// NOTE: a SESSION_ABSOLUTE_LIFETIME of 0 opts out of an absolute lifetime,
+	// which session.Config takes as a negative duration.
+	absolute := e.AbsoluteLifetime
+	if absolute == 0 {
+		absolute = -1
+	}
+
 	cfg := session.Config{
-		AuthKey:      os.Getenv(SessionAuthKeyEnvVar),
-		Domain:       trails.EnvVarOrString(SessionDomainEnvVar, ""),
-		EncryptKey:   os.Getenv(SessionEncryptKeyEnvVar),
-		Env:          env,
-		MaxAge:       int(trails.EnvVarOrDuration(SessionMaxAgeEnvVar, defaultSessionMaxAge).Seconds()),
-		SameSiteMode: sameSiteMode,
-		SessionName:  "trails-" + appName,
+		AbsoluteLifetime:      absolute,
+		AuthKey:               e.AuthKey,
+		Domain:                e.Domain,
+		EncryptKey:            e.EncryptionKey,
+		Env:                   env,
+		Fingerprint:           fingerprint,
+		FingerprintStrictness: strictness,
+		IdleTimeout:           e.IdleTimeout,
+		MaxAge:                int(e.MaxAge.Seconds()),
+		Partitioned:           e.Partitioned,
+		Path:                  e.Path,
+		PreviousAuthKeys:      e.AuthKeyPrevious,
+		PreviousEncryptKeys:   e.EncryptionKeyPrevious,
+		RememberMaxAge:        int(e.RememberMaxAge.Seconds()),
+		SameSiteMode:          sameSiteMode,
+		SessionName:           "trails-" + appName,
 	}
 
 	return session.NewStoreService(cfg)
 }
 
-// defaultServer constructs a default [*http.Server].
-func defaultServer(ctx context.Context) *http.Server {
-	host := os.Getenv(hostEnvVar)
-	port := trails.EnvVarOrString(portEnvVar, DefaultPort)
+// defaultServer constructs a default [*http.Server] listening on port.
+func defaultServer(ctx context.Context, port string, e ServerEnv) *http.Server {
+	if port[0] != ':' {
+		port = ":" + port
+	}
+
+	srv := &http.Server{
+		Addr:              e.Host + port,
+		IdleTimeout:       e.IdleTimeout,
+		MaxHeaderBytes:    e.MaxHeaderBytes,
+		ReadHeaderTimeout: e.ReadHeaderTimeout,
+		ReadTimeout:       e.ReadTimeout,
+		WriteTimeout:      e.WriteTimeout,
+	}
+	if ctx != nil {
+		// NOTE: requests in flight when ctx is cancelled get to finish while the server shuts down.
+		srv.BaseContext = func(_ net.Listener) context.Context { return context.WithoutCancel(ctx) }
+	}
+
+	srv.SetKeepAlivesEnabled(e.KeepAlives)
+
+	return srv
+}
+
+// defaultInternalServer constructs the [*http.Server] listening on INTERNAL_PORT,
+// apart from the web server, or nil if INTERNAL_PORT is not set.
+//
+// defaultInternalServer relies on these env vars:
+//   - HOST
+//   - INTERNAL_PORT
+func defaultInternalServer(ctx context.Context, e ServerEnv) *http.Server {
+	port := e.InternalPort
+	if port == "" {
+		return nil
+	}
+
 	if port[0] != ':' {
 		port = ":" + port
 	}
 
 	srv := &http.Server{
-		Addr:         host + port,
-		IdleTimeout:  trails.EnvVarOrDuration(serverIdleTimeoutEnvVar, DefaultServerIdleTimeout),
-		ReadTimeout:  trails.EnvVarOrDuration(serverReadTimeoutEnvVar, DefaultServerReadTimeout),
-		WriteTimeout: trails.EnvVarOrDuration(serverWriteTimeoutEnvVar, DefaultServerWriteTimeout),
+		Addr:              e.Host + port,
+		IdleTimeout:       e.IdleTimeout,
+		ReadHeaderTimeout: e.ReadTimeout,
+		// NOTE: no WriteTimeout, since CPU profiles and traces take as long as requested.
 	}
 	if ctx != nil {
-		srv.BaseContext = func(_ net.Listener) context.Context { return ctx }
+		srv.BaseContext = func(_ net.Listener) context.Context { return context.WithoutCancel(ctx) }
 	}
 
 	return srv
@@ -0,0 +1,67 @@
+package ranger
+
+import (
+	"encoding/json"
+	"expvar"
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+
+	"github.com/xy-planning-network/trails/http/middleware"
+	"github.com/xy-planning-network/trails/http/router"
+	"github.com/xy-planning-network/trails/postgres"
+)
+
+// defaultDiagnostics serves runtime profiles at PprofPath and runtime variables at VarsPath.
+//
+// On the internal listener, they are served without authentication.
+// On the web server, they are served behind HTTP Basic authentication,
+// so defaultDiagnostics returns trails.ErrBadConfig unless the ADMIN_USERNAME and ADMIN_PASSWORD env vars are set.
+func (r *Ranger) defaultDiagnostics(title string, e AdminEnv) error {
+	var mws []middleware.Adapter
+	if r.internal.srv == nil {
+		adminAuth, err := defaultAdminAuth(title, e)
+		if err != nil {
+			return err
+		}
+
+		mws = append(mws, adminAuth)
+	}
+
+	// NOTE: named profiles must be registered ahead of the one matching any profile.
+	for _, route := range []router.Route{
+		{Path: PprofPath + "cmdline", Method: http.MethodGet, Handler: pprof.Cmdline},
+		{Path: PprofPath + "profile", Method: http.MethodGet, Handler: pprof.Profile},
+		{Path: PprofPath + "symbol", Method: http.MethodGet, Handler: pprof.Symbol},
+		{Path: PprofPath + "symbol", Method: http.MethodPost, Handler: pprof.Symbol},
+		{Path: PprofPath + "trace", Method: http.MethodGet, Handler: pprof.Trace},
+		{Path: PprofPath, Method: http.MethodGet, Handler: pprof.Index},
+		{Path: PprofPath + "{profile}", Method: http.MethodGet, Handler: pprof.Index},
+		{Path: VarsPath, Method: http.MethodGet, Handler: r.handleVars},
+	} {
+		route.Middlewares = mws
+		r.handleInternal(route)
+	}
+
+	return nil
+}
+
+// handleVars responds with the variables published by package expvar,
+// including "memstats" with garbage collector statistics,
+// along with the number of "goroutines" and, if connected, "database" connection pool statistics.
+func (r *Ranger) handleVars(w http.ResponseWriter, _ *http.Request) {
+	vars := make(map[string]any)
+	expvar.Do(func(kv expvar.KeyValue) {
+		vars[kv.Key] = json.RawMessage(kv.Value.String())
+	})
+
+	vars["goroutines"] = runtime.NumGoroutine()
+
+	if db, ok := r.db.(*postgres.DatabaseServiceImpl); ok {
+		if sqlDB, err := db.DB.DB(); err == nil {
+			vars["database"] = sqlDB.Stats()
+		}
+	}
+
+	writeHealth(w, http.StatusOK, vars)
+}
@@ -0,0 +1,88 @@
+package ranger_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/require"
+	"github.com/xy-planning-network/trails"
+	"github.com/xy-planning-network/trails/postgres"
+	"github.com/xy-planning-network/trails/ranger"
+	"go.uber.org/mock/gomock"
+)
+
+func TestNewDiagnostics(t *testing.T) {
+	// Arrange
+	t.Setenv("ADMIN_PASSWORD", "")
+	t.Setenv("ADMIN_USERNAME", "")
+	t.Setenv("APP_DESCRIPTION", "An app")
+	t.Setenv("APP_TITLE", "App")
+
+	cfg := ranger.Config[trails.User]{Diagnostics: true, FS: fstest.MapFS{}}
+	cfg.UseDBMock(postgres.NewMockDatabaseService(gomock.NewController(t)))
+	cfg.UseLogOutput(new(bytes.Buffer))
+
+	for _, env := range []string{"production", "development"} {
+		t.Run("No-Auth-"+env, func(t *testing.T) {
+			// Arrange
+			t.Setenv("ENVIRONMENT", env)
+
+			// Act
+			_, err := ranger.New(cfg)
+
+			// Assert
+			require.ErrorIs(t, err, trails.ErrBadConfig)
+			require.ErrorContains(t, err, `"ADMIN_USERNAME"`)
+		})
+	}
+
+	// Arrange
+	t.Setenv("ADMIN_PASSWORD", "secret")
+	t.Setenv("ADMIN_USERNAME", "admin")
+	t.Setenv("ENVIRONMENT", "development")
+
+	rng, err := ranger.New(cfg)
+	require.NoError(t, err)
+
+	for _, path := range []string{ranger.PprofPath, ranger.PprofPath + "heap", ranger.VarsPath} {
+		t.Run(path, func(t *testing.T) {
+			// Arrange
+			rr := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, path, nil)
+
+			// Act
+			rng.Router.ServeHTTP(rr, req)
+
+			// Assert
+			require.Equal(t, http.StatusUnauthorized, rr.Code)
+
+			// Arrange
+			rr = httptest.NewRecorder()
+			req.SetBasicAuth("admin", "secret")
+
+			// Act
+			rng.Router.ServeHTTP(rr, req)
+
+			// Assert
+			require.Equal(t, http.StatusOK, rr.Code)
+		})
+	}
+
+	// Arrange
+	rr := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, ranger.VarsPath, nil)
+	req.SetBasicAuth("admin", "secret")
+
+	// Act
+	rng.Router.ServeHTTP(rr, req)
+
+	// Assert
+	var vars map[string]any
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&vars))
+	require.Contains(t, vars, "goroutines")
+	require.Contains(t, vars, "memstats")
+}
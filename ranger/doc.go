@@ -13,41 +13,249 @@ By default, [*Ranger.Guide] listens on [DefaultHost]:[DefaultPort] (localhost:30
 assuming either a reverse proxy proxies requests
 or only a client application makes direct requests to the trails web server.
 
+Before listening, [*Ranger.Guide] calls the functions added with [*Ranger.OnStart],
+e.g., to warm caches or verify external dependencies, returning the first error any returns.
+Once listening, it calls those added with [*Ranger.OnReady], e.g., to register with service discovery.
+
 Upon calling [*Ranger.Guide], all routes configured up to that point are now active.
 Stop that web server with [*Ranger.Shutdown],
 call the context.CancelFunc returned by [*Ranger.Cancel],
 or send a signal [*Ranger.Guide] listens for.
 
+# TLS
+
+By default, [*Ranger.Guide] serves plain HTTP, assuming a proxy in front of it terminates TLS.
+To serve TLS directly, either set CERT_FILE and KEY_FILE, which are loaded again when renewed,
+or set ACME_DOMAINS to obtain and renew certificates from Let's Encrypt or another ACME CA.
+Either way, a listener on HTTP_REDIRECT_PORT redirects HTTP requests to HTTPS
+and answers ACME HTTP-01 challenges; PORT ought to be set to 443.
+
+# Internal listener
+
+When INTERNAL_PORT is set, [*Ranger.Guide] also listens on that port for operators,
+moving these endpoints off the web server: metrics ([Config].Metrics),
+liveness, readiness, and build info ([Config].HealthEndpoints),
+and the log level ([Config].LogLevelEndpoint).
+The internal listener also serves runtime profiles at [PprofPath], cf. [net/http/pprof],
+and runtime variables at [VarsPath], cf. [expvar],
+which the web server serves only if [Config].Diagnostics is set, behind the admin HTTP Basic authentication.
+On the internal listener, profiles and variables are served without authentication,
+so INTERNAL_PORT must not be reachable publicly.
+The internal listener shuts down with the web server.
+
+# Background jobs
+
+[*Ranger.Jobs] exposes a [jobs.Manager] for running work in the background.
+Register a [jobs.Handler] for each named job before calling [*Ranger.Guide],
+which starts running jobs alongside the web server;
+on shutdown, Ranger waits for running jobs to finish before closing the web server.
+For a process running only jobs, construct a Ranger with [BuildWorkerCore] and call [*Ranger.Work].
+
+By default, jobs are stored in-process and lost when the process exits.
+Set JOBS_BACKEND to "postgres" to store them in the database with a [jobs.PostgresBackend],
+whose tables Ranger migrates alongside [Config].Migrations.
+Set JOBS_BACKEND to "faktory" to store them in the Faktory server at FAKTORY_URL with a [jobs.FaktoryBackend].
+
+# Scheduled tasks
+
+[*Ranger.Every] runs a function in-process on a cron-style schedule,
+unlike a job scheduled with [jobs.Manager.Schedule], which a worker runs.
+When several processes run the application against the same database,
+a Postgres advisory lock ensures only one of them executes each run,
+and [*Ranger.Tasks] reports how the last run went.
+
+	err := rng.Every("0 3 * * *", "nightly-report", func(ctx context.Context) error {
+		return sendReport(ctx)
+	})
+
+# Email
+
+[*Ranger.Mailer] sends emails through the provider MAIL_PROVIDER names,
+rendering HTML bodies with the same templates, layouts, and partials as pages; cf. [mailer.TemplateMailer].
+By default, emails are logged instead of sent.
+
+# Admin UI
+
+[*Ranger.Admin] serves server-rendered pages, under [AdminPath], listing, showing, and editing models
+to users granted [AdminRole]; the application's user must be a [middleware.RoledUser].
+Register each model with the fields to list and those that can be edited:
+
+	err := rng.Admin().Register(new(models.User), ranger.AdminOpts{
+		Columns:  []string{"ID", "Email", "CreatedAt"},
+		Editable: []string{"Email", "Suspended"},
+	})
+
+# Mounting applications
+
+[*Ranger.Mount] composes applications into one served by the same web server,
+e.g., splitting a monolith into modules without deploying them separately.
+Construct each with [*Ranger.NewRouter], giving it its own [*resp.Responder] and middlewares,
+and mount it under a path prefix:
+
+	billing := rng.NewRouter(resp.NewResponder(resp.WithParser(billingParser), resp.WithRootUrl(billingURL)))
+	billing.Get("/invoices", listInvoices)
+	rng.Mount("/billing", billing)
+
+A mounted application routes requests as if the prefix were not part of their path,
+so "/billing/invoices" reaches the handler registered for "/invoices";
+URLs it renders or redirects to, though, ought to include the prefix, e.g., in the root URL of its Responder.
+The mounted application shares the Ranger's logger and shutdown,
+which waits for requests in flight to it as it does for any other.
+
+# Recording requests
+
+While developing, [Config].Recording records the requests it selects, along with the responses to them,
+to JSON files or the database, so a request a client reports a problem with can be reproduced exactly:
+
+	cfg.Recording = &ranger.RecordingConfig{Match: middleware.RecordRoutes("POST /checkout")}
+
+Recordings are listed at [RecordingsPath]; POSTing to RecordingsPath/{id}/replay
+issues the request recorded to the application again and responds with what it responded with this time.
+
+# Static export
+
+[*Ranger.Export] renders pages the Router serves, e.g., marketing pages, to HTML files for hosting on a CDN,
+using the same templates as the rest of the application:
+
+	rng.Router.UnauthedRoutes(landingRoutes)
+	err := rng.Export(ctx, []string{"/", "/pricing", "/404.html"}, "dist")
+
+# Frontend types
+
+[WriteTypeScript] generates TypeScript declarations of the Go structs passed to [resp.Data] and [resp.Vue],
+alongside the payloads trails structures, e.g., InitialProps and PagedData,
+so the frontend stays in sync with the backend by running go generate:
+
+	//go:generate go run ./cmd/tstypes client/src/types/payloads.ts
+
+	func main() {
+		err := ranger.WriteTypeScript[*models.User](os.Args[1], handlers.DashboardProps{}, models.Invoice{})
+		...
+	}
+
+In development, [Config].VueProps checks the props each Vue entry point is passed match what it expects,
+logging where they drift apart.
+
+# Request scope
+
+Every request handled by a Ranger carries a [Container], retrieved with [Scope].
+Middlewares register values in it with [Provide] and handlers resolve them with [Resolve],
+rather than asserting values out of the request's context.Context:
+
+	user, ok := ranger.Resolve[*models.User](ranger.Scope(r))
+
+Ranger provides the current user, of the type the [Config] is set with, once it is known.
+
 # Configuration
 
 A developer configures a trails app through environment variables
 and by setting fields on [Config].
-For environment variables, required values can be discovered by inspecting the errors [New] returns.
+For environment variables, required values can be discovered by inspecting the error [New] returns:
+[Config.Validate] checks every environment variable before New constructs anything,
+joining every one missing or not valid into that error, so a deploy can be fixed in one attempt.
+Setting [Config].StartupReport logs the value of every environment variable, masking secrets.
+
+New loads environment variables once, into an [Env], which [Ranger.Config] returns.
+An app loads its own configuration the same way, by describing it with struct tags
+and setting [Config].EnvSections or calling [LoadEnv]:
+
+	type BillingEnv struct {
+		APIKey   string `env:"BILLING_API_KEY" required:"true" secret:"true"`
+		Currency string `env:"BILLING_CURRENCY" default:"usd" oneof:"usd,eur"`
+	}
+
+	var billing BillingEnv
+	rng, err := ranger.New(ranger.Config[User]{EnvSections: []any{&billing}, ...})
 
 Environment variables ought to be set in a file called ".env"
 found at the same directory the application is executed from.
 
 Here are the available environment variables.
+  - ACME_CACHE_DIR: the directory certificates obtained from an ACME CA are cached in; default: acme-certs
+  - ACME_DIRECTORY_URL: the directory URL of the ACME CA, e.g., Let's Encrypt's staging environment; default: Let's Encrypt
+  - ACME_DOMAINS: a comma-separated list of domains to obtain certificates for from an ACME CA, serving TLS; cf. [golang.org/x/crypto/acme/autocert]
+  - ACME_EMAIL: the email address the ACME CA contacts about certificates
+  - ADMIN_PASSWORD: the password for HTTP Basic authentication to endpoints operators use; required when [Config].LogLevelEndpoint is true; serves build info when [Config].HealthEndpoints is true
+  - ADMIN_USERNAME: the username for HTTP Basic authentication to endpoints operators use; required when [Config].LogLevelEndpoint is true; serves build info when [Config].HealthEndpoints is true
   - APP_DESCRIPTION: a short description of the application
   - APP_TITLE: a short title for the application
   - ASSETS_URL: the base URL the application serves client-side assets over
+  - AWS_ACCESS_KEY_ID: the ID of the AWS credentials sending email when MAIL_PROVIDER is ses
+  - AWS_REGION: the AWS region sending email when MAIL_PROVIDER is ses, e.g., us-east-1
+  - AWS_SECRET_ACCESS_KEY: the secret of the AWS credentials sending email when MAIL_PROVIDER is ses
+  - AWS_SESSION_TOKEN: the session token of temporary AWS credentials sending email when MAIL_PROVIDER is ses
   - BASE_URL: the base URL the application runs on; replaces HOST & PORT
+  - CERT_FILE: the path to a PEM-encoded certificate, serving TLS with KEY_FILE
   - CONTACT_US: the email address end users can contact XYPN at; default: hello@xyplanningnetwork.com
   - DATABASE_HOST: the host the database is running on; default: localhost
   - DATABASE_NAME: the name of the database
   - DATABASE_PORT: the port the database is listening on; default: 5432
+  - DATABASE_PREPARE_STMT: whether statements are prepared once per connection and cached, sparing Postgres parsing and planning them again; default: false; cf. [postgres.StmtCache]
+  - DATABASE_REQUIRE_ORDER: whether paging through models without an order fails; default: false; cf. [postgres.DatabaseServiceImpl]
+  - DATABASE_SENSITIVE_COLUMNS: a comma-separated list of columns whose values are masked when queries are logged; default: password; cf. [postgres.Sensitive]
+  - DATABASE_STMT_CACHE_SIZE: the most prepared statements kept when DATABASE_PREPARE_STMT is true; default: 256
+  - DATABASE_TIEBREAK_ORDER: whether paging through models orders them by their primary key last, so pages never overlap nor skip rows; default: true; cf. [postgres.DatabaseServiceImpl]
   - DATABASE_URL: the fully-qualified connection string for connecting to the database; replaces all other DATABASE_* env vars
   - DATABASE_USER: the user for authenticating a connection to the database
+  - DATABASE_VALIDATE_COLUMNS: whether maps models are created or updated with are checked against their columns before querying the database; default: false; cf. [postgres.ColumnValidator]
   - DATABSE_PASSWORD: the password for authenticating a connection to the database
   - ENVIRONMENT: the environment the application is running in; cf. [trails.Environment]
+  - FAKTORY_QUEUES: a comma-separated list of Faktory queues, in priority order, background jobs are fetched from and pushed to the first of when JOBS_BACKEND is faktory; default: default
+  - FAKTORY_URL: the URL of the Faktory server, e.g., tcp://:password@localhost:7419, when JOBS_BACKEND is faktory; default: tcp://localhost:7419
   - HOST: the host the application is running on; default: localhost
-  - LOG_LEVEL: the level at which to begin logging; default: INFO; cf. [logger.LogLevel]
+  - HTTP_REDIRECT_PORT: the port redirecting HTTP requests to HTTPS when serving TLS; default: :80
+  - INTERNAL_PORT: the port an internal listener serves endpoints for operators and runtime profiles on, apart from PORT; default: none, serving those endpoints on PORT and no profiles
+  - JOBS_BACKEND: where background jobs are stored: "memory", "postgres", or "faktory"; default: memory; cf. [jobs.MemoryBackend], [jobs.PostgresBackend], [jobs.FaktoryBackend]
+  - JOBS_CONCURRENCY: the number of background jobs run at once; default: 10; cf. [jobs.Config]
+  - JOBS_POLL_INTERVAL: the duration - as understood by [time.ParseDuration] - between checks for background jobs due to run when JOBS_BACKEND is postgres; default: 1s
+  - JOBS_VISIBILITY_TIMEOUT: the duration - as understood by [time.ParseDuration] - a background job runs before it is assumed abandoned and run again when JOBS_BACKEND is postgres; default: 5m
+  - KEY_FILE: the path to the PEM-encoded private key of CERT_FILE
+  - LOG_AUDIT_CHECKSUM: whether to chain checksums through audit logs, making them tamper-evident; default: false; cf. [logger.AuditConfig]
+  - LOG_AUDIT_OUTPUT: where audit logs go, as LOG_OUTPUT lists; default: stdout; cf. [logger.Audit]
+  - LOG_BURST_LIMIT: the number of app or worker logs with the same level and message written each LOG_BURST_WINDOW, dropping the rest; default: 0, no limit
+  - LOG_BURST_WINDOW: the duration - as understood by [time.ParseDuration] - LOG_BURST_LIMIT applies over; default: 1m
+  - LOG_CRASH_DIR: the directory a crash report is written to when a goroutine Ranger, its jobs, or its tasks run panics, before exiting; default: none, cf. [logger.CapturePanics]
+  - LOG_DEBUG_DROP_PERCENT: the percentage of DEBUG app or worker logs dropped at random; default: 0
+  - LOG_FILE_MAX_AGE: the duration - as understood by [time.ParseDuration] - a "file:" LOG_OUTPUT is written to before rotating it; default: no rotation by age
+  - LOG_FILE_MAX_BACKUPS: the number of rotated "file:" LOG_OUTPUT files kept; default: 0, keeping all
+  - LOG_FILE_MAX_SIZE_MB: the size in megabytes a "file:" LOG_OUTPUT reaches before rotating it; default: no rotation by size
+  - LOG_LEVEL: the level at which to begin logging; default: INFO; cf. [logger.LogLevel], [*Ranger.SetLogLevel]
+  - LOG_OTLP_HEADERS: a comma-separated list of key=value headers sent to an "otlp:" LOG_OUTPUT, e.g., for authentication
+  - LOG_OUTPUT: a comma-separated list of where logs go: "stdout", "stderr", "file:<path>", "syslog", "syslog:<network>://<address>", or "otlp:<url>"; default: stdout; cf. [logger.RotatingFile], [logger.NewSyslogWriter], [logger.OTLPHandler]
+  - MAIL_FROM: the address emails are sent from, unless set on a [mailer.Message]; default: CONTACT_US
+  - MAIL_PROVIDER: how emails are sent: "log", "smtp", "postmark", "sendgrid", or "ses"; default: log; cf. [mailer.LogMailer], [mailer.SMTPMailer], [mailer.PostmarkMailer], [mailer.SendGridMailer], [mailer.SESMailer]
+  - METRICS_PASSWORD: the password for HTTP Basic authentication when scraping metrics; required when [Config].Metrics is true
+  - METRICS_USERNAME: the username for HTTP Basic authentication when scraping metrics; required when [Config].Metrics is true
   - PORT: the port the application should listen on; default: :3000
+  - POSTMARK_SERVER_TOKEN: the server token authenticating with Postmark; required when MAIL_PROVIDER is postmark
+  - SENDGRID_API_KEY: the API key authenticating with SendGrid; required when MAIL_PROVIDER is sendgrid
+  - SERVER_CANONICAL_HOST: whether requests arriving on any host other than that of BASE_URL, e.g., a www subdomain, are redirected to it; default: false; cf. [middleware.CanonicalHost]
+  - SERVER_H2C: whether the web server accepts HTTP/2 without TLS (h2c), e.g., behind a load balancer speaking HTTP/2 to it; has no effect when serving TLS; default: false
   - SERVER_IDLE_TIMEOUT: the timeout - as understood by [time.ParseDuration] - for idiling between requests when using keep-alives; default: 120s
+  - SERVER_KEEP_ALIVES: whether the web server keeps connections alive between requests; default: true
+  - SERVER_MAX_HEADER_BYTES: the maximum size in bytes of the headers of an HTTP request; default: 1048576
+  - SERVER_READ_HEADER_TIMEOUT: the timeout - as understood by [time.ParseDuration] - for reading the headers of HTTP requests; default: SERVER_READ_TIMEOUT
   - SERVER_READ_TIMEOUT: the timeout - as understood by [time.ParseDuration] - for reading HTTP requests; default: 5s
+  - SERVER_SHUTDOWN_TIMEOUT: the timeout - as understood by [time.ParseDuration] - for shutting down, including for requests in flight to finish; default: 5s; cf. [*Ranger.Shutdown]
   - SERVER_WRITE_TIMEOUT: the timeout - as understood by [time.ParseDuration] - for writing HTTP responses; default: 5s
+  - SESSION_ABSOLUTE_LIFETIME: the duration - as understood by [time.ParseDuration] - a session is valid for regardless of activity, or 0 for no absolute lifetime; default: 24h
   - SESSION_AUTH_KEY: a hex-encoded key for authenticating cookies; cf. [encoding/hex]
-  - SESSION_ENCRYPTION_KEY: a hex-encoded key for encrypting cookies; cf. [encoding/hex]
+  - SESSION_AUTH_KEY_PREVIOUS: a comma-separated list of hex-encoded keys, newest to oldest, that authenticated cookies before SESSION_AUTH_KEY; used when rotating keys
   - SESSION_DOMAIN: the host the application is served over for setting as the cookie's domain; default: the hostname of BASE_URL
+  - SESSION_ENCRYPTION_KEY: a hex-encoded key for encrypting cookies; cf. [encoding/hex]
+  - SESSION_ENCRYPTION_KEY_PREVIOUS: a comma-separated list of hex-encoded keys, newest to oldest, that encrypted cookies before SESSION_ENCRYPTION_KEY; used when rotating keys
+  - SESSION_FINGERPRINT: the traits of a client a session is bound to once a user logs in, protecting against session hijacking: ip, ip-user-agent, or user-agent; default: sessions are not bound
+  - SESSION_FINGERPRINT_STRICTNESS: what happens to a session used by a client not matching its fingerprint: strict replaces it with a brand new one, lenient only records an audit event; default: strict
+  - SESSION_IDLE_TIMEOUT: the duration - as understood by [time.ParseDuration] - a session is valid for without activity; default: no idle timeout
+  - SESSION_MAX_AGE: the duration - as understood by [time.ParseDuration] - a session cookie is valid for; default: 24h
+  - SESSION_PARTITIONED: whether the session cookie is stored apart for each site embedding the application, e.g., in an iframe; makes the cookie Secure; default: false
+  - SESSION_PATH: the path to assign the session cookie to; default: /
+  - SESSION_REMEMBER_MAX_AGE: the duration - as understood by [time.ParseDuration] - a session cookie is valid for when a user asks to be remembered; when set, other session cookies last until the browser closes; no session outlives SESSION_ABSOLUTE_LIFETIME
+  - SESSION_SAMESITE_MODE: the SameSite mode of the session cookie: lax, none, or strict; none makes the cookie Secure; default: lax
+  - SMTP_HOST: the host of the SMTP server sending email; required when MAIL_PROVIDER is smtp
+  - SMTP_PASSWORD: the password authenticating SMTP_USERNAME with the SMTP server
+  - SMTP_PORT: the port of the SMTP server sending email; 465 connects over TLS; default: 587
+  - SMTP_USERNAME: the username authenticating with the SMTP server, if required
+  - TRUSTED_PROXIES: a comma-separated list of CIDR prefixes or IP addresses of proxies, e.g., load balancers, trusted to set the X-Forwarded-For and X-Request-ID headers; when not set, X-Forwarded-For is trusted from any client and X-Request-ID from none
 */
 package ranger
@@ -0,0 +1,409 @@
+package ranger
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"os"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/xy-planning-network/trails"
+)
+
+// Env is the configuration Ranger reads from env vars, loaded once by New; cf. [Ranger.Config].
+// Each section groups the env vars configuring one component;
+// [LoadEnv] describes how the struct tags on each field are used.
+type Env struct {
+	Admin        AdminEnv
+	App          AppEnv
+	Database     DatabaseEnv
+	DatabaseTest DatabaseTestEnv
+	Jobs         JobsEnv
+	Log          LogEnv
+	Mail         MailEnv
+	Metrics      MetricsEnv
+	Server       ServerEnv
+	Session      SessionEnv
+	TLS          TLSEnv
+}
+
+// AdminEnv configures HTTP Basic authentication to endpoints operators use.
+type AdminEnv struct {
+	Password string `env:"ADMIN_PASSWORD" secret:"true"`
+	Username string `env:"ADMIN_USERNAME"`
+}
+
+// AppEnv describes the application and where it is served from.
+type AppEnv struct {
+	AssetsURL   *url.URL           `env:"ASSETS_URL" default:"/"`
+	BaseURL     *url.URL           `env:"BASE_URL" default:"http://localhost:3000/"`
+	ContactUs   string             `env:"CONTACT_US_EMAIL" default:"hello@xyplanningnetwork.com"`
+	Description string             `env:"APP_DESCRIPTION" required:"true"`
+	Environment trails.Environment `env:"ENVIRONMENT" default:"DEVELOPMENT"`
+	Title       string             `env:"APP_TITLE" required:"true"`
+}
+
+// DatabaseEnv configures connecting to the database outside the testing environment.
+type DatabaseEnv struct {
+	Host string `env:"DATABASE_HOST" default:"localhost"`
+	// NOTE(dlk): same as database/sql
+	// cf., https://cs.opensource.google/go/go/+/refs/tags/go1.21.1:src/database/sql/sql.go;l=912
+	MaxIdleCxns      int      `env:"DATABASE_MAX_IDLE_CXNS" default:"2"`
+	Name             string   `env:"DATABASE_NAME"`
+	Password         string   `env:"DATABASE_PASSWORD" secret:"true"`
+	Port             string   `env:"DATABASE_PORT" default:"5432"`
+	PrepareStmt      bool     `env:"DATABASE_PREPARE_STMT"`
+	RequireOrder     bool     `env:"DATABASE_REQUIRE_ORDER"`
+	SensitiveColumns []string `env:"DATABASE_SENSITIVE_COLUMNS" default:"password"`
+	SSLMode          string   `env:"DATABASE_SSLMODE" default:"prefer"`
+	StmtCacheSize    int      `env:"DATABASE_STMT_CACHE_SIZE"`
+	TiebreakOrder    bool     `env:"DATABASE_TIEBREAK_ORDER" default:"true"`
+	URL              string   `env:"DATABASE_URL" secret:"true"`
+	User             string   `env:"DATABASE_USER"`
+	ValidateColumns  bool     `env:"DATABASE_VALIDATE_COLUMNS"`
+}
+
+// DatabaseTestEnv configures connecting to the database in the testing environment.
+type DatabaseTestEnv struct {
+	Host     string `env:"DATABASE_TEST_HOST" default:"localhost"`
+	Name     string `env:"DATABASE_TEST_NAME"`
+	Password string `env:"DATABASE_TEST_PASSWORD" secret:"true"`
+	Port     string `env:"DATABASE_TEST_PORT" default:"5432"`
+	SSLMode  string `env:"DATABASE_TEST_SSLMODE" default:"prefer"`
+	User     string `env:"DATABASE_TEST_USER"`
+}
+
+// JobsEnv configures running background jobs.
+type JobsEnv struct {
+	Backend           string        `env:"JOBS_BACKEND" default:"memory" oneof:"memory,postgres,faktory"`
+	Concurrency       int           `env:"JOBS_CONCURRENCY" default:"10"`
+	FaktoryQueues     []string      `env:"FAKTORY_QUEUES"`
+	FaktoryURL        string        `env:"FAKTORY_URL" secret:"true"`
+	PollInterval      time.Duration `env:"JOBS_POLL_INTERVAL" default:"1s"`
+	VisibilityTimeout time.Duration `env:"JOBS_VISIBILITY_TIMEOUT" default:"5m"`
+}
+
+// LogEnv configures writing logs.
+type LogEnv struct {
+	AuditChecksum    bool          `env:"LOG_AUDIT_CHECKSUM"`
+	AuditOutput      []string      `env:"LOG_AUDIT_OUTPUT" default:"stdout"`
+	BurstLimit       int           `env:"LOG_BURST_LIMIT"`
+	BurstWindow      time.Duration `env:"LOG_BURST_WINDOW" default:"1m"`
+	CrashDir         string        `env:"LOG_CRASH_DIR"`
+	DebugDropPercent int           `env:"LOG_DEBUG_DROP_PERCENT"`
+	FileMaxAge       time.Duration `env:"LOG_FILE_MAX_AGE"`
+	FileMaxBackups   int           `env:"LOG_FILE_MAX_BACKUPS"`
+	FileMaxSizeMB    int           `env:"LOG_FILE_MAX_SIZE_MB"`
+	JSON             bool          `env:"LOG_JSON"`
+	Level            slog.Level    `env:"LOG_LEVEL" default:"INFO"`
+	OTLPHeaders      []string      `env:"LOG_OTLP_HEADERS" secret:"true"`
+	Output           []string      `env:"LOG_OUTPUT" default:"stdout"`
+	SentryDSN        string        `env:"SENTRY_DSN" secret:"true"`
+}
+
+// MailEnv configures sending email.
+type MailEnv struct {
+	AWSAccessKeyID      string `env:"AWS_ACCESS_KEY_ID"`
+	AWSRegion           string `env:"AWS_REGION"`
+	AWSSecretAccessKey  string `env:"AWS_SECRET_ACCESS_KEY" secret:"true"`
+	AWSSessionToken     string `env:"AWS_SESSION_TOKEN" secret:"true"`
+	From                string `env:"MAIL_FROM"`
+	PostmarkServerToken string `env:"POSTMARK_SERVER_TOKEN" secret:"true"`
+	Provider            string `env:"MAIL_PROVIDER" default:"log" oneof:"log,smtp,postmark,sendgrid,ses"`
+	SendGridAPIKey      string `env:"SENDGRID_API_KEY" secret:"true"`
+	SMTPHost            string `env:"SMTP_HOST"`
+	SMTPPassword        string `env:"SMTP_PASSWORD" secret:"true"`
+	SMTPPort            string `env:"SMTP_PORT"`
+	SMTPUsername        string `env:"SMTP_USERNAME"`
+}
+
+// MetricsEnv configures HTTP Basic authentication to metrics.
+type MetricsEnv struct {
+	Password string `env:"METRICS_PASSWORD" secret:"true"`
+	Username string `env:"METRICS_USERNAME"`
+}
+
+// ServerEnv configures the web server and the listeners beside it.
+type ServerEnv struct {
+	CanonicalHost     bool          `env:"SERVER_CANONICAL_HOST"`
+	H2C               bool          `env:"SERVER_H2C"`
+	Host              string        `env:"HOST"`
+	HTTPRedirectPort  string        `env:"HTTP_REDIRECT_PORT" default:":80"`
+	IdleTimeout       time.Duration `env:"SERVER_IDLE_TIMEOUT" default:"2m"`
+	InternalPort      string        `env:"INTERNAL_PORT"`
+	KeepAlives        bool          `env:"SERVER_KEEP_ALIVES" default:"true"`
+	MaxHeaderBytes    int           `env:"SERVER_MAX_HEADER_BYTES" default:"1048576"`
+	Port              string        `env:"PORT" default:":3000"`
+	ReadHeaderTimeout time.Duration `env:"SERVER_READ_HEADER_TIMEOUT"`
+	ReadTimeout       time.Duration `env:"SERVER_READ_TIMEOUT" default:"5s"`
+	ShutdownTimeout   time.Duration `env:"SERVER_SHUTDOWN_TIMEOUT" default:"5s"`
+	TrustedProxies    []string      `env:"TRUSTED_PROXIES"`
+	WriteTimeout      time.Duration `env:"SERVER_WRITE_TIMEOUT" default:"5s"`
+}
+
+// SessionEnv configures storing sessions in cookies.
+type SessionEnv struct {
+	AbsoluteLifetime      time.Duration `env:"SESSION_ABSOLUTE_LIFETIME" default:"24h"`
+	AuthKey               string        `env:"SESSION_AUTH_KEY" secret:"true"`
+	AuthKeyPrevious       []string      `env:"SESSION_AUTH_KEY_PREVIOUS" secret:"true"`
+	Domain                string        `env:"SESSION_DOMAIN"`
+	EncryptionKey         string        `env:"SESSION_ENCRYPTION_KEY" secret:"true"`
+	EncryptionKeyPrevious []string      `env:"SESSION_ENCRYPTION_KEY_PREVIOUS" secret:"true"`
+	Fingerprint           string        `env:"SESSION_FINGERPRINT" oneof:"ip,ip-user-agent,user-agent"`
+	FingerprintStrictness string        `env:"SESSION_FINGERPRINT_STRICTNESS" default:"strict" oneof:"lenient,strict"`
+	IdleTimeout           time.Duration `env:"SESSION_IDLE_TIMEOUT"`
+	MaxAge                time.Duration `env:"SESSION_MAX_AGE" default:"24h"`
+	Partitioned           bool          `env:"SESSION_PARTITIONED"`
+	Path                  string        `env:"SESSION_PATH" default:"/"`
+	RememberMaxAge        time.Duration `env:"SESSION_REMEMBER_MAX_AGE"`
+	SameSiteMode          string        `env:"SESSION_SAMESITE_MODE" default:"lax" oneof:"lax,none,strict"`
+}
+
+// TLSEnv configures serving TLS directly.
+type TLSEnv struct {
+	ACMECacheDir     string   `env:"ACME_CACHE_DIR" default:"acme-certs"`
+	ACMEDirectoryURL *url.URL `env:"ACME_DIRECTORY_URL"`
+	ACMEDomains      []string `env:"ACME_DOMAINS"`
+	ACMEEmail        string   `env:"ACME_EMAIL"`
+	CertFile         string   `env:"CERT_FILE"`
+	KeyFile          string   `env:"KEY_FILE"`
+}
+
+var (
+	durationType    = reflect.TypeFor[time.Duration]()
+	environmentType = reflect.TypeFor[trails.Environment]()
+	unmarshalerType = reflect.TypeFor[encoding.TextUnmarshaler]()
+	urlType         = reflect.TypeFor[*url.URL]()
+)
+
+// LoadEnv populates the struct dst points to from env vars,
+// returning every env var missing or not valid joined together, each wrapping trails.ErrBadConfig.
+// An app loads its own configuration with LoadEnv the same way New loads Env; cf. [Config].EnvSections.
+//
+// LoadEnv reads the env var named by the `env` tag of each exported field, parsing it by the field's type:
+// string, bool, int, []string from a comma-separated list, [time.Duration], [*url.URL], [trails.Environment],
+// or a type implementing [encoding.TextUnmarshaler], e.g., [slog.Level].
+// These tags further describe a field:
+//   - `default`: the value used if the env var is not set
+//   - `oneof`: a comma-separated list of the values allowed, matched ignoring case
+//   - `required:"true"`: the env var must be set
+//   - `secret:"true"`: the value is masked when reported, e.g., by [Config].StartupReport
+//
+// A struct field without an `env` tag is loaded as a section of its own.
+// A field whose env var is not valid keeps its default.
+func LoadEnv(dst any) error {
+	return errors.Join(loadEnv(dst)...)
+}
+
+// loadEnv populates the struct dst points to from env vars, returning every error found.
+func loadEnv(dst any) []error {
+	fields, err := envFields(dst)
+	if err != nil {
+		return []error{err}
+	}
+
+	var errs []error
+	for _, f := range fields {
+		f.v.SetZero()
+		if f.def != "" {
+			if err := f.set(f.def); err != nil {
+				errs = append(errs, fmt.Errorf("%w: default of %q is not valid: %s", trails.ErrBadConfig, f.name, err))
+			}
+		}
+
+		val := os.Getenv(f.name)
+		if val == "" {
+			if f.required {
+				errs = append(errs, fmt.Errorf("%w: missing %q", trails.ErrBadConfig, f.name))
+			}
+
+			continue
+		}
+
+		if len(f.oneOf) > 0 {
+			i := slices.IndexFunc(f.oneOf, func(opt string) bool { return strings.EqualFold(opt, val) })
+			if i < 0 {
+				errs = append(errs, fmt.Errorf("%w: %q must be one of %q", trails.ErrBadConfig, f.name, f.oneOf))
+				continue
+			}
+
+			val = f.oneOf[i]
+		}
+
+		if err := f.set(val); err != nil {
+			// NOTE: keep a secret out of errors, which are logged.
+			if f.secret {
+				errs = append(errs, fmt.Errorf("%w: %q is not valid", trails.ErrBadConfig, f.name))
+				continue
+			}
+
+			errs = append(errs, fmt.Errorf("%w: %q is not valid: %s", trails.ErrBadConfig, f.name, err))
+		}
+	}
+
+	return errs
+}
+
+// reportEnv adds the value of each field of the struct src points to to report, by env var, masking secrets.
+func reportEnv(report map[string]any, src any) {
+	fields, err := envFields(src)
+	if err != nil {
+		return
+	}
+
+	for _, f := range fields {
+		report[f.name] = f.report()
+	}
+}
+
+// An envField is a field of a struct loaded from the env var name.
+type envField struct {
+	def      string
+	name     string
+	oneOf    []string
+	required bool
+	secret   bool
+	v        reflect.Value
+}
+
+// envFields lists the fields of the struct dst points to loaded from env vars,
+// including those of its sections, returning trails.ErrBadConfig if dst is not a pointer to a struct.
+func envFields(dst any) ([]envField, error) {
+	v := reflect.ValueOf(dst)
+	if v.Kind() != reflect.Pointer || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return nil, fmt.Errorf("%w: %T is not a pointer to a struct", trails.ErrBadConfig, dst)
+	}
+
+	var fields []envField
+	var walk func(v reflect.Value)
+	walk = func(v reflect.Value) {
+		for i := range v.NumField() {
+			sf := v.Type().Field(i)
+			if !sf.IsExported() {
+				continue
+			}
+
+			name, ok := sf.Tag.Lookup("env")
+			if !ok {
+				if sf.Type.Kind() == reflect.Struct {
+					walk(v.Field(i))
+				}
+
+				continue
+			}
+
+			f := envField{
+				def:      sf.Tag.Get("default"),
+				name:     name,
+				required: sf.Tag.Get("required") == "true",
+				secret:   sf.Tag.Get("secret") == "true",
+				v:        v.Field(i),
+			}
+			if opts := sf.Tag.Get("oneof"); opts != "" {
+				f.oneOf = strings.Split(opts, ",")
+			}
+
+			fields = append(fields, f)
+		}
+	}
+	walk(v.Elem())
+
+	return fields, nil
+}
+
+// set parses val by the type of f, setting f to it.
+func (f envField) set(val string) error {
+	t := f.v.Type()
+	switch {
+	case t == durationType:
+		d, err := time.ParseDuration(val)
+		if err != nil {
+			return err
+		}
+		f.v.SetInt(int64(d))
+
+	case t == environmentType:
+		env := trails.Environment(strings.ToUpper(val))
+		if err := env.Valid(); err != nil {
+			return err
+		}
+		f.v.Set(reflect.ValueOf(env))
+
+	case t == urlType:
+		u, err := url.ParseRequestURI(val)
+		if err != nil {
+			return err
+		}
+		f.v.Set(reflect.ValueOf(u))
+
+	case reflect.PointerTo(t).Implements(unmarshalerType):
+		ptr := reflect.New(t)
+		if err := ptr.Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(val)); err != nil {
+			return err
+		}
+		f.v.Set(ptr.Elem())
+
+	case t.Kind() == reflect.String:
+		f.v.SetString(val)
+
+	case t.Kind() == reflect.Bool:
+		b, err := strconv.ParseBool(val)
+		if err != nil {
+			return err
+		}
+		f.v.SetBool(b)
+
+	case t.Kind() == reflect.Int:
+		n, err := strconv.Atoi(val)
+		if err != nil {
+			return err
+		}
+		f.v.SetInt(int64(n))
+
+	case t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.String:
+		var vals []string
+		for _, v := range strings.Split(val, ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				vals = append(vals, v)
+			}
+		}
+		f.v.Set(reflect.ValueOf(vals).Convert(t))
+
+	default:
+		return fmt.Errorf("cannot load a %s", t)
+	}
+
+	return nil
+}
+
+// report formats the value of f for reporting, masking it if secret;
+// a secret URL has only its password masked.
+func (f envField) report() string {
+	var val string
+	switch v := f.v.Interface().(type) {
+	case *url.URL:
+		if v != nil {
+			val = v.String()
+		}
+	case []string:
+		val = strings.Join(v, ",")
+	default:
+		val = fmt.Sprint(v)
+	}
+
+	if !f.secret || f.v.IsZero() {
+		return val
+	}
+
+	if u, err := url.Parse(val); err == nil && u.User != nil && u.Host != "" {
+		return u.Redacted()
+	}
+
+	return "(set)"
+}
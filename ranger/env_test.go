@@ -0,0 +1,105 @@
+package ranger_test
+
+import (
+	"bytes"
+	"log/slog"
+	"net/url"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/xy-planning-network/trails"
+	"github.com/xy-planning-network/trails/ranger"
+)
+
+type billingEnv struct {
+	APIKey   string        `env:"BILLING_API_KEY" required:"true" secret:"true"`
+	Currency string        `env:"BILLING_CURRENCY" default:"usd" oneof:"usd,eur"`
+	Level    slog.Level    `env:"BILLING_LOG_LEVEL" default:"WARN"`
+	Plans    []string      `env:"BILLING_PLANS"`
+	Timeout  time.Duration `env:"BILLING_TIMEOUT" default:"10s"`
+	URL      *url.URL      `env:"BILLING_URL" default:"https://billing.example.com/"`
+
+	Retries struct {
+		Max int `env:"BILLING_RETRIES_MAX" default:"3"`
+	}
+}
+
+func TestLoadEnv(t *testing.T) {
+	// Arrange
+	t.Setenv("BILLING_API_KEY", "key")
+	t.Setenv("BILLING_CURRENCY", "EUR")
+	t.Setenv("BILLING_PLANS", "basic, pro,")
+
+	var e billingEnv
+
+	// Act
+	err := ranger.LoadEnv(&e)
+
+	// Assert
+	require.NoError(t, err)
+	require.Equal(t, "key", e.APIKey)
+	require.Equal(t, "eur", e.Currency)
+	require.Equal(t, slog.LevelWarn, e.Level)
+	require.Equal(t, []string{"basic", "pro"}, e.Plans)
+	require.Equal(t, 10*time.Second, e.Timeout)
+	require.Equal(t, "https://billing.example.com/", e.URL.String())
+	require.Equal(t, 3, e.Retries.Max)
+
+	// Arrange
+	t.Setenv("BILLING_API_KEY", "")
+	t.Setenv("BILLING_CURRENCY", "gbp")
+	t.Setenv("BILLING_RETRIES_MAX", "many")
+	t.Setenv("BILLING_TIMEOUT", "soon")
+
+	// Act
+	err = ranger.LoadEnv(&e)
+
+	// Assert
+	require.ErrorIs(t, err, trails.ErrBadConfig)
+	require.ErrorContains(t, err, `missing "BILLING_API_KEY"`)
+	require.ErrorContains(t, err, `"BILLING_CURRENCY" must be one of`)
+	require.ErrorContains(t, err, `"BILLING_RETRIES_MAX" is not valid`)
+	require.ErrorContains(t, err, `"BILLING_TIMEOUT" is not valid`)
+	require.Equal(t, 10*time.Second, e.Timeout)
+
+	// Act
+	err = ranger.LoadEnv(e)
+
+	// Assert
+	require.ErrorIs(t, err, trails.ErrBadConfig)
+}
+
+func TestRangerConfig(t *testing.T) {
+	// Arrange
+	t.Setenv("APP_DESCRIPTION", "An app")
+	t.Setenv("APP_TITLE", "App")
+	t.Setenv("BILLING_API_KEY", "billing-secret")
+	t.Setenv("ENVIRONMENT", "testing")
+	t.Setenv("SERVER_READ_TIMEOUT", "30s")
+
+	var billing billingEnv
+	b := new(bytes.Buffer)
+	cfg := ranger.Config[trails.User]{
+		EnvSections:   []any{&billing},
+		FS:            fstest.MapFS{},
+		MaintMode:     true,
+		StartupReport: true,
+	}
+	cfg.UseLogOutput(b)
+
+	// Act
+	rng, err := ranger.New(cfg)
+
+	// Assert
+	require.NoError(t, err)
+	require.Equal(t, trails.Testing, rng.Config().App.Environment)
+	require.Equal(t, "App", rng.Config().App.Title)
+	require.Equal(t, 30*time.Second, rng.Config().Server.ReadTimeout)
+	require.Equal(t, 5*time.Second, rng.Config().Server.WriteTimeout)
+	require.Equal(t, 24*time.Hour, rng.Config().Session.AbsoluteLifetime)
+	require.Equal(t, "billing-secret", billing.APIKey)
+	require.Contains(t, b.String(), "BILLING_CURRENCY")
+	require.NotContains(t, b.String(), "billing-secret")
+}
@@ -0,0 +1,122 @@
+package ranger
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/xy-planning-network/trails"
+)
+
+// Export renders the pages the Router serves at paths, e.g., "/" or "/pricing",
+// to HTML files in outDir for hosting on a CDN,
+// so pages not needing a live server share their templates with the rest of the application.
+//
+// Each path is requested as an anonymous GET request to BASE_URL, passing through every middleware,
+// and written to index.html in the matching directory under outDir, e.g., outDir/pricing/index.html,
+// unless the path names a file, e.g., "/404.html", which is written as is.
+//
+// Pages are rendered once, so nothing specific to a request or session ought to appear in them,
+// e.g., a CSRF token.
+//
+// If a path is not absolute or escapes outDir, Export returns trails.ErrNotValid.
+// If the Router does not respond to a path with 200, Export returns trails.ErrNotValid without writing it.
+func (r *Ranger) Export(ctx context.Context, paths []string, outDir string) error {
+	for _, p := range paths {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		file, err := exportFile(p)
+		if err != nil {
+			return err
+		}
+
+		body, err := r.render(ctx, p)
+		if err != nil {
+			return err
+		}
+
+		file = filepath.Join(outDir, file)
+		if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+			return fmt.Errorf("could not export %s: %w", p, err)
+		}
+
+		if err := os.WriteFile(file, body, 0o644); err != nil {
+			return fmt.Errorf("could not export %s: %w", p, err)
+		}
+	}
+
+	return nil
+}
+
+// render serves a GET request to p through the Router, returning the body of a 200 response.
+func (r *Ranger) render(ctx context.Context, p string) ([]byte, error) {
+	// NOTE: as with requests a server receives, the body is never nil.
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, r.url.JoinPath(p).String(), http.NoBody)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %s", trails.ErrNotValid, p, err)
+	}
+	req.Header.Set("Accept", "text/html")
+
+	w := &exportWriter{header: make(http.Header)}
+	r.Router.ServeHTTP(w, req)
+
+	if w.code == 0 {
+		w.code = http.StatusOK
+	}
+
+	if w.code != http.StatusOK {
+		return nil, fmt.Errorf("%w: %s responded with %d", trails.ErrNotValid, p, w.code)
+	}
+
+	return w.body.Bytes(), nil
+}
+
+// exportFile is the file, relative to the directory pages are exported to, the page at p is written to.
+func exportFile(p string) (string, error) {
+	if !strings.HasPrefix(p, "/") || strings.ContainsAny(p, "?#") {
+		return "", fmt.Errorf("%w: %q is not an absolute path", trails.ErrNotValid, p)
+	}
+
+	for _, seg := range strings.Split(p, "/") {
+		if seg == ".." {
+			return "", fmt.Errorf("%w: %q escapes the export directory", trails.ErrNotValid, p)
+		}
+	}
+
+	clean := path.Clean(p)
+	if path.Ext(clean) == "" {
+		clean = path.Join(clean, "index.html")
+	}
+
+	return filepath.FromSlash(strings.TrimPrefix(clean, "/")), nil
+}
+
+// An exportWriter records the response to a page Export renders.
+type exportWriter struct {
+	body   bytes.Buffer
+	code   int
+	header http.Header
+}
+
+func (w *exportWriter) Header() http.Header { return w.header }
+
+func (w *exportWriter) Write(b []byte) (int, error) {
+	if w.code == 0 {
+		w.code = http.StatusOK
+	}
+
+	return w.body.Write(b)
+}
+
+func (w *exportWriter) WriteHeader(code int) {
+	if w.code == 0 {
+		w.code = code
+	}
+}
@@ -0,0 +1,88 @@
+package ranger_test
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/require"
+	"github.com/xy-planning-network/trails"
+	"github.com/xy-planning-network/trails/http/resp"
+	"github.com/xy-planning-network/trails/postgres"
+	"github.com/xy-planning-network/trails/ranger"
+	"go.uber.org/mock/gomock"
+)
+
+func TestRangerExport(t *testing.T) {
+	// Arrange
+	t.Setenv("APP_DESCRIPTION", "An app")
+	t.Setenv("APP_TITLE", "App")
+
+	cfg := ranger.Config[trails.User]{
+		FS: fstest.MapFS{"tmpl/page.tmpl": {Data: []byte("<h1>{{ .Data }}</h1>")}},
+	}
+	cfg.UseDBMock(postgres.NewMockDatabaseService(gomock.NewController(t)))
+	cfg.UseLogOutput(new(bytes.Buffer))
+
+	rng, err := ranger.New(cfg)
+	require.NoError(t, err)
+
+	page := func(title string) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			_ = rng.Html(w, r, resp.Tmpls("tmpl/page.tmpl"), resp.Data(title))
+		}
+	}
+	rng.Router.Get("/", page("Home"))
+	rng.Router.Get("/pricing", page("Pricing"))
+	rng.Router.Get("/404.html", page("Lost"))
+
+	t.Run("Pages", func(t *testing.T) {
+		// Arrange
+		dir := t.TempDir()
+
+		// Act
+		err := rng.Export(context.Background(), []string{"/", "/pricing", "/404.html"}, dir)
+
+		// Assert
+		require.NoError(t, err)
+
+		for file, expected := range map[string]string{
+			"index.html":         "<h1>Home</h1>",
+			"pricing/index.html": "<h1>Pricing</h1>",
+			"404.html":           "<h1>Lost</h1>",
+		} {
+			b, err := os.ReadFile(filepath.Join(dir, file))
+			require.NoError(t, err)
+			require.Equal(t, expected, string(b))
+		}
+	})
+
+	for _, tc := range []struct {
+		name string
+		path string
+	}{
+		{"Not-Absolute", "pricing"},
+		{"Escapes", "/../pricing"},
+		{"Query", "/pricing?plan=pro"},
+		{"Not-Found", "/missing"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			dir := t.TempDir()
+
+			// Act
+			err := rng.Export(context.Background(), []string{tc.path}, dir)
+
+			// Assert
+			require.ErrorIs(t, err, trails.ErrNotValid)
+
+			entries, err := os.ReadDir(dir)
+			require.NoError(t, err)
+			require.Empty(t, entries)
+		})
+	}
+}
@@ -0,0 +1,204 @@
+package ranger
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/http"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"github.com/xy-planning-network/trails/logger"
+	"github.com/xy-planning-network/trails/postgres"
+)
+
+const (
+	// BuildInfoPath is the URL path [Config].HealthEndpoints serves build information at.
+	BuildInfoPath = "/buildinfo"
+
+	// HealthzPath is the URL path [Config].HealthEndpoints serves liveness at.
+	HealthzPath = "/healthz"
+
+	// ReadyzPath is the URL path [Config].HealthEndpoints serves readiness at.
+	ReadyzPath = "/readyz"
+
+	// DefaultHealthCheckTimeout is how long readiness waits on each HealthCheck.
+	DefaultHealthCheckTimeout = 5 * time.Second
+)
+
+// A HealthCheck reports whether something the application depends on, e.g., a database,
+// is ready to handle requests, returning an error if it is not.
+type HealthCheck func(ctx context.Context) error
+
+// health tracks the HealthChecks readiness runs.
+type health struct {
+	sync.RWMutex
+	checks map[string]HealthCheck
+	on     bool
+}
+
+// AddHealthCheck registers check to run under name whenever readiness is requested at ReadyzPath,
+// replacing any check already registered under name.
+//
+// By default, Ranger checks "database", if configured.
+func (r *Ranger) AddHealthCheck(name string, check HealthCheck) {
+	r.health.Lock()
+	defer r.health.Unlock()
+
+	if r.health.checks == nil {
+		r.health.checks = make(map[string]HealthCheck)
+	}
+
+	r.health.checks[name] = check
+}
+
+// A BuildInfo describes the build of the running application.
+type BuildInfo struct {
+	GoVersion string `json:"goVersion"`
+	Modified  bool   `json:"modified"`
+	Path      string `json:"path"`
+	Revision  string `json:"revision,omitempty"`
+	Time      string `json:"time,omitempty"`
+	Version   string `json:"version"`
+}
+
+// ReadBuildInfo reads the BuildInfo embedded in the running binary,
+// reporting whether it was available.
+func ReadBuildInfo() (BuildInfo, bool) {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return BuildInfo{}, false
+	}
+
+	bi := BuildInfo{GoVersion: info.GoVersion, Path: info.Main.Path, Version: info.Main.Version}
+	for _, setting := range info.Settings {
+		switch setting.Key {
+		case "vcs.modified":
+			bi.Modified = setting.Value == "true"
+		case "vcs.revision":
+			bi.Revision = setting.Value
+		case "vcs.time":
+			bi.Time = setting.Value
+		}
+	}
+
+	return bi, true
+}
+
+// defaultHealthChecks registers a check pinging the database, if configured.
+//
+// Sessions are stored in cookies, so there is no session store to check.
+func (r *Ranger) defaultHealthChecks() {
+	if db, ok := r.db.(*postgres.DatabaseServiceImpl); ok {
+		r.AddHealthCheck("database", func(ctx context.Context) error {
+			sqlDB, err := db.DB.DB()
+			if err != nil {
+				return err
+			}
+
+			return sqlDB.PingContext(ctx)
+		})
+	}
+}
+
+// probes responds to liveness and readiness requests ahead of h,
+// so middlewares like ForceHTTPS and LogRequest never apply to them.
+//
+// If health endpoints are off or served by the internal listener, probes returns h.
+func (r *Ranger) probes(h http.Handler) http.Handler {
+	r.health.RLock()
+	on := r.health.on
+	r.health.RUnlock()
+
+	if !on || r.internal.srv != nil {
+		return h
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodGet && req.Method != http.MethodHead {
+			h.ServeHTTP(w, req)
+			return
+		}
+
+		switch req.URL.Path {
+		case HealthzPath:
+			writeHealth(w, http.StatusOK, map[string]any{"status": "ok"})
+		case ReadyzPath:
+			r.handleReadyz(w, req)
+		default:
+			h.ServeHTTP(w, req)
+		}
+	})
+}
+
+// handleReadyz runs every HealthCheck concurrently,
+// responding with 200 if all pass or 503 if any fail or the Ranger is shutting down.
+//
+// Errors are logged, not responded with, since readiness is served without authentication.
+func (r *Ranger) handleReadyz(w http.ResponseWriter, req *http.Request) {
+	if r.ctx != nil && r.ctx.Err() != nil {
+		writeHealth(w, http.StatusServiceUnavailable, map[string]any{"status": "shutting down"})
+		return
+	}
+
+	r.health.RLock()
+	checks := maps.Clone(r.health.checks)
+	r.health.RUnlock()
+
+	ctx, cancel := context.WithTimeout(req.Context(), DefaultHealthCheckTimeout)
+	defer cancel()
+
+	var (
+		mu      sync.Mutex
+		results = make(map[string]string, len(checks))
+		wg      sync.WaitGroup
+	)
+	for name, check := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			result := "ok"
+			if err := check(ctx); err != nil {
+				result = "failed"
+				r.Logger.Warn(fmt.Sprintf("health check %q failed: %s", name, err), &logger.LogContext{Error: err, Request: req})
+			}
+
+			mu.Lock()
+			results[name] = result
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	code, status := http.StatusOK, "ok"
+	for _, result := range results {
+		if result != "ok" {
+			code, status = http.StatusServiceUnavailable, "unavailable"
+			break
+		}
+	}
+
+	writeHealth(w, code, map[string]any{"checks": results, "status": status})
+}
+
+// handleBuildInfo responds with the BuildInfo of the running application.
+func (r *Ranger) handleBuildInfo(w http.ResponseWriter, _ *http.Request) {
+	bi, ok := ReadBuildInfo()
+	if !ok {
+		writeHealth(w, http.StatusNotFound, map[string]any{"error": "build info unavailable"})
+		return
+	}
+
+	writeHealth(w, http.StatusOK, bi)
+}
+
+// writeHealth responds with code and body encoded as JSON.
+func writeHealth(w http.ResponseWriter, code int, body any) {
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(body)
+}
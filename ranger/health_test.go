@@ -0,0 +1,156 @@
+package ranger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/xy-planning-network/trails"
+	"github.com/xy-planning-network/trails/postgres"
+	"go.uber.org/mock/gomock"
+)
+
+// newTestRanger constructs a *Ranger from cfg with a mock database,
+// returning it alongside the buffer it logs to.
+func newTestRanger(t *testing.T, cfg Config[trails.User]) (*Ranger, *bytes.Buffer) {
+	t.Helper()
+	t.Setenv("APP_DESCRIPTION", "An app")
+	t.Setenv("APP_TITLE", "App")
+
+	b := new(bytes.Buffer)
+	cfg.FS = fstest.MapFS{}
+	cfg.UseDBMock(postgres.NewMockDatabaseService(gomock.NewController(t)))
+	cfg.UseLogOutput(b)
+
+	rng, err := New(cfg)
+	require.NoError(t, err)
+
+	return rng, b
+}
+
+func TestRangerReadyz(t *testing.T) {
+	errCheck := errors.New("unreachable")
+	pass := func(context.Context) error { return nil }
+	fail := func(context.Context) error { return errCheck }
+	block := func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+
+	for _, tc := range []struct {
+		name     string
+		checks   map[string]HealthCheck
+		expected int
+		status   string
+		results  map[string]any
+	}{
+		{"No-Checks", nil, http.StatusOK, "ok", map[string]any{}},
+		{"Pass", map[string]HealthCheck{"a": pass, "b": pass}, http.StatusOK, "ok", map[string]any{"a": "ok", "b": "ok"}},
+		{"Fail", map[string]HealthCheck{"a": fail}, http.StatusServiceUnavailable, "unavailable", map[string]any{"a": "failed"}},
+		{"Some-Fail", map[string]HealthCheck{"a": pass, "b": fail}, http.StatusServiceUnavailable, "unavailable", map[string]any{"a": "ok", "b": "failed"}},
+		{"Timeout", map[string]HealthCheck{"a": pass, "b": block}, http.StatusServiceUnavailable, "unavailable", map[string]any{"a": "ok", "b": "failed"}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			rng, b := newTestRanger(t, Config[trails.User]{HealthEndpoints: true})
+			for name, check := range tc.checks {
+				rng.AddHealthCheck(name, check)
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+			defer cancel()
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequestWithContext(ctx, http.MethodGet, ReadyzPath, nil)
+
+			// Act
+			rng.probes(rng.Router).ServeHTTP(w, r)
+
+			// Assert
+			require.Equal(t, tc.expected, w.Code)
+			require.Equal(t, "no-store", w.Header().Get("Cache-Control"))
+
+			var actual map[string]any
+			require.NoError(t, json.NewDecoder(w.Body).Decode(&actual))
+			require.Equal(t, tc.status, actual["status"])
+			require.Equal(t, tc.results, actual["checks"])
+
+			if tc.expected != http.StatusOK {
+				require.Contains(t, b.String(), "health check")
+			}
+		})
+	}
+
+	t.Run("Replace", func(t *testing.T) {
+		// Arrange
+		rng, _ := newTestRanger(t, Config[trails.User]{HealthEndpoints: true})
+		rng.AddHealthCheck("a", fail)
+		rng.AddHealthCheck("a", pass)
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, ReadyzPath, nil)
+
+		// Act
+		rng.probes(rng.Router).ServeHTTP(w, r)
+
+		// Assert
+		require.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("Shutting-Down", func(t *testing.T) {
+		// Arrange
+		rng, _ := newTestRanger(t, Config[trails.User]{HealthEndpoints: true})
+		rng.AddHealthCheck("a", pass)
+		rng.ctx, rng.cancel = context.WithCancel(context.Background())
+		rng.Shutdown()
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, ReadyzPath, nil)
+
+		// Act
+		rng.probes(rng.Router).ServeHTTP(w, r)
+
+		// Assert
+		require.Equal(t, http.StatusServiceUnavailable, w.Code)
+		require.Contains(t, w.Body.String(), "shutting down")
+	})
+}
+
+func TestRangerProbes(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		on       bool
+		method   string
+		path     string
+		expected int
+	}{
+		{"Healthz", true, http.MethodGet, HealthzPath, http.StatusOK},
+		{"Healthz-Head", true, http.MethodHead, HealthzPath, http.StatusOK},
+		{"Readyz", true, http.MethodGet, ReadyzPath, http.StatusOK},
+		{"Readyz-Post", true, http.MethodPost, ReadyzPath, http.StatusNotFound},
+		{"Other", true, http.MethodGet, "/other", http.StatusNotFound},
+		{"Off-Healthz", false, http.MethodGet, HealthzPath, http.StatusNotFound},
+		{"Off-Readyz", false, http.MethodGet, ReadyzPath, http.StatusNotFound},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			rng, _ := newTestRanger(t, Config[trails.User]{HealthEndpoints: tc.on})
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(tc.method, tc.path, nil)
+
+			// Act
+			rng.probes(rng.Router).ServeHTTP(w, r)
+
+			// Assert
+			require.Equal(t, tc.expected, w.Code)
+		})
+	}
+}
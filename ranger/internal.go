@@ -0,0 +1,35 @@
+package ranger
+
+import (
+	"net/http"
+
+	"github.com/xy-planning-network/trails/http/middleware"
+	"github.com/xy-planning-network/trails/http/router"
+)
+
+// internalListener serves endpoints for operators on INTERNAL_PORT, apart from the web server.
+type internalListener struct {
+	mux *http.ServeMux
+	srv *http.Server
+}
+
+// handleInternal serves route on the internal listener, if INTERNAL_PORT is set,
+// or the Router otherwise.
+func (r *Ranger) handleInternal(route router.Route) {
+	if r.internal.srv == nil {
+		r.Router.Handle(route)
+		return
+	}
+
+	r.internal.mux.Handle(route.Method+" "+route.Path, middleware.Chain(route.Handler, route.Middlewares...))
+}
+
+// defaultInternalRoutes serves liveness and readiness on the internal listener, if health endpoints are on.
+func (r *Ranger) defaultInternalRoutes() {
+	if r.health.on {
+		r.internal.mux.HandleFunc("GET "+HealthzPath, func(w http.ResponseWriter, _ *http.Request) {
+			writeHealth(w, http.StatusOK, map[string]any{"status": "ok"})
+		})
+		r.internal.mux.HandleFunc("GET "+ReadyzPath, r.handleReadyz)
+	}
+}
@@ -0,0 +1,124 @@
+package ranger
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/xy-planning-network/trails"
+)
+
+func TestRangerInternal(t *testing.T) {
+	setenv := func(t *testing.T) {
+		t.Setenv("ADMIN_PASSWORD", "secret")
+		t.Setenv("ADMIN_USERNAME", "admin")
+		t.Setenv("METRICS_PASSWORD", "scrape")
+		t.Setenv("METRICS_USERNAME", "prometheus")
+	}
+	cfg := Config[trails.User]{Diagnostics: true, HealthEndpoints: true, LogLevelEndpoint: true, Metrics: true}
+
+	// NOTE: diagnostics are served without authentication on the internal listener.
+	routes := []struct {
+		path         string
+		username     string
+		password     string
+		internalAuth bool
+	}{
+		{HealthzPath, "", "", false},
+		{ReadyzPath, "", "", false},
+		{BuildInfoPath, "admin", "secret", true},
+		{LogLevelPath, "admin", "secret", true},
+		{MetricsPath, "prometheus", "scrape", true},
+		{PprofPath, "admin", "secret", false},
+		{VarsPath, "admin", "secret", false},
+	}
+
+	t.Run("Internal-Port", func(t *testing.T) {
+		// Arrange
+		setenv(t)
+		t.Setenv("INTERNAL_PORT", "9090")
+		rng, _ := newTestRanger(t, cfg)
+		public := rng.probes(rng.Router)
+
+		require.Equal(t, ":9090", rng.internal.srv.Addr)
+
+		for _, route := range routes {
+			t.Run(route.path, func(t *testing.T) {
+				// Arrange
+				w := httptest.NewRecorder()
+				r := httptest.NewRequest(http.MethodGet, route.path, nil)
+				if route.internalAuth {
+					r.SetBasicAuth(route.username, route.password)
+				}
+
+				// Act
+				rng.internal.srv.Handler.ServeHTTP(w, r)
+
+				// Assert
+				require.Equal(t, http.StatusOK, w.Code)
+
+				// Arrange
+				w = httptest.NewRecorder()
+
+				// Act
+				public.ServeHTTP(w, r)
+
+				// Assert
+				require.Equal(t, http.StatusNotFound, w.Code)
+			})
+		}
+	})
+
+	t.Run("No-Internal-Port", func(t *testing.T) {
+		// Arrange
+		setenv(t)
+		t.Setenv("INTERNAL_PORT", "")
+		rng, _ := newTestRanger(t, cfg)
+		public := rng.probes(rng.Router)
+
+		require.Nil(t, rng.internal.srv)
+
+		for _, route := range routes {
+			t.Run(route.path, func(t *testing.T) {
+				// Arrange
+				w := httptest.NewRecorder()
+				r := httptest.NewRequest(http.MethodGet, route.path, nil)
+				if route.username != "" {
+					r.SetBasicAuth(route.username, route.password)
+				}
+
+				// Act
+				public.ServeHTTP(w, r)
+
+				// Assert
+				require.Equal(t, http.StatusOK, w.Code)
+			})
+		}
+	})
+
+	t.Run("Unauthorized", func(t *testing.T) {
+		// Arrange
+		setenv(t)
+		t.Setenv("INTERNAL_PORT", "9090")
+		rng, _ := newTestRanger(t, cfg)
+
+		for _, route := range routes {
+			if !route.internalAuth {
+				continue
+			}
+
+			t.Run(route.path, func(t *testing.T) {
+				// Arrange
+				w := httptest.NewRecorder()
+				r := httptest.NewRequest(http.MethodGet, route.path, nil)
+
+				// Act
+				rng.internal.srv.Handler.ServeHTTP(w, r)
+
+				// Assert
+				require.Equal(t, http.StatusUnauthorized, w.Code)
+			})
+		}
+	})
+}
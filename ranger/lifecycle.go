@@ -0,0 +1,49 @@
+package ranger
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/xy-planning-network/trails/logger"
+)
+
+// A StartFn prepares a component of the application when Ranger starts,
+// e.g., warming a cache, verifying an external dependency, or registering with service discovery.
+type StartFn func(context.Context) error
+
+// OnStart has Guide and Work call fn before serving requests or running background work,
+// after those StartFns added before fn.
+// If fn returns an error, Guide or Work return it without starting.
+func (r *Ranger) OnStart(fn StartFn) {
+	r.starts = append(r.starts, fn)
+}
+
+// OnReady has Guide call fn once the web server is listening,
+// or Work once background jobs are running,
+// after those StartFns added before fn.
+// If fn returns an error, it is logged; Ranger keeps running.
+func (r *Ranger) OnReady(fn StartFn) {
+	r.readies = append(r.readies, fn)
+}
+
+// start calls each StartFn added with OnStart, in order, stopping at the first error.
+func (r *Ranger) start() error {
+	for _, fn := range r.starts {
+		if err := fn(r.ctx); err != nil {
+			return fmt.Errorf("could not start: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// ready calls each StartFn added with OnReady, in order, logging those returning an error.
+func (r *Ranger) ready(pc uintptr) {
+	defer logger.CapturePanics(r.Logger)
+
+	for _, fn := range r.readies {
+		if err := fn(r.ctx); err != nil {
+			r.Error(fmt.Sprintf("ready hook failed: %s", err), &logger.LogContext{Caller: pc, Error: err})
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package ranger_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/xy-planning-network/trails"
+	"github.com/xy-planning-network/trails/ranger"
+)
+
+func TestRangerLifecycle(t *testing.T) {
+	newRanger := func(t *testing.T) *ranger.Ranger {
+		t.Setenv("APP_DESCRIPTION", "An app")
+		t.Setenv("APP_TITLE", "App")
+		t.Setenv("PORT", ":0")
+
+		cfg := ranger.Config[trails.User]{FS: fstest.MapFS{}, MaintMode: true}
+		cfg.UseLogOutput(new(bytes.Buffer))
+
+		rng, err := ranger.New(cfg)
+		require.NoError(t, err)
+
+		return rng
+	}
+
+	t.Run("Ready", func(t *testing.T) {
+		// Arrange
+		rng := newRanger(t)
+
+		var calls []string
+		rng.OnStart(func(context.Context) error {
+			calls = append(calls, "start")
+			return nil
+		})
+		rng.OnReady(func(context.Context) error {
+			calls = append(calls, "ready")
+			rng.Shutdown()
+			return nil
+		})
+
+		// Act
+		done := make(chan error, 1)
+		go func() { done <- rng.Guide() }()
+
+		// Assert
+		select {
+		case err := <-done:
+			require.NoError(t, err)
+		case <-time.After(5 * time.Second):
+			t.Fatal("Guide did not return")
+		}
+		require.Equal(t, []string{"start", "ready"}, calls)
+	})
+
+	t.Run("Start-Err", func(t *testing.T) {
+		// Arrange
+		rng := newRanger(t)
+
+		errStart := errors.New("unreachable")
+		rng.OnStart(func(context.Context) error { return errStart })
+		rng.OnReady(func(context.Context) error {
+			t.Error("called OnReady")
+			return nil
+		})
+
+		// Act
+		err := rng.Guide()
+
+		// Assert
+		require.ErrorIs(t, err, errStart)
+	})
+}
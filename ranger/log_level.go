@@ -0,0 +1,73 @@
+package ranger
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+
+	"github.com/xy-planning-network/trails/http/resp"
+)
+
+// LogLevel reports the level app and worker logs are written at.
+func (r *Ranger) LogLevel() slog.Level { return r.logLevel.Level() }
+
+// SetLogLevel changes the level app and worker logs are written at, without restarting,
+// e.g., to [slog.LevelDebug] while debugging a live instance.
+// Audit logs are unaffected.
+func (r *Ranger) SetLogLevel(lvl slog.Level) {
+	prev := r.logLevel.Level()
+	r.logLevel.Set(lvl)
+	r.Logger.Warn(fmt.Sprintf("changed log level from %s to %s", prev, lvl), nil)
+}
+
+// toggleDebug sets the log level to DEBUG or, if already DEBUG, back to the level set by LOG_LEVEL.
+func (r *Ranger) toggleDebug() {
+	lvl := slog.LevelDebug
+	if r.LogLevel() == slog.LevelDebug {
+		lvl = r.config.Log.Level
+	}
+
+	r.SetLogLevel(lvl)
+}
+
+// watchLogLevelSignal toggles DEBUG logs each time the process receives logLevelSignal,
+// until the Ranger shuts down.
+//
+// On platforms without logLevelSignal, watchLogLevelSignal does nothing.
+func (r *Ranger) watchLogLevelSignal() {
+	if logLevelSignal == nil {
+		return
+	}
+
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, logLevelSignal)
+	go func() {
+		defer signal.Stop(ch)
+		for {
+			select {
+			case <-ch:
+				r.toggleDebug()
+			case <-r.ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// handleLogLevel responds with the level logs are written at,
+// first changing it to the "level" form value on requests other than GET.
+func (r *Ranger) handleLogLevel(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		var lvl slog.Level
+		if err := lvl.UnmarshalText([]byte(req.FormValue("level"))); err != nil {
+			r.Responder.Json(w, req, resp.Code(http.StatusBadRequest), resp.Data(map[string]any{"error": err.Error()}))
+			return
+		}
+
+		r.SetLogLevel(lvl)
+	}
+
+	r.Responder.Json(w, req, resp.Data(map[string]any{"level": r.LogLevel().String()}))
+}
@@ -0,0 +1,130 @@
+package ranger
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/xy-planning-network/trails"
+)
+
+func TestRangerHandleLogLevel(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		method   string
+		level    string
+		code     int
+		expected slog.Level
+	}{
+		{"Get", http.MethodGet, "", http.StatusOK, slog.LevelInfo},
+		{"Get-Ignores-Level", http.MethodGet, "DEBUG", http.StatusOK, slog.LevelInfo},
+		{"Post-Debug", http.MethodPost, "DEBUG", http.StatusOK, slog.LevelDebug},
+		{"Put-Warn", http.MethodPut, "warn", http.StatusOK, slog.LevelWarn},
+		{"Post-Offset", http.MethodPost, "ERROR+2", http.StatusOK, slog.LevelError + 2},
+		{"Post-Invalid", http.MethodPost, "LOUD", http.StatusBadRequest, slog.LevelInfo},
+		{"Post-Empty", http.MethodPost, "", http.StatusBadRequest, slog.LevelInfo},
+		{"Delete", http.MethodDelete, "DEBUG", http.StatusMethodNotAllowed, slog.LevelInfo},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			t.Setenv("ADMIN_PASSWORD", "secret")
+			t.Setenv("ADMIN_USERNAME", "admin")
+			t.Setenv("LOG_LEVEL", "INFO")
+			rng, _ := newTestRanger(t, Config[trails.User]{LogLevelEndpoint: true})
+
+			form := url.Values{"level": {tc.level}}.Encode()
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(tc.method, LogLevelPath+"?"+form, strings.NewReader(form))
+			r.Header.Set("Accept", "application/json")
+			r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			r.SetBasicAuth("admin", "secret")
+
+			// Act
+			rng.Router.ServeHTTP(w, r)
+
+			// Assert
+			require.Equal(t, tc.code, w.Code)
+			require.Equal(t, tc.expected, rng.LogLevel())
+
+			if tc.code == http.StatusOK {
+				var actual map[string]any
+				require.NoError(t, json.NewDecoder(w.Body).Decode(&actual))
+				require.Equal(t, tc.expected.String(), actual["data"].(map[string]any)["level"])
+			}
+		})
+	}
+
+	t.Run("Unauthorized", func(t *testing.T) {
+		// Arrange
+		t.Setenv("ADMIN_PASSWORD", "secret")
+		t.Setenv("ADMIN_USERNAME", "admin")
+		rng, _ := newTestRanger(t, Config[trails.User]{LogLevelEndpoint: true})
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, LogLevelPath+"?level=DEBUG", nil)
+
+		// Act
+		rng.Router.ServeHTTP(w, r)
+
+		// Assert
+		require.Equal(t, http.StatusUnauthorized, w.Code)
+		require.NotEqual(t, slog.LevelDebug, rng.LogLevel())
+	})
+}
+
+func TestRangerToggleDebug(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		env      string
+		expected []slog.Level
+	}{
+		{"Info", "INFO", []slog.Level{slog.LevelDebug, slog.LevelInfo, slog.LevelDebug}},
+		{"Error", "ERROR", []slog.Level{slog.LevelDebug, slog.LevelError, slog.LevelDebug}},
+		{"Debug", "DEBUG", []slog.Level{slog.LevelDebug, slog.LevelDebug, slog.LevelDebug}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			t.Setenv("LOG_LEVEL", tc.env)
+			rng, b := newTestRanger(t, Config[trails.User]{})
+
+			for _, expected := range tc.expected {
+				// Act
+				rng.toggleDebug()
+
+				// Assert
+				require.Equal(t, expected, rng.LogLevel())
+			}
+			require.Contains(t, b.String(), "changed log level")
+		})
+	}
+
+	t.Run("Signal", func(t *testing.T) {
+		if logLevelSignal == nil {
+			t.Skip("no log level signal on this platform")
+		}
+
+		// Arrange
+		t.Setenv("LOG_LEVEL", "INFO")
+		rng, _ := newTestRanger(t, Config[trails.User]{LogLevelSignal: true})
+		_, cancel := rng.Context()
+		defer cancel()
+
+		p, err := os.FindProcess(os.Getpid())
+		require.NoError(t, err)
+
+		rng.watchLogLevelSignal()
+
+		// Act
+		require.NoError(t, p.Signal(logLevelSignal))
+
+		// Assert
+		require.Eventually(t, func() bool { return rng.LogLevel() == slog.LevelDebug }, time.Second, 10*time.Millisecond)
+	})
+}
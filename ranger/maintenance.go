@@ -0,0 +1,163 @@
+package ranger
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/xy-planning-network/trails/http/middleware"
+	"github.com/xy-planning-network/trails/http/router"
+	"github.com/xy-planning-network/trails/http/template"
+	"github.com/xy-planning-network/trails/logger"
+)
+
+// MaintenanceConfig customizes the page a Ranger booted in maintenance mode serves; cf. [Config].MaintMode.
+type MaintenanceConfig struct {
+	// Data is passed to Template when rendering it.
+	//
+	// If Data is nil, the email set by CONTACT_US_EMAIL is, as the default template expects.
+	Data any
+
+	// RetryAfter is how long clients are told to wait before trying again,
+	// in the "Retry-After" header.
+	//
+	// If RetryAfter is zero, 10 minutes is used.
+	RetryAfter time.Duration
+
+	// Routes are served as usual instead of the maintenance page,
+	// e.g., an endpoint reporting the status of the maintenance.
+	// Liveness and readiness are served when [Config].HealthEndpoints is true.
+	Routes []router.Route
+
+	// Template is the template in [Config].FS rendered for clients not accepting JSON.
+	//
+	// If Template is empty, "tmpl/maintenance.tmpl" is used.
+	Template string
+}
+
+// handler responds with 503 and a "Retry-After" header,
+// writing JSON in the same shape as middleware.MaintenanceGate if the request's "Accept" header has "application/json" in it
+// and rendering c.Template with p otherwise.
+func (c MaintenanceConfig) handler(p *template.Parser, l logger.Logger) http.HandlerFunc {
+	tmpl := cmp.Or(c.Template, defaultMaintTmpl)
+	retryAfter := strconv.Itoa(int(cmp.Or(c.RetryAfter, defaultMaintRetryAfter).Seconds()))
+
+	return func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Retry-After", retryAfter)
+
+		for _, v := range req.Header.Values("Accept") {
+			if strings.Contains(v, "application/json") {
+				w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+				w.WriteHeader(http.StatusServiceUnavailable)
+				json.NewEncoder(w).Encode(map[string]any{"data": map[string]string{"error": middleware.MaintenanceMsg}})
+				return
+			}
+		}
+
+		w.WriteHeader(http.StatusServiceUnavailable)
+
+		t, err := p.Parse(tmpl)
+		if err != nil {
+			l.Error(err.Error(), nil)
+			return
+		}
+
+		if err := t.Execute(w, c.Data); err != nil {
+			l.Error(err.Error(), nil)
+		}
+	}
+}
+
+// maintenance tracks whether maintenance mode is on
+// and what requests are allowed through while it is.
+type maintenance struct {
+	sync.RWMutex
+	on       bool
+	paths    []string
+	prefixes []netip.Prefix
+}
+
+// SetMaintenance turns maintenance mode on or off while the web server runs.
+// While it is on, requests are responded to with 503 by middleware.MaintenanceGate
+// unless they match an entry in allowlist.
+//
+// Each entry in allowlist is one of:
+//   - an IP address, e.g., "203.0.113.7"
+//   - a CIDR prefix, e.g., "203.0.113.0/24"
+//   - a URL path, beginning with "/", e.g., "/health"
+//
+// IP addresses are matched against the address middleware.ClientIP derives from the proxies TRUSTED_PROXIES lists,
+// so clients cannot bypass maintenance mode by setting the "X-Forwarded-For" or "X-Real-Ip" headers.
+// Paths match exactly.
+//
+// If any entry is invalid, SetMaintenance returns trails.ErrNotValid and leaves maintenance mode unchanged.
+//
+// SetMaintenance is distinct from Config.MaintMode,
+// which boots a Ranger able to do nothing but render a maintenance page.
+func (r *Ranger) SetMaintenance(on bool, allowlist []string) error {
+	var (
+		paths []string
+		ips   []string
+	)
+
+	for _, entry := range allowlist {
+		entry = strings.TrimSpace(entry)
+		if strings.HasPrefix(entry, "/") {
+			paths = append(paths, entry)
+			continue
+		}
+
+		ips = append(ips, entry)
+	}
+
+	prefixes, err := middleware.ParsePrefixes(ips)
+	if err != nil {
+		return fmt.Errorf("allowlist: %w", err)
+	}
+
+	r.maint.Lock()
+	defer r.maint.Unlock()
+
+	r.maint.on = on
+	r.maint.paths = paths
+	r.maint.prefixes = prefixes
+
+	return nil
+}
+
+// InMaintenance asserts whether req ought to be blocked because maintenance mode is on
+// and req matches nothing in the allowlist passed to SetMaintenance.
+func (r *Ranger) InMaintenance(req *http.Request) bool {
+	r.maint.RLock()
+	defer r.maint.RUnlock()
+
+	if !r.maint.on {
+		return false
+	}
+
+	for _, p := range r.maint.paths {
+		if req.URL.Path == p {
+			return false
+		}
+	}
+
+	addr, err := netip.ParseAddr(middleware.ClientIP(req, r.proxies))
+	if err != nil {
+		return true
+	}
+
+	addr = addr.Unmap()
+	for _, prefix := range r.maint.prefixes {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+
+	return true
+}
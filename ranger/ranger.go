@@ -2,25 +2,33 @@ package ranger
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log/slog"
+	"net"
 	"net/http"
+	"net/netip"
 	"net/url"
 	"os"
 	"os/signal"
 	"runtime"
-	"runtime/debug"
 	"syscall"
 	"time"
 
 	_ "github.com/joho/godotenv/autoload"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/xy-planning-network/trails"
 	"github.com/xy-planning-network/trails/http/middleware"
 	"github.com/xy-planning-network/trails/http/resp"
 	"github.com/xy-planning-network/trails/http/router"
 	"github.com/xy-planning-network/trails/http/session"
 	"github.com/xy-planning-network/trails/http/template"
+	"github.com/xy-planning-network/trails/jobs"
 	"github.com/xy-planning-network/trails/logger"
+	"github.com/xy-planning-network/trails/mailer"
 	"github.com/xy-planning-network/trails/postgres"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
 )
 
 // A RangerUser is the kind of functionality an application's User must fulfill
@@ -38,16 +46,36 @@ type Ranger struct {
 	*resp.Responder
 	router.Router
 
+	admin      *Admin
 	assetsURL  *url.URL
+	auditLogs  logSinks
 	cancel     context.CancelFunc
+	config     Env
 	ctx        context.Context
 	db         postgres.DatabaseService
 	env        trails.Environment
+	flushes    []ShutdownFn
+	health     health
+	internal   internalListener
+	jobs       *jobs.Manager
+	logLevel   *slog.LevelVar
+	logReq     middleware.Adapter
+	logSignal  bool
+	logs       logSinks
+	mail       *mailer.TemplateMailer
+	maint      maintenance
 	metadata   Metadata
+	metrics    *prometheus.Registry
 	migrations []postgres.Migration
+	proxies    []netip.Prefix
+	readies    []StartFn
+	recordings middleware.RecordStore
+	redirect   *http.Server
 	sessions   session.SessionStorer
-	shutdowns  []ShutdownFn
+	shutdowns  []ShutdownGroup
 	srv        *http.Server
+	starts     []StartFn
+	tasks      *jobs.Scheduler
 	url        *url.URL
 }
 
@@ -55,29 +83,43 @@ type Ranger struct {
 // Default options are applied first followed by the options passed into New.
 // Options supplied to New overwrite default configurations.
 func New[U RangerUser](cfg Config[U]) (*Ranger, error) {
-	err := cfg.Valid()
+	e, err := cfg.loadEnv()
 	if err != nil {
 		return nil, err
 	}
 
-	if cfg.logoutput == nil {
-		cfg.logoutput = os.Stdout
+	r := &Ranger{config: e}
+
+	// Setup initial configuration
+	r.env = e.App.Environment
+	r.logLevel = defaultLogLevel(e.Log)
+	r.logs, err = defaultLogSinks(logOutputEnvVar, e.Log.Output, e, r.logLevel, cfg.logoutput)
+	if err != nil {
+		return nil, err
 	}
 
-	r := new(Ranger)
+	r.auditLogs, err = defaultLogSinks(logAuditOutputEnvVar, e.Log.AuditOutput, e, slog.LevelInfo, cfg.logoutput)
+	if err != nil {
+		return nil, err
+	}
 
-	// Setup initial configuration
-	r.env = trails.EnvVarOrEnv(environmentEnvVar, trails.Development)
-	r.Logger = defaultAppLogger(r.env, cfg.logoutput)
+	r.Logger = defaultAppLogger(r.env, r.logs, e.Log.SentryDSN, cfg.ErrorEnrichers)
+	defaultAuditLogger(r.env, r.auditLogs, e.Log.AuditChecksum)
+	logger.SetCrashReportDir(e.Log.CrashDir)
 	if _, ok := r.Logger.(*logger.SentryLogger); ok {
-		r.shutdowns = append(r.shutdowns, logger.FlushSentry)
+		r.flushes = append(r.flushes, logger.FlushSentry)
+	}
+
+	if cfg.StartupReport {
+		r.Logger.Info("resolved configuration", &logger.LogContext{Data: configReport(&r.config, cfg.EnvSections)})
 	}
 
 	r.ctx, r.cancel = context.WithCancel(context.Background())
 
-	r.assetsURL = trails.EnvVarOrURL(AssetsURLEnvVar, defaultAssetsURL)
-	r.url = trails.EnvVarOrURL(BaseURLEnvVar, defaultBaseURL)
-	r.metadata, err = newMetadata()
+	r.assetsURL = e.App.AssetsURL
+	r.url = e.App.BaseURL
+	r.metadata = newMetadata(e.App)
+	r.proxies, err = defaultTrustedProxies(e.Server.TrustedProxies)
 	if err != nil {
 		return nil, err
 	}
@@ -89,7 +131,7 @@ func New[U RangerUser](cfg Config[U]) (*Ranger, error) {
 
 	r.migrations = cfg.Migrations
 	if cfg.mockdb == nil {
-		r.db, err = defaultDB(r.env)
+		r.db, err = defaultDB(r.env, e, newSlogger(trails.AppLogKind, r.env, r.logs))
 		if err != nil {
 			return nil, err
 		}
@@ -97,48 +139,235 @@ func New[U RangerUser](cfg Config[U]) (*Ranger, error) {
 		r.db = cfg.mockdb
 	}
 
-	r.Responder = defaultResponder(r.Logger, r.url, defaultParser(r.env, r.url, r.assetsURL, cfg.FS, r.metadata), r.metadata.Contact)
+	r.jobs, err = defaultJobs(r.Logger, cfg.JobBackend, r.db, e.Jobs)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, ok := r.jobs.Backend().(*jobs.PostgresBackend); ok {
+		r.migrations = append(r.migrations, jobs.PostgresMigrations...)
+	}
 
-	r.sessions, err = defaultSessionStore(r.env, r.metadata.Title)
+	r.tasks = defaultScheduler(r.Logger, r.db)
+	if _, ok := r.db.(*postgres.DatabaseServiceImpl); ok {
+		r.migrations = append(r.migrations, jobs.PostgresTaskMigrations...)
+	}
+
+	r.shutdowns = append(r.shutdowns, backgroundShutdown(r.tasks, r.jobs))
+	r.shutdowns = append(r.shutdowns, cfg.Shutdowns...)
+
+	parser := defaultParser(r.env, r.url, r.assetsURL, cfg.FS, r.metadata)
+	var vueProps map[string]*resp.PropsSchema
+	if r.env.IsDevelopment() {
+		vueProps = cfg.VueProps
+	}
+	r.Responder = defaultResponder(r.Logger, r.url, parser, r.metadata.Contact, cfg.ErrorEnrichers, vueProps)
+
+	r.mail, err = defaultMailer(r.Logger, parser, r.metadata.Contact, e.Mail)
+	if err != nil {
+		return nil, err
+	}
+
+	r.sessions, err = defaultSessionStore(r.env, r.metadata.Title, e.Session)
 	if err != nil {
 		return nil, err
 	}
 
 	userstore := cfg.defaultUserStore(r.db)
-	var mws []middleware.Adapter
+	stack := middleware.NewStack(middleware.Named("Scope", scopeRequest()))
 	// NOTE(dlk): PRODUCTION only middlewares
 	if r.env.IsProduction() {
-		mws = append(
-			mws,
-			middleware.ForceHTTPS(r.env),
-		)
+		stack.Append(middleware.Named("ForceHTTPS", middleware.ForceHTTPS(r.env)))
 	}
 
-	logReq := middleware.LogRequest(defaultHTTPLogger(r.env, cfg.logoutput))
+	if e.Server.CanonicalHost {
+		stack.Append(middleware.Named("CanonicalHost", middleware.CanonicalHost(r.url.Host, true)))
+	}
 
-	mws = append(
-		mws,
-		logReq,
-		middleware.RequestID(),
-		middleware.InjectIPAddress(),
-		middleware.InjectSession(r.sessions),
-		middleware.CurrentUser(r.Responder, userstore),
+	logReq := middleware.LogRequest(defaultHTTPLogger(r.env, r.logs))
+	r.logReq = logReq
+
+	r.internal.srv = defaultInternalServer(r.ctx, e.Server)
+	if r.internal.srv != nil {
+		r.internal.mux = http.NewServeMux()
+		r.internal.srv.Handler = r.internal.mux
+	}
+
+	var metricsHandler http.Handler
+	if cfg.Metrics {
+		r.metrics, metricsHandler, err = defaultMetrics(r.metadata.Title, e.Metrics)
+		if err != nil {
+			return nil, err
+		}
+
+		if db, ok := r.db.(*postgres.DatabaseServiceImpl); ok {
+			c := postgres.NewCollector()
+			if err := db.DB.Use(c); err != nil {
+				return nil, err
+			}
+
+			r.metrics.MustRegister(c)
+		}
+	}
+
+	// NOTE: a nil *prometheus.Registry is not a nil prometheus.Registerer.
+	metricsMW := middleware.NoopAdapter
+	if r.metrics != nil {
+		metricsMW = middleware.Metrics(r.metrics)
+	}
+
+	recordMW := middleware.NoopAdapter
+	if cfg.Recording != nil && r.env.IsDevelopment() {
+		recordMW = r.defaultRecording(*cfg.Recording)
+	}
+
+	stack.Append(
+		middleware.Named("LogRequest", logReq),
+		middleware.Named("Metrics", metricsMW),
+		middleware.Named("Record", recordMW),
+		middleware.Named("RequestID", middleware.RequestID(r.proxies...)),
+		middleware.Named("InjectIPAddress", middleware.InjectIPAddress(r.proxies...)),
+		middleware.Named("MaintenanceGate", middleware.MaintenanceGate(r.InMaintenance)),
+		middleware.Named("InjectResponder", middleware.InjectResponder(r.Responder)),
+		middleware.Named("InjectSession", middleware.InjectSession(r.sessions)),
+		middleware.Named("CurrentUser", middleware.CurrentUser(r.Responder, userstore)),
+		middleware.Named("Impersonation", middleware.Impersonation(r.Responder, userstore)),
+		middleware.Named("ScopeUser", scopeUser[U]()),
 	)
+
+	if cfg.Middleware != nil {
+		if err := cfg.Middleware(stack); err != nil {
+			return nil, err
+		}
+	}
+
+	mws := stack.Adapters()
 	r.Router = defaultRouter(r.env, r.url, r.Responder, logReq, mws)
-	r.srv = defaultServer(r.ctx)
+	if cfg.Assets != nil {
+		r.Router.Assets(*cfg.Assets)
+	}
+	if metricsHandler != nil {
+		r.handleInternal(router.Route{Path: MetricsPath, Method: http.MethodGet, Handler: metricsHandler.ServeHTTP})
+	}
+
+	if cfg.LogLevelEndpoint {
+		adminAuth, err := defaultAdminAuth(r.metadata.Title, e.Admin)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, method := range []string{http.MethodGet, http.MethodPost, http.MethodPut} {
+			r.handleInternal(router.Route{
+				Path:        LogLevelPath,
+				Method:      method,
+				Handler:     r.handleLogLevel,
+				Middlewares: []middleware.Adapter{adminAuth},
+			})
+		}
+	}
+	r.logSignal = cfg.LogLevelSignal
+
+	if cfg.HealthEndpoints {
+		r.health.on = true
+		r.defaultHealthChecks()
+
+		if adminAuth, err := defaultAdminAuth(r.metadata.Title, e.Admin); err == nil {
+			r.handleInternal(router.Route{
+				Path:        BuildInfoPath,
+				Method:      http.MethodGet,
+				Handler:     r.handleBuildInfo,
+				Middlewares: []middleware.Adapter{adminAuth},
+			})
+		} else {
+			r.Logger.Warn("not serving build info: "+err.Error(), nil)
+		}
+	}
+
+	if cfg.RoutesEndpoint && r.env.IsDevelopment() {
+		r.Router.Get(RoutesPath, func(w http.ResponseWriter, req *http.Request) {
+			r.Responder.Json(w, req, resp.Data(r.Router.Routes()))
+		})
+	}
+
+	if r.recordings != nil {
+		r.defaultRecordingRoutes()
+	}
+
+	if r.internal.srv != nil {
+		r.defaultInternalRoutes()
+	}
+
+	if cfg.Diagnostics || r.internal.srv != nil {
+		if err := r.defaultDiagnostics(r.metadata.Title, e.Admin); err != nil {
+			return nil, err
+		}
+	}
+
+	r.srv = defaultServer(r.ctx, e.Server.Port, e.Server)
+
+	tlsCfg, redirect, err := defaultTLS(r.srv.Addr, e.TLS)
+	if err != nil {
+		return nil, err
+	}
+
+	if tlsCfg != nil {
+		r.srv.TLSConfig = tlsCfg
+		r.redirect = defaultRedirectServer(r.ctx, redirect, e.Server)
+	}
+
+	if cfg.ConfigureServer != nil {
+		cfg.ConfigureServer(r.srv)
+	}
 
 	return r, nil
 }
 
 func (r *Ranger) AssetsURL() *url.URL                            { return r.assetsURL }
 func (r *Ranger) BaseURL() *url.URL                              { return r.url }
+func (r *Ranger) Config() Env                                    { return r.config }
 func (r *Ranger) Context() (context.Context, context.CancelFunc) { return r.ctx, r.cancel }
 func (r *Ranger) DB() postgres.DatabaseService                   { return r.db }
 func (r *Ranger) Env() trails.Environment                        { return r.env }
+func (r *Ranger) Jobs() *jobs.Manager                            { return r.jobs }
+func (r *Ranger) Mailer() *mailer.TemplateMailer                 { return r.mail }
 func (r *Ranger) Metadata() Metadata                             { return r.metadata }
+func (r *Ranger) MetricsRegistry() *prometheus.Registry          { return r.metrics }
 func (r *Ranger) SessionStore() session.SessionStorer            { return r.sessions }
+func (r *Ranger) Tasks() *jobs.Scheduler                         { return r.tasks }
+
+// NewRouter constructs a router.Router for an application mounted on the Ranger with Mount,
+// e.g., a module of a monolith with its own middlewares, templates, and Responder:
+//
+//	billing := rng.NewRouter(billingResponder)
+//	billing.OnEveryRequest(billingMiddlewares...)
+//	billing.HandleRoutes(billingRoutes)
+//	rng.Mount("/billing", billing)
+//
+// The Router logs requests as the Ranger does, reports panics with d, stashes d for resp.FromContext,
+// and, once mounted, is served by and shut down with the Ranger's web server.
+// Other middlewares the Ranger applies, e.g., InjectSession or CurrentUser, are not applied;
+// add those the application needs with OnEveryRequest.
+//
+// If d is nil, the Ranger's Responder is used.
+func (r *Ranger) NewRouter(d *resp.Responder) router.Router {
+	if d == nil {
+		d = r.Responder
+	}
+
+	logReq := r.logReq
+	if logReq == nil {
+		logReq = middleware.NoopAdapter
+	}
+
+	rt := router.NewWithResponder(r.env.String(), logReq, d, r.Logger)
+	rt.OnEveryRequest(logReq, middleware.InjectResponder(d))
+
+	return rt
+}
 
 // Guide begins the web server.
+// Beforehand, Guide calls the StartFns added with OnStart,
+// and, once listening, those added with OnReady.
 //
 // These, and (*Ranger).Shutdown, stop Guide:
 //   - os.Interrupt
@@ -146,6 +375,8 @@ func (r *Ranger) SessionStore() session.SessionStorer            { return r.sess
 //   - syscall.SIGINT
 //   - syscall.SIGQUIT
 //   - syscall.SIGTERM
+//
+// When [Config].LogLevelSignal is true, SIGUSR1 toggles DEBUG logs instead.
 func (r *Ranger) Guide() error {
 	// NOTE(dlk): check the concrete type as it may be the desired type
 	// or *postgres.MockDatabaseService,
@@ -160,6 +391,122 @@ func (r *Ranger) Guide() error {
 		r.ctx, r.cancel = context.WithCancel(context.Background())
 	}
 
+	if err := r.start(); err != nil {
+		return err
+	}
+
+	pc, _, _, _ := runtime.Caller(1)
+	stop := r.watchShutdownSignals(pc)
+
+	if r.logSignal {
+		r.watchLogLevelSignal()
+	}
+
+	r.jobs.Start(r.ctx)
+	r.tasks.Start(r.ctx)
+
+	if r.internal.srv != nil {
+		go r.serve(r.internal.srv, "internal server", pc, nil)
+	}
+
+	if r.redirect != nil {
+		go r.serve(r.redirect, "HTTP to HTTPS redirect", pc, nil)
+	}
+
+	go func() {
+		defer logger.CapturePanics(r.Logger)
+
+		if bi, ok := ReadBuildInfo(); ok && bi.Revision != "" {
+			r.Info(fmt.Sprintf("running %s on commit: %s", bi.GoVersion, bi.Revision), &logger.LogContext{Caller: pc})
+		}
+
+		r.srv.Handler = r.probes(r.Router)
+		if r.config.Server.H2C && r.srv.TLSConfig == nil {
+			r.srv.Handler = h2c.NewHandler(r.srv.Handler, &http2.Server{IdleTimeout: r.srv.IdleTimeout})
+		}
+
+		r.serve(r.srv, "web server", pc, func() { r.ready(pc) })
+	}()
+
+	<-r.ctx.Done()
+	stop()
+
+	return r.shutdown()
+}
+
+// serve runs srv, serving TLS if it is configured, until it shuts down,
+// logging an error if it stops otherwise.
+// If not nil, listening is called in its own goroutine once srv is listening.
+func (r *Ranger) serve(srv *http.Server, name string, pc uintptr, listening func()) {
+	defer logger.CapturePanics(r.Logger)
+
+	ln, err := net.Listen("tcp", srv.Addr)
+	if err != nil {
+		err = fmt.Errorf("could not listen: %w", err)
+		r.Error(err.Error(), nil)
+		return
+	}
+
+	if listening != nil {
+		go listening()
+	}
+
+	if srv.TLSConfig != nil {
+		r.Info(fmt.Sprintf("running %s at %s over TLS", name, srv.Addr), &logger.LogContext{Caller: pc})
+		err = srv.ServeTLS(ln, "", "")
+	} else {
+		r.Info(fmt.Sprintf("running %s at %s", name, srv.Addr), &logger.LogContext{Caller: pc})
+		err = srv.Serve(ln)
+	}
+
+	if err != http.ErrServerClosed {
+		err = fmt.Errorf("could not listen: %w", err)
+		r.Error(err.Error(), nil)
+	}
+}
+
+// Work runs the background jobs registered with (*Ranger).Jobs
+// until receiving a signal Guide listens for or (*Ranger).Shutdown is called,
+// then waits for running jobs to finish, up to SERVER_SHUTDOWN_TIMEOUT.
+//
+// Work suits a *Ranger constructed by BuildWorkerCore, running no web server.
+func (r *Ranger) Work() error {
+	if err := r.start(); err != nil {
+		return err
+	}
+
+	pc, _, _, _ := runtime.Caller(1)
+	stop := r.watchShutdownSignals(pc)
+
+	r.jobs.Start(r.ctx)
+	r.tasks.Start(r.ctx)
+	r.Info("running background jobs", &logger.LogContext{Caller: pc})
+	go r.ready(pc)
+
+	<-r.ctx.Done()
+	stop()
+
+	return r.shutdown()
+}
+
+// Every runs fn under name in-process on the schedule spec describes,
+// e.g., "0 3 * * *" for 03:00 every day; cf. [jobs.ParseSchedule].
+// Tasks begin running with Guide or Work and stop on shutdown,
+// which waits for those running to finish, up to SERVER_SHUTDOWN_TIMEOUT.
+//
+// When connected to a database, a Postgres advisory lock ensures only one process
+// running the application executes each run, and the status of the last run is recorded;
+// cf. [jobs.PostgresTaskStore], (*Ranger).Tasks.
+//
+// Every returns trails.ErrNotValid if spec cannot be parsed
+// or a task is already scheduled under name.
+func (r *Ranger) Every(spec, name string, fn func(ctx context.Context) error) error {
+	return r.tasks.Every(spec, name, fn)
+}
+
+// watchShutdownSignals cancels the *Ranger's context.Context upon a signal Guide listens for,
+// returning a func to stop watching.
+func (r *Ranger) watchShutdownSignals(pc uintptr) func() {
 	ch := make(chan os.Signal, 1)
 	signal.Notify(
 		ch,
@@ -170,49 +517,38 @@ func (r *Ranger) Guide() error {
 		syscall.SIGTERM,
 	)
 
-	pc, _, _, _ := runtime.Caller(1)
 	go func() {
-		s := <-ch
-		r.Info(fmt.Sprint("received shutdown signal: ", s), &logger.LogContext{Caller: pc})
-		r.cancel()
-	}()
-
-	go func() {
-		if info, ok := debug.ReadBuildInfo(); ok {
-			for _, setting := range info.Settings {
-				if setting.Key == "vcs.revision" {
-					r.Info(fmt.Sprintf("running %s on commit: %s", info.GoVersion, setting.Value), &logger.LogContext{Caller: pc})
-					break
-				}
-			}
+		s, ok := <-ch
+		if !ok {
+			return
 		}
 
-		r.Info(fmt.Sprintf("running web server at %s", r.srv.Addr), &logger.LogContext{Caller: pc})
-		r.srv.Handler = r.Router
-		if err := r.srv.ListenAndServe(); err != http.ErrServerClosed {
-			err = fmt.Errorf("could not listen: %w", err)
-			r.Error(err.Error(), nil)
-		}
+		r.Info(fmt.Sprint("received shutdown signal: ", s), &logger.LogContext{Caller: pc})
+		r.cancel()
 	}()
 
-	<-r.ctx.Done()
-	close(ch)
-
-	return r.shutdown()
+	return func() {
+		signal.Stop(ch)
+		close(ch)
+	}
 }
 
 // Shutdown shutdowns the web server
 // and cancels the context.Context exposed by *Ranger.Context.
 //
-// If you pass custom ShutdownFns using Config.Shutdowns,
-// Shutdown calls these before closing the web server.
-//
-// You may want to provide custom ShutdownFns if other services
-// ought to be stopped before the web server stops accepts requests.
+// Ranger then shuts down in this order, within SERVER_SHUTDOWN_TIMEOUT:
+//  1. stops running background jobs and tasks, waiting for those running to finish
+//  2. runs the ShutdownGroups of [Config].Shutdowns and (*Ranger).OnShutdown, in order
+//  3. stops the web server accepting requests, waiting for those in flight to finish
+//     and logging the routes of any that do not
+//  4. flushes errors to Sentry, if configured
+//  5. closes log outputs
 //
-// In such a case, Ranger continues to accept HTTP requests
-// until these custom ShutdownFns finish.
+// Ranger continues to accept HTTP requests until custom ShutdownGroups finish.
 // This state of affairs ought to be gracefully handled in your web handlers.
+//
+// A step failing does not stop the rest;
+// every error is logged, and Guide or Work returns them joined together.
 func (r *Ranger) Shutdown() {
 	// NOTE(dlk): this misdirection exists to ensure any dependencies on this *Ranger
 	// not using a ShutdownFn can clean themselves up,
@@ -221,29 +557,79 @@ func (r *Ranger) Shutdown() {
 }
 
 func (r *Ranger) shutdown() error {
-	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	shutdownCtx, cancel := context.WithTimeout(
+		context.Background(),
+		r.config.Server.ShutdownTimeout,
+	)
 	defer cancel()
 
+	// NOTE: close log sinks last, so logs about shutting down reach them,
+	// giving exporters time to finish even if draining requests used up shutdownCtx.
+	defer func() {
+		closeCtx := context.WithoutCancel(shutdownCtx)
+		if err := errors.Join(r.logs.close(closeCtx), r.auditLogs.close(closeCtx)); err != nil {
+			fmt.Fprintln(os.Stderr, "could not close log outputs: "+err.Error())
+		}
+	}()
+
 	ll := r.Logger.AddSkip(r.Logger.Skip() + 2)
 
+	var errs []error
+	for _, g := range r.shutdowns {
+		ll.Info("shutting down "+g.Name, nil)
+		if err := g.Run(shutdownCtx); err != nil {
+			ll.Error(err.Error(), &logger.LogContext{Error: err})
+			errs = append(errs, err)
+		}
+	}
+
+	// NOTE: a *Ranger from BuildWorkerCore runs no web server.
+	if r.srv != nil {
+		if err := r.shutdownServer(shutdownCtx, ll); err != nil {
+			ll.Error(err.Error(), &logger.LogContext{Error: err})
+			errs = append(errs, err)
+		}
+	}
+
+	// NOTE: flush after the web server stops, so Sentry receives errors from requests in flight.
+	flush := ShutdownGroup{Fns: r.flushes, Name: "error reporting"}
+	if err := flush.Run(shutdownCtx); err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		errs = append(errs, err)
+	}
+
+	return errors.Join(errs...)
+}
+
+// shutdownServer stops the web server accepting requests,
+// waiting for those in flight to finish or ctx to be done.
+func (r *Ranger) shutdownServer(ctx context.Context, ll logger.Logger) error {
 	ll.Info("shutting down web server", nil)
-	if len(r.shutdowns) > 0 {
-		ll.Info("shutting down plugins", nil)
-		for _, fn := range r.shutdowns {
-			if err := fn(shutdownCtx); err != nil {
-				ll.Error("failed shutting down: "+err.Error(), nil)
-				return err
+
+	err := r.srv.Shutdown(ctx)
+	if r.Router != nil {
+		if inflight := drain(ctx, r.Router); len(inflight) > 0 {
+			data := make(map[string]any, len(inflight))
+			for route, n := range inflight {
+				data[route] = n
 			}
+
+			ll.Error("web server shutdown timed out with requests in flight", &logger.LogContext{Data: data})
 		}
 	}
 
-	err := r.srv.Shutdown(shutdownCtx)
-	if err == http.ErrServerClosed {
-		ll.Info("web server shutdown successfully", nil)
-		return nil
+	// NOTE: shut down the internal server once requests drain, so operators can watch them.
+	for _, srv := range []*http.Server{r.internal.srv, r.redirect} {
+		if srv == nil {
+			continue
+		}
+
+		if serr := srv.Shutdown(ctx); serr != http.ErrServerClosed {
+			err = errors.Join(err, serr)
+		}
 	}
 
-	if err != nil {
+	if err != nil && err != http.ErrServerClosed {
 		return fmt.Errorf("could not shutdown: %w", err)
 	}
 
@@ -251,22 +637,73 @@ func (r *Ranger) shutdown() error {
 	return nil
 }
 
+// drain waits for the requests rt is handling to finish or ctx to be done,
+// returning those still in flight by route.
+func drain(ctx context.Context, rt router.Router) map[string]int {
+	ticker := time.NewTicker(drainInterval)
+	defer ticker.Stop()
+
+	for rt.InFlight() > 0 {
+		select {
+		case <-ctx.Done():
+			return rt.InFlightRoutes()
+		case <-ticker.C:
+		}
+	}
+
+	return nil
+}
+
 // BuildWorkerCore constructs a *Ranger but skips those components relating to the HTTP router.
 func BuildWorkerCore() (*Ranger, error) {
+	var e Env
+	if err := LoadEnv(&e); err != nil {
+		return nil, err
+	}
+
 	var err error
-	r := new(Ranger)
-	r.env = trails.EnvVarOrEnv(environmentEnvVar, trails.Development)
-	r.Logger = defaultWorkerLogger(r.env)
+	r := &Ranger{config: e}
+	r.env = e.App.Environment
+	r.logLevel = defaultLogLevel(e.Log)
+	r.logs, err = defaultLogSinks(logOutputEnvVar, e.Log.Output, e, r.logLevel, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	r.auditLogs, err = defaultLogSinks(logAuditOutputEnvVar, e.Log.AuditOutput, e, slog.LevelInfo, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	r.Logger = defaultWorkerLogger(r.env, r.logs, e.Log.SentryDSN)
+	defaultAuditLogger(r.env, r.auditLogs, e.Log.AuditChecksum)
+	logger.SetCrashReportDir(e.Log.CrashDir)
+
+	if _, ok := r.Logger.(*logger.SentryLogger); ok {
+		r.flushes = append(r.flushes, logger.FlushSentry)
+	}
 
 	r.ctx, r.cancel = context.WithCancel(context.Background())
 
-	r.db, err = defaultDB(r.env)
+	r.db, err = defaultDB(r.env, e, newSlogger(trails.WorkerLogKind, r.env, r.logs))
+	if err != nil {
+		return nil, err
+	}
+
+	// NOTE: a worker does not run migrations;
+	// those for a PostgresBackend or PostgresTaskStore run with the web server's.
+	r.jobs, err = defaultJobs(r.Logger, nil, r.db, e.Jobs)
 	if err != nil {
 		return nil, err
 	}
+	r.tasks = defaultScheduler(r.Logger, r.db)
+	r.shutdowns = append(r.shutdowns, backgroundShutdown(r.tasks, r.jobs))
 
-	r.url = trails.EnvVarOrURL(BaseURLEnvVar, defaultBaseURL)
-	r.metadata, err = newMetadata()
+	r.url = e.App.BaseURL
+	r.metadata = newMetadata(e.App)
+
+	// NOTE: a worker finds email templates relative to where it runs, as the web server finds assets.
+	r.mail, err = defaultMailer(r.Logger, defaultParser(r.env, r.url, nil, os.DirFS("."), r.metadata), r.metadata.Contact, e.Mail)
 	if err != nil {
 		return nil, err
 	}
@@ -284,30 +721,8 @@ type Metadata struct {
 	Title   string
 }
 
-func newMetadata() (Metadata, error) {
-	m := Metadata{
-		Contact: trails.EnvVarOrString(ContactUsEnvVar, defaultContactUs),
-		Desc:    os.Getenv(AppDescEnvVar),
-		Title:   os.Getenv(AppTitleEnvVar),
-	}
-
-	if m.Contact == "" {
-		err := fmt.Errorf("%w: missing %q", trails.ErrBadConfig, ContactUsEnvVar)
-		return Metadata{}, err
-
-	}
-
-	if m.Desc == "" {
-		err := fmt.Errorf("%w: missing %q", trails.ErrBadConfig, AppDescEnvVar)
-		return Metadata{}, err
-	}
-
-	if m.Title == "" {
-		err := fmt.Errorf("%w: missing %q", trails.ErrBadConfig, AppTitleEnvVar)
-		return Metadata{}, err
-	}
-
-	return m, nil
+func newMetadata(e AppEnv) Metadata {
+	return Metadata{Contact: e.ContactUs, Desc: e.Description, Title: e.Title}
 }
 
 func (m Metadata) templateFunc() (string, func(key string) string) {
@@ -320,47 +735,53 @@ func (m Metadata) templateFunc() (string, func(key string) string) {
 	}
 }
 
-type ShutdownFn func(context.Context) error
-
 // newMaintRanger configures the bare minimum to render an HTML maintenance page.
 // This includes logging.
 func newMaintRanger[U RangerUser](r *Ranger, cfg Config[U]) *Ranger {
-	logReq := middleware.LogRequest(defaultHTTPLogger(r.env, cfg.logoutput))
+	logReq := middleware.LogRequest(defaultHTTPLogger(r.env, r.logs))
 	mws := []middleware.Adapter{
-		middleware.RequestID(),
-		middleware.InjectIPAddress(),
+		scopeRequest(),
+		middleware.RequestID(r.proxies...),
+		middleware.InjectIPAddress(r.proxies...),
 		logReq,
 	}
 
-	r.Router = router.New(r.env.String(), logReq)
+	// NOTE: maintenance mode runs no background jobs or tasks nor sends email,
+	// but applications may still register them or reference the mailer.
+	r.jobs = jobs.New(jobs.Config{Logger: r.Logger})
+	r.tasks = jobs.NewScheduler(jobs.SchedulerConfig{Logger: r.Logger})
+	r.mail = mailer.NewTemplateMailer(mailer.NewLogMailer(r.Logger), nil, r.Logger, r.metadata.Contact)
+
+	r.Router = router.NewWithResponder(r.env.String(), logReq, nil, r.Logger)
+	if cfg.Assets != nil {
+		r.Router.Assets(*cfg.Assets)
+	}
 	r.Router.OnEveryRequest(mws...)
 
-	r.Router.CatchAll(MaintModeHandler(
-		defaultParser(r.env, r.url, r.assetsURL, cfg.FS, r.metadata),
-		r.Logger,
-		r.metadata.Contact),
-	)
+	// NOTE: routes kept live must be registered ahead of the catch-all.
+	for _, route := range cfg.Maintenance.Routes {
+		r.Router.Handle(route)
+	}
+
+	// NOTE: Guide serves liveness and readiness ahead of the router.
+	r.health.on = cfg.HealthEndpoints
+
+	maint := cfg.Maintenance
+	if maint.Data == nil {
+		maint.Data = r.metadata.Contact
+	}
+
+	r.Router.CatchAll(maint.handler(defaultParser(r.env, r.url, r.assetsURL, cfg.FS, r.metadata), r.Logger))
 
-	r.srv = defaultServer(r.ctx)
+	r.srv = defaultServer(r.ctx, r.config.Server.Port, r.config.Server)
 
 	r.Logger.Info("Maintenance mode is turned on", nil)
 
 	return r
 }
 
+// MaintModeHandler responds with 503, rendering the "tmpl/maintenance.tmpl" template in p with contact
+// or, if the request accepts JSON, middleware.MaintenanceMsg; cf. [MaintenanceConfig].
 func MaintModeHandler(p *template.Parser, l logger.Logger, contact string) http.HandlerFunc {
-	return func(w http.ResponseWriter, req *http.Request) {
-		w.Header().Add("Retry-After", "600")
-		w.WriteHeader(http.StatusServiceUnavailable)
-
-		tmpl, err := p.Parse("tmpl/maintenance.tmpl")
-		if err != nil {
-			l.Error(err.Error(), nil)
-			return
-		}
-		if err := tmpl.Execute(w, contact); err != nil {
-			l.Error(err.Error(), nil)
-			return
-		}
-	}
+	return MaintenanceConfig{Data: contact}.handler(p, l)
 }
@@ -2,18 +2,30 @@ package ranger_test
 
 import (
 	"bytes"
+	"context"
+	"encoding/json"
+	"io"
 	"io/fs"
 	"log/slog"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"testing/fstest"
+	"time"
 
 	"github.com/stretchr/testify/require"
 	"github.com/xy-planning-network/trails"
+	"github.com/xy-planning-network/trails/http/middleware"
+	"github.com/xy-planning-network/trails/http/resp"
+	"github.com/xy-planning-network/trails/http/router"
+	"github.com/xy-planning-network/trails/http/session"
 	"github.com/xy-planning-network/trails/http/template"
 	tt "github.com/xy-planning-network/trails/http/template/templatetest"
 	"github.com/xy-planning-network/trails/logger"
+	"github.com/xy-planning-network/trails/postgres"
 	"github.com/xy-planning-network/trails/ranger"
+	"go.uber.org/mock/gomock"
 )
 
 func TestMaintModeHandler(t *testing.T) {
@@ -48,4 +60,263 @@ func TestMaintModeHandler(t *testing.T) {
 	require.Equal(t, http.StatusServiceUnavailable, rr.Code)
 	require.Equal(t, "600", rr.Result().Header.Get("Retry-After"))
 	require.Equal(t, msg, rr.Body.String())
+
+	// Arrange -- Test JSON
+	rr = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Accept", "application/json")
+
+	// Act + Assert
+	handler.ServeHTTP(rr, req)
+	require.Equal(t, http.StatusServiceUnavailable, rr.Code)
+	require.Equal(t, "600", rr.Result().Header.Get("Retry-After"))
+	require.JSONEq(t, `{"data": {"error": "`+middleware.MaintenanceMsg+`"}}`, rr.Body.String())
+}
+
+func TestNewMaintMode(t *testing.T) {
+	// Arrange
+	t.Setenv("APP_DESCRIPTION", "An app")
+	t.Setenv("APP_TITLE", "App")
+
+	cfg := ranger.Config[trails.User]{
+		FS:        fstest.MapFS{"tmpl/down.tmpl": {Data: []byte("Back at {{ .Back }}")}},
+		MaintMode: true,
+		Maintenance: ranger.MaintenanceConfig{
+			Data:       map[string]string{"Back": "noon"},
+			RetryAfter: time.Hour,
+			Routes: []router.Route{{
+				Path:   "/status",
+				Method: http.MethodGet,
+				Handler: func(w http.ResponseWriter, _ *http.Request) {
+					w.Write([]byte("upgrading"))
+				},
+			}},
+			Template: "tmpl/down.tmpl",
+		},
+	}
+	cfg.UseLogOutput(new(bytes.Buffer))
+
+	rng, err := ranger.New(cfg)
+	require.NoError(t, err)
+
+	for _, tc := range []struct {
+		name   string
+		path   string
+		accept string
+		code   int
+		body   string
+	}{
+		{"Page", "/", "text/html", http.StatusServiceUnavailable, "Back at noon"},
+		{"JSON", "/users", "application/json", http.StatusServiceUnavailable, middleware.MaintenanceMsg},
+		{"Route", "/status", "text/html", http.StatusOK, "upgrading"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			rr := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, tc.path, nil)
+			req.Header.Set("Accept", tc.accept)
+
+			// Act
+			rng.Router.ServeHTTP(rr, req)
+
+			// Assert
+			require.Equal(t, tc.code, rr.Code)
+			require.Contains(t, rr.Body.String(), tc.body)
+			if tc.code == http.StatusServiceUnavailable {
+				require.Equal(t, "3600", rr.Header().Get("Retry-After"))
+			}
+		})
+	}
+}
+
+func TestNewCanonicalHost(t *testing.T) {
+	// Arrange
+	t.Setenv("APP_DESCRIPTION", "An app")
+	t.Setenv("APP_TITLE", "App")
+	t.Setenv("BASE_URL", "http://app.example.com")
+	t.Setenv("SERVER_CANONICAL_HOST", "true")
+
+	cfg := ranger.Config[trails.User]{FS: fstest.MapFS{}}
+	cfg.UseDBMock(postgres.NewMockDatabaseService(gomock.NewController(t)))
+	cfg.UseLogOutput(new(bytes.Buffer))
+
+	rng, err := ranger.New(cfg)
+	require.NoError(t, err)
+	rng.Router.Get("/a", func(w http.ResponseWriter, _ *http.Request) {})
+
+	rr := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "http://www.example.com/a?b=c", nil)
+
+	// Act
+	rng.Router.ServeHTTP(rr, req)
+
+	// Assert
+	require.Equal(t, http.StatusMovedPermanently, rr.Code)
+	require.Equal(t, "http://app.example.com/a?b=c", rr.Header().Get("Location"))
+}
+
+func TestRangerNewRouter(t *testing.T) {
+	// Arrange
+	t.Setenv("APP_DESCRIPTION", "An app")
+	t.Setenv("APP_TITLE", "App")
+
+	logs := new(bytes.Buffer)
+	cfg := ranger.Config[trails.User]{FS: fstest.MapFS{}}
+	cfg.UseDBMock(postgres.NewMockDatabaseService(gomock.NewController(t)))
+	cfg.UseLogOutput(logs)
+
+	rng, err := ranger.New(cfg)
+	require.NoError(t, err)
+
+	var hasSession bool
+	var d *resp.Responder
+	billing := rng.NewRouter(nil)
+	billing.Get("/invoices", func(w http.ResponseWriter, r *http.Request) {
+		_, hasSession = trails.Value[session.Session](r.Context(), trails.SessionKey)
+		d, _ = resp.FromContext(r.Context())
+		w.WriteHeader(http.StatusTeapot)
+	})
+	rng.Mount("/billing", billing)
+
+	rr := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "http://localhost:3000/billing/invoices", nil)
+
+	// Act
+	rng.Router.ServeHTTP(rr, req)
+
+	// Assert
+	require.Equal(t, http.StatusTeapot, rr.Code)
+	require.False(t, hasSession)
+	require.Same(t, rng.Responder, d)
+	require.Contains(t, logs.String(), "/invoices")
+	routes := rng.Router.Routes()
+	require.Equal(t, "/billing/invoices", routes[len(routes)-1].Path)
+}
+
+func TestRangerRecording(t *testing.T) {
+	// Arrange
+	t.Setenv("APP_DESCRIPTION", "An app")
+	t.Setenv("APP_TITLE", "App")
+
+	cfg := ranger.Config[trails.User]{
+		FS:        fstest.MapFS{},
+		Recording: &ranger.RecordingConfig{Dir: t.TempDir(), Match: middleware.RecordRoutes("POST /echo")},
+	}
+	cfg.UseDBMock(postgres.NewMockDatabaseService(gomock.NewController(t)))
+	cfg.UseLogOutput(new(bytes.Buffer))
+
+	rng, err := ranger.New(cfg)
+	require.NoError(t, err)
+
+	rng.Router.Post("/echo", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+		io.Copy(w, r.Body)
+	})
+
+	serve := func(method, path, body string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		rng.Router.ServeHTTP(rr, httptest.NewRequest(method, "http://localhost:3000"+path, strings.NewReader(body)))
+		return rr
+	}
+
+	type recording struct {
+		ID           string `json:"id"`
+		ResponseBody []byte `json:"responseBody"`
+		Status       int    `json:"status"`
+	}
+
+	// Act
+	require.Equal(t, http.StatusTeapot, serve(http.MethodPost, "/echo", "hello").Code)
+
+	// Assert
+	var list struct{ Data []recording }
+	rr := serve(http.MethodGet, ranger.RecordingsPath, "")
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&list))
+	require.Len(t, list.Data, 1)
+	require.Equal(t, "hello", string(list.Data[0].ResponseBody))
+
+	// Act
+	rr = serve(http.MethodPost, ranger.RecordingsPath+"/"+list.Data[0].ID+"/replay", "")
+
+	// Assert
+	var replayed struct{ Data recording }
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&replayed))
+	require.Equal(t, http.StatusTeapot, replayed.Data.Status)
+	require.Equal(t, "hello", string(replayed.Data.ResponseBody))
+	require.NotEqual(t, list.Data[0].ID, replayed.Data.ID)
+
+	require.Equal(t, http.StatusNotFound, serve(http.MethodGet, ranger.RecordingsPath+"/missing", "").Code)
+}
+
+func TestRangerSetMaintenance(t *testing.T) {
+	newReq := func(path, ip string) *http.Request {
+		r := httptest.NewRequest(http.MethodGet, "https://example.com"+path, nil)
+		r.RemoteAddr = ip + ":1234"
+		return r
+	}
+
+	spoofed := newReq("/", "198.51.100.1")
+	spoofed.Header.Set("X-Forwarded-For", "203.0.113.7")
+	spoofed = spoofed.WithContext(context.WithValue(spoofed.Context(), trails.IpAddrKey, "203.0.113.7"))
+
+	// Arrange
+	rng := new(ranger.Ranger)
+
+	// Act + Assert
+	require.False(t, rng.InMaintenance(newReq("/", "198.51.100.1")))
+
+	// Act
+	err := rng.SetMaintenance(true, []string{"203.0.113.7", "192.0.2.0/24", "/health"})
+
+	// Assert
+	require.Nil(t, err)
+	require.True(t, rng.InMaintenance(newReq("/", "198.51.100.1")))
+	require.True(t, rng.InMaintenance(newReq("/", "")))
+	require.True(t, rng.InMaintenance(spoofed))
+	require.False(t, rng.InMaintenance(newReq("/", "203.0.113.7")))
+	require.False(t, rng.InMaintenance(newReq("/", "192.0.2.42")))
+	require.False(t, rng.InMaintenance(newReq("/health", "198.51.100.1")))
+
+	// Act
+	err = rng.SetMaintenance(false, []string{"not-an-ip"})
+
+	// Assert
+	require.ErrorIs(t, err, trails.ErrNotValid)
+	require.True(t, rng.InMaintenance(newReq("/", "198.51.100.1")))
+
+	// Act
+	err = rng.SetMaintenance(false, nil)
+
+	// Assert
+	require.Nil(t, err)
+	require.False(t, rng.InMaintenance(newReq("/", "198.51.100.1")))
+}
+
+func TestNewWithoutMetrics(t *testing.T) {
+	// Arrange
+	t.Setenv("APP_DESCRIPTION", "An app")
+	t.Setenv("APP_TITLE", "App")
+
+	cfg := ranger.Config[trails.User]{FS: fstest.MapFS{}}
+	cfg.UseDBMock(postgres.NewMockDatabaseService(gomock.NewController(t)))
+	cfg.UseLogOutput(new(bytes.Buffer))
+
+	// Act
+	rng, err := ranger.New(cfg)
+
+	// Assert
+	require.NoError(t, err)
+	require.Nil(t, rng.MetricsRegistry())
+
+	// Arrange
+	rr := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, ranger.MetricsPath, nil)
+
+	// Act
+	rng.Router.ServeHTTP(rr, req)
+
+	// Assert
+	require.Equal(t, http.StatusNotFound, rr.Code)
 }
@@ -0,0 +1,125 @@
+package ranger
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/xy-planning-network/trails"
+	"github.com/xy-planning-network/trails/http/middleware"
+	"github.com/xy-planning-network/trails/http/resp"
+	"github.com/xy-planning-network/trails/http/router"
+	"github.com/xy-planning-network/trails/postgres"
+)
+
+// RecordingConfig selects the requests a Ranger records while developing; cf. [Config].Recording.
+type RecordingConfig struct {
+	// Dir is the directory Recordings are written to as JSON files.
+	//
+	// If Dir is empty, DefaultRecordingsDir is used.
+	Dir string
+
+	// Match asserts whether to record the request, e.g., middleware.RecordRoutes("POST /users/{id}").
+	//
+	// If Match is nil, every request is recorded.
+	Match func(*http.Request) bool
+
+	// MaxBodyBytes is how much of a request or response body is kept.
+	//
+	// If MaxBodyBytes is 0, middleware.DefaultMaxRecordedBytes is used.
+	MaxBodyBytes int64
+
+	// Postgres keeps Recordings in the database instead of Dir,
+	// running middleware.RecordingMigrations.
+	Postgres bool
+}
+
+// defaultRecording constructs the middleware recording the requests c selects,
+// keeping them in the database or files.
+func (r *Ranger) defaultRecording(c RecordingConfig) middleware.Adapter {
+	if c.Postgres {
+		if db, ok := r.db.(*postgres.DatabaseServiceImpl); ok {
+			r.migrations = append(r.migrations, middleware.RecordingMigrations...)
+			r.recordings = middleware.NewPostgresRecordStore(db.DB)
+		}
+	}
+
+	if r.recordings == nil {
+		dir := c.Dir
+		if dir == "" {
+			dir = DefaultRecordingsDir
+		}
+
+		r.recordings = middleware.FileRecordStore{Dir: dir}
+	}
+
+	return middleware.Record(middleware.RecordOpts{
+		Match: func(req *http.Request) bool {
+			if strings.HasPrefix(req.URL.Path, RecordingsPath) {
+				return false
+			}
+
+			return c.Match == nil || c.Match(req)
+		},
+		Logger:       r.Logger,
+		MaxBodyBytes: c.MaxBodyBytes,
+		Store:        r.recordings,
+	})
+}
+
+// defaultRecordingRoutes serves the Recordings kept at RecordingsPath.
+func (r *Ranger) defaultRecordingRoutes() {
+	r.Router.HandleRoutes([]router.Route{
+		{Path: RecordingsPath, Method: http.MethodGet, Handler: r.handleRecordings},
+		{Path: RecordingsPath + "/{id}", Method: http.MethodGet, Handler: r.handleRecording},
+		{Path: RecordingsPath + "/{id}/replay", Method: http.MethodPost, Handler: r.handleReplay},
+	})
+}
+
+// handleRecordings responds with every Recording kept, newest first.
+func (r *Ranger) handleRecordings(w http.ResponseWriter, req *http.Request) {
+	recs, err := r.recordings.Recordings(req.Context())
+	if err != nil {
+		r.recordingFailed(w, req, err)
+		return
+	}
+
+	r.Responder.Json(w, req, resp.Data(recs))
+}
+
+// handleRecording responds with the Recording identified in the path.
+func (r *Ranger) handleRecording(w http.ResponseWriter, req *http.Request) {
+	rec, err := r.recording(req)
+	if err != nil {
+		r.recordingFailed(w, req, err)
+		return
+	}
+
+	r.Responder.Json(w, req, resp.Data(rec))
+}
+
+// handleReplay issues the request the Recording identified in the path recorded to the Router again,
+// responding with a Recording of the exchange.
+func (r *Ranger) handleReplay(w http.ResponseWriter, req *http.Request) {
+	rec, err := r.recording(req)
+	if err != nil {
+		r.recordingFailed(w, req, err)
+		return
+	}
+
+	r.Responder.Json(w, req, resp.Data(middleware.Replay(req.Context(), r.Router, rec)))
+}
+
+// recording retrieves the Recording identified in the path of req.
+func (r *Ranger) recording(req *http.Request) (middleware.Recording, error) {
+	id, err := router.Param[string](req, "id")
+	if err != nil {
+		return middleware.Recording{}, err
+	}
+
+	return r.recordings.Recording(req.Context(), id)
+}
+
+// recordingFailed responds with err, as 404 if no such Recording exists.
+func (r *Ranger) recordingFailed(w http.ResponseWriter, req *http.Request, err error) {
+	r.Responder.Json(w, req, resp.Code(trails.StatusFor(err)), resp.Data(map[string]any{"error": err.Error()}))
+}
@@ -0,0 +1,90 @@
+package ranger
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"sync"
+
+	"github.com/xy-planning-network/trails"
+	"github.com/xy-planning-network/trails/http/middleware"
+)
+
+// scopeKey stashes the *Container Scope attaches to an *http.Request.
+type scopeKey struct{}
+
+// A Container holds values scoped to a single *http.Request, at most one per type.
+// Middlewares register values with Provide, e.g., a database transaction, the current user, or a locale,
+// and handlers resolve them with Resolve, rather than asserting values out of the context.Context:
+//
+//	ranger.Provide(ranger.Scope(r), locale)
+//	...
+//	locale, ok := ranger.Resolve[language.Tag](ranger.Scope(r))
+//
+// A Container is safe for concurrent use.
+type Container struct {
+	mu   sync.RWMutex
+	vals map[reflect.Type]any
+}
+
+// Scope retrieves the *Container attached to req.
+// If there is none, Scope attaches a new one to req, in place,
+// so middlewares and handlers later handed req share it.
+//
+// Ranger attaches a *Container to every request ahead of all other middlewares
+// and provides the current user, of the type the Ranger was constructed with, once it is known.
+func Scope(req *http.Request) *Container {
+	if c, ok := req.Context().Value(scopeKey{}).(*Container); ok {
+		return c
+	}
+
+	c := &Container{vals: make(map[reflect.Type]any)}
+	*req = *req.WithContext(context.WithValue(req.Context(), scopeKey{}, c))
+	return c
+}
+
+// Provide registers v in c under the type T, replacing any value already registered under T.
+//
+// T is the type parameter, not the dynamic type of v,
+// so an interface can be provided and resolved by specifying it:
+//
+//	ranger.Provide[postgres.DatabaseService](ranger.Scope(r), tx)
+func Provide[T any](c *Container, v T) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.vals[reflect.TypeFor[T]()] = v
+}
+
+// Resolve retrieves the value registered in c under the type T,
+// reporting whether there is one.
+func Resolve[T any](c *Container) (T, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	v, ok := c.vals[reflect.TypeFor[T]()].(T)
+	return v, ok
+}
+
+// scopeRequest attaches a *Container to every request.
+func scopeRequest() middleware.Adapter {
+	return func(handler http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			Scope(r)
+			handler.ServeHTTP(w, r)
+		})
+	}
+}
+
+// scopeUser provides the current user stashed under trails.CurrentUserKey, if a U, to the request's *Container.
+func scopeUser[U RangerUser]() middleware.Adapter {
+	return func(handler http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if user, ok := trails.Value[U](r.Context(), trails.CurrentUserKey); ok {
+				Provide(Scope(r), user)
+			}
+
+			handler.ServeHTTP(w, r)
+		})
+	}
+}
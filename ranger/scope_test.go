@@ -0,0 +1,46 @@
+package ranger_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/xy-planning-network/trails/ranger"
+)
+
+type locale string
+
+func TestScope(t *testing.T) {
+	// Arrange
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+
+	// Act
+	c := ranger.Scope(req)
+
+	// Assert
+	require.Same(t, c, ranger.Scope(req))
+	require.Same(t, c, ranger.Scope(req.Clone(req.Context())))
+
+	_, ok := ranger.Resolve[locale](c)
+	require.False(t, ok)
+
+	// Act
+	ranger.Provide(c, locale("en-US"))
+	ranger.Provide[fmt.Stringer](c, locale("fr-FR"))
+
+	// Assert
+	l, ok := ranger.Resolve[locale](c)
+	require.True(t, ok)
+	require.Equal(t, locale("en-US"), l)
+
+	s, ok := ranger.Resolve[fmt.Stringer](c)
+	require.True(t, ok)
+	require.Equal(t, "fr-FR", s.String())
+
+	_, ok = ranger.Resolve[string](c)
+	require.False(t, ok)
+}
+
+func (l locale) String() string { return string(l) }
@@ -0,0 +1,100 @@
+package ranger
+
+import (
+	"bytes"
+	"net/http"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/xy-planning-network/trails"
+)
+
+func TestRangerServer(t *testing.T) {
+	t.Run("Defaults", func(t *testing.T) {
+		// Arrange
+		t.Setenv("HOST", "")
+		t.Setenv("PORT", "")
+
+		// Act
+		rng, _ := newTestRanger(t, Config[trails.User]{})
+
+		// Assert
+		require.Equal(t, ":3000", rng.srv.Addr)
+		require.Equal(t, 2*time.Minute, rng.srv.IdleTimeout)
+		require.Equal(t, 1<<20, rng.srv.MaxHeaderBytes)
+		require.Zero(t, rng.srv.ReadHeaderTimeout)
+		require.Equal(t, 5*time.Second, rng.srv.ReadTimeout)
+		require.Equal(t, 5*time.Second, rng.srv.WriteTimeout)
+		require.NotNil(t, rng.srv.BaseContext)
+		require.Nil(t, rng.internal.srv)
+		require.Nil(t, rng.redirect)
+	})
+
+	t.Run("Env", func(t *testing.T) {
+		// Arrange
+		t.Setenv("HOST", "127.0.0.1")
+		t.Setenv("INTERNAL_PORT", "9090")
+		t.Setenv("PORT", "8080")
+		t.Setenv("SERVER_IDLE_TIMEOUT", "30s")
+		t.Setenv("SERVER_MAX_HEADER_BYTES", "4096")
+		t.Setenv("SERVER_READ_HEADER_TIMEOUT", "2s")
+		t.Setenv("SERVER_READ_TIMEOUT", "10s")
+		t.Setenv("SERVER_WRITE_TIMEOUT", "20s")
+
+		// Act
+		rng, _ := newTestRanger(t, Config[trails.User]{})
+
+		// Assert
+		require.Equal(t, "127.0.0.1:8080", rng.srv.Addr)
+		require.Equal(t, 30*time.Second, rng.srv.IdleTimeout)
+		require.Equal(t, 4096, rng.srv.MaxHeaderBytes)
+		require.Equal(t, 2*time.Second, rng.srv.ReadHeaderTimeout)
+		require.Equal(t, 10*time.Second, rng.srv.ReadTimeout)
+		require.Equal(t, 20*time.Second, rng.srv.WriteTimeout)
+
+		require.Equal(t, "127.0.0.1:9090", rng.internal.srv.Addr)
+		require.Equal(t, 30*time.Second, rng.internal.srv.IdleTimeout)
+		require.Equal(t, 10*time.Second, rng.internal.srv.ReadHeaderTimeout)
+		require.Zero(t, rng.internal.srv.WriteTimeout)
+	})
+
+	t.Run("Bad-Env", func(t *testing.T) {
+		// Arrange
+		t.Setenv("APP_DESCRIPTION", "An app")
+		t.Setenv("APP_TITLE", "App")
+		t.Setenv("SERVER_READ_TIMEOUT", "soon")
+
+		cfg := Config[trails.User]{FS: fstest.MapFS{}}
+		cfg.UseLogOutput(new(bytes.Buffer))
+
+		// Act
+		_, err := New(cfg)
+
+		// Assert
+		require.ErrorIs(t, err, trails.ErrBadConfig)
+		require.ErrorContains(t, err, `"SERVER_READ_TIMEOUT" is not valid`)
+	})
+
+	t.Run("Configure-Server", func(t *testing.T) {
+		// Arrange
+		t.Setenv("SERVER_WRITE_TIMEOUT", "20s")
+
+		var configured *http.Server
+		cfg := Config[trails.User]{
+			ConfigureServer: func(srv *http.Server) {
+				require.Equal(t, 20*time.Second, srv.WriteTimeout)
+				configured = srv
+				srv.WriteTimeout = time.Minute
+			},
+		}
+
+		// Act
+		rng, _ := newTestRanger(t, cfg)
+
+		// Assert
+		require.Same(t, rng.srv, configured)
+		require.Equal(t, time.Minute, rng.srv.WriteTimeout)
+	})
+}
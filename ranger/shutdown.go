@@ -0,0 +1,90 @@
+package ranger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// A ShutdownFn stops a component of the application when Ranger shuts down,
+// returning once it has or ctx is done.
+type ShutdownFn func(context.Context) error
+
+// WithTimeout bounds fn to d, returning an error wrapping context.DeadlineExceeded
+// once d elapses, even if fn ignores its context.Context.
+func (fn ShutdownFn) WithTimeout(d time.Duration) ShutdownFn {
+	return func(ctx context.Context) error {
+		ctx, cancel := context.WithTimeout(ctx, d)
+		defer cancel()
+
+		return callShutdown(ctx, fn)
+	}
+}
+
+// A ShutdownGroup is a set of ShutdownFns Ranger calls together when shutting down.
+type ShutdownGroup struct {
+	// Fns stop components of the application.
+	Fns []ShutdownFn
+
+	// Name identifies the ShutdownGroup in errors and logs.
+	Name string
+
+	// Parallel calls Fns at once, rather than one after another, in order.
+	Parallel bool
+}
+
+// Run calls every ShutdownFn in the ShutdownGroup, even if some fail,
+// returning the errors of those that do joined together.
+//
+// Run stops waiting on a ShutdownFn once ctx is done, even if it ignores ctx.
+func (g ShutdownGroup) Run(ctx context.Context) error {
+	errs := make([]error, len(g.Fns))
+	if g.Parallel {
+		var wg sync.WaitGroup
+		for i, fn := range g.Fns {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				errs[i] = callShutdown(ctx, fn)
+			}()
+		}
+		wg.Wait()
+	} else {
+		for i, fn := range g.Fns {
+			errs[i] = callShutdown(ctx, fn)
+		}
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		name := g.Name
+		if name == "" {
+			name = "components"
+		}
+
+		return fmt.Errorf("could not shut down %s: %w", name, err)
+	}
+
+	return nil
+}
+
+// callShutdown calls fn, returning ctx.Err() if ctx is done before fn returns.
+func callShutdown(ctx context.Context, fn ShutdownFn) error {
+	done := make(chan error, 1)
+	go func() { done <- fn(ctx) }()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return fmt.Errorf("timed out: %w", ctx.Err())
+	}
+}
+
+// OnShutdown has Ranger run g when shutting down,
+// after stopping background work and those ShutdownGroups added before g,
+// but before the web server stops accepting requests.
+func (r *Ranger) OnShutdown(g ShutdownGroup) {
+	r.shutdowns = append(r.shutdowns, g)
+}
@@ -0,0 +1,107 @@
+package ranger_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/xy-planning-network/trails/ranger"
+)
+
+func TestShutdownFnWithTimeout(t *testing.T) {
+	// Arrange
+	block := make(chan struct{})
+	defer close(block)
+
+	fn := ranger.ShutdownFn(func(context.Context) error {
+		<-block
+		return nil
+	})
+
+	// Act
+	start := time.Now()
+	err := fn.WithTimeout(10 * time.Millisecond)(context.Background())
+
+	// Assert
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.Less(t, time.Since(start), time.Second)
+}
+
+func TestShutdownGroupRun(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	t.Run("Ordered", func(t *testing.T) {
+		// Arrange
+		var calls []string
+		g := ranger.ShutdownGroup{
+			Fns: []ranger.ShutdownFn{
+				func(context.Context) error { calls = append(calls, "first"); return errBoom },
+				func(context.Context) error { calls = append(calls, "second"); return nil },
+				func(context.Context) error { calls = append(calls, "third"); return errBoom },
+			},
+			Name: "ordered",
+		}
+
+		// Act
+		err := g.Run(context.Background())
+
+		// Assert
+		require.ErrorIs(t, err, errBoom)
+		require.ErrorContains(t, err, "could not shut down ordered")
+		require.Equal(t, []string{"first", "second", "third"}, calls)
+	})
+
+	t.Run("Parallel", func(t *testing.T) {
+		// Arrange
+		var (
+			arrived atomic.Int32
+			release = make(chan struct{})
+		)
+		wait := func(context.Context) error {
+			if arrived.Add(1) == 2 {
+				close(release)
+			}
+			<-release
+			return nil
+		}
+		g := ranger.ShutdownGroup{Fns: []ranger.ShutdownFn{wait, wait}, Parallel: true}
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+
+		// Act
+		err := g.Run(ctx)
+
+		// Assert
+		require.Nil(t, err)
+		require.EqualValues(t, 2, arrived.Load())
+	})
+
+	t.Run("Deadline", func(t *testing.T) {
+		// Arrange
+		block := make(chan struct{})
+		defer close(block)
+
+		g := ranger.ShutdownGroup{
+			Fns: []ranger.ShutdownFn{
+				func(context.Context) error { <-block; return nil },
+				func(context.Context) error { return nil },
+			},
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		// Act
+		start := time.Now()
+		err := g.Run(ctx)
+
+		// Assert
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+		require.ErrorContains(t, err, "could not shut down components")
+		require.Less(t, time.Since(start), time.Second)
+	})
+}
@@ -0,0 +1,8 @@
+//go:build !unix
+
+package ranger
+
+import "os"
+
+// logLevelSignal is nil since this platform has no SIGUSR1.
+var logLevelSignal os.Signal
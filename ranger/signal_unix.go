@@ -0,0 +1,11 @@
+//go:build unix
+
+package ranger
+
+import (
+	"os"
+	"syscall"
+)
+
+// logLevelSignal is the signal toggling DEBUG logs when Config.LogLevelSignal is true.
+var logLevelSignal os.Signal = syscall.SIGUSR1
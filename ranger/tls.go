@@ -0,0 +1,189 @@
+package ranger
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/xy-planning-network/trails"
+	"golang.org/x/crypto/acme"
+	"golang.org/x/crypto/acme/autocert"
+)
+
+// certReloadInterval is how often a certReloader checks whether its files changed.
+const certReloadInterval = time.Minute
+
+// defaultTLS configures serving TLS directly, rather than behind a proxy terminating it,
+// from certificate files or certificates obtained from an ACME CA, e.g., Let's Encrypt.
+// defaultTLS returns a nil *tls.Config if neither is configured.
+//
+// defaultTLS also constructs the handler for a listener redirecting HTTP requests to HTTPS at httpsAddr,
+// which answers ACME HTTP-01 challenges, if obtaining certificates from an ACME CA.
+//
+// defaultTLS relies on these env vars:
+//   - ACME_CACHE_DIR
+//   - ACME_DIRECTORY_URL
+//   - ACME_DOMAINS
+//   - ACME_EMAIL
+//   - CERT_FILE
+//   - KEY_FILE
+func defaultTLS(httpsAddr string, e TLSEnv) (*tls.Config, http.Handler, error) {
+	certFile, keyFile, domains := e.CertFile, e.KeyFile, e.ACMEDomains
+
+	_, port, _ := net.SplitHostPort(httpsAddr)
+	redirect := redirectHTTPS(port)
+
+	switch {
+	case certFile == "" && keyFile == "" && len(domains) == 0:
+		return nil, nil, nil
+
+	case len(domains) > 0 && (certFile != "" || keyFile != ""):
+		err := fmt.Errorf("%w: set either %q or %q and %q", trails.ErrBadConfig, acmeDomainsEnvVar, certFileEnvVar, keyFileEnvVar)
+		return nil, nil, err
+
+	case len(domains) > 0:
+		m := &autocert.Manager{
+			Cache:      autocert.DirCache(e.ACMECacheDir),
+			Email:      e.ACMEEmail,
+			HostPolicy: autocert.HostWhitelist(domains...),
+			Prompt:     autocert.AcceptTOS,
+		}
+		if e.ACMEDirectoryURL != nil {
+			m.Client = &acme.Client{DirectoryURL: e.ACMEDirectoryURL.String()}
+		}
+
+		return m.TLSConfig(), m.HTTPHandler(redirect), nil
+
+	case certFile == "":
+		return nil, nil, fmt.Errorf("%w: missing %q", trails.ErrBadConfig, certFileEnvVar)
+
+	case keyFile == "":
+		return nil, nil, fmt.Errorf("%w: missing %q", trails.ErrBadConfig, keyFileEnvVar)
+	}
+
+	cr, err := newCertReloader(certFile, keyFile)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %s", trails.ErrBadConfig, err)
+	}
+
+	cfg := &tls.Config{
+		GetCertificate: cr.getCertificate,
+		MinVersion:     tls.VersionTLS12,
+		NextProtos:     []string{"h2", "http/1.1"},
+	}
+
+	return cfg, redirect, nil
+}
+
+// redirectHTTPS redirects requests to the same URL over HTTPS on port.
+func redirectHTTPS(port string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		host := req.Host
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+
+		if port != "" && port != "443" {
+			host = net.JoinHostPort(host, port)
+		}
+
+		http.Redirect(w, req, "https://"+host+req.URL.RequestURI(), http.StatusMovedPermanently)
+	})
+}
+
+// defaultRedirectServer constructs the [*http.Server] redirecting HTTP requests to HTTPS with h.
+//
+// defaultRedirectServer relies on these env vars:
+//   - HOST
+//   - HTTP_REDIRECT_PORT
+func defaultRedirectServer(ctx context.Context, h http.Handler, e ServerEnv) *http.Server {
+	srv := defaultServer(ctx, e.HTTPRedirectPort, e)
+	srv.Handler = h
+
+	return srv
+}
+
+// A certReloader loads a certificate and its key from files,
+// loading them again when they change, e.g., when renewed by certbot.
+type certReloader struct {
+	certFile string
+	keyFile  string
+
+	mu      sync.RWMutex
+	cert    *tls.Certificate
+	checked time.Time
+	modTime time.Time
+}
+
+// newCertReloader constructs a *certReloader, returning an error if the files cannot be loaded.
+func newCertReloader(certFile, keyFile string) (*certReloader, error) {
+	cr := &certReloader{certFile: certFile, keyFile: keyFile}
+	if err := cr.load(); err != nil {
+		return nil, err
+	}
+
+	return cr, nil
+}
+
+// getCertificate returns the certificate, loading it again if its files changed
+// since it was last checked at least certReloadInterval ago.
+//
+// getCertificate matches the signature of [tls.Config].GetCertificate.
+func (cr *certReloader) getCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	cr.mu.RLock()
+	cert, stale := cr.cert, time.Since(cr.checked) > certReloadInterval
+	cr.mu.RUnlock()
+
+	if !stale {
+		return cert, nil
+	}
+
+	// NOTE: keep serving the certificate loaded before if loading it again fails,
+	// e.g., while only one of its files has been replaced.
+	if err := cr.load(); err != nil {
+		return cert, nil
+	}
+
+	cr.mu.RLock()
+	defer cr.mu.RUnlock()
+
+	return cr.cert, nil
+}
+
+// load loads the certificate and its key, if their files changed since they were last loaded.
+func (cr *certReloader) load() error {
+	cr.mu.Lock()
+	defer cr.mu.Unlock()
+
+	cr.checked = time.Now()
+
+	var modTime time.Time
+	for _, name := range []string{cr.certFile, cr.keyFile} {
+		fi, err := os.Stat(name)
+		if err != nil {
+			return fmt.Errorf("could not read certificate: %w", err)
+		}
+
+		if fi.ModTime().After(modTime) {
+			modTime = fi.ModTime()
+		}
+	}
+
+	if cr.cert != nil && !modTime.After(cr.modTime) {
+		return nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(cr.certFile, cr.keyFile)
+	if err != nil {
+		return fmt.Errorf("could not load certificate: %w", err)
+	}
+
+	cr.cert, cr.modTime = &cert, modTime
+
+	return nil
+}
@@ -0,0 +1,251 @@
+package ranger
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/xy-planning-network/trails"
+)
+
+// writeCert writes a self-signed certificate for cn and its key to files in dir,
+// returning their paths.
+func writeCert(t *testing.T, dir, cn string) (string, string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	tmpl := &x509.Certificate{
+		DNSNames:     []string{cn},
+		NotAfter:     time.Now().Add(time.Hour),
+		NotBefore:    time.Now().Add(-time.Hour),
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: cn},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	certFile, keyFile := filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600))
+
+	return certFile, keyFile
+}
+
+func TestDefaultTLS(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := writeCert(t, dir, "example.com")
+
+	garbage := filepath.Join(dir, "garbage.pem")
+	require.NoError(t, os.WriteFile(garbage, []byte("not a certificate"), 0o600))
+
+	for _, tc := range []struct {
+		name string
+		env  TLSEnv
+	}{
+		{"ACME-And-Files", TLSEnv{ACMEDomains: []string{"example.com"}, CertFile: certFile, KeyFile: keyFile}},
+		{"Missing-Cert-File", TLSEnv{KeyFile: keyFile}},
+		{"Missing-Key-File", TLSEnv{CertFile: certFile}},
+		{"No-Cert-File", TLSEnv{CertFile: filepath.Join(dir, "missing.pem"), KeyFile: keyFile}},
+		{"No-Key-File", TLSEnv{CertFile: certFile, KeyFile: filepath.Join(dir, "missing.pem")}},
+		{"Bad-Cert", TLSEnv{CertFile: garbage, KeyFile: keyFile}},
+		{"Mismatched-Key", TLSEnv{CertFile: certFile, KeyFile: certFile}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			// Act
+			cfg, redirect, err := defaultTLS(":443", tc.env)
+
+			// Assert
+			require.ErrorIs(t, err, trails.ErrBadConfig)
+			require.Nil(t, cfg)
+			require.Nil(t, redirect)
+		})
+	}
+
+	t.Run("None", func(t *testing.T) {
+		// Act
+		cfg, redirect, err := defaultTLS(":443", TLSEnv{})
+
+		// Assert
+		require.NoError(t, err)
+		require.Nil(t, cfg)
+		require.Nil(t, redirect)
+	})
+
+	t.Run("Files", func(t *testing.T) {
+		// Act
+		cfg, redirect, err := defaultTLS(":443", TLSEnv{CertFile: certFile, KeyFile: keyFile})
+
+		// Assert
+		require.NoError(t, err)
+		require.NotNil(t, redirect)
+		require.Equal(t, uint16(tls.VersionTLS12), cfg.MinVersion)
+		require.Equal(t, []string{"h2", "http/1.1"}, cfg.NextProtos)
+
+		cert, err := cfg.GetCertificate(&tls.ClientHelloInfo{ServerName: "example.com"})
+		require.NoError(t, err)
+		require.Equal(t, "example.com", cert.Leaf.Subject.CommonName)
+
+		ln, err := tls.Listen("tcp", "127.0.0.1:0", cfg)
+		require.NoError(t, err)
+		defer ln.Close()
+
+		go func() {
+			for {
+				conn, err := ln.Accept()
+				if err != nil {
+					return
+				}
+				_ = conn.(*tls.Conn).Handshake()
+				conn.Close()
+			}
+		}()
+
+		for version, ok := range map[uint16]bool{tls.VersionTLS11: false, tls.VersionTLS12: true, tls.VersionTLS13: true} {
+			conn, err := tls.Dial("tcp", ln.Addr().String(), &tls.Config{
+				InsecureSkipVerify: true,
+				MaxVersion:         version,
+				MinVersion:         version,
+				ServerName:         "example.com",
+			})
+			if ok {
+				require.NoError(t, err, tls.VersionName(version))
+				conn.Close()
+			} else {
+				require.Error(t, err, tls.VersionName(version))
+			}
+		}
+	})
+
+	t.Run("ACME", func(t *testing.T) {
+		// Act
+		cfg, redirect, err := defaultTLS(":443", TLSEnv{ACMECacheDir: t.TempDir(), ACMEDomains: []string{"example.com"}})
+
+		// Assert
+		require.NoError(t, err)
+		require.NotNil(t, cfg)
+		require.Contains(t, cfg.NextProtos, "acme-tls/1")
+
+		w := httptest.NewRecorder()
+		redirect.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "http://example.com/path?q=1", nil))
+		require.Equal(t, http.StatusMovedPermanently, w.Code)
+		require.Equal(t, "https://example.com/path?q=1", w.Header().Get("Location"))
+	})
+}
+
+func TestRangerTLS(t *testing.T) {
+	// Arrange
+	certFile, keyFile := writeCert(t, t.TempDir(), "example.com")
+	t.Setenv("CERT_FILE", certFile)
+	t.Setenv("KEY_FILE", keyFile)
+	t.Setenv("HTTP_REDIRECT_PORT", ":8080")
+	t.Setenv("PORT", ":8443")
+
+	// Act
+	rng, _ := newTestRanger(t, Config[trails.User]{})
+
+	// Assert
+	require.Equal(t, uint16(tls.VersionTLS12), rng.srv.TLSConfig.MinVersion)
+	require.Equal(t, ":8080", rng.redirect.Addr)
+
+	w := httptest.NewRecorder()
+	rng.redirect.Handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "http://example.com:8080/path?q=1", nil))
+	require.Equal(t, http.StatusMovedPermanently, w.Code)
+	require.Equal(t, "https://example.com:8443/path?q=1", w.Header().Get("Location"))
+}
+
+func TestRedirectHTTPS(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		port     string
+		url      string
+		expected string
+	}{
+		{"Default-Port", "443", "http://example.com/", "https://example.com/"},
+		{"No-Port", "", "http://example.com/", "https://example.com/"},
+		{"Other-Port", "8443", "http://example.com/", "https://example.com:8443/"},
+		{"Request-Port", "443", "http://example.com:8080/", "https://example.com/"},
+		{"Path-Query", "443", "http://example.com/a/b?c=d&e=f", "https://example.com/a/b?c=d&e=f"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodGet, tc.url, nil)
+
+			// Act
+			redirectHTTPS(tc.port).ServeHTTP(w, r)
+
+			// Assert
+			require.Equal(t, http.StatusMovedPermanently, w.Code)
+			require.Equal(t, tc.expected, w.Header().Get("Location"))
+		})
+	}
+}
+
+func TestCertReloader(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	certFile, keyFile := writeCert(t, dir, "old.example.com")
+
+	cr, err := newCertReloader(certFile, keyFile)
+	require.NoError(t, err)
+
+	// Act
+	cert, err := cr.getCertificate(nil)
+
+	// Assert
+	require.NoError(t, err)
+	require.Equal(t, "old.example.com", cert.Leaf.Subject.CommonName)
+
+	// Arrange
+	writeCert(t, dir, "new.example.com")
+	future := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(certFile, future, future))
+	require.NoError(t, os.Chtimes(keyFile, future, future))
+
+	// Act
+	cert, err = cr.getCertificate(nil)
+
+	// Assert
+	require.NoError(t, err)
+	require.Equal(t, "old.example.com", cert.Leaf.Subject.CommonName, "reloaded before certReloadInterval")
+
+	// Arrange
+	cr.checked = time.Now().Add(-2 * certReloadInterval)
+
+	// Act
+	cert, err = cr.getCertificate(nil)
+
+	// Assert
+	require.NoError(t, err)
+	require.Equal(t, "new.example.com", cert.Leaf.Subject.CommonName)
+
+	// Arrange
+	require.NoError(t, os.WriteFile(keyFile, []byte("half written"), 0o600))
+	later := future.Add(time.Minute)
+	require.NoError(t, os.Chtimes(keyFile, later, later))
+	cr.checked = time.Now().Add(-2 * certReloadInterval)
+
+	// Act
+	cert, err = cr.getCertificate(nil)
+
+	// Assert
+	require.NoError(t, err)
+	require.Equal(t, "new.example.com", cert.Leaf.Subject.CommonName)
+}
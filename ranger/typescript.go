@@ -0,0 +1,268 @@
+package ranger
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/xy-planning-network/trails"
+	"github.com/xy-planning-network/trails/http/resp"
+	"github.com/xy-planning-network/trails/http/session"
+	"github.com/xy-planning-network/trails/postgres"
+)
+
+// GenerateTypeScript writes TypeScript declarations of the JSON payloads the application responds with to w,
+// so the frontend stays in sync with them, e.g., by running go generate on:
+//
+//	//go:generate go run ./cmd/tstypes client/src/types/payloads.ts
+//
+// where ./cmd/tstypes calls:
+//
+//	ranger.WriteTypeScript[*models.User](os.Args[1], handlers.DashboardProps{}, models.Invoice{})
+//
+// An interface is exported for each struct type values are or contain, named as the Go type is,
+// with fields as encoding/json encodes them; cf. [resp.PropsSchemaOf].
+// Alongside them are the payloads trails structures:
+//   - CurrentUser, the user of type U
+//   - Envelope<T>, a payload [resp.Responder.Json] structures with [resp.EnvelopeSchema], whose data is a T
+//   - Flash, a [session.Flash]
+//   - InitialProps, the "initialProps" [resp.Vue] passes to every entry point
+//   - PagedData<T>, a [postgres.PagedData] paging through Ts
+//   - VueProps<P>, the props resp.Vue passes to an entry point alongside the props P set by [resp.Data]
+//
+// If a value is not a struct, or two types are declared by the same name differently,
+// GenerateTypeScript returns trails.ErrNotValid.
+func GenerateTypeScript[U RangerUser](w io.Writer, values ...any) error {
+	g := &tsGenerator{decls: make(map[string]string)}
+
+	user := resp.PropsSchemaOf(*new(U))
+	if user.Type != nil {
+		user.Type = slices.DeleteFunc(slices.Clone(user.Type), func(t string) bool { return t == "null" })
+	}
+	g.alias("CurrentUser", "", g.typeOf(user))
+
+	g.declare("Envelope", "<T = unknown>", resp.PropsSchemaOf(tsEnvelope{}), map[string]string{
+		"currentUser": "CurrentUser",
+		"data":        "T",
+		"flashes":     "Flash[]",
+	})
+	g.declare("Flash", "", resp.PropsSchemaOf(session.Flash{}), nil)
+	g.declare("InitialProps", "", resp.PropsSchemaOf(tsInitialProps{}), map[string]string{
+		"currentUser":  "CurrentUser | null",
+		"flashes":      "Flash[]",
+		"impersonator": "CurrentUser",
+	})
+	g.declare("PagedData", "<T = unknown>", resp.PropsSchemaOf(postgres.PagedData{}), map[string]string{"items": "T[]"})
+	g.alias("VueProps", "<P = {}>", "P & {\n  appProps?: Record<string, unknown>;\n  initialProps: InitialProps;\n}")
+
+	for _, v := range values {
+		s := resp.PropsSchemaOf(v)
+		if s.Title == "" || s.Properties == nil {
+			return fmt.Errorf("%w: %T is not a named struct", trails.ErrNotValid, v)
+		}
+
+		g.typeOf(s)
+	}
+
+	if len(g.conflicts) > 0 {
+		return fmt.Errorf("%w: more than one type is named %s", trails.ErrNotValid, strings.Join(g.conflicts, ", "))
+	}
+
+	var b bytes.Buffer
+	b.WriteString("// Code generated by ranger.GenerateTypeScript; DO NOT EDIT.\n")
+	for _, name := range slices.Sorted(maps.Keys(g.decls)) {
+		b.WriteString("\n" + g.decls[name])
+	}
+
+	_, err := w.Write(b.Bytes())
+	return err
+}
+
+// WriteTypeScript writes the TypeScript declarations GenerateTypeScript generates to file,
+// leaving file untouched if they have not changed, so tools watching it do not rebuild needlessly.
+func WriteTypeScript[U RangerUser](file string, values ...any) error {
+	var b bytes.Buffer
+	if err := GenerateTypeScript[U](&b, values...); err != nil {
+		return err
+	}
+
+	if existing, err := os.ReadFile(file); err == nil && bytes.Equal(existing, b.Bytes()) {
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+		return fmt.Errorf("could not write TypeScript to %s: %w", file, err)
+	}
+
+	if err := os.WriteFile(file, b.Bytes(), 0o644); err != nil {
+		return fmt.Errorf("could not write TypeScript to %s: %w", file, err)
+	}
+
+	return nil
+}
+
+// tsEnvelope is a payload resp.Json structures with resp.EnvelopeSchema.
+type tsEnvelope struct {
+	CurrentUser any             `json:"currentUser,omitempty"`
+	Data        any             `json:"data,omitempty"`
+	Flashes     []session.Flash `json:"flashes,omitempty"`
+}
+
+// tsInitialProps are the "initialProps" resp.Vue passes to every entry point.
+type tsInitialProps struct {
+	BaseURL      string          `json:"baseURL,omitempty"`
+	CSRFToken    string          `json:"csrfToken,omitempty"`
+	CurrentUser  any             `json:"currentUser"`
+	Flashes      []session.Flash `json:"flashes,omitempty"`
+	Impersonator any             `json:"impersonator,omitempty"`
+	Locale       string          `json:"locale,omitempty"`
+}
+
+// A tsGenerator collects TypeScript declarations by name.
+type tsGenerator struct {
+	conflicts []string
+	decls     map[string]string
+}
+
+// alias declares the type name, with type parameters params, as typ.
+func (g *tsGenerator) alias(name, params, typ string) {
+	g.add(name, fmt.Sprintf("export type %s%s = %s;\n", name, params, typ))
+}
+
+// declare declares the interface name, with type parameters params, of the object s describes,
+// typing its properties named in overrides as they say.
+func (g *tsGenerator) declare(name, params string, s *resp.PropsSchema, overrides map[string]string) {
+	var b strings.Builder
+	fmt.Fprintf(&b, "export interface %s%s {\n", name, params)
+	for _, key := range slices.Sorted(maps.Keys(s.Properties)) {
+		typ, ok := overrides[key]
+		if !ok {
+			typ = g.typeOf(s.Properties[key])
+		}
+
+		optional := ""
+		if !slices.Contains(s.Required, key) {
+			optional = "?"
+		}
+
+		fmt.Fprintf(&b, "  %s%s: %s;\n", tsKey(key), optional, typ)
+	}
+	b.WriteString("}\n")
+
+	g.add(name, b.String())
+}
+
+// add adds the declaration decl of name, noting a conflict if name is declared differently.
+func (g *tsGenerator) add(name, decl string) {
+	if existing, ok := g.decls[name]; ok && existing != decl {
+		if !slices.Contains(g.conflicts, name) {
+			g.conflicts = append(g.conflicts, name)
+		}
+		return
+	}
+
+	g.decls[name] = decl
+}
+
+// typeOf writes the TypeScript type of values s describes,
+// declaring interfaces for the titled objects among them.
+func (g *tsGenerator) typeOf(s *resp.PropsSchema) string {
+	if len(s.AnyOf) > 0 {
+		alts := make([]string, len(s.AnyOf))
+		for i, alt := range s.AnyOf {
+			alts[i] = g.typeOf(alt)
+		}
+
+		return strings.Join(alts, " | ")
+	}
+
+	if len(s.Type) == 0 {
+		return "unknown"
+	}
+
+	var alts []string
+	for _, t := range s.Type {
+		var alt string
+		switch t {
+		case "array":
+			alt = "unknown[]"
+			if s.Items != nil {
+				alt = g.typeOf(s.Items)
+				if strings.Contains(alt, " | ") {
+					alt = "(" + alt + ")"
+				}
+				alt += "[]"
+			}
+
+		case "integer", "number":
+			alt = "number"
+
+		case "object":
+			alt = g.objectType(s)
+
+		default:
+			alt = t
+		}
+
+		if !slices.Contains(alts, alt) {
+			alts = append(alts, alt)
+		}
+	}
+
+	return strings.Join(alts, " | ")
+}
+
+// objectType writes the TypeScript type of the objects s describes,
+// naming the interface declared for them when s is titled.
+func (g *tsGenerator) objectType(s *resp.PropsSchema) string {
+	switch {
+	case s.Title != "":
+		// NOTE: a generic type's name is followed by its type arguments, e.g., Page[models.User].
+		name, _, _ := strings.Cut(s.Title, "[")
+		if s.Properties != nil {
+			g.declare(name, "", s, nil)
+		}
+		return name
+
+	case s.Properties != nil:
+		props := make([]string, 0, len(s.Properties))
+		for _, key := range slices.Sorted(maps.Keys(s.Properties)) {
+			optional := ""
+			if !slices.Contains(s.Required, key) {
+				optional = "?"
+			}
+
+			props = append(props, tsKey(key)+optional+": "+g.typeOf(s.Properties[key]))
+		}
+
+		if len(props) == 0 {
+			return "Record<string, never>"
+		}
+		return "{ " + strings.Join(props, "; ") + " }"
+
+	case s.AdditionalProperties != nil:
+		return "Record<string, " + g.typeOf(s.AdditionalProperties) + ">"
+
+	default:
+		return "Record<string, unknown>"
+	}
+}
+
+// tsIdentifier matches property names TypeScript needs no quotes around.
+var tsIdentifier = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+
+// tsKey quotes key when TypeScript needs it to.
+func tsKey(key string) string {
+	if tsIdentifier.MatchString(key) {
+		return key
+	}
+
+	return strconv.Quote(key)
+}
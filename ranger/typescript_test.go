@@ -0,0 +1,113 @@
+package ranger_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/xy-planning-network/trails"
+	"github.com/xy-planning-network/trails/ranger"
+)
+
+type invoiceLine struct {
+	Amount int `json:"amount"`
+}
+
+type invoiceProps struct {
+	Due      time.Time         `json:"due"`
+	Lines    []invoiceLine     `json:"lines"`
+	Meta     map[string]string `json:"meta,omitempty"`
+	Original *invoiceProps     `json:"original,omitempty"`
+	Status   struct {
+		Paid bool `json:"paid"`
+	} `json:"status"`
+	Header string `json:"x-header"`
+}
+
+// Flash conflicts with the Flash GenerateTypeScript declares.
+type Flash struct {
+	Text string `json:"text"`
+}
+
+func TestGenerateTypeScript(t *testing.T) {
+	// Arrange
+	b := new(bytes.Buffer)
+
+	// Act
+	err := ranger.GenerateTypeScript[*adminUser](b, invoiceProps{})
+
+	// Assert
+	require.NoError(t, err)
+
+	actual := b.String()
+	require.Contains(t, actual, "// Code generated by ranger.GenerateTypeScript; DO NOT EDIT.\n")
+	require.Contains(t, actual, "export type CurrentUser = adminUser;\n")
+	require.Contains(t, actual, "export interface adminUser {\n  ID: number;\n  Roles: string[] | null;\n}\n")
+	require.Contains(t, actual, "export interface Envelope<T = unknown> {\n  currentUser?: CurrentUser;\n  data?: T;\n  flashes?: Flash[];\n}\n")
+	require.Contains(t, actual, "  currentUser: CurrentUser | null;\n")
+	require.Contains(t, actual, "export interface PagedData<T = unknown> {\n  estimated?: boolean;\n  items: T[];\n")
+	require.Contains(t, actual, "export type VueProps<P = {}> = P & {\n")
+	require.Contains(t, actual, "export interface invoiceLine {\n  amount: number;\n}\n")
+	require.Contains(t, actual, "export interface invoiceProps {\n"+
+		"  due: string;\n"+
+		"  lines: invoiceLine[] | null;\n"+
+		"  meta?: Record<string, string> | null;\n"+
+		"  original?: invoiceProps | null;\n"+
+		"  status: { paid: boolean };\n"+
+		"  \"x-header\": string;\n"+
+		"}\n")
+}
+
+func TestGenerateTypeScriptNotValid(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		values []any
+	}{
+		{"Not-Struct", []any{map[string]any{}}},
+		{"Anonymous-Struct", []any{struct{ A int }{}}},
+		{"Conflict", []any{Flash{}}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			// Act
+			err := ranger.GenerateTypeScript[*adminUser](new(bytes.Buffer), tc.values...)
+
+			// Assert
+			require.ErrorIs(t, err, trails.ErrNotValid)
+		})
+	}
+}
+
+func TestWriteTypeScript(t *testing.T) {
+	// Arrange
+	file := filepath.Join(t.TempDir(), "types", "payloads.ts")
+
+	// Act
+	err := ranger.WriteTypeScript[*adminUser](file, invoiceProps{})
+
+	// Assert
+	require.NoError(t, err)
+
+	expected := new(bytes.Buffer)
+	require.NoError(t, ranger.GenerateTypeScript[*adminUser](expected, invoiceProps{}))
+
+	actual, err := os.ReadFile(file)
+	require.NoError(t, err)
+	require.Equal(t, expected.String(), string(actual))
+
+	// Arrange
+	old := time.Now().Add(-time.Hour).Truncate(time.Second)
+	require.NoError(t, os.Chtimes(file, old, old))
+
+	// Act
+	err = ranger.WriteTypeScript[*adminUser](file, invoiceProps{})
+
+	// Assert
+	require.NoError(t, err)
+
+	info, err := os.Stat(file)
+	require.NoError(t, err)
+	require.Equal(t, old, info.ModTime())
+}
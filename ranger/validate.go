@@ -0,0 +1,133 @@
+package ranger
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/xy-planning-network/trails"
+)
+
+// Validate checks the Config and loads, as far as possible without connecting to anything,
+// every env var New reads, including those of EnvSections,
+// returning every problem found joined together, each wrapping trails.ErrBadConfig.
+// Unlike stopping at the first problem, Validate lets a deploy be fixed in one attempt.
+//
+// New calls Validate before constructing anything.
+func (c Config[U]) Validate() error {
+	_, err := c.loadEnv()
+	return err
+}
+
+// loadEnv loads the Env and EnvSections,
+// checking the env vars depending on one another or on the Config.
+func (c Config[U]) loadEnv() (Env, error) {
+	var errs []error
+	if err := c.Valid(); err != nil {
+		errs = append(errs, err)
+	}
+
+	var e Env
+	errs = append(errs, loadEnv(&e)...)
+	for _, section := range c.EnvSections {
+		errs = append(errs, loadEnv(section)...)
+	}
+
+	var required []string
+	if !c.MaintMode {
+		if c.Metrics {
+			required = append(required, metricsUsernameEnvVar, metricsPasswordEnvVar)
+		}
+
+		if c.Diagnostics && e.Server.InternalPort == "" {
+			required = append(required, adminUsernameEnvVar, adminPasswordEnvVar)
+		}
+
+		if c.LogLevelEndpoint {
+			required = append(required, adminUsernameEnvVar, adminPasswordEnvVar)
+		}
+
+		if c.JobBackend == nil && e.Jobs.Backend == "postgres" && c.mockdb != nil {
+			errs = append(errs, fmt.Errorf("%w: %s=postgres requires a database connection", trails.ErrBadConfig, jobsBackendEnvVar))
+		}
+
+		switch e.Mail.Provider {
+		case "smtp":
+			required = append(required, smtpHostEnvVar)
+		case "postmark":
+			required = append(required, postmarkServerTokenEnvVar)
+		case "sendgrid":
+			required = append(required, sendGridAPIKeyEnvVar)
+		case "ses":
+			required = append(required, awsAccessKeyIDEnvVar, awsRegionEnvVar, awsSecretAccessKeyEnvVar)
+		}
+
+		for _, keys := range []struct {
+			name string
+			vals []string
+		}{
+			{SessionAuthKeyEnvVar, []string{e.Session.AuthKey}},
+			{SessionAuthKeyPreviousEnvVar, e.Session.AuthKeyPrevious},
+			{SessionEncryptKeyEnvVar, []string{e.Session.EncryptionKey}},
+			{SessionEncryptKeyPreviousEnvVar, e.Session.EncryptionKeyPrevious},
+		} {
+			for _, key := range keys.vals {
+				if _, err := hex.DecodeString(key); err != nil {
+					errs = append(errs, fmt.Errorf("%w: %q is not valid", trails.ErrBadConfig, keys.name))
+					break
+				}
+			}
+		}
+
+		tls := e.TLS
+		switch {
+		case len(tls.ACMEDomains) > 0 && (tls.CertFile != "" || tls.KeyFile != ""):
+			errs = append(errs, fmt.Errorf("%w: set either %q or %q and %q", trails.ErrBadConfig, acmeDomainsEnvVar, certFileEnvVar, keyFileEnvVar))
+		case tls.CertFile != "" && tls.KeyFile == "":
+			required = append(required, keyFileEnvVar)
+		case tls.KeyFile != "" && tls.CertFile == "":
+			required = append(required, certFileEnvVar)
+		}
+	}
+
+	fields, _ := envFields(&e)
+	for _, f := range fields {
+		if slices.Contains(required, f.name) && f.v.IsZero() {
+			errs = append(errs, fmt.Errorf("%w: missing %q", trails.ErrBadConfig, f.name))
+		}
+	}
+
+	if _, err := defaultTrustedProxies(e.Server.TrustedProxies); err != nil {
+		errs = append(errs, err)
+	}
+
+	for _, outputs := range []struct {
+		name  string
+		specs []string
+	}{
+		{logOutputEnvVar, e.Log.Output},
+		{logAuditOutputEnvVar, e.Log.AuditOutput},
+	} {
+		for _, spec := range outputs.specs {
+			kind, _, _ := strings.Cut(spec, ":")
+			if !slices.Contains([]string{"file", "otlp", "stderr", "stdout", "syslog"}, kind) {
+				errs = append(errs, fmt.Errorf("%w: %q lists unknown output %q", trails.ErrBadConfig, outputs.name, spec))
+			}
+		}
+	}
+
+	return e, errors.Join(errs...)
+}
+
+// configReport maps every env var e and sections are loaded from to its value, masking secrets.
+func configReport(e *Env, sections []any) map[string]any {
+	report := make(map[string]any)
+	reportEnv(report, e)
+	for _, section := range sections {
+		reportEnv(report, section)
+	}
+
+	return report
+}
@@ -1,14 +1,29 @@
 package trails
 
+import "slices"
+
 // A Toolbox is a set of Tools exposed to the end user
 // in certain environments, notably, not in Production.
 // Generally, these are administrative tools that
 // simplify demonstrating features
 // which would otherwise require actions taken in many steps.
+//
+// Build a Toolbox once, e.g., when registering routes, with NewTool,
+// restricting Tools and ToolActions to those users permitted to use them with Tool.If,
+// and pass it to each response, where FilterFor removes what the current user cannot use:
+//
+//	toolbox := trails.Toolbox{
+//		trails.NewTool("Accounts").
+//			Action("Suspend", "/accounts/suspend", http.MethodPost).If(userCan("suspend")).
+//			Action("Export", "/accounts/export", http.MethodGet),
+//	}
 type Toolbox []Tool
 
-// Filter returns a Toolbox after removing all Tools that cannot be rendered.
+// Filter returns a Toolbox after removing all Tools that cannot be rendered,
+// i.e., those without ToolActions, reusing t's backing array.
 // If none can be rendered, Filter returns a zero-value Toolbox.
+//
+// Filter does not consider who can use a Tool; cf. FilterFor.
 func (t Toolbox) Filter() Toolbox {
 	var n int
 	for _, tool := range t {
@@ -25,12 +40,71 @@ func (t Toolbox) Filter() Toolbox {
 	return t[:n]
 }
 
+// FilterFor returns a new Toolbox holding only the Tools and ToolActions user is permitted to use,
+// as Tool.If restricts them, and only the Tools with ToolActions left to render.
+// If none can be rendered, FilterFor returns a zero-value Toolbox.
+//
+// user is whatever value represents the current user, e.g., the one stored under CurrentUserKey,
+// or nil if there is none.
+func (t Toolbox) FilterFor(user any) Toolbox {
+	filtered := make(Toolbox, 0, len(t))
+	for _, tool := range t {
+		if !tool.allow.allows(user) {
+			continue
+		}
+
+		actions := make([]ToolAction, 0, len(tool.Actions))
+		for _, action := range tool.Actions {
+			if action.allow.allows(user) {
+				actions = append(actions, action)
+			}
+		}
+
+		tool.Actions = actions
+		if tool.Render() {
+			filtered = append(filtered, tool)
+		}
+	}
+
+	return filtered
+}
+
 // A Tool is a set of actions grouped under a category.
 // A Tool may pertain to a part of the domain,
 // grouping actions touching similar models.
 type Tool struct {
 	Actions []ToolAction `json:"actions"`
 	Title   string       `json:"title"`
+
+	allow Permission
+}
+
+// NewTool constructs a Tool titled title, to which ToolActions are added with Tool.Action.
+func NewTool(title string) Tool {
+	return Tool{Title: title}
+}
+
+// Action returns a copy of the Tool with a ToolAction, named name,
+// the end user follows by requesting url with the HTTP method.
+func (t Tool) Action(name, url, method string) Tool {
+	t.Actions = append(slices.Clip(t.Actions), ToolAction{Method: method, Name: name, URL: url})
+	return t
+}
+
+// If returns a copy of the Tool restricted to users p permits,
+// restricting the ToolAction added last with Tool.Action or, if none has been added, the whole Tool.
+// Successive calls to If restrict to users every Permission permits.
+func (t Tool) If(p Permission) Tool {
+	if len(t.Actions) == 0 {
+		t.allow = t.allow.and(p)
+		return t
+	}
+
+	t.Actions = slices.Clone(t.Actions)
+	last := &t.Actions[len(t.Actions)-1]
+	last.allow = last.allow.and(p)
+
+	return t
 }
 
 // Render asserts whether the Tool should be rendered.
@@ -39,6 +113,43 @@ func (t Tool) Render() bool { return len(t.Actions) > 0 }
 // A ToolAction is a specific link the end user can follow
 // to execute the named action.
 type ToolAction struct {
-	Name string `json:"name"`
-	URL  string `json:"url"`
+	Method string `json:"method,omitempty"`
+	Name   string `json:"name"`
+	URL    string `json:"url"`
+
+	allow Permission
+}
+
+// A Permission reports whether user, representing the current user or nil if there is none,
+// may use a Tool or ToolAction; cf. Tool.If.
+type Permission func(user any) bool
+
+// Allow adapts fn, checking a user of type U, into a Permission.
+// The Permission denies any user not of type U, including no user.
+//
+//	userCan := func(perm string) trails.Permission {
+//		return trails.Allow(func(u *models.User) bool { return u.Can(perm) })
+//	}
+func Allow[U any](fn func(user U) bool) Permission {
+	return func(user any) bool {
+		u, ok := user.(U)
+		return ok && fn(u)
+	}
+}
+
+// allows reports whether p permits user; a nil Permission permits every user.
+func (p Permission) allows(user any) bool {
+	return p == nil || p(user)
+}
+
+// and combines p and q into a Permission permitting only users both permit.
+func (p Permission) and(q Permission) Permission {
+	switch {
+	case q == nil:
+		return p
+	case p == nil:
+		return q
+	default:
+		return func(user any) bool { return p(user) && q(user) }
+	}
 }
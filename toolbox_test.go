@@ -1,6 +1,8 @@
 package trails_test
 
 import (
+	"net/http"
+	"slices"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -58,3 +60,81 @@ func TestToolRender(t *testing.T) {
 		})
 	}
 }
+
+func TestTool(t *testing.T) {
+	// Arrange
+	base := trails.NewTool("Accounts").Action("Export", "/export", http.MethodGet)
+
+	// Act
+	suspend := base.Action("Suspend", "/suspend", http.MethodPost)
+	restore := base.Action("Restore", "/restore", http.MethodPost)
+
+	// Assert
+	require.Equal(t, "Accounts", suspend.Title)
+	require.Equal(t, []trails.ToolAction{
+		{Method: http.MethodGet, Name: "Export", URL: "/export"},
+		{Method: http.MethodPost, Name: "Suspend", URL: "/suspend"},
+	}, suspend.Actions)
+	require.Equal(t, "Restore", restore.Actions[1].Name)
+	require.Len(t, base.Actions, 1)
+}
+
+func TestToolboxFilterFor(t *testing.T) {
+	type user struct{ perms []string }
+
+	userCan := func(perm string) trails.Permission {
+		return trails.Allow(func(u user) bool { return slices.Contains(u.perms, perm) })
+	}
+
+	toolbox := trails.Toolbox{
+		trails.NewTool("Accounts").
+			Action("Export", "/export", http.MethodGet).
+			Action("Suspend", "/suspend", http.MethodPost).If(userCan("suspend")),
+		trails.NewTool("Billing").If(userCan("billing")).
+			Action("Refund", "/refund", http.MethodPost),
+		trails.NewTool("Support").
+			Action("Impersonate", "/impersonate", http.MethodPost).If(userCan("support")).If(userCan("impersonate")),
+	}
+
+	names := func(tb trails.Toolbox) []string {
+		var out []string
+		for _, tool := range tb {
+			for _, action := range tool.Actions {
+				out = append(out, tool.Title+"/"+action.Name)
+			}
+		}
+
+		return out
+	}
+
+	for _, tc := range []struct {
+		name     string
+		user     any
+		expected []string
+	}{
+		{"No-User", nil, []string{"Accounts/Export"}},
+		{"Other-Type", "admin", []string{"Accounts/Export"}},
+		{"No-Perms", user{}, []string{"Accounts/Export"}},
+		{"Suspend", user{[]string{"suspend"}}, []string{"Accounts/Export", "Accounts/Suspend"}},
+		{"Billing", user{[]string{"billing"}}, []string{"Accounts/Export", "Billing/Refund"}},
+		{"One-Of-Two", user{[]string{"support"}}, []string{"Accounts/Export"}},
+		{"Both", user{[]string{"impersonate", "support"}}, []string{"Accounts/Export", "Support/Impersonate"}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			// Act
+			actual := toolbox.FilterFor(tc.user)
+
+			// Assert
+			require.Equal(t, tc.expected, names(actual))
+		})
+	}
+
+	// Assert
+	require.Len(t, toolbox[0].Actions, 2)
+
+	// Act
+	actual := trails.Toolbox{trails.NewTool("Empty")}.FilterFor(nil)
+
+	// Assert
+	require.Equal(t, make(trails.Toolbox, 0), actual)
+}